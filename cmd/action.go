@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/github"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+var (
+	actionEventPath string
+	actionComment   bool
+)
+
+var actionCmd = &cobra.Command{
+	Use:   "action",
+	Short: "Triage the issue from a GitHub Actions event",
+	Long: `Action runs triage inside a GitHub Actions workflow. It reads the event
+payload from GITHUB_EVENT_PATH, triages the single issue it describes,
+writes the results to the job summary (GITHUB_STEP_SUMMARY), and exits.
+
+The suggested labels and duplicate issue numbers are also exported as the
+step outputs "labels" and "duplicates". Use --comment to post the results
+as a comment on the issue.
+
+If github.auth is not configured, the workflow's GITHUB_TOKEN is used.`,
+	Args: cobra.NoArgs,
+	RunE: runAction,
+}
+
+func init() {
+	actionCmd.Flags().StringVar(&actionEventPath, "event-path", "", "path to the event payload (default $GITHUB_EVENT_PATH)")
+	actionCmd.Flags().BoolVar(&actionComment, "comment", false, "post the results as a comment on the issue")
+	rootCmd.AddCommand(actionCmd)
+}
+
+// actionEvent is the subset of an issues/issue_comment event payload used by
+// the action command.
+type actionEvent struct {
+	Action     string               `json:"action"`
+	Issue      *gogithub.Issue      `json:"issue"`
+	Repository *gogithub.Repository `json:"repository"`
+}
+
+// readActionEvent reads and decodes the event payload at path.
+func readActionEvent(path string) (*actionEvent, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no event payload: GITHUB_EVENT_PATH is not set (are you running in GitHub Actions?)")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading event payload: %w", err)
+	}
+	var evt actionEvent
+	if err := json.Unmarshal(data, &evt); err != nil {
+		return nil, fmt.Errorf("parsing event payload: %w", err)
+	}
+	if evt.Issue == nil {
+		return nil, fmt.Errorf("event payload has no issue; trigger the workflow on issues events")
+	}
+	return &evt, nil
+}
+
+// repoFullName returns the owner/repo the event belongs to, falling back to
+// GITHUB_REPOSITORY when the payload has no repository.
+func (e *actionEvent) repoFullName() string {
+	if name := e.Repository.GetFullName(); name != "" {
+		return name
+	}
+	return os.Getenv("GITHUB_REPOSITORY")
+}
+
+func runAction(cmd *cobra.Command, args []string) error {
+	eventPath := actionEventPath
+	if eventPath == "" {
+		eventPath = os.Getenv("GITHUB_EVENT_PATH")
+	}
+	evt, err := readActionEvent(eventPath)
+	if err != nil {
+		return err
+	}
+
+	logger := setupLogger()
+
+	if evt.Issue.IsPullRequest() {
+		logger.Info("event is for a pull request, skipping", "number", evt.Issue.GetNumber())
+		return nil
+	}
+
+	repoFull := evt.repoFullName()
+	owner, repo, err := parseRepoArg(repoFull)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger, withGitHubToken(os.Getenv("GITHUB_TOKEN")))
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	if c.GHClient == nil {
+		return notConfigured("GitHub client", "set github.auth in config or pass GITHUB_TOKEN")
+	}
+
+	ctx := context.Background()
+
+	issue := convertGHIssue(evt.Issue)
+	result, err := triageIssue(ctx, c, owner, repo, issue)
+	if err != nil {
+		return err
+	}
+
+	summary := formatActionSummary(repoFull, issue, result)
+	if err := appendGitHubFile("GITHUB_STEP_SUMMARY", summary); err != nil {
+		return err
+	}
+	if err := appendGitHubFile("GITHUB_OUTPUT", formatActionOutputs(result)); err != nil {
+		return err
+	}
+
+	if actionComment {
+		if err := newWriter(c).Comment(ctx, owner, repo, issue.Number, summary); err != nil {
+			return err
+		}
+		if !dryRun {
+			logger.Info("posted triage comment", "repo", repoFull, "number", issue.Number)
+		}
+	}
+
+	return nil
+}
+
+// appendGitHubFile appends content to the file named by the given Actions
+// environment variable. Outside Actions (variable unset) the summary is
+// printed to stdout instead and outputs are dropped.
+func appendGitHubFile(envVar, content string) error {
+	path := os.Getenv(envVar)
+	if path == "" {
+		if envVar == "GITHUB_STEP_SUMMARY" {
+			fmt.Print(content)
+		}
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", envVar, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return fmt.Errorf("writing %s: %w", envVar, err)
+	}
+	return nil
+}
+
+// formatActionSummary renders a triage result as Markdown for the job
+// summary and issue comment.
+func formatActionSummary(repoFull string, issue github.Issue, result *github.TriageResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Triage: %s#%d\n\n", repoFull, issue.Number)
+	fmt.Fprintf(&b, "**%s**\n\n", issue.Title)
+
+	b.WriteString("**Possible duplicates**\n\n")
+	if len(result.Duplicates) == 0 {
+		b.WriteString("None found\n")
+	}
+	for _, d := range result.Duplicates {
+		pct := int(math.Round(float64(d.Score) * 100))
+		fmt.Fprintf(&b, "- #%d — %d%% similar\n", d.Number, pct)
+	}
+
+	b.WriteString("\n**Suggested labels**\n\n")
+	if len(result.SuggestedLabels) == 0 {
+		b.WriteString("None suggested\n")
+	}
+	for _, l := range result.SuggestedLabels {
+		pct := int(math.Round(l.Confidence * 100))
+		fmt.Fprintf(&b, "- `%s` (%d%% confidence)\n", l.Name, pct)
+	}
+
+	if result.Reasoning != "" {
+		fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(result.Reasoning, "\n", "\n> "))
+	}
+	return b.String()
+}
+
+// formatActionOutputs renders step outputs in GITHUB_OUTPUT key=value form.
+func formatActionOutputs(result *github.TriageResult) string {
+	labels := make([]string, 0, len(result.SuggestedLabels))
+	for _, l := range result.SuggestedLabels {
+		labels = append(labels, l.Name)
+	}
+	dups := make([]string, 0, len(result.Duplicates))
+	for _, d := range result.Duplicates {
+		dups = append(dups, strconv.Itoa(d.Number))
+	}
+	return fmt.Sprintf("labels=%s\nduplicates=%s\n", strings.Join(labels, ","), strings.Join(dups, ","))
+}
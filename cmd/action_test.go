@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+func TestReadActionEvent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "event.json")
+	payload := `{
+  "action": "opened",
+  "issue": {"number": 7, "title": "Crash on start", "body": "boom", "state": "open", "user": {"login": "alice"}},
+  "repository": {"full_name": "acme/widgets"}
+}`
+	if err := os.WriteFile(path, []byte(payload), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	evt, err := readActionEvent(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if evt.Action != "opened" {
+		t.Errorf("expected action opened, got %q", evt.Action)
+	}
+	if evt.repoFullName() != "acme/widgets" {
+		t.Errorf("expected acme/widgets, got %q", evt.repoFullName())
+	}
+	issue := convertGHIssue(evt.Issue)
+	if issue.Number != 7 || issue.Author != "alice" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+}
+
+func TestReadActionEventErrors(t *testing.T) {
+	if _, err := readActionEvent(""); err == nil {
+		t.Error("expected error for empty path")
+	}
+
+	path := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(path, []byte(`{"action":"created"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readActionEvent(path); err == nil {
+		t.Error("expected error for payload without issue")
+	}
+}
+
+func TestActionEventRepoFallback(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY", "acme/fallback")
+	evt := &actionEvent{}
+	if got := evt.repoFullName(); got != "acme/fallback" {
+		t.Errorf("expected GITHUB_REPOSITORY fallback, got %q", got)
+	}
+}
+
+func TestFormatActionSummary(t *testing.T) {
+	issue := github.Issue{Number: 42, Title: "Login fails"}
+	result := &github.TriageResult{
+		Duplicates:      []github.DuplicateCandidate{{Number: 10, Score: 0.92}},
+		SuggestedLabels: []github.LabelSuggestion{{Name: "bug", Confidence: 0.95}},
+		Reasoning:       "Reports an error",
+	}
+
+	got := formatActionSummary("acme/widgets", issue, result)
+	for _, want := range []string{"acme/widgets#42", "Login fails", "#10 — 92% similar", "`bug` (95% confidence)", "> Reports an error"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+
+	empty := formatActionSummary("acme/widgets", issue, &github.TriageResult{})
+	if !strings.Contains(empty, "None found") || !strings.Contains(empty, "None suggested") {
+		t.Errorf("expected placeholders for empty result:\n%s", empty)
+	}
+}
+
+func TestActionOutputsWrittenToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	result := &github.TriageResult{
+		Duplicates:      []github.DuplicateCandidate{{Number: 3}, {Number: 9}},
+		SuggestedLabels: []github.LabelSuggestion{{Name: "bug"}, {Name: "ui"}},
+	}
+	if err := appendGitHubFile("GITHUB_OUTPUT", formatActionOutputs(result)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "labels=bug,ui\nduplicates=3,9\n" {
+		t.Errorf("unexpected outputs: %q", data)
+	}
+}
+
+func TestActionCmdRegistered(t *testing.T) {
+	c, _, err := rootCmd.Find([]string{"action"})
+	if err != nil || c.Name() != "action" {
+		t.Fatal("action command not registered")
+	}
+	for _, name := range []string{"event-path", "comment"} {
+		if actionCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag on action", name)
+		}
+	}
+}
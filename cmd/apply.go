@@ -1,25 +1,63 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/jacklau/triage/internal/classify"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/notify"
 	"github.com/jacklau/triage/internal/store"
 )
 
+var (
+	applyPendingAll           bool
+	applyPendingMinConfidence float64
+)
+
 var applyCmd = &cobra.Command{
 	Use:   "apply <owner/repo#number> [labels...]",
 	Short: "Apply labels to an issue",
 	Long: `Apply labels to a GitHub issue and log the action as an approved
-human decision in the triage log.`,
-	Args: cobra.MinimumNArgs(2),
-	RunE: runApply,
+human decision in the triage log.
+
+Use "apply pending <owner/repo>" to review stored suggestions in bulk.`,
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeApplyArgs,
+	RunE:              runApply,
+}
+
+var applyPendingCmd = &cobra.Command{
+	Use:   "pending <owner/repo>",
+	Short: "Review and apply stored triage suggestions",
+	Long: `List triage suggestions for a repo that have not been approved or
+rejected yet, and apply the selected ones to GitHub: suggested labels are
+added and duplicates get a comment pointing at the original issue. When an
+LLM provider is configured, the comment also explains what the issue has in
+common with the original and what it adds.
+
+By default each suggestion is shown with a prompt. Use --all to apply every
+pending suggestion without prompting, optionally keeping only labels at or
+above --min-confidence:
+  triage apply pending owner/repo --all --min-confidence 0.9`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runApplyPending,
 }
 
 func init() {
+	applyPendingCmd.Flags().BoolVar(&applyPendingAll, "all", false, "apply all pending suggestions without prompting")
+	applyPendingCmd.Flags().Float64Var(&applyPendingMinConfidence, "min-confidence", 0, "only apply labels with at least this confidence (0-1)")
+	applyCmd.AddCommand(applyPendingCmd)
 	rootCmd.AddCommand(applyCmd)
 }
 
@@ -44,28 +82,38 @@ func runApply(cmd *cobra.Command, args []string) error {
 	defer c.Store.Close()
 
 	if c.GHClient == nil {
-		return fmt.Errorf("GitHub client not configured (set github.auth: app in config)")
+		return notConfigured("GitHub client", "set github.auth: app in config")
+	}
+
+	if dryRun {
+		fmt.Printf("Would apply labels %v to %s/%s#%d (dry run)\n", labels, owner, repo, number)
+		return nil
 	}
 
 	ctx := context.Background()
 
 	// Apply labels via GitHub API
-	_, _, err = c.GHClient.Issues.AddLabelsToIssue(ctx, owner, repo, number, labels)
-	if err != nil {
-		return fmt.Errorf("applying labels to %s/%s#%d: %w", owner, repo, number, err)
+	if err := newWriter(c).ApplyLabels(ctx, owner, repo, number, labels); err != nil {
+		return err
 	}
 
 	fmt.Printf("Applied labels %v to %s/%s#%d\n", labels, owner, repo, number)
 
+	if n, err := createNotifier(cfg, "", c.Store); err != nil {
+		logger.Warn("notifier unavailable, not posting follow-up", "error", err)
+	} else {
+		followUp(ctx, n, owner+"/"+repo, number, "Labels applied by a maintainer: "+strings.Join(labels, ", "), logger)
+	}
+
 	// Log in triage_log
 	repoRecord, err := c.Store.GetRepoByOwnerRepo(owner, repo)
-	if err != nil {
+	if errors.Is(err, store.ErrRepoNotFound) {
 		// Repo might not be in store, create it
 		repoRecord, err = c.Store.CreateRepo(owner, repo)
-		if err != nil {
-			logger.Warn("failed to create repo record for logging", "error", err)
-			return nil
-		}
+	}
+	if err != nil {
+		logger.Warn("failed to get repo record for logging", "error", err)
+		return nil
 	}
 
 	triageLog := &store.TriageLog{
@@ -73,7 +121,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 		IssueNumber:     number,
 		Action:          "apply_labels",
 		SuggestedLabels: strings.Join(labels, ", "),
-		HumanDecision:   "approved",
+		HumanDecision:   store.DecisionApproved,
 	}
 
 	if err := c.Store.LogTriageAction(triageLog); err != nil {
@@ -84,3 +132,289 @@ func runApply(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// pendingPlan is what applying a stored suggestion would do on GitHub.
+type pendingPlan struct {
+	Entry       store.TriageLog
+	Labels      []string
+	DuplicateOf string
+}
+
+// empty reports whether the plan has nothing to apply.
+func (p pendingPlan) empty() bool {
+	return len(p.Labels) == 0 && p.DuplicateOf == ""
+}
+
+// planPending builds the plan for a stored suggestion. Labels below
+// minConfidence are dropped; when minConfidence is set, labels logged without
+// a confidence are dropped too.
+func planPending(entry store.TriageLog, minConfidence float64) pendingPlan {
+	plan := pendingPlan{Entry: entry}
+	for _, name := range strings.Split(entry.SuggestedLabels, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if minConfidence > 0 {
+			conf, ok := entry.LabelConfidences[name]
+			if !ok || conf < minConfidence {
+				continue
+			}
+		}
+		plan.Labels = append(plan.Labels, name)
+	}
+	if entry.Action == "duplicate" {
+		plan.DuplicateOf = entry.DuplicateOf
+	}
+	return plan
+}
+
+// formatPendingPlan renders a one-line description of a plan.
+func formatPendingPlan(p pendingPlan) string {
+	return fmt.Sprintf("#%d: %s", p.Entry.IssueNumber, p.summary())
+}
+
+// summary describes what the plan applies, without the issue number.
+func (p pendingPlan) summary() string {
+	var parts []string
+	if len(p.Labels) > 0 {
+		labels := make([]string, len(p.Labels))
+		for i, name := range p.Labels {
+			labels[i] = name
+			if conf, ok := p.Entry.LabelConfidences[name]; ok {
+				labels[i] = fmt.Sprintf("%s (%d%%)", name, int(math.Round(conf*100)))
+			}
+		}
+		parts = append(parts, "labels: "+strings.Join(labels, ", "))
+	}
+	if p.DuplicateOf != "" {
+		parts = append(parts, "duplicate of "+p.DuplicateOf)
+	}
+	if len(parts) == 0 {
+		return "nothing to apply"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// followUp posts text about an issue as a follow-up to its triage
+// notification, for notifiers that thread them. Failures are logged.
+func followUp(ctx context.Context, n notify.Notifier, repo string, number int, text string, logger *slog.Logger) {
+	if n == nil {
+		return
+	}
+	if err := notify.SendFollowUp(ctx, n, repo, number, text); err != nil {
+		logger.Warn("failed to post follow-up", "issue", number, "error", err)
+	}
+}
+
+// reviewPending asks for a decision on each plan and returns the plans to
+// apply and to reject. With all set, every non-empty plan is approved
+// without prompting. Answering q stops the review early.
+func reviewPending(w io.Writer, r *bufio.Reader, plans []pendingPlan, all bool) (approved, rejected []pendingPlan) {
+	for _, p := range plans {
+		if p.empty() {
+			continue
+		}
+		fmt.Fprintln(w, formatPendingPlan(p))
+		if all {
+			approved = append(approved, p)
+			continue
+		}
+
+		fmt.Fprint(w, "  Apply? [y]es / [n]o (reject) / [s]kip / [q]uit: ")
+		answer, err := r.ReadString('\n')
+		switch strings.TrimSpace(strings.ToLower(answer)) {
+		case "y", "yes":
+			approved = append(approved, p)
+		case "n", "no":
+			rejected = append(rejected, p)
+		case "q", "quit":
+			return approved, rejected
+		}
+		if err != nil {
+			return approved, rejected
+		}
+	}
+	return approved, rejected
+}
+
+func runApplyPending(cmd *cobra.Command, args []string) error {
+	owner, repo, err := parseRepoArg(args[0])
+	if err != nil {
+		return err
+	}
+	if applyPendingMinConfidence < 0 || applyPendingMinConfidence > 1 {
+		return fmt.Errorf("--min-confidence must be between 0 and 1, got %v", applyPendingMinConfidence)
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	if c.GHClient == nil {
+		return notConfigured("GitHub client", "set github.auth: app in config")
+	}
+
+	repoRecord, err := c.Store.GetRepoByOwnerRepo(owner, repo)
+	if errors.Is(err, store.ErrRepoNotFound) {
+		return fmt.Errorf("no triage history for %s/%s", owner, repo)
+	}
+	if err != nil {
+		return fmt.Errorf("looking up repo: %w", err)
+	}
+
+	entries, err := c.Store.ListPendingTriage(repoRecord.ID)
+	if err != nil {
+		return err
+	}
+
+	plans := make([]pendingPlan, 0, len(entries))
+	for _, e := range entries {
+		plans = append(plans, planPending(e, applyPendingMinConfidence))
+	}
+
+	out := cmd.OutOrStdout()
+	approved, rejected := reviewPending(out, bufio.NewReader(cmd.InOrStdin()), plans, applyPendingAll)
+	if len(approved) == 0 && len(rejected) == 0 {
+		fmt.Fprintf(out, "No pending suggestions to apply for %s/%s\n", owner, repo)
+		return nil
+	}
+
+	if dryRun {
+		for _, p := range approved {
+			fmt.Fprintf(out, "Would apply %s (dry run)\n", formatPendingPlan(p))
+		}
+		for _, p := range rejected {
+			fmt.Fprintf(out, "Would reject #%d (dry run)\n", p.Entry.IssueNumber)
+		}
+		return nil
+	}
+
+	n, err := createNotifier(cfg, "", c.Store)
+	if err != nil {
+		logger.Warn("notifier unavailable, not posting follow-ups", "error", err)
+	}
+
+	ctx := context.Background()
+	w := newWriter(c)
+	fullName := owner + "/" + repo
+	var dc *duplicateCommenter
+	if c.Classifier != nil {
+		dc = &duplicateCommenter{
+			explainer: c.Classifier,
+			issue:     func(number int) (*store.Issue, error) { return c.Store.GetIssue(repoRecord.ID, number) },
+			repo:      fullName,
+			logger:    logger,
+		}
+	}
+	applied := 0
+	for _, p := range approved {
+		if err := applyPendingPlan(ctx, w, owner, repo, p, dc); err != nil {
+			logger.Error("failed to apply suggestion", "issue", p.Entry.IssueNumber, "error", err)
+			continue
+		}
+		if err := c.Store.UpdateHumanDecision(p.Entry.ID, store.DecisionApproved); err != nil {
+			logger.Warn("failed to record decision", "issue", p.Entry.IssueNumber, "error", err)
+		}
+		followUp(ctx, n, fullName, p.Entry.IssueNumber, "Approved by a maintainer and applied: "+p.summary(), logger)
+		applied++
+	}
+	for _, p := range rejected {
+		if err := c.Store.UpdateHumanDecision(p.Entry.ID, store.DecisionRejected); err != nil {
+			logger.Warn("failed to record decision", "issue", p.Entry.IssueNumber, "error", err)
+		}
+		followUp(ctx, n, fullName, p.Entry.IssueNumber, "Suggestion rejected by a maintainer", logger)
+	}
+
+	fmt.Fprintf(out, "Applied %d, rejected %d, failed %d\n", applied, len(rejected), len(approved)-applied)
+	return nil
+}
+
+// applyPendingPlan adds the planned labels and posts a duplicate comment,
+// written by dc if it is non-nil.
+func applyPendingPlan(ctx context.Context, w github.Writer, owner, repo string, p pendingPlan, dc *duplicateCommenter) error {
+	number := p.Entry.IssueNumber
+	if len(p.Labels) > 0 {
+		if err := w.ApplyLabels(ctx, owner, repo, number, p.Labels); err != nil {
+			return err
+		}
+	}
+	if p.DuplicateOf != "" {
+		body := dc.comment(ctx, number, p.DuplicateOf)
+		if err := w.Comment(ctx, owner, repo, number, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// duplicateExplainer explains why one issue duplicates another.
+type duplicateExplainer interface {
+	ExplainDuplicate(ctx context.Context, repo string, original, report github.Issue) (*classify.DuplicateExplanation, error)
+}
+
+// duplicateCommenter writes the comment posted on a duplicate. So reporters
+// aren't left with a bare link, it adds what the issue has in common with
+// the original and what it adds, when both issues are stored.
+type duplicateCommenter struct {
+	explainer duplicateExplainer
+	issue     func(number int) (*store.Issue, error)
+	repo      string
+	logger    *slog.Logger
+}
+
+// comment returns the comment for issue number, a duplicate of duplicateOf
+// (e.g. "#12, #15"). It is explained against the first issue listed. Without
+// an explanation, or with a nil commenter, it is a one-line note.
+func (d *duplicateCommenter) comment(ctx context.Context, number int, duplicateOf string) string {
+	body := fmt.Sprintf("This issue looks like a possible duplicate of %s.", duplicateOf)
+	if d == nil {
+		return body
+	}
+	first, _, _ := strings.Cut(duplicateOf, ",")
+	original, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(first), "#"))
+	if err != nil {
+		return body
+	}
+
+	e, err := d.explain(ctx, original, number)
+	if err != nil {
+		d.logger.Warn("not explaining duplicate", "issue", number, "original", original, "error", err)
+		return body
+	}
+
+	var b strings.Builder
+	b.WriteString(body)
+	b.WriteString("\n\n" + e.Overlap)
+	if len(e.NewDetails) > 0 {
+		fmt.Fprintf(&b, "\n\nThis report adds details that #%d doesn't have:\n", original)
+		for _, detail := range e.NewDetails {
+			b.WriteString("\n- " + detail)
+		}
+	}
+	return b.String()
+}
+
+// explain loads both issues and asks for an explanation.
+func (d *duplicateCommenter) explain(ctx context.Context, original, number int) (*classify.DuplicateExplanation, error) {
+	orig, err := d.issue(original)
+	if err != nil {
+		return nil, fmt.Errorf("loading #%d: %w", original, err)
+	}
+	report, err := d.issue(number)
+	if err != nil {
+		return nil, fmt.Errorf("loading #%d: %w", number, err)
+	}
+	return d.explainer.ExplainDuplicate(ctx, d.repo,
+		github.Issue{Number: orig.Number, Title: orig.Title, Body: orig.Body},
+		github.Issue{Number: report.Number, Title: report.Title, Body: report.Body})
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/classify"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestApplyPendingCmdRegistered(t *testing.T) {
+	c, _, err := rootCmd.Find([]string{"apply", "pending", "owner/repo"})
+	if err != nil || c != applyPendingCmd {
+		t.Fatalf("expected apply pending subcommand, got %v (%v)", c, err)
+	}
+	for _, name := range []string{"all", "min-confidence"} {
+		if applyPendingCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag on apply pending", name)
+		}
+	}
+}
+
+func TestPlanPending(t *testing.T) {
+	entry := store.TriageLog{
+		IssueNumber:      7,
+		Action:           "triaged",
+		SuggestedLabels:  "bug, ui",
+		LabelConfidences: map[string]float64{"bug": 0.95, "ui": 0.6},
+	}
+
+	plan := planPending(entry, 0)
+	if strings.Join(plan.Labels, ",") != "bug,ui" {
+		t.Errorf("expected all labels without threshold, got %v", plan.Labels)
+	}
+
+	plan = planPending(entry, 0.9)
+	if strings.Join(plan.Labels, ",") != "bug" {
+		t.Errorf("expected only bug above 0.9, got %v", plan.Labels)
+	}
+
+	// Labels logged without confidences are dropped when a threshold is set.
+	legacy := store.TriageLog{Action: "triaged", SuggestedLabels: "bug"}
+	if plan := planPending(legacy, 0.5); !plan.empty() {
+		t.Errorf("expected empty plan for legacy entry, got %+v", plan)
+	}
+
+	dup := store.TriageLog{IssueNumber: 8, Action: "duplicate", DuplicateOf: "#3"}
+	plan = planPending(dup, 0.9)
+	if plan.DuplicateOf != "#3" || plan.empty() {
+		t.Errorf("expected duplicate plan, got %+v", plan)
+	}
+	if got := formatPendingPlan(plan); got != "#8: duplicate of #3" {
+		t.Errorf("unexpected format %q", got)
+	}
+}
+
+func TestReviewPending(t *testing.T) {
+	plans := []pendingPlan{
+		{Entry: store.TriageLog{IssueNumber: 1}, Labels: []string{"bug"}},
+		{Entry: store.TriageLog{IssueNumber: 2}, Labels: []string{"ui"}},
+		{Entry: store.TriageLog{IssueNumber: 3}},
+		{Entry: store.TriageLog{IssueNumber: 4}, DuplicateOf: "#1"},
+		{Entry: store.TriageLog{IssueNumber: 5}, Labels: []string{"docs"}},
+	}
+
+	var out bytes.Buffer
+	// #3 has nothing to apply so it is not prompted; #4 is skipped and the
+	// review stops at #5.
+	in := bufio.NewReader(strings.NewReader("y\nn\ns\nq\n"))
+	approved, rejected := reviewPending(&out, in, plans, false)
+	if len(approved) != 1 || approved[0].Entry.IssueNumber != 1 {
+		t.Errorf("expected #1 approved, got %+v", approved)
+	}
+	if len(rejected) != 1 || rejected[0].Entry.IssueNumber != 2 {
+		t.Errorf("expected #2 rejected, got %+v", rejected)
+	}
+	if strings.Contains(out.String(), "#3:") {
+		t.Errorf("empty plan should not be shown:\n%s", out.String())
+	}
+
+	out.Reset()
+	approved, rejected = reviewPending(&out, bufio.NewReader(strings.NewReader("")), plans, true)
+	if len(approved) != 4 || len(rejected) != 0 {
+		t.Errorf("expected 4 approved with --all, got %d approved %d rejected", len(approved), len(rejected))
+	}
+}
+
+// recordingWriter is a github.Writer that records each call.
+type recordingWriter struct {
+	calls []string
+}
+
+func (w *recordingWriter) ApplyLabels(_ context.Context, owner, repo string, number int, labels []string) error {
+	w.calls = append(w.calls, fmt.Sprintf("labels %s/%s#%d %v", owner, repo, number, labels))
+	return nil
+}
+
+func (w *recordingWriter) Comment(_ context.Context, owner, repo string, number int, body string) error {
+	w.calls = append(w.calls, fmt.Sprintf("comment %s/%s#%d %s", owner, repo, number, body))
+	return nil
+}
+
+func (w *recordingWriter) Close(_ context.Context, owner, repo string, number int, reason string) error {
+	w.calls = append(w.calls, fmt.Sprintf("close %s/%s#%d %s", owner, repo, number, reason))
+	return nil
+}
+
+func (w *recordingWriter) Assign(_ context.Context, owner, repo string, number int, assignees []string) error {
+	w.calls = append(w.calls, fmt.Sprintf("assign %s/%s#%d %v", owner, repo, number, assignees))
+	return nil
+}
+
+func TestApplyPendingPlan(t *testing.T) {
+	w := &recordingWriter{}
+	p := pendingPlan{
+		Entry:       store.TriageLog{IssueNumber: 7},
+		Labels:      []string{"bug"},
+		DuplicateOf: "#3",
+	}
+	if err := applyPendingPlan(context.Background(), w, "o", "r", p, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"labels o/r#7 [bug]",
+		"comment o/r#7 This issue looks like a possible duplicate of #3.",
+	}
+	if strings.Join(w.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %q, want %q", w.calls, want)
+	}
+}
+
+// fakeExplainer returns a fixed explanation, or err.
+type fakeExplainer struct {
+	explanation classify.DuplicateExplanation
+	err         error
+	got         []github.Issue
+}
+
+func (f *fakeExplainer) ExplainDuplicate(_ context.Context, _ string, original, report github.Issue) (*classify.DuplicateExplanation, error) {
+	f.got = append(f.got, original, report)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &f.explanation, nil
+}
+
+func testDuplicateCommenter(ex duplicateExplainer) *duplicateCommenter {
+	issues := map[int]*store.Issue{
+		3: {Number: 3, Title: "Crash on start", Body: "It crashes."},
+		7: {Number: 7, Title: "App crashes at launch", Body: "Crashes on Windows 11."},
+	}
+	return &duplicateCommenter{
+		explainer: ex,
+		issue: func(n int) (*store.Issue, error) {
+			if i, ok := issues[n]; ok {
+				return i, nil
+			}
+			return nil, fmt.Errorf("issue #%d not stored", n)
+		},
+		repo:   "o/r",
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestDuplicateCommenter_Explains(t *testing.T) {
+	ex := &fakeExplainer{explanation: classify.DuplicateExplanation{
+		Overlap:    "Both report a crash right after launch.",
+		NewDetails: []string{"Happens on Windows 11"},
+	}}
+	got := testDuplicateCommenter(ex).comment(context.Background(), 7, "#3, #5")
+
+	want := "This issue looks like a possible duplicate of #3, #5.\n\n" +
+		"Both report a crash right after launch.\n\n" +
+		"This report adds details that #3 doesn't have:\n\n" +
+		"- Happens on Windows 11"
+	if got != want {
+		t.Errorf("comment =\n%s\nwant:\n%s", got, want)
+	}
+	if len(ex.got) != 2 || ex.got[0].Number != 3 || ex.got[1].Body != "Crashes on Windows 11." {
+		t.Errorf("explainer got %+v, want #3 then #7", ex.got)
+	}
+}
+
+func TestDuplicateCommenter_NothingNew(t *testing.T) {
+	ex := &fakeExplainer{explanation: classify.DuplicateExplanation{Overlap: "Same crash."}}
+	got := testDuplicateCommenter(ex).comment(context.Background(), 7, "#3")
+	if want := "This issue looks like a possible duplicate of #3.\n\nSame crash."; got != want {
+		t.Errorf("comment = %q, want %q", got, want)
+	}
+}
+
+func TestDuplicateCommenter_FallsBack(t *testing.T) {
+	plain := "This issue looks like a possible duplicate of #3."
+	tests := map[string]struct {
+		dc          *duplicateCommenter
+		number      int
+		duplicateOf string
+	}{
+		"nil commenter":   {nil, 7, "#3"},
+		"explainer error": {testDuplicateCommenter(&fakeExplainer{err: errors.New("timeout")}), 7, "#3"},
+		"not stored":      {testDuplicateCommenter(&fakeExplainer{}), 9, "#3"},
+	}
+	for name, tt := range tests {
+		if got := tt.dc.comment(context.Background(), tt.number, tt.duplicateOf); got != plain {
+			t.Errorf("%s: comment = %q, want %q", name, got, plain)
+		}
+	}
+}
@@ -3,35 +3,88 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/pipeline"
 	"github.com/jacklau/triage/internal/store"
 )
 
-var checkOutput string
+var (
+	checkOutput string
+	checkStdin  bool
+	checkFile   string
+
+	checkFailOnDuplicates bool
+	checkFailAbove        float64
+)
 
 var checkCmd = &cobra.Command{
-	Use:   "check <owner/repo#number>",
+	Use:   "check <owner/repo#number | owner/repo>",
 	Short: "Check a single issue for duplicates and classification",
 	Long: `Check fetches a single issue, runs dedup detection and classification,
 and prints the results to stdout.
 
-Use --output json to get structured JSON output.`,
-	Args: cobra.ExactArgs(1),
-	RunE: runCheck,
+To check an issue before filing it, pass just owner/repo and read the draft
+with --stdin or --file. The first non-empty line is the title (a leading
+Markdown "#" is stripped) and the rest is the body. Drafts are compared
+against issues already stored by scan; nothing is written to the database.
+
+Use --output json to get structured JSON output.
+
+For CI, --fail-on-duplicates (or --fail-if-duplicate-above <score>) makes
+the command exit with status 2 when duplicates are found.`,
+	Example: `  triage check octocat/hello-world#42
+  triage check octocat/hello-world --file draft.md
+  pbpaste | triage check octocat/hello-world --stdin`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIssueRefs(1),
+	RunE:              runCheck,
 }
 
 func init() {
 	checkCmd.Flags().StringVar(&checkOutput, "output", "text", "output format: text or json")
+	checkCmd.Flags().BoolVar(&checkStdin, "stdin", false, "read an issue draft from stdin instead of fetching an issue")
+	checkCmd.Flags().StringVar(&checkFile, "file", "", "read an issue draft from `path` instead of fetching an issue")
+	checkCmd.MarkFlagsMutuallyExclusive("stdin", "file")
+	checkCmd.Flags().BoolVar(&checkFailOnDuplicates, "fail-on-duplicates", false, "exit with status 2 if any potential duplicates are found")
+	checkCmd.Flags().Float64Var(&checkFailAbove, "fail-if-duplicate-above", 0, "exit with status 2 if any duplicate scores above this similarity (0-1)")
+	checkCmd.Flags().BoolVar(&noCache, "no-cache", false, "classify every issue with the LLM, ignoring cached results")
+	registerFlagValues(checkCmd, "output", outputFormats)
 	rootCmd.AddCommand(checkCmd)
 }
 
+// parseDraft reads an unfiled issue. The first non-empty line is the title,
+// with any leading Markdown heading markers removed; the rest is the body.
+func parseDraft(r io.Reader) (github.Issue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return github.Issue{}, fmt.Errorf("reading draft: %w", err)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		title := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		if title == "" {
+			continue
+		}
+		return github.Issue{
+			Title: title,
+			Body:  strings.TrimSpace(strings.Join(lines[i+1:], "\n")),
+		}, nil
+	}
+	return github.Issue{}, fmt.Errorf("draft is empty: the first non-empty line is used as the title")
+}
+
 func parseIssueRef(ref string) (owner, repo string, number int, err error) {
 	// Format: owner/repo#number
 	hashIdx := strings.LastIndex(ref, "#")
@@ -56,6 +109,15 @@ func parseIssueRef(ref string) (owner, repo string, number int, err error) {
 }
 
 func runCheck(cmd *cobra.Command, args []string) error {
+	gate, err := newDuplicateGate(checkFailOnDuplicates, checkFailAbove)
+	if err != nil {
+		return err
+	}
+
+	if checkStdin || checkFile != "" {
+		return runCheckDraft(cmd, args[0], gate)
+	}
+
 	owner, repo, number, err := parseIssueRef(args[0])
 	if err != nil {
 		return err
@@ -68,14 +130,14 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	c, err := initComponents(cfg, logger)
+	c, err := initComponents(cfg, logger, withNoCache(noCache))
 	if err != nil {
 		return fmt.Errorf("initializing components: %w", err)
 	}
 	defer c.Store.Close()
 
 	if c.GHClient == nil {
-		return fmt.Errorf("GitHub client not configured (set github.auth: app in config)")
+		return notConfigured("GitHub client", "set github.auth: app in config")
 	}
 
 	ctx := context.Background()
@@ -88,13 +150,103 @@ func runCheck(cmd *cobra.Command, args []string) error {
 
 	issue := convertGHIssue(ghIssue)
 
+	repoFull := fmt.Sprintf("%s/%s", owner, repo)
+	result, err := triageIssue(ctx, c, owner, repo, issue)
+	if err != nil {
+		return err
+	}
+
+	// Output results
+	if err := printCheck(repoFull, number, issue, result); err != nil {
+		return err
+	}
+	return checkGate(gate, result)
+}
+
+// printCheck prints a check result in the --output format.
+func printCheck(repoFull string, number int, issue github.Issue, result *github.TriageResult) error {
+	if checkOutput == "json" {
+		return printCheckJSON(issue, result)
+	}
+	return printCheckText(repoFull, number, issue, result)
+}
+
+// checkGate applies the --fail-* duplicate gate to a single result.
+func checkGate(gate duplicateGate, result *github.TriageResult) error {
+	if gate.trips(result) {
+		return gate.err(1)
+	}
+	return nil
+}
+
+// runCheckDraft checks an issue draft read from stdin or --file against the
+// issues already stored for the repo.
+func runCheckDraft(cmd *cobra.Command, repoArg string, gate duplicateGate) error {
+	owner, repo, err := parseRepoArg(repoArg)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = cmd.InOrStdin()
+	if checkFile != "" {
+		f, err := os.Open(checkFile)
+		if err != nil {
+			return fmt.Errorf("opening draft: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	draft, err := parseDraft(r)
+	if err != nil {
+		return err
+	}
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	// Drafts are not stored, so neither is their classification
+	c, err := initComponents(cfg, logger, withNoCache(true))
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	repoFull := fmt.Sprintf("%s/%s", owner, repo)
+	if r, err := c.Store.GetRepoByOwnerRepo(owner, repo); err == nil {
+		if err := c.checkEmbeddingDims(context.Background(), r.ID, repoFull); err != nil {
+			return err
+		}
+	}
+	p := createPipeline(c, nil, findRepoLabels(cfg, repoFull), nil)
+	result, err := p.ProcessDraft(context.Background(), repoFull, draft)
+	if err != nil {
+		return fmt.Errorf("processing draft: %w", err)
+	}
+
+	if err := printCheck(repoFull, 0, draft, result); err != nil {
+		return err
+	}
+	return checkGate(gate, result)
+}
+
+// triageIssue records a single issue in the store and runs it through the
+// pipeline without notifications. It is shared by check and action.
+func triageIssue(ctx context.Context, c *components, owner, repo string, issue github.Issue) (*github.TriageResult, error) {
 	// Ensure repo and issue exist in store
 	repoRecord, err := c.Store.GetRepoByOwnerRepo(owner, repo)
-	if err != nil {
+	if errors.Is(err, store.ErrRepoNotFound) {
 		repoRecord, err = c.Store.CreateRepo(owner, repo)
 		if err != nil {
-			return fmt.Errorf("creating repo record: %w", err)
+			return nil, fmt.Errorf("creating repo record: %w", err)
 		}
+	} else if err != nil {
+		return nil, fmt.Errorf("looking up repo: %w", err)
+	}
+	if err := c.checkEmbeddingDims(ctx, repoRecord.ID, owner+"/"+repo); err != nil {
+		return nil, err
 	}
 
 	err = c.Store.UpsertIssue(&store.Issue{
@@ -105,36 +257,36 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		State:     issue.State,
 		Author:    issue.Author,
 		Labels:    issue.Labels,
+		Assignees: issue.Assignees,
+		Milestone: issue.Milestone,
 		CreatedAt: issue.CreatedAt,
 		UpdatedAt: issue.UpdatedAt,
 	})
 	if err != nil {
-		logger.Warn("failed to upsert issue", "error", err)
+		c.Logger.Warn("failed to upsert issue", "error", err)
 	}
 
 	// Run pipeline without notifier
 	repoFull := fmt.Sprintf("%s/%s", owner, repo)
-	labels := findRepoLabels(cfg, repoFull)
-	p := createPipeline(c, nil, labels)
+	labels := findRepoLabels(c.Config, repoFull)
+	p := createPipeline(c, nil, labels, nil)
 
 	result, err := p.ProcessSingleIssue(ctx, repoFull, issue)
-	if err != nil {
-		return fmt.Errorf("processing issue: %w", err)
+	if errors.Is(err, pipeline.ErrIncomplete) {
+		c.Logger.Warn("issue partly triaged", "error", err)
+		return result, nil
 	}
-
-	// Output results
-	if checkOutput == "json" {
-		return printCheckJSON(issue, result)
+	if err != nil {
+		return nil, fmt.Errorf("processing issue: %w", err)
 	}
-	return printCheckText(repoFull, number, issue, result)
+	return result, nil
 }
 
-// checkResultJSON is the JSON output structure for the check command.
+// checkResultJSON is the JSON output structure for the check and scan
+// commands: the issue followed by the versioned triage result.
 type checkResultJSON struct {
-	Issue      issueJSON       `json:"issue"`
-	Duplicates []duplicateJSON `json:"duplicates"`
-	Labels     []labelJSON     `json:"labels"`
-	Reasoning  string          `json:"reasoning"`
+	Issue issueJSON `json:"issue"`
+	github.TriageResultJSON
 }
 
 type issueJSON struct {
@@ -142,40 +294,21 @@ type issueJSON struct {
 	Title  string `json:"title"`
 }
 
-type duplicateJSON struct {
-	Number int     `json:"number"`
-	Score  float64 `json:"score"`
-}
-
 type labelJSON struct {
 	Name       string  `json:"name"`
 	Confidence float64 `json:"confidence"`
 }
 
-func printCheckJSON(issue github.Issue, result *github.TriageResult) error {
-	out := checkResultJSON{
-		Issue: issueJSON{
-			Number: issue.Number,
-			Title:  issue.Title,
-		},
-		Duplicates: make([]duplicateJSON, 0, len(result.Duplicates)),
-		Labels:     make([]labelJSON, 0, len(result.SuggestedLabels)),
-		Reasoning:  result.Reasoning,
-	}
-
-	for _, d := range result.Duplicates {
-		out.Duplicates = append(out.Duplicates, duplicateJSON{
-			Number: d.Number,
-			Score:  float64(d.Score),
-		})
+// newCheckResultJSON returns the JSON output for an issue's triage result.
+func newCheckResultJSON(issue github.Issue, result *github.TriageResult) checkResultJSON {
+	return checkResultJSON{
+		Issue:            issueJSON{Number: issue.Number, Title: issue.Title},
+		TriageResultJSON: result.JSON(),
 	}
+}
 
-	for _, l := range result.SuggestedLabels {
-		out.Labels = append(out.Labels, labelJSON{
-			Name:       l.Name,
-			Confidence: l.Confidence,
-		})
-	}
+func printCheckJSON(issue github.Issue, result *github.TriageResult) error {
+	out := newCheckResultJSON(issue, result)
 
 	data, err := json.MarshalIndent(out, "", "  ")
 	if err != nil {
@@ -186,10 +319,15 @@ func printCheckJSON(issue github.Issue, result *github.TriageResult) error {
 }
 
 func printCheckText(repoFull string, number int, issue github.Issue, result *github.TriageResult) error {
-	fmt.Printf("Issue: %s#%d\n", repoFull, number)
-	fmt.Printf("Title: %s\n", issue.Title)
-	fmt.Printf("State: %s\n", issue.State)
-	fmt.Printf("Author: %s\n", issue.Author)
+	if number == 0 {
+		fmt.Printf("Draft: %s\n", repoFull)
+		fmt.Printf("Title: %s\n", issue.Title)
+	} else {
+		fmt.Printf("Issue: %s#%d\n", repoFull, number)
+		fmt.Printf("Title: %s\n", issue.Title)
+		fmt.Printf("State: %s\n", issue.State)
+		fmt.Printf("Author: %s\n", issue.Author)
+	}
 	if len(issue.Labels) > 0 {
 		fmt.Printf("Current Labels: %s\n", strings.Join(issue.Labels, ", "))
 	}
@@ -202,14 +340,23 @@ func printCheckText(repoFull string, number int, issue github.Issue, result *git
 	} else {
 		for _, d := range result.Duplicates {
 			pct := int(math.Round(float64(d.Score) * 100))
-			fmt.Printf("  #%d — %d%% similar\n", d.Number, pct)
+			title := ""
+			if d.Title != "" {
+				title = " " + d.Title
+			}
+			if d.State != "" {
+				title += fmt.Sprintf(" (%s)", d.State)
+			}
+			fmt.Printf("  #%d%s — %d%% similar\n", d.Number, title, pct)
 		}
 	}
 	fmt.Println()
 
 	// Classification
 	fmt.Println("Classification:")
-	if len(result.SuggestedLabels) == 0 {
+	if result.NeedsHumanTriage {
+		fmt.Println("  Needs human triage (classifier confidence too low)")
+	} else if len(result.SuggestedLabels) == 0 {
 		fmt.Println("  No labels suggested")
 	} else {
 		for _, l := range result.SuggestedLabels {
@@ -222,5 +369,13 @@ func printCheckText(repoFull string, number int, issue github.Issue, result *git
 		fmt.Printf("\nReasoning: %s\n", result.Reasoning)
 	}
 
+	if len(result.PullRequests) > 0 {
+		fmt.Printf("\n%s\n", notify.FormatPullRequests(result.PullRequests))
+	}
+
+	if result.Transfer != nil {
+		fmt.Printf("\n%s\n", notify.FormatTransfer(result.Transfer))
+	}
+
 	return nil
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/jacklau/triage/internal/github"
@@ -25,30 +26,7 @@ func TestPrintCheckJSON(t *testing.T) {
 		Reasoning: "This is a bug report",
 	}
 
-	// Test that printCheckJSON produces valid JSON by checking the struct directly.
-	out := checkResultJSON{
-		Issue: issueJSON{
-			Number: issue.Number,
-			Title:  issue.Title,
-		},
-		Duplicates: make([]duplicateJSON, 0, len(result.Duplicates)),
-		Labels:     make([]labelJSON, 0, len(result.SuggestedLabels)),
-		Reasoning:  result.Reasoning,
-	}
-
-	for _, d := range result.Duplicates {
-		out.Duplicates = append(out.Duplicates, duplicateJSON{
-			Number: d.Number,
-			Score:  float64(d.Score),
-		})
-	}
-
-	for _, l := range result.SuggestedLabels {
-		out.Labels = append(out.Labels, labelJSON{
-			Name:       l.Name,
-			Confidence: l.Confidence,
-		})
-	}
+	out := newCheckResultJSON(issue, result)
 
 	data, err := json.Marshal(out)
 	if err != nil {
@@ -88,18 +66,13 @@ func TestPrintCheckJSON(t *testing.T) {
 	if parsed.Reasoning != "This is a bug report" {
 		t.Errorf("reasoning = %q, want %q", parsed.Reasoning, "This is a bug report")
 	}
+	if parsed.SchemaVersion != github.TriageResultSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", parsed.SchemaVersion, github.TriageResultSchemaVersion)
+	}
 }
 
 func TestCheckJSONEmptyResults(t *testing.T) {
-	out := checkResultJSON{
-		Issue: issueJSON{
-			Number: 1,
-			Title:  "No results",
-		},
-		Duplicates: make([]duplicateJSON, 0),
-		Labels:     make([]labelJSON, 0),
-		Reasoning:  "",
-	}
+	out := newCheckResultJSON(github.Issue{Number: 1, Title: "No results"}, &github.TriageResult{})
 
 	data, err := json.Marshal(out)
 	if err != nil {
@@ -119,3 +92,77 @@ func TestCheckJSONEmptyResults(t *testing.T) {
 		t.Errorf("labels = %s, want []", string(raw["labels"]))
 	}
 }
+
+func TestParseDraft(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantTitle string
+		wantBody  string
+		wantErr   bool
+	}{
+		{
+			name:      "plain title and body",
+			input:     "Crash on startup\n\nThe app panics when config is missing.\n",
+			wantTitle: "Crash on startup",
+			wantBody:  "The app panics when config is missing.",
+		},
+		{
+			name:      "markdown heading and leading blank lines",
+			input:     "\n\n# Crash on startup\r\nLine one\r\n\r\nLine two\r\n",
+			wantTitle: "Crash on startup",
+			wantBody:  "Line one\n\nLine two",
+		},
+		{
+			name:      "title only",
+			input:     "## Just a title",
+			wantTitle: "Just a title",
+		},
+		{
+			name:    "empty",
+			input:   " \n\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue, err := parseDraft(strings.NewReader(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if issue.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", issue.Title, tt.wantTitle)
+			}
+			if issue.Body != tt.wantBody {
+				t.Errorf("body = %q, want %q", issue.Body, tt.wantBody)
+			}
+			if issue.Number != 0 {
+				t.Errorf("expected draft number 0, got %d", issue.Number)
+			}
+		})
+	}
+}
+
+func TestCheckDraftFlagsMutuallyExclusive(t *testing.T) {
+	if checkCmd.Flags().Lookup("stdin") == nil || checkCmd.Flags().Lookup("file") == nil {
+		t.Fatal("expected --stdin and --file flags on check")
+	}
+	checkCmd.Flags().Set("stdin", "true")
+	checkCmd.Flags().Set("file", "draft.md")
+	defer func() {
+		checkCmd.Flags().Set("stdin", "false")
+		checkCmd.Flags().Set("file", "")
+		checkCmd.Flags().Lookup("stdin").Changed = false
+		checkCmd.Flags().Lookup("file").Changed = false
+	}()
+	if err := checkCmd.ValidateFlagGroups(); err == nil {
+		t.Error("expected --stdin and --file to be mutually exclusive")
+	}
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/classify"
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+var (
+	compareJudge  bool
+	compareOutput string
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <owner/repo#a> <owner/repo#b>",
+	Short: "Show how similar two issues are",
+	Long: `Compare prints the embedding similarity of two issues, reusing stored
+vectors when the issue content has not changed since it was last embedded.
+Nothing is written to the database.
+
+Use --judge to also ask the LLM whether the issues are duplicates and why,
+which helps when adjudicating borderline cases by hand.
+
+Issues are fetched from GitHub when github.auth is configured and read from
+the local database otherwise.`,
+	Example:           `  triage compare octocat/hello-world#12 octocat/hello-world#48 --judge`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeIssueRefs(2),
+	RunE:              runCompare,
+}
+
+func init() {
+	compareCmd.Flags().BoolVar(&compareJudge, "judge", false, "ask the LLM whether the issues are duplicates")
+	compareCmd.Flags().StringVar(&compareOutput, "output", "text", "output format: text or json")
+	registerFlagValues(compareCmd, "output", outputFormats)
+	rootCmd.AddCommand(compareCmd)
+}
+
+// compareIssue is one side of a comparison.
+type compareIssue struct {
+	Repo   string
+	RepoID int64 // 0 if the repo is not in the store
+	Issue  github.Issue
+}
+
+// compareResult is the outcome of comparing two issues.
+type compareResult struct {
+	A, B       compareIssue
+	Similarity float32
+	Judgement  *classify.DuplicateJudgement
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	type ref struct {
+		owner, repo string
+		number      int
+	}
+	var refs [2]ref
+	for i, arg := range args {
+		owner, repo, number, err := parseIssueRef(arg)
+		if err != nil {
+			return err
+		}
+		refs[i] = ref{owner, repo, number}
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	if c.Dedup == nil {
+		return notConfigured("embedding provider", "set providers.embedding in config")
+	}
+	if compareJudge && c.Classifier == nil {
+		return notConfigured("LLM provider", "set providers.llm in config")
+	}
+
+	ctx := context.Background()
+
+	var res compareResult
+	for i, r := range refs {
+		side, err := loadCompareIssue(ctx, c, r.owner, r.repo, r.number)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			res.A = side
+		} else {
+			res.B = side
+		}
+	}
+
+	res.Similarity, err = c.Dedup.Similarity(ctx, res.A.RepoID, res.A.Issue, res.B.RepoID, res.B.Issue)
+	if err != nil {
+		return fmt.Errorf("computing similarity: %w", err)
+	}
+
+	if compareJudge {
+		res.Judgement, err = c.Classifier.JudgeDuplicate(ctx, res.A.Repo, res.A.Issue, res.B.Issue)
+		if err != nil {
+			return fmt.Errorf("asking LLM: %w", err)
+		}
+	}
+
+	if compareOutput == "json" {
+		return printCompareJSON(cmd.OutOrStdout(), &res)
+	}
+	printCompareText(cmd.OutOrStdout(), &res, findRepoThreshold(cfg, res.A.Repo))
+	return nil
+}
+
+// loadCompareIssue fetches an issue from GitHub if a client is configured,
+// falling back to the stored copy.
+func loadCompareIssue(ctx context.Context, c *components, owner, repo string, number int) (compareIssue, error) {
+	side := compareIssue{Repo: owner + "/" + repo}
+	if r, err := c.Store.GetRepoByOwnerRepo(owner, repo); err == nil {
+		side.RepoID = r.ID
+	}
+
+	if c.GHClient != nil {
+		gh, _, err := c.GHClient.Issues.Get(ctx, owner, repo, number)
+		if err != nil {
+			return side, fmt.Errorf("fetching %s#%d: %w", side.Repo, number, err)
+		}
+		side.Issue = convertGHIssue(gh)
+		return side, nil
+	}
+
+	if side.RepoID == 0 {
+		return side, fmt.Errorf("%s is not in the database and github.auth is %w", side.Repo, config.ErrNotConfigured)
+	}
+	stored, err := c.Store.GetIssue(side.RepoID, number)
+	if err != nil {
+		return side, fmt.Errorf("%s#%d is not in the database and github.auth is %w", side.Repo, number, config.ErrNotConfigured)
+	}
+	side.Issue = convertStoredIssue(stored)
+	return side, nil
+}
+
+// convertStoredIssue converts a stored issue to the internal github.Issue type.
+func convertStoredIssue(s *store.Issue) github.Issue {
+	return github.Issue{
+		Number:    s.Number,
+		Title:     s.Title,
+		Body:      s.Body,
+		State:     s.State,
+		Author:    s.Author,
+		Labels:    s.Labels,
+		Assignees: s.Assignees,
+		Milestone: s.Milestone,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+func printCompareText(w io.Writer, res *compareResult, threshold float64) {
+	fmt.Fprintf(w, "A: %s#%d  %s\n", res.A.Repo, res.A.Issue.Number, res.A.Issue.Title)
+	fmt.Fprintf(w, "B: %s#%d  %s\n", res.B.Repo, res.B.Issue.Number, res.B.Issue.Title)
+	fmt.Fprintln(w)
+
+	pct := int(math.Round(float64(res.Similarity) * 100))
+	verdict := "below"
+	if float64(res.Similarity) >= threshold {
+		verdict = "at or above"
+	}
+	fmt.Fprintf(w, "Similarity: %d%% (%s the %.2f duplicate threshold)\n", pct, verdict, threshold)
+
+	if j := res.Judgement; j != nil {
+		answer := "not duplicates"
+		if j.Duplicate {
+			answer = "duplicates"
+		}
+		conf := int(math.Round(j.Confidence * 100))
+		fmt.Fprintf(w, "LLM verdict: %s (%d%% confidence)\n", answer, conf)
+		if j.Reasoning != "" {
+			fmt.Fprintf(w, "\nReasoning: %s\n", j.Reasoning)
+		}
+	}
+}
+
+// compareResultJSON is the JSON output structure for the compare command.
+type compareResultJSON struct {
+	A          compareIssueJSON             `json:"a"`
+	B          compareIssueJSON             `json:"b"`
+	Similarity float64                      `json:"similarity"`
+	Judgement  *classify.DuplicateJudgement `json:"judgement,omitempty"`
+}
+
+type compareIssueJSON struct {
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+func printCompareJSON(w io.Writer, res *compareResult) error {
+	out := compareResultJSON{
+		A:          compareIssueJSON{res.A.Repo, res.A.Issue.Number, res.A.Issue.Title},
+		B:          compareIssueJSON{res.B.Repo, res.B.Issue.Number, res.B.Issue.Title},
+		Similarity: float64(res.Similarity),
+		Judgement:  res.Judgement,
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
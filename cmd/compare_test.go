@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/classify"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func sampleCompareResult() *compareResult {
+	return &compareResult{
+		A:          compareIssue{Repo: "owner/repo", Issue: github.Issue{Number: 12, Title: "Crash on start"}},
+		B:          compareIssue{Repo: "owner/repo", Issue: github.Issue{Number: 48, Title: "Startup crash"}},
+		Similarity: 0.876,
+	}
+}
+
+func TestPrintCompareText(t *testing.T) {
+	res := sampleCompareResult()
+	var buf bytes.Buffer
+	printCompareText(&buf, res, 0.85)
+	out := buf.String()
+
+	for _, want := range []string{"A: owner/repo#12  Crash on start", "B: owner/repo#48  Startup crash", "Similarity: 88% (at or above the 0.85 duplicate threshold)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "LLM verdict") {
+		t.Error("expected no LLM verdict without --judge")
+	}
+
+	res.Judgement = &classify.DuplicateJudgement{Duplicate: false, Confidence: 0.7, Reasoning: "Different root causes"}
+	buf.Reset()
+	printCompareText(&buf, res, 0.9)
+	out = buf.String()
+	for _, want := range []string{"below the 0.90 duplicate threshold", "LLM verdict: not duplicates (70% confidence)", "Reasoning: Different root causes"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintCompareJSON(t *testing.T) {
+	res := sampleCompareResult()
+	var buf bytes.Buffer
+	if err := printCompareJSON(&buf, res); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := got["judgement"]; ok {
+		t.Error("expected judgement to be omitted when not requested")
+	}
+	a := got["a"].(map[string]any)
+	if a["number"].(float64) != 12 || a["repo"] != "owner/repo" {
+		t.Errorf("unexpected a: %v", a)
+	}
+}
+
+func TestLoadCompareIssueFromStore(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	defer db.Close()
+
+	repo, err := db.CreateRepo("owner", "repo")
+	if err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+	if err := db.UpsertIssue(&store.Issue{
+		RepoID:    repo.ID,
+		Number:    12,
+		Title:     "Stored title",
+		Body:      "Stored body",
+		State:     "open",
+		Labels:    []string{"bug"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("upserting issue: %v", err)
+	}
+
+	c := &components{Store: db}
+	side, err := loadCompareIssue(context.Background(), c, "owner", "repo", 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if side.RepoID != repo.ID || side.Issue.Title != "Stored title" || side.Issue.Body != "Stored body" {
+		t.Errorf("unexpected side: %+v", side)
+	}
+
+	if _, err := loadCompareIssue(context.Background(), c, "owner", "repo", 99); err == nil {
+		t.Error("expected error for an issue missing from the store")
+	}
+	if _, err := loadCompareIssue(context.Background(), c, "other", "repo", 1); err == nil {
+		t.Error("expected error for a repo missing from the store")
+	}
+}
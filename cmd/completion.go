@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// Shell completion scripts come from cobra's built-in completion command
+// (triage completion bash|zsh|fish|powershell). The functions below supply
+// the dynamic parts: repo arguments complete from the config and the
+// database, and enumerated flags complete their valid values.
+
+var (
+	notifyTargets = []string{"slack", "discord", "both"}
+	outputFormats = []string{"text", "json"}
+	issueStates   = []string{"open", "closed", "all"}
+)
+
+// knownRepos returns the owner/repo names listed in the config and tracked
+// in the database. Errors are ignored: completion must never fail loudly.
+// The database is only opened if it already exists, so completing does not
+// create one.
+func knownRepos() []string {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, rc := range cfg.Repos {
+		if rc.Name != "" && !config.IsRepoPattern(rc.Name) {
+			seen[rc.Name] = true
+		}
+	}
+
+	if _, err := os.Stat(cfg.Store.Path); err == nil {
+		if db, err := store.Open(cfg.Store.Path); err == nil {
+			if repos, err := db.ListRepos(); err == nil {
+				for _, r := range repos {
+					seen[r.Owner+"/"+r.RepoName] = true
+				}
+			}
+			db.Close()
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeRepos completes up to max owner/repo arguments (0 for no limit),
+// skipping repos already given.
+func completeRepos(max int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if max > 0 && len(args) >= max {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		used := make(map[string]bool, len(args))
+		for _, a := range args {
+			used[a] = true
+		}
+		var out []cobra.Completion
+		for _, name := range knownRepos() {
+			if !used[name] && strings.HasPrefix(name, toComplete) {
+				out = append(out, name)
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeIssueRefs completes up to max owner/repo#number arguments. Only
+// the owner/repo# prefix is suggested; the user types the number.
+func completeIssueRefs(max int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) >= max || strings.Contains(toComplete, "#") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var out []cobra.Completion
+		for _, name := range knownRepos() {
+			if strings.HasPrefix(name, toComplete) {
+				out = append(out, name+"#")
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// completeApplyArgs completes the issue reference, then the repo's
+// configured label names.
+func completeApplyArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeIssueRefs(1)(cmd, args, toComplete)
+	}
+	owner, repo, _, err := parseIssueRef(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	used := make(map[string]bool, len(args))
+	for _, a := range args[1:] {
+		used[a] = true
+	}
+	var out []cobra.Completion
+	for _, l := range findRepoLabels(cfg, owner+"/"+repo) {
+		if !used[l.Name] && strings.HasPrefix(l.Name, toComplete) {
+			out = append(out, cobra.CompletionWithDesc(l.Name, l.Description))
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerFlagValues registers fixed completions for a flag on cmd.
+func registerFlagValues(cmd *cobra.Command, flag string, values []string) {
+	_ = cmd.RegisterFlagCompletionFunc(flag, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+}
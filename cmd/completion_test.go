@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+// writeCompletionConfig writes a config listing one repo and a store that
+// tracks another, and returns the store path.
+func writeCompletionConfig(t *testing.T) string {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(dbPath)
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	if _, err := db.CreateRepo("acme", "tracked"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+	db.Close()
+
+	writeTestConfig(t, fmt.Sprintf(`store:
+  path: %s
+repos:
+  - name: acme/configured
+    labels:
+      - name: bug
+        description: Something is broken
+      - name: perf
+        description: Slow
+`, dbPath))
+	return dbPath
+}
+
+func TestKnownReposMergesConfigAndStore(t *testing.T) {
+	writeCompletionConfig(t)
+
+	got := knownRepos()
+	want := []string{"acme/configured", "acme/tracked"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("knownRepos() = %v, want %v", got, want)
+	}
+}
+
+func TestKnownReposDoesNotCreateStore(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "missing.db")
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\n", dbPath))
+
+	if got := knownRepos(); len(got) != 0 {
+		t.Errorf("expected no repos, got %v", got)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Error("expected completion not to create the database")
+	}
+}
+
+func TestCompleteRepos(t *testing.T) {
+	writeCompletionConfig(t)
+
+	got, directive := completeRepos(0)(watchCmd, []string{"acme/tracked"}, "acme/")
+	if want := []cobra.Completion{"acme/configured"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completions = %v, want %v (already-given repos are skipped)", got, want)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("unexpected directive %v", directive)
+	}
+
+	if got, _ := completeRepos(1)(scanCmd, []string{"acme/tracked"}, ""); len(got) != 0 {
+		t.Errorf("expected no completions past the arg limit, got %v", got)
+	}
+}
+
+func TestCompleteIssueRefs(t *testing.T) {
+	writeCompletionConfig(t)
+
+	got, directive := completeIssueRefs(1)(checkCmd, nil, "acme/c")
+	if want := []cobra.Completion{"acme/configured#"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completions = %v, want %v", got, want)
+	}
+	if directive&cobra.ShellCompDirectiveNoSpace == 0 {
+		t.Error("expected NoSpace so the issue number can be typed after #")
+	}
+
+	if got, _ := completeIssueRefs(1)(checkCmd, nil, "acme/configured#4"); len(got) != 0 {
+		t.Errorf("expected no completions once a number is being typed, got %v", got)
+	}
+}
+
+func TestCompleteApplyArgsLabels(t *testing.T) {
+	writeCompletionConfig(t)
+
+	got, _ := completeApplyArgs(applyCmd, []string{"acme/configured#4", "perf"}, "")
+	want := []cobra.Completion{cobra.CompletionWithDesc("bug", "Something is broken")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completions = %v, want %v", got, want)
+	}
+}
+
+func TestFlagValueCompletionsRegistered(t *testing.T) {
+	tests := []struct {
+		cmd  *cobra.Command
+		flag string
+	}{
+		{scanCmd, "notify"},
+		{scanCmd, "output"},
+		{scanCmd, "state"},
+		{watchCmd, "notify"},
+		{checkCmd, "output"},
+		{compareCmd, "output"},
+	}
+	for _, tt := range tests {
+		if _, ok := tt.cmd.GetFlagCompletionFunc(tt.flag); !ok {
+			t.Errorf("%s --%s has no completion function", tt.cmd.Name(), tt.flag)
+		}
+	}
+}
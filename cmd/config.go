@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate the configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Strictly validate the config file",
+	Long: `Validate parses the config file strictly, reporting unknown keys (which
+are otherwise silently ignored), and checks webhook URL formats, GitHub App
+settings, and that referenced key files exist.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print a JSON Schema for the config file",
+	Long: `Schema prints a JSON Schema describing the config file. Point your
+editor's YAML language server at it for completion and inline validation.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigSchema,
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd, configSchemaCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	path := configPath()
+	cfg, err := config.LoadStrict(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	problems := config.Check(cfg)
+	if len(problems) > 0 {
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "%s has %d problem(s):\n", path, len(problems))
+		for _, p := range problems {
+			fmt.Fprintf(out, "  - %v\n", p)
+		}
+		return fmt.Errorf("config validation failed")
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", path)
+	return nil
+}
+
+func runConfigSchema(cmd *cobra.Command, args []string) error {
+	data, err := config.JSONSchema()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
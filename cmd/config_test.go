@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestConfig(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := cfgFile
+	cfgFile = path
+	t.Cleanup(func() { cfgFile = old })
+}
+
+func TestConfigValidateValid(t *testing.T) {
+	writeTestConfig(t, "notify:\n  slack_webhook: https://hooks.slack.com/services/x\n")
+
+	var out bytes.Buffer
+	configValidateCmd.SetOut(&out)
+	defer configValidateCmd.SetOut(nil)
+
+	if err := runConfigValidate(configValidateCmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "is valid") {
+		t.Errorf("expected success message, got %q", out.String())
+	}
+}
+
+func TestConfigValidateUnknownKey(t *testing.T) {
+	writeTestConfig(t, "notify:\n  slack_hook: https://hooks.slack.com/services/x\n")
+
+	err := runConfigValidate(configValidateCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "slack_hook") {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestConfigValidateProblems(t *testing.T) {
+	writeTestConfig(t, "notify:\n  discord_webhook: not-a-url\n")
+
+	var out bytes.Buffer
+	configValidateCmd.SetOut(&out)
+	defer configValidateCmd.SetOut(nil)
+
+	if err := runConfigValidate(configValidateCmd, nil); err == nil {
+		t.Fatal("expected validation failure")
+	}
+	if !strings.Contains(out.String(), "notify.discord_webhook") {
+		t.Errorf("expected problem listing, got %q", out.String())
+	}
+}
+
+func TestConfigSchemaOutput(t *testing.T) {
+	var out bytes.Buffer
+	configSchemaCmd.SetOut(&out)
+	defer configSchemaCmd.SetOut(nil)
+
+	if err := runConfigSchema(configSchemaCmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(out.Bytes(), &schema); err != nil {
+		t.Fatalf("schema output is not JSON: %v", err)
+	}
+}
+
+func TestLoadConfigStrictFlag(t *testing.T) {
+	writeTestConfig(t, "defaults:\n  similarty_threshold: 0.9\n")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("expected unknown key to be a warning, got %v", err)
+	}
+	if len(cfg.UnknownKeys) != 1 {
+		t.Errorf("expected 1 unknown key, got %v", cfg.UnknownKeys)
+	}
+
+	old := strictConfig
+	strictConfig = true
+	t.Cleanup(func() { strictConfig = old })
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "similarty_threshold") {
+		t.Errorf("expected --strict-config to reject the unknown key, got %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/provider"
+	"github.com/jacklau/triage/internal/secrets"
+	"github.com/jacklau/triage/internal/store"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+var doctorSendTest bool
+
+// doctorCheckTimeout bounds each individual diagnostic.
+const doctorCheckTimeout = 20 * time.Second
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration and connectivity problems",
+	Long: `Doctor checks each external dependency in turn: that the config is valid,
+the database is writable, GitHub credentials work (and which repos the
+installation can see), and the embedding and LLM providers answer a tiny
+request. Each dependency is set up by its own check, so one that cannot be
+created, such as an unreadable GitHub key, does not stop the others.
+
+Webhooks are only contacted with --send-test, which posts a sample
+triage message to each configured channel.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorSendTest, "send-test", false, "post a test message to each configured webhook")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// errDoctorSkip marks a check that does not apply to the current config.
+var errDoctorSkip = errors.New("skipped")
+
+// doctorCheck is a single named diagnostic. Run returns a short detail
+// message on success, or an error (errDoctorSkip to skip).
+type doctorCheck struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// runDoctorChecks runs each check in order, printing a status line for each,
+// and returns the number of failures.
+func runDoctorChecks(ctx context.Context, out io.Writer, checks []doctorCheck) int {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	failed := 0
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+		detail, err := c.Run(checkCtx)
+		cancel()
+
+		status := "ok"
+		switch {
+		case errors.Is(err, errDoctorSkip):
+			status = "skip"
+		case err != nil:
+			status = "FAIL"
+			detail = err.Error()
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status, c.Name, detail)
+	}
+	w.Flush()
+	return failed
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	setupLogger()
+	out := cmd.OutOrStdout()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(out, "FAIL  config  %v\n", err)
+		return fmt.Errorf("config could not be loaded")
+	}
+
+	checks, closeStore := doctorChecks(cfg)
+	defer closeStore()
+	if failed := runDoctorChecks(context.Background(), out, checks); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// doctorChecks returns the checks for cfg. Each builds the dependency it
+// checks, so one that cannot be created, such as a GitHub key that does not
+// parse, fails its own check and the others still run. Secret references
+// are resolved first, as the others need their values. The returned func
+// closes the store once the checks have run.
+func doctorChecks(cfg *config.Config) ([]doctorCheck, func()) {
+	var db *store.DB
+	checks := []doctorCheck{
+		{"config", func(context.Context) (string, error) { return checkConfig(configPath(), cfg) }},
+		{"secrets", func(ctx context.Context) (string, error) { return checkSecrets(ctx, cfg) }},
+		{"store", func(context.Context) (string, error) {
+			var err error
+			db, err = store.Open(cfg.Store.Path)
+			if err != nil {
+				return "", fmt.Errorf("opening %s: %w", cfg.Store.Path, err)
+			}
+			// The github check looks for managed repos too
+			if managed, err := db.ListManagedRepos(); err == nil {
+				cfg.Repos = mergeManagedRepos(cfg.Repos, managed)
+			}
+			return checkStoreWritable(db, cfg.Store.Path)
+		}},
+		{"github", func(ctx context.Context) (string, error) {
+			gh, err := newGitHubClient(cfg.GitHub, "")
+			if err != nil {
+				return "", err
+			}
+			return checkGitHub(ctx, gh, cfg)
+		}},
+		{"embedding", func(ctx context.Context) (string, error) {
+			e, err := newEmbedder(cfg.Providers.Embedding)
+			if err != nil {
+				return "", err
+			}
+			return checkEmbedder(ctx, e)
+		}},
+		{"llm", func(ctx context.Context) (string, error) {
+			c, err := newCompleter(cfg.Providers.LLM)
+			if err != nil {
+				return "", err
+			}
+			return checkCompleter(ctx, c)
+		}},
+	}
+	for _, target := range configuredNotifiers(cfg) {
+		target := target
+		checks = append(checks, doctorCheck{target.name, func(ctx context.Context) (string, error) {
+			if !target.configured {
+				return "not configured", errDoctorSkip
+			}
+			n, err := createNotifier(cfg, target.name, nil)
+			if err != nil {
+				return "", err
+			}
+			return checkNotifier(ctx, n, doctorSendTest)
+		}})
+	}
+	return checks, func() {
+		if db != nil {
+			db.Close()
+		}
+	}
+}
+
+func checkConfig(path string, cfg *config.Config) (string, error) {
+	if problems := config.Check(cfg); len(problems) > 0 {
+		return "", errors.Join(problems...)
+	}
+	return path, nil
+}
+
+// checkSecrets resolves the config's secret references in place.
+func checkSecrets(ctx context.Context, cfg *config.Config) (string, error) {
+	if err := cfg.ResolveSecrets(ctx, secrets.NewResolver()); err != nil {
+		return "", err
+	}
+	if len(cfg.SecretRefs) == 0 {
+		return "no secret references", errDoctorSkip
+	}
+	return fmt.Sprintf("resolved %d reference(s)", len(cfg.SecretRefs)), nil
+}
+
+// checkStoreWritable makes a write that is rolled back to prove the
+// database accepts writes.
+func checkStoreWritable(st *store.DB, path string) (string, error) {
+	if err := st.CheckWritable(); err != nil {
+		return "", fmt.Errorf("%s is not writable: %w", path, err)
+	}
+	return path + " is writable", nil
+}
+
+// checkGitHub verifies the GitHub credentials. For app auth it lists the
+// repos visible to the installation and fails if a configured repo is not
+// among them.
+func checkGitHub(ctx context.Context, gh *gogithub.Client, cfg *config.Config) (string, error) {
+	if gh == nil {
+		return "github.auth not configured", errDoctorSkip
+	}
+
+	if cfg.GitHub.Auth != "app" {
+		user, _, err := gh.Users.Get(ctx, "")
+		if err != nil {
+			return "", fmt.Errorf("authenticating with token: %w", err)
+		}
+		return "authenticated as " + user.GetLogin(), nil
+	}
+
+	visible := make(map[string]bool)
+	opts := &gogithub.ListOptions{PerPage: 100}
+	for {
+		repos, resp, err := gh.Apps.ListRepos(ctx, opts)
+		if err != nil {
+			return "", fmt.Errorf("listing installation repos: %w", err)
+		}
+		for _, r := range repos.Repositories {
+			visible[strings.ToLower(r.GetFullName())] = true
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	var missing []string
+	for _, rc := range cfg.Repos {
+		if rc.Name == "" {
+			continue
+		}
+		if config.IsRepoPattern(rc.Name) {
+			if !anyRepoMatches(rc.Name, visible) {
+				missing = append(missing, rc.Name)
+			}
+		} else if !visible[strings.ToLower(rc.Name)] {
+			missing = append(missing, rc.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("installation cannot see configured repos: %s", strings.Join(missing, ", "))
+	}
+	return fmt.Sprintf("installation can see %d repo(s)", len(visible)), nil
+}
+
+// anyRepoMatches reports whether pattern matches one of the repo names in
+// visible.
+func anyRepoMatches(pattern string, visible map[string]bool) bool {
+	for name := range visible {
+		if config.MatchRepo(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkEmbedder(ctx context.Context, e provider.Embedder) (string, error) {
+	if e == nil {
+		return "no embedding provider configured", errDoctorSkip
+	}
+	vec, err := e.Embed(ctx, "triage doctor")
+	if err != nil {
+		return "", err
+	}
+	if len(vec) == 0 {
+		return "", fmt.Errorf("provider returned an empty embedding")
+	}
+	return fmt.Sprintf("returned %d dimensions", len(vec)), nil
+}
+
+func checkCompleter(ctx context.Context, c provider.Completer) (string, error) {
+	if c == nil {
+		return "no LLM provider configured", errDoctorSkip
+	}
+	if _, err := c.Complete(ctx, "Reply with the single word OK."); err != nil {
+		return "", err
+	}
+	return "responded", nil
+}
+
+func checkNotifier(ctx context.Context, n notify.Notifier, send bool) (string, error) {
+	if !send {
+		return "configured (use --send-test to post a message)", errDoctorSkip
+	}
+	if err := n.Notify(ctx, sampleTriageResult()); err != nil {
+		return "", err
+	}
+	return "test message delivered", nil
+}
+
+// sampleTriageResult returns a canned result used for test notifications.
+func sampleTriageResult() github.TriageResult {
+	return github.TriageResult{
+		Repo:        "octocat/hello-world",
+		IssueNumber: 1,
+		IssueTitle:  "Test message from triage",
+		Duplicates:  []github.DuplicateCandidate{{Number: 2, Score: 0.91}},
+		SuggestedLabels: []github.LabelSuggestion{
+			{Name: "bug", Confidence: 0.93},
+		},
+		Reasoning: "This is a test message from triage. If you can read it, notifications are working.",
+	}
+}
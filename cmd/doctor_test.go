@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+type fakeEmbedder struct {
+	vec []float32
+	err error
+}
+
+func (f fakeEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return f.vec, f.err
+}
+
+type recordingNotifier struct {
+	sent []github.TriageResult
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, result github.TriageResult) error {
+	r.sent = append(r.sent, result)
+	return nil
+}
+
+func TestRunDoctorChecks(t *testing.T) {
+	checks := []doctorCheck{
+		{"good", func(context.Context) (string, error) { return "fine", nil }},
+		{"bad", func(context.Context) (string, error) { return "", errors.New("broken") }},
+		{"n/a", func(context.Context) (string, error) { return "not configured", errDoctorSkip }},
+	}
+
+	var out bytes.Buffer
+	failed := runDoctorChecks(context.Background(), &out, checks)
+	if failed != 1 {
+		t.Errorf("expected 1 failure, got %d", failed)
+	}
+	got := out.String()
+	for _, want := range []string{"ok    good", "FAIL  bad", "broken", "skip  n/a", "not configured"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCheckStoreWritable(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := checkStoreWritable(db, ":memory:"); err != nil {
+		t.Errorf("expected writable store, got %v", err)
+	}
+	var n int
+	if err := db.Conn().QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'write_check'`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("expected the scratch table to be rolled back, got %d (%v)", n, err)
+	}
+}
+
+func TestCheckEmbedder(t *testing.T) {
+	if _, err := checkEmbedder(context.Background(), nil); !errors.Is(err, errDoctorSkip) {
+		t.Errorf("expected skip for nil embedder, got %v", err)
+	}
+	detail, err := checkEmbedder(context.Background(), fakeEmbedder{vec: make([]float32, 3)})
+	if err != nil || detail != "returned 3 dimensions" {
+		t.Errorf("unexpected result %q, %v", detail, err)
+	}
+	if _, err := checkEmbedder(context.Background(), fakeEmbedder{}); err == nil {
+		t.Error("expected error for empty embedding")
+	}
+}
+
+func TestCheckNotifierSendsOnlyWhenAsked(t *testing.T) {
+	n := &recordingNotifier{}
+	if _, err := checkNotifier(context.Background(), n, false); !errors.Is(err, errDoctorSkip) {
+		t.Errorf("expected skip without --send-test, got %v", err)
+	}
+	if len(n.sent) != 0 {
+		t.Fatal("should not send without --send-test")
+	}
+	if _, err := checkNotifier(context.Background(), n, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(n.sent) != 1 || n.sent[0].Reasoning == "" {
+		t.Errorf("expected one sample result sent, got %+v", n.sent)
+	}
+}
+
+func TestCheckGitHubAppMissingRepo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/installation/repositories" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"total_count":1,"repositories":[{"full_name":"acme/widgets"}]}`))
+	}))
+	defer srv.Close()
+
+	gh := gogithub.NewClient(nil)
+	gh.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	cfg := &config.Config{
+		GitHub: config.GitHubConfig{Auth: "app"},
+		Repos:  []config.RepoConfig{{Name: "acme/widgets"}},
+	}
+	detail, err := checkGitHub(context.Background(), gh, cfg)
+	if err != nil || detail != "installation can see 1 repo(s)" {
+		t.Errorf("unexpected result %q, %v", detail, err)
+	}
+
+	cfg.Repos = append(cfg.Repos, config.RepoConfig{Name: "acme/widg*"})
+	if _, err := checkGitHub(context.Background(), gh, cfg); err != nil {
+		t.Errorf("expected pattern matching a visible repo to pass, got %v", err)
+	}
+
+	cfg.Repos = append(cfg.Repos, config.RepoConfig{Name: "acme/secret"})
+	if _, err := checkGitHub(context.Background(), gh, cfg); err == nil || !strings.Contains(err.Error(), "acme/secret") {
+		t.Errorf("expected missing repo error, got %v", err)
+	}
+}
+
+func TestDoctorChecksRunPastSetupFailures(t *testing.T) {
+	cfg := &config.Config{
+		Store:  config.StoreConfig{Path: ":memory:"},
+		GitHub: config.GitHubConfig{Auth: "app", AppID: "not-a-number"},
+		Providers: config.ProvidersConfig{
+			Embedding: config.ProviderConfig{Type: "bogus"},
+		},
+	}
+	checks, closeStore := doctorChecks(cfg)
+	defer closeStore()
+
+	var out bytes.Buffer
+	runDoctorChecks(context.Background(), &out, checks)
+	got := out.String()
+	for _, want := range []string{"FAIL  github", "parsing app_id", "FAIL  embedding", "ok    store", "skip  llm"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
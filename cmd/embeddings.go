@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// embeddingProbeText is embedded once to learn the embedder's dimension.
+const embeddingProbeText = "triage dimension probe"
+
+// embeddingModelName identifies an embedding provider and model in the
+// store, e.g. "openai:text-embedding-3-small".
+func embeddingModelName(pc config.ProviderConfig) string {
+	return pc.Type + ":" + pc.Model
+}
+
+// embeddingDims returns the dimension of the configured embedder's vectors,
+// probing it on first use.
+func (c *components) embeddingDims(ctx context.Context) (int, error) {
+	if c.embedDims > 0 {
+		return c.embedDims, nil
+	}
+	vec, err := c.Embedder.Embed(ctx, embeddingProbeText)
+	if err != nil {
+		return 0, fmt.Errorf("probing embedder: %w", err)
+	}
+	if len(vec) == 0 {
+		return 0, fmt.Errorf("probing embedder: it returned an empty embedding")
+	}
+	c.embedDims = len(vec)
+	return c.embedDims, nil
+}
+
+// checkEmbeddingDims makes sure the configured embedder's vectors can be
+// compared with those stored for a repo, since vectors of different
+// dimensions cannot be. The embedder's dimension is recorded for repos
+// without one. It does nothing when no embedder is configured.
+func (c *components) checkEmbeddingDims(ctx context.Context, repoID int64, repoName string) error {
+	if c.Embedder == nil {
+		return nil
+	}
+	dims, err := c.embeddingDims(ctx)
+	if err != nil {
+		return err
+	}
+	current := store.EmbeddingInfo{Model: embeddingModelName(c.Config.Providers.Embedding), Dims: dims}
+
+	recorded, ok, err := c.Store.GetEmbeddingInfo(repoID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Repos embedded before dimensions were recorded are checked
+		// against a stored vector
+		stored, err := c.Store.StoredEmbeddingDims(repoID)
+		if err != nil {
+			return err
+		}
+		if stored > 0 && stored != dims {
+			return embeddingDimsError(repoName, store.EmbeddingInfo{Model: "an earlier model", Dims: stored}, current)
+		}
+		return c.Store.SetEmbeddingInfo(repoID, current)
+	}
+
+	if recorded.Dims != dims {
+		return embeddingDimsError(repoName, recorded, current)
+	}
+	if recorded.Model != current.Model {
+		c.Logger.Warn("embedding model changed; similarity scores mix models until the repo is re-embedded",
+			"repo", repoName, "stored_model", recorded.Model, "model", current.Model,
+			"hint", "triage reembed "+repoName)
+	}
+	return nil
+}
+
+func embeddingDimsError(repoName string, stored, current store.EmbeddingInfo) error {
+	return fmt.Errorf("%s has %d-dimension embeddings from %s, but %s returns %d dimensions; run \"triage reembed %s\" to recompute them",
+		repoName, stored.Dims, stored.Model, current.Model, current.Dims, repoName)
+}
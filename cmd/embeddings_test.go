@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// newEmbeddingComponents returns components with an in-memory store, a
+// stored repo, and an embedder returning vec.
+func newEmbeddingComponents(t *testing.T, vec []float32) (*components, *store.Repo) {
+	t.Helper()
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo, err := db.CreateRepo("owner", "repo")
+	if err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+	cfg := &config.Config{}
+	cfg.Providers.Embedding = config.ProviderConfig{Type: "ollama", Model: "nomic-embed-text"}
+	embedder := fakeEmbedder{vec: vec}
+	return &components{
+		Config:   cfg,
+		Store:    db,
+		Embedder: embedder,
+		Dedup:    dedup.NewEngine(embedder, db),
+		Logger:   slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+	}, repo
+}
+
+func TestCheckEmbeddingDims(t *testing.T) {
+	c, repo := newEmbeddingComponents(t, []float32{0.1, 0.2, 0.3})
+	ctx := context.Background()
+
+	// The first check records the embedder's dimension
+	if err := c.checkEmbeddingDims(ctx, repo.ID, "owner/repo"); err != nil {
+		t.Fatalf("checkEmbeddingDims: %v", err)
+	}
+	info, ok, _ := c.Store.GetEmbeddingInfo(repo.ID)
+	if !ok || info != (store.EmbeddingInfo{Model: "ollama:nomic-embed-text", Dims: 3}) {
+		t.Fatalf("recorded %+v, %v", info, ok)
+	}
+
+	// A new embedder with another dimension is refused
+	c2, _ := newEmbeddingComponents(t, []float32{0.1, 0.2})
+	c2.Store = c.Store
+	err := c2.checkEmbeddingDims(ctx, repo.ID, "owner/repo")
+	if err == nil || !strings.Contains(err.Error(), `run "triage reembed owner/repo"`) {
+		t.Errorf("expected a reembed error, got %v", err)
+	}
+}
+
+func TestCheckEmbeddingDims_UnrecordedRepo(t *testing.T) {
+	c, repo := newEmbeddingComponents(t, []float32{0.1, 0.2})
+	if err := c.Store.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: 1, Title: "t", State: "open"}); err != nil {
+		t.Fatalf("upserting issue: %v", err)
+	}
+	// Embedded before dimensions were recorded, with three dimensions
+	if err := c.Store.UpdateEmbeddingWithHash(repo.ID, 1, dedup.EncodeEmbedding([]float32{1, 2, 3}), "", "h"); err != nil {
+		t.Fatalf("storing embedding: %v", err)
+	}
+
+	if err := c.checkEmbeddingDims(context.Background(), repo.ID, "owner/repo"); err == nil {
+		t.Error("expected a dimension mismatch with the stored embedding")
+	}
+}
+
+func TestCheckEmbeddingDims_NoEmbedder(t *testing.T) {
+	c := &components{}
+	if err := c.checkEmbeddingDims(context.Background(), 1, "owner/repo"); err != nil {
+		t.Errorf("expected no check without an embedder, got %v", err)
+	}
+}
+
+func TestReembedRepo(t *testing.T) {
+	c, repo := newEmbeddingComponents(t, []float32{0.1, 0.2})
+	for _, n := range []int{1, 2} {
+		if err := c.Store.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: n, Title: "Issue", Body: "body", State: "open"}); err != nil {
+			t.Fatalf("upserting issue: %v", err)
+		}
+	}
+	if err := c.Store.UpdateEmbeddingWithHash(repo.ID, 1, dedup.EncodeEmbedding([]float32{1, 2, 3}), "", "h"); err != nil {
+		t.Fatalf("storing embedding: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := reembedRepo(context.Background(), c, repo, &out); err != nil {
+		t.Fatalf("reembedRepo: %v", err)
+	}
+	if !strings.Contains(out.String(), "Re-embedded 2 issues in owner/repo with ollama:nomic-embed-text (2 dimensions); cleared 1 old embeddings.") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+	if dims, _ := c.Store.StoredEmbeddingDims(repo.ID); dims != 2 {
+		t.Errorf("stored dimension = %d, want 2", dims)
+	}
+	if err := c.checkEmbeddingDims(context.Background(), repo.ID, "owner/repo"); err != nil {
+		t.Errorf("expected the re-embedded repo to pass the check, got %v", err)
+	}
+
+	// Running again has nothing left to do
+	out.Reset()
+	if err := reembedRepo(context.Background(), c, repo, &out); err != nil {
+		t.Fatalf("reembedRepo again: %v", err)
+	}
+	if !strings.Contains(out.String(), "Re-embedded 0 issues") {
+		t.Errorf("unexpected output on rerun: %s", out.String())
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+var (
+	evalSince      string
+	evalExperiment bool
+	evalDuplicates bool
+	evalOutput     string
+)
+
+var evalCmd = &cobra.Command{
+	Use:   "eval [owner/repo ...]",
+	Short: "Measure how often triage suggestions are approved",
+	Long: `Eval compares triage suggestions with the human decisions made on them
+with "triage apply pending": how many were approved, rejected, or are still
+undecided, and the share of decided suggestions that were approved.
+
+Use --experiment to break the numbers down by experiment variant, comparing
+the alternate prompt or model configured under experiment with the control.
+Only suggestions made while the experiment ran are counted.
+
+Use --duplicates instead to measure duplicate detection against the pairs
+people confirmed by closing issues with "Duplicate of #N", which scan learns
+from closed issues: how many pairs score above the similarity threshold, and
+the threshold that would catch 90% of them. --since does not apply.
+
+With no arguments, every repository in the store is evaluated.`,
+	Example:           `  triage eval octocat/hello-world --experiment --since 30d`,
+	ValidArgsFunction: completeRepos(0),
+	RunE:              runEval,
+}
+
+func init() {
+	evalCmd.Flags().StringVar(&evalSince, "since", "30d", "count suggestions made within this duration (e.g. 7d, 90d)")
+	evalCmd.Flags().BoolVar(&evalExperiment, "experiment", false, "compare experiment variants")
+	evalCmd.Flags().BoolVar(&evalDuplicates, "duplicates", false, "measure duplicate detection against confirmed duplicates")
+	evalCmd.MarkFlagsMutuallyExclusive("experiment", "duplicates")
+	evalCmd.Flags().StringVar(&evalOutput, "output", "text", "output format: text or json")
+	registerFlagValues(evalCmd, "output", outputFormats)
+	rootCmd.AddCommand(evalCmd)
+}
+
+// evalRow is the outcome of one repo's suggestions, or one variant's with
+// --experiment.
+type evalRow struct {
+	Repo      string `json:"repo"`
+	Variant   string `json:"variant,omitempty"`
+	Suggested int    `json:"suggested"`
+	Approved  int    `json:"approved"`
+	Rejected  int    `json:"rejected"`
+	Undecided int    `json:"undecided"`
+	// ApprovalRate is the share of decided suggestions approved, or nil if
+	// none were decided.
+	ApprovalRate *float64 `json:"approval_rate"`
+}
+
+func runEval(cmd *cobra.Command, args []string) error {
+	window, err := parseSinceDuration(evalSince)
+	if err != nil {
+		return err
+	}
+	if window <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	if evalOutput != "text" && evalOutput != "json" {
+		return fmt.Errorf("invalid --output %q: expected text or json", evalOutput)
+	}
+	for _, arg := range args {
+		if _, _, err := parseRepoArg(arg); err != nil {
+			return err
+		}
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	repos := args
+	if len(repos) == 0 {
+		all, err := c.Store.ListRepos()
+		if err != nil {
+			return fmt.Errorf("listing repos: %w", err)
+		}
+		for _, r := range all {
+			repos = append(repos, r.FullName())
+		}
+	}
+
+	var rows []evalRow
+	var dupRows []duplicateEvalRow
+	since := time.Now().Add(-window)
+	for _, name := range repos {
+		owner, repo, _ := parseRepoArg(name)
+		r, err := c.Store.GetRepoByOwnerRepo(owner, repo)
+		if errors.Is(err, store.ErrRepoNotFound) {
+			return fmt.Errorf("%s is not tracked", name)
+		}
+		if err != nil {
+			return fmt.Errorf("looking up repo: %w", err)
+		}
+		if evalDuplicates {
+			row, err := evalDuplicatePairs(c.Store, r.ID, name, findRepoThreshold(cfg, name))
+			if err != nil {
+				return err
+			}
+			dupRows = append(dupRows, row)
+			continue
+		}
+		stats, err := c.Store.GetDecisionStats(r.ID, since)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, evalRows(name, stats, evalExperiment)...)
+	}
+
+	if evalDuplicates {
+		if evalOutput == "json" {
+			if dupRows == nil {
+				dupRows = []duplicateEvalRow{}
+			}
+			return writeEvalJSON(cmd.OutOrStdout(), dupRows)
+		}
+		writeDuplicateEval(cmd.OutOrStdout(), dupRows)
+		return nil
+	}
+
+	if evalOutput == "json" {
+		if rows == nil {
+			rows = []evalRow{}
+		}
+		return writeEvalJSON(cmd.OutOrStdout(), rows)
+	}
+	writeEval(cmd.OutOrStdout(), rows, evalExperiment)
+	return nil
+}
+
+// writeEvalJSON prints rows as indented JSON.
+func writeEvalJSON(w io.Writer, rows any) error {
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// evalRows returns a repo's row from its per-variant stats, or with
+// byVariant one row per experiment variant.
+func evalRows(repo string, stats []store.DecisionStats, byVariant bool) []evalRow {
+	var rows []evalRow
+	total := evalRow{Repo: repo}
+	for _, s := range stats {
+		if byVariant {
+			if s.Variant == "" {
+				continue
+			}
+			row := evalRow{Repo: repo, Variant: s.Variant}
+			row.add(s)
+			rows = append(rows, row)
+			continue
+		}
+		total.add(s)
+	}
+	if !byVariant {
+		rows = append(rows, total)
+	}
+	return rows
+}
+
+// add counts s into r.
+func (r *evalRow) add(s store.DecisionStats) {
+	r.Suggested += s.Suggested
+	r.Approved += s.Approved
+	r.Rejected += s.Rejected
+	r.Undecided = r.Suggested - r.Approved - r.Rejected
+	r.ApprovalRate = nil
+	if decided := r.Approved + r.Rejected; decided > 0 {
+		rate := float64(r.Approved) / float64(decided)
+		r.ApprovalRate = &rate
+	}
+}
+
+// writeEval prints rows as a table.
+func writeEval(w io.Writer, rows []evalRow, byVariant bool) {
+	if len(rows) == 0 {
+		if byVariant {
+			fmt.Fprintf(w, "No experiment results recorded in the last %s.\n", evalSince)
+		} else {
+			fmt.Fprintln(w, "No repositories to evaluate.")
+		}
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if byVariant {
+		fmt.Fprintln(tw, "REPO\tVARIANT\tSUGGESTED\tAPPROVED\tREJECTED\tUNDECIDED\tAPPROVAL")
+	} else {
+		fmt.Fprintln(tw, "REPO\tSUGGESTED\tAPPROVED\tREJECTED\tUNDECIDED\tAPPROVAL")
+	}
+	for _, r := range rows {
+		rate := "-"
+		if r.ApprovalRate != nil {
+			rate = fmt.Sprintf("%.0f%%", *r.ApprovalRate*100)
+		}
+		if byVariant {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n", r.Repo, r.Variant, r.Suggested, r.Approved, r.Rejected, r.Undecided, rate)
+		} else {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", r.Repo, r.Suggested, r.Approved, r.Rejected, r.Undecided, rate)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// duplicateRecallTarget is the share of confirmed pairs the suggested
+// threshold detects.
+const duplicateRecallTarget = 0.9
+
+// duplicateEvalRow measures duplicate detection in a repo against the
+// duplicate pairs people confirmed.
+type duplicateEvalRow struct {
+	Repo  string `json:"repo"`
+	Pairs int    `json:"pairs"`
+	// Compared counts the pairs whose issues both have a stored embedding.
+	Compared  int     `json:"compared"`
+	Detected  int     `json:"detected"`
+	Threshold float64 `json:"threshold"`
+	// Recall is the share of compared pairs detected, or nil if none were
+	// compared.
+	Recall *float64 `json:"recall"`
+	// SuggestedThreshold is the highest threshold, to two decimals, that
+	// would detect duplicateRecallTarget of the compared pairs.
+	SuggestedThreshold *float64 `json:"suggested_threshold"`
+}
+
+// evalDuplicatePairs compares the stored embeddings of a repo's confirmed
+// duplicate pairs against threshold.
+func evalDuplicatePairs(db *store.DB, repoID int64, repo string, threshold float64) (duplicateEvalRow, error) {
+	row := duplicateEvalRow{Repo: repo, Threshold: threshold}
+	pairs, err := db.ListDuplicatePairs(repoID)
+	if err != nil {
+		return row, err
+	}
+	row.Pairs = len(pairs)
+
+	vectors := make(map[int][]float32)
+	vector := func(number int) []float32 {
+		if v, ok := vectors[number]; ok {
+			return v
+		}
+		var v []float32
+		if issue, err := db.GetIssue(repoID, number); err == nil && len(issue.Embedding) > 0 {
+			v = dedup.DecodeEmbedding(issue.Embedding)
+		}
+		vectors[number] = v
+		return v
+	}
+
+	var scores []float64
+	for _, p := range pairs {
+		a, b := vector(p.IssueNumber), vector(p.DuplicateOf)
+		if a == nil || b == nil {
+			continue
+		}
+		score, err := dedup.CosineSimilarity(a, b)
+		if err != nil {
+			continue
+		}
+		scores = append(scores, float64(score))
+		if float64(score) >= threshold {
+			row.Detected++
+		}
+	}
+	row.Compared = len(scores)
+	if row.Compared == 0 {
+		return row, nil
+	}
+	recall := float64(row.Detected) / float64(row.Compared)
+	row.Recall = &recall
+
+	sort.Float64s(scores)
+	suggested := math.Floor(scores[int(float64(len(scores))*(1-duplicateRecallTarget))]*100) / 100
+	row.SuggestedThreshold = &suggested
+	return row, nil
+}
+
+// writeDuplicateEval prints rows as a table.
+func writeDuplicateEval(w io.Writer, rows []duplicateEvalRow) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No repositories to evaluate.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tPAIRS\tCOMPARED\tDETECTED\tTHRESHOLD\tRECALL\tSUGGESTED")
+	for _, r := range rows {
+		recall, suggested := "-", "-"
+		if r.Recall != nil {
+			recall = fmt.Sprintf("%.0f%%", *r.Recall*100)
+		}
+		if r.SuggestedThreshold != nil {
+			suggested = fmt.Sprintf("%.2f", *r.SuggestedThreshold)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\t%s\t%s\n", r.Repo, r.Pairs, r.Compared, r.Detected, r.Threshold, recall, suggested)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nPairs come from issues closed with \"Duplicate of #N\", learned by scan --state closed.\n")
+	fmt.Fprintf(w, "SUGGESTED is the highest threshold detecting %.0f%% of the compared pairs.\n", duplicateRecallTarget*100)
+}
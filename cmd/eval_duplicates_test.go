@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestRunEvalDuplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vecs := map[int][]float32{
+		1: {1, 0, 0},
+		2: {0.95, 0.31, 0}, // 0.95 to #1
+		3: {0, 1, 0},
+		4: {0.6, 0.8, 0}, // 0.8 to #3
+		5: nil,
+	}
+	for n, vec := range vecs {
+		if err := db.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: n, Title: fmt.Sprintf("Issue %d", n), State: "closed"}); err != nil {
+			t.Fatal(err)
+		}
+		if vec != nil {
+			if err := db.UpdateEmbedding(repo.ID, n, dedup.EncodeEmbedding(vec), "nomic-embed-text"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, p := range [][2]int{{2, 1}, {4, 3}, {5, 1}} {
+		if _, err := db.AddDuplicatePair(store.DuplicatePair{RepoID: repo.ID, IssueNumber: p[0], DuplicateOf: p[1], Source: store.DuplicateSourceComment}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	row, err := evalDuplicatePairs(db, repo.ID, "org/repo", 0.85)
+	if err != nil {
+		t.Fatalf("evalDuplicatePairs: %v", err)
+	}
+	if row.Pairs != 3 || row.Compared != 2 || row.Detected != 1 || *row.Recall != 0.5 {
+		t.Errorf("unexpected row %+v", row)
+	}
+	if *row.SuggestedThreshold != 0.8 {
+		t.Errorf("suggested threshold = %v, want 0.8", *row.SuggestedThreshold)
+	}
+	db.Close()
+
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\ndefaults:\n  similarity_threshold: 0.9\n", path))
+	var out bytes.Buffer
+	evalCmd.SetOut(&out)
+	defer evalCmd.SetOut(nil)
+	evalDuplicates = true
+	defer func() { evalDuplicates, evalOutput = false, "text" }()
+
+	if err := runEval(evalCmd, []string{"org/repo"}); err != nil {
+		t.Fatalf("runEval: %v", err)
+	}
+	for _, want := range []string{
+		"REPO      PAIRS  COMPARED  DETECTED  THRESHOLD  RECALL  SUGGESTED",
+		"org/repo  3      2         1         0.90       50%     0.80",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	evalOutput = "json"
+	if err := runEval(evalCmd, []string{"org/repo"}); err != nil {
+		t.Fatalf("runEval: %v", err)
+	}
+	var rows []duplicateEvalRow
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(rows) != 1 || rows[0].Detected != 1 || rows[0].Threshold != 0.9 {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+}
+
+func TestEvalDuplicatePairsNone(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err := evalDuplicatePairs(db, repo.ID, "org/repo", 0.85)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Pairs != 0 || row.Recall != nil || row.SuggestedThreshold != nil {
+		t.Errorf("unexpected row %+v", row)
+	}
+	var out bytes.Buffer
+	writeDuplicateEval(&out, []duplicateEvalRow{row})
+	if !strings.Contains(out.String(), "org/repo  0      0         0         0.85       -       -") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestEvalRows(t *testing.T) {
+	stats := []store.DecisionStats{
+		{Variant: "", Suggested: 4, Approved: 1, Rejected: 1},
+		{Variant: "control", Suggested: 10, Approved: 6, Rejected: 2},
+		{Variant: "short-prompt", Suggested: 3},
+	}
+
+	rows := evalRows("org/repo", stats, false)
+	if len(rows) != 1 || rows[0].Suggested != 17 || rows[0].Undecided != 7 || *rows[0].ApprovalRate != 0.7 {
+		t.Errorf("unexpected total: %+v", rows)
+	}
+
+	rows = evalRows("org/repo", stats, true)
+	if len(rows) != 2 || rows[0].Variant != "control" || rows[1].Variant != "short-prompt" {
+		t.Fatalf("expected a row per variant, got %+v", rows)
+	}
+	if *rows[0].ApprovalRate != 0.75 || rows[1].ApprovalRate != nil || rows[1].Undecided != 3 {
+		t.Errorf("unexpected variant rows: %+v", rows)
+	}
+
+	var out bytes.Buffer
+	writeEval(&out, rows, true)
+	for _, want := range []string{
+		"REPO      VARIANT       SUGGESTED  APPROVED  REJECTED  UNDECIDED  APPROVAL",
+		"org/repo  control       10         6         2         2          75%",
+		"org/repo  short-prompt  3          0         0         3          -",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunEval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, variant := range []string{"control", "control", "b"} {
+		if err := db.LogTriageAction(&store.TriageLog{RepoID: repo.ID, IssueNumber: n + 1, Action: "triaged", Variant: variant}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logs, _ := db.GetTriageLog(repo.ID, 3)
+	if err := db.UpdateHumanDecision(logs[0].ID, store.DecisionApproved); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\n", path))
+
+	var out bytes.Buffer
+	evalCmd.SetOut(&out)
+	defer evalCmd.SetOut(nil)
+	evalExperiment = true
+	defer func() { evalExperiment = false }()
+
+	if err := runEval(evalCmd, nil); err != nil {
+		t.Fatalf("runEval: %v", err)
+	}
+	for _, want := range []string{"org/repo  b        1          1         0         0          100%", "org/repo  control  2"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := runEval(evalCmd, []string{"org/other"}); err == nil || !strings.Contains(err.Error(), "not tracked") {
+		t.Errorf("expected an untracked repo error, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+// Exit codes returned by the triage binary.
+const (
+	ExitOK      = 0 // success
+	ExitError   = 1 // the command failed
+	ExitGate    = 2 // the command ran, but a --fail-* quality gate tripped
+	ExitPartial = 3 // the command ran, but too many items failed
+)
+
+// exitError is an error that carries a specific process exit code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// ExitCode maps an error returned by Execute to a process exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return ExitError
+}
+
+// duplicateGate fails a command when results contain duplicates, so issue
+// quality checks can run in CI. Any trips on any duplicate candidate; Above
+// trips on a candidate scoring strictly above it (0 disables).
+type duplicateGate struct {
+	Any   bool
+	Above float64
+}
+
+func newDuplicateGate(any bool, above float64) (duplicateGate, error) {
+	if above < 0 || above > 1 {
+		return duplicateGate{}, fmt.Errorf("--fail-if-duplicate-above must be between 0 and 1, got %g", above)
+	}
+	return duplicateGate{Any: any, Above: above}, nil
+}
+
+// trips reports whether result fails the gate.
+func (g duplicateGate) trips(result *github.TriageResult) bool {
+	for _, d := range result.Duplicates {
+		if g.Any || (g.Above > 0 && float64(d.Score) > g.Above) {
+			return true
+		}
+	}
+	return false
+}
+
+// err returns the gate failure for n tripping issues, or nil if n is 0.
+func (g duplicateGate) err(n int) error {
+	if n == 0 {
+		return nil
+	}
+	cond := "potential duplicates"
+	if !g.Any {
+		cond = fmt.Sprintf("duplicates scoring above %.2f", g.Above)
+	}
+	return &exitError{code: ExitGate, err: fmt.Errorf("%d issue(s) have %s", n, cond)}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+func TestExitCode(t *testing.T) {
+	gateErr := duplicateGate{Any: true}.err(2)
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"plain error", errors.New("boom"), ExitError},
+		{"gate", gateErr, ExitGate},
+		{"wrapped gate", fmt.Errorf("scan: %w", gateErr), ExitGate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDuplicateGateValidates(t *testing.T) {
+	if _, err := newDuplicateGate(false, 1.5); err == nil {
+		t.Error("expected error for threshold above 1")
+	}
+	if _, err := newDuplicateGate(false, -0.1); err == nil {
+		t.Error("expected error for negative threshold")
+	}
+	if _, err := newDuplicateGate(true, 0.9); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDuplicateGateTrips(t *testing.T) {
+	withDup := &github.TriageResult{Duplicates: []github.DuplicateCandidate{{Number: 3, Score: 0.88}}}
+	noDup := &github.TriageResult{}
+
+	tests := []struct {
+		name   string
+		gate   duplicateGate
+		result *github.TriageResult
+		want   bool
+	}{
+		{"disabled", duplicateGate{}, withDup, false},
+		{"any with duplicate", duplicateGate{Any: true}, withDup, true},
+		{"any without duplicate", duplicateGate{Any: true}, noDup, false},
+		{"above, score higher", duplicateGate{Above: 0.85}, withDup, true},
+		{"above, score lower", duplicateGate{Above: 0.9}, withDup, false},
+		{"above, score equal", duplicateGate{Above: 0.88}, withDup, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.gate.trips(tt.result); got != tt.want {
+				t.Errorf("trips() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateGateErr(t *testing.T) {
+	g := duplicateGate{Above: 0.9}
+	if err := g.err(0); err != nil {
+		t.Errorf("expected nil error when nothing tripped, got %v", err)
+	}
+	err := g.err(3)
+	if err == nil || !strings.Contains(err.Error(), "3 issue(s) have duplicates scoring above 0.90") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if ExitCode(err) != ExitGate {
+		t.Errorf("expected exit code %d, got %d", ExitGate, ExitCode(err))
+	}
+}
@@ -21,6 +21,12 @@ func convertGHIssue(gh *gogithub.Issue) github.Issue {
 	for _, label := range gh.Labels {
 		issue.Labels = append(issue.Labels, label.GetName())
 	}
+	for _, a := range gh.Assignees {
+		issue.Assignees = append(issue.Assignees, a.GetLogin())
+	}
+	if gh.Milestone != nil {
+		issue.Milestone = gh.Milestone.GetTitle()
+	}
 	if gh.CreatedAt != nil {
 		issue.CreatedAt = gh.CreatedAt.Time
 	}
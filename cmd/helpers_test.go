@@ -23,6 +23,8 @@ func TestConvertGHIssue(t *testing.T) {
 				{Name: strPtr("bug")},
 				{Name: strPtr("help wanted")},
 			},
+			Assignees: []*gogithub.User{{Login: strPtr("hubot")}},
+			Milestone: &gogithub.Milestone{Title: strPtr("v1.0")},
 			CreatedAt: &created,
 			UpdatedAt: &updated,
 		}
@@ -53,6 +55,12 @@ func TestConvertGHIssue(t *testing.T) {
 		if issue.Labels[1] != "help wanted" {
 			t.Errorf("Labels[1] = %q, want %q", issue.Labels[1], "help wanted")
 		}
+		if len(issue.Assignees) != 1 || issue.Assignees[0] != "hubot" {
+			t.Errorf("Assignees = %v, want [hubot]", issue.Assignees)
+		}
+		if issue.Milestone != "v1.0" {
+			t.Errorf("Milestone = %q, want %q", issue.Milestone, "v1.0")
+		}
 		if !issue.CreatedAt.Equal(created.Time) {
 			t.Errorf("CreatedAt = %v, want %v", issue.CreatedAt, created.Time)
 		}
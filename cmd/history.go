@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+var historyOutput string
+
+var historyCmd = &cobra.Command{
+	Use:   "history <owner/repo#number | triage-id>",
+	Short: "Show the triage history of an issue",
+	Long: `History prints every triage log entry recorded for an issue, oldest first:
+when it was triaged, the duplicates and labels suggested, the reasoning, where
+the result was sent, and any human decision made with apply.
+
+Use it to audit how a decision evolved as the issue was edited or re-triaged.
+Use --output json to get structured JSON output.
+
+Each entry shows its triage ID, which is also in the footer of the
+notification it sent and on the log lines of its run. Pass a triage ID in
+place of the issue to show the history of the issue it triaged.`,
+	Example: `  triage history octocat/hello-world#42
+  triage history 5f2c9a0e1b7d4c36`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIssueRefs(1),
+	RunE:              runHistory,
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyOutput, "output", "text", "output format: text or json")
+	registerFlagValues(historyCmd, "output", outputFormats)
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	byTriageID := !strings.ContainsAny(args[0], "/#")
+	owner, repo, number, err := parseIssueRef(args[0])
+	if err != nil && !byTriageID {
+		return err
+	}
+	if historyOutput != "text" && historyOutput != "json" {
+		return fmt.Errorf("invalid --output %q: expected text or json", historyOutput)
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	if byTriageID {
+		entry, err := c.Store.GetTriageLogByTriageID(args[0])
+		if errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("no triage log entry with ID %s", args[0])
+		}
+		if err != nil {
+			return err
+		}
+		r, err := c.Store.GetRepo(entry.RepoID)
+		if err != nil {
+			return fmt.Errorf("looking up repo: %w", err)
+		}
+		owner, repo, number = r.Owner, r.RepoName, entry.IssueNumber
+	}
+
+	ref := fmt.Sprintf("%s/%s#%d", owner, repo, number)
+	repoRecord, err := c.Store.GetRepoByOwnerRepo(owner, repo)
+	if errors.Is(err, store.ErrRepoNotFound) {
+		return fmt.Errorf("no triage history for %s: repo is not tracked", ref)
+	}
+	if err != nil {
+		return fmt.Errorf("looking up repo: %w", err)
+	}
+
+	logs, err := c.Store.GetTriageLog(repoRecord.ID, number)
+	if err != nil {
+		return err
+	}
+	// GetTriageLog returns newest first; a history reads oldest first.
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].ID < logs[j].ID })
+
+	var title string
+	if issue, err := c.Store.GetIssue(repoRecord.ID, number); err == nil {
+		title = issue.Title
+	}
+
+	if historyOutput == "json" {
+		return writeHistoryJSON(cmd.OutOrStdout(), title, number, logs)
+	}
+	writeHistory(cmd.OutOrStdout(), ref, title, logs)
+	return nil
+}
+
+// writeHistory prints an issue's triage log entries as text, one block per
+// entry.
+func writeHistory(w io.Writer, ref, title string, logs []store.TriageLog) {
+	fmt.Fprintf(w, "Issue: %s\n", ref)
+	if title != "" {
+		fmt.Fprintf(w, "Title: %s\n", title)
+	}
+	if len(logs) == 0 {
+		fmt.Fprintln(w, "\nNo triage history recorded.")
+		return
+	}
+
+	for _, l := range logs {
+		fmt.Fprintf(w, "\n%s  %s\n", l.CreatedAt.Local().Format(time.DateTime), l.Action)
+		if l.DuplicateOf != "" {
+			fmt.Fprintf(w, "  Duplicate of: %s\n", l.DuplicateOf)
+		}
+		if l.SuggestedLabels != "" {
+			fmt.Fprintf(w, "  Labels: %s\n", formatLoggedLabels(l))
+		}
+		if l.Reasoning != "" {
+			fmt.Fprintf(w, "  Reasoning: %s\n", l.Reasoning)
+		}
+		if l.Variant != "" {
+			fmt.Fprintf(w, "  Variant: %s\n", l.Variant)
+		}
+		if l.TriageID != "" {
+			fmt.Fprintf(w, "  Triage ID: %s\n", l.TriageID)
+		}
+		if l.NotifiedVia != "" {
+			fmt.Fprintf(w, "  Notified: %s\n", l.NotifiedVia)
+		}
+		if l.HumanDecision != "" {
+			fmt.Fprintf(w, "  Decision: %s\n", l.HumanDecision)
+		}
+		if l.RawResponse != "" {
+			fmt.Fprintf(w, "  Raw response:\n    %s\n", strings.ReplaceAll(l.RawResponse, "\n", "\n    "))
+		}
+	}
+}
+
+// formatLoggedLabels lists a log entry's suggested labels with their
+// confidence, when it was recorded.
+func formatLoggedLabels(l store.TriageLog) string {
+	names := strings.Split(l.SuggestedLabels, ", ")
+	for i, name := range names {
+		if conf, ok := l.LabelConfidences[name]; ok {
+			names[i] = fmt.Sprintf("%s (%d%%)", name, int(math.Round(conf*100)))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// historyJSON is the JSON output structure for the history command.
+type historyJSON struct {
+	Issue   issueJSON          `json:"issue"`
+	Entries []historyEntryJSON `json:"entries"`
+}
+
+type historyEntryJSON struct {
+	Time          time.Time   `json:"time"`
+	Action        string      `json:"action"`
+	DuplicateOf   string      `json:"duplicate_of,omitempty"`
+	Labels        []labelJSON `json:"labels"`
+	Reasoning     string      `json:"reasoning,omitempty"`
+	NotifiedVia   string      `json:"notified_via,omitempty"`
+	HumanDecision string      `json:"human_decision,omitempty"`
+	RawResponse   string      `json:"raw_response,omitempty"`
+	Variant       string      `json:"variant,omitempty"`
+	TriageID      string      `json:"triage_id,omitempty"`
+}
+
+func writeHistoryJSON(w io.Writer, title string, number int, logs []store.TriageLog) error {
+	out := historyJSON{
+		Issue:   issueJSON{Number: number, Title: title},
+		Entries: make([]historyEntryJSON, 0, len(logs)),
+	}
+	for _, l := range logs {
+		entry := historyEntryJSON{
+			Time:          l.CreatedAt,
+			Action:        l.Action,
+			DuplicateOf:   l.DuplicateOf,
+			Labels:        []labelJSON{},
+			Reasoning:     l.Reasoning,
+			NotifiedVia:   l.NotifiedVia,
+			HumanDecision: l.HumanDecision,
+			RawResponse:   l.RawResponse,
+			Variant:       l.Variant,
+			TriageID:      l.TriageID,
+		}
+		if l.SuggestedLabels != "" {
+			for _, name := range strings.Split(l.SuggestedLabels, ", ") {
+				entry.Labels = append(entry.Labels, labelJSON{Name: name, Confidence: l.LabelConfidences[name]})
+			}
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestWriteHistory(t *testing.T) {
+	var out bytes.Buffer
+	writeHistory(&out, "org/repo#7", "Crash on start", []store.TriageLog{
+		{
+			Action:           "triaged",
+			SuggestedLabels:  "bug, question",
+			LabelConfidences: map[string]float64{"bug": 0.92},
+			Reasoning:        "Stack trace in body",
+			NotifiedVia:      "slack",
+			HumanDecision:    "rejected",
+		},
+		{Action: "duplicate", DuplicateOf: "#3", TriageID: "5f2c9a0e1b7d4c36"},
+		{Action: "triaged", Reasoning: "Failed to parse LLM response after retry", RawResponse: "Sure!\nIt's a bug."},
+	})
+
+	for _, want := range []string{
+		"Issue: org/repo#7\nTitle: Crash on start\n",
+		"triaged\n  Labels: bug (92%), question\n  Reasoning: Stack trace in body\n  Notified: slack\n  Decision: rejected\n",
+		"duplicate\n  Duplicate of: #3\n  Triage ID: 5f2c9a0e1b7d4c36\n",
+		"  Raw response:\n    Sure!\n    It's a bug.\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	writeHistory(&out, "org/repo#8", "", nil)
+	if !strings.Contains(out.String(), "No triage history recorded.") {
+		t.Errorf("unexpected output for empty history:\n%s", out.String())
+	}
+}
+
+func TestRunHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []store.TriageLog{
+		{RepoID: repo.ID, IssueNumber: 7, Action: "triaged", SuggestedLabels: "bug"},
+		{RepoID: repo.ID, IssueNumber: 7, Action: "duplicate", DuplicateOf: "#3", TriageID: "5f2c9a0e1b7d4c36"},
+		{RepoID: repo.ID, IssueNumber: 9, Action: "triaged"},
+	} {
+		if err := db.LogTriageAction(&l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\n", path))
+
+	var out bytes.Buffer
+	historyCmd.SetOut(&out)
+	defer historyCmd.SetOut(nil)
+	historyOutput = "json"
+	defer func() { historyOutput = "text" }()
+
+	if err := runHistory(historyCmd, []string{"org/repo#7"}); err != nil {
+		t.Fatalf("runHistory: %v", err)
+	}
+	var got historyJSON
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(got.Entries) != 2 || got.Entries[0].Action != "triaged" || got.Entries[1].Action != "duplicate" {
+		t.Errorf("expected two entries oldest first, got %+v", got.Entries)
+	}
+
+	if got.Entries[1].TriageID != "5f2c9a0e1b7d4c36" {
+		t.Errorf("expected the triage ID in the output, got %+v", got.Entries[1])
+	}
+
+	// A triage ID shows the history of the issue it triaged
+	out.Reset()
+	if err := runHistory(historyCmd, []string{"5f2c9a0e1b7d4c36"}); err != nil {
+		t.Fatalf("runHistory by triage ID: %v", err)
+	}
+	got = historyJSON{}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if got.Issue.Number != 7 || len(got.Entries) != 2 {
+		t.Errorf("expected the history of #7, got %+v", got)
+	}
+	if err := runHistory(historyCmd, []string{"0000000000000000"}); err == nil || !strings.Contains(err.Error(), "no triage log entry") {
+		t.Errorf("expected an unknown triage ID error, got %v", err)
+	}
+
+	if err := runHistory(historyCmd, []string{"other/repo#7"}); err == nil || !strings.Contains(err.Error(), "not tracked") {
+		t.Errorf("expected untracked repo error, got %v", err)
+	}
+}
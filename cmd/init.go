@@ -2,138 +2,390 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/buildinfo"
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/provider"
+
+	gogithub "github.com/google/go-github/v60/github"
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Interactive setup for Triage configuration",
-	Long:  `Creates a default configuration file with guided prompts.`,
-	RunE:  runInit,
+	Long: `Creates a configuration file with guided prompts.
+
+The wizard looks for a local Ollama server and offers it as the default
+provider, checks entered API keys with a small test call, and can import a
+repository's existing GitHub labels so classification uses them from the
+start.`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
 }
 
 func init() {
 	rootCmd.AddCommand(initCmd)
 }
 
-func runInit(cmd *cobra.Command, args []string) error {
-	reader := bufio.NewReader(os.Stdin)
+// Hooks for the wizard's network calls, replaced in tests.
+var (
+	initOllamaURL = provider.DefaultOllamaURL
+
+	// initValidateKey makes a minimal call to check an API key.
+	initValidateKey = validateProviderKey
+
+	// initGitHubClient builds the client used to import labels.
+	initGitHubClient = newInitGitHubClient
+)
+
+// initOptions are the answers gathered by the init wizard.
+type initOptions struct {
+	AppID          string
+	InstallationID string
+	KeyPath        string
+	UseToken       bool // authenticate with ${GITHUB_TOKEN}
+
+	EmbedProvider string
+	LLMProvider   string
+	EmbedAPIKey   string // "" writes the ${ENV} placeholder
+	LLMAPIKey     string
+	OllamaURL     string // written for ollama providers when non-default
 
-	fmt.Println("Welcome to Triage setup!")
-	fmt.Println("This will create a configuration file for you.")
-	fmt.Println()
+	SlackURL   string
+	DiscordURL string
 
-	configPath := cfgFile
-	if configPath == "" {
-		configPath = defaultConfigPath()
+	Repo   string
+	Labels []config.LabelConfig
+}
+
+// wizard reads answers from in and writes prompts to out.
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints label and returns the trimmed answer, or def if it is empty.
+func (w *wizard) ask(label, def string) string {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", label)
+	}
+	answer, _ := w.in.ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def
+	}
+	return answer
+}
+
+// confirm asks a yes/no question.
+func (w *wizard) confirm(label string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
 	}
+	answer := strings.ToLower(w.ask(label+" ["+hint+"]", ""))
+	if answer == "" {
+		return def
+	}
+	return answer == "y" || answer == "yes"
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	w := &wizard{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()}
+	ctx := context.Background()
+
+	fmt.Fprintln(w.out, "Welcome to Triage setup!")
+	fmt.Fprintln(w.out, "This will create a configuration file for you.")
+	fmt.Fprintln(w.out)
+
+	configPath := configPath()
 
 	// Check if config already exists
 	if _, err := os.Stat(configPath); err == nil {
-		fmt.Printf("Config file already exists at %s\n", configPath)
-		fmt.Print("Overwrite? [y/N]: ")
-		answer, _ := reader.ReadString('\n')
-		answer = strings.TrimSpace(strings.ToLower(answer))
-		if answer != "y" && answer != "yes" {
-			fmt.Println("Aborted.")
+		fmt.Fprintf(w.out, "Config file already exists at %s\n", configPath)
+		if !w.confirm("Overwrite?", false) {
+			fmt.Fprintln(w.out, "Aborted.")
 			return nil
 		}
 	}
 
-	// Gather inputs
-	fmt.Print("GitHub App ID (or press Enter to skip): ")
-	appID, _ := reader.ReadString('\n')
-	appID = strings.TrimSpace(appID)
-
-	fmt.Print("GitHub private key path (or press Enter to skip): ")
-	keyPath, _ := reader.ReadString('\n')
-	keyPath = strings.TrimSpace(keyPath)
+	var o initOptions
 
-	fmt.Print("Embedding provider (openai/ollama) [openai]: ")
-	embedProvider, _ := reader.ReadString('\n')
-	embedProvider = strings.TrimSpace(embedProvider)
-	if embedProvider == "" {
-		embedProvider = "openai"
+	// GitHub access
+	o.AppID = w.ask("GitHub App ID (or press Enter to skip)", "")
+	if o.AppID != "" {
+		o.InstallationID = w.ask("GitHub App installation ID", "")
+		o.KeyPath = w.ask("GitHub private key path", "")
+	} else if os.Getenv("GITHUB_TOKEN") != "" {
+		o.UseToken = w.confirm("Use $GITHUB_TOKEN for GitHub access?", true)
 	}
 
-	fmt.Print("LLM provider (openai/ollama/anthropic) [openai]: ")
-	llmProvider, _ := reader.ReadString('\n')
-	llmProvider = strings.TrimSpace(llmProvider)
-	if llmProvider == "" {
-		llmProvider = "openai"
+	// Providers, preferring a local Ollama server when one is running
+	embedDefault, llmDefault := "openai", "openai"
+	models, err := provider.ProbeOllama(ctx, initOllamaURL)
+	if err == nil {
+		fmt.Fprintf(w.out, "Found Ollama at %s", initOllamaURL)
+		if len(models) > 0 {
+			fmt.Fprintf(w.out, " (models: %s)", strings.Join(models, ", "))
+		}
+		fmt.Fprintln(w.out)
+		embedDefault, llmDefault = "ollama", "ollama"
+		if initOllamaURL != provider.DefaultOllamaURL {
+			o.OllamaURL = initOllamaURL
+		}
+	}
+	o.EmbedProvider = w.ask("Embedding provider (openai/ollama)", embedDefault)
+	o.LLMProvider = w.ask("LLM provider (openai/ollama/anthropic)", llmDefault)
+	if o.EmbedProvider == "ollama" || o.LLMProvider == "ollama" {
+		warnMissingOllamaModels(w.out, &o, models)
 	}
 
-	fmt.Print("Slack webhook URL (or press Enter to skip): ")
-	slackURL, _ := reader.ReadString('\n')
-	slackURL = strings.TrimSpace(slackURL)
+	// API keys, checked with a test call
+	o.EmbedAPIKey = askAPIKey(ctx, w, o.EmbedProvider, "")
+	o.LLMAPIKey = askAPIKey(ctx, w, o.LLMProvider, keyIfSame(o.EmbedProvider, o.LLMProvider, o.EmbedAPIKey))
 
-	fmt.Print("Discord webhook URL (or press Enter to skip): ")
-	discordURL, _ := reader.ReadString('\n')
-	discordURL = strings.TrimSpace(discordURL)
+	// Notifications
+	o.SlackURL = w.ask("Slack webhook URL (or press Enter to skip)", "")
+	o.DiscordURL = w.ask("Discord webhook URL (or press Enter to skip)", "")
+
+	// First repository, with its existing labels
+	o.Repo = w.ask("Repository to triage, owner/repo (or press Enter to skip)", "")
+	if o.Repo != "" {
+		if owner, repo, err := parseRepoArg(o.Repo); err != nil {
+			fmt.Fprintf(w.out, "  %v; skipping\n", err)
+			o.Repo = ""
+		} else if labels, err := importRepoLabels(ctx, &o, owner, repo); err != nil {
+			fmt.Fprintf(w.out, "  Could not fetch labels: %v\n  Default labels will be used; edit repos[].labels later.\n", err)
+		} else {
+			o.Labels = labels
+			fmt.Fprintf(w.out, "  Imported %d labels from %s\n", len(labels), o.Repo)
+		}
+	}
 
 	// Build config
-	config := buildConfigYAML(appID, keyPath, embedProvider, llmProvider, slackURL, discordURL)
+	cfgYAML := buildConfigYAML(o)
 
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
 		return fmt.Errorf("creating config directory: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+	if err := os.WriteFile(configPath, []byte(cfgYAML), 0o600); err != nil {
 		return fmt.Errorf("writing config file: %w", err)
 	}
 
-	fmt.Printf("\nConfig written to %s\n", configPath)
-	fmt.Println("Edit the file to add API keys and customize settings.")
+	fmt.Fprintf(w.out, "\nConfig written to %s\n", configPath)
+	fmt.Fprintln(w.out, "Run 'triage doctor' to check the setup.")
 	return nil
 }
 
-func buildConfigYAML(appID, keyPath, embedProvider, llmProvider, slackURL, discordURL string) string {
+// providerKeyEnv returns the environment variable holding the API key for a
+// provider type, or "" if the provider does not use one.
+func providerKeyEnv(providerType string) string {
+	switch providerType {
+	case "openai":
+		return "OPENAI_API_KEY"
+	case "anthropic":
+		return "ANTHROPIC_API_KEY"
+	default:
+		return ""
+	}
+}
+
+// keyIfSame returns key when both providers are the same type, so the key is
+// not asked for twice.
+func keyIfSame(a, b, key string) string {
+	if a == b {
+		return key
+	}
+	return ""
+}
+
+// askAPIKey asks for the API key of a provider, offering the environment
+// variable as the default, and checks it with a test call. It returns the
+// key to write into the config, or "" to reference the environment variable.
+// If known is non-empty the key was already entered for the other provider.
+func askAPIKey(ctx context.Context, w *wizard, providerType, known string) string {
+	env := providerKeyEnv(providerType)
+	if env == "" {
+		return ""
+	}
+	if known != "" {
+		return known
+	}
+
+	entered := w.ask(fmt.Sprintf("%s API key (or press Enter to use $%s)", providerType, env), "")
+	key := entered
+	if key == "" {
+		key = os.Getenv(env)
+	}
+	if key == "" {
+		fmt.Fprintf(w.out, "  $%s is not set; set it before running triage.\n", env)
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	if err := initValidateKey(ctx, providerType, key); err != nil {
+		fmt.Fprintf(w.out, "  ✗ %s key check failed: %v\n", providerType, err)
+	} else {
+		fmt.Fprintf(w.out, "  ✓ %s key works\n", providerType)
+	}
+	return entered
+}
+
+// validateProviderKey makes the smallest call each provider supports with key.
+func validateProviderKey(ctx context.Context, providerType, key string) error {
+	switch providerType {
+	case "openai":
+		_, err := provider.NewOpenAIEmbedder(key, "").Embed(ctx, "triage init")
+		return err
+	case "anthropic":
+		_, err := provider.NewAnthropicCompleter(key, "").Complete(ctx, "Reply with OK.")
+		return err
+	default:
+		return nil
+	}
+}
+
+// warnMissingOllamaModels points out default Ollama models that are not
+// pulled yet.
+func warnMissingOllamaModels(out io.Writer, o *initOptions, installed []string) {
+	have := make(map[string]bool, len(installed))
+	for _, m := range installed {
+		have[m] = true
+		have[strings.TrimSuffix(m, ":latest")] = true
+	}
+	var wanted []string
+	if o.EmbedProvider == "ollama" {
+		model, _ := embeddingProviderDefaults("ollama")
+		wanted = append(wanted, model)
+	}
+	if o.LLMProvider == "ollama" {
+		model, _ := llmProviderDefaults("ollama")
+		wanted = append(wanted, model)
+	}
+	for _, m := range wanted {
+		if !have[m] {
+			fmt.Fprintf(out, "  Model %s is not installed; run 'ollama pull %s'\n", m, m)
+		}
+	}
+}
+
+// newInitGitHubClient builds a GitHub client from the wizard's answers: the
+// GitHub App if fully configured, $GITHUB_TOKEN if chosen, and otherwise an
+// unauthenticated client, which can read public repositories.
+func newInitGitHubClient(o *initOptions) (*gogithub.Client, error) {
+	if o.AppID != "" && o.InstallationID != "" && o.KeyPath != "" {
+		appID, err := strconv.ParseInt(o.AppID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing app ID: %w", err)
+		}
+		installID, err := strconv.ParseInt(o.InstallationID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing installation ID: %w", err)
+		}
+		return github.NewGitHubClient(appID, installID, nil, o.KeyPath)
+	}
+	if o.UseToken {
+		return github.NewTokenClient(os.Getenv("GITHUB_TOKEN")), nil
+	}
+	client := gogithub.NewClient(nil)
+	client.UserAgent = buildinfo.UserAgent()
+	return client, nil
+}
+
+// importRepoLabels fetches all labels defined on a repository.
+func importRepoLabels(ctx context.Context, o *initOptions, owner, repo string) ([]config.LabelConfig, error) {
+	client, err := initGitHubClient(o)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []config.LabelConfig
+	opts := &gogithub.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range page {
+			labels = append(labels, config.LabelConfig{Name: l.GetName(), Description: l.GetDescription()})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return labels, nil
+}
+
+func buildConfigYAML(o initOptions) string {
 	var b strings.Builder
 
 	b.WriteString("# Triage configuration\n")
 	b.WriteString("# See documentation for all available options.\n\n")
 
 	b.WriteString("github:\n")
-	if appID != "" {
-		b.WriteString(fmt.Sprintf("  app_id: %s\n", appID))
-	} else {
+	switch {
+	case o.AppID != "":
+		b.WriteString("  auth: app\n")
+		b.WriteString(fmt.Sprintf("  app_id: %s\n", yamlString(o.AppID)))
+	case o.UseToken:
+		b.WriteString("  auth: token\n")
+		b.WriteString("  token: ${GITHUB_TOKEN}\n")
+	default:
+		b.WriteString("  # auth: app\n")
 		b.WriteString("  # app_id: YOUR_APP_ID\n")
 	}
-	if keyPath != "" {
-		b.WriteString(fmt.Sprintf("  private_key_path: %s\n", keyPath))
-	} else {
-		b.WriteString("  # private_key_path: /path/to/private-key.pem\n")
+	if o.AppID != "" || !o.UseToken {
+		if o.InstallationID != "" {
+			b.WriteString(fmt.Sprintf("  installation_id: %s\n", yamlString(o.InstallationID)))
+		} else {
+			b.WriteString("  # installation_id: YOUR_INSTALLATION_ID\n")
+		}
+		if o.KeyPath != "" {
+			b.WriteString(fmt.Sprintf("  private_key_path: %s\n", o.KeyPath))
+		} else {
+			b.WriteString("  # private_key_path: /path/to/private-key.pem\n")
+		}
 	}
-	b.WriteString("  # installation_id: YOUR_INSTALLATION_ID\n")
 	b.WriteString("\n")
 
 	b.WriteString("providers:\n")
 	b.WriteString("  embedding:\n")
-	b.WriteString(fmt.Sprintf("    type: %s\n", embedProvider))
-	embedModel, embedAPIKey := embeddingProviderDefaults(embedProvider)
+	b.WriteString(fmt.Sprintf("    type: %s\n", o.EmbedProvider))
+	embedModel, embedAPIKey := embeddingProviderDefaults(o.EmbedProvider)
 	b.WriteString(fmt.Sprintf("    model: %s\n", embedModel))
-	b.WriteString(fmt.Sprintf("    api_key: %s\n", embedAPIKey))
+	writeProviderAccess(&b, o.EmbedProvider, embedAPIKey, o.EmbedAPIKey, o.OllamaURL)
 	b.WriteString("  llm:\n")
-	b.WriteString(fmt.Sprintf("    type: %s\n", llmProvider))
-	llmModel, llmAPIKey := llmProviderDefaults(llmProvider)
+	b.WriteString(fmt.Sprintf("    type: %s\n", o.LLMProvider))
+	llmModel, llmAPIKey := llmProviderDefaults(o.LLMProvider)
 	b.WriteString(fmt.Sprintf("    model: %s\n", llmModel))
-	b.WriteString(fmt.Sprintf("    api_key: %s\n", llmAPIKey))
+	writeProviderAccess(&b, o.LLMProvider, llmAPIKey, o.LLMAPIKey, o.OllamaURL)
 	b.WriteString("\n")
 
 	b.WriteString("notify:\n")
-	if slackURL != "" {
-		b.WriteString(fmt.Sprintf("  slack_webhook: %s\n", slackURL))
+	if o.SlackURL != "" {
+		b.WriteString(fmt.Sprintf("  slack_webhook: %s\n", o.SlackURL))
 	} else {
 		b.WriteString("  # slack_webhook: https://hooks.slack.com/services/...\n")
 	}
-	if discordURL != "" {
-		b.WriteString(fmt.Sprintf("  discord_webhook: %s\n", discordURL))
+	if o.DiscordURL != "" {
+		b.WriteString(fmt.Sprintf("  discord_webhook: %s\n", o.DiscordURL))
 	} else {
 		b.WriteString("  # discord_webhook: https://discord.com/api/webhooks/...\n")
 	}
@@ -151,9 +403,49 @@ func buildConfigYAML(appID, keyPath, embedProvider, llmProvider, slackURL, disco
 	b.WriteString("store:\n")
 	b.WriteString("  path: ~/.triage/triage.db\n")
 
+	if o.Repo != "" {
+		b.WriteString("\nrepos:\n")
+		b.WriteString(fmt.Sprintf("  - name: %s\n", o.Repo))
+		if len(o.Labels) > 0 {
+			b.WriteString("    labels:\n")
+			for _, l := range o.Labels {
+				b.WriteString(fmt.Sprintf("      - name: %s\n", yamlString(l.Name)))
+				if l.Description != "" {
+					b.WriteString(fmt.Sprintf("        description: %s\n", yamlString(l.Description)))
+				}
+			}
+		}
+	}
+
 	return b.String()
 }
 
+// writeProviderAccess writes the api_key (or Ollama url) lines of a provider.
+func writeProviderAccess(b *strings.Builder, providerType, placeholder, key, ollamaURL string) {
+	if providerType == "ollama" && ollamaURL != "" {
+		b.WriteString(fmt.Sprintf("    url: %s\n", ollamaURL))
+	}
+	if key != "" {
+		placeholder = yamlString(key)
+	}
+	b.WriteString(fmt.Sprintf("    api_key: %s\n", placeholder))
+}
+
+// yamlString quotes s for YAML when needed, such as label names with colons
+// or emoji.
+func yamlString(s string) string {
+	if s == "" || strings.ContainsAny(s, ":#{}[],&*!|>'\"%@`") || strings.TrimSpace(s) != s ||
+		strings.HasPrefix(s, "-") || strings.HasPrefix(s, "?") {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if r > 127 {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
 // embeddingProviderDefaults returns the default model and api_key placeholder
 // for the given embedding provider type.
 func embeddingProviderDefaults(provider string) (model, apiKey string) {
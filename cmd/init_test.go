@@ -1,12 +1,23 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/jacklau/triage/internal/config"
+
+	gogithub "github.com/google/go-github/v60/github"
 )
 
 func TestBuildConfigYAML_OpenAI(t *testing.T) {
-	result := buildConfigYAML("", "", "openai", "openai", "", "")
+	result := buildConfigYAML(initOptions{EmbedProvider: "openai", LLMProvider: "openai"})
 
 	if !strings.Contains(result, "model: text-embedding-3-small") {
 		t.Error("expected OpenAI embedding model 'text-embedding-3-small' in config")
@@ -21,7 +32,7 @@ func TestBuildConfigYAML_OpenAI(t *testing.T) {
 }
 
 func TestBuildConfigYAML_Anthropic(t *testing.T) {
-	result := buildConfigYAML("", "", "openai", "anthropic", "", "")
+	result := buildConfigYAML(initOptions{EmbedProvider: "openai", LLMProvider: "anthropic"})
 
 	if !strings.Contains(result, "type: anthropic") {
 		t.Error("expected 'type: anthropic' in config")
@@ -39,7 +50,7 @@ func TestBuildConfigYAML_Anthropic(t *testing.T) {
 }
 
 func TestBuildConfigYAML_Ollama(t *testing.T) {
-	result := buildConfigYAML("", "", "ollama", "ollama", "", "")
+	result := buildConfigYAML(initOptions{EmbedProvider: "ollama", LLMProvider: "ollama"})
 
 	if !strings.Contains(result, "model: nomic-embed-text") {
 		t.Errorf("expected Ollama embedding model 'nomic-embed-text' in config, got:\n%s", result)
@@ -57,7 +68,7 @@ func TestBuildConfigYAML_Ollama(t *testing.T) {
 }
 
 func TestBuildConfigYAML_WithGitHub(t *testing.T) {
-	result := buildConfigYAML("12345", "/path/to/key.pem", "openai", "openai", "", "")
+	result := buildConfigYAML(initOptions{AppID: "12345", KeyPath: "/path/to/key.pem", EmbedProvider: "openai", LLMProvider: "openai"})
 
 	if !strings.Contains(result, "app_id: 12345") {
 		t.Error("expected app_id in config")
@@ -68,7 +79,7 @@ func TestBuildConfigYAML_WithGitHub(t *testing.T) {
 }
 
 func TestBuildConfigYAML_WithWebhooks(t *testing.T) {
-	result := buildConfigYAML("", "", "openai", "openai", "https://hooks.slack.com/test", "https://discord.com/api/webhooks/test")
+	result := buildConfigYAML(initOptions{EmbedProvider: "openai", LLMProvider: "openai", SlackURL: "https://hooks.slack.com/test", DiscordURL: "https://discord.com/api/webhooks/test"})
 
 	if !strings.Contains(result, "slack_webhook: https://hooks.slack.com/test") {
 		t.Error("expected slack_webhook in config")
@@ -126,3 +137,164 @@ func TestLLMProviderDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildConfigYAML_TokenAndLabels(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	result := buildConfigYAML(initOptions{
+		UseToken:      true,
+		EmbedProvider: "openai",
+		LLMProvider:   "openai",
+		LLMAPIKey:     "sk-entered",
+		Repo:          "org/repo",
+		Labels: []config.LabelConfig{
+			{Name: "bug", Description: "Something isn't working"},
+			{Name: "area: ui"},
+			{Name: "🚀 feature"},
+		},
+	})
+
+	if !strings.Contains(result, "auth: token") || !strings.Contains(result, "token: ${GITHUB_TOKEN}") {
+		t.Errorf("expected token auth, got:\n%s", result)
+	}
+	if strings.Contains(result, "installation_id") {
+		t.Errorf("token config should not mention installation_id, got:\n%s", result)
+	}
+
+	cfg, err := config.ParseStrict([]byte(result))
+	if err != nil {
+		t.Fatalf("generated config does not parse: %v\n%s", err, result)
+	}
+	if cfg.Providers.LLM.APIKey != "sk-entered" {
+		t.Errorf("LLM api_key = %q, want entered key", cfg.Providers.LLM.APIKey)
+	}
+	if len(cfg.Repos) != 1 || cfg.Repos[0].Name != "org/repo" {
+		t.Fatalf("unexpected repos: %+v", cfg.Repos)
+	}
+	labels := cfg.Repos[0].Labels
+	if len(labels) != 3 || labels[0].Description != "Something isn't working" || labels[1].Name != "area: ui" || labels[2].Name != "🚀 feature" {
+		t.Errorf("labels did not round-trip: %+v", labels)
+	}
+}
+
+func TestBuildConfigYAML_AppInstallation(t *testing.T) {
+	result := buildConfigYAML(initOptions{AppID: "12345", InstallationID: "678", KeyPath: "/k.pem", EmbedProvider: "openai", LLMProvider: "openai"})
+
+	if !strings.Contains(result, "auth: app") || !strings.Contains(result, "installation_id: 678") {
+		t.Errorf("expected app auth with installation_id, got:\n%s", result)
+	}
+}
+
+func TestBuildConfigYAML_OllamaURL(t *testing.T) {
+	result := buildConfigYAML(initOptions{EmbedProvider: "ollama", LLMProvider: "openai", OllamaURL: "http://gpu-box:11434"})
+
+	if strings.Count(result, "url: http://gpu-box:11434") != 1 {
+		t.Errorf("expected ollama url only on the ollama provider, got:\n%s", result)
+	}
+}
+
+func TestImportRepoLabels(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/repo/labels" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"name":"enhancement","description":"New feature"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/org/repo/labels?page=2>; rel="next"`, srv.URL))
+		fmt.Fprint(w, `[{"name":"bug","description":"Broken"},{"name":"question"}]`)
+	}))
+	defer srv.Close()
+
+	useInitGitHubServer(t, srv.URL)
+
+	labels, err := importRepoLabels(context.Background(), &initOptions{}, "org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []config.LabelConfig{{Name: "bug", Description: "Broken"}, {Name: "question"}, {Name: "enhancement", Description: "New feature"}}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %+v, want %+v", labels, want)
+	}
+}
+
+func TestRunInitWizard(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models":[{"name":"nomic-embed-text:latest"}]}`)
+	}))
+	defer ollama.Close()
+	gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"bug","description":"Broken"}]`)
+	}))
+	defer gh.Close()
+
+	oldURL, oldValidate := initOllamaURL, initValidateKey
+	initOllamaURL = ollama.URL
+	var validated []string
+	initValidateKey = func(_ context.Context, providerType, key string) error {
+		validated = append(validated, providerType+"="+key)
+		return nil
+	}
+	t.Cleanup(func() { initOllamaURL, initValidateKey = oldURL, oldValidate })
+	useInitGitHubServer(t, gh.URL)
+	t.Setenv("GITHUB_TOKEN", "")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	oldCfg := cfgFile
+	cfgFile = path
+	t.Cleanup(func() { cfgFile = oldCfg })
+
+	// App ID skipped, ollama embeddings accepted, anthropic LLM with an
+	// entered key, no webhooks, one repo.
+	answers := "\n\nanthropic\nsk-ant-test\n\n\norg/repo\n"
+	var out bytes.Buffer
+	initCmd.SetIn(strings.NewReader(answers))
+	initCmd.SetOut(&out)
+	t.Cleanup(func() { initCmd.SetIn(nil); initCmd.SetOut(nil) })
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"Found Ollama at " + ollama.URL, "✓ anthropic key works", "Imported 1 labels from org/repo"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if len(validated) != 1 || validated[0] != "anthropic=sk-ant-test" {
+		t.Errorf("validated = %v", validated)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("loading written config: %v", err)
+	}
+	if cfg.Providers.Embedding.Type != "ollama" || cfg.Providers.Embedding.URL != ollama.URL {
+		t.Errorf("embedding provider = %+v", cfg.Providers.Embedding)
+	}
+	if cfg.Providers.LLM.Type != "anthropic" || cfg.Providers.LLM.APIKey != "sk-ant-test" {
+		t.Errorf("llm provider = %+v", cfg.Providers.LLM)
+	}
+	if len(cfg.Repos) != 1 || len(cfg.Repos[0].Labels) != 1 || cfg.Repos[0].Labels[0].Name != "bug" {
+		t.Errorf("repos = %+v", cfg.Repos)
+	}
+}
+
+// useInitGitHubServer points label import at a test server.
+func useInitGitHubServer(t *testing.T, url string) {
+	t.Helper()
+	old := initGitHubClient
+	initGitHubClient = func(*initOptions) (*gogithub.Client, error) {
+		client := gogithub.NewClient(nil)
+		base, err := client.BaseURL.Parse(url + "/")
+		if err != nil {
+			return nil, err
+		}
+		client.BaseURL = base
+		return client, nil
+	}
+	t.Cleanup(func() { initGitHubClient = old })
+}
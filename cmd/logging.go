@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/logfile"
+)
+
+// logOutput is the handler that loggers from setupLogger write through.
+// Commands create their logger before the config is loaded, so it starts as
+// JSON on stderr and is replaced by configureLogging once the config's
+// logging section is known.
+var logOutput = newSwitchHandler(newLogHandler(os.Stderr, "json", slog.LevelInfo))
+
+var (
+	// logConfigured is set once configureLogging has applied a config
+	logConfigured atomic.Bool
+
+	logFileMu sync.Mutex
+	logFile   io.Closer
+)
+
+// newLogHandler returns a handler writing format ("json" or "text") to w.
+func newLogHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "text" {
+		return slog.NewTextHandler(w, opts)
+	}
+	return slog.NewJSONHandler(w, opts)
+}
+
+// configureLogging points every logger from setupLogger at the destination,
+// format, and level lc sets. --verbose lowers the level to debug. It also
+// becomes the default logger, so output from the log package, such as the
+// pollers', is written the same way at info level.
+func configureLogging(lc config.LoggingConfig) error {
+	level, err := lc.SlogLevel()
+	if err != nil {
+		return err
+	}
+	if verbose {
+		level = slog.LevelDebug
+	}
+
+	var w io.Writer = os.Stderr
+	var f *logfile.File
+	if lc.File != "" {
+		f, err = logfile.Open(lc.File, lc.MaxSize(), lc.Backups())
+		if err != nil {
+			return err
+		}
+		w = f
+	}
+	logOutput.set(newLogHandler(w, lc.Format, level))
+	logConfigured.Store(true)
+	slog.SetDefault(slog.New(logOutput))
+
+	logFileMu.Lock()
+	defer logFileMu.Unlock()
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = nil
+	if f != nil {
+		logFile = f
+	}
+	return nil
+}
+
+// switchHandler is a slog.Handler whose destination can be replaced after
+// loggers derived from it were created. Attributes and groups added with
+// With are replayed onto the current destination.
+type switchHandler struct {
+	current *atomic.Pointer[slog.Handler]
+	// with applies the attributes and groups added to this handler
+	with func(slog.Handler) slog.Handler
+	// cache is the current destination with with applied
+	cache atomic.Pointer[derivedHandler]
+}
+
+type derivedHandler struct {
+	base, h slog.Handler
+}
+
+func newSwitchHandler(h slog.Handler) *switchHandler {
+	s := &switchHandler{current: new(atomic.Pointer[slog.Handler])}
+	s.current.Store(&h)
+	return s
+}
+
+// set replaces the destination of s and every handler derived from it.
+func (s *switchHandler) set(h slog.Handler) {
+	s.current.Store(&h)
+}
+
+func (s *switchHandler) handler() slog.Handler {
+	base := *s.current.Load()
+	if s.with == nil {
+		return base
+	}
+	if d := s.cache.Load(); d != nil && d.base == base {
+		return d.h
+	}
+	h := s.with(base)
+	s.cache.Store(&derivedHandler{base: base, h: h})
+	return h
+}
+
+func (s *switchHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return s.handler().Enabled(ctx, level)
+}
+
+func (s *switchHandler) Handle(ctx context.Context, r slog.Record) error {
+	return s.handler().Handle(ctx, r)
+}
+
+func (s *switchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return s.derive(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+func (s *switchHandler) WithGroup(name string) slog.Handler {
+	return s.derive(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+func (s *switchHandler) derive(op func(slog.Handler) slog.Handler) *switchHandler {
+	with := op
+	if prev := s.with; prev != nil {
+		with = func(h slog.Handler) slog.Handler { return op(prev(h)) }
+	}
+	return &switchHandler{current: s.current, with: with}
+}
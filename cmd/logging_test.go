@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/jacklau/triage/internal/config"
 )
 
 // TestNoStderrInScanWatch verifies that scan.go and watch.go do not contain
@@ -58,3 +65,69 @@ func TestSetupLoggerVerbose(t *testing.T) {
 		t.Fatal("setupLogger() returned nil with verbose=true")
 	}
 }
+
+func TestSwitchHandler(t *testing.T) {
+	var first, second bytes.Buffer
+	h := newSwitchHandler(newLogHandler(&first, "json", slog.LevelInfo))
+	logger := slog.New(h).With("repo", "org/app").WithGroup("issue")
+
+	logger.Info("triaged", "number", 7)
+	logger.Debug("hidden")
+	var rec map[string]any
+	if err := json.Unmarshal(first.Bytes(), &rec); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", first.String(), err)
+	}
+	if rec["repo"] != "org/app" || rec["issue"].(map[string]any)["number"] != float64(7) {
+		t.Errorf("expected attributes and groups to be kept, got %v", rec)
+	}
+
+	// Loggers created earlier follow the new destination
+	h.set(newLogHandler(&second, "text", slog.LevelDebug))
+	logger.Debug("shown", "number", 8)
+	if got := second.String(); !strings.Contains(got, "msg=shown") || !strings.Contains(got, "repo=org/app") || !strings.Contains(got, "issue.number=8") {
+		t.Errorf("unexpected text record %q", got)
+	}
+	if strings.Contains(first.String(), "shown") {
+		t.Error("expected nothing more on the old destination")
+	}
+}
+
+func TestConfigureLogging(t *testing.T) {
+	defer configureLogging(config.LoggingConfig{})
+	path := filepath.Join(t.TempDir(), "logs", "triage.log")
+	if err := configureLogging(config.LoggingConfig{Level: "warn", Format: "text", File: path}); err != nil {
+		t.Fatalf("configureLogging: %v", err)
+	}
+	logger := setupLogger()
+	logger.Info("not logged")
+	logger.Warn("disk almost full", "free", "1%")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log file: %v", err)
+	}
+	if got := string(data); strings.Contains(got, "not logged") || !strings.Contains(got, `msg="disk almost full" free=1%`) {
+		t.Errorf("unexpected log file %q", got)
+	}
+
+	// --verbose overrides the configured level
+	verbose = true
+	defer func() { verbose = false }()
+	if err := configureLogging(config.LoggingConfig{Level: "error", File: path}); err != nil {
+		t.Fatalf("configureLogging: %v", err)
+	}
+	setupLogger().Debug("debugging")
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), `"msg":"debugging"`) {
+		t.Errorf("expected a JSON debug record with --verbose, got %q", data)
+	}
+
+	// The log package writes through the same handler
+	log.Printf("[poller org/app] starting")
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), `"level":"INFO","msg":"[poller org/app] starting"`) {
+		t.Errorf("expected log package output as a JSON record, got %q", data)
+	}
+
+	if err := configureLogging(config.LoggingConfig{Level: "loud"}); err == nil {
+		t.Error("expected an invalid level to be rejected")
+	}
+}
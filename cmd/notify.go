@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/secrets"
+)
+
+var notifyTestTarget string
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Work with notification channels",
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a sample triage message to the configured notifiers",
+	Long: `Notify test sends a canned triage result through each configured notifier
+and reports whether it was delivered, so webhook URLs and bot tokens can be
+checked before a real issue arrives.
+
+With --target, only that notifier is tested, and it is an error if it is
+not configured.`,
+	Example: `  triage notify test
+  triage notify test --target slack`,
+	Args: cobra.NoArgs,
+	RunE: runNotifyTest,
+}
+
+func init() {
+	notifyTestCmd.Flags().StringVar(&notifyTestTarget, "target", "all", "notifier to test: slack, discord, or all")
+	registerFlagValues(notifyTestCmd, "target", []string{"slack", "discord", "all"})
+	notifyCmd.AddCommand(notifyTestCmd)
+	rootCmd.AddCommand(notifyCmd)
+}
+
+// notifyTarget is a notifier that can be set up from the config.
+type notifyTarget struct {
+	name       string
+	configured bool
+}
+
+// configuredNotifiers returns each notifier and whether the config sets it up.
+func configuredNotifiers(cfg *config.Config) []notifyTarget {
+	return []notifyTarget{
+		{"slack", cfg.Notify.SlackWebhook != "" || cfg.Notify.SlackBotToken != ""},
+		{"discord", cfg.Notify.DiscordWebhook != ""},
+	}
+}
+
+// notifyTestChecks returns a check that sends the sample message through
+// each notifier selected by target. An explicitly selected notifier that is
+// not configured fails; with "all", unconfigured ones are skipped.
+func notifyTestChecks(cfg *config.Config, target string) ([]doctorCheck, error) {
+	var checks []doctorCheck
+	configured := 0
+	for _, t := range configuredNotifiers(cfg) {
+		if target != "all" && target != t.name {
+			continue
+		}
+		t := t
+		if t.configured {
+			configured++
+		}
+		checks = append(checks, doctorCheck{t.name, func(ctx context.Context) (string, error) {
+			if !t.configured {
+				if target == "all" {
+					return "not configured", errDoctorSkip
+				}
+				return "", config.ErrNotConfigured
+			}
+			n, err := createNotifier(cfg, t.name, nil)
+			if err != nil {
+				return "", err
+			}
+			return checkNotifier(ctx, n, true)
+		}})
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("unknown notifier %q (want slack, discord, or all)", target)
+	}
+	if target == "all" && configured == 0 {
+		return nil, fmt.Errorf("no notifiers configured; set notify.slack_webhook or notify.discord_webhook")
+	}
+	return checks, nil
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	checks, err := notifyTestChecks(cfg, strings.ToLower(notifyTestTarget))
+	if err != nil {
+		return err
+	}
+	if failed := runDoctorChecks(context.Background(), cmd.OutOrStdout(), checks); failed > 0 {
+		return fmt.Errorf("%d notifier(s) failed", failed)
+	}
+	return nil
+}
+
+// notifyPreflightTimeout bounds the startup checks of notification targets.
+const notifyPreflightTimeout = 15 * time.Second
+
+// lookupHost resolves webhook hosts for checkNotifyTargets.
+var lookupHost = net.DefaultResolver.LookupHost
+
+// checkNotifyTargets fails fast, at watch or serve startup, on notification
+// settings that would only fail at the first real triage: webhook URLs that
+// are malformed or whose host does not resolve. With sendTest, it also sends
+// a test message through n, which must not be nil.
+func checkNotifyTargets(ctx context.Context, cfg *config.Config, n notify.Notifier, sendTest bool) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyPreflightTimeout)
+	defer cancel()
+
+	problems := config.CheckWebhooks(cfg)
+	for _, wh := range cfg.Webhooks() {
+		u, err := url.Parse(wh.URL)
+		if err != nil || u.Hostname() == "" || secrets.IsRef(wh.URL) || net.ParseIP(u.Hostname()) != nil {
+			continue
+		}
+		if _, err := lookupHost(ctx, u.Hostname()); err != nil {
+			problems = append(problems, fmt.Errorf("%s: cannot resolve %s: %w", wh.Key, u.Hostname(), err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid notification settings: %w", errors.Join(problems...))
+	}
+
+	if sendTest {
+		if err := n.Notify(ctx, sampleTriageResult()); err != nil {
+			return fmt.Errorf("sending a test message to %s: %w", notify.Name(n), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jacklau/triage/internal/config"
+)
+
+func TestNotifyTestChecks(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Notify.SlackWebhook = server.URL
+
+	checks, err := notifyTestChecks(cfg, "all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if failed := runDoctorChecks(context.Background(), &out, checks); failed != 0 {
+		t.Fatalf("expected no failures, got %d:\n%s", failed, out.String())
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected one message sent, got %d", calls.Load())
+	}
+	if !strings.Contains(out.String(), "test message delivered") || !strings.Contains(out.String(), "skip") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	// Asking for an unconfigured notifier fails instead of skipping.
+	checks, err = notifyTestChecks(cfg, "discord")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out.Reset()
+	if failed := runDoctorChecks(context.Background(), &out, checks); failed != 1 {
+		t.Errorf("expected discord to fail, got %d failures:\n%s", failed, out.String())
+	}
+}
+
+func TestNotifyTestChecksErrors(t *testing.T) {
+	if _, err := notifyTestChecks(&config.Config{}, "all"); err == nil {
+		t.Error("expected error with no notifiers configured")
+	}
+	if _, err := notifyTestChecks(&config.Config{}, "email"); err == nil {
+		t.Error("expected error for unknown target")
+	}
+}
+
+func TestNotifyTestReportsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Notify.DiscordWebhook = server.URL
+	checks, err := notifyTestChecks(cfg, "discord")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if failed := runDoctorChecks(context.Background(), &out, checks); failed != 1 {
+		t.Errorf("expected a failure, got %d", failed)
+	}
+	if !strings.Contains(out.String(), "404") {
+		t.Errorf("expected the status in the output, got:\n%s", out.String())
+	}
+}
+
+func TestCheckNotifyTargets(t *testing.T) {
+	var calls atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	defer func(orig func(context.Context, string) ([]string, error)) { lookupHost = orig }(lookupHost)
+	lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if host == "hooks.slack.com" {
+			return []string{"192.0.2.1"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	cfg := &config.Config{}
+	cfg.Notify.SlackWebhook = server.URL
+	n, err := createNotifier(cfg, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a test message, nothing is sent
+	if err := checkNotifyTargets(context.Background(), cfg, n, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("expected no message without sendTest, got %d", calls.Load())
+	}
+	if err := checkNotifyTargets(context.Background(), cfg, n, true); err != nil || calls.Load() != 1 {
+		t.Fatalf("expected a delivered test message, got %v after %d call(s)", err, calls.Load())
+	}
+	status.Store(http.StatusNotFound)
+	if err := checkNotifyTargets(context.Background(), cfg, n, true); err == nil || !strings.Contains(err.Error(), "test message to slack") {
+		t.Errorf("expected a failed test message, got %v", err)
+	}
+
+	tests := []struct {
+		name, slack, discord, want string
+	}{
+		{"resolvable", "https://hooks.slack.com/services/T0/B0/x", "", ""},
+		{"bad shape", "https://hooks.slack.com/T0/B0/x", "", "notify.slack_webhook"},
+		{"unresolvable", "", "https://discrod.com/api/webhooks/1/x", "cannot resolve discrod.com"},
+		{"secret reference", "vault://secret/slack#webhook", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Notify.SlackWebhook, cfg.Notify.DiscordWebhook = tt.slack, tt.discord
+			err := checkNotifyTargets(context.Background(), cfg, nil, false)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), "invalid notification settings") {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultProfile names the config at the default path rather than a file
+// under the profiles directory.
+const defaultProfile = "default"
+
+var profileName string
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named configurations",
+	Long: `Profiles are named config files under ~/.triage/profiles/, e.g.
+~/.triage/profiles/work.yaml, for triaging repos with different providers and
+webhooks. Select one per command with --profile, or for every command with
+"triage profile use". The profile "default" is ~/.triage/config.yaml.
+
+--config takes precedence over any profile. Create a profile with
+"triage --profile work init".`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles, marking the active one",
+	Args:  cobra.NoArgs,
+	RunE:  runProfileList,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a profile the default for subsequent commands",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileUse,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, _ := listProfiles()
+		return append([]string{defaultProfile}, names...), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "named config under ~/.triage/profiles/ (default: the profile set by \"profile use\")")
+	profileCmd.AddCommand(profileListCmd, profileUseCmd)
+	rootCmd.AddCommand(profileCmd)
+}
+
+// triageDir returns ~/.triage, or .triage if the home directory is unknown.
+func triageDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".triage"
+	}
+	return filepath.Join(home, ".triage")
+}
+
+// profilesDir returns the directory holding profile config files.
+func profilesDir() string {
+	return filepath.Join(triageDir(), "profiles")
+}
+
+// currentProfileFile returns the file recording the profile chosen with
+// "profile use".
+func currentProfileFile() string {
+	return filepath.Join(triageDir(), "profile")
+}
+
+// validateProfileName rejects names that would escape the profiles directory.
+func validateProfileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	return nil
+}
+
+// profileConfigPath returns the config file for the named profile.
+func profileConfigPath(name string) string {
+	if name == defaultProfile {
+		return defaultConfigPath()
+	}
+	return filepath.Join(profilesDir(), name+".yaml")
+}
+
+// activeProfile returns the --profile flag, else the profile saved by
+// "profile use", else "default".
+func activeProfile() string {
+	if profileName != "" {
+		return profileName
+	}
+	data, err := os.ReadFile(currentProfileFile())
+	if err == nil {
+		if name := strings.TrimSpace(string(data)); validateProfileName(name) == nil {
+			return name
+		}
+	}
+	return defaultProfile
+}
+
+// listProfiles returns the names of the profiles in the profiles directory,
+// sorted.
+func listProfiles() ([]string, error) {
+	entries, err := os.ReadDir(profilesDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading profiles: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".yaml"); ok && !e.IsDir() && name != defaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	names, err := listProfiles()
+	if err != nil {
+		return err
+	}
+	active := activeProfile()
+	out := cmd.OutOrStdout()
+	for _, name := range append([]string{defaultProfile}, names...) {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		fmt.Fprintf(out, "%s %-12s %s\n", marker, name, profileConfigPath(name))
+	}
+	return nil
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	path := profileConfigPath(name)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("profile %s: %w (create it with: triage --profile %s init)", name, err, name)
+	}
+
+	if name == defaultProfile {
+		if err := os.Remove(currentProfileFile()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("clearing profile: %w", err)
+		}
+	} else {
+		if err := os.MkdirAll(triageDir(), 0o755); err != nil {
+			return fmt.Errorf("creating %s: %w", triageDir(), err)
+		}
+		if err := os.WriteFile(currentProfileFile(), []byte(name+"\n"), 0o644); err != nil {
+			return fmt.Errorf("saving profile: %w", err)
+		}
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Using profile %s (%s)\n", name, path)
+	return nil
+}
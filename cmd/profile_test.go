@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupProfileHome points the home directory at a temp dir with the given
+// profiles and resets the profile flags.
+func setupProfileHome(t *testing.T, profiles ...string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".triage", "profiles")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range profiles {
+		if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte("defaults:\n  poll_interval: 1m\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldCfg, oldProfile := cfgFile, profileName
+	cfgFile, profileName = "", ""
+	t.Cleanup(func() { cfgFile, profileName = oldCfg, oldProfile })
+	return home
+}
+
+func TestConfigPathProfiles(t *testing.T) {
+	home := setupProfileHome(t, "work")
+
+	if got, want := configPath(), filepath.Join(home, ".triage", "config.yaml"); got != want {
+		t.Errorf("configPath() = %q, want %q", got, want)
+	}
+
+	profileName = "work"
+	if got, want := configPath(), filepath.Join(home, ".triage", "profiles", "work.yaml"); got != want {
+		t.Errorf("configPath() with --profile = %q, want %q", got, want)
+	}
+
+	cfgFile = "/tmp/explicit.yaml"
+	if got := configPath(); got != cfgFile {
+		t.Errorf("expected --config to take precedence, got %q", got)
+	}
+}
+
+func TestProfileUseAndList(t *testing.T) {
+	home := setupProfileHome(t, "work", "oss")
+
+	var out bytes.Buffer
+	profileUseCmd.SetOut(&out)
+	if err := runProfileUse(profileUseCmd, []string{"oss"}); err != nil {
+		t.Fatalf("profile use: %v", err)
+	}
+	if got, want := configPath(), filepath.Join(home, ".triage", "profiles", "oss.yaml"); got != want {
+		t.Errorf("configPath() after use = %q, want %q", got, want)
+	}
+
+	// --profile overrides the saved profile.
+	profileName = "work"
+	if !strings.HasSuffix(configPath(), "work.yaml") {
+		t.Errorf("expected --profile to override the saved profile, got %q", configPath())
+	}
+	profileName = ""
+
+	out.Reset()
+	profileListCmd.SetOut(&out)
+	if err := runProfileList(profileListCmd, nil); err != nil {
+		t.Fatalf("profile list: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 profiles, got:\n%s", out.String())
+	}
+	if !strings.HasPrefix(lines[0], "  default") || !strings.HasPrefix(lines[1], "* oss") || !strings.HasPrefix(lines[2], "  work") {
+		t.Errorf("unexpected list output:\n%s", out.String())
+	}
+
+	// Switching back to default needs the default config to exist.
+	if err := runProfileUse(profileUseCmd, []string{"default"}); err == nil {
+		t.Error("expected error for missing default config")
+	}
+	if err := os.WriteFile(filepath.Join(home, ".triage", "config.yaml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runProfileUse(profileUseCmd, []string{"default"}); err != nil {
+		t.Fatalf("profile use default: %v", err)
+	}
+	if activeProfile() != defaultProfile {
+		t.Errorf("expected default profile, got %q", activeProfile())
+	}
+}
+
+func TestProfileUseErrors(t *testing.T) {
+	setupProfileHome(t)
+
+	if err := runProfileUse(profileUseCmd, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "--profile missing init") {
+		t.Errorf("expected hint to create the profile, got %v", err)
+	}
+	if err := runProfileUse(profileUseCmd, []string{"../etc"}); err == nil || !strings.Contains(err.Error(), "invalid profile name") {
+		t.Errorf("expected invalid name error, got %v", err)
+	}
+}
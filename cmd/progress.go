@@ -1,18 +1,67 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"time"
 )
 
+// progressReporter shows the progress of a long-running command as its
+// items finish.
+type progressReporter interface {
+	// Done records that the item numbered n finished, and whether it failed.
+	Done(n int, failed bool)
+	// Finish reports that every item finished.
+	Finish()
+	// Stop reports that the command stopped short of the total.
+	Stop()
+	// AddTotal adds n items to the total, for commands that find their
+	// items as they go.
+	AddTotal(n int)
+}
+
+// progressFormats are the values of --progress.
+var progressFormats = []string{"bar", "json"}
+
+// validateProgressFormat checks a --progress value.
+func validateProgressFormat(format string) error {
+	if format != "bar" && format != "json" {
+		return fmt.Errorf("--progress must be bar or json, got %q", format)
+	}
+	return nil
+}
+
+// newProgressReporter returns a reporter writing to w in the given format:
+// "json" for one JSON event per line, else a terminal progress bar.
+func newProgressReporter(format string, total int, description string, w io.Writer) progressReporter {
+	if format == "json" {
+		return newProgressJSON(total, w)
+	}
+	return newProgressBar(total, description, w)
+}
+
+// etaWindow is the number of recent completions used to estimate the rate.
+// A short window lets the ETA react when a provider starts rate limiting.
+const etaWindow = 20
+
 // progressBar is a simple terminal progress bar that writes to stderr.
+// It is safe for concurrent use.
 type progressBar struct {
+	mu          sync.Mutex
 	total       int
 	current     int
 	width       int
 	description string
 	writer      io.Writer
+
+	// status, if set, returns extra text shown after the ETA.
+	status func() string
+	now    func() time.Time
+	start  time.Time
+	recent []time.Time // completion times, at most etaWindow
 }
 
 // newProgressBar creates a new progress bar.
@@ -22,25 +71,78 @@ func newProgressBar(total int, description string, writer io.Writer) *progressBa
 		width:       30,
 		description: description,
 		writer:      writer,
+		now:         time.Now,
+		start:       time.Now(),
 	}
 }
 
 // Add increments the progress bar by n.
 func (p *progressBar) Add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.current += n
 	if p.current > p.total {
 		p.current = p.total
 	}
+	now := p.now()
+	for i := 0; i < n; i++ {
+		p.recent = append(p.recent, now)
+	}
+	if len(p.recent) > etaWindow {
+		p.recent = p.recent[len(p.recent)-etaWindow:]
+	}
+	p.render()
+}
+
+// Done advances the progress bar by one item.
+func (p *progressBar) Done(int, bool) { p.Add(1) }
+
+// AddTotal grows the progress bar's total by n.
+func (p *progressBar) AddTotal(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += n
 	p.render()
 }
 
 // Finish completes the progress bar and prints a newline.
 func (p *progressBar) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.current = p.total
 	p.render()
 	fmt.Fprintln(p.writer)
 }
 
+// Stop ends the progress bar where it is, leaving it short of the total, and
+// prints a newline.
+func (p *progressBar) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintln(p.writer)
+}
+
+// eta estimates the time remaining from the rate of recent completions, or
+// returns false if there is not enough data yet.
+func (p *progressBar) eta() (time.Duration, bool) {
+	remaining := p.total - p.current
+	if remaining <= 0 || len(p.recent) == 0 {
+		return 0, false
+	}
+	// With a full window, measure between its oldest and newest entries;
+	// otherwise measure from the start so the first completion counts.
+	from, done := p.start, len(p.recent)
+	if len(p.recent) == etaWindow {
+		from, done = p.recent[0], etaWindow-1
+	}
+	elapsed := p.recent[len(p.recent)-1].Sub(from)
+	if elapsed <= 0 || done == 0 {
+		return 0, false
+	}
+	perItem := elapsed / time.Duration(done)
+	return perItem * time.Duration(remaining), true
+}
+
 // render draws the progress bar to the writer using carriage return.
 func (p *progressBar) render() {
 	if p.total <= 0 {
@@ -54,5 +156,100 @@ func (p *progressBar) render() {
 	}
 
 	bar := strings.Repeat("=", filled) + strings.Repeat(" ", p.width-filled)
-	fmt.Fprintf(p.writer, "\r%s [%s] %d/%d", p.description, bar, p.current, p.total)
+	line := fmt.Sprintf("\r%s [%s] %d/%d", p.description, bar, p.current, p.total)
+	if eta, ok := p.eta(); ok {
+		line += " ETA " + formatETA(eta)
+	}
+	if p.status != nil {
+		if s := p.status(); s != "" {
+			line += " (" + s + ")"
+		}
+	}
+	// Clear to end of line in case the previous render was longer
+	fmt.Fprintf(p.writer, "%s\033[K", line)
+}
+
+// formatETA renders a duration compactly, e.g. "45s", "3m20s", "1h05m".
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// progressEvent is a line of --progress json output.
+type progressEvent struct {
+	// Event is "start", "progress", "done", or "stopped".
+	Event     string `json:"event"`
+	Processed int    `json:"processed"`
+	Total     int    `json:"total"`
+	// Issue is the item that just finished, set on progress events.
+	Issue    int `json:"issue,omitempty"`
+	Failures int `json:"failures"`
+}
+
+// progressJSON reports progress as newline-delimited JSON events, for
+// wrappers and web UIs that show live progress. It is safe for concurrent
+// use.
+type progressJSON struct {
+	mu        sync.Mutex
+	enc       *json.Encoder
+	total     int
+	processed int
+	failures  int
+}
+
+// newProgressJSON creates a JSON reporter and writes its start event.
+func newProgressJSON(total int, w io.Writer) *progressJSON {
+	p := &progressJSON{enc: json.NewEncoder(w), total: total}
+	p.emit("start", 0)
+	return p
+}
+
+// Done writes a progress event for item n.
+func (p *progressJSON) Done(n int, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed++
+	if failed {
+		p.failures++
+	}
+	p.emit("progress", n)
+}
+
+// AddTotal grows the total reported by later events by n.
+func (p *progressJSON) AddTotal(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += n
+}
+
+// Finish writes a done event.
+func (p *progressJSON) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.emit("done", 0)
+}
+
+// Stop writes a stopped event.
+func (p *progressJSON) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.emit("stopped", 0)
+}
+
+// emit writes an event. Write errors are ignored, as for the progress bar.
+func (p *progressJSON) emit(event string, issue int) {
+	_ = p.enc.Encode(progressEvent{
+		Event:     event,
+		Processed: p.processed,
+		Total:     p.total,
+		Issue:     issue,
+		Failures:  p.failures,
+	})
 }
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestProgressBar(t *testing.T) {
@@ -40,6 +42,19 @@ func TestProgressBarFinish(t *testing.T) {
 	}
 }
 
+func TestProgressBarStop(t *testing.T) {
+	var buf bytes.Buffer
+	bar := newProgressBar(5, "Stopped", &buf)
+
+	bar.Add(2)
+	bar.Stop()
+	output := buf.String()
+
+	if strings.Contains(output, "5/5") || !strings.HasSuffix(output, "\n") {
+		t.Errorf("stopped progress bar should stay at 2/5 and end with newline, got %q", output)
+	}
+}
+
 func TestProgressBarZeroTotal(t *testing.T) {
 	var buf bytes.Buffer
 	bar := newProgressBar(0, "Empty", &buf)
@@ -77,3 +92,142 @@ func TestProgressBarRender(t *testing.T) {
 		t.Errorf("at 50%% should have 15 '=' chars, got %d in %q", equalCount, output)
 	}
 }
+
+func TestProgressBarETA(t *testing.T) {
+	var buf bytes.Buffer
+	bar := newProgressBar(10, "ETA", &buf)
+	clock := bar.start
+	bar.now = func() time.Time { return clock }
+
+	clock = clock.Add(4 * time.Second)
+	bar.Add(2) // 2s per item, 8 remaining
+
+	if !strings.Contains(buf.String(), "ETA 16s") {
+		t.Errorf("expected ETA 16s, got %q", buf.String())
+	}
+}
+
+func TestProgressBarETAUsesRecentRate(t *testing.T) {
+	var buf bytes.Buffer
+	bar := newProgressBar(100, "ETA", &buf)
+	clock := bar.start
+	bar.now = func() time.Time { return clock }
+
+	// Fast at first, then slowing to 10s per item as if rate limited.
+	for i := 0; i < 20; i++ {
+		clock = clock.Add(100 * time.Millisecond)
+		bar.Add(1)
+	}
+	for i := 0; i < etaWindow; i++ {
+		clock = clock.Add(10 * time.Second)
+		bar.Add(1)
+	}
+
+	eta, ok := bar.eta()
+	if !ok {
+		t.Fatal("expected an ETA")
+	}
+	if eta != 600*time.Second {
+		t.Errorf("expected ETA from the recent rate (600s), got %v", eta)
+	}
+}
+
+func TestProgressBarStatus(t *testing.T) {
+	var buf bytes.Buffer
+	bar := newProgressBar(2, "Status", &buf)
+	bar.status = func() string { return "GitHub quota 10/5000" }
+
+	bar.Add(1)
+	if !strings.Contains(buf.String(), "(GitHub quota 10/5000)") {
+		t.Errorf("expected status in output, got %q", buf.String())
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{45 * time.Second, "45s"},
+		{1500 * time.Millisecond, "2s"},
+		{200 * time.Second, "3m20s"},
+		{65 * time.Minute, "1h05m"},
+	}
+	for _, tt := range tests {
+		if got := formatETA(tt.d); got != tt.want {
+			t.Errorf("formatETA(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestProgressJSON(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgressReporter("json", 3, "Processing", &buf)
+	p.Done(12, false)
+	p.Done(7, true)
+	p.Stop()
+
+	var events []progressEvent
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e progressEvent
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("decoding event: %v", err)
+		}
+		events = append(events, e)
+	}
+	want := []progressEvent{
+		{Event: "start", Total: 3},
+		{Event: "progress", Processed: 1, Total: 3, Issue: 12},
+		{Event: "progress", Processed: 2, Total: 3, Issue: 7, Failures: 1},
+		{Event: "stopped", Processed: 2, Total: 3, Failures: 1},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestNewProgressReporter(t *testing.T) {
+	if _, ok := newProgressReporter("bar", 1, "x", &bytes.Buffer{}).(*progressBar); !ok {
+		t.Error("expected a progress bar for bar")
+	}
+	if _, ok := newProgressReporter("json", 1, "x", &bytes.Buffer{}).(*progressJSON); !ok {
+		t.Error("expected JSON events for json")
+	}
+	if err := validateProgressFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestProgressAddTotal(t *testing.T) {
+	var buf bytes.Buffer
+	bar := newProgressBar(0, "Growing", &buf)
+	bar.AddTotal(4)
+	bar.Add(1)
+	bar.AddTotal(2)
+	if !strings.Contains(buf.String(), "1/6") {
+		t.Errorf("expected the total to grow to 6, got %q", buf.String())
+	}
+
+	buf.Reset()
+	p := newProgressReporter("json", 0, "Processing", &buf)
+	p.AddTotal(2)
+	p.Done(3, false)
+	var events []progressEvent
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e progressEvent
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("decoding event: %v", err)
+		}
+		events = append(events, e)
+	}
+	if len(events) != 2 || events[1].Total != 2 {
+		t.Errorf("expected later events to report the grown total, got %+v", events)
+	}
+}
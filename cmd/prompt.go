@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/classify"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/provider"
+)
+
+var (
+	promptTestCustom    string
+	promptTestPrintOnly bool
+	promptTestOutput    string
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect and iterate on classification prompts",
+}
+
+var promptTestCmd = &cobra.Command{
+	Use:   "test <owner/repo#number>",
+	Short: "Show the classification prompt for an issue and the model's reply",
+	Long: `Prompt test prints the exact prompt that would be sent to classify an
+issue, runs it against the configured LLM, and shows the raw model output
+alongside the parsed result. Nothing is logged or sent to notifiers.
+
+The repo's labels and custom_prompt come from the config. Use
+--custom-prompt to try different wording without editing the config, or
+--custom-prompt "" to see the prompt without one.`,
+	Example: `  triage prompt test octocat/hello-world#42 --custom-prompt "This is a mobile app; crashes are always bugs."
+  triage prompt test octocat/hello-world#42 --print-only`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIssueRefs(1),
+	RunE:              runPromptTest,
+}
+
+func init() {
+	promptTestCmd.Flags().StringVar(&promptTestCustom, "custom-prompt", "", "use this custom prompt instead of the configured one")
+	promptTestCmd.Flags().BoolVar(&promptTestPrintOnly, "print-only", false, "print the prompt without calling the LLM")
+	promptTestCmd.Flags().StringVar(&promptTestOutput, "output", "text", "output format: text or json")
+	registerFlagValues(promptTestCmd, "output", outputFormats)
+	promptCmd.AddCommand(promptTestCmd)
+	rootCmd.AddCommand(promptCmd)
+}
+
+// promptTestResult is the outcome of a prompt test.
+type promptTestResult struct {
+	Repo      string
+	Number    int
+	Prompt    string
+	Exchanges []classify.Exchange
+	Result    *classify.ClassifyResult
+}
+
+func runPromptTest(cmd *cobra.Command, args []string) error {
+	owner, repo, number, err := parseIssueRef(args[0])
+	if err != nil {
+		return err
+	}
+	repoFull := owner + "/" + repo
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	if !promptTestPrintOnly && c.Classifier == nil {
+		return notConfigured("LLM provider", "set providers.llm in config")
+	}
+
+	ctx := context.Background()
+	side, err := loadCompareIssue(ctx, c, owner, repo, number)
+	if err != nil {
+		return err
+	}
+
+	guidance := findRepoGuidance(cfg, repoFull)
+	if r, err := c.Store.GetRepoByOwnerRepo(owner, repo); err == nil {
+		m, err := c.RepoMetadata.Get(ctx, r.ID, repoFull)
+		if err != nil {
+			logger.Warn("failed to refresh repo metadata, using last known", "error", err)
+		}
+		if m != nil {
+			guidance.Description, guidance.Topics, guidance.RepoLanguage = m.Description, m.Topics, m.Language
+		}
+	}
+	if cmd.Flags().Changed("custom-prompt") {
+		guidance.CustomPrompt = promptTestCustom
+	}
+	labels := findRepoLabels(cfg, repoFull)
+
+	res := promptTestResult{Repo: repoFull, Number: number}
+	res.Prompt, err = classify.BuildPromptWithGuidance(repoFull, labels, side.Issue, guidance)
+	if err != nil {
+		return fmt.Errorf("building prompt: %w", err)
+	}
+
+	if !promptTestPrintOnly {
+		res.Result, res.Exchanges, err = c.Classifier.ClassifyTrace(ctx, repoFull, labels, side.Issue, guidance)
+		if err != nil && len(res.Exchanges) == 0 {
+			return fmt.Errorf("classifying: %w", err)
+		}
+	}
+
+	if promptTestOutput == "json" {
+		return printPromptTestJSON(cmd.OutOrStdout(), &res)
+	}
+	printPromptTestText(cmd.OutOrStdout(), &res)
+	return nil
+}
+
+func printPromptTestText(w io.Writer, res *promptTestResult) {
+	fmt.Fprintf(w, "--- Prompt (~%d tokens) ---\n%s\n", provider.EstimateTokens(res.Prompt), res.Prompt)
+
+	for i, ex := range res.Exchanges {
+		if i > 0 {
+			// Retries repeat the prompt with extra instructions; show only those.
+			fmt.Fprintf(w, "\n--- Retry %d, prompt suffix ---\n%s\n", i, strings.TrimPrefix(ex.Prompt, res.Prompt))
+		}
+		if ex.Err != nil {
+			fmt.Fprintf(w, "\n--- Error ---\n%v\n", ex.Err)
+			continue
+		}
+		fmt.Fprintf(w, "\n--- Raw output ---\n%s\n", ex.Response)
+	}
+
+	if res.Result == nil {
+		return
+	}
+	fmt.Fprintln(w, "\n--- Parsed result ---")
+	fmt.Fprintf(w, "Labels:     %s\n", notify.FormatLabels(res.Result.Labels))
+	fmt.Fprintf(w, "Confidence: %.2f (%s)\n", res.Result.Confidence, res.Result.ConfidenceLevel)
+	fmt.Fprintf(w, "Reasoning:  %s\n", res.Result.Reasoning)
+}
+
+type promptExchangeJSON struct {
+	Prompt   string `json:"prompt"`
+	Response string `json:"response,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type promptResultJSON struct {
+	Labels          []labelJSON `json:"labels"`
+	Confidence      float64     `json:"confidence"`
+	ConfidenceLevel string      `json:"confidence_level"`
+	Reasoning       string      `json:"reasoning,omitempty"`
+}
+
+type promptTestJSON struct {
+	Repo      string               `json:"repo"`
+	Number    int                  `json:"number"`
+	Prompt    string               `json:"prompt"`
+	Exchanges []promptExchangeJSON `json:"exchanges"`
+	Result    *promptResultJSON    `json:"result,omitempty"`
+}
+
+func printPromptTestJSON(w io.Writer, res *promptTestResult) error {
+	out := promptTestJSON{
+		Repo:      res.Repo,
+		Number:    res.Number,
+		Prompt:    res.Prompt,
+		Exchanges: make([]promptExchangeJSON, 0, len(res.Exchanges)),
+	}
+	for _, ex := range res.Exchanges {
+		ej := promptExchangeJSON{Prompt: ex.Prompt, Response: ex.Response}
+		if ex.Err != nil {
+			ej.Error = ex.Err.Error()
+		}
+		out.Exchanges = append(out.Exchanges, ej)
+	}
+	if res.Result != nil {
+		out.Result = &promptResultJSON{
+			Labels:          make([]labelJSON, 0, len(res.Result.Labels)),
+			Confidence:      res.Result.Confidence,
+			ConfidenceLevel: res.Result.ConfidenceLevel,
+			Reasoning:       res.Result.Reasoning,
+		}
+		for _, l := range res.Result.Labels {
+			out.Result.Labels = append(out.Result.Labels, labelJSON{Name: l.Name, Confidence: l.Confidence})
+		}
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
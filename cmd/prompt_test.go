@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/classify"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func testPromptResult() *promptTestResult {
+	return &promptTestResult{
+		Repo:   "org/repo",
+		Number: 7,
+		Prompt: "PROMPT",
+		Exchanges: []classify.Exchange{
+			{Prompt: "PROMPT", Response: "not json"},
+			{Prompt: "PROMPT\n\nRespond with JSON", Response: `{"labels":["bug"],"confidence":0.9}`},
+		},
+		Result: &classify.ClassifyResult{
+			Labels:          []github.LabelSuggestion{{Name: "bug", Confidence: 0.9}},
+			Confidence:      0.9,
+			ConfidenceLevel: "suggested",
+			Reasoning:       "Crash",
+		},
+	}
+}
+
+func TestPrintPromptTestText(t *testing.T) {
+	var buf bytes.Buffer
+	printPromptTestText(&buf, testPromptResult())
+	out := buf.String()
+
+	for _, want := range []string{
+		"--- Prompt (~2 tokens) ---\nPROMPT\n",
+		"--- Raw output ---\nnot json\n",
+		"--- Retry 1, prompt suffix ---\n\n\nRespond with JSON\n",
+		"Labels:     `bug` (90%)",
+		"Confidence: 0.90 (suggested)",
+		"Reasoning:  Crash",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintPromptTestTextError(t *testing.T) {
+	res := &promptTestResult{
+		Prompt:    "PROMPT",
+		Exchanges: []classify.Exchange{{Prompt: "PROMPT", Err: errors.New("rate limited")}},
+	}
+	var buf bytes.Buffer
+	printPromptTestText(&buf, res)
+	if !strings.Contains(buf.String(), "--- Error ---\nrate limited") {
+		t.Errorf("expected error section, got:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "Parsed result") {
+		t.Error("expected no parsed result without a result")
+	}
+}
+
+func TestPrintPromptTestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printPromptTestJSON(&buf, testPromptResult()); err != nil {
+		t.Fatal(err)
+	}
+	var got promptTestJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got.Exchanges) != 2 || got.Exchanges[0].Response != "not json" {
+		t.Errorf("unexpected exchanges: %+v", got.Exchanges)
+	}
+	if got.Result == nil || got.Result.ConfidenceLevel != "suggested" || got.Result.Labels[0].Name != "bug" {
+		t.Errorf("unexpected result: %+v", got.Result)
+	}
+}
+
+func TestRunPromptTestPrintOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, _ := db.CreateRepo("org", "mobile")
+	now := time.Now()
+	if err := db.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: 7, Title: "App crashes", Body: "On launch",
+		State: "open", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetRepoMetadata(repo.ID, &store.RepoMetadata{Description: "Our iOS and Android app"}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\nrepos:\n  - name: org/mobile\n    custom_prompt: Configured context\n", dbPath))
+
+	var out bytes.Buffer
+	promptTestCmd.SetOut(&out)
+	promptTestPrintOnly = true
+	defer func() {
+		promptTestCmd.SetOut(nil)
+		promptTestPrintOnly = false
+		promptTestCustom = ""
+		promptTestCmd.Flags().Lookup("custom-prompt").Changed = false
+	}()
+
+	if err := runPromptTest(promptTestCmd, []string{"org/mobile#7"}); err != nil {
+		t.Fatalf("runPromptTest: %v", err)
+	}
+	if !strings.Contains(out.String(), "Title: Issue #7: App crashes") || !strings.Contains(out.String(), "Configured context") {
+		t.Errorf("expected prompt with issue and configured context:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Repository description: Our iOS and Android app") {
+		t.Errorf("expected prompt with the stored repo description:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "Raw output") {
+		t.Error("expected no LLM call with --print-only")
+	}
+
+	out.Reset()
+	if err := promptTestCmd.Flags().Set("custom-prompt", "Inline override"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runPromptTest(promptTestCmd, []string{"org/mobile#7"}); err != nil {
+		t.Fatalf("runPromptTest: %v", err)
+	}
+	if !strings.Contains(out.String(), "Inline override") || strings.Contains(out.String(), "Configured context") {
+		t.Errorf("expected inline prompt to replace the configured one:\n%s", out.String())
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// reembedPageSize is how many stored issues reembed reads at a time.
+const reembedPageSize = 500
+
+var reembedCmd = &cobra.Command{
+	Use:   "reembed <owner/repo>",
+	Short: "Recompute a repository's stored embeddings with the configured embedder",
+	Long: `Reembed discards a repository's stored issue and pull request embeddings and
+embeds its stored issues again with the configured embedding provider.
+
+Run it after switching embedding models. Vectors from different models
+cannot be compared, so scan, check, and watch refuse to run against a
+repository whose stored embeddings have another dimension. Pull requests
+are embedded again when next matched.
+
+If the run is interrupted, run it again: embeddings already recomputed with
+the configured model are kept.`,
+	Example:           `  triage reembed octocat/hello-world`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runReembed,
+}
+
+func init() {
+	rootCmd.AddCommand(reembedCmd)
+}
+
+func runReembed(cmd *cobra.Command, args []string) error {
+	owner, repo, err := parseRepoArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	if c.Dedup == nil {
+		return notConfigured("embedding provider", "set providers.embedding")
+	}
+	repoRecord, err := c.Store.GetRepoByOwnerRepo(owner, repo)
+	if errors.Is(err, store.ErrRepoNotFound) {
+		return fmt.Errorf("repo %s/%s is not in the store; run scan first", owner, repo)
+	}
+	if err != nil {
+		return fmt.Errorf("looking up repo: %w", err)
+	}
+
+	return reembedRepo(context.Background(), c, repoRecord, cmd.OutOrStdout())
+}
+
+// reembedRepo replaces a repo's stored embeddings with ones from the
+// configured embedder and records its model and dimension. An interrupted
+// run can be repeated: once the model is recorded, only issues without a
+// current embedding are embedded.
+func reembedRepo(ctx context.Context, c *components, repo *store.Repo, w io.Writer) error {
+	dims, err := c.embeddingDims(ctx)
+	if err != nil {
+		return err
+	}
+	info := store.EmbeddingInfo{Model: embeddingModelName(c.Config.Providers.Embedding), Dims: dims}
+
+	recorded, ok, err := c.Store.GetEmbeddingInfo(repo.ID)
+	if err != nil {
+		return err
+	}
+	var cleared int64
+	if !ok || recorded != info {
+		if cleared, err = c.Store.ClearEmbeddings(repo.ID); err != nil {
+			return err
+		}
+		if err := c.Store.SetEmbeddingInfo(repo.ID, info); err != nil {
+			return err
+		}
+	}
+
+	// A page at a time, so large repos are not held in memory at once
+	var embedded int
+	q := store.IssueQuery{Limit: reembedPageSize}
+	for {
+		stored, err := c.Store.ListIssues(repo.ID, q)
+		if err != nil {
+			return err
+		}
+		if len(stored) == 0 {
+			break
+		}
+		issues := make([]github.Issue, len(stored))
+		for i, s := range stored {
+			issues[i] = github.Issue{Number: s.Number, Title: s.Title, Body: s.Body}
+		}
+
+		n, err := c.Dedup.EmbedStale(ctx, repo.ID, issues)
+		embedded += n
+		if err != nil {
+			return fmt.Errorf("re-embedding %s after %d issues: %w", repo.FullName(), embedded, err)
+		}
+		q.AfterNumber = stored[len(stored)-1].Number
+	}
+	fmt.Fprintf(w, "Re-embedded %d issues in %s with %s (%d dimensions); cleared %d old embeddings.\n",
+		embedded, repo.FullName(), info.Model, info.Dims, cleared)
+	return nil
+}
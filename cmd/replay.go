@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/pipeline"
+	"github.com/jacklau/triage/internal/store"
+)
+
+var (
+	replaySince  string
+	replayOutput string
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <owner/repo>",
+	Short: "Re-triage stored issues with the current config",
+	Long: `Replay runs the stored issues of a repo created within --since through the
+pipeline again, with the current labels, prompts, and thresholds, and reports
+how each result compares with the issue's last logged triage. Use it to see
+what a config change would do before rolling it out.
+
+Issues are read from the local database, so run scan first. They are
+classified even if labeled, as for a re-triage request. Only issues filed
+before an issue are counted as its duplicates, as a later one could not have
+been found when it was first triaged.
+
+Nothing is notified. Without --dry-run the replayed results are recorded in
+the triage log, where "triage apply pending" picks them up; with --dry-run
+nothing is written.`,
+	Example:           `  triage replay octocat/hello-world --since 30d --dry-run`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replaySince, "since", "30d", "replay issues created within this duration (e.g. 24h, 30d)")
+	replayCmd.Flags().StringVar(&replayOutput, "output", "text", "output format: text or json")
+	registerFlagValues(replayCmd, "output", outputFormats)
+	rootCmd.AddCommand(replayCmd)
+}
+
+// Statuses of a replayed issue.
+const (
+	replaySame    = "same"
+	replayChanged = "changed"
+	replayNew     = "new" // never triaged before
+	replaySkipped = "skipped"
+	replayFailed  = "failed"
+)
+
+// replayOutcome is a triage decision as replay compares it.
+type replayOutcome struct {
+	Action     string   `json:"action"`
+	Labels     []string `json:"labels"`
+	Duplicates []int    `json:"duplicates"`
+}
+
+// replayRow compares an issue's last logged triage with its replay.
+type replayRow struct {
+	Number   int            `json:"number"`
+	Title    string         `json:"title"`
+	Status   string         `json:"status"`
+	Original *replayOutcome `json:"original,omitempty"`
+	Replay   *replayOutcome `json:"replay,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	owner, repo, err := parseRepoArg(args[0])
+	if err != nil {
+		return err
+	}
+	window, err := parseSinceDuration(replaySince)
+	if err != nil {
+		return err
+	}
+	if window <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	if replayOutput != "text" && replayOutput != "json" {
+		return fmt.Errorf("invalid --output %q: expected text or json", replayOutput)
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	repoRecord, err := c.Store.GetRepoByOwnerRepo(owner, repo)
+	if errors.Is(err, store.ErrRepoNotFound) {
+		return fmt.Errorf("%s is not tracked; run scan first", args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("looking up repo: %w", err)
+	}
+
+	issues, err := c.Store.ListIssues(repoRecord.ID, store.IssueQuery{CreatedSince: time.Now().Add(-window)})
+	if err != nil {
+		return err
+	}
+
+	p := createPipeline(c, nil, findRepoLabels(cfg, args[0]), nil)
+	rows, err := replayIssues(context.Background(), p, c.Store, repoRecord.ID, args[0], issues)
+	if err != nil {
+		return err
+	}
+
+	if replayOutput == "json" {
+		return writeReplayJSON(cmd.OutOrStdout(), args[0], rows)
+	}
+	writeReplay(cmd.OutOrStdout(), args[0], rows)
+	return nil
+}
+
+// replayIssues replays issues through p and compares each result with the
+// issue's last logged triage, read before the replay logs its own.
+func replayIssues(ctx context.Context, p *pipeline.Pipeline, db *store.DB, repoID int64, repo string, issues []store.Issue) ([]replayRow, error) {
+	rows := make([]replayRow, 0, len(issues))
+	for i := range issues {
+		issue := &issues[i]
+		row := replayRow{Number: issue.Number, Title: issue.Title}
+
+		logs, err := db.GetTriageLog(repoID, issue.Number)
+		if err != nil {
+			return nil, err
+		}
+		if l := lastTriage(logs); l != nil {
+			row.Original = loggedOutcome(*l)
+		}
+
+		result, action, err := p.ReplayIssue(ctx, repo, convertStoredIssue(issue))
+		switch {
+		case errors.Is(err, pipeline.ErrIgnored):
+			row.Status = replaySkipped
+			row.Error = err.Error()
+		case result == nil:
+			row.Status = replayFailed
+			row.Error = err.Error()
+		default:
+			row.Replay = resultOutcome(result, action)
+			if err != nil {
+				row.Error = err.Error()
+			}
+			switch {
+			case row.Original == nil:
+				row.Status = replayNew
+			case row.Original.equal(row.Replay):
+				row.Status = replaySame
+			default:
+				row.Status = replayChanged
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// lastTriage returns the newest of logs (newest first) recording a triage
+// decision rather than a human action, or nil.
+func lastTriage(logs []store.TriageLog) *store.TriageLog {
+	for i, l := range logs {
+		switch l.Action {
+		case "triaged", "duplicate", "abstained":
+			return &logs[i]
+		}
+	}
+	return nil
+}
+
+// loggedOutcome returns the decision recorded by a triage log entry.
+func loggedOutcome(l store.TriageLog) *replayOutcome {
+	out := &replayOutcome{Action: l.Action, Labels: []string{}, Duplicates: []int{}}
+	if l.SuggestedLabels != "" {
+		out.Labels = strings.Split(l.SuggestedLabels, ", ")
+		sort.Strings(out.Labels)
+	}
+	for _, ref := range strings.Split(l.DuplicateOf, ", ") {
+		if n, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+			out.Duplicates = append(out.Duplicates, n)
+		}
+	}
+	sort.Ints(out.Duplicates)
+	return out
+}
+
+// resultOutcome returns the decision of a replayed result, leaving out
+// duplicates filed after the issue.
+func resultOutcome(result *github.TriageResult, action string) *replayOutcome {
+	out := &replayOutcome{Action: action, Labels: []string{}, Duplicates: []int{}}
+	for _, l := range result.SuggestedLabels {
+		out.Labels = append(out.Labels, l.Name)
+	}
+	sort.Strings(out.Labels)
+	for _, d := range result.Duplicates {
+		if d.Number < result.IssueNumber {
+			out.Duplicates = append(out.Duplicates, d.Number)
+		}
+	}
+	sort.Ints(out.Duplicates)
+	return out
+}
+
+func (o *replayOutcome) equal(other *replayOutcome) bool {
+	return o.Action == other.Action && slices.Equal(o.Labels, other.Labels) && slices.Equal(o.Duplicates, other.Duplicates)
+}
+
+// String describes the decision in a few words, e.g. "triaged: bug, ui".
+func (o *replayOutcome) String() string {
+	if o == nil {
+		return "-"
+	}
+	switch {
+	case len(o.Duplicates) > 0:
+		refs := make([]string, len(o.Duplicates))
+		for i, n := range o.Duplicates {
+			refs[i] = fmt.Sprintf("#%d", n)
+		}
+		return o.Action + " of " + strings.Join(refs, ", ")
+	case len(o.Labels) > 0:
+		return o.Action + ": " + strings.Join(o.Labels, ", ")
+	default:
+		return o.Action
+	}
+}
+
+// replayCounts returns how many rows have each status.
+func replayCounts(rows []replayRow) map[string]int {
+	counts := map[string]int{replaySame: 0, replayChanged: 0, replayNew: 0, replaySkipped: 0, replayFailed: 0}
+	for _, r := range rows {
+		counts[r.Status]++
+	}
+	return counts
+}
+
+// writeReplay prints the comparison as a table with a summary line.
+func writeReplay(w io.Writer, repo string, rows []replayRow) {
+	mode := ""
+	if dryRun {
+		mode = " (dry run)"
+	}
+	fmt.Fprintf(w, "Replayed %d issue(s) of %s created in the last %s%s\n", len(rows), repo, replaySince, mode)
+	if len(rows) > 0 {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ISSUE\tSTATUS\tORIGINAL\tREPLAY")
+		for _, r := range rows {
+			replayed := r.Replay.String()
+			if r.Error != "" {
+				replayed = strings.Join(strings.Fields(r.Error), " ")
+				if r.Replay != nil {
+					replayed = r.Replay.String() + " (" + replayed + ")"
+				}
+			}
+			fmt.Fprintf(tw, "#%d\t%s\t%s\t%s\n", r.Number, r.Status, r.Original.String(), replayed)
+		}
+		tw.Flush()
+	}
+	counts := replayCounts(rows)
+	fmt.Fprintf(w, "\nSame: %d  Changed: %d  New: %d  Skipped: %d  Failed: %d\n",
+		counts[replaySame], counts[replayChanged], counts[replayNew], counts[replaySkipped], counts[replayFailed])
+}
+
+// replayJSON is the JSON output structure for the replay command.
+type replayJSON struct {
+	Repo    string         `json:"repo"`
+	Since   string         `json:"since"`
+	DryRun  bool           `json:"dry_run"`
+	Issues  []replayRow    `json:"issues"`
+	Summary map[string]int `json:"summary"`
+}
+
+func writeReplayJSON(w io.Writer, repo string, rows []replayRow) error {
+	data, err := json.MarshalIndent(replayJSON{
+		Repo:    repo,
+		Since:   replaySince,
+		DryRun:  dryRun,
+		Issues:  rows,
+		Summary: replayCounts(rows),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// newFakeOllama serves embeddings that put texts mentioning a crash close
+// together, and classifies every issue as a bug.
+func newFakeOllama(t *testing.T) *httptest.Server {
+	t.Helper()
+	vec := func(text string) []float32 {
+		if strings.Contains(strings.ToLower(text), "crash") {
+			return []float32{1, 0, 0}
+		}
+		return []float32{0, 1, 0}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string   `json:"prompt"`
+			Input  []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/api/embeddings":
+			json.NewEncoder(w).Encode(map[string]any{"embedding": vec(req.Prompt)})
+		case "/api/embed":
+			vecs := make([][]float32, len(req.Input))
+			for i, in := range req.Input {
+				vecs[i] = vec(in)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"embeddings": vecs})
+		case "/api/generate":
+			json.NewEncoder(w).Encode(map[string]any{"response": `{"labels":["bug"],"confidence":0.9,"reasoning":"crash"}`})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRunReplay(t *testing.T) {
+	srv := newFakeOllama(t)
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, is := range []struct {
+		number int
+		title  string
+		age    time.Duration
+	}{
+		{1, "App crash", 60 * 24 * time.Hour},
+		{10, "Crash on start", 2 * 24 * time.Hour},
+		{11, "Dark mode", 24 * time.Hour},
+	} {
+		created := time.Now().Add(-is.age)
+		if err := db.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: is.number, Title: is.title, State: "open", CreatedAt: created, UpdatedAt: created}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.UpdateEmbedding(repo.ID, 1, dedup.EncodeEmbedding([]float32{1, 0, 0}), "nomic-embed-text"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.LogTriageAction(&store.TriageLog{RepoID: repo.ID, IssueNumber: 10, Action: "triaged", SuggestedLabels: "feature"}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	writeTestConfig(t, fmt.Sprintf(`store:
+  path: %s
+providers:
+  embedding:
+    type: ollama
+    model: nomic-embed-text
+    url: %s
+  llm:
+    type: ollama
+    model: llama3
+    url: %s
+`, path, srv.URL, srv.URL))
+
+	dryRun = true
+	defer func() { dryRun = false }()
+	var out bytes.Buffer
+	replayCmd.SetOut(&out)
+	defer replayCmd.SetOut(nil)
+
+	if err := runReplay(replayCmd, []string{"org/repo"}); err != nil {
+		t.Fatalf("runReplay: %v", err)
+	}
+	for _, want := range []string{
+		"Replayed 2 issue(s) of org/repo created in the last 30d (dry run)",
+		"#10    changed  triaged: feature  duplicate of #1",
+		"#11    new      -                 triaged: bug",
+		"Same: 0  Changed: 1  New: 1  Skipped: 0  Failed: 0",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	db, err = store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if logs, _ := db.GetTriageLog(repo.ID, 11); len(logs) != 0 {
+		t.Errorf("expected a dry run to log nothing, got %+v", logs)
+	}
+
+	if err := runReplay(replayCmd, []string{"org/other"}); err == nil || !strings.Contains(err.Error(), "not tracked") {
+		t.Errorf("expected an untracked repo error, got %v", err)
+	}
+}
+
+func TestReplayOutcomes(t *testing.T) {
+	logged := loggedOutcome(store.TriageLog{Action: "duplicate", DuplicateOf: "#7, #3", SuggestedLabels: "ui, bug"})
+	if logged.String() != "duplicate of #3, #7" || strings.Join(logged.Labels, ",") != "bug,ui" {
+		t.Errorf("unexpected logged outcome %+v", logged)
+	}
+
+	// Duplicates filed later could not have been found originally
+	replayed := resultOutcome(&github.TriageResult{
+		IssueNumber:     5,
+		Duplicates:      []github.DuplicateCandidate{{Number: 7}, {Number: 3}, {Number: 9}},
+		SuggestedLabels: []github.LabelSuggestion{{Name: "ui"}, {Name: "bug"}},
+	}, "duplicate")
+	if fmt.Sprint(replayed.Duplicates) != "[3]" {
+		t.Errorf("expected only earlier duplicates, got %v", replayed.Duplicates)
+	}
+	if logged.equal(replayed) {
+		t.Error("expected the outcomes to differ")
+	}
+	replayed.Duplicates = []int{3, 7}
+	if !logged.equal(replayed) {
+		t.Error("expected the outcomes to match")
+	}
+
+	if got := (&replayOutcome{Action: "abstained"}).String(); got != "abstained" {
+		t.Errorf("String() = %q", got)
+	}
+	if l := lastTriage([]store.TriageLog{{Action: "apply_labels"}, {Action: "triaged", ID: 2}}); l == nil || l.ID != 2 {
+		t.Errorf("expected the newest triage decision, got %+v", l)
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/report"
+	"github.com/jacklau/triage/internal/store"
+)
+
+var (
+	reportSince  string
+	reportFormat string
+	reportNotify string
+)
+
+// reportFormats are the values accepted by report --format.
+var reportFormats = []string{"markdown", "slack"}
+
+var reportCmd = &cobra.Command{
+	Use:   "report [owner/repo ...]",
+	Short: "Generate a triage activity report",
+	Long: `Report summarizes recent triage activity per repository: issues triaged,
+the largest duplicate clusters, the distribution of suggested labels, the
+average label confidence, and how often humans rejected suggestions.
+
+With no arguments, every repository in the store is reported on. The report
+covers the last 7 days by default; use --since to change the window.
+
+The report is printed as Markdown, or as Slack mrkdwn with --format slack.
+Use --notify to post it to Slack and/or Discord instead:
+  triage report --notify slack
+
+To post reports automatically, run watch with --report-every 7d.`,
+	ValidArgsFunction: completeRepos(0),
+	RunE:              runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportSince, "since", "7d", "report on activity within this duration (e.g. 24h, 7d)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "markdown", "output format: markdown or slack")
+	reportCmd.Flags().StringVar(&reportNotify, "notify", "", "post the report to: slack, discord, or both")
+	registerFlagValues(reportCmd, "format", reportFormats)
+	registerFlagValues(reportCmd, "notify", notifyTargets)
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	window, err := parseSinceDuration(reportSince)
+	if err != nil {
+		return err
+	}
+	if window <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	if reportFormat != "markdown" && reportFormat != "slack" {
+		return fmt.Errorf("invalid --format %q: expected markdown or slack", reportFormat)
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	reports, err := buildReports(c.Store, args, window, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No repositories tracked yet.")
+		return nil
+	}
+
+	if reportNotify == "" {
+		return writeReports(cmd.OutOrStdout(), reports, reportFormat)
+	}
+
+	n, err := createNotifier(cfg, reportNotify, c.Store)
+	if err != nil {
+		return fmt.Errorf("creating notifier: %w", err)
+	}
+	if dryRun {
+		logger.Info("dry run: printing report instead of posting it", "notify", reportNotify)
+		return writeReports(cmd.OutOrStdout(), reports, reportFormat)
+	}
+	return postReports(context.Background(), n, reports, logger)
+}
+
+// buildReports builds a report for each named repo, or for every repo in the
+// store if none are named, covering the window that ends at now.
+func buildReports(st *store.DB, repoArgs []string, window time.Duration, now time.Time) ([]*report.Report, error) {
+	var repos []store.Repo
+	if len(repoArgs) == 0 {
+		all, err := st.ListRepos()
+		if err != nil {
+			return nil, fmt.Errorf("listing repos: %w", err)
+		}
+		repos = all
+	}
+	for _, arg := range repoArgs {
+		owner, name, err := parseRepoArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		repo, err := st.GetRepoByOwnerRepo(owner, name)
+		if errors.Is(err, store.ErrRepoNotFound) {
+			return nil, fmt.Errorf("no triage data for %s; run scan or watch first", arg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("looking up %s: %w", arg, err)
+		}
+		repos = append(repos, *repo)
+	}
+
+	since := now.Add(-window)
+	reports := make([]*report.Report, 0, len(repos))
+	for _, repo := range repos {
+		logs, err := st.ListTriageLogsSince(repo.ID, since)
+		if err != nil {
+			return nil, fmt.Errorf("reading triage log for %s: %w", repo.FullName(), err)
+		}
+		reports = append(reports, report.Build(repo.FullName(), since, now, logs))
+	}
+	return reports, nil
+}
+
+// writeReports prints reports in the given format: "markdown" or "slack".
+func writeReports(w io.Writer, reports []*report.Report, format string) error {
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		var err error
+		if format == "slack" {
+			_, err = fmt.Fprintf(w, "*%s*\n%s", r.Title(), notify.SlackMarkdown(r.Markdown()))
+		} else {
+			_, err = fmt.Fprintf(w, "## %s\n\n%s", r.Title(), r.Markdown())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postReports sends each report as a message through n.
+func postReports(ctx context.Context, n notify.Notifier, reports []*report.Report, logger *slog.Logger) error {
+	if n == nil {
+		return fmt.Errorf("no notification target configured (set notify.slack_webhook or notify.discord_webhook)")
+	}
+	var errs []error
+	for _, r := range reports {
+		if err := notify.SendMessage(ctx, n, r.Title(), r.Markdown()); err != nil {
+			errs = append(errs, fmt.Errorf("posting report for %s: %w", r.Repo, err))
+			continue
+		}
+		logger.Info("posted triage report", "repo", r.Repo, "issues_triaged", r.IssuesTriaged)
+	}
+	return errors.Join(errs...)
+}
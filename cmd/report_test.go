@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/report"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func seedReportStore(t *testing.T, path string) {
+	t.Helper()
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	defer db.Close()
+
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []store.TriageLog{
+		{RepoID: repo.ID, IssueNumber: 1, Action: "triaged", SuggestedLabels: "bug",
+			LabelConfidences: map[string]float64{"bug": 0.9}},
+		{RepoID: repo.ID, IssueNumber: 2, Action: "duplicate", DuplicateOf: "#1"},
+	} {
+		if err := db.LogTriageAction(&l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.CreateRepo("org", "quiet"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildReports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	seedReportStore(t, path)
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	reports, err := buildReports(db, nil, 7*24*time.Hour, time.Now())
+	if err != nil {
+		t.Fatalf("buildReports: %v", err)
+	}
+	if len(reports) != 2 || reports[0].Repo != "org/repo" || reports[1].Repo != "org/quiet" {
+		t.Fatalf("expected reports for every stored repo, got %+v", reports)
+	}
+	if reports[0].IssuesTriaged != 2 || reports[0].Duplicates != 1 {
+		t.Errorf("unexpected report: %+v", reports[0])
+	}
+
+	reports, err = buildReports(db, []string{"org/quiet"}, time.Hour, time.Now())
+	if err != nil || len(reports) != 1 || reports[0].IssuesTriaged != 0 {
+		t.Errorf("expected one empty report for org/quiet, got %+v, %v", reports, err)
+	}
+
+	if _, err := buildReports(db, []string{"org/missing"}, time.Hour, time.Now()); err == nil {
+		t.Error("expected error for a repo with no triage data")
+	}
+}
+
+func TestWriteReports(t *testing.T) {
+	now := time.Now()
+	r := report.Build("org/repo", now.Add(-time.Hour), now, []store.TriageLog{
+		{ID: 1, IssueNumber: 1, Action: "triaged", SuggestedLabels: "bug"},
+	})
+
+	var md bytes.Buffer
+	if err := writeReports(&md, []*report.Report{r}, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(md.String(), "## Triage report: org/repo") || !strings.Contains(md.String(), "**Issues triaged:** 1") {
+		t.Errorf("unexpected markdown output:\n%s", md.String())
+	}
+
+	var slack bytes.Buffer
+	if err := writeReports(&slack, []*report.Report{r}, "slack"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(slack.String(), "*Triage report: org/repo") || strings.Contains(slack.String(), "**") {
+		t.Errorf("unexpected slack output:\n%s", slack.String())
+	}
+}
+
+func TestPostReports(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	r := report.Build("org/repo", time.Now(), time.Now(), nil)
+	n := notify.NewSlackNotifier(server.URL)
+	if err := postReports(context.Background(), n, []*report.Report{r}, slog.Default()); err != nil {
+		t.Fatalf("postReports: %v", err)
+	}
+	if len(bodies) != 1 || !strings.Contains(bodies[0], "Triage report: org/repo") {
+		t.Errorf("expected one posted report, got %v", bodies)
+	}
+
+	if err := postReports(context.Background(), nil, []*report.Report{r}, slog.Default()); err == nil {
+		t.Error("expected error without a notifier")
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	seedReportStore(t, path)
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\n", path))
+
+	var out bytes.Buffer
+	reportCmd.SetOut(&out)
+	defer reportCmd.SetOut(nil)
+
+	if err := runReport(reportCmd, []string{"org/repo"}); err != nil {
+		t.Fatalf("runReport: %v", err)
+	}
+	for _, want := range []string{"## Triage report: org/repo", "- #1 ← #2", "- `bug`: 1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report output missing %q:\n%s", want, out.String())
+		}
+	}
+}
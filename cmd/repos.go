@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/store"
+)
+
+var (
+	reposAddThreshold float64
+	reposAddPrompt    string
+	reposAddLabels    []string
+)
+
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Manage the watched repo set",
+	Long: `Repos manages the set of watched repositories in the database, as an
+alternative to listing them in the config file. watch uses the union of
+both; if a repo appears in both, the config entry wins.`,
+}
+
+var reposAddCmd = &cobra.Command{
+	Use:   "add <owner/repo>",
+	Short: "Add a repo to the watched set",
+	Long: `Add a repo to the watched set, with optional per-repo overrides.
+Adding a repo that is already managed replaces its options.`,
+	Example: `  triage repos add octocat/hello-world --threshold 0.9 \
+    --label bug="Something isn't working" --label ios="iOS app only"`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runReposAdd,
+}
+
+var reposListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List watched repos from the config and the database",
+	Args:  cobra.NoArgs,
+	RunE:  runReposList,
+}
+
+var reposEnableCmd = &cobra.Command{
+	Use:   "enable <owner/repo>",
+	Short: "Resume watching a repo that was disabled",
+	Long: `Enable a repo that watch stopped polling because GitHub returned 404 or
+410 for it, such as after it was made private. A running watch picks the
+change up at its next poll.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runReposEnable,
+}
+
+var reposRemoveCmd = &cobra.Command{
+	Use:   "remove <owner/repo>",
+	Short: "Remove a repo from the watched set",
+	Long: `Remove a repo added with "repos add". Its stored issues and triage
+history are kept. Repos listed in the config file must be removed there.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runReposRemove,
+}
+
+func init() {
+	reposAddCmd.Flags().Float64Var(&reposAddThreshold, "threshold", 0, "similarity threshold for duplicates in this repo (0-1)")
+	reposAddCmd.Flags().StringVar(&reposAddPrompt, "prompt", "", "extra classification context for this repo")
+	reposAddCmd.Flags().StringArrayVar(&reposAddLabels, "label", nil, "label to classify into, as name or name=description (repeatable)")
+	reposCmd.AddCommand(reposAddCmd, reposListCmd, reposEnableCmd, reposRemoveCmd)
+	rootCmd.AddCommand(reposCmd)
+}
+
+// parseRepoLabels parses --label values of the form name or name=description.
+func parseRepoLabels(values []string) ([]store.RepoLabel, error) {
+	labels := make([]store.RepoLabel, 0, len(values))
+	for _, v := range values {
+		name, desc, _ := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid label %q: expected name or name=description", v)
+		}
+		labels = append(labels, store.RepoLabel{Name: name, Description: strings.TrimSpace(desc)})
+	}
+	return labels, nil
+}
+
+// mergeManagedRepos appends managed repos to the configured ones, skipping
+// any the config already lists.
+func mergeManagedRepos(cfgRepos []config.RepoConfig, managed []store.ManagedRepo) []config.RepoConfig {
+	inConfig := make(map[string]bool, len(cfgRepos))
+	for _, rc := range cfgRepos {
+		inConfig[strings.ToLower(rc.Name)] = true
+	}
+	merged := append([]config.RepoConfig(nil), cfgRepos...)
+	for _, m := range managed {
+		if inConfig[strings.ToLower(m.FullName())] {
+			continue
+		}
+		rc := config.RepoConfig{
+			Name:                m.FullName(),
+			CustomPrompt:        m.Options.CustomPrompt,
+			SimilarityThreshold: m.Options.SimilarityThreshold,
+		}
+		for _, l := range m.Options.Labels {
+			rc.Labels = append(rc.Labels, config.LabelConfig{Name: l.Name, Description: l.Description})
+		}
+		merged = append(merged, rc)
+	}
+	return merged
+}
+
+func runReposAdd(cmd *cobra.Command, args []string) error {
+	owner, repo, err := parseRepoArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	var opts store.RepoOptions
+	if cmd.Flags().Changed("threshold") {
+		if reposAddThreshold < 0 || reposAddThreshold > 1 {
+			return fmt.Errorf("--threshold must be between 0 and 1, got %g", reposAddThreshold)
+		}
+		t := reposAddThreshold
+		opts.SimilarityThreshold = &t
+	}
+	opts.CustomPrompt = reposAddPrompt
+	if opts.Labels, err = parseRepoLabels(reposAddLabels); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	db, err := store.Open(cfg.Store.Path)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	m, err := db.ManageRepo(owner, repo, opts)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Added %s to the watched repos\n", m.FullName())
+	for _, rc := range cfg.Repos {
+		if strings.EqualFold(rc.Name, m.FullName()) {
+			fmt.Fprintln(out, "Note: this repo is also in the config file, whose settings take precedence")
+			break
+		}
+	}
+	return nil
+}
+
+func runReposList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	db, err := store.Open(cfg.Store.Path)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	managed, err := db.ListManagedRepos()
+	if err != nil {
+		return err
+	}
+	records, err := db.ListRepos()
+	if err != nil {
+		return err
+	}
+	printReposList(cmd.OutOrStdout(), cfg, managed, records)
+	return nil
+}
+
+// maxRepoErrorWidth is how much of a repo's last error repos list shows.
+const maxRepoErrorWidth = 60
+
+// printReposList prints the configured and managed repos with their
+// effective per-repo settings, and the status and triage counters of those
+// with a record in the store.
+func printReposList(w io.Writer, cfg *config.Config, managed []store.ManagedRepo, records []store.Repo) {
+	cfgRepos := cfg.Repos
+	merged := mergeManagedRepos(cfgRepos, managed)
+	if len(merged) == 0 {
+		fmt.Fprintln(w, "No repos configured. Add one with: triage repos add owner/repo")
+		return
+	}
+
+	byName := make(map[string]store.Repo, len(records))
+	for _, r := range records {
+		byName[strings.ToLower(r.FullName())] = r
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tSOURCE\tTHRESHOLD\tLABELS\tPROMPT\tSTATUS\tSEEN\tTRIAGED\tDUPLICATES\tLAST ERROR")
+	for i, rc := range merged {
+		source := "db"
+		if i < len(cfgRepos) {
+			source = "config"
+		}
+		threshold := fmt.Sprintf("%.2f (default)", cfg.Defaults.SimilarityThreshold)
+		if rc.SimilarityThreshold != nil {
+			threshold = fmt.Sprintf("%.2f", *rc.SimilarityThreshold)
+		}
+		labels := "default"
+		if len(rc.Labels) > 0 {
+			names := make([]string, len(rc.Labels))
+			for j, l := range rc.Labels {
+				names[j] = l.Name
+			}
+			labels = strings.Join(names, ",")
+		}
+		prompt := "-"
+		if rc.CustomPrompt != "" {
+			prompt = "yes"
+		}
+		status, seen, triaged, dups, lastErr := "active", "-", "-", "-", "-"
+		if r, ok := byName[strings.ToLower(rc.Name)]; ok {
+			if r.DisabledAt != nil {
+				status = "disabled (" + r.DisabledReason + ")"
+			}
+			c := r.Counters
+			seen, triaged, dups = strconv.Itoa(c.IssuesSeen), strconv.Itoa(c.Triaged), strconv.Itoa(c.Duplicates)
+			if c.LastErrorAt != nil {
+				lastErr = c.LastErrorAt.Local().Format(time.DateTime) + " " + shortError(c.LastError)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", rc.Name, source, threshold, labels, prompt, status, seen, triaged, dups, lastErr)
+	}
+	tw.Flush()
+}
+
+func runReposEnable(cmd *cobra.Command, args []string) error {
+	owner, repo, err := parseRepoArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	db, err := store.Open(cfg.Store.Path)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	r, err := db.GetRepoByOwnerRepo(owner, repo)
+	if errors.Is(err, store.ErrRepoNotFound) {
+		return fmt.Errorf("%s has no record in the store", args[0])
+	}
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if r.DisabledAt == nil {
+		fmt.Fprintf(out, "%s is not disabled\n", args[0])
+		return nil
+	}
+	if err := db.EnableRepo(r.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Enabled %s (was disabled after %s)\n", args[0], r.DisabledReason)
+	return nil
+}
+
+func runReposRemove(cmd *cobra.Command, args []string) error {
+	owner, repo, err := parseRepoArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	db, err := store.Open(cfg.Store.Path)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.UnmanageRepo(owner, repo); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			for _, rc := range cfg.Repos {
+				if strings.EqualFold(rc.Name, args[0]) {
+					return fmt.Errorf("%s is listed in the config file; remove it there", args[0])
+				}
+			}
+			return fmt.Errorf("%s is not a managed repo", args[0])
+		}
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %s from the watched repos\n", args[0])
+	return nil
+}
+
+// shortError flattens msg to one line and cuts it to maxRepoErrorWidth
+// runes so a long error does not stretch the table.
+func shortError(msg string) string {
+	msg = strings.Join(strings.Fields(msg), " ")
+	if r := []rune(msg); len(r) > maxRepoErrorWidth {
+		return string(r[:maxRepoErrorWidth-3]) + "..."
+	}
+	return msg
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestParseRepoLabels(t *testing.T) {
+	labels, err := parseRepoLabels([]string{"bug=Something isn't working", "ios", " ui = Frontend "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []store.RepoLabel{
+		{Name: "bug", Description: "Something isn't working"},
+		{Name: "ios"},
+		{Name: "ui", Description: "Frontend"},
+	}
+	if fmt.Sprint(labels) != fmt.Sprint(want) {
+		t.Errorf("parseRepoLabels() = %v, want %v", labels, want)
+	}
+
+	if _, err := parseRepoLabels([]string{"=desc"}); err == nil {
+		t.Error("expected error for empty label name")
+	}
+}
+
+func TestMergeManagedRepos(t *testing.T) {
+	threshold := 0.8
+	cfgRepos := []config.RepoConfig{{Name: "org/configured", CustomPrompt: "from config"}}
+	managed := []store.ManagedRepo{
+		{Repo: store.Repo{Owner: "org", RepoName: "Configured"}, Options: store.RepoOptions{CustomPrompt: "from db"}},
+		{Repo: store.Repo{Owner: "org", RepoName: "managed"}, Options: store.RepoOptions{
+			SimilarityThreshold: &threshold,
+			Labels:              []store.RepoLabel{{Name: "ios", Description: "iOS"}},
+		}},
+	}
+
+	merged := mergeManagedRepos(cfgRepos, managed)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 repos, got %d: %+v", len(merged), merged)
+	}
+	if merged[0].CustomPrompt != "from config" {
+		t.Errorf("expected config entry to take precedence, got %q", merged[0].CustomPrompt)
+	}
+	m := merged[1]
+	if m.Name != "org/managed" || m.SimilarityThreshold == nil || *m.SimilarityThreshold != 0.8 {
+		t.Errorf("unexpected managed repo config: %+v", m)
+	}
+	if len(m.Labels) != 1 || m.Labels[0].Name != "ios" || m.Labels[0].Description != "iOS" {
+		t.Errorf("unexpected labels: %+v", m.Labels)
+	}
+	if len(cfgRepos) != 1 {
+		t.Error("expected input slice to be left unchanged")
+	}
+}
+
+func TestReposAddListRemove(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "triage.db")
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\nrepos:\n  - name: org/configured\n", dbPath))
+
+	var out bytes.Buffer
+	for _, c := range []*cobra.Command{reposAddCmd, reposListCmd, reposRemoveCmd} {
+		c.SetOut(&out)
+		defer c.SetOut(nil)
+	}
+
+	if err := reposAddCmd.Flags().Set("threshold", "0.9"); err != nil {
+		t.Fatal(err)
+	}
+	reposAddLabels = []string{"ios=iOS app"}
+	defer func() {
+		reposAddThreshold, reposAddLabels = 0, nil
+		reposAddCmd.Flags().Lookup("threshold").Changed = false
+	}()
+
+	if err := runReposAdd(reposAddCmd, []string{"org/managed"}); err != nil {
+		t.Fatalf("repos add: %v", err)
+	}
+	if err := reposAddCmd.Flags().Set("threshold", "1.5"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runReposAdd(reposAddCmd, []string{"org/other"}); err == nil {
+		t.Error("expected error for out-of-range threshold")
+	}
+
+	out.Reset()
+	if err := runReposList(reposListCmd, nil); err != nil {
+		t.Fatalf("repos list: %v", err)
+	}
+	list := out.String()
+	for _, want := range []string{"org/configured  config", "org/managed     db", "0.90", "ios"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("list output missing %q:\n%s", want, list)
+		}
+	}
+
+	if err := runReposRemove(reposRemoveCmd, []string{"org/managed"}); err != nil {
+		t.Fatalf("repos remove: %v", err)
+	}
+	err := runReposRemove(reposRemoveCmd, []string{"org/configured"})
+	if err == nil || !strings.Contains(err.Error(), "config file") {
+		t.Errorf("expected config-file hint removing a configured repo, got %v", err)
+	}
+	if err := runReposRemove(reposRemoveCmd, []string{"org/managed"}); err == nil {
+		t.Error("expected error removing an unmanaged repo")
+	}
+}
+
+func TestPrintReposListCounters(t *testing.T) {
+	cfg := &config.Config{Repos: []config.RepoConfig{{Name: "org/busy"}, {Name: "org/new"}}}
+	failedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)
+	records := []store.Repo{{Owner: "Org", RepoName: "Busy", DisabledAt: &failedAt, DisabledReason: "404 Not Found", Counters: store.RepoCounters{
+		IssuesSeen:  12,
+		Triaged:     9,
+		Duplicates:  3,
+		LastError:   "issue #7: classification:\n" + strings.Repeat("x", 100),
+		LastErrorAt: &failedAt,
+	}}}
+
+	var out bytes.Buffer
+	printReposList(&out, cfg, nil, records)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 repos, got:\n%s", out.String())
+	}
+	busy := strings.Join(strings.Fields(lines[1]), " ")
+	if !strings.Contains(busy, " disabled (404 Not Found) 12 9 3 2025-01-02 03:04:05 issue #7: classification: xxx") || !strings.HasSuffix(busy, "...") {
+		t.Errorf("expected the status, counters, and a flattened, truncated last error, got %q", lines[1])
+	}
+	if !strings.HasSuffix(strings.Join(strings.Fields(lines[2]), " "), "- active - - - -") {
+		t.Errorf("expected placeholders for a repo without a record, got %q", lines[2])
+	}
+}
+
+func TestReposEnable(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "triage.db")
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\n", dbPath))
+
+	db, err := store.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, _ := db.CreateRepo("org", "gone")
+	if _, err := db.DisableRepo(repo.ID, "404 Not Found"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	var out bytes.Buffer
+	reposEnableCmd.SetOut(&out)
+	defer reposEnableCmd.SetOut(nil)
+
+	if err := runReposEnable(reposEnableCmd, []string{"org/gone"}); err != nil {
+		t.Fatalf("repos enable: %v", err)
+	}
+	if !strings.Contains(out.String(), "Enabled org/gone (was disabled after 404 Not Found)") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	out.Reset()
+	if err := runReposEnable(reposEnableCmd, []string{"org/gone"}); err != nil || !strings.Contains(out.String(), "not disabled") {
+		t.Errorf("expected enabling again to be a no-op, got %q, %v", out.String(), err)
+	}
+	if err := runReposEnable(reposEnableCmd, []string{"org/unknown"}); err == nil {
+		t.Error("expected an error for a repo without a record")
+	}
+}
+
+func TestInitComponentsMergesManagedRepos(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "triage.db")
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\nrepos:\n  - name: org/configured\n", dbPath))
+
+	db, err := store.Open(dbPath)
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	if _, err := db.ManageRepo("org", "managed", store.RepoOptions{}); err != nil {
+		t.Fatalf("ManageRepo: %v", err)
+	}
+	db.Close()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loading config: %v", err)
+	}
+	c, err := initComponents(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("initComponents: %v", err)
+	}
+	defer c.Store.Close()
+
+	var names []string
+	for _, rc := range cfg.Repos {
+		names = append(names, rc.Name)
+	}
+	if got := strings.Join(names, ","); got != "org/configured,org/managed" {
+		t.Errorf("expected config and managed repos, got %s", got)
+	}
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -13,18 +15,26 @@ import (
 	"github.com/jacklau/triage/internal/config"
 	"github.com/jacklau/triage/internal/dedup"
 	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/jira"
 	"github.com/jacklau/triage/internal/notify"
 	"github.com/jacklau/triage/internal/pipeline"
 	"github.com/jacklau/triage/internal/provider"
 	"github.com/jacklau/triage/internal/pubsub"
+	"github.com/jacklau/triage/internal/secrets"
 	"github.com/jacklau/triage/internal/store"
 
 	gogithub "github.com/google/go-github/v60/github"
 )
 
 var (
-	cfgFile string
-	verbose bool
+	cfgFile      string
+	verbose      bool
+	dryRun       bool
+	strictConfig bool
+	// renotify is set by --renotify on the commands that notify.
+	renotify bool
+	// noCache is set by --no-cache on the commands that classify.
+	noCache bool
 )
 
 var rootCmd = &cobra.Command{
@@ -43,31 +53,61 @@ func Execute() error {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("config file (default %s)", defaultConfigPath()))
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "run dedup and classification but skip notifications, GitHub writes, and triage log writes")
+	rootCmd.PersistentFlags().BoolVar(&strictConfig, "strict-config", false, "fail on unknown config keys instead of warning")
 }
 
 func defaultConfigPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ".triage/config.yaml"
-	}
-	return home + "/.triage/config.yaml"
+	return filepath.Join(triageDir(), "config.yaml")
 }
 
+// setupLogger returns the logger commands log to. Until loadConfig applies
+// the config's logging section, it writes JSON to stderr.
 func setupLogger() *slog.Logger {
-	level := slog.LevelInfo
-	if verbose {
-		level = slog.LevelDebug
+	if !logConfigured.Load() && verbose {
+		logOutput.set(newLogHandler(os.Stderr, "json", slog.LevelDebug))
 	}
-	handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level})
-	return slog.New(handler)
+	return slog.New(logOutput)
 }
 
+// loadConfig loads the config file. Unknown keys, which are usually typos,
+// are logged as warnings, or rejected with --strict-config.
 func loadConfig() (*config.Config, error) {
-	path := cfgFile
-	if path == "" {
-		path = defaultConfigPath()
+	load := config.Load
+	if strictConfig {
+		load = config.LoadStrict
+	}
+	cfg, err := load(configPath())
+	if err != nil {
+		return nil, err
 	}
-	return config.Load(path)
+	if err := configureLogging(cfg.Logging); err != nil {
+		return nil, fmt.Errorf("logging: %w", err)
+	}
+	if len(cfg.UnknownKeys) > 0 {
+		logger := setupLogger()
+		for _, key := range cfg.UnknownKeys {
+			logger.Warn("unknown config key ignored", "path", configPath(), "key", key)
+		}
+	}
+	return cfg, nil
+}
+
+// configPath returns the --config path, else the active profile's config.
+func configPath() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	return profileConfigPath(activeProfile())
+}
+
+// notConfigured returns an error wrapping config.ErrNotConfigured saying
+// that what is not configured, with a hint on how to configure it if any.
+func notConfigured(what, hint string) error {
+	if hint == "" {
+		return fmt.Errorf("%s %w", what, config.ErrNotConfigured)
+	}
+	return fmt.Errorf("%s %w (%s)", what, config.ErrNotConfigured, hint)
 }
 
 // components holds initialized components for use by subcommands.
@@ -77,68 +117,136 @@ type components struct {
 	GHClient   *gogithub.Client
 	Embedder   provider.Embedder
 	Completer  provider.Completer
+	Meter      *provider.Meter
 	Dedup      *dedup.Engine
 	Classifier *classify.Classifier
+	// Experiment is set when the config runs an experiment.
+	Experiment *pipeline.Experiment
 	Broker     *pubsub.Broker[github.IssueEvent]
 	Logger     *slog.Logger
+	// RemoteConfig is set when defaults.remote_config is enabled.
+	RemoteConfig *github.RemoteConfigCache
+	// RepoMetadata keeps each repo's description and topics for the
+	// classifier.
+	RepoMetadata *github.RepoMetadataCache
+
+	// Set when a provider's API key comes from a secret manager.
+	embedSwap *provider.SwappableEmbedder
+	llmSwap   *provider.SwappableCompleter
+
+	// embedDims is the embedder's dimension, once probed; see
+	// checkEmbeddingDims.
+	embedDims int
+}
+
+// componentOption adjusts how initComponents builds components.
+type componentOption func(*componentSettings)
+
+type componentSettings struct {
+	// noCache leaves out the classifier's result cache.
+	noCache bool
+	// githubToken authenticates the GitHub client when github.auth is
+	// not set.
+	githubToken string
+}
+
+// withNoCache classifies every issue with the LLM, ignoring cached results,
+// when skip is set.
+func withNoCache(skip bool) componentOption {
+	return func(s *componentSettings) { s.noCache = skip }
+}
+
+// withGitHubToken authenticates GitHub with token when the config sets no
+// github.auth, such as the workflow's GITHUB_TOKEN in an Action.
+func withGitHubToken(token string) componentOption {
+	return func(s *componentSettings) { s.githubToken = token }
 }
 
 // initComponents creates all components from config.
-func initComponents(cfg *config.Config, logger *slog.Logger) (*components, error) {
+func initComponents(cfg *config.Config, logger *slog.Logger, options ...componentOption) (*components, error) {
+	var settings componentSettings
+	for _, o := range options {
+		o(&settings)
+	}
 	c := &components{
 		Config: cfg,
 		Logger: logger,
 	}
 
+	// Resolve vault:// and aws-sm:// references
+	if err := cfg.ResolveSecrets(context.Background(), secrets.NewResolver()); err != nil {
+		return nil, fmt.Errorf("resolving secrets: %w", err)
+	}
+
 	// Open store
-	db, err := store.Open(cfg.Store.Path)
+	var storeOpts []store.Option
+	if cfg.Store.ReadConnections > 0 {
+		storeOpts = append(storeOpts, store.WithReadReplica(cfg.Store.ReadConnections))
+	}
+	db, err := store.Open(cfg.Store.Path, storeOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("opening store: %w", err)
 	}
 	c.Store = db
 
-	// Create GitHub client
-	if cfg.GitHub.Auth == "app" {
-		appID, err := strconv.ParseInt(cfg.GitHub.AppID, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("parsing app_id: %w", err)
-		}
-		installID, err := strconv.ParseInt(cfg.GitHub.InstallationID, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("parsing installation_id: %w", err)
-		}
-		client, err := github.NewGitHubClient(appID, installID, []byte(cfg.GitHub.PrivateKey), cfg.GitHub.PrivateKeyPath)
-		if err != nil {
-			return nil, fmt.Errorf("creating GitHub client: %w", err)
+	// Add repos managed from the CLI; config entries take precedence
+	if managed, err := db.ListManagedRepos(); err != nil {
+		logger.Warn("failed to load managed repos", "error", err)
+	} else {
+		cfg.Repos = mergeManagedRepos(cfg.Repos, managed)
+	}
+
+	c.GHClient, err = newGitHubClient(cfg.GitHub, settings.githubToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Per-repo settings from each repo's .github/triage.yml
+	if cfg.Defaults.RemoteConfig {
+		if c.GHClient == nil {
+			logger.Warn("defaults.remote_config needs github.auth; ignoring it")
+		} else {
+			ttl, _ := cfg.Defaults.RemoteConfigTTL() // validated on load
+			c.RemoteConfig = github.NewRemoteConfigCache(c.GHClient, ttl, cfg.Packs)
 		}
-		c.GHClient = client
 	}
 
-	// Create embedding provider
-	switch cfg.Providers.Embedding.Type {
-	case "openai":
-		c.Embedder = provider.NewOpenAIEmbedder(cfg.Providers.Embedding.APIKey, cfg.Providers.Embedding.Model)
-	case "ollama":
-		c.Embedder = provider.NewOllamaEmbedder(cfg.Providers.Embedding.URL, cfg.Providers.Embedding.Model)
-	case "":
-		// No embedding provider configured
-	default:
-		return nil, fmt.Errorf("unsupported embedding provider type: %q", cfg.Providers.Embedding.Type)
+	c.RepoMetadata = github.NewRepoMetadataCache(c.GHClient, c.Store, github.DefaultRepoMetadataTTL)
+
+	// Create providers. Keys from a secret manager can be rotated while
+	// watch runs, so those providers are swappable; see refreshSecrets.
+	embedder, err := newEmbedder(cfg.Providers.Embedding)
+	if err != nil {
+		return nil, err
 	}
+	if _, ok := cfg.SecretRefs["providers.embedding.api_key"]; ok && embedder != nil {
+		c.embedSwap = provider.NewSwappableEmbedder(embedder)
+		embedder = c.embedSwap
+	}
+	c.Embedder = embedder
 
-	// Create LLM provider
-	switch cfg.Providers.LLM.Type {
-	case "openai":
-		c.Completer = provider.NewOpenAICompleter(cfg.Providers.LLM.APIKey, cfg.Providers.LLM.Model)
-	case "anthropic":
-		c.Completer = provider.NewAnthropicCompleter(cfg.Providers.LLM.APIKey, cfg.Providers.LLM.Model)
-	case "ollama":
-		c.Completer = provider.NewOllamaCompleter(cfg.Providers.LLM.URL, cfg.Providers.LLM.Model)
-	case "":
-		// No LLM provider configured
-	default:
-		return nil, fmt.Errorf("unsupported LLM provider type: %q", cfg.Providers.LLM.Type)
+	completer, err := newCompleter(cfg.Providers.LLM)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.SecretRefs["providers.llm.api_key"]; ok && completer != nil {
+		c.llmSwap = provider.NewSwappableCompleter(completer)
+		completer = c.llmSwap
 	}
+	c.Completer = completer
+
+	// Bound every provider call, so one hung request cannot stall a worker
+	timeout, err := cfg.Defaults.RequestTimeout()
+	if err != nil {
+		timeout = 30 * time.Second
+	}
+	c.Embedder = provider.TimeoutEmbedder(c.Embedder, timeout)
+	c.Completer = provider.TimeoutCompleter(c.Completer, timeout)
+
+	// Meter provider calls so commands can report usage and estimate spend
+	c.Meter = &provider.Meter{}
+	c.Embedder = c.Meter.Embedder(c.Embedder)
+	c.Completer = c.Meter.Completer(c.Completer)
 
 	// Create dedup engine
 	if c.Embedder != nil {
@@ -146,16 +254,52 @@ func initComponents(cfg *config.Config, logger *slog.Logger) (*components, error
 			dedup.WithThreshold(float32(cfg.Defaults.SimilarityThreshold)),
 			dedup.WithMaxCandidates(cfg.Defaults.MaxDuplicatesShown),
 		}
+		if cfg.Defaults.MatchPullRequests {
+			opts = append(opts, dedup.WithPullRequests(db))
+		}
+		if cfg.Defaults.EmbeddingCache {
+			opts = append(opts, dedup.WithCache())
+		}
+		if emb := cfg.Providers.Embedding; emb.DocumentPrefix != "" || emb.QueryPrefix != "" {
+			opts = append(opts, dedup.WithPrefixes(emb.DocumentPrefix, emb.QueryPrefix))
+		}
 		c.Dedup = dedup.NewEngine(c.Embedder, db, opts...)
 	}
 
 	// Create classifier
 	if c.Completer != nil {
-		timeout, err := cfg.Defaults.RequestTimeout()
-		if err != nil {
-			timeout = 30 * time.Second
+		levels := cfg.Defaults.ConfidenceLevels
+		multiPass := cfg.Defaults.MultiPassThreshold
+		if multiPass == 0 {
+			multiPass = classify.DefaultMultiPassThreshold
+		}
+		classifierOpts := func(llm config.ProviderConfig) []classify.Option {
+			opts := []classify.Option{
+				classify.WithAliases(cfg.Aliases),
+				classify.WithConfidenceLevels(levels.Suggested, levels.Possible),
+				classify.WithMultiPass(multiPass),
+				classify.WithLanguage(cfg.Notify.Language),
+			}
+			if !settings.noCache {
+				opts = append(opts, classify.WithCache(db, providerName(llm)))
+			}
+			return opts
+		}
+		c.Classifier = classify.NewClassifier(c.Completer, timeout, classifierOpts(cfg.Providers.LLM)...)
+
+		// Classify a share of issues with the experiment's prompt or model
+		if exp := cfg.Experiment; exp.Enabled() {
+			c.Experiment = &pipeline.Experiment{Name: exp.Variant(), Percent: exp.Percent, Prompt: exp.Prompt}
+			if exp.LLM.Type != "" {
+				c.Experiment.Provider = providerName(exp.LLM)
+				alt, err := newCompleter(exp.LLM)
+				if err != nil {
+					return nil, fmt.Errorf("experiment: %w", err)
+				}
+				alt = c.Meter.Completer(provider.TimeoutCompleter(alt, timeout))
+				c.Experiment.Classifier = classify.NewClassifier(alt, timeout, classifierOpts(exp.LLM)...)
+			}
 		}
-		c.Classifier = classify.NewClassifier(c.Completer, timeout)
 	}
 
 	// Create broker
@@ -164,12 +308,84 @@ func initComponents(cfg *config.Config, logger *slog.Logger) (*components, error
 	return c, nil
 }
 
-// createNotifier builds a Notifier from config and flag override.
-func createNotifier(cfg *config.Config, notifyFlag string) (notify.Notifier, error) {
+// providerName names the provider pc describes, such as "ollama:llama3",
+// or "" if none is configured.
+func providerName(pc config.ProviderConfig) string {
+	if pc.Type == "" {
+		return ""
+	}
+	return pc.Type + ":" + pc.Model
+}
+
+// newEmbedder creates the embedding provider described by pc, or nil if
+// none is configured.
+// newGitHubClient creates the GitHub client gc configures, or one
+// authenticated with fallbackToken if gc sets no auth. It returns nil if
+// neither is set.
+func newGitHubClient(gc config.GitHubConfig, fallbackToken string) (*gogithub.Client, error) {
+	switch {
+	case gc.Auth == "app":
+		appID, err := strconv.ParseInt(gc.AppID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing app_id: %w", err)
+		}
+		installID, err := strconv.ParseInt(gc.InstallationID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing installation_id: %w", err)
+		}
+		client, err := github.NewGitHubClient(appID, installID, []byte(gc.PrivateKey), gc.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("creating GitHub client: %w", err)
+		}
+		return client, nil
+	case gc.Auth == "token":
+		return github.NewTokenClient(gc.Token), nil
+	case fallbackToken != "":
+		return github.NewTokenClient(fallbackToken), nil
+	}
+	return nil, nil
+}
+
+func newEmbedder(pc config.ProviderConfig) (provider.Embedder, error) {
+	switch pc.Type {
+	case "openai":
+		return provider.NewOpenAIEmbedder(pc.APIKey, pc.Model), nil
+	case "ollama":
+		return provider.NewOllamaEmbedder(pc.URL, pc.Model), nil
+	case "":
+		return nil, nil // no embedding provider configured
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider type: %q", pc.Type)
+	}
+}
+
+// newCompleter creates the LLM provider described by pc, or nil if none is
+// configured.
+func newCompleter(pc config.ProviderConfig) (provider.Completer, error) {
+	switch pc.Type {
+	case "openai":
+		return provider.NewOpenAICompleter(pc.APIKey, pc.Model), nil
+	case "anthropic":
+		return provider.NewAnthropicCompleter(pc.APIKey, pc.Model,
+			provider.WithMaxTokens(pc.MaxTokens),
+			provider.WithStopSequences(pc.StopSequences)), nil
+	case "ollama":
+		return provider.NewOllamaCompleter(pc.URL, pc.Model), nil
+	case "":
+		return nil, nil // no LLM provider configured
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider type: %q", pc.Type)
+	}
+}
+
+// createNotifier builds the notifier selected by notifyFlag, or by the
+// config when it is empty. threads, if non-nil, records Slack threads when
+// posting as a bot and Discord forum posts.
+func createNotifier(cfg *config.Config, notifyFlag string, threads notify.ThreadStore) (notify.Notifier, error) {
 	notifyType := notifyFlag
 	if notifyType == "" {
 		// Determine from config
-		hasSlack := cfg.Notify.SlackWebhook != ""
+		hasSlack := cfg.Notify.SlackWebhook != "" || cfg.Notify.SlackBotToken != ""
 		hasDiscord := cfg.Notify.DiscordWebhook != ""
 		switch {
 		case hasSlack && hasDiscord:
@@ -183,43 +399,129 @@ func createNotifier(cfg *config.Config, notifyFlag string) (notify.Notifier, err
 		}
 	}
 
-	return notify.NewNotifier(notifyType, cfg.Notify.SlackWebhook, cfg.Notify.DiscordWebhook)
+	style := notifyStyle(cfg)
+	slackMentions := make(notify.Mentions)
+	discordMentions := make(notify.Mentions)
+	for label, m := range cfg.Notify.Mentions {
+		slackMentions[label] = m.Slack
+		discordMentions[label] = m.Discord
+	}
+	opts := notify.Options{
+		Slack:   []notify.SlackOption{notify.WithSlackStyle(style), notify.WithSlackMentions(slackMentions)},
+		Discord: []notify.DiscordOption{notify.WithDiscordStyle(style), notify.WithDiscordMentions(discordMentions)},
+	}
+	if cfg.Notify.SlackBotToken != "" {
+		opts.Slack = append(opts.Slack, notify.WithSlackBot(cfg.Notify.SlackBotToken, cfg.Notify.SlackChannel, threads))
+	}
+	if cfg.Notify.DiscordForum {
+		opts.Discord = append(opts.Discord, notify.WithDiscordForum(threads))
+	}
+	return notify.NewNotifier(notifyType, cfg.Notify.SlackWebhook, cfg.Notify.DiscordWebhook, opts)
+}
+
+// notifyStyle returns the notification style the config sets: its severity
+// emoji and the confidence that counts as confident.
+func notifyStyle(cfg *config.Config) notify.Style {
+	return notify.Style{
+		Emoji: map[notify.Severity]string{
+			notify.SeverityHigh:   cfg.Notify.Emoji.High,
+			notify.SeverityMedium: cfg.Notify.Emoji.Medium,
+			notify.SeverityLow:    cfg.Notify.Emoji.Low,
+		},
+		SuggestedConfidence: cfg.Defaults.ConfidenceLevels.Suggested,
+	}
 }
 
 // createPoller builds a Poller for the specified repo.
 func createPoller(c *components, owner, repo string) *github.Poller {
-	return github.NewPoller(c.GHClient, c.Store, c.Broker, owner, repo)
+	p := github.NewPoller(c.GHClient, c.Store, c.Broker, owner, repo)
+	p.SetRetriageCommands(c.Config.Defaults.RetriageCommands)
+	p.SetTrackPullRequests(c.Config.Defaults.MatchPullRequests)
+	interval, _ := c.Config.Defaults.ReconcileInterval() // validated on load
+	p.SetReconcileInterval(interval)
+	return p
 }
 
-// createPipeline builds a Pipeline from components.
-func createPipeline(c *components, n notify.Notifier, labels []config.LabelConfig) *pipeline.Pipeline {
-	return pipeline.New(pipeline.PipelineDeps{
-		Dedup:       c.Dedup,
-		Classifier:  c.Classifier,
-		Notifier:    n,
-		Store:       c.Store,
-		Broker:      c.Broker,
-		Labels:      labels,
-		RepoConfigs: c.Config.Repos,
-		Logger:      c.Logger,
-	})
+// createPipeline builds a Pipeline from components. skipLabeled, if
+// non-nil, overrides skip_if_labeled for every repo.
+func createPipeline(c *components, n notify.Notifier, labels []config.LabelConfig, skipLabeled *bool) *pipeline.Pipeline {
+	deps := pipeline.PipelineDeps{
+		Dedup:                 c.Dedup,
+		Classifier:            c.Classifier,
+		Notifier:              n,
+		Store:                 c.Store,
+		Broker:                c.Broker,
+		Labels:                labels,
+		RepoConfigs:           c.Config.Repos,
+		OrgDefaults:           c.Config.OrgDefaults,
+		RepoExclude:           c.Config.Exclude,
+		LabelPacks:            c.Config.Packs,
+		SkipIfLabeled:         c.Config.Defaults.SkipIfLabeled,
+		SkipIfLabeledOverride: skipLabeled,
+		TransferSuggestions:   c.Config.Defaults.TransferSuggestions,
+		ParallelClassify:      c.Config.Defaults.ParallelClassify,
+		Abstain:               c.Config.Defaults.Abstain,
+		ConfidenceThreshold:   c.Config.Defaults.ConfidenceThreshold,
+		Logger:                c.Logger,
+		DryRun:                dryRun,
+		Renotify:              renotify,
+		KeepRawResponses:      c.Config.Store.KeepRawResponses,
+		Experiment:            c.Experiment,
+		EmbeddingProvider:     providerName(c.Config.Providers.Embedding),
+		LLMProvider:           providerName(c.Config.Providers.LLM),
+	}
+	deps.ProcessTimeout, _ = c.Config.Pipeline.Timeout() // validated on load
+	if c.RemoteConfig != nil {
+		deps.RemoteConfig = c.RemoteConfig
+	}
+	if c.RepoMetadata != nil {
+		deps.RepoMetadata = c.RepoMetadata
+	}
+	return pipeline.New(deps)
+}
+
+// newWriter returns the Writer commands use to change issues: one that only
+// logs under --dry-run, else one backed by c.GHClient.
+func newWriter(c *components) github.Writer {
+	if dryRun {
+		return github.NewDryRunWriter(c.Logger)
+	}
+	return github.NewWriter(c.GHClient, c.Logger)
+}
+
+// newJiraSyncer returns a Syncer for integrations.jira, or nil if it is not
+// configured.
+func newJiraSyncer(c *components) *jira.Syncer {
+	jc := c.Config.Integrations.Jira
+	if !jc.Enabled() {
+		return nil
+	}
+	client := jira.NewClient(jc.URL, jc.Email, jc.APIToken)
+	return jira.NewSyncer(client, c.Store, jc, c.Logger)
 }
 
-// findRepoLabels looks up configured labels for a given owner/repo, falling back to defaults.
+// findRepoLabels looks up configured labels for a given owner/repo, including
+// those set by org defaults, repo patterns, and label packs, falling back to
+// the built-in github pack.
 func findRepoLabels(cfg *config.Config, fullName string) []config.LabelConfig {
-	for _, rc := range cfg.Repos {
-		if rc.Name == fullName {
-			if len(rc.Labels) > 0 {
-				return rc.Labels
-			}
-		}
+	if rc, ok := cfg.Repo(fullName); ok && len(rc.Labels) > 0 {
+		return rc.Labels
 	}
-	// Return a default set of labels
-	return []config.LabelConfig{
-		{Name: "bug", Description: "Something isn't working"},
-		{Name: "feature", Description: "New feature or request"},
-		{Name: "question", Description: "Further information is requested"},
-		{Name: "documentation", Description: "Improvements or additions to documentation"},
-		{Name: "enhancement", Description: "Improvement to an existing feature"},
+	return config.BuiltinPack("github")
+}
+
+// findRepoThreshold returns the duplicate similarity threshold for a repo,
+// honoring per-repo overrides.
+func findRepoThreshold(cfg *config.Config, fullName string) float64 {
+	if rc, ok := cfg.Repo(fullName); ok && rc.SimilarityThreshold != nil {
+		return *rc.SimilarityThreshold
 	}
+	return cfg.Defaults.SimilarityThreshold
+}
+
+// findRepoGuidance returns the custom classification prompt, examples, and
+// language configured for a repo.
+func findRepoGuidance(cfg *config.Config, fullName string) classify.Guidance {
+	rc, _ := cfg.Repo(fullName)
+	return classify.Guidance{CustomPrompt: rc.CustomPrompt, Examples: rc.Examples, Language: rc.Language}
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"log/slog"
 	"strings"
 	"testing"
@@ -9,6 +10,19 @@ import (
 	"github.com/jacklau/triage/internal/notify"
 )
 
+func TestNotConfigured(t *testing.T) {
+	err := notConfigured("GitHub client", "set github.auth in config")
+	if !errors.Is(err, config.ErrNotConfigured) {
+		t.Errorf("expected config.ErrNotConfigured, got %v", err)
+	}
+	if got := err.Error(); got != "GitHub client not configured (set github.auth in config)" {
+		t.Errorf("unexpected message %q", got)
+	}
+	if got := notConfigured("embedding provider", "").Error(); got != "embedding provider not configured" {
+		t.Errorf("unexpected message %q", got)
+	}
+}
+
 func TestCreateNotifier(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -147,7 +161,7 @@ func TestCreateNotifier(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			n, err := createNotifier(tt.cfg, tt.notifyFlag)
+			n, err := createNotifier(tt.cfg, tt.notifyFlag, nil)
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -174,7 +188,7 @@ func TestCreateNotifierTypes(t *testing.T) {
 				SlackWebhook: "https://hooks.slack.com/services/xxx",
 			},
 		}
-		n, err := createNotifier(cfg, "slack")
+		n, err := createNotifier(cfg, "slack", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -189,7 +203,7 @@ func TestCreateNotifierTypes(t *testing.T) {
 				DiscordWebhook: "https://discord.com/api/webhooks/xxx",
 			},
 		}
-		n, err := createNotifier(cfg, "discord")
+		n, err := createNotifier(cfg, "discord", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -205,7 +219,7 @@ func TestCreateNotifierTypes(t *testing.T) {
 				DiscordWebhook: "https://discord.com/api/webhooks/xxx",
 			},
 		}
-		n, err := createNotifier(cfg, "both")
+		n, err := createNotifier(cfg, "both", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -412,6 +426,36 @@ func TestInitComponentsWithOllamaProviders(t *testing.T) {
 	}
 }
 
+func TestInitComponentsWithGitHubToken(t *testing.T) {
+	cfg := &config.Config{
+		Store:    config.StoreConfig{Path: ":memory:"},
+		Defaults: config.DefaultsConfig{RemoteConfig: true},
+	}
+
+	c, err := initComponents(cfg, slog.Default(), withGitHubToken("ghs_workflow"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Store.Close()
+
+	if c.GHClient == nil {
+		t.Fatal("expected the fallback token to create a GitHub client")
+	}
+	if c.RemoteConfig == nil {
+		t.Error("expected the fallback client to enable remote config")
+	}
+
+	// Without a token or github.auth there is no client
+	c, err = initComponents(cfg, slog.Default(), withGitHubToken(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Store.Close()
+	if c.GHClient != nil || c.RemoteConfig != nil {
+		t.Error("expected no GitHub client without a token")
+	}
+}
+
 func TestInitComponentsWithAnthropicLLM(t *testing.T) {
 	cfg := &config.Config{
 		Store: config.StoreConfig{
@@ -676,7 +720,11 @@ func TestBuildConfigYAML(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := buildConfigYAML(tt.appID, tt.keyPath, tt.embedProvider, tt.llmProvider, tt.slackURL, tt.discordURL)
+			result := buildConfigYAML(initOptions{
+				AppID: tt.appID, KeyPath: tt.keyPath,
+				EmbedProvider: tt.embedProvider, LLMProvider: tt.llmProvider,
+				SlackURL: tt.slackURL, DiscordURL: tt.discordURL,
+			})
 			for _, want := range tt.wantContains {
 				if !strings.Contains(result, want) {
 					t.Errorf("expected config to contain %q, but it did not.\nConfig:\n%s", want, result)
@@ -690,3 +738,61 @@ func TestBuildConfigYAML(t *testing.T) {
 		})
 	}
 }
+
+func TestFindRepoThreshold(t *testing.T) {
+	override := 0.95
+	cfg := &config.Config{
+		Defaults: config.DefaultsConfig{SimilarityThreshold: 0.85},
+		Repos:    []config.RepoConfig{{Name: "owner/strict", SimilarityThreshold: &override}},
+	}
+	if got := findRepoThreshold(cfg, "owner/strict"); got != 0.95 {
+		t.Errorf("expected per-repo override 0.95, got %f", got)
+	}
+	if got := findRepoThreshold(cfg, "owner/other"); got != 0.85 {
+		t.Errorf("expected default 0.85, got %f", got)
+	}
+}
+
+func TestFindRepoGuidance(t *testing.T) {
+	examples := []config.Example{{Title: "Login loops", Labels: []string{"auth"}}}
+	cfg := &config.Config{
+		Repos: []config.RepoConfig{{Name: "owner/mobile", CustomPrompt: "Mobile app", Examples: examples, Language: "ja"}},
+	}
+	got := findRepoGuidance(cfg, "owner/mobile")
+	if got.CustomPrompt != "Mobile app" || got.Language != "ja" {
+		t.Errorf("expected configured prompt and language, got %+v", got)
+	}
+	if len(got.Examples) != 1 || got.Examples[0].Title != "Login loops" {
+		t.Errorf("expected configured examples, got %+v", got.Examples)
+	}
+	if got := findRepoGuidance(cfg, "owner/other"); got.CustomPrompt != "" || got.Examples != nil {
+		t.Errorf("expected no guidance, got %+v", got)
+	}
+}
+
+func TestFindRepoSettingsFromPattern(t *testing.T) {
+	orgThreshold := 0.9
+	cfg := &config.Config{
+		Defaults: config.DefaultsConfig{SimilarityThreshold: 0.85},
+		Repos: []config.RepoConfig{{
+			Name:                "myorg/*",
+			Labels:              []config.LabelConfig{{Name: "team-bug"}},
+			CustomPrompt:        "Org prompt",
+			SimilarityThreshold: &orgThreshold,
+		}},
+		Exclude: []string{"myorg/infra-*"},
+	}
+
+	if got := findRepoLabels(cfg, "myorg/api"); len(got) != 1 || got[0].Name != "team-bug" {
+		t.Errorf("expected pattern labels, got %+v", got)
+	}
+	if got := findRepoThreshold(cfg, "myorg/api"); got != 0.9 {
+		t.Errorf("expected pattern threshold 0.9, got %f", got)
+	}
+	if got := findRepoGuidance(cfg, "myorg/api").CustomPrompt; got != "Org prompt" {
+		t.Errorf("expected pattern prompt, got %q", got)
+	}
+	if got := findRepoThreshold(cfg, "myorg/infra-dns"); got != 0.85 {
+		t.Errorf("expected default threshold for excluded repo, got %f", got)
+	}
+}
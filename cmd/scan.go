@@ -3,7 +3,9 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
@@ -14,8 +16,10 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/jacklau/triage/internal/config"
 	"github.com/jacklau/triage/internal/github"
 	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/pipeline"
 	"github.com/jacklau/triage/internal/store"
 
 	gogithub "github.com/google/go-github/v60/github"
@@ -26,31 +30,119 @@ var (
 	scanOutput  string
 	scanSince   string
 	scanWorkers int
+	scanResume  bool
+
+	scanState    string
+	scanLabels   []string
+	scanNoLabels []string
+	scanAuthor   string
+	scanIssues   string
+
+	scanFailOnDuplicates bool
+	scanFailAbove        float64
+
+	scanBudgetFlag string
+
+	scanOnlyUnlabeled  bool
+	scanIncludeLabeled bool
+
+	scanMaxFailures float64
+
+	scanProgress string
+
+	scanFetchConcurrency int
 )
 
 const defaultScanWorkers = 5
 
+// errScanBudget stops fetching pages once the budget stops dispatching.
+var errScanBudget = errors.New("scan budget reached")
+
 var scanCmd = &cobra.Command{
 	Use:   "scan <owner/repo>",
 	Short: "One-shot full scan of all open issues",
 	Long: `Scan fetches all open issues from a repository, computes embeddings,
-runs dedup detection across all issues, classifies unlabeled issues,
-and sends a summary notification.
+runs dedup detection across all issues, classifies them, and sends a
+summary notification.
+
+Whether issues that already have one of the configured labels are
+classified follows skip_if_labeled. --only-unlabeled skips classifying
+them (they are still checked for duplicates), and --include-labeled
+classifies every issue, for all repos regardless of the config.
 
 Use --since to limit scanning to recently updated issues (e.g. --since 24h).
-Use --output json to get structured JSON output.`,
-	Args: cobra.ExactArgs(1),
-	RunE: runScan,
+Use --label, --no-label, --author, --state, and --issues to target a subset:
+  triage scan owner/repo --no-label triaged --issues 100-200
+Use --output json to get structured JSON output.
+
+For CI, --fail-on-duplicates (or --fail-if-duplicate-above <score>) makes
+the command exit with status 2 when the scanned issues contain duplicates.
+
+Issues are fetched oldest first. Each page is processed as soon as it
+arrives, while later pages are fetched --fetch-concurrency at a time, so an
+issue is checked for duplicates against every issue filed before it.
+
+Progress is checkpointed in the store as issues are processed. If a scan is
+interrupted, rerun it with the same options plus --resume to skip issues
+that were already processed.
+
+The progress bar shows an ETA based on recent throughput, so it slows when
+a provider is rate limiting, along with the remaining GitHub API quota.
+--progress json replaces it with one JSON event per line on stderr, with
+the processed, total, and failed counts and the issue that just finished.
+--budget stops dispatching issues before the estimated provider spend would
+exceed a limit, given in dollars (e.g. --budget '$5', which requires
+cost_per_mtok in the provider config) or tokens (e.g. --budget 500k). A
+scan stopped by its budget can be continued later with --resume.
+
+Issues that fail, such as when a provider is down, are listed with the
+reason at the end, and are processed again by --resume. If more than
+--max-failures percent of the processed issues failed, scan exits with
+status 3.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runScan,
 }
 
 func init() {
 	scanCmd.Flags().StringVar(&scanNotify, "notify", "", "notification target: slack, discord, or both")
 	scanCmd.Flags().StringVar(&scanOutput, "output", "text", "output format: text or json")
+	scanCmd.Flags().BoolVar(&renotify, "renotify", false, "notify results even if an identical one was already sent")
+	scanCmd.Flags().BoolVar(&noCache, "no-cache", false, "classify every issue with the LLM, ignoring cached results")
 	scanCmd.Flags().StringVar(&scanSince, "since", "", "only process issues updated within this duration (e.g. 24h, 7d)")
 	scanCmd.Flags().IntVar(&scanWorkers, "workers", defaultScanWorkers, "number of concurrent workers for issue processing")
+	scanCmd.Flags().BoolVar(&scanResume, "resume", false, "continue the last interrupted scan with the same options")
+	scanCmd.Flags().StringVar(&scanState, "state", "open", "issue state to scan: open, closed, or all")
+	scanCmd.Flags().StringSliceVar(&scanLabels, "label", nil, "only scan issues with all of these labels (repeatable)")
+	scanCmd.Flags().StringSliceVar(&scanNoLabels, "no-label", nil, "skip issues with any of these labels (repeatable)")
+	scanCmd.Flags().StringVar(&scanAuthor, "author", "", "only scan issues opened by this user")
+	scanCmd.Flags().StringVar(&scanIssues, "issues", "", "only scan these issue numbers or ranges (e.g. 100-200,250)")
+	scanCmd.Flags().BoolVar(&scanFailOnDuplicates, "fail-on-duplicates", false, "exit with status 2 if any potential duplicates are found")
+	scanCmd.Flags().Float64Var(&scanFailAbove, "fail-if-duplicate-above", 0, "exit with status 2 if any duplicate scores above this similarity (0-1)")
+	scanCmd.Flags().StringVar(&scanBudgetFlag, "budget", "", "stop before estimated provider spend exceeds this many dollars ($5) or tokens (500k)")
+	scanCmd.Flags().BoolVar(&scanOnlyUnlabeled, "only-unlabeled", false, "skip classifying issues that already have a configured label")
+	scanCmd.Flags().BoolVar(&scanIncludeLabeled, "include-labeled", false, "classify issues even if they already have a configured label")
+	scanCmd.MarkFlagsMutuallyExclusive("only-unlabeled", "include-labeled")
+	scanCmd.Flags().StringVar(&scanProgress, "progress", "bar", "progress output on stderr: bar or json")
+	scanCmd.Flags().IntVar(&scanFetchConcurrency, "fetch-concurrency", defaultFetchConcurrency, "number of pages of issues to fetch from GitHub at once")
+	scanCmd.Flags().Float64Var(&scanMaxFailures, "max-failures", 0, "exit with status 3 if more than this percent of issues fail (0-100)")
+	registerFlagValues(scanCmd, "notify", notifyTargets)
+	registerFlagValues(scanCmd, "output", outputFormats)
+	registerFlagValues(scanCmd, "state", issueStates)
+	registerFlagValues(scanCmd, "progress", progressFormats)
 	rootCmd.AddCommand(scanCmd)
 }
 
+// scanSkipLabeled returns the skip_if_labeled override set by
+// --only-unlabeled or --include-labeled, or nil to follow the config.
+func scanSkipLabeled() *bool {
+	if !scanOnlyUnlabeled && !scanIncludeLabeled {
+		return nil
+	}
+	skip := scanOnlyUnlabeled
+	return &skip
+}
+
 // parseSinceDuration parses a duration string that supports standard Go duration
 // syntax plus a "d" suffix for days (e.g. "7d" = 7*24h).
 func parseSinceDuration(s string) (time.Duration, error) {
@@ -89,6 +181,30 @@ func runScan(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	filter, err := newScanFilter(scanState, scanLabels, scanNoLabels, scanAuthor, scanIssues)
+	if err != nil {
+		return err
+	}
+
+	gate, err := newDuplicateGate(scanFailOnDuplicates, scanFailAbove)
+	if err != nil {
+		return err
+	}
+	if scanMaxFailures < 0 || scanMaxFailures > 100 {
+		return fmt.Errorf("--max-failures must be between 0 and 100, got %g", scanMaxFailures)
+	}
+	if err := validateProgressFormat(scanProgress); err != nil {
+		return err
+	}
+	if scanFetchConcurrency < 1 {
+		return fmt.Errorf("--fetch-concurrency must be at least 1, got %d", scanFetchConcurrency)
+	}
+
+	budget, err := parseBudget(scanBudgetFlag)
+	if err != nil {
+		return err
+	}
+
 	logger := setupLogger()
 
 	cfg, err := loadConfig()
@@ -96,14 +212,18 @@ func runScan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	c, err := initComponents(cfg, logger)
+	if budget.USD > 0 && cfg.Providers.Embedding.CostPerMTok == 0 && cfg.Providers.LLM.CostPerMTok == 0 {
+		return fmt.Errorf("a dollar --budget requires cost_per_mtok to be set for the embedding or llm provider")
+	}
+
+	c, err := initComponents(cfg, logger, withNoCache(noCache))
 	if err != nil {
 		return fmt.Errorf("initializing components: %w", err)
 	}
 	defer c.Store.Close()
 
 	if c.GHClient == nil {
-		return fmt.Errorf("GitHub client not configured (set github.auth: app in config)")
+		return notConfigured("GitHub client", "set github.auth: app in config")
 	}
 
 	// Graceful shutdown on SIGINT/SIGTERM
@@ -120,42 +240,136 @@ func runScan(cmd *cobra.Command, args []string) error {
 
 	// Create or get repo record
 	repoRecord, err := c.Store.GetRepoByOwnerRepo(owner, repo)
-	if err != nil {
+	if errors.Is(err, store.ErrRepoNotFound) {
 		repoRecord, err = c.Store.CreateRepo(owner, repo)
 		if err != nil {
 			return fmt.Errorf("creating repo record: %w", err)
 		}
+	} else if err != nil {
+		return fmt.Errorf("looking up repo: %w", err)
+	}
+	if err := c.checkEmbeddingDims(ctx, repoRecord.ID, repoArg); err != nil {
+		return err
 	}
 
-	// Fetch all open issues with pagination
-	logger.Info("fetching open issues", "owner", owner, "repo", repo)
+	// Build pipeline for single-issue processing
+	labels := findRepoLabels(cfg, repoArg)
+	n, err := createNotifier(cfg, scanNotify, c.Store)
+	if err != nil {
+		logger.Warn("failed to create notifier", "error", err)
+	}
+	p := createPipeline(c, n, labels, scanSkipLabeled())
 
-	var allIssues []github.Issue
-	opts := &gogithub.IssueListByRepoOptions{
-		State:     "open",
-		Sort:      "updated",
-		Direction: "desc",
+	// Process issues concurrently using a worker pool
+	workers := scanWorkers
+	if workers <= 0 {
+		workers = defaultScanWorkers
+	}
+
+	var triaged, duplicatesCount, classifiedCount, gateCount, ignoredCount, finished int64
+	var mu sync.Mutex
+	var triageResults []github.TriageResult
+	var failures scanFailures
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	// Set once the first matching issues arrive
+	var session *store.ScanSession
+	var processed map[int]bool
+	var bar progressReporter
+	var ghRate atomic.Pointer[rateSnapshot]
+
+	process := func(iss github.Issue) {
+		defer wg.Done()
+		defer func() { <-sem }()
+
+		result, err := p.ProcessSingleIssue(ctx, repoArg, iss)
+		atomic.AddInt64(&finished, 1)
+		bar.Done(iss.Number, err != nil && !errors.Is(err, pipeline.ErrIgnored))
+
+		if errors.Is(err, pipeline.ErrIgnored) {
+			logger.Debug("skipping issue", "issue", iss.Number, "reason", err)
+			atomic.AddInt64(&ignoredCount, 1)
+			if err := c.Store.MarkScanProcessed(session.ID, iss.Number); err != nil {
+				logger.Warn("failed to checkpoint scan progress", "issue", iss.Number, "error", err)
+			}
+			return
+		}
+		// An incomplete issue is left unchecked so --resume retries it
+		if err != nil {
+			logger.Warn("failed to process issue", "issue", iss.Number, "error", err)
+			failures.add(iss.Number, err)
+			return
+		}
+
+		if err := c.Store.MarkScanProcessed(session.ID, iss.Number); err != nil {
+			logger.Warn("failed to checkpoint scan progress", "issue", iss.Number, "error", err)
+		}
+
+		atomic.AddInt64(&triaged, 1)
+		if len(result.Duplicates) > 0 {
+			atomic.AddInt64(&duplicatesCount, 1)
+		}
+		if gate.trips(result) {
+			atomic.AddInt64(&gateCount, 1)
+		}
+		if len(result.SuggestedLabels) > 0 {
+			atomic.AddInt64(&classifiedCount, 1)
+		}
+
+		mu.Lock()
+		triageResults = append(triageResults, *result)
+		mu.Unlock()
+	}
+
+	// Fetch matching issues a page at a time, processing each page while
+	// later ones are fetched. Oldest first, so the issues an issue may
+	// duplicate are stored and embedded by the time it is checked.
+	logger.Info("fetching issues", "owner", owner, "repo", repo, "state", filter.State)
+
+	opts := gogithub.IssueListByRepoOptions{
+		Sort:      "created",
+		Direction: "asc",
 		ListOptions: gogithub.ListOptions{
 			PerPage: 100,
 		},
 	}
 
+	filter.applyTo(&opts)
+
 	// Apply --since filter at the API level
 	if sinceDuration > 0 {
 		opts.Since = time.Now().Add(-sinceDuration)
 	}
 
-	for {
-		issues, resp, err := c.GHClient.Issues.ListByRepo(ctx, owner, repo, opts)
-		if err != nil {
-			return fmt.Errorf("fetching issues: %w", err)
-		}
-
-		for _, ghIssue := range issues {
+	var allIssues []github.Issue
+	var pendingCount int
+	budgetStopped := false
+	seen := make(map[int]bool)
+	fetchErr := fetchIssuePages(ctx, c.GHClient.Issues, owner, repo, opts, scanFetchConcurrency, func(page issuePage) error {
+		ghRate.Store(&rateSnapshot{Limit: page.rate.Limit, Remaining: page.rate.Remaining, Reset: page.rate.Reset.Time})
+
+		var matched []github.Issue
+		var stored []*store.Issue
+		for _, ghIssue := range page.issues {
 			if ghIssue.PullRequestLinks != nil {
-				continue // skip PRs
+				// Keep PRs for matching issues against, then skip them
+				if cfg.Defaults.MatchPullRequests {
+					if err := c.Store.UpsertPullRequest(github.ConvertPullRequest(repoRecord.ID, ghIssue)); err != nil {
+						logger.Warn("failed to store pull request", "number", ghIssue.GetNumber(), "error", err)
+					}
+				}
+				continue
+			}
+			// Pages fetched at once overlap when issues change meanwhile
+			if seen[ghIssue.GetNumber()] {
+				continue
 			}
+			seen[ghIssue.GetNumber()] = true
 			issue := convertGHIssue(ghIssue)
+			if !filter.matches(issue) {
+				continue
+			}
 
 			// Client-side filter for --since (in case API doesn't filter precisely)
 			if sinceDuration > 0 {
@@ -165,166 +379,262 @@ func runScan(cmd *cobra.Command, args []string) error {
 				}
 			}
 
-			allIssues = append(allIssues, issue)
+			matched = append(matched, issue)
+			stored = append(stored, &store.Issue{
+				RepoID:    repoRecord.ID,
+				Number:    issue.Number,
+				Title:     issue.Title,
+				Body:      issue.Body,
+				State:     issue.State,
+				Author:    issue.Author,
+				Labels:    issue.Labels,
+				Assignees: issue.Assignees,
+				Milestone: issue.Milestone,
+				CreatedAt: issue.CreatedAt,
+				UpdatedAt: issue.UpdatedAt,
+			})
+		}
+
+		// Store each page in one transaction
+		if err := c.Store.UpsertIssues(stored); err != nil {
+			logger.Warn("failed to upsert issues", "count", len(stored), "error", err)
+		}
+		if len(matched) == 0 {
+			return nil
+		}
+		allIssues = append(allIssues, matched...)
+
+		// Checkpoint progress so an interrupted scan can be resumed
+		if session == nil {
+			var err error
+			session, processed, err = startScanSession(c.Store, repoRecord.ID, scanParams(filter), scanResume, logger)
+			if err != nil {
+				return err
+			}
+			bar = newProgressReporter(scanProgress, 0, "Processing", os.Stderr)
+			if pb, ok := bar.(*progressBar); ok {
+				pb.status = func() string {
+					return scanStatus(c.Meter, cfg.Providers, ghRate.Load(), time.Now())
+				}
+			}
+		}
+		pending := pendingIssues(matched, processed)
+		pendingCount += len(pending)
+		bar.AddTotal(len(pending))
+
+		// Embed the page's new and edited issues in batches, which is far
+		// fewer provider calls than one per issue. A budget is checked per
+		// issue, so budgeted scans embed as they go.
+		if c.Dedup != nil && !budget.enabled() {
+			if embedded, err := c.Dedup.EmbedStale(ctx, repoRecord.ID, unignoredIssues(cfg, repoArg, pending)); err != nil {
+				logger.Warn("batch embedding failed, embedding issues one at a time", "embedded", embedded, "error", err)
+			} else if embedded > 0 {
+				logger.Debug("embedded issues in batches", "count", embedded)
+			}
+		}
+
+		for _, issue := range pending {
+			sem <- struct{}{}
+			if budget.enabled() && budget.wouldExceed(c.Meter.Usage(), cfg.Providers, int(atomic.LoadInt64(&finished)), len(sem), issue) {
+				<-sem
+				budgetStopped = true
+				return errScanBudget
+			}
+			wg.Add(1)
+			go process(issue)
 		}
+		return nil
+	})
+	if errors.Is(fetchErr, errScanBudget) {
+		fetchErr = nil
+	}
 
-		if resp.NextPage == 0 {
-			break
+	// Closed issues tell which duplicates people confirmed
+	learned, err := learnDuplicates(ctx, c.GHClient, c.Store, repoRecord.ID, repoArg, dryRun)
+	if err != nil {
+		logger.Warn("failed to learn confirmed duplicates", "learned", learned, "error", err)
+	} else if learned > 0 && dryRun {
+		logger.Info("dry run: not recording confirmed duplicates", "count", learned)
+	} else if learned > 0 {
+		logger.Info("learned confirmed duplicates", "count", learned)
+	}
+
+	wg.Wait()
+	if bar != nil {
+		if budgetStopped || fetchErr != nil {
+			bar.Stop()
+		} else {
+			bar.Finish()
 		}
-		opts.ListOptions.Page = resp.NextPage
+	}
+	// Issues processed so far are checkpointed, so --resume continues
+	if fetchErr != nil && ctx.Err() == nil {
+		return fetchErr
 	}
 
 	total := len(allIssues)
 	if sinceDuration > 0 {
-		logger.Info("found open issues within window", "count", total, "since", scanSince)
+		logger.Info("found matching issues within window", "count", total, "since", scanSince)
 	} else {
-		logger.Info("found open issues", "count", total)
+		logger.Info("found matching issues", "count", total)
 	}
 
 	if total == 0 {
 		if scanOutput == "json" {
-			fmt.Println("[]")
-		} else {
-			fmt.Println("No open issues found.")
+			return printScanJSON(nil, nil)
 		}
+		fmt.Println("No matching issues found.")
 		return nil
 	}
-
-	// Upsert all issues into store
-	for _, issue := range allIssues {
-		err := c.Store.UpsertIssue(&store.Issue{
-			RepoID:    repoRecord.ID,
-			Number:    issue.Number,
-			Title:     issue.Title,
-			Body:      issue.Body,
-			State:     issue.State,
-			Author:    issue.Author,
-			Labels:    issue.Labels,
-			CreatedAt: issue.CreatedAt,
-			UpdatedAt: issue.UpdatedAt,
-		})
-		if err != nil {
-			logger.Warn("failed to upsert issue", "issue", issue.Number, "error", err)
-		}
+	if err := c.Store.SetScanTotal(session.ID, total); err != nil {
+		logger.Warn("failed to record scan total", "error", err)
 	}
-
-	// Build pipeline for single-issue processing
-	labels := findRepoLabels(cfg, repoArg)
-	n, err := createNotifier(cfg, scanNotify)
-	if err != nil {
-		logger.Warn("failed to create notifier", "error", err)
-	}
-	p := createPipeline(c, n, labels)
-
-	// Process issues concurrently using a worker pool
-	workers := scanWorkers
-	if workers <= 0 {
-		workers = defaultScanWorkers
+	skipped := total - pendingCount
+
+	if ctx.Err() != nil {
+		logger.Info("scan interrupted; rerun with --resume to continue", "repo", repoArg)
+	} else if budgetStopped {
+		logger.Info("scan stopped at budget; rerun with --resume to continue",
+			"repo", repoArg, "budget", budget.String(), "remaining", pendingCount-int(atomic.LoadInt64(&finished)))
+	} else if err := c.Store.CompleteScanSession(session.ID); err != nil {
+		logger.Warn("failed to complete scan session", "error", err)
 	}
 
-	var triaged, duplicatesCount, classifiedCount int64
-	var mu sync.Mutex
-	var results []checkResultJSON
-	sem := make(chan struct{}, workers)
-	var wg sync.WaitGroup
-
-	bar := newProgressBar(total, "Processing", os.Stderr)
-
-	for _, issue := range allIssues {
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(iss github.Issue) {
-			defer wg.Done()
-			defer func() { <-sem }()
-
-			result, err := p.ProcessSingleIssue(ctx, repoArg, iss)
-			bar.Add(1)
-
-			if err != nil {
-				logger.Warn("failed to process issue", "issue", iss.Number, "error", err)
-				return
-			}
-
-			atomic.AddInt64(&triaged, 1)
-			if len(result.Duplicates) > 0 {
-				atomic.AddInt64(&duplicatesCount, 1)
-			}
-			if len(result.SuggestedLabels) > 0 {
-				atomic.AddInt64(&classifiedCount, 1)
-			}
-
-			if scanOutput == "json" {
-				jr := checkResultJSON{
-					Issue: issueJSON{
-						Number: iss.Number,
-						Title:  iss.Title,
-					},
-					Duplicates: make([]duplicateJSON, 0, len(result.Duplicates)),
-					Labels:     make([]labelJSON, 0, len(result.SuggestedLabels)),
-					Reasoning:  result.Reasoning,
-				}
-				for _, d := range result.Duplicates {
-					jr.Duplicates = append(jr.Duplicates, duplicateJSON{
-						Number: d.Number,
-						Score:  float64(d.Score),
-					})
-				}
-				for _, l := range result.SuggestedLabels {
-					jr.Labels = append(jr.Labels, labelJSON{
-						Name:       l.Name,
-						Confidence: l.Confidence,
-					})
-				}
-				mu.Lock()
-				results = append(results, jr)
-				mu.Unlock()
-			}
-		}(issue)
-	}
-	wg.Wait()
-	bar.Finish()
-
 	// Output results
 	dupCount := atomic.LoadInt64(&duplicatesCount)
 	classCount := atomic.LoadInt64(&classifiedCount)
 	triagedCount := atomic.LoadInt64(&triaged)
+	failed := failures.sorted()
 
 	if scanOutput == "json" {
-		if results == nil {
-			results = make([]checkResultJSON, 0)
+		if err := printScanJSON(triageResults, failed); err != nil {
+			return err
 		}
-		data, err := json.MarshalIndent(results, "", "  ")
-		if err != nil {
-			return fmt.Errorf("marshaling JSON: %w", err)
-		}
-		fmt.Println(string(data))
 	} else {
 		// Print text summary
 		fmt.Printf("\nScan complete for %s/%s\n", owner, repo)
 		fmt.Printf("  Total issues scanned: %d\n", total)
+		if skipped > 0 {
+			fmt.Printf("  Skipped (resumed):    %d\n", skipped)
+		}
 		fmt.Printf("  Successfully triaged: %d\n", triagedCount)
+		if len(failed) > 0 {
+			fmt.Printf("  Failed:               %d\n", len(failed))
+		}
+		if ignored := atomic.LoadInt64(&ignoredCount); ignored > 0 {
+			fmt.Printf("  Ignored by rules:     %d\n", ignored)
+		}
 		fmt.Printf("  Potential duplicates: %d\n", dupCount)
 		fmt.Printf("  Issues classified:    %d\n", classCount)
+		if budgetStopped {
+			fmt.Printf("  Stopped at budget:    %s (%d not processed)\n", budget, pendingCount-int(atomic.LoadInt64(&finished)))
+		}
+		clusters, _ := clusterResults(triageResults)
+		writeClusters(os.Stdout, clusters)
+		writeFailures(os.Stdout, failed)
 	}
 
 	// Send summary notification
-	if n != nil {
-		summaryResult := github.TriageResult{
-			Repo:        repoArg,
-			IssueNumber: 0, // summary, not a single issue
-			Reasoning:   fmt.Sprintf("Scan complete: %d issues scanned, %d potential duplicates, %d classified", total, dupCount, classCount),
-		}
-		if err := n.Notify(ctx, summaryResult); err != nil {
+	if n != nil && dryRun {
+		logger.Info("dry run: skipping summary notification")
+	} else if n != nil {
+		summary := notify.NewScanSummary(repoArg, total, triageResults)
+		if err := notify.SendSummary(ctx, n, summary); err != nil {
 			logger.Warn("failed to send summary notification", "error", err)
 		}
 	}
 
+	return errors.Join(
+		failureRateErr(len(failed), int(atomic.LoadInt64(&finished)-atomic.LoadInt64(&ignoredCount)), scanMaxFailures),
+		gate.err(int(atomic.LoadInt64(&gateCount))),
+	)
+}
+
+// printScanJSON prints results grouped into duplicate clusters, followed by
+// any failures.
+func printScanJSON(results []github.TriageResult, failures []scanFailure) error {
+	out := newScanOutputJSON(results)
+	out.Failures = failures
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	fmt.Println(string(data))
 	return nil
 }
 
+// scanParams returns a canonical description of the scan options that
+// determine which issues are selected, used to match resumable sessions.
+func scanParams(filter *scanFilter) string {
+	return "since=" + scanSince + ";" + filter.params()
+}
+
+// scanSessionStore is the subset of the store used for scan checkpoints.
+type scanSessionStore interface {
+	CreateScanSession(repoID int64, params string, total int) (*store.ScanSession, error)
+	GetRunningScanSession(repoID int64, params string) (*store.ScanSession, error)
+	GetScanProcessed(sessionID int64) (map[int]bool, error)
+}
+
+// startScanSession returns the scan session to checkpoint into and the
+// issues it already processed. With resume set, the last interrupted session
+// with the same params is continued; otherwise (or if there is none) a new
+// session is started. Its total is recorded once every page is fetched.
+func startScanSession(st scanSessionStore, repoID int64, params string, resume bool, logger *slog.Logger) (*store.ScanSession, map[int]bool, error) {
+	if resume {
+		session, err := st.GetRunningScanSession(repoID, params)
+		switch {
+		case err == nil:
+			processed, err := st.GetScanProcessed(session.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			logger.Info("resuming interrupted scan", "session", session.ID, "already_processed", len(processed))
+			return session, processed, nil
+		case errors.Is(err, store.ErrNotFound):
+			logger.Info("no interrupted scan to resume, starting a new one")
+		default:
+			return nil, nil, err
+		}
+	}
+
+	session, err := st.CreateScanSession(repoID, params, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, map[int]bool{}, nil
+}
+
+// pendingIssues returns the issues not yet processed.
+func pendingIssues(issues []github.Issue, processed map[int]bool) []github.Issue {
+	pending := make([]github.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if !processed[issue.Number] {
+			pending = append(pending, issue)
+		}
+	}
+	return pending
+}
+
 // noopNotifier is a Notifier that does nothing.
 type noopNotifier struct{}
 
 func (n *noopNotifier) Notify(_ context.Context, _ github.TriageResult) error { return nil }
 
 var _ notify.Notifier = (*noopNotifier)(nil)
+
+// unignoredIssues returns the issues not matched by the repo's configured
+// ignore rules, which the pipeline skips before any provider call.
+func unignoredIssues(cfg *config.Config, repo string, issues []github.Issue) []github.Issue {
+	rc, ok := cfg.Repo(repo)
+	if !ok {
+		return issues
+	}
+	var kept []github.Issue
+	for _, issue := range issues {
+		if rc.Ignore.Match(issue.Title, issue.Author, issue.Labels) == "" {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
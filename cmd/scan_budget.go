@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/provider"
+)
+
+// Rough per-issue token overheads used to estimate spend before any issue
+// has been processed: the classification prompt template plus label list,
+// and the JSON response.
+const (
+	promptOverheadTokens = 400
+	completionTokens     = 80
+)
+
+// scanBudget caps the estimated provider spend of a scan. Zero fields are
+// unlimited.
+type scanBudget struct {
+	USD    float64
+	Tokens int64
+}
+
+// parseBudget parses a --budget value: a dollar amount ("$5", "2.50usd") or
+// a token count ("500000", "500k", "1.5m", "200k tokens").
+func parseBudget(s string) (scanBudget, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	if v == "" {
+		return scanBudget{}, nil
+	}
+	invalid := fmt.Errorf("invalid budget %q: expected dollars (e.g. $5) or tokens (e.g. 500k)", s)
+
+	if strings.HasPrefix(v, "$") || strings.HasSuffix(v, "usd") {
+		v = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(v, "$"), "usd"))
+		usd, err := strconv.ParseFloat(v, 64)
+		if err != nil || usd <= 0 {
+			return scanBudget{}, invalid
+		}
+		return scanBudget{USD: usd}, nil
+	}
+
+	v = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(v, "tokens"), "tok"))
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(v, "k"):
+		mult, v = 1e3, strings.TrimSuffix(v, "k")
+	case strings.HasSuffix(v, "m"):
+		mult, v = 1e6, strings.TrimSuffix(v, "m")
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 {
+		return scanBudget{}, invalid
+	}
+	return scanBudget{Tokens: int64(n * mult)}, nil
+}
+
+func (b scanBudget) enabled() bool { return b.USD > 0 || b.Tokens > 0 }
+
+func (b scanBudget) String() string {
+	if b.USD > 0 {
+		return fmt.Sprintf("$%.2f", b.USD)
+	}
+	return fmt.Sprintf("%d tokens", b.Tokens)
+}
+
+// spend is an estimated amount of provider usage.
+type spend struct {
+	Tokens float64
+	USD    float64
+}
+
+// spendOf prices usage with the configured per-provider costs.
+func spendOf(u provider.Usage, p config.ProvidersConfig) spend {
+	embed := float64(u.EmbedTokens)
+	llm := float64(u.PromptTokens + u.CompletionTokens)
+	return spend{
+		Tokens: embed + llm,
+		USD:    (embed*p.Embedding.CostPerMTok + llm*p.LLM.CostPerMTok) / 1e6,
+	}
+}
+
+// estimateIssueUsage guesses the usage of triaging one issue from its text.
+func estimateIssueUsage(issue github.Issue) provider.Usage {
+	text := provider.EstimateTokens(issue.Title) + provider.EstimateTokens(issue.Body)
+	return provider.Usage{
+		EmbedTokens:      text,
+		PromptTokens:     text + promptOverheadTokens,
+		CompletionTokens: completionTokens,
+	}
+}
+
+// wouldExceed reports whether starting another issue could take spend over
+// the budget. The cost per issue is the average so far, or an estimate from
+// next's text before any issue has finished; slots counts the issues that
+// would then be in flight, including next.
+func (b scanBudget) wouldExceed(used provider.Usage, providers config.ProvidersConfig, finished, slots int, next github.Issue) bool {
+	cur := spendOf(used, providers)
+	var per spend
+	if finished > 0 {
+		per = spend{Tokens: cur.Tokens / float64(finished), USD: cur.USD / float64(finished)}
+	} else {
+		per = spendOf(estimateIssueUsage(next), providers)
+	}
+	projected := spend{
+		Tokens: cur.Tokens + per.Tokens*float64(slots),
+		USD:    cur.USD + per.USD*float64(slots),
+	}
+	return (b.Tokens > 0 && projected.Tokens > float64(b.Tokens)) ||
+		(b.USD > 0 && projected.USD > b.USD)
+}
+
+// scanStatus returns the extra progress text for a scan: provider rate
+// limiting, GitHub quota, and estimated spend.
+func scanStatus(m *provider.Meter, providers config.ProvidersConfig, ghRate *rateSnapshot, now time.Time) string {
+	var parts []string
+	u := m.Usage()
+	if !u.LastRateLimit.IsZero() && now.Sub(u.LastRateLimit) < 30*time.Second {
+		parts = append(parts, "provider rate-limited, backing off")
+	}
+	if ghRate != nil && ghRate.Limit > 0 {
+		parts = append(parts, fmt.Sprintf("GitHub quota %d/%d", ghRate.Remaining, ghRate.Limit))
+	}
+	if s := spendOf(u, providers); s.USD > 0 {
+		parts = append(parts, fmt.Sprintf("~$%.2f spent", s.USD))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rateSnapshot is the GitHub rate limit state seen on the last API response.
+type rateSnapshot struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
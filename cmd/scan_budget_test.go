@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/provider"
+)
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    scanBudget
+		wantErr bool
+	}{
+		{"", scanBudget{}, false},
+		{"$5", scanBudget{USD: 5}, false},
+		{"2.50usd", scanBudget{USD: 2.5}, false},
+		{"50000", scanBudget{Tokens: 50000}, false},
+		{"500k", scanBudget{Tokens: 500000}, false},
+		{"1.5M", scanBudget{Tokens: 1500000}, false},
+		{"200k tokens", scanBudget{Tokens: 200000}, false},
+		{"$0", scanBudget{}, true},
+		{"-5", scanBudget{}, true},
+		{"lots", scanBudget{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseBudget(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBudget(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseBudget(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSpendOf(t *testing.T) {
+	providers := config.ProvidersConfig{
+		Embedding: config.ProviderConfig{CostPerMTok: 0.02},
+		LLM:       config.ProviderConfig{CostPerMTok: 3},
+	}
+	s := spendOf(provider.Usage{EmbedTokens: 1e6, PromptTokens: 500000, CompletionTokens: 500000}, providers)
+	if s.Tokens != 2e6 {
+		t.Errorf("expected 2M tokens, got %v", s.Tokens)
+	}
+	if s.USD < 3.019 || s.USD > 3.021 {
+		t.Errorf("expected $3.02, got %v", s.USD)
+	}
+}
+
+func TestBudgetWouldExceed(t *testing.T) {
+	providers := config.ProvidersConfig{}
+	issue := github.Issue{Title: "title", Body: strings.Repeat("x", 400)}
+
+	// Before any issue finishes, the estimate from the issue text is used:
+	// 102 embed + 502 prompt + 80 completion = 684 tokens.
+	if (scanBudget{Tokens: 700}).wouldExceed(provider.Usage{}, providers, 0, 1, issue) {
+		t.Error("expected one issue to fit in 700 tokens")
+	}
+	if !(scanBudget{Tokens: 700}).wouldExceed(provider.Usage{}, providers, 0, 2, issue) {
+		t.Error("expected two in-flight issues not to fit in 700 tokens")
+	}
+
+	// Afterwards, the average so far: 1000 tokens over 2 issues.
+	used := provider.Usage{EmbedTokens: 400, PromptTokens: 500, CompletionTokens: 100}
+	if (scanBudget{Tokens: 1500}).wouldExceed(used, providers, 2, 1, issue) {
+		t.Error("expected 1000+500 to fit in 1500 tokens")
+	}
+	if !(scanBudget{Tokens: 1499}).wouldExceed(used, providers, 2, 1, issue) {
+		t.Error("expected 1000+500 to exceed 1499 tokens")
+	}
+
+	priced := config.ProvidersConfig{LLM: config.ProviderConfig{CostPerMTok: 1000}}
+	// $0.60 spent over 2 issues, so one more projects to $0.90.
+	if (scanBudget{USD: 1}).wouldExceed(used, priced, 2, 1, issue) {
+		t.Error("expected $0.90 to fit in $1")
+	}
+	if !(scanBudget{USD: 0.85}).wouldExceed(used, priced, 2, 1, issue) {
+		t.Error("expected $0.90 to exceed $0.85")
+	}
+}
+
+func TestScanStatus(t *testing.T) {
+	now := time.Now()
+	var m provider.Meter
+	if got := scanStatus(&m, config.ProvidersConfig{}, &rateSnapshot{}, now); got != "" {
+		t.Errorf("expected empty status, got %q", got)
+	}
+
+	got := scanStatus(&m, config.ProvidersConfig{}, &rateSnapshot{Limit: 5000, Remaining: 4200}, now)
+	if got != "GitHub quota 4200/5000" {
+		t.Errorf("unexpected status %q", got)
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+// duplicateCluster is a group of issues linked as duplicates, directly or
+// through a chain (#3 matches #2, which matches #1). Closing all but one of
+// them is a single decision, so scan reports them together.
+type duplicateCluster struct {
+	// Issues holds every issue in the cluster in ascending order, including
+	// earlier issues that scanned ones matched but that were not scanned.
+	Issues []int
+	// Results holds the scanned members' results, by issue number.
+	Results []github.TriageResult
+	// titles holds the known title of each issue.
+	titles map[int]string
+}
+
+// clusterResults groups results linked by duplicate matches into clusters,
+// largest first, and returns the results that belong to none, by issue
+// number.
+func clusterResults(results []github.TriageResult) ([]duplicateCluster, []github.TriageResult) {
+	parent := make(map[int]int)
+	var find func(int) int
+	find = func(n int) int {
+		p, ok := parent[n]
+		if !ok || p == n {
+			parent[n] = n
+			return n
+		}
+		root := find(p)
+		parent[n] = root
+		return root
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		// Root at the lower number so each cluster is keyed by its oldest
+		// issue.
+		if ra < rb {
+			parent[rb] = ra
+		} else {
+			parent[ra] = rb
+		}
+	}
+
+	titles := make(map[int]string)
+	for _, r := range results {
+		for _, d := range r.Duplicates {
+			union(r.IssueNumber, d.Number)
+			if d.Title != "" && titles[d.Number] == "" {
+				titles[d.Number] = d.Title
+			}
+		}
+	}
+	for _, r := range results {
+		if r.IssueTitle != "" {
+			titles[r.IssueNumber] = r.IssueTitle
+		}
+	}
+
+	byRoot := make(map[int]*duplicateCluster)
+	for n := range parent {
+		root := find(n)
+		c, ok := byRoot[root]
+		if !ok {
+			c = &duplicateCluster{titles: titles}
+			byRoot[root] = c
+		}
+		c.Issues = append(c.Issues, n)
+	}
+
+	var rest []github.TriageResult
+	for _, r := range results {
+		if _, ok := parent[r.IssueNumber]; !ok {
+			rest = append(rest, r)
+			continue
+		}
+		c := byRoot[find(r.IssueNumber)]
+		c.Results = append(c.Results, r)
+	}
+
+	clusters := make([]duplicateCluster, 0, len(byRoot))
+	for _, c := range byRoot {
+		sort.Ints(c.Issues)
+		sortResults(c.Results)
+		clusters = append(clusters, *c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if len(a.Issues) != len(b.Issues) {
+			return len(a.Issues) > len(b.Issues)
+		}
+		return a.Issues[0] < b.Issues[0]
+	})
+	sortResults(rest)
+	return clusters, rest
+}
+
+func sortResults(results []github.TriageResult) {
+	sort.Slice(results, func(i, j int) bool { return results[i].IssueNumber < results[j].IssueNumber })
+}
+
+// writeClusters prints clusters as text: the oldest issue first, then each
+// other member with the match that links it to the cluster.
+func writeClusters(w io.Writer, clusters []duplicateCluster) {
+	if len(clusters) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nDuplicate clusters: %d\n", len(clusters))
+	for _, c := range clusters {
+		// A scanned member's strongest match is what links it in.
+		links := make(map[int]github.DuplicateCandidate)
+		for _, r := range c.Results {
+			for _, d := range r.Duplicates {
+				if best, ok := links[r.IssueNumber]; !ok || d.Score > best.Score {
+					links[r.IssueNumber] = d
+				}
+			}
+		}
+
+		fmt.Fprintf(w, "  %s\n", c.describe(c.Issues[0]))
+		for _, n := range c.Issues[1:] {
+			if d, ok := links[n]; ok {
+				pct := int(math.Round(float64(d.Score) * 100))
+				fmt.Fprintf(w, "    %s — %d%% similar to #%d\n", c.describe(n), pct, d.Number)
+			} else {
+				fmt.Fprintf(w, "    %s\n", c.describe(n))
+			}
+		}
+	}
+}
+
+// describe returns "#<number> <title>", leaving out an unknown title.
+func (c duplicateCluster) describe(n int) string {
+	if title := c.titles[n]; title != "" {
+		return fmt.Sprintf("#%d %s", n, title)
+	}
+	return fmt.Sprintf("#%d", n)
+}
+
+// scanOutputJSON is the JSON output of scan: duplicate clusters, then the
+// results for issues in no cluster, then any issues that failed.
+type scanOutputJSON struct {
+	Clusters []scanClusterJSON `json:"clusters"`
+	Issues   []checkResultJSON `json:"issues"`
+	Failures []scanFailure     `json:"failures,omitempty"`
+}
+
+type scanClusterJSON struct {
+	Issues  []int             `json:"issues"`
+	Results []checkResultJSON `json:"results"`
+}
+
+// newScanOutputJSON groups results into the scan JSON output.
+func newScanOutputJSON(results []github.TriageResult) scanOutputJSON {
+	clusters, rest := clusterResults(results)
+	out := scanOutputJSON{
+		Clusters: make([]scanClusterJSON, 0, len(clusters)),
+		Issues:   resultsJSON(rest),
+	}
+	for _, c := range clusters {
+		out.Clusters = append(out.Clusters, scanClusterJSON{Issues: c.Issues, Results: resultsJSON(c.Results)})
+	}
+	return out
+}
+
+func resultsJSON(results []github.TriageResult) []checkResultJSON {
+	out := make([]checkResultJSON, 0, len(results))
+	for i := range results {
+		r := &results[i]
+		out = append(out, newCheckResultJSON(github.Issue{Number: r.IssueNumber, Title: r.IssueTitle}, r))
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+func clusterTestResults() []github.TriageResult {
+	return []github.TriageResult{
+		{IssueNumber: 5, IssueTitle: "Unrelated"},
+		{IssueNumber: 3, IssueTitle: "Crash after update", Duplicates: []github.DuplicateCandidate{
+			{Number: 2, Title: "App crashes at launch", Score: 0.88},
+		}},
+		{IssueNumber: 2, IssueTitle: "App crashes at launch", Duplicates: []github.DuplicateCandidate{
+			{Number: 1, Title: "Crash on start", Score: 0.91},
+		}},
+		{IssueNumber: 9, IssueTitle: "Dark mode", Duplicates: []github.DuplicateCandidate{
+			{Number: 7, Score: 0.86},
+		}},
+	}
+}
+
+func TestClusterResults(t *testing.T) {
+	clusters, rest := clusterResults(clusterTestResults())
+
+	if len(clusters) != 2 {
+		t.Fatalf("got %d clusters, want 2", len(clusters))
+	}
+	// Chained matches form one cluster, which sorts first as the largest.
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(clusters[0].Issues, want) {
+		t.Errorf("cluster 0 issues = %v, want %v", clusters[0].Issues, want)
+	}
+	if got := []int{clusters[0].Results[0].IssueNumber, clusters[0].Results[1].IssueNumber}; !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Errorf("cluster 0 results = %v, want scanned issues [2 3]", got)
+	}
+	if want := []int{7, 9}; !reflect.DeepEqual(clusters[1].Issues, want) {
+		t.Errorf("cluster 1 issues = %v, want %v", clusters[1].Issues, want)
+	}
+	if len(rest) != 1 || rest[0].IssueNumber != 5 {
+		t.Errorf("unclustered = %+v, want only #5", rest)
+	}
+}
+
+func TestWriteClusters(t *testing.T) {
+	clusters, _ := clusterResults(clusterTestResults())
+	var buf bytes.Buffer
+	writeClusters(&buf, clusters)
+
+	want := `
+Duplicate clusters: 2
+  #1 Crash on start
+    #2 App crashes at launch — 91% similar to #1
+    #3 Crash after update — 88% similar to #2
+  #7
+    #9 Dark mode — 86% similar to #7
+`
+	if buf.String() != want {
+		t.Errorf("writeClusters =\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeClusters(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("writeClusters(nil) = %q, want nothing", buf.String())
+	}
+}
+
+func TestScanOutputJSON(t *testing.T) {
+	data, err := json.Marshal(newScanOutputJSON(clusterTestResults()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed scanOutputJSON
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Clusters) != 2 || len(parsed.Issues) != 1 {
+		t.Fatalf("got %d clusters and %d issues, want 2 and 1", len(parsed.Clusters), len(parsed.Issues))
+	}
+	c := parsed.Clusters[0]
+	if !reflect.DeepEqual(c.Issues, []int{1, 2, 3}) || len(c.Results) != 2 {
+		t.Errorf("cluster 0 = %+v", c)
+	}
+	if c.Results[0].Issue.Number != 2 || c.Results[0].Issue.Title != "App crashes at launch" {
+		t.Errorf("cluster 0 first result issue = %+v", c.Results[0].Issue)
+	}
+	if parsed.Issues[0].Issue.Number != 5 {
+		t.Errorf("unclustered issue = %+v, want #5", parsed.Issues[0].Issue)
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gogithub "github.com/google/go-github/v60/github"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// closedAsDuplicate reports whether a closed issue may have been closed as
+// a duplicate, so its comments are worth reading: it was closed as a
+// duplicate or as not planned, or it has a duplicate label.
+func closedAsDuplicate(issue *gogithub.Issue) bool {
+	if issue.GetState() != "closed" {
+		return false
+	}
+	switch issue.GetStateReason() {
+	case "duplicate", "not_planned":
+		return true
+	}
+	for _, l := range issue.Labels {
+		if strings.EqualFold(l.GetName(), "duplicate") {
+			return true
+		}
+	}
+	return false
+}
+
+// learnDuplicates lists a repo's closed issues, apart from the issues being
+// scanned and whatever the scan's filters, and reads the body and comments
+// of those that may have been closed as duplicates for "Duplicate of #N".
+// The pairs found are recorded as confirmed duplicates, the ground truth
+// "triage eval --duplicates" measures detection against. Each issue read is
+// recorded as of its last update, pair or not, and only issues updated since
+// are listed and read again. It returns how many pairs were new; with dryRun
+// they are counted but nothing is recorded.
+func learnDuplicates(ctx context.Context, gh *gogithub.Client, db *store.DB, repoID int64, repoFull string, dryRun bool) (int, error) {
+	owner, repo, err := parseRepoArg(repoFull)
+	if err != nil {
+		return 0, err
+	}
+	read, err := db.ListDuplicatesRead(repoID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Oldest update first, so a run that stops early resumes from the last
+	// issue it read
+	opts := &gogithub.IssueListByRepoOptions{
+		State:       "closed",
+		Sort:        "updated",
+		Direction:   "asc",
+		ListOptions: gogithub.ListOptions{PerPage: 100},
+	}
+	for _, updated := range read {
+		if updated.After(opts.Since) {
+			opts.Since = updated
+		}
+	}
+
+	learned := 0
+	for {
+		issues, resp, err := gh.Issues.ListByRepo(ctx, owner, repo, opts)
+		if err != nil {
+			return learned, fmt.Errorf("listing closed issues: %w", err)
+		}
+		for _, issue := range issues {
+			if issue.PullRequestLinks != nil || !closedAsDuplicate(issue) {
+				continue
+			}
+			updated := issue.GetUpdatedAt().Time
+			if at, ok := read[issue.GetNumber()]; ok && !updated.After(at) {
+				continue
+			}
+			n, err := learnIssueDuplicates(ctx, gh, db, repoID, repoFull, issue, dryRun)
+			learned += n
+			if err != nil {
+				return learned, err
+			}
+			if dryRun {
+				continue
+			}
+			if err := db.MarkDuplicatesRead(repoID, issue.GetNumber(), updated); err != nil {
+				return learned, err
+			}
+		}
+		if resp.NextPage == 0 {
+			return learned, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// learnIssueDuplicates reads an issue's body and comments for "Duplicate of
+// #N" and records the pairs found, returning how many were new.
+func learnIssueDuplicates(ctx context.Context, gh *gogithub.Client, db *store.DB, repoID int64, repoFull string, issue *gogithub.Issue, dryRun bool) (int, error) {
+	owner, repo, err := parseRepoArg(repoFull)
+	if err != nil {
+		return 0, err
+	}
+	refs := github.DuplicateRefs(issue.GetBody(), repoFull)
+	opts := &gogithub.IssueListCommentsOptions{ListOptions: gogithub.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := gh.Issues.ListComments(ctx, owner, repo, issue.GetNumber(), opts)
+		if err != nil {
+			return 0, fmt.Errorf("fetching comments of #%d: %w", issue.GetNumber(), err)
+		}
+		for _, c := range comments {
+			refs = append(refs, github.DuplicateRefs(c.GetBody(), repoFull)...)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	learned := 0
+	seen := make(map[int]bool, len(refs))
+	for _, n := range refs {
+		if n == issue.GetNumber() || seen[n] {
+			continue
+		}
+		seen[n] = true
+		if dryRun {
+			learned++
+			continue
+		}
+		added, err := db.AddDuplicatePair(store.DuplicatePair{
+			RepoID:      repoID,
+			IssueNumber: issue.GetNumber(),
+			DuplicateOf: n,
+			Source:      store.DuplicateSourceComment,
+		})
+		if err != nil {
+			return learned, err
+		}
+		if added {
+			learned++
+		}
+	}
+	return learned, nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gogithub "github.com/google/go-github/v60/github"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestClosedAsDuplicate(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue *gogithub.Issue
+		want  bool
+	}{
+		{"open", &gogithub.Issue{State: gogithub.String("open"), StateReason: gogithub.String("not_planned")}, false},
+		{"completed", &gogithub.Issue{State: gogithub.String("closed"), StateReason: gogithub.String("completed")}, false},
+		{"not planned", &gogithub.Issue{State: gogithub.String("closed"), StateReason: gogithub.String("not_planned")}, true},
+		{"duplicate", &gogithub.Issue{State: gogithub.String("closed"), StateReason: gogithub.String("duplicate")}, true},
+		{"labeled", &gogithub.Issue{State: gogithub.String("closed"), Labels: []*gogithub.Label{{Name: gogithub.String("Duplicate")}}}, true},
+	}
+	for _, tt := range tests {
+		if got := closedAsDuplicate(tt.issue); got != tt.want {
+			t.Errorf("%s: closedAsDuplicate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLearnDuplicates(t *testing.T) {
+	var srvURL string
+	listed := `[
+		{"number":5,"state":"closed","state_reason":"not_planned","updated_at":"2024-03-01T12:00:00Z","body":"Duplicate of https://github.com/org/app/issues/1"},
+		{"number":8,"state":"closed","state_reason":"duplicate","updated_at":"2024-03-01T12:00:00Z"},
+		{"number":9,"state":"closed","state_reason":"completed","updated_at":"2024-03-01T12:00:00Z","body":"Duplicate of #3"},
+		{"number":10,"state":"closed","state_reason":"duplicate","updated_at":"2024-03-01T12:00:00Z","pull_request":{}},
+		{"number":11,"state":"closed","state_reason":"duplicate","updated_at":"2024-03-02T12:00:00Z"}
+	]`
+	var since string
+	commentFetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/repos/org/app/issues":
+			if q := r.URL.Query(); q.Get("state") != "closed" || q.Get("sort") != "updated" {
+				t.Errorf("expected closed issues by update, got %s", r.URL.RawQuery)
+			}
+			since = r.URL.Query().Get("since")
+			w.Write([]byte(listed))
+			return
+		case r.URL.Path == "/repos/org/app/issues/5/comments" && r.URL.Query().Get("page") == "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/org/app/issues/5/comments?page=2>; rel="next"`, srvURL))
+			w.Write([]byte(`[{"body":"Thanks for the report!"}]`))
+		case r.URL.Path == "/repos/org/app/issues/5/comments":
+			w.Write([]byte(`[{"body":"Duplicate of #2"}]`))
+		case r.URL.Path == "/repos/org/app/issues/8/comments":
+			w.Write([]byte(`[{"body":"Duplicate of #8, and of org/other#1"}]`))
+		case r.URL.Path == "/repos/org/app/issues/11/comments":
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+		commentFetches++
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	gh := gogithub.NewClient(nil)
+	gh.BaseURL, _ = url.Parse(srv.URL + "/")
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo, err := db.CreateRepo("org", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run counts the pairs without recording them or what was read
+	learned, err := learnDuplicates(context.Background(), gh, db, repo.ID, "org/app", true)
+	if err != nil || learned != 2 {
+		t.Fatalf("dry run learned = %d, %v; want 2", learned, err)
+	}
+	if pairs, _ := db.ListDuplicatePairs(repo.ID); len(pairs) != 0 {
+		t.Fatalf("expected a dry run to record nothing, got %+v", pairs)
+	}
+	if read, _ := db.ListDuplicatesRead(repo.ID); len(read) != 0 {
+		t.Fatalf("expected a dry run to record nothing, got %v", read)
+	}
+
+	learned, err = learnDuplicates(context.Background(), gh, db, repo.ID, "org/app", false)
+	if err != nil {
+		t.Fatalf("learnDuplicates: %v", err)
+	}
+	if learned != 2 {
+		t.Errorf("learned = %d, want 2", learned)
+	}
+	pairs, err := db.ListDuplicatePairs(repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 2 || pairs[0].IssueNumber != 5 || pairs[0].DuplicateOf != 1 || pairs[1].DuplicateOf != 2 {
+		t.Errorf("unexpected pairs %+v", pairs)
+	}
+	// Issues without pairs are remembered as read too
+	read, err := db.ListDuplicatesRead(repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 3 || read[8].IsZero() || read[11].IsZero() {
+		t.Errorf("expected #5, #8 and #11 to be recorded as read, got %v", read)
+	}
+
+	// Issues read are not fetched again, and the listing starts from the
+	// latest update read
+	fetches := commentFetches
+	if learned, err := learnDuplicates(context.Background(), gh, db, repo.ID, "org/app", false); err != nil || learned != 0 {
+		t.Errorf("relearning = %d, %v; want 0", learned, err)
+	}
+	if commentFetches != fetches {
+		t.Errorf("expected no comments to be fetched again, got %d fetches", commentFetches-fetches)
+	}
+	if since != "2024-03-02T12:00:00Z" {
+		t.Errorf("expected the listing since the latest update read, got since=%q", since)
+	}
+
+	// An issue updated since it was read is read again
+	listed = `[{"number":11,"state":"closed","state_reason":"duplicate","updated_at":"2024-03-03T12:00:00Z"}]`
+	fetches = commentFetches
+	if _, err := learnDuplicates(context.Background(), gh, db, repo.ID, "org/app", false); err != nil {
+		t.Fatalf("learnDuplicates: %v", err)
+	}
+	if commentFetches != fetches+1 {
+		t.Errorf("expected #11 to be fetched again, got %d fetches", commentFetches-fetches)
+	}
+
+	// A failed fetch is reported
+	listed = `[{"number":99,"state":"closed","state_reason":"duplicate","updated_at":"2024-03-04T12:00:00Z"}]`
+	if _, err := learnDuplicates(context.Background(), gh, db, repo.ID, "org/app", false); err == nil {
+		t.Error("expected an error for a failed comment fetch")
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// scanFailure is an issue that scan could not fully triage.
+type scanFailure struct {
+	Number int    `json:"number"`
+	Reason string `json:"reason"`
+}
+
+// scanFailures collects the failures of a scan's workers.
+type scanFailures struct {
+	mu   sync.Mutex
+	list []scanFailure
+}
+
+// add records that issue number failed with err.
+func (f *scanFailures) add(number int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.list = append(f.list, scanFailure{Number: number, Reason: strings.Join(strings.Fields(err.Error()), " ")})
+}
+
+// sorted returns the failures in issue number order.
+func (f *scanFailures) sorted() []scanFailure {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := append([]scanFailure(nil), f.list...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out
+}
+
+// writeFailures prints a table of failed issues and why they failed.
+func writeFailures(w io.Writer, failures []scanFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nFailed issues (%d):\n", len(failures))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ISSUE\tREASON")
+	for _, f := range failures {
+		fmt.Fprintf(tw, "  #%d\t%s\n", f.Number, f.Reason)
+	}
+	tw.Flush()
+}
+
+// failureRateErr returns an error exiting with ExitPartial if more than
+// maxPercent of the processed issues failed, or nil.
+func failureRateErr(failed, processed int, maxPercent float64) error {
+	if failed == 0 || processed == 0 {
+		return nil
+	}
+	rate := float64(failed) / float64(processed) * 100
+	if rate <= maxPercent {
+		return nil
+	}
+	return &exitError{code: ExitPartial, err: fmt.Errorf("%d of %d issue(s) failed (%.0f%%, limit %g%%)", failed, processed, rate, maxPercent)}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestScanFailures(t *testing.T) {
+	var f scanFailures
+	f.add(12, errors.New("triage incomplete: dedup: embedder down\nclassification: llm down"))
+	f.add(3, errors.New("creating repo record: locked"))
+
+	failed := f.sorted()
+	if len(failed) != 2 || failed[0].Number != 3 || failed[1].Number != 12 {
+		t.Fatalf("expected failures in issue order, got %+v", failed)
+	}
+	if failed[1].Reason != "triage incomplete: dedup: embedder down classification: llm down" {
+		t.Errorf("expected the reason on one line, got %q", failed[1].Reason)
+	}
+
+	var out bytes.Buffer
+	writeFailures(&out, failed)
+	for _, want := range []string{"Failed issues (2):", "ISSUE  REASON", "#3     creating repo record: locked"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	writeFailures(&out, nil)
+	if out.Len() != 0 {
+		t.Errorf("expected no output without failures, got %q", out.String())
+	}
+}
+
+func TestFailureRateErr(t *testing.T) {
+	tests := []struct {
+		name              string
+		failed, processed int
+		max               float64
+		wantErr           bool
+	}{
+		{"no failures", 0, 10, 0, false},
+		{"nothing processed", 0, 0, 0, false},
+		{"any failure by default", 1, 100, 0, true},
+		{"at the limit", 1, 10, 10, false},
+		{"above the limit", 2, 10, 10, true},
+		{"never", 10, 10, 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := failureRateErr(tt.failed, tt.processed, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("failureRateErr() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && ExitCode(fmt.Errorf("scan: %w", err)) != ExitPartial {
+				t.Errorf("expected exit status %d, got %d", ExitPartial, ExitCode(err))
+			}
+		})
+	}
+
+	err := errors.Join(failureRateErr(2, 10, 10), duplicateGate{Any: true}.err(1))
+	if ExitCode(err) != ExitPartial || !strings.Contains(err.Error(), "2 of 10 issue(s) failed (20%, limit 10%)") {
+		t.Errorf("expected the failure exit status to win over the gate, got %d: %v", ExitCode(err), err)
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+// defaultFetchConcurrency is how many pages of issues scan fetches at once.
+// GitHub discourages many concurrent requests from one client, so it is
+// kept small.
+const defaultFetchConcurrency = 4
+
+// fetchRateReserve is the GitHub quota left to other requests when deciding
+// whether pages may be fetched concurrently.
+const fetchRateReserve = 100
+
+// issueLister lists a repo's issues; *gogithub.IssuesService implements it.
+type issueLister interface {
+	ListByRepo(ctx context.Context, owner, repo string, opts *gogithub.IssueListByRepoOptions) ([]*gogithub.Issue, *gogithub.Response, error)
+}
+
+// issuePage is a page of a repo's issues, with the rate limit seen fetching
+// it.
+type issuePage struct {
+	issues []*gogithub.Issue
+	rate   gogithub.Rate
+}
+
+// fetchIssuePages lists a repo's issues matching opts and calls yield with
+// each page, in page order, as soon as it and the pages before it arrive, so
+// the caller can process a page while later ones are fetched. The first page
+// is fetched alone; its Link header gives the number of pages, and the rest
+// are fetched up to concurrency at a time. They are fetched one at a time
+// when the remaining quota could not cover them all. An error from yield
+// stops fetching and is returned.
+func fetchIssuePages(ctx context.Context, lister issueLister, owner, repo string, opts gogithub.IssueListByRepoOptions, concurrency int, yield func(issuePage) error) error {
+	opts.Page = 0
+	issues, resp, err := lister.ListByRepo(ctx, owner, repo, &opts)
+	if err != nil {
+		return fmt.Errorf("fetching issues: %w", err)
+	}
+	if err := yield(issuePage{issues: issues, rate: resp.Rate}); err != nil {
+		return err
+	}
+	if resp.NextPage == 0 {
+		return nil
+	}
+
+	last := resp.LastPage
+	pagesLeft := last - resp.NextPage + 1
+	if last == 0 || concurrency <= 1 || (resp.Rate.Limit > 0 && resp.Rate.Remaining < pagesLeft+fetchRateReserve) {
+		// Follow the links one page at a time
+		for next := resp.NextPage; next != 0; next = resp.NextPage {
+			opts.Page = next
+			issues, resp, err = lister.ListByRepo(ctx, owner, repo, &opts)
+			if err != nil {
+				return fmt.Errorf("fetching issues page %d: %w", next, err)
+			}
+			if err := yield(issuePage{issues: issues, rate: resp.Rate}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	type result struct {
+		page issuePage
+		err  error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	first := resp.NextPage
+	results := make([]chan result, last+1)
+	for p := first; p <= last; p++ {
+		results[p] = make(chan result, 1)
+	}
+	pages := make(chan int)
+	go func() {
+		defer close(pages)
+		for p := first; p <= last; p++ {
+			select {
+			case pages <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for range min(concurrency, pagesLeft) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range pages {
+				pageOpts := opts
+				pageOpts.Page = p
+				issues, resp, err := lister.ListByRepo(ctx, owner, repo, &pageOpts)
+				if err != nil {
+					results[p] <- result{err: fmt.Errorf("fetching issues page %d: %w", p, err)}
+					continue
+				}
+				results[p] <- result{page: issuePage{issues: issues, rate: resp.Rate}}
+			}
+		}()
+	}
+
+	for p := first; p <= last; p++ {
+		var r result
+		select {
+		case r = <-results[p]:
+		case <-ctx.Done():
+			return fmt.Errorf("fetching issues: %w", ctx.Err())
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if err := yield(r.page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+// newPagedIssuesServer serves pages issues pages of org/app, each holding
+// one issue numbered after its page. With withLast unset, Link headers give
+// only the next page.
+func newPagedIssuesServer(t *testing.T, pages int, withLast bool, remaining int) (*gogithub.Client, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page == 3 && r.URL.Query().Get("milestone") == "fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		link := func(p int, rel string) string {
+			q := r.URL.Query()
+			q.Set("page", strconv.Itoa(p))
+			return fmt.Sprintf(`<%s%s?%s>; rel=%q`, srvURL, r.URL.Path, q.Encode(), rel)
+		}
+		if page < pages {
+			header := link(page+1, "next")
+			if withLast {
+				header += ", " + link(pages, "last")
+			}
+			w.Header().Set("Link", header)
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"number":%d,"title":"Issue %d"}]`, page, page)
+	}))
+	t.Cleanup(srv.Close)
+	srvURL = srv.URL
+
+	gh := gogithub.NewClient(nil)
+	gh.BaseURL, _ = url.Parse(srv.URL + "/")
+	return gh, &requests
+}
+
+// collectPages fetches every page and returns the issue numbers in the order
+// they were yielded.
+func collectPages(t *testing.T, gh *gogithub.Client, concurrency int) ([]int, error) {
+	t.Helper()
+	var got []int
+	err := fetchIssuePages(context.Background(), gh.Issues, "org", "app", gogithub.IssueListByRepoOptions{}, concurrency, func(page issuePage) error {
+		for _, issue := range page.issues {
+			got = append(got, issue.GetNumber())
+		}
+		if page.rate.Limit != 5000 {
+			t.Errorf("expected the page's rate limit, got %+v", page.rate)
+		}
+		return nil
+	})
+	return got, err
+}
+
+func TestFetchIssuePages(t *testing.T) {
+	tests := []struct {
+		name        string
+		withLast    bool
+		remaining   int
+		concurrency int
+	}{
+		{"concurrent", true, 5000, 4},
+		{"more workers than pages", true, 5000, 20},
+		{"sequential", true, 5000, 1},
+		{"no last page", false, 5000, 4},
+		{"low quota", true, 50, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh, requests := newPagedIssuesServer(t, 7, tt.withLast, tt.remaining)
+			got, err := collectPages(t, gh, tt.concurrency)
+			if err != nil {
+				t.Fatalf("fetchIssuePages: %v", err)
+			}
+			if fmt.Sprint(got) != "[1 2 3 4 5 6 7]" {
+				t.Errorf("expected pages in order, got %v", got)
+			}
+			if n := requests.Load(); n != 7 {
+				t.Errorf("expected each page fetched once, got %d requests", n)
+			}
+		})
+	}
+}
+
+func TestFetchIssuePagesSinglePage(t *testing.T) {
+	gh, requests := newPagedIssuesServer(t, 1, true, 5000)
+	got, err := collectPages(t, gh, 4)
+	if err != nil || fmt.Sprint(got) != "[1]" || requests.Load() != 1 {
+		t.Errorf("expected one page from one request, got %v (%d requests), %v", got, requests.Load(), err)
+	}
+}
+
+func TestFetchIssuePagesErrors(t *testing.T) {
+	gh, _ := newPagedIssuesServer(t, 7, true, 5000)
+	stop := errors.New("stop")
+	var yielded int
+	err := fetchIssuePages(context.Background(), gh.Issues, "org", "app", gogithub.IssueListByRepoOptions{}, 4, func(issuePage) error {
+		yielded++
+		if yielded == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || yielded != 2 {
+		t.Errorf("expected yield's error after 2 pages, got %v after %d", err, yielded)
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		var got []int
+		err := fetchIssuePages(context.Background(), gh.Issues, "org", "app", gogithub.IssueListByRepoOptions{Milestone: "fail"}, concurrency, func(page issuePage) error {
+			got = append(got, page.issues[0].GetNumber())
+			return nil
+		})
+		if err == nil || fmt.Sprint(got) != "[1 2]" {
+			t.Errorf("concurrency %d: expected a page 3 error after pages 1 and 2, got %v after %v", concurrency, err, got)
+		}
+	}
+}
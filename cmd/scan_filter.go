@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jacklau/triage/internal/github"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+// issueRange is an inclusive range of issue numbers.
+type issueRange struct {
+	From, To int
+}
+
+// parseIssueRanges parses a comma-separated list of issue numbers and
+// inclusive ranges, e.g. "100-200,250".
+func parseIssueRanges(s string) ([]issueRange, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var ranges []issueRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil || from <= 0 {
+			return nil, fmt.Errorf("invalid issue range %q: expected N or N-M", part)
+		}
+		to := from
+		if isRange {
+			to, err = strconv.Atoi(strings.TrimSpace(hi))
+			if err != nil || to < from {
+				return nil, fmt.Errorf("invalid issue range %q: expected N or N-M", part)
+			}
+		}
+		ranges = append(ranges, issueRange{From: from, To: to})
+	}
+	return ranges, nil
+}
+
+// scanFilter selects which issues a scan processes. Label, author, and state
+// filters are pushed down to the GitHub API; the rest are applied locally.
+type scanFilter struct {
+	State    string
+	Labels   []string
+	NoLabels []string
+	Author   string
+	Ranges   []issueRange
+}
+
+// newScanFilter validates and builds a scanFilter from flag values.
+func newScanFilter(state string, labels, noLabels []string, author, issues string) (*scanFilter, error) {
+	switch state {
+	case "":
+		state = "open"
+	case "open", "closed", "all":
+	default:
+		return nil, fmt.Errorf("invalid state %q: expected open, closed, or all", state)
+	}
+	ranges, err := parseIssueRanges(issues)
+	if err != nil {
+		return nil, err
+	}
+	return &scanFilter{
+		State:    state,
+		Labels:   labels,
+		NoLabels: noLabels,
+		Author:   author,
+		Ranges:   ranges,
+	}, nil
+}
+
+// applyTo sets the API-level filters on opts.
+func (f *scanFilter) applyTo(opts *gogithub.IssueListByRepoOptions) {
+	opts.State = f.State
+	opts.Labels = f.Labels
+	opts.Creator = f.Author
+}
+
+// matches reports whether an issue passes the local filters.
+func (f *scanFilter) matches(issue github.Issue) bool {
+	if len(f.Ranges) > 0 {
+		inRange := false
+		for _, r := range f.Ranges {
+			if issue.Number >= r.From && issue.Number <= r.To {
+				inRange = true
+				break
+			}
+		}
+		if !inRange {
+			return false
+		}
+	}
+	for _, excluded := range f.NoLabels {
+		for _, l := range issue.Labels {
+			if strings.EqualFold(l, excluded) {
+				return false
+			}
+		}
+	}
+	if f.Author != "" && !strings.EqualFold(issue.Author, f.Author) {
+		return false
+	}
+	return true
+}
+
+// params returns a canonical description of the filter, used to match
+// resumable scan sessions.
+func (f *scanFilter) params() string {
+	sorted := func(in []string) string {
+		out := append([]string(nil), in...)
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+	ranges := make([]string, len(f.Ranges))
+	for i, r := range f.Ranges {
+		ranges[i] = fmt.Sprintf("%d-%d", r.From, r.To)
+	}
+	return fmt.Sprintf("state=%s;label=%s;no-label=%s;author=%s;issues=%s",
+		f.State, sorted(f.Labels), sorted(f.NoLabels), f.Author, strings.Join(ranges, ","))
+}
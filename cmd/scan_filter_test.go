@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+func TestParseIssueRanges(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []issueRange
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"42", []issueRange{{42, 42}}, false},
+		{"100-200", []issueRange{{100, 200}}, false},
+		{"1-3, 7", []issueRange{{1, 3}, {7, 7}}, false},
+		{"200-100", nil, true},
+		{"abc", nil, true},
+		{"0", nil, true},
+		{"5-", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseIssueRanges(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseIssueRanges(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parseIssueRanges(%q) = %v, want %v", tt.in, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseIssueRanges(%q)[%d] = %v, want %v", tt.in, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestNewScanFilterState(t *testing.T) {
+	f, err := newScanFilter("", nil, nil, "", "")
+	if err != nil || f.State != "open" {
+		t.Errorf("expected default state open, got %+v, %v", f, err)
+	}
+	if _, err := newScanFilter("merged", nil, nil, "", ""); err == nil {
+		t.Error("expected error for invalid state")
+	}
+}
+
+func TestScanFilterMatches(t *testing.T) {
+	f, err := newScanFilter("all", nil, []string{"triaged"}, "alice", "10-20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		issue github.Issue
+		want  bool
+	}{
+		{"matches", github.Issue{Number: 15, Author: "alice"}, true},
+		{"author case-insensitive", github.Issue{Number: 15, Author: "Alice"}, true},
+		{"out of range", github.Issue{Number: 21, Author: "alice"}, false},
+		{"excluded label", github.Issue{Number: 15, Author: "alice", Labels: []string{"Triaged"}}, false},
+		{"other author", github.Issue{Number: 15, Author: "bob"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.matches(tt.issue); got != tt.want {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanFilterApplyTo(t *testing.T) {
+	f, _ := newScanFilter("closed", []string{"bug", "ui"}, nil, "alice", "")
+	opts := &gogithub.IssueListByRepoOptions{}
+	f.applyTo(opts)
+	if opts.State != "closed" || opts.Creator != "alice" || len(opts.Labels) != 2 {
+		t.Errorf("unexpected API options: %+v", opts)
+	}
+}
+
+func TestScanFilterParamsCanonical(t *testing.T) {
+	a, _ := newScanFilter("open", []string{"ui", "bug"}, nil, "", "1-5")
+	b, _ := newScanFilter("open", []string{"bug", "ui"}, nil, "", "1-5")
+	c, _ := newScanFilter("open", []string{"bug"}, nil, "", "1-5")
+	if a.params() != b.params() {
+		t.Errorf("label order should not change params: %q vs %q", a.params(), b.params())
+	}
+	if a.params() == c.params() {
+		t.Error("different labels should change params")
+	}
+}
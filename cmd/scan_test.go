@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"encoding/json"
+	"log/slog"
 	"testing"
 	"time"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
 )
 
 func TestScanCmdArgsValidation(t *testing.T) {
@@ -168,18 +173,13 @@ func TestParseSinceDuration(t *testing.T) {
 func TestScanJSONArrayOutput(t *testing.T) {
 	// Test that a slice of checkResultJSON marshals to a valid JSON array
 	results := []checkResultJSON{
-		{
-			Issue:      issueJSON{Number: 1, Title: "First issue"},
-			Duplicates: make([]duplicateJSON, 0),
-			Labels:     []labelJSON{{Name: "bug", Confidence: 0.9}},
-			Reasoning:  "looks like a bug",
-		},
-		{
-			Issue:      issueJSON{Number: 2, Title: "Second issue"},
-			Duplicates: []duplicateJSON{{Number: 1, Score: 0.85}},
-			Labels:     make([]labelJSON, 0),
-			Reasoning:  "",
-		},
+		newCheckResultJSON(github.Issue{Number: 1, Title: "First issue"}, &github.TriageResult{
+			SuggestedLabels: []github.LabelSuggestion{{Name: "bug", Confidence: 0.9}},
+			Reasoning:       "looks like a bug",
+		}),
+		newCheckResultJSON(github.Issue{Number: 2, Title: "Second issue"}, &github.TriageResult{
+			Duplicates: []github.DuplicateCandidate{{Number: 1, Score: 0.85}},
+		}),
 	}
 
 	data, err := json.MarshalIndent(results, "", "  ")
@@ -212,14 +212,13 @@ func TestScanJSONArrayOutput(t *testing.T) {
 }
 
 func TestScanJSONEmptyOutput(t *testing.T) {
-	// Test empty results produce "[]" not "null"
-	results := make([]checkResultJSON, 0)
-	data, err := json.Marshal(results)
+	// Test empty results produce empty arrays, not null
+	data, err := json.Marshal(newScanOutputJSON(nil))
 	if err != nil {
 		t.Fatalf("failed to marshal: %v", err)
 	}
-	if string(data) != "[]" {
-		t.Errorf("empty results = %q, want %q", string(data), "[]")
+	if want := `{"clusters":[],"issues":[]}`; string(data) != want {
+		t.Errorf("empty results = %q, want %q", string(data), want)
 	}
 }
 
@@ -247,6 +246,14 @@ func TestScanFlagRegistration(t *testing.T) {
 	if notifyFlag == nil {
 		t.Fatal("--notify flag not found on scan command")
 	}
+
+	resumeFlag := flags.Lookup("resume")
+	if resumeFlag == nil {
+		t.Fatal("--resume flag not found on scan command")
+	}
+	if resumeFlag.DefValue != "false" {
+		t.Errorf("--resume default = %q, want false", resumeFlag.DefValue)
+	}
 }
 
 func TestCheckFlagRegistration(t *testing.T) {
@@ -277,3 +284,98 @@ func TestScanCmd_WorkersFlagRegistered(t *testing.T) {
 		t.Errorf("expected default value '5', got %q", flag.DefValue)
 	}
 }
+
+func TestScanSkipLabeled(t *testing.T) {
+	defer func() { scanOnlyUnlabeled, scanIncludeLabeled = false, false }()
+
+	if got := scanSkipLabeled(); got != nil {
+		t.Errorf("expected no override by default, got %v", *got)
+	}
+	scanOnlyUnlabeled = true
+	if got := scanSkipLabeled(); got == nil || !*got {
+		t.Errorf("expected --only-unlabeled to skip labeled issues, got %v", got)
+	}
+	scanOnlyUnlabeled, scanIncludeLabeled = false, true
+	if got := scanSkipLabeled(); got == nil || *got {
+		t.Errorf("expected --include-labeled to classify labeled issues, got %v", got)
+	}
+
+	err := scanCmd.ParseFlags([]string{"--only-unlabeled", "--include-labeled"})
+	if err == nil {
+		err = scanCmd.ValidateFlagGroups()
+	}
+	defer func() {
+		for _, name := range []string{"only-unlabeled", "include-labeled"} {
+			scanCmd.Flags().Lookup(name).Changed = false
+		}
+	}()
+	if err == nil {
+		t.Error("expected --only-unlabeled and --include-labeled to be mutually exclusive")
+	}
+}
+
+func TestStartScanSessionResume(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo, _ := db.CreateRepo("owner", "repo")
+	logger := slog.Default()
+
+	issues := []github.Issue{{Number: 1}, {Number: 2}, {Number: 3}}
+
+	// Resume with nothing to resume starts a fresh session.
+	first, processed, err := startScanSession(db, repo.ID, "since=", true, logger)
+	if err != nil {
+		t.Fatalf("startScanSession: %v", err)
+	}
+	if pending := pendingIssues(issues, processed); len(pending) != 3 {
+		t.Fatalf("expected all issues pending, got %d", len(pending))
+	}
+	db.MarkScanProcessed(first.ID, 1)
+	db.MarkScanProcessed(first.ID, 3)
+
+	// Resuming skips processed issues and keeps the same session.
+	resumed, processed, err := startScanSession(db, repo.ID, "since=", true, logger)
+	if err != nil {
+		t.Fatalf("startScanSession resume: %v", err)
+	}
+	if resumed.ID != first.ID {
+		t.Errorf("expected to resume session %d, got %d", first.ID, resumed.ID)
+	}
+	if pending := pendingIssues(issues, processed); len(pending) != 1 || pending[0].Number != 2 {
+		t.Errorf("expected only #2 pending, got %+v", pending)
+	}
+
+	// Different params do not match the interrupted session.
+	other, processed, _ := startScanSession(db, repo.ID, "since=24h", true, logger)
+	if other.ID == first.ID || len(processed) != 0 {
+		t.Errorf("expected a fresh session for different params, got %+v with %d processed", other, len(processed))
+	}
+
+	// Without --resume a new session is always started.
+	fresh, processed, _ := startScanSession(db, repo.ID, "since=", false, logger)
+	if fresh.ID == first.ID || len(processed) != 0 {
+		t.Errorf("expected a fresh session without resume, got %+v with %d processed", fresh, len(processed))
+	}
+}
+
+func TestUnignoredIssues(t *testing.T) {
+	cfg := &config.Config{Repos: []config.RepoConfig{{
+		Name:   "org/app",
+		Ignore: &config.IgnoreRules{Authors: []string{"dependabot[bot]"}},
+	}}}
+	issues := []github.Issue{
+		{Number: 1, Title: "Crash", Author: "alice"},
+		{Number: 2, Title: "Bump deps", Author: "dependabot[bot]"},
+	}
+
+	got := unignoredIssues(cfg, "org/app", issues)
+	if len(got) != 1 || got[0].Number != 1 {
+		t.Errorf("got %v, want only #1", got)
+	}
+	if got := unignoredIssues(cfg, "org/other", issues); len(got) != 2 {
+		t.Errorf("expected every issue for an unconfigured repo, got %v", got)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jacklau/triage/internal/secrets"
+)
+
+// refreshSecrets re-resolves the config's secret references every interval
+// until ctx is done.
+func refreshSecrets(ctx context.Context, c *components, r *secrets.Resolver, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshSecretsOnce(ctx, c, r)
+		}
+	}
+}
+
+// refreshSecretsOnce re-resolves each secret reference once. Rotated
+// provider API keys take effect immediately by swapping in a new provider;
+// other secrets are only read at startup, so a change is logged.
+func refreshSecretsOnce(ctx context.Context, c *components, r *secrets.Resolver) {
+	paths := make([]string, 0, len(c.Config.SecretRefs))
+	for path := range c.Config.SecretRefs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		v, err := r.Resolve(ctx, c.Config.SecretRefs[path])
+		if err != nil {
+			c.Logger.Warn("failed to refresh secret, keeping the current value", "field", path, "error", err)
+			continue
+		}
+		field := c.Config.SecretField(path)
+		if field == nil || *field == v {
+			continue
+		}
+
+		switch {
+		case path == "providers.embedding.api_key" && c.embedSwap != nil:
+			pc := c.Config.Providers.Embedding
+			pc.APIKey = v
+			e, err := newEmbedder(pc)
+			if err != nil {
+				c.Logger.Warn("failed to apply rotated secret", "field", path, "error", err)
+				continue
+			}
+			c.embedSwap.Swap(e)
+		case path == "providers.llm.api_key" && c.llmSwap != nil:
+			pc := c.Config.Providers.LLM
+			pc.APIKey = v
+			llm, err := newCompleter(pc)
+			if err != nil {
+				c.Logger.Warn("failed to apply rotated secret", "field", path, "error", err)
+				continue
+			}
+			c.llmSwap.Swap(llm)
+		default:
+			c.Logger.Warn("secret changed; restart to use the new value", "field", path)
+			continue
+		}
+		*field = v
+		c.Logger.Info("picked up rotated secret", "field", path)
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/provider"
+	"github.com/jacklau/triage/internal/secrets"
+)
+
+type mapBackend map[string]string
+
+func (m mapBackend) Get(_ context.Context, path, key string) (string, error) {
+	v, ok := m[path+"#"+key]
+	if !ok {
+		return "", fmt.Errorf("no secret %s", path)
+	}
+	return v, nil
+}
+
+func TestInitComponentsResolvesSecrets(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/triage" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"data":{"openai":"sk-from-vault"}}}`)
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	cfg := &config.Config{
+		Store: config.StoreConfig{Path: ":memory:"},
+		Providers: config.ProvidersConfig{
+			LLM: config.ProviderConfig{Type: "openai", APIKey: "vault://secret/triage#openai"},
+		},
+	}
+	c, err := initComponents(cfg, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Store.Close()
+
+	if cfg.Providers.LLM.APIKey != "sk-from-vault" {
+		t.Errorf("api_key = %q, want the resolved secret", cfg.Providers.LLM.APIKey)
+	}
+	if c.llmSwap == nil || c.embedSwap != nil {
+		t.Error("expected only the LLM provider to be swappable")
+	}
+
+	cfg = &config.Config{
+		Store:     config.StoreConfig{Path: ":memory:"},
+		Providers: config.ProvidersConfig{LLM: config.ProviderConfig{Type: "openai", APIKey: "vault://secret/missing"}},
+	}
+	if _, err := initComponents(cfg, slog.Default()); err == nil || !strings.Contains(err.Error(), "providers.llm.api_key") {
+		t.Errorf("expected resolution error naming the field, got %v", err)
+	}
+}
+
+func TestRefreshSecretsOnce(t *testing.T) {
+	backend := mapBackend{"secret/llm#": "sk-old", "secret/slack#": "https://hooks.slack.com/old"}
+	r := secrets.NewResolverWith(map[string]secrets.Backend{"vault": backend})
+
+	cfg := &config.Config{
+		Providers: config.ProvidersConfig{LLM: config.ProviderConfig{Type: "openai", APIKey: "vault://secret/llm"}},
+		Notify:    config.NotifyConfig{SlackWebhook: "vault://secret/slack"},
+	}
+	if err := cfg.ResolveSecrets(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	c := &components{
+		Config:  cfg,
+		Logger:  slog.New(slog.NewTextHandler(&logs, nil)),
+		llmSwap: provider.NewSwappableCompleter(nil),
+	}
+
+	refreshSecretsOnce(context.Background(), c, r)
+	if logs.Len() != 0 {
+		t.Errorf("expected no changes, got logs:\n%s", logs.String())
+	}
+
+	backend["secret/llm#"] = "sk-new"
+	backend["secret/slack#"] = "https://hooks.slack.com/new"
+	refreshSecretsOnce(context.Background(), c, r)
+
+	if cfg.Providers.LLM.APIKey != "sk-new" {
+		t.Errorf("expected rotated API key to be applied, got %q", cfg.Providers.LLM.APIKey)
+	}
+	if cfg.Notify.SlackWebhook != "https://hooks.slack.com/old" {
+		t.Errorf("webhook should keep its startup value, got %q", cfg.Notify.SlackWebhook)
+	}
+	for _, want := range []string{"picked up rotated secret", "field=providers.llm.api_key", "restart to use the new value", "field=notify.slack_webhook"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs missing %q:\n%s", want, logs.String())
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/api"
+	"github.com/jacklau/triage/internal/auth"
+	"github.com/jacklau/triage/internal/chatops"
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/sla"
+	"github.com/jacklau/triage/internal/store"
+)
+
+var (
+	serveAddr       string
+	serveNotifyTest bool
+)
+
+// statsWindow is the period "/triage stats" covers.
+const statsWindow = 7 * 24 * time.Hour
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Answer Slack and Discord slash commands and the similar issues API",
+	Long: `Serve runs an HTTP server that answers "/triage" slash commands from Slack
+and Discord, so maintainers can trigger triage from chat:
+
+  /triage check 42                  triage an issue and reply in the channel
+  /triage check owner/repo#42
+  /triage stats [owner/repo]        summarize the last week of triage
+
+Slack commands are served at /slack/commands and need
+server.slack_signing_secret; Discord interactions are served at
+/discord/interactions and need server.discord_public_key. When a single repo
+is configured, commands may leave it out.
+
+Slash commands need an API token (see "triage token") with the triage
+scope, sent as "Authorization: Bearer <token>" or, as chat platforms cannot
+set headers, as an access_token query parameter in the registered URL.
+
+Check runs the pipeline like the check command: nothing is written to
+GitHub and no notifications are sent.
+
+With server.similar.enabled, POST /api/similar also answers with the stored
+issues similar to a title and body, for a "similar issues" box in a custom
+issue form. Browsers call it without a token, so it answers only for the
+configured repos that are public or listed in server.similar.private_repos,
+from the allowed origins, at server.similar.rate_limit requests a minute
+per client.
+
+Notification webhook URLs, used for SLA reminders, are checked at startup;
+serve fails if one is malformed or its host does not resolve. Use
+--notify-test to also send a test message.`,
+	Example: `  triage serve
+  triage serve --addr :9000`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "", "address to listen on (default server.addr, or :8080)")
+	serveCmd.Flags().BoolVar(&serveNotifyTest, "notify-test", false, "send a test message at startup and exit if it fails")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	if err := checkServeNotifiers(cmd.Context(), cfg, c.Store); err != nil {
+		return err
+	}
+
+	authn, err := newAuthenticator(cfg, c.Store)
+	if err != nil {
+		return err
+	}
+	mux, err := newServeMux(cfg, &serveRunner{c: c}, authn, logger)
+	if err != nil {
+		return err
+	}
+
+	addr := serveAddr
+	if addr == "" {
+		addr = cfg.Server.Addr
+	}
+	if addr == "" {
+		addr = ":8080"
+	}
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		logger.Info("received signal, shutting down", "signal", sig)
+		cancel()
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, done := context.WithTimeout(context.Background(), 10*time.Second)
+		defer done()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("shutting down server", "error", err)
+		}
+	}()
+
+	if err := startSLAReminders(ctx, c); err != nil {
+		return err
+	}
+
+	logger.Info("serving", "addr", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving: %w", err)
+	}
+	return nil
+}
+
+// serveBackend answers the requests serve routes.
+type serveBackend interface {
+	chatops.Runner
+	api.SimilarFinder
+}
+
+// newServeMux routes each configured integration to its handler. Slash
+// commands, which run the pipeline, need a bearer token with the triage
+// scope; the similar issues API is public, for browsers. It is an error if
+// none is configured.
+func newServeMux(cfg *config.Config, run serveBackend, authn *auth.Authenticator, logger *slog.Logger) (*http.ServeMux, error) {
+	defaultRepo := singleConfiguredRepo(cfg)
+	mux := http.NewServeMux()
+	configured := false
+
+	if secret := cfg.Server.SlackSigningSecret; secret != "" {
+		mux.Handle("/slack/commands", authn.Require(auth.ScopeTriage, chatops.NewSlackHandler(secret, run, defaultRepo, logger)))
+		configured = true
+	}
+	if key := cfg.Server.DiscordPublicKey; key != "" {
+		h, err := chatops.NewDiscordHandler(key, run, defaultRepo, logger)
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle("/discord/interactions", authn.Require(auth.ScopeTriage, h))
+		configured = true
+	}
+	if sim := cfg.Server.Similar; sim.Enabled {
+		mux.Handle("/api/similar", api.NewSimilarHandler(run, defaultRepo, sim.AllowedOrigins, sim.RateLimit, logger))
+		configured = true
+	}
+	if cfg.SLA.Enabled() {
+		// Reminders are sent in the background; see startSLAReminders
+		configured = true
+	}
+	if !configured {
+		return nil, fmt.Errorf("nothing to serve (set server.slack_signing_secret, server.discord_public_key, server.similar.enabled, or sla)")
+	}
+	return mux, nil
+}
+
+// startSLAReminders checks for issues past their SLA every sla.check_interval
+// until ctx is cancelled, when the config sets an SLA.
+func startSLAReminders(ctx context.Context, c *components) error {
+	cfg := c.Config
+	if !cfg.SLA.Enabled() {
+		return nil
+	}
+	limits, err := slaLimits(cfg.SLA)
+	if err != nil {
+		return err
+	}
+	interval, err := cfg.SLA.Interval()
+	if err != nil {
+		return err
+	}
+	n, err := createNotifier(cfg, "", c.Store)
+	if err != nil {
+		return fmt.Errorf("creating notifier: %w", err)
+	}
+	if n == nil {
+		c.Logger.Warn("sla is set but no notifier is configured; not sending reminders")
+		return nil
+	}
+	checker := sla.NewChecker(c.Store, limits, notifyStyle(cfg), n, c.Logger)
+	go checker.Run(ctx, interval, func() ([]store.Repo, error) { return slaRepos(c) })
+	c.Logger.Info("sending SLA reminders", "check_interval", interval)
+	return nil
+}
+
+// checkServeNotifiers checks the configured notifiers at startup, sending a
+// test message with --notify-test.
+func checkServeNotifiers(ctx context.Context, cfg *config.Config, st *store.DB) error {
+	n, err := createNotifier(cfg, "", st)
+	if err != nil {
+		return fmt.Errorf("creating notifier: %w", err)
+	}
+	if n == nil {
+		if serveNotifyTest {
+			return fmt.Errorf("--notify-test requires a notification target (set notify.slack_webhook or notify.discord_webhook)")
+		}
+		return nil
+	}
+	return checkNotifyTargets(ctx, cfg, n, serveNotifyTest && !dryRun)
+}
+
+// slaLimits returns the configured SLA of each severity.
+func slaLimits(cfg config.SLAConfig) (map[notify.Severity]time.Duration, error) {
+	byName, err := cfg.Limits()
+	if err != nil {
+		return nil, err
+	}
+	limits := make(map[notify.Severity]time.Duration, len(byName))
+	for _, sev := range []notify.Severity{notify.SeverityHigh, notify.SeverityMedium, notify.SeverityLow} {
+		if d, ok := byName[sev.String()]; ok {
+			limits[sev] = d
+		}
+	}
+	return limits, nil
+}
+
+// slaRepos returns the stored repos SLA reminders cover: those in the config
+// that are still polled.
+func slaRepos(c *components) ([]store.Repo, error) {
+	repos, err := c.Store.ListRepos()
+	if err != nil {
+		return nil, err
+	}
+	var covered []store.Repo
+	for _, r := range repos {
+		if r.DisabledAt != nil {
+			continue
+		}
+		if _, ok := c.Config.Repo(r.Owner + "/" + r.RepoName); ok {
+			covered = append(covered, r)
+		}
+	}
+	return covered, nil
+}
+
+// singleConfiguredRepo returns the configured repo when there is exactly one
+// and it is not a pattern, or "" otherwise.
+func singleConfiguredRepo(cfg *config.Config) string {
+	if len(cfg.Repos) != 1 || config.IsRepoPattern(cfg.Repos[0].Name) {
+		return ""
+	}
+	return cfg.Repos[0].Name
+}
+
+// serveRunner runs slash commands against the configured components.
+type serveRunner struct {
+	c *components
+}
+
+// Check fetches and triages an issue, like the check command.
+func (r *serveRunner) Check(ctx context.Context, repoFull string, number int) (string, error) {
+	if r.c.GHClient == nil {
+		return "", notConfigured("GitHub client", "")
+	}
+	owner, repo, err := parseRepoArg(repoFull)
+	if err != nil {
+		return "", err
+	}
+	ghIssue, _, err := r.c.GHClient.Issues.Get(ctx, owner, repo, number)
+	if err != nil {
+		return "", fmt.Errorf("fetching issue #%d: %w", number, err)
+	}
+	issue := convertGHIssue(ghIssue)
+	result, err := triageIssue(ctx, r.c, owner, repo, issue)
+	if err != nil {
+		return "", err
+	}
+	return formatCheckReply(repoFull, issue, result), nil
+}
+
+// Stats summarizes the last week of triage for a repo.
+func (r *serveRunner) Stats(ctx context.Context, repo string) (string, error) {
+	reports, err := buildReports(r.c.Store, []string{repo}, statsWindow, time.Now())
+	if err != nil {
+		return "", err
+	}
+	rep := reports[0]
+	return fmt.Sprintf("**%s**\n%s", rep.Title(), rep.Markdown()), nil
+}
+
+// Similar returns the stored issues of a configured repo similar to a draft,
+// with the server.similar threshold and cap. Private repos are only served
+// when listed in server.similar.private_repos.
+func (r *serveRunner) Similar(ctx context.Context, repoFull, title, body string) ([]github.DuplicateCandidate, error) {
+	owner, repo, err := parseRepoArg(repoFull)
+	if err != nil {
+		return nil, api.ErrUnknownRepo
+	}
+	if _, ok := r.c.Config.Repo(repoFull); !ok {
+		return nil, api.ErrUnknownRepo
+	}
+	rec, err := r.c.Store.GetRepoByOwnerRepo(owner, repo)
+	if errors.Is(err, store.ErrRepoNotFound) {
+		return nil, api.ErrUnknownRepo
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up repo: %w", err)
+	}
+	sim := r.c.Config.Server.Similar
+	if !r.similarAllowed(ctx, repoFull, rec.ID) {
+		return nil, api.ErrUnknownRepo
+	}
+	if r.c.Dedup == nil {
+		return nil, notConfigured("embedding provider", "")
+	}
+	return r.c.Dedup.Similar(ctx, rec.ID, github.Issue{Title: title, Body: body}, float32(sim.Threshold), sim.MaxResults)
+}
+
+// similarAllowed reports whether the similar issues API may show the issues
+// of a repo: one listed in server.similar.private_repos, or one GitHub says
+// is public. Repos whose visibility is not known are not shown.
+func (r *serveRunner) similarAllowed(ctx context.Context, repoFull string, repoID int64) bool {
+	for _, name := range r.c.Config.Server.Similar.PrivateRepos {
+		if strings.EqualFold(name, repoFull) {
+			return true
+		}
+	}
+	if r.c.RepoMetadata == nil {
+		return false
+	}
+	m, err := r.c.RepoMetadata.Get(ctx, repoID, repoFull)
+	if m == nil || m.FetchedAt.IsZero() {
+		r.c.Logger.Warn("not serving similar issues of a repo of unknown visibility", "repo", repoFull, "error", err)
+		return false
+	}
+	return !m.Private
+}
+
+// formatCheckReply describes a triage result as a chat message.
+func formatCheckReply(repoFull string, issue github.Issue, result *github.TriageResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s#%d** %s\n", repoFull, issue.Number, issue.Title)
+	fmt.Fprintf(&b, "**Labels:** %s\n", notify.FormatSuggestedLabels(*result))
+	fmt.Fprintf(&b, "**Duplicates:**\n%s\n", notify.FormatDuplicates(result.Duplicates))
+	if result.Transfer != nil {
+		fmt.Fprintf(&b, "%s\n", notify.FormatTransfer(result.Transfer))
+	}
+	if len(result.PullRequests) > 0 {
+		fmt.Fprintf(&b, "%s\n", notify.FormatPullRequests(result.PullRequests))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/api"
+	"github.com/jacklau/triage/internal/auth"
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/provider"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestNewServeMux(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	run := &serveRunner{}
+
+	authn := auth.NewAuthenticator(nil, nil)
+
+	if _, err := newServeMux(&config.Config{}, run, authn, logger); err == nil {
+		t.Error("expected error with no integration configured")
+	}
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		SlackSigningSecret: "shh",
+		DiscordPublicKey:   strings.Repeat("ab", 32),
+		Similar:            config.SimilarAPIConfig{Enabled: true},
+	}}
+	mux, err := newServeMux(cfg, run, authn, logger)
+	if err != nil {
+		t.Fatalf("newServeMux: %v", err)
+	}
+	for _, path := range []string{"/slack/commands", "/discord/interactions", "/api/similar"} {
+		if _, pattern := mux.Handler(httptest.NewRequest(http.MethodPost, path, nil)); pattern != path {
+			t.Errorf("%s not routed (pattern %q)", path, pattern)
+		}
+	}
+
+	// SLA reminders alone are enough to serve
+	if _, err := newServeMux(&config.Config{SLA: config.SLAConfig{High: "4h"}}, run, authn, logger); err != nil {
+		t.Errorf("expected SLA reminders alone to be served, got %v", err)
+	}
+}
+
+func TestServeMuxRequiresToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{Server: config.ServerConfig{
+		SlackSigningSecret: "shh",
+		DiscordPublicKey:   strings.Repeat("ab", 32),
+		Similar:            config.SimilarAPIConfig{Enabled: true},
+	}}
+	authn := auth.NewAuthenticator(map[string]*auth.Token{
+		auth.HashToken("reader"): {Name: "reader", Scopes: []auth.Scope{auth.ScopeRead}},
+	}, nil)
+	mux, err := newServeMux(cfg, &serveRunner{}, authn, logger)
+	if err != nil {
+		t.Fatalf("newServeMux: %v", err)
+	}
+
+	tests := []struct {
+		method, target, token string
+		want                  int
+	}{
+		{http.MethodPost, "/slack/commands", "", http.StatusUnauthorized},
+		{http.MethodPost, "/discord/interactions", "", http.StatusUnauthorized},
+		{http.MethodPost, "/slack/commands", "reader", http.StatusForbidden},
+		{http.MethodPost, "/discord/interactions?access_token=reader", "", http.StatusForbidden},
+		// The similar issues API is public; the handler rejects the empty body
+		{http.MethodPost, "/api/similar", "", http.StatusBadRequest},
+		{http.MethodOptions, "/api/similar", "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(""))
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s with token %q: status = %d, want %d", tt.method, tt.target, tt.token, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestSLALimits(t *testing.T) {
+	limits, err := slaLimits(config.SLAConfig{High: "4h", Low: "3h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[notify.Severity]time.Duration{notify.SeverityHigh: 4 * time.Hour, notify.SeverityLow: 3 * time.Hour}
+	if !reflect.DeepEqual(limits, want) {
+		t.Errorf("slaLimits() = %v, want %v", limits, want)
+	}
+}
+
+func TestSLARepos(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "triage.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, name := range []string{"app", "gone", "private"} {
+		repo, err := db.CreateRepo("org", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "gone" {
+			if _, err := db.DisableRepo(repo.ID, "not found"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	c := &components{
+		Config: &config.Config{Repos: []config.RepoConfig{{Name: "org/app"}, {Name: "org/gone"}}},
+		Store:  db,
+	}
+	repos, err := slaRepos(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0].RepoName != "app" {
+		t.Errorf("expected only org/app to be covered, got %+v", repos)
+	}
+}
+
+func TestSingleConfiguredRepo(t *testing.T) {
+	tests := []struct {
+		repos []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"org/app"}, "org/app"},
+		{[]string{"org/*"}, ""},
+		{[]string{"org/app", "org/lib"}, ""},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{}
+		for _, name := range tt.repos {
+			cfg.Repos = append(cfg.Repos, config.RepoConfig{Name: name})
+		}
+		if got := singleConfiguredRepo(cfg); got != tt.want {
+			t.Errorf("singleConfiguredRepo(%v) = %q, want %q", tt.repos, got, tt.want)
+		}
+	}
+}
+
+func TestServeRunnerStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	seedReportStore(t, path)
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	r := &serveRunner{c: &components{Store: db}}
+	got, err := r.Stats(context.Background(), "org/repo")
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if !strings.HasPrefix(got, "**Triage report: org/repo") || !strings.Contains(got, "Issues triaged") {
+		t.Errorf("Stats = %q", got)
+	}
+
+	if _, err := r.Stats(context.Background(), "org/unknown"); err == nil {
+		t.Error("expected error for a repo with no triage data")
+	}
+}
+
+func TestServeRunnerSimilar(t *testing.T) {
+	srv := newFakeOllama(t)
+	db, err := store.Open(filepath.Join(t.TempDir(), "triage.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, name := range []string{"app", "private"} {
+		repo, err := db.CreateRepo("org", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, is := range []struct {
+			number int
+			title  string
+			vec    []float32
+		}{
+			{1, "App crash", []float32{1, 0, 0}},
+			{2, "Dark mode", []float32{0, 1, 0}},
+		} {
+			if err := db.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: is.number, Title: is.title, State: "open"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.UpdateEmbedding(repo.ID, is.number, dedup.EncodeEmbedding(is.vec), "nomic-embed-text"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for name, private := range map[string]bool{"app": false, "private": true} {
+		repo, err := db.GetRepoByOwnerRepo("org", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.SetRepoMetadata(repo.ID, &store.RepoMetadata{Private: private}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Visibility never fetched
+	if _, err := db.CreateRepo("org", "fresh"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Repos:  []config.RepoConfig{{Name: "org/app"}, {Name: "org/private"}, {Name: "org/fresh"}},
+		Server: config.ServerConfig{Similar: config.SimilarAPIConfig{Enabled: true, Threshold: 0.7, MaxResults: 5}},
+	}
+	var logs bytes.Buffer
+	r := &serveRunner{c: &components{
+		Config:       cfg,
+		Store:        db,
+		Logger:       slog.New(slog.NewTextHandler(&logs, nil)),
+		Dedup:        dedup.NewEngine(provider.NewOllamaEmbedder(srv.URL, "nomic-embed-text"), db),
+		RepoMetadata: github.NewRepoMetadataCache(nil, db, github.DefaultRepoMetadataTTL),
+	}}
+
+	similar, err := r.Similar(context.Background(), "org/app", "Crash when saving", "")
+	if err != nil {
+		t.Fatalf("Similar: %v", err)
+	}
+	if len(similar) != 1 || similar[0].Number != 1 || similar[0].Title != "App crash" {
+		t.Errorf("expected #1, got %+v", similar)
+	}
+
+	// Private, of unknown visibility, or not configured, so not served
+	for _, repo := range []string{"org/private", "org/fresh", "org/unknown", "bad"} {
+		if _, err := r.Similar(context.Background(), repo, "Crash", ""); !errors.Is(err, api.ErrUnknownRepo) {
+			t.Errorf("%s: expected ErrUnknownRepo, got %v", repo, err)
+		}
+	}
+	if !strings.Contains(logs.String(), "unknown visibility") || !strings.Contains(logs.String(), "org/fresh") {
+		t.Errorf("expected the unknown visibility to be logged, got %q", logs.String())
+	}
+
+	// Private repos are served once opted in
+	cfg.Server.Similar.PrivateRepos = []string{"org/private"}
+	if similar, err := r.Similar(context.Background(), "org/private", "Crash when saving", ""); err != nil || len(similar) != 1 {
+		t.Errorf("expected an opted-in private repo to be served, got %+v, %v", similar, err)
+	}
+}
+
+func TestFormatCheckReply(t *testing.T) {
+	issue := github.Issue{Number: 42, Title: "Crash on start"}
+	result := &github.TriageResult{
+		SuggestedLabels: []github.LabelSuggestion{{Name: "bug", Confidence: 0.9}},
+		Duplicates:      []github.DuplicateCandidate{{Number: 7, Score: 0.91}},
+	}
+	got := formatCheckReply("org/app", issue, result)
+	for _, want := range []string{"**org/app#42** Crash on start", "`bug` (90%)", "- #7 — 91% similar"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("reply missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "transferring") {
+		t.Errorf("reply mentions a transfer that was not suggested:\n%s", got)
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+var (
+	statsSince  string
+	statsOutput string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [owner/repo]",
+	Short: "Show triage latency per stage and provider",
+	Long: `Stats shows how long triage takes: the median (p50), 95th percentile (p95),
+and slowest time of each stage (dedup, classify, notify) per provider, and of
+the whole triage of an issue. Use it to compare models, such as a local LLM
+against a hosted one, and pick a faster one where it matters.
+
+Timings are recorded by watch and scan for each issue they triage, except in
+dry runs. Only stages that succeeded are counted. With no argument, every repo
+is covered; the last 30 days by default, set with --since.`,
+	Example:           `  triage stats --since 7d`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsSince, "since", "30d", "cover triages within this duration (e.g. 24h, 7d)")
+	statsCmd.Flags().StringVar(&statsOutput, "output", "text", "output format: text or json")
+	registerFlagValues(statsCmd, "output", outputFormats)
+	rootCmd.AddCommand(statsCmd)
+}
+
+// latencyRow is a stage and provider's latency as the stats command reports
+// it, in milliseconds.
+type latencyRow struct {
+	Stage    string `json:"stage"`
+	Provider string `json:"provider,omitempty"`
+	Count    int    `json:"count"`
+	P50Ms    int64  `json:"p50_ms"`
+	P95Ms    int64  `json:"p95_ms"`
+	MaxMs    int64  `json:"max_ms"`
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	window, err := parseSinceDuration(statsSince)
+	if err != nil {
+		return err
+	}
+	if window <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	if statsOutput != "text" && statsOutput != "json" {
+		return fmt.Errorf("invalid --output %q: expected text or json", statsOutput)
+	}
+
+	_, db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var repoID int64
+	if len(args) == 1 {
+		owner, name, err := parseRepoArg(args[0])
+		if err != nil {
+			return err
+		}
+		repo, err := db.GetRepoByOwnerRepo(owner, name)
+		if errors.Is(err, store.ErrRepoNotFound) {
+			return fmt.Errorf("%s is not tracked; run scan or watch first", args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("looking up repo: %w", err)
+		}
+		repoID = repo.ID
+	}
+
+	stats, err := db.GetLatencyStats(repoID, time.Now().Add(-window))
+	if err != nil {
+		return err
+	}
+	rows := make([]latencyRow, len(stats))
+	for i, s := range stats {
+		rows[i] = latencyRow{
+			Stage:    s.Stage,
+			Provider: s.Provider,
+			Count:    s.Count,
+			P50Ms:    s.P50.Milliseconds(),
+			P95Ms:    s.P95.Milliseconds(),
+			MaxMs:    s.Max.Milliseconds(),
+		}
+	}
+
+	if statsOutput == "json" {
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+	writeLatency(cmd.OutOrStdout(), stats)
+	return nil
+}
+
+// writeLatency prints stats as a table, stage by stage.
+func writeLatency(w io.Writer, stats []store.LatencyStats) {
+	if len(stats) == 0 {
+		fmt.Fprintln(w, "No triage timings recorded in this window.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tPROVIDER\tCOUNT\tP50\tP95\tMAX")
+	for _, s := range stats {
+		provider := s.Provider
+		if provider == "" {
+			provider = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", s.Stage, provider, s.Count,
+			formatLatency(s.P50), formatLatency(s.P95), formatLatency(s.Max))
+	}
+	tw.Flush()
+}
+
+// formatLatency formats d to the millisecond below a second and to a tenth
+// of a second above, e.g. "850ms" or "4.2s".
+func formatLatency(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestRunStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.LogTriageTimings([]store.TriageTiming{
+		{RepoID: repo.ID, IssueNumber: 1, Stage: store.StageDedup, Provider: "ollama:nomic-embed-text", Duration: 120 * time.Millisecond},
+		{RepoID: repo.ID, IssueNumber: 1, Stage: store.StageClassify, Provider: "ollama:llama3", Duration: 4200 * time.Millisecond},
+		{RepoID: repo.ID, IssueNumber: 1, Stage: store.StageTotal, Duration: 4500 * time.Millisecond},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	writeTestConfig(t, fmt.Sprintf("store:\n  path: %s\n", path))
+
+	var out bytes.Buffer
+	statsCmd.SetOut(&out)
+	defer statsCmd.SetOut(nil)
+
+	if err := runStats(statsCmd, nil); err != nil {
+		t.Fatalf("runStats: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and 3 rows:\n%s", out.String())
+	}
+	for i, want := range []string{"dedup  ", "classify", "total"} {
+		if !strings.HasPrefix(lines[i+1], want) {
+			t.Errorf("row %d = %q, want it to start with %q", i, lines[i+1], want)
+		}
+	}
+	for _, want := range []string{"ollama:llama3", "4.2s", "120ms", "total     -"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	statsOutput = "json"
+	defer func() { statsOutput = "text" }()
+	if err := runStats(statsCmd, []string{"org/repo"}); err != nil {
+		t.Fatalf("runStats: %v", err)
+	}
+	var rows []latencyRow
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	want := latencyRow{Stage: "classify", Provider: "ollama:llama3", Count: 1, P50Ms: 4200, P95Ms: 4200, MaxMs: 4200}
+	if len(rows) != 3 || rows[1] != want {
+		t.Errorf("rows = %+v, want classify row %+v", rows, want)
+	}
+
+	if err := runStats(statsCmd, []string{"org/other"}); err == nil || !strings.Contains(err.Error(), "not tracked") {
+		t.Errorf("expected an untracked repo error, got %v", err)
+	}
+}
+
+func TestRunStatsFlags(t *testing.T) {
+	defer func() { statsSince, statsOutput = "30d", "text" }()
+	for _, set := range []func(){
+		func() { statsSince = "0d" },
+		func() { statsSince = "soon" },
+		func() { statsOutput = "xml" },
+	} {
+		statsSince, statsOutput = "30d", "text"
+		set()
+		if err := runStats(statsCmd, nil); err == nil {
+			t.Errorf("expected an error with --since %q --output %q", statsSince, statsOutput)
+		}
+	}
+}
+
+func TestWriteLatencyEmpty(t *testing.T) {
+	var out bytes.Buffer
+	writeLatency(&out, nil)
+	if !strings.Contains(out.String(), "No triage timings") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if got := formatLatency(850 * time.Millisecond); got != "850ms" {
+		t.Errorf("formatLatency = %q", got)
+	}
+}
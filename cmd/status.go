@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"text/tabwriter"
 	"time"
@@ -50,10 +51,10 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Print per-repo stats
 	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(w, "REPOSITORY\tISSUES\tEMBEDDINGS\tCLASSIFIED\tLAST POLLED")
-	fmt.Fprintln(w, "----------\t------\t----------\t----------\t-----------")
+	fmt.Fprintln(w, "REPOSITORY\tISSUES\tEMBEDDINGS\tCLASSIFIED\tABSTAINED\tLAST POLLED")
+	fmt.Fprintln(w, "----------\t------\t----------\t----------\t---------\t-----------")
 
-	var totalIssues, totalEmbeddings, totalClassified int
+	var totalIssues, totalEmbeddings, totalClassified, totalAbstained int
 	for _, s := range allStats {
 		repoName := fmt.Sprintf("%s/%s", s.Repo.Owner, s.Repo.RepoName)
 		lastPolled := "never"
@@ -61,17 +62,20 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			lastPolled = formatTimeAgo(*s.Repo.LastPolledAt)
 		}
 
-		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n",
-			repoName, s.IssueCount, s.EmbeddingCount, s.ClassifiedCount, lastPolled)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\n",
+			repoName, s.IssueCount, s.EmbeddingCount, s.ClassifiedCount,
+			formatAbstained(s.AbstainedCount, s.ClassifiedCount), lastPolled)
 
 		totalIssues += s.IssueCount
 		totalEmbeddings += s.EmbeddingCount
 		totalClassified += s.ClassifiedCount
+		totalAbstained += s.AbstainedCount
 	}
 
 	if len(allStats) > 1 {
-		fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t\n",
-			totalIssues, totalEmbeddings, totalClassified)
+		fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t%s\t\n",
+			totalIssues, totalEmbeddings, totalClassified,
+			formatAbstained(totalAbstained, totalClassified))
 	}
 	w.Flush()
 
@@ -87,6 +91,15 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// formatAbstained formats an abstention count with its rate among classified
+// issues, e.g. "3 (12%)".
+func formatAbstained(abstained, classified int) string {
+	if classified == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%d (%d%%)", abstained, int(math.Round(float64(abstained)/float64(classified)*100)))
+}
+
 // formatTimeAgo formats a time as a human-readable relative string.
 func formatTimeAgo(t time.Time) string {
 	d := time.Since(t)
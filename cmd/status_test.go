@@ -83,6 +83,15 @@ func TestFormatBytes(t *testing.T) {
 	}
 }
 
+func TestFormatAbstained(t *testing.T) {
+	if got := formatAbstained(0, 0); got != "0" {
+		t.Errorf("formatAbstained(0, 0) = %q, want %q", got, "0")
+	}
+	if got := formatAbstained(3, 25); got != "3 (12%)" {
+		t.Errorf("formatAbstained(3, 25) = %q, want %q", got, "3 (12%)")
+	}
+}
+
 func TestDbFileSize_NonExistent(t *testing.T) {
 	_, err := dbFileSize("/nonexistent/path/to/db.sqlite")
 	if err == nil {
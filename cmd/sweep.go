@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/pipeline"
+)
+
+var (
+	sweepSince  string
+	sweepNotify string
+)
+
+var sweepCmd = &cobra.Command{
+	Use:   "sweep [owner/repo ...]",
+	Short: "Re-check recent issues for duplicates missed at filing time",
+	Long: `Sweep re-checks recently filed open issues against every embedding now in
+the store and reports duplicates that were not found when the issues were
+triaged. Dedup at filing time misses matches whose embeddings arrived later,
+for example after scanning a repo's history.
+
+Only stored embeddings are compared, so sweep makes no provider calls. Only
+issues that were triaged before are swept, and only earlier issues are
+reported as their duplicates. Findings are recorded in the triage log, where
+"triage apply pending" picks them up.
+
+With no arguments, every repository in the store is swept. To sweep
+automatically, run watch with --sweep-every.`,
+	ValidArgsFunction: completeRepos(0),
+	RunE:              runSweep,
+}
+
+func init() {
+	sweepCmd.Flags().StringVar(&sweepSince, "since", "7d", "sweep issues created within this duration (e.g. 24h, 7d)")
+	sweepCmd.Flags().StringVar(&sweepNotify, "notify", "", "also send findings to: slack, discord, or both")
+	registerFlagValues(sweepCmd, "notify", notifyTargets)
+	rootCmd.AddCommand(sweepCmd)
+}
+
+func runSweep(cmd *cobra.Command, args []string) error {
+	window, err := parseSinceDuration(sweepSince)
+	if err != nil {
+		return err
+	}
+	if window <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	for _, arg := range args {
+		if _, _, err := parseRepoArg(arg); err != nil {
+			return err
+		}
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	repos := args
+	if len(repos) == 0 {
+		all, err := c.Store.ListRepos()
+		if err != nil {
+			return fmt.Errorf("listing repos: %w", err)
+		}
+		for _, r := range all {
+			repos = append(repos, r.FullName())
+		}
+	}
+
+	n, err := createNotifier(cfg, sweepNotify, c.Store)
+	if err != nil {
+		return fmt.Errorf("creating notifier: %w", err)
+	}
+	p := createPipeline(c, n, nil, nil)
+
+	var findings []*github.TriageResult
+	since := time.Now().Add(-window)
+	for _, repo := range repos {
+		found, err := p.Sweep(context.Background(), repo, since)
+		if err != nil {
+			return fmt.Errorf("sweeping %s: %w", repo, err)
+		}
+		findings = append(findings, found...)
+	}
+	writeSweepFindings(cmd.OutOrStdout(), findings)
+	return nil
+}
+
+// writeSweepFindings prints one line per issue with late duplicates.
+func writeSweepFindings(w io.Writer, findings []*github.TriageResult) {
+	for _, f := range findings {
+		dups := make([]string, len(f.Duplicates))
+		for i, d := range f.Duplicates {
+			dups[i] = fmt.Sprintf("#%d (%d%%)", d.Number, int(math.Round(float64(d.Score)*100)))
+		}
+		fmt.Fprintf(w, "%s#%d: possible duplicate of %s\n", f.Repo, f.IssueNumber, strings.Join(dups, ", "))
+	}
+	fmt.Fprintf(w, "%d late duplicate(s) found\n", len(findings))
+}
+
+// runSweepLoop sweeps repos every interval for issues created within window,
+// until ctx is cancelled.
+func runSweepLoop(ctx context.Context, p *pipeline.Pipeline, c *components, repos []string, every, window time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, repo := range repos {
+				findings, err := p.Sweep(ctx, repo, now.Add(-window))
+				if err != nil {
+					c.Logger.Warn("duplicate sweep failed", "repo", repo, "error", err)
+					continue
+				}
+				c.Logger.Info("duplicate sweep complete", "repo", repo, "late_duplicates", len(findings))
+			}
+		}
+	}
+}
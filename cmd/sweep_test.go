@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestWriteSweepFindings(t *testing.T) {
+	var out bytes.Buffer
+	writeSweepFindings(&out, []*github.TriageResult{{
+		Repo:        "org/repo",
+		IssueNumber: 10,
+		Duplicates:  []github.DuplicateCandidate{{Number: 1, Score: 0.934}, {Number: 4, Score: 0.9}},
+	}})
+	want := "org/repo#10: possible duplicate of #1 (93%), #4 (90%)\n1 late duplicate(s) found\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunSweep(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, is := range []struct {
+		number int
+		age    time.Duration
+		vec    []float32
+	}{
+		{1, 90 * 24 * time.Hour, []float32{1, 0, 0}},
+		{10, 24 * time.Hour, []float32{1, 0.01, 0}},
+	} {
+		created := time.Now().Add(-is.age)
+		if err := db.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: is.number, Title: "t", State: "open", CreatedAt: created, UpdatedAt: created}); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.UpdateEmbedding(repo.ID, is.number, dedup.EncodeEmbedding(is.vec), "m"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.LogTriageAction(&store.TriageLog{RepoID: repo.ID, IssueNumber: 10, Action: "triaged"}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	writeTestConfig(t, fmt.Sprintf(`store:
+  path: %s
+providers:
+  embedding:
+    type: ollama
+    model: nomic-embed-text
+`, path))
+
+	var out bytes.Buffer
+	sweepCmd.SetOut(&out)
+	defer sweepCmd.SetOut(nil)
+
+	if err := runSweep(sweepCmd, nil); err != nil {
+		t.Fatalf("runSweep: %v", err)
+	}
+	if !strings.Contains(out.String(), "org/repo#10: possible duplicate of #1") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	if err := runSweep(sweepCmd, []string{"bad"}); err == nil {
+		t.Error("expected error for invalid repo argument")
+	}
+}
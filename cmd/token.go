@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/auth"
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/store"
+)
+
+var tokenScopes []string
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage API tokens for the HTTP surface",
+	Long: `Create, list, and revoke API tokens used to authenticate requests to
+triage's HTTP endpoints. Tokens carry scopes: read, triage, or admin.
+Higher scopes imply lower ones (admin > triage > read).
+
+Tokens can also be configured statically under server.tokens in the
+config file.`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new API token",
+	Long: `Create a new API token and print it. The plaintext token is shown only
+once; only its hash is stored.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTokenCreate,
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API tokens",
+	Args:  cobra.NoArgs,
+	RunE:  runTokenList,
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Revoke an API token",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTokenRevoke,
+}
+
+func init() {
+	tokenCreateCmd.Flags().StringSliceVar(&tokenScopes, "scope", []string{string(auth.ScopeRead)}, "token scopes: read, triage, admin (repeatable)")
+	tokenCmd.AddCommand(tokenCreateCmd, tokenListCmd, tokenRevokeCmd)
+	rootCmd.AddCommand(tokenCmd)
+}
+
+// openStore loads the config and opens only the store, for commands that
+// don't need providers or a GitHub client.
+func openStore() (*config.Config, *store.DB, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading config: %w", err)
+	}
+	db, err := store.Open(cfg.Store.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening store: %w", err)
+	}
+	return cfg, db, nil
+}
+
+func runTokenCreate(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	scopes, err := auth.ParseScopes(tokenScopes)
+	if err != nil {
+		return err
+	}
+
+	_, db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	plaintext, err := auth.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	scopeNames := make([]string, len(scopes))
+	for i, s := range scopes {
+		scopeNames[i] = string(s)
+	}
+	if _, err := db.CreateAPIToken(name, auth.HashToken(plaintext), scopeNames); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Created token %q with scopes: %s\n", name, strings.Join(scopeNames, ", "))
+	fmt.Fprintln(out, "Store it now; it will not be shown again:")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "  "+plaintext)
+	return nil
+}
+
+func runTokenList(cmd *cobra.Command, args []string) error {
+	cfg, db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tokens, err := db.ListAPITokens()
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 && len(cfg.Server.Tokens) == 0 {
+		fmt.Println("No API tokens configured.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCOPES\tSOURCE\tLAST USED\tSTATUS")
+	for _, t := range cfg.Server.Tokens {
+		fmt.Fprintf(w, "%s\t%s\tconfig\t-\tactive\n", t.Name, strings.Join(t.Scopes, ","))
+	}
+	for _, t := range tokens {
+		lastUsed := "never"
+		if t.LastUsedAt != nil {
+			lastUsed = formatTimeAgo(*t.LastUsedAt)
+		}
+		status := "active"
+		if t.RevokedAt != nil {
+			status = "revoked"
+		}
+		fmt.Fprintf(w, "%s\t%s\tstore\t%s\t%s\n", t.Name, strings.Join(t.Scopes, ","), lastUsed, status)
+	}
+	w.Flush()
+	return nil
+}
+
+func runTokenRevoke(cmd *cobra.Command, args []string) error {
+	_, db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.RevokeAPIToken(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Revoked token %q\n", args[0])
+	return nil
+}
+
+// newAuthenticator builds an Authenticator from statically configured tokens
+// and tokens provisioned in the store.
+func newAuthenticator(cfg *config.Config, db *store.DB) (*auth.Authenticator, error) {
+	static := make(map[string]*auth.Token, len(cfg.Server.Tokens))
+	for _, tc := range cfg.Server.Tokens {
+		scopes, err := auth.ParseScopes(tc.Scopes)
+		if err != nil {
+			return nil, fmt.Errorf("server token %s: %w", tc.Name, err)
+		}
+		hash := tc.TokenHash
+		if tc.Token != "" {
+			hash = auth.HashToken(tc.Token)
+		}
+		static[strings.ToLower(hash)] = &auth.Token{Name: tc.Name, Scopes: scopes}
+	}
+
+	var lookup auth.LookupFunc
+	if db != nil {
+		lookup = func(hash string) (*auth.Token, error) {
+			t, err := db.GetAPITokenByHash(hash)
+			if err != nil {
+				if errors.Is(err, store.ErrNotFound) {
+					return nil, nil
+				}
+				return nil, err
+			}
+			scopes, err := auth.ParseScopes(t.Scopes)
+			if err != nil {
+				return nil, err
+			}
+			_ = db.TouchAPIToken(t.ID)
+			return &auth.Token{Name: t.Name, Scopes: scopes}, nil
+		}
+	}
+
+	return auth.NewAuthenticator(static, lookup), nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jacklau/triage/internal/auth"
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestTokenCmdRegistered(t *testing.T) {
+	var found bool
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "token" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("token command not registered")
+	}
+	for _, name := range []string{"create", "list", "revoke"} {
+		if c, _, err := tokenCmd.Find([]string{name}); err != nil || c.Name() != name {
+			t.Errorf("token %s subcommand not registered", name)
+		}
+	}
+	if tokenCreateCmd.Flags().Lookup("scope") == nil {
+		t.Error("expected --scope flag on token create")
+	}
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.CreateAPIToken("stored", auth.HashToken("stored-secret"), []string{"triage"}); err != nil {
+		t.Fatalf("creating token: %v", err)
+	}
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Tokens: []config.TokenConfig{
+				{Name: "plain", Token: "plain-secret", Scopes: []string{"read"}},
+				{Name: "hashed", TokenHash: auth.HashToken("hashed-secret"), Scopes: []string{"admin"}},
+			},
+		},
+	}
+
+	a, err := newAuthenticator(cfg, db)
+	if err != nil {
+		t.Fatalf("newAuthenticator: %v", err)
+	}
+
+	tests := []struct {
+		token    string
+		wantName string
+		scope    auth.Scope
+	}{
+		{"plain-secret", "plain", auth.ScopeRead},
+		{"hashed-secret", "hashed", auth.ScopeAdmin},
+		{"stored-secret", "stored", auth.ScopeTriage},
+	}
+	for _, tt := range tests {
+		tok, err := a.Authenticate(tt.token)
+		if err != nil {
+			t.Fatalf("Authenticate(%q): %v", tt.token, err)
+		}
+		if tok == nil || tok.Name != tt.wantName || !tok.Allows(tt.scope) {
+			t.Errorf("Authenticate(%q) = %+v, want %s with %s", tt.token, tok, tt.wantName, tt.scope)
+		}
+	}
+
+	tok, err := a.Authenticate("unknown")
+	if err != nil || tok != nil {
+		t.Errorf("unknown token: got %+v, %v", tok, err)
+	}
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/store"
+	"github.com/jacklau/triage/internal/topics"
+)
+
+var (
+	topicsClusters int
+	topicsTop      int
+	topicsSince    string
+	topicsNoNames  bool
+	topicsOutput   string
+)
+
+// topicsSeed fixes the clustering so repeated runs over the same issues
+// give the same topics.
+const topicsSeed = 1
+
+// topicsExamples is how many of a topic's most central issues are listed.
+const topicsExamples = 3
+
+var topicsCmd = &cobra.Command{
+	Use:   "topics <owner/repo>",
+	Short: "Report the recurring problem areas among a repo's issues",
+	Long: `Topics clusters the stored embeddings of a repo's issues into groups of
+similar issues, asks the LLM to name each group, and lists the largest groups
+with their most representative issues: the recurring problem areas of the repo.
+
+Issues are read from the local database, so run scan first. Issues without an
+embedding are left out. The number of clusters defaults to about the square
+root of half the number of issues; set it with --clusters. Use --no-names, or
+leave llm unconfigured, to skip naming.`,
+	Example:           `  triage topics octocat/hello-world --since 90d --top 5`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepos(1),
+	RunE:              runTopics,
+}
+
+func init() {
+	topicsCmd.Flags().IntVar(&topicsClusters, "clusters", 0, "number of clusters (0 picks one from the number of issues)")
+	topicsCmd.Flags().IntVar(&topicsTop, "top", 10, "number of topics to show")
+	topicsCmd.Flags().StringVar(&topicsSince, "since", "", "only cluster issues created within this duration (e.g. 30d)")
+	topicsCmd.Flags().BoolVar(&topicsNoNames, "no-names", false, "do not ask the LLM to name topics")
+	topicsCmd.Flags().StringVar(&topicsOutput, "output", "text", "output format: text or json")
+	registerFlagValues(topicsCmd, "output", outputFormats)
+	rootCmd.AddCommand(topicsCmd)
+}
+
+// topicIssue is an issue listed under a topic.
+type topicIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+// topicRow is a topic as the topics command reports it.
+type topicRow struct {
+	Name  string  `json:"name,omitempty"`
+	Size  int     `json:"size"`
+	Share float64 `json:"share"`
+	// Issues are the topic's most central issues.
+	Issues []topicIssue `json:"issues"`
+}
+
+func runTopics(cmd *cobra.Command, args []string) error {
+	owner, repo, err := parseRepoArg(args[0])
+	if err != nil {
+		return err
+	}
+	if topicsClusters < 0 {
+		return fmt.Errorf("--clusters must not be negative")
+	}
+	if topicsTop <= 0 {
+		return fmt.Errorf("--top must be positive")
+	}
+	var since time.Time
+	if topicsSince != "" {
+		window, err := parseSinceDuration(topicsSince)
+		if err != nil {
+			return err
+		}
+		if window <= 0 {
+			return fmt.Errorf("--since must be positive")
+		}
+		since = time.Now().Add(-window)
+	}
+	if topicsOutput != "text" && topicsOutput != "json" {
+		return fmt.Errorf("invalid --output %q: expected text or json", topicsOutput)
+	}
+
+	logger := setupLogger()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	c, err := initComponents(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	repoRecord, err := c.Store.GetRepoByOwnerRepo(owner, repo)
+	if errors.Is(err, store.ErrRepoNotFound) {
+		return fmt.Errorf("%s is not tracked; run scan first", args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("looking up repo: %w", err)
+	}
+
+	embedded := true
+	stored, err := c.Store.ListIssues(repoRecord.ID, store.IssueQuery{HasEmbedding: &embedded, CreatedSince: since})
+	if err != nil {
+		return err
+	}
+	issues := make([]topics.Issue, len(stored))
+	for i, is := range stored {
+		issues[i] = topics.Issue{Number: is.Number, Title: is.Title, Embedding: dedup.DecodeEmbedding(is.Embedding)}
+	}
+
+	k := topicsClusters
+	if k == 0 {
+		k = topics.DefaultK(len(issues))
+	}
+	found := topics.Cluster(issues, k, topicsSeed)
+	if len(found) > topicsTop {
+		found = found[:topicsTop]
+	}
+
+	if !topicsNoNames && len(found) > 0 {
+		if c.Completer == nil {
+			fmt.Fprintln(cmd.ErrOrStderr(), "No llm configured; topics are not named.")
+		} else if err := topics.Name(context.Background(), c.Completer, args[0], found); err != nil {
+			logger.Warn("some topics could not be named", "error", err)
+		}
+	}
+
+	rows := topicRows(found, len(issues))
+	if topicsOutput == "json" {
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+	writeTopics(cmd.OutOrStdout(), args[0], rows, len(issues))
+	return nil
+}
+
+// topicRows returns the report rows of found, out of total clustered issues.
+func topicRows(found []topics.Topic, total int) []topicRow {
+	rows := make([]topicRow, 0, len(found))
+	for _, t := range found {
+		row := topicRow{Name: t.Name, Size: len(t.Issues), Issues: []topicIssue{}}
+		if total > 0 {
+			row.Share = float64(len(t.Issues)) / float64(total)
+		}
+		for _, is := range t.Issues[:min(len(t.Issues), topicsExamples)] {
+			row.Issues = append(row.Issues, topicIssue{Number: is.Number, Title: is.Title})
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// writeTopics prints rows largest first, each with its example issues.
+func writeTopics(w io.Writer, repo string, rows []topicRow, total int) {
+	if len(rows) == 0 {
+		fmt.Fprintf(w, "No embedded issues of %s to cluster; run scan first.\n", repo)
+		return
+	}
+	fmt.Fprintf(w, "Top %d topic(s) among %d issue(s) of %s\n", len(rows), total, repo)
+	for i, r := range rows {
+		name := r.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		fmt.Fprintf(w, "\n%d. %s — %d issue(s), %.0f%%\n", i+1, name, r.Size, r.Share*100)
+		for _, is := range r.Issues {
+			fmt.Fprintf(w, "   #%d %s\n", is.Number, is.Title)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/store"
+	"github.com/jacklau/triage/internal/topics"
+)
+
+func TestRunTopics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/api/embeddings":
+			json.NewEncoder(w).Encode(map[string]any{"embedding": []float32{1, 0, 0}})
+		case "/api/generate":
+			name := "Login problems"
+			if strings.Contains(req.Prompt, "- Crash") {
+				name = "\"Crashes\""
+			}
+			json.NewEncoder(w).Encode(map[string]any{"response": name})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, is := range []struct {
+		number int
+		title  string
+		vec    []float32
+	}{
+		{1, "Crash on start", []float32{1, 0.1, 0}},
+		{2, "Crash on exit", []float32{1, 0, 0.1}},
+		{3, "Crash when idle", []float32{1, 0, 0}},
+		{4, "Login fails", []float32{0, 1, 0}},
+		{5, "Not embedded", nil},
+	} {
+		now := time.Now()
+		if err := db.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: is.number, Title: is.title, State: "open", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatal(err)
+		}
+		if is.vec != nil {
+			if err := db.UpdateEmbedding(repo.ID, is.number, dedup.EncodeEmbedding(is.vec), "nomic-embed-text"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	db.Close()
+
+	writeTestConfig(t, fmt.Sprintf(`store:
+  path: %s
+providers:
+  embedding:
+    type: ollama
+    model: nomic-embed-text
+    url: %s
+  llm:
+    type: ollama
+    model: llama3
+    url: %s
+`, path, srv.URL, srv.URL))
+
+	topicsClusters = 2
+	defer func() { topicsClusters = 0 }()
+	var out bytes.Buffer
+	topicsCmd.SetOut(&out)
+	defer topicsCmd.SetOut(nil)
+
+	if err := runTopics(topicsCmd, []string{"org/repo"}); err != nil {
+		t.Fatalf("runTopics: %v", err)
+	}
+	for _, want := range []string{
+		"Top 2 topic(s) among 4 issue(s) of org/repo",
+		"1. Crashes — 3 issue(s), 75%",
+		"   #3 Crash when idle",
+		"2. Login problems — 1 issue(s), 25%",
+		"   #4 Login fails",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Not embedded") {
+		t.Errorf("expected issues without an embedding to be left out:\n%s", out.String())
+	}
+
+	out.Reset()
+	topicsNoNames, topicsTop, topicsOutput = true, 1, "json"
+	defer func() { topicsNoNames, topicsTop, topicsOutput = false, 10, "text" }()
+	if err := runTopics(topicsCmd, []string{"org/repo"}); err != nil {
+		t.Fatalf("runTopics: %v", err)
+	}
+	var rows []topicRow
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(rows) != 1 || rows[0].Name != "" || rows[0].Size != 3 || len(rows[0].Issues) != 3 {
+		t.Errorf("expected one unnamed topic of 3 issues, got %+v", rows)
+	}
+
+	if err := runTopics(topicsCmd, []string{"org/other"}); err == nil || !strings.Contains(err.Error(), "not tracked") {
+		t.Errorf("expected an untracked repo error, got %v", err)
+	}
+}
+
+func TestRunTopicsFlags(t *testing.T) {
+	defer func() { topicsClusters, topicsTop, topicsSince, topicsOutput = 0, 10, "", "text" }()
+	tests := []struct {
+		name  string
+		set   func()
+		error string
+	}{
+		{"negative clusters", func() { topicsClusters = -1 }, "--clusters"},
+		{"zero top", func() { topicsTop = 0 }, "--top"},
+		{"bad since", func() { topicsSince = "soon" }, ""},
+		{"bad output", func() { topicsOutput = "xml" }, "--output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topicsClusters, topicsTop, topicsSince, topicsOutput = 0, 10, "", "text"
+			tt.set()
+			err := runTopics(topicsCmd, []string{"org/repo"})
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.error, err)
+			}
+		})
+	}
+}
+
+func TestWriteTopicsEmpty(t *testing.T) {
+	var out bytes.Buffer
+	writeTopics(&out, "org/repo", topicRows(nil, 0), 0)
+	if !strings.Contains(out.String(), "No embedded issues of org/repo") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	rows := topicRows([]topics.Topic{{Issues: []topics.Issue{{Number: 1, Title: "a"}}}}, 1)
+	out.Reset()
+	writeTopics(&out, "org/repo", rows, 1)
+	if !strings.Contains(out.String(), "1. (unnamed) — 1 issue(s), 100%") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
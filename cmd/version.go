@@ -1,24 +1,93 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/jacklau/triage/internal/buildinfo"
+	"github.com/jacklau/triage/internal/config"
 )
 
-// version is set at build time via ldflags:
-//
-//	go build -ldflags="-X github.com/jacklau/triage/cmd.version=1.0.0"
-var version = "dev"
+var (
+	versionShort  bool
+	versionOutput string
+)
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version of triage",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Fprintln(cmd.OutOrStdout(), "triage", version)
-	},
+	Long: `Print the version of triage with its build metadata: git commit, build
+date, Go version, platform, and the providers compiled in. The same version
+is sent in the User-Agent of GitHub and provider API requests.
+
+The version, commit, and date are set at build time via ldflags; see
+internal/buildinfo.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
 }
 
 func init() {
+	versionCmd.Flags().BoolVar(&versionShort, "short", false, "print only the version number")
+	versionCmd.Flags().StringVar(&versionOutput, "output", "text", "output format: text or json")
+	registerFlagValues(versionCmd, "output", outputFormats)
 	rootCmd.AddCommand(versionCmd)
 }
+
+// versionJSON is the JSON form of the version command's output.
+type versionJSON struct {
+	buildinfo.Info
+	Providers map[string][]string `json:"providers"`
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := buildinfo.Get()
+	w := cmd.OutOrStdout()
+
+	switch {
+	case versionShort:
+		fmt.Fprintln(w, info.Version)
+		return nil
+	case versionOutput == "json":
+		data, err := json.MarshalIndent(versionJSON{
+			Info: info,
+			Providers: map[string][]string{
+				"embedding": config.EmbeddingProviderTypes,
+				"llm":       config.LLMProviderTypes,
+			},
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+	printVersionText(w, info)
+	return nil
+}
+
+func printVersionText(w io.Writer, info buildinfo.Info) {
+	fmt.Fprintln(w, "triage", info.Version)
+
+	commit := info.Commit
+	if commit == "" {
+		commit = "unknown"
+	}
+	if info.Modified {
+		commit += " (modified)"
+	}
+	date := info.Date
+	if date == "" {
+		date = "unknown"
+	}
+
+	fmt.Fprintf(w, "  commit:     %s\n", commit)
+	fmt.Fprintf(w, "  built:      %s\n", date)
+	fmt.Fprintf(w, "  go:         %s\n", info.GoVersion)
+	fmt.Fprintf(w, "  platform:   %s\n", info.Platform)
+	fmt.Fprintf(w, "  embedding:  %s\n", strings.Join(config.EmbeddingProviderTypes, ", "))
+	fmt.Fprintf(w, "  llm:        %s\n", strings.Join(config.LLMProviderTypes, ", "))
+}
@@ -2,28 +2,43 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"runtime"
 	"strings"
 	"testing"
-)
 
-func TestVersionCommand(t *testing.T) {
-	oldVersion := version
-	version = "test-1.2.3"
-	defer func() { version = oldVersion }()
+	"github.com/jacklau/triage/internal/buildinfo"
+)
 
+// runVersionCmd executes "triage version" with args and returns its output.
+func runVersionCmd(t *testing.T, args ...string) string {
+	t.Helper()
 	buf := new(bytes.Buffer)
 	rootCmd.SetOut(buf)
-	rootCmd.SetArgs([]string{"version"})
+	rootCmd.SetArgs(append([]string{"version"}, args...))
 	defer func() {
 		rootCmd.SetOut(nil)
 		rootCmd.SetArgs(nil)
+		versionShort, versionOutput = false, "text"
 	}()
 
 	if err := rootCmd.Execute(); err != nil {
 		t.Fatalf("version command failed: %v", err)
 	}
+	return buf.String()
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func TestVersionCommand(t *testing.T) {
+	oldVersion := buildinfo.Version
+	buildinfo.Version = "test-1.2.3"
+	defer func() { buildinfo.Version = oldVersion }()
 
-	got := strings.TrimSpace(buf.String())
+	got := firstLine(runVersionCmd(t))
 	want := "triage test-1.2.3"
 	if got != want {
 		t.Errorf("version output = %q, want %q", got, want)
@@ -31,23 +46,11 @@ func TestVersionCommand(t *testing.T) {
 }
 
 func TestVersionDefaultIsDev(t *testing.T) {
-	oldVersion := version
-	version = "dev"
-	defer func() { version = oldVersion }()
-
-	buf := new(bytes.Buffer)
-	rootCmd.SetOut(buf)
-	rootCmd.SetArgs([]string{"version"})
-	defer func() {
-		rootCmd.SetOut(nil)
-		rootCmd.SetArgs(nil)
-	}()
+	oldVersion := buildinfo.Version
+	buildinfo.Version = "dev"
+	defer func() { buildinfo.Version = oldVersion }()
 
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("version command failed: %v", err)
-	}
-
-	got := strings.TrimSpace(buf.String())
+	got := firstLine(runVersionCmd(t))
 	want := "triage dev"
 	if got != want {
 		t.Errorf("version output = %q, want %q", got, want)
@@ -69,8 +72,8 @@ func TestVersionCommandRegistered(t *testing.T) {
 
 func TestVersionLdflags(t *testing.T) {
 	// Verify the version variable can be overridden (simulating ldflags)
-	oldVersion := version
-	defer func() { version = oldVersion }()
+	oldVersion := buildinfo.Version
+	defer func() { buildinfo.Version = oldVersion }()
 
 	testCases := []struct {
 		name    string
@@ -84,24 +87,42 @@ func TestVersionLdflags(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			version = tc.ver
-
-			buf := new(bytes.Buffer)
-			rootCmd.SetOut(buf)
-			rootCmd.SetArgs([]string{"version"})
-			defer func() {
-				rootCmd.SetOut(nil)
-				rootCmd.SetArgs(nil)
-			}()
-
-			if err := rootCmd.Execute(); err != nil {
-				t.Fatalf("version command failed: %v", err)
-			}
+			buildinfo.Version = tc.ver
 
-			got := strings.TrimSpace(buf.String())
+			got := firstLine(runVersionCmd(t))
 			if got != tc.wantOut {
 				t.Errorf("got %q, want %q", got, tc.wantOut)
 			}
 		})
 	}
 }
+
+func TestVersionBuildMetadata(t *testing.T) {
+	oldV, oldC, oldD := buildinfo.Version, buildinfo.Commit, buildinfo.Date
+	defer func() { buildinfo.Version, buildinfo.Commit, buildinfo.Date = oldV, oldC, oldD }()
+	buildinfo.Version, buildinfo.Commit, buildinfo.Date = "1.2.3", "abc1234", "2025-01-02T03:04:05Z"
+
+	out := runVersionCmd(t)
+	for _, want := range []string{
+		"commit:     abc1234",
+		"built:      2025-01-02T03:04:05Z",
+		"go:         " + runtime.Version(),
+		"llm:        openai, anthropic, ollama",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("version output missing %q:\n%s", want, out)
+		}
+	}
+
+	if got := strings.TrimSpace(runVersionCmd(t, "--short")); got != "1.2.3" {
+		t.Errorf("--short output = %q, want 1.2.3", got)
+	}
+
+	var parsed versionJSON
+	if err := json.Unmarshal([]byte(runVersionCmd(t, "--output", "json")), &parsed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if parsed.Version != "1.2.3" || parsed.Commit != "abc1234" || len(parsed.Providers["embedding"]) != 2 {
+		t.Errorf("unexpected JSON output: %+v", parsed)
+	}
+}
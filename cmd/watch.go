@@ -13,14 +13,28 @@ import (
 
 	"github.com/jacklau/triage/internal/config"
 	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/leader"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/pipeline"
+	"github.com/jacklau/triage/internal/secrets"
 )
 
 var (
-	watchInterval string
-	watchNotify   string
-	watchDryRun   bool
+	watchInterval    string
+	watchNotify      string
+	watchLeaderElect bool
+	watchInstanceID  string
+	watchReportEvery string
+	watchSweepEvery  string
+	watchSweepWindow string
+	watchStatusEvery string
+	watchStatusPort  int
+	watchNotifyTest  bool
 )
 
+// watchLeaseName is the lease contended for by watch instances sharing a store.
+const watchLeaseName = "watch"
+
 var watchCmd = &cobra.Command{
 	Use:   "watch [owner/repo ...]",
 	Short: "Continuously poll and triage issues",
@@ -31,15 +45,46 @@ results to configured notification channels.
 Multiple repos can be specified as arguments:
   triage watch org/repo1 org/repo2
 
-If no arguments are provided, all repos defined in the config file
-will be watched.`,
-	RunE: runWatch,
+If no arguments are provided, all repos defined in the config file or
+added with "triage repos add" will be watched.
+
+Use --leader-elect to run several redundant instances against the same
+store: only the instance holding the lease polls and notifies, and a
+standby takes over within the lease TTL if the leader exits.
+
+Use --report-every (e.g. 7d) to post a triage report for the watched repos
+to the notification channels on that interval; see "triage report".
+
+Use --sweep-every (e.g. 1d) to re-check issues filed within --sweep-window
+for duplicates missed at filing time; see "triage sweep".
+
+Every --status-every (default 1m, 0 to turn off) watch prints a status line
+to stderr: when repos were last polled, how many issues were processed, how
+many events are queued, and GitHub's remaining rate limit. Use --status-port
+to also serve it as JSON at http://localhost:<port>/status.
+
+Watch checks the notification webhook URLs before it starts, failing if one
+is malformed or its host does not resolve. Use --notify-test to also send a
+test message, so a revoked webhook or bot token stops watch right away
+rather than at the first triage.`,
+	ValidArgsFunction: completeRepos(0),
+	RunE:              runWatch,
 }
 
 func init() {
 	watchCmd.Flags().StringVar(&watchInterval, "interval", "5m", "poll interval (e.g. 5m, 30s)")
 	watchCmd.Flags().StringVar(&watchNotify, "notify", "", "notification target: slack, discord, or both")
-	watchCmd.Flags().BoolVar(&watchDryRun, "dry-run", false, "process issues but skip notifications")
+	watchCmd.Flags().BoolVar(&renotify, "renotify", false, "notify results even if an identical one was already sent")
+	watchCmd.Flags().BoolVar(&noCache, "no-cache", false, "classify every issue with the LLM, ignoring cached results")
+	watchCmd.Flags().BoolVar(&watchLeaderElect, "leader-elect", false, "only poll while holding the store lease (for redundant instances)")
+	watchCmd.Flags().StringVar(&watchInstanceID, "instance-id", "", "identity used for leader election (default hostname-pid)")
+	watchCmd.Flags().StringVar(&watchReportEvery, "report-every", "", "post a triage report on this interval (e.g. 7d)")
+	watchCmd.Flags().StringVar(&watchSweepEvery, "sweep-every", "", "re-check recent issues for late duplicates on this interval (e.g. 1d)")
+	watchCmd.Flags().StringVar(&watchSweepWindow, "sweep-window", "7d", "how far back --sweep-every looks for issues")
+	watchCmd.Flags().StringVar(&watchStatusEvery, "status-every", "1m", "print a status line on this interval (0 to disable)")
+	watchCmd.Flags().IntVar(&watchStatusPort, "status-port", 0, "serve status as JSON on this localhost port (0 to disable)")
+	watchCmd.Flags().BoolVar(&watchNotifyTest, "notify-test", false, "send a test message at startup and exit if it fails")
+	registerFlagValues(watchCmd, "notify", notifyTargets)
 	rootCmd.AddCommand(watchCmd)
 }
 
@@ -66,12 +111,49 @@ func resolveWatchRepos(args []string, cfgRepos []string) ([]string, error) {
 
 	// No args: use repos from config
 	if len(cfgRepos) == 0 {
-		return nil, fmt.Errorf("no repos specified and none configured; provide repos as arguments or add them to the config file")
+		return nil, fmt.Errorf("no repos specified and none configured; provide repos as arguments, add them to the config file, or use \"triage repos add\"")
 	}
 
 	return cfgRepos, nil
 }
 
+// expandRepoPatterns replaces glob patterns such as "myorg/*" in names with
+// the matching repos of the pattern's owner, as returned by list, leaving out
+// repos that match an exclude pattern. Exact names are kept as given and
+// duplicates are dropped.
+func expandRepoPatterns(ctx context.Context, names, exclude []string, list func(ctx context.Context, owner string) ([]string, error)) ([]string, error) {
+	var expanded []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			expanded = append(expanded, name)
+		}
+	}
+
+	owners := make(map[string][]string)
+	for _, name := range names {
+		if !config.IsRepoPattern(name) {
+			add(name)
+			continue
+		}
+		owner, _, _ := strings.Cut(name, "/")
+		if _, ok := owners[owner]; !ok {
+			repos, err := list(ctx, owner)
+			if err != nil {
+				return nil, fmt.Errorf("expanding %s: %w", name, err)
+			}
+			owners[owner] = repos
+		}
+		for _, repo := range owners[owner] {
+			if config.MatchRepo(name, repo) && !config.Excluded(exclude, repo) {
+				add(repo)
+			}
+		}
+	}
+	return expanded, nil
+}
+
 func runWatch(cmd *cobra.Command, args []string) error {
 	logger := setupLogger()
 
@@ -80,7 +162,13 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	// Collect repo names from config
+	c, err := initComponents(cfg, logger, withNoCache(noCache))
+	if err != nil {
+		return fmt.Errorf("initializing components: %w", err)
+	}
+	defer c.Store.Close()
+
+	// Collect repo names from config (including repos managed with "triage repos add")
 	var cfgRepoNames []string
 	for _, rc := range cfg.Repos {
 		if rc.Name != "" {
@@ -88,16 +176,34 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Configured event sources can stand in for polled repos
 	repos, err := resolveWatchRepos(args, cfgRepoNames)
+	if err != nil && (len(args) > 0 || len(cfg.Sources) == 0) {
+		return err
+	}
+	repos, err = expandRepoPatterns(context.Background(), repos, cfg.Exclude, func(ctx context.Context, owner string) ([]string, error) {
+		if c.GHClient == nil {
+			return nil, notConfigured("GitHub client", "set github.auth in config")
+		}
+		return github.ListOwnerRepos(ctx, c.GHClient, owner)
+	})
 	if err != nil {
 		return err
 	}
+	if len(repos) == 0 && len(cfg.Sources) == 0 {
+		return fmt.Errorf("no repos match the configured patterns")
+	}
 
-	c, err := initComponents(cfg, logger)
-	if err != nil {
-		return fmt.Errorf("initializing components: %w", err)
+	// Refuse to compare new embeddings with stored ones of another
+	// dimension
+	for _, repoArg := range repos {
+		owner, repo, _ := parseRepoArg(repoArg)
+		if r, err := c.Store.GetRepoByOwnerRepo(owner, repo); err == nil {
+			if err := c.checkEmbeddingDims(context.Background(), r.ID, repoArg); err != nil {
+				return err
+			}
+		}
 	}
-	defer c.Store.Close()
 
 	// Parse interval
 	interval, err := time.ParseDuration(watchInterval)
@@ -105,28 +211,84 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid interval %q: %w", watchInterval, err)
 	}
 
+	reportEvery, err := parseSinceDuration(watchReportEvery)
+	if err != nil {
+		return fmt.Errorf("invalid --report-every: %w", err)
+	}
+	sweepEvery, err := parseSinceDuration(watchSweepEvery)
+	if err != nil {
+		return fmt.Errorf("invalid --sweep-every: %w", err)
+	}
+	sweepWindow, err := parseSinceDuration(watchSweepWindow)
+	if err != nil {
+		return fmt.Errorf("invalid --sweep-window: %w", err)
+	}
+	statusEvery, err := time.ParseDuration(watchStatusEvery)
+	if err != nil || statusEvery < 0 {
+		return fmt.Errorf("invalid --status-every %q: expected a duration such as 1m, or 0", watchStatusEvery)
+	}
+	if watchStatusPort < 0 || watchStatusPort > 65535 {
+		return fmt.Errorf("invalid --status-port %d", watchStatusPort)
+	}
+
 	// Create notifier
-	n, err := createNotifier(cfg, watchNotify)
+	n, err := createNotifier(cfg, watchNotify, c.Store)
 	if err != nil {
 		return fmt.Errorf("creating notifier: %w", err)
 	}
+	if reportEvery > 0 && n == nil {
+		return fmt.Errorf("--report-every requires a notification target (set notify.slack_webhook or notify.discord_webhook)")
+	}
+	if watchNotifyTest && n == nil {
+		return fmt.Errorf("--notify-test requires a notification target (set notify.slack_webhook or notify.discord_webhook)")
+	}
+	if n != nil {
+		if err := checkNotifyTargets(cmd.Context(), cfg, n, watchNotifyTest && !dryRun); err != nil {
+			return err
+		}
+	}
 
-	if watchDryRun {
-		n = nil
-		logger.Info("dry-run mode enabled, notifications disabled")
+	if dryRun {
+		logger.Info("dry-run mode enabled, notifications and triage log writes disabled")
 	}
 
 	// Merge labels from all watched repos for the pipeline
 	labels := mergeRepoLabels(cfg, repos)
 
+	// Triage results also go to Jira, if configured; reports do not
+	pn := n
+	jiraSync := newJiraSyncer(c)
+	if jiraSync != nil {
+		pn = jiraSync
+		if n != nil {
+			pn = notify.NewMultiNotifier(n, jiraSync)
+		}
+	}
+
 	// Build pipeline (one pipeline, shared across all pollers via the broker)
-	p := createPipeline(c, n, labels)
+	p := createPipeline(c, pn, labels, nil)
+	status := newWatchStatus(p.Stats, c.Broker.Pending)
 
-	// Create pollers for each repo
-	var pollers []*github.Poller
+	// Create pollers for each repo, and any configured sources
+	var sources []github.EventSource
 	for _, repoArg := range repos {
 		owner, repo, _ := parseRepoArg(repoArg) // already validated
-		pollers = append(pollers, createPoller(c, owner, repo))
+		poller := createPoller(c, owner, repo)
+		poller.SetDisabledHandler(repoDisabledHandler(c, n))
+		poller.SetPollHandler(status.recordPoll)
+		sources = append(sources, poller.Source(interval))
+	}
+	for i, sc := range cfg.Sources {
+		src, err := github.NewSource(sc, c.Broker)
+		if err != nil {
+			return fmt.Errorf("sources[%d]: %w", i, err)
+		}
+		logger.Info("starting event source", "type", sc.Type)
+		sources = append(sources, src)
+	}
+
+	if cfg.Defaults.EmbeddingCache {
+		preloadEmbeddings(c, repos)
 	}
 
 	// Graceful shutdown
@@ -141,43 +303,159 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	// Pick up rotated secrets on every instance, so a standby that takes
+	// over has current keys.
+	if refresh, _ := cfg.Secrets.Refresh(); refresh > 0 && len(cfg.SecretRefs) > 0 {
+		go refreshSecrets(ctx, c, secrets.NewResolver(), refresh)
+	}
+
 	for _, repoArg := range repos {
 		logger.Info("starting watch", "repo", repoArg, "interval", interval.String())
 	}
 
+	if statusEvery > 0 {
+		go runStatusLoop(ctx, cmd.ErrOrStderr(), status, statusEvery)
+	}
+	if watchStatusPort > 0 {
+		if err := startStatusServer(ctx, watchStatusPort, status, logger); err != nil {
+			return err
+		}
+	}
+
+	// loop runs everything that should only happen on one instance at a time.
+	loop := func(ctx context.Context) error {
+		if reportEvery > 0 {
+			go runReportLoop(ctx, c, n, repos, reportEvery)
+		}
+		if sweepEvery > 0 {
+			go runSweepLoop(ctx, p, c, repos, sweepEvery, sweepWindow)
+		}
+		if jiraSync != nil && !dryRun {
+			go jiraSync.Run(ctx, c.Broker)
+		}
+		return runWatchLoop(ctx, p, sources)
+	}
+
+	if watchLeaderElect {
+		id := watchInstanceID
+		if id == "" {
+			id = leader.DefaultID()
+		}
+		logger.Info("leader election enabled", "instance", id)
+		elector := leader.NewElector(c.Store, watchLeaseName, id, leader.WithLogger(logger))
+		err := elector.Run(ctx, loop)
+		if err != nil && err != context.Canceled {
+			return err
+		}
+	} else if err := loop(ctx); err != nil {
+		return err
+	}
+
+	logger.Info("watch stopped")
+	return nil
+}
+
+// repoDisabledHandler returns the poller callback run when GitHub no longer
+// finds a watched repo. It logs the change and tells the operator through
+// n, if set.
+func repoDisabledHandler(c *components, n notify.Notifier) func(ctx context.Context, repo, reason string) {
+	return func(ctx context.Context, repo, reason string) {
+		c.Logger.Warn("watched repo unavailable, no longer polling it", "repo", repo, "reason", reason)
+		if n == nil || dryRun {
+			return
+		}
+		text := fmt.Sprintf("GitHub returned %s for %s. It may have been renamed, made private, or deleted, "+
+			"or triage lost access to it. Once it is reachable again, run `triage repos enable %s`.", reason, repo, repo)
+		if err := notify.SendMessage(ctx, n, "Stopped watching "+repo, text); err != nil {
+			c.Logger.Warn("failed to notify about unavailable repo", "repo", repo, "error", err)
+		}
+	}
+}
+
+// preloadEmbeddings loads the stored embeddings of each tracked repo into the
+// dedup engine's cache, so the first checks don't pay for decoding them.
+// Failures are logged; the cache then loads lazily.
+func preloadEmbeddings(c *components, repos []string) {
+	if c.Dedup == nil {
+		return
+	}
+	start := time.Now()
+	var ids []int64
+	for _, repoArg := range repos {
+		owner, repo, _ := parseRepoArg(repoArg)
+		if r, err := c.Store.GetRepoByOwnerRepo(owner, repo); err == nil {
+			ids = append(ids, r.ID)
+		}
+	}
+	if err := c.Dedup.Preload(ids...); err != nil {
+		c.Logger.Warn("preloading embeddings failed", "error", err)
+		return
+	}
+	c.Logger.Info("preloaded embeddings", "repos", len(ids), "duration", time.Since(start))
+}
+
+// runWatchLoop runs the pipeline and all event sources until ctx is
+// cancelled or one of them fails.
+func runWatchLoop(ctx context.Context, p *pipeline.Pipeline, sources []github.EventSource) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Start pipeline in background
 	pipelineErr := make(chan error, 1)
 	go func() {
 		pipelineErr <- p.Run(ctx)
 	}()
 
-	// Start all pollers in background
-	pollerErr := make(chan error, len(pollers))
-	for _, poller := range pollers {
-		poller := poller // capture loop variable
+	// Start all sources in background
+	sourceErr := make(chan error, len(sources))
+	for _, src := range sources {
 		go func() {
-			pollerErr <- poller.Run(ctx, interval)
+			sourceErr <- src.Run(ctx)
 		}()
 	}
 
-	// Wait for pipeline or any poller to finish
+	// Wait for pipeline or any source to finish
 	select {
 	case err := <-pipelineErr:
 		cancel()
 		if err != nil && err != context.Canceled {
 			return fmt.Errorf("pipeline error: %w", err)
 		}
-	case err := <-pollerErr:
+	case err := <-sourceErr:
 		cancel()
 		if err != nil && err != context.Canceled {
-			return fmt.Errorf("poller error: %w", err)
+			return fmt.Errorf("event source error: %w", err)
 		}
 	}
-
-	logger.Info("watch stopped")
 	return nil
 }
 
+// runReportLoop posts a triage report for repos every interval, each
+// covering the preceding interval, until ctx is cancelled.
+func runReportLoop(ctx context.Context, c *components, n notify.Notifier, repos []string, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			reports, err := buildReports(c.Store, repos, every, now)
+			if err != nil {
+				c.Logger.Warn("failed to build triage report", "error", err)
+				continue
+			}
+			if dryRun {
+				c.Logger.Info("dry run: skipping triage report", "repos", len(reports))
+				continue
+			}
+			if err := postReports(ctx, n, reports, c.Logger); err != nil {
+				c.Logger.Warn("failed to post triage report", "error", err)
+			}
+		}
+	}
+}
+
 // mergeRepoLabels collects labels from all specified repos, deduplicating by name.
 func mergeRepoLabels(cfg *config.Config, repos []string) []config.LabelConfig {
 	seen := make(map[string]bool)
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/pipeline"
+)
+
+// watchStatus tracks what watch has been doing, for its periodic status
+// line and --status-port endpoint.
+type watchStatus struct {
+	started time.Time
+	// stats and queued report the pipeline's counts and the events
+	// waiting for it.
+	stats  func() pipeline.Stats
+	queued func() int
+
+	mu    sync.Mutex
+	polls map[string]github.PollResult
+}
+
+func newWatchStatus(stats func() pipeline.Stats, queued func() int) *watchStatus {
+	return &watchStatus{
+		started: time.Now(),
+		stats:   stats,
+		queued:  queued,
+		polls:   make(map[string]github.PollResult),
+	}
+}
+
+// recordPoll keeps the latest poll of each repo; it is a poller's poll
+// handler.
+func (s *watchStatus) recordPoll(r github.PollResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.polls[r.Repo] = r
+}
+
+// watchSnapshot is the status of watch at one time, as the status endpoint
+// returns it.
+type watchSnapshot struct {
+	Started  time.Time  `json:"started"`
+	LastPoll *time.Time `json:"last_poll,omitempty"`
+	// FailingRepos are the repos whose last poll failed.
+	FailingRepos []string `json:"failing_repos,omitempty"`
+	Processed    int64    `json:"processed"`
+	Skipped      int64    `json:"skipped"`
+	Failed       int64    `json:"failed"`
+	Queued       int      `json:"queued"`
+	// RateLimit is GitHub's rate limit as of the latest poll, if known.
+	RateLimit *rateLimitJSON `json:"rate_limit,omitempty"`
+}
+
+type rateLimitJSON struct {
+	Remaining int       `json:"remaining"`
+	Limit     int       `json:"limit"`
+	Reset     time.Time `json:"reset"`
+}
+
+func (s *watchStatus) snapshot() watchSnapshot {
+	stats := s.stats()
+	snap := watchSnapshot{
+		Started:   s.started,
+		Processed: stats.Processed,
+		Skipped:   stats.Skipped,
+		Failed:    stats.Failed,
+		Queued:    s.queued(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var last, rated github.PollResult
+	for repo, r := range s.polls {
+		if r.At.After(last.At) {
+			last = r
+		}
+		if r.Rate.Limit > 0 && r.At.After(rated.At) {
+			rated = r
+		}
+		if r.Err != nil {
+			snap.FailingRepos = append(snap.FailingRepos, repo)
+		}
+	}
+	sort.Strings(snap.FailingRepos)
+	if !last.At.IsZero() {
+		snap.LastPoll = &last.At
+	}
+	if rated.Rate.Limit > 0 {
+		snap.RateLimit = &rateLimitJSON{Remaining: rated.Rate.Remaining, Limit: rated.Rate.Limit, Reset: rated.Rate.Reset.Time}
+	}
+	return snap
+}
+
+// line formats snap as a one-line summary, with times relative to now.
+func (snap watchSnapshot) line(now time.Time) string {
+	var b strings.Builder
+	b.WriteString("status: last poll ")
+	if snap.LastPoll == nil {
+		b.WriteString("pending")
+	} else {
+		fmt.Fprintf(&b, "%s ago", now.Sub(*snap.LastPoll).Round(time.Second))
+	}
+	if n := len(snap.FailingRepos); n > 0 {
+		fmt.Fprintf(&b, " (%d repo(s) failing: %s)", n, strings.Join(snap.FailingRepos, ", "))
+	}
+	fmt.Fprintf(&b, ", %d processed", snap.Processed)
+	if snap.Skipped > 0 || snap.Failed > 0 {
+		fmt.Fprintf(&b, " (%d skipped, %d failed)", snap.Skipped, snap.Failed)
+	}
+	fmt.Fprintf(&b, ", %d queued", snap.Queued)
+	if snap.RateLimit != nil {
+		fmt.Fprintf(&b, ", rate limit %d/%d", snap.RateLimit.Remaining, snap.RateLimit.Limit)
+	}
+	return b.String()
+}
+
+// ServeHTTP answers GET /status with the current snapshot as JSON.
+func (s *watchStatus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.snapshot())
+}
+
+// runStatusLoop writes a status line to w every interval until ctx is
+// cancelled.
+func runStatusLoop(ctx context.Context, w io.Writer, s *watchStatus, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			fmt.Fprintln(w, s.snapshot().line(now))
+		}
+	}
+}
+
+// startStatusServer serves s at /status on localhost:port until ctx is
+// cancelled. It fails at once if the port cannot be listened on.
+func startStatusServer(ctx context.Context, port int, s *watchStatus, logger *slog.Logger) error {
+	ln, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return fmt.Errorf("listening on --status-port: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/status", s)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	go srv.Serve(ln)
+	logger.Info("serving watch status", "url", "http://"+ln.Addr().String()+"/status")
+	return nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v60/github"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/pipeline"
+)
+
+// syncBuffer is a bytes.Buffer safe for one writer and one reader.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestWatchStatus() *watchStatus {
+	return newWatchStatus(
+		func() pipeline.Stats { return pipeline.Stats{Processed: 42, Skipped: 3, Failed: 1} },
+		func() int { return 2 },
+	)
+}
+
+func TestWatchStatusLine(t *testing.T) {
+	now := time.Now()
+	s := newWatchStatus(func() pipeline.Stats { return pipeline.Stats{} }, func() int { return 0 })
+	if got := s.snapshot().line(now); got != "status: last poll pending, 0 processed, 0 queued" {
+		t.Errorf("line before any poll = %q", got)
+	}
+
+	s = newTestWatchStatus()
+	rate := gogithub.Rate{Limit: 5000, Remaining: 4321}
+	s.recordPoll(github.PollResult{Repo: "org/a", At: now.Add(-30 * time.Second), Rate: rate})
+	s.recordPoll(github.PollResult{Repo: "org/b", At: now.Add(-12 * time.Second), Err: errors.New("502")})
+	want := "status: last poll 12s ago (1 repo(s) failing: org/b), 42 processed (3 skipped, 1 failed), 2 queued, rate limit 4321/5000"
+	if got := s.snapshot().line(now); got != want {
+		t.Errorf("line = %q\nwant   %q", got, want)
+	}
+
+	// A later poll of the same repo replaces the earlier one
+	s.recordPoll(github.PollResult{Repo: "org/b", At: now, Rate: gogithub.Rate{Limit: 5000, Remaining: 4300}})
+	if got := s.snapshot().line(now); !strings.HasPrefix(got, "status: last poll 0s ago, 42") || !strings.HasSuffix(got, "rate limit 4300/5000") {
+		t.Errorf("line = %q", got)
+	}
+}
+
+func TestWatchStatusHTTP(t *testing.T) {
+	s := newTestWatchStatus()
+	s.recordPoll(github.PollResult{Repo: "org/a", At: time.Now(), Rate: gogithub.Rate{Limit: 5000, Remaining: 4321}})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status = %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var got watchSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, rec.Body)
+	}
+	if got.Processed != 42 || got.Queued != 2 || got.LastPoll == nil || got.RateLimit == nil || got.RateLimit.Remaining != 4321 {
+		t.Errorf("unexpected snapshot %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/status", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
+
+func TestStartStatusServer(t *testing.T) {
+	// Find a free port
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := startStatusServer(ctx, port, newTestWatchStatus(), logger); err != nil {
+		t.Fatalf("startStatusServer: %v", err)
+	}
+	resp, err := http.Get("http://" + ln.Addr().String() + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+
+	// The port is now taken
+	if err := startStatusServer(ctx, port, newTestWatchStatus(), logger); err == nil || !strings.Contains(err.Error(), "--status-port") {
+		t.Errorf("expected a listen error, got %v", err)
+	}
+}
+
+func TestRunStatusLoop(t *testing.T) {
+	var out syncBuffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runStatusLoop(ctx, &out, newTestWatchStatus(), 5*time.Millisecond)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "42 processed") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if !strings.HasPrefix(out.String(), "status: last poll pending, 42 processed") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
@@ -1,6 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -123,8 +129,28 @@ func TestWatchCmdHasFlags(t *testing.T) {
 			defValue: "",
 		},
 		{
-			name:     "dry-run flag",
-			flag:     "dry-run",
+			name:     "leader-elect flag",
+			flag:     "leader-elect",
+			defValue: "false",
+		},
+		{
+			name:     "instance-id flag",
+			flag:     "instance-id",
+			defValue: "",
+		},
+		{
+			name:     "status-every flag",
+			flag:     "status-every",
+			defValue: "1m",
+		},
+		{
+			name:     "status-port flag",
+			flag:     "status-port",
+			defValue: "0",
+		},
+		{
+			name:     "notify-test flag",
+			flag:     "notify-test",
 			defValue: "false",
 		},
 	}
@@ -142,6 +168,19 @@ func TestWatchCmdHasFlags(t *testing.T) {
 	}
 }
 
+func TestWatchCmdInheritsGlobalDryRun(t *testing.T) {
+	if watchCmd.LocalNonPersistentFlags().Lookup("dry-run") != nil {
+		t.Error("watch should use the global --dry-run rather than its own flag")
+	}
+	flag := watchCmd.InheritedFlags().Lookup("dry-run")
+	if flag == nil {
+		t.Fatal("expected watch to inherit the global --dry-run flag")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("--dry-run default: expected false, got %q", flag.DefValue)
+	}
+}
+
 func TestWatchCmdDefaultInterval(t *testing.T) {
 	flag := watchCmd.Flags().Lookup("interval")
 	if flag == nil {
@@ -269,3 +308,65 @@ func TestMergeRepoLabels_DefaultsWhenNoConfig(t *testing.T) {
 		t.Error("expected default labels, got none")
 	}
 }
+
+func TestExpandRepoPatterns(t *testing.T) {
+	var listed []string
+	list := func(_ context.Context, owner string) ([]string, error) {
+		listed = append(listed, owner)
+		return []string{owner + "/api", owner + "/web", owner + "/infra-dns"}, nil
+	}
+
+	got, err := expandRepoPatterns(context.Background(),
+		[]string{"other/tool", "myorg/*", "myorg/api", "myorg/w*"},
+		[]string{"myorg/infra-*"}, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"other/tool", "myorg/api", "myorg/web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandRepoPatterns() = %v, want %v", got, want)
+	}
+	if len(listed) != 1 {
+		t.Errorf("expected the owner to be listed once, got %v", listed)
+	}
+}
+
+func TestExpandRepoPatternsListError(t *testing.T) {
+	list := func(context.Context, string) ([]string, error) { return nil, errors.New("forbidden") }
+	if _, err := expandRepoPatterns(context.Background(), []string{"myorg/*"}, nil, list); err == nil || !strings.Contains(err.Error(), "myorg/*") {
+		t.Errorf("expected expansion error naming the pattern, got %v", err)
+	}
+}
+
+// messageRecorder is a notifier that records the messages it is sent.
+type messageRecorder struct {
+	noopNotifier
+	titles, texts []string
+}
+
+func (m *messageRecorder) NotifyMessage(_ context.Context, title, markdown string) error {
+	m.titles = append(m.titles, title)
+	m.texts = append(m.texts, markdown)
+	return nil
+}
+
+func TestRepoDisabledHandler(t *testing.T) {
+	c := &components{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	n := &messageRecorder{}
+
+	repoDisabledHandler(c, n)(context.Background(), "org/gone", "404 Not Found")
+	if len(n.titles) != 1 || n.titles[0] != "Stopped watching org/gone" {
+		t.Fatalf("expected one message, got %v", n.titles)
+	}
+	if !strings.Contains(n.texts[0], "404 Not Found") || !strings.Contains(n.texts[0], "triage repos enable org/gone") {
+		t.Errorf("expected the reason and how to re-enable, got %q", n.texts[0])
+	}
+
+	dryRun = true
+	defer func() { dryRun = false }()
+	repoDisabledHandler(c, n)(context.Background(), "org/gone", "404 Not Found")
+	repoDisabledHandler(c, nil)(context.Background(), "org/gone", "404 Not Found")
+	if len(n.titles) != 1 {
+		t.Errorf("expected no message in a dry run, got %v", n.titles)
+	}
+}
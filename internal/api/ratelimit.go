@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// clientLimiter allows each client up to perMinute requests a minute, in
+// bursts of up to perMinute, with a token bucket per client. Idle buckets are
+// dropped once full again, so the map only holds recent clients.
+type clientLimiter struct {
+	perMinute int
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// bucket holds a client's remaining requests as of updated.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newClientLimiter(perMinute int) *clientLimiter {
+	return &clientLimiter{
+		perMinute: perMinute,
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+	}
+}
+
+// allow reports whether client may make a request now, using one of its
+// requests if so.
+func (l *clientLimiter) allow(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(l.perMinute), updated: now}
+		l.buckets[client] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.updated = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill returns b's tokens as of now.
+func (l *clientLimiter) refill(b *bucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.updated).Minutes()*float64(l.perMinute)
+	return min(tokens, float64(l.perMinute))
+}
+
+// sweep drops the buckets that have refilled, at most once a minute.
+func (l *clientLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+	for client, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.perMinute) {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// clientKey identifies the caller of r by its address.
+func clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
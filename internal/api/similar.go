@@ -0,0 +1,199 @@
+// Package api serves the HTTP endpoints that web frontends call directly,
+// without an API token.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+// ErrUnknownRepo is returned by a SimilarFinder for a repo it does not
+// serve.
+var ErrUnknownRepo = errors.New("unknown repository")
+
+// SimilarFinder finds stored issues similar to an issue being written.
+type SimilarFinder interface {
+	// Similar returns the stored issues of repo most similar to a draft
+	// with title and body, best first.
+	Similar(ctx context.Context, repo, title, body string) ([]github.DuplicateCandidate, error)
+}
+
+const (
+	// maxBodySize limits the size of a request; issue bodies are far
+	// smaller.
+	maxBodySize = 256 << 10
+
+	// maxInFlight caps concurrent lookups, each of which calls the
+	// embedder, so a burst of keystrokes from many forms cannot queue
+	// unbounded work.
+	maxInFlight = 8
+)
+
+// SimilarRequest is the body of POST /api/similar.
+type SimilarRequest struct {
+	// Repo is "owner/repo". It may be left out when a single repo is
+	// served.
+	Repo  string `json:"repo"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// SimilarIssue is a stored issue in a SimilarResponse.
+type SimilarIssue struct {
+	Number int     `json:"number"`
+	Title  string  `json:"title"`
+	State  string  `json:"state,omitempty"`
+	URL    string  `json:"url"`
+	Score  float32 `json:"score"`
+}
+
+// SimilarResponse is the reply to POST /api/similar.
+type SimilarResponse struct {
+	Repo   string         `json:"repo"`
+	Issues []SimilarIssue `json:"issues"`
+}
+
+// SimilarHandler serves POST /api/similar, which returns the stored issues
+// similar to a title and body, to back a "similar issues" box in an issue
+// form. Browsers may call it from the allowed origins.
+type SimilarHandler struct {
+	find        SimilarFinder
+	defaultRepo string
+	anyOrigin   bool
+	origins     map[string]bool
+	slots       chan struct{}
+	limiter     *clientLimiter
+	logger      *slog.Logger
+}
+
+// NewSimilarHandler creates a SimilarHandler. defaultRepo is used for
+// requests without a repo; allowedOrigins are the origins allowed to call it
+// from a browser, "*" allowing any. Each client address may make up to
+// perMinute requests a minute; 0 means no limit.
+func NewSimilarHandler(find SimilarFinder, defaultRepo string, allowedOrigins []string, perMinute int, logger *slog.Logger) *SimilarHandler {
+	h := &SimilarHandler{
+		find:        find,
+		defaultRepo: defaultRepo,
+		origins:     make(map[string]bool, len(allowedOrigins)),
+		slots:       make(chan struct{}, maxInFlight),
+		logger:      logger,
+	}
+	if perMinute > 0 {
+		h.limiter = newClientLimiter(perMinute)
+	}
+	for _, o := range allowedOrigins {
+		if o == "*" {
+			h.anyOrigin = true
+		}
+		h.origins[strings.TrimSuffix(o, "/")] = true
+	}
+	return h
+}
+
+func (h *SimilarHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.allowOrigin(w, r)
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodOptions:
+		// CORS preflight
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req SimilarRequest
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Repo == "" {
+		req.Repo = h.defaultRepo
+	}
+	if req.Repo == "" {
+		writeError(w, http.StatusBadRequest, "repo is required")
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" && strings.TrimSpace(req.Body) == "" {
+		writeError(w, http.StatusBadRequest, "title or body is required")
+		return
+	}
+
+	if h.limiter != nil && !h.limiter.allow(clientKey(r)) {
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusTooManyRequests, "too many requests")
+		return
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	default:
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusTooManyRequests, "too many requests")
+		return
+	}
+
+	found, err := h.find.Similar(r.Context(), req.Repo, req.Title, req.Body)
+	if errors.Is(err, ErrUnknownRepo) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("repository %s is not served", req.Repo))
+		return
+	}
+	if err != nil {
+		h.logger.Warn("similar issues lookup failed", "repo", req.Repo, "error", err)
+		writeError(w, http.StatusServiceUnavailable, "similar issues are unavailable")
+		return
+	}
+
+	resp := SimilarResponse{Repo: req.Repo, Issues: make([]SimilarIssue, 0, len(found))}
+	for _, c := range found {
+		resp.Issues = append(resp.Issues, SimilarIssue{
+			Number: c.Number,
+			Title:  c.Title,
+			State:  c.State,
+			URL:    fmt.Sprintf("https://github.com/%s/issues/%d", req.Repo, c.Number),
+			Score:  c.Score,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// allowOrigin sets the CORS headers letting a browser on an allowed origin
+// read the response. Other origins get none, so the browser blocks them.
+func (h *SimilarHandler) allowOrigin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	if h.anyOrigin {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else if h.origins[origin] {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError replies with {"error": msg}.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
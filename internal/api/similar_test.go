@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+// fakeFinder records lookups and answers for org/app only.
+type fakeFinder struct {
+	repo, title, body string
+	err               error
+}
+
+func (f *fakeFinder) Similar(ctx context.Context, repo, title, body string) ([]github.DuplicateCandidate, error) {
+	f.repo, f.title, f.body = repo, title, body
+	if f.err != nil {
+		return nil, f.err
+	}
+	if repo != "org/app" {
+		return nil, ErrUnknownRepo
+	}
+	return []github.DuplicateCandidate{
+		{Number: 7, Title: "Crash on start", State: "open", Score: 0.91},
+		{Number: 3, Title: "Crash on exit", State: "closed", Score: 0.78},
+	}, nil
+}
+
+// blockingFinder blocks lookups until release is closed.
+type blockingFinder struct {
+	release chan struct{}
+}
+
+func (f blockingFinder) Similar(context.Context, string, string, string) ([]github.DuplicateCandidate, error) {
+	<-f.release
+	return nil, nil
+}
+
+func newTestHandler(find SimilarFinder, origins ...string) *SimilarHandler {
+	return NewSimilarHandler(find, "org/app", origins, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func post(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/similar", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSimilarHandler(t *testing.T) {
+	find := &fakeFinder{}
+	rec := post(newTestHandler(find), `{"title":"App crashes","body":"on launch"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var resp SimilarResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if find.repo != "org/app" || find.title != "App crashes" || find.body != "on launch" {
+		t.Errorf("unexpected lookup %+v", find)
+	}
+	if resp.Repo != "org/app" || len(resp.Issues) != 2 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	want := SimilarIssue{Number: 7, Title: "Crash on start", State: "open", URL: "https://github.com/org/app/issues/7", Score: 0.91}
+	if resp.Issues[0] != want {
+		t.Errorf("issue = %+v, want %+v", resp.Issues[0], want)
+	}
+}
+
+func TestSimilarHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		find   *fakeFinder
+		body   string
+		status int
+		error  string
+	}{
+		{"bad JSON", &fakeFinder{}, `{`, http.StatusBadRequest, "invalid JSON"},
+		{"empty draft", &fakeFinder{}, `{"title":"  "}`, http.StatusBadRequest, "title or body is required"},
+		{"unknown repo", &fakeFinder{}, `{"repo":"org/secret","title":"x"}`, http.StatusNotFound, "org/secret is not served"},
+		{"lookup failure", &fakeFinder{err: errors.New("embedder down")}, `{"title":"x"}`, http.StatusServiceUnavailable, "unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post(newTestHandler(tt.find), tt.body)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			var resp map[string]string
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if !strings.Contains(resp["error"], tt.error) {
+				t.Errorf("error = %q, want it to mention %q", resp["error"], tt.error)
+			}
+			if strings.Contains(rec.Body.String(), "embedder down") {
+				t.Error("expected internal errors not to be exposed")
+			}
+		})
+	}
+
+	// Without a default repo, one is required
+	h := NewSimilarHandler(&fakeFinder{}, "", nil, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if rec := post(h, `{"title":"x"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 without a repo", rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	newTestHandler(&fakeFinder{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/similar", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") == "" {
+		t.Errorf("status = %d, want 405 with Allow", rec.Code)
+	}
+}
+
+func TestSimilarHandlerCORS(t *testing.T) {
+	h := newTestHandler(&fakeFinder{}, "https://example.com/")
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/api/similar", nil)
+	preflight.Header.Set("Origin", "https://example.com")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, preflight)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("preflight status = %d, want 204", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q", got)
+	}
+	if !strings.Contains(rec.Header().Get("Access-Control-Allow-Methods"), "POST") {
+		t.Errorf("expected POST to be allowed, got %q", rec.Header().Get("Access-Control-Allow-Methods"))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/similar", strings.NewReader(`{"title":"x"}`))
+	req.Header.Set("Origin", "https://evil.example")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no CORS grant for another origin, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/similar", strings.NewReader(`{"title":"x"}`))
+	req.Header.Set("Origin", "https://anything.example")
+	rec = httptest.NewRecorder()
+	newTestHandler(&fakeFinder{}, "*").ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("expected any origin to be allowed, got %q", got)
+	}
+}
+
+func TestSimilarHandlerLimitsInFlight(t *testing.T) {
+	find := blockingFinder{release: make(chan struct{})}
+	h := newTestHandler(find)
+
+	done := make(chan struct{})
+	for i := 0; i < maxInFlight; i++ {
+		go func() {
+			post(h, `{"title":"x"}`)
+			done <- struct{}{}
+		}()
+	}
+	// Wait until every slot is taken
+	for len(h.slots) < maxInFlight {
+		select {
+		case <-done:
+			t.Fatal("a lookup finished early")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	rec := post(h, `{"title":"x"}`)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d, want 429 with Retry-After", rec.Code)
+	}
+	close(find.release)
+	for i := 0; i < maxInFlight; i++ {
+		<-done
+	}
+}
+
+func TestSimilarHandlerRateLimitsClients(t *testing.T) {
+	h := NewSimilarHandler(&fakeFinder{}, "org/app", nil, 2, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	h.limiter.now = func() time.Time { return now }
+
+	postFrom := func(addr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/similar", strings.NewReader(`{"title":"x"}`))
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for i := 0; i < 2; i++ {
+		if code := postFrom("192.0.2.1:1234"); code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, code)
+		}
+	}
+	if code := postFrom("192.0.2.1:5678"); code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429 over the limit", code)
+	}
+	if code := postFrom("192.0.2.2:1234"); code != http.StatusOK {
+		t.Errorf("status = %d, want other clients unaffected", code)
+	}
+
+	now = now.Add(30 * time.Second)
+	if code := postFrom("192.0.2.1:1234"); code != http.StatusOK {
+		t.Errorf("status = %d, want 200 once a request has refilled", code)
+	}
+}
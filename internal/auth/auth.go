@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Scope is a permission granted to an API token.
+type Scope string
+
+const (
+	// ScopeRead allows read-only access (stats, history, metrics).
+	ScopeRead Scope = "read"
+	// ScopeTriage allows running triage operations. Implies ScopeRead.
+	ScopeTriage Scope = "triage"
+	// ScopeAdmin allows everything, including configuration changes.
+	ScopeAdmin Scope = "admin"
+)
+
+// tokenPrefix makes triage tokens recognizable in logs and secret scanners.
+const tokenPrefix = "trg_"
+
+// scopeRank orders scopes so that a higher scope implies the lower ones.
+var scopeRank = map[Scope]int{
+	ScopeRead:   1,
+	ScopeTriage: 2,
+	ScopeAdmin:  3,
+}
+
+// ParseScope validates and converts a string into a Scope.
+func ParseScope(s string) (Scope, error) {
+	sc := Scope(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := scopeRank[sc]; !ok {
+		return "", fmt.Errorf("unknown scope %q (valid: read, triage, admin)", s)
+	}
+	return sc, nil
+}
+
+// ParseScopes validates a list of scope strings.
+func ParseScopes(ss []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(ss))
+	for _, s := range ss {
+		sc, err := ParseScope(s)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, sc)
+	}
+	return scopes, nil
+}
+
+// Token is an authenticated API principal.
+type Token struct {
+	Name   string
+	Scopes []Scope
+}
+
+// Allows reports whether the token grants the required scope, either
+// directly or through a higher scope.
+func (t *Token) Allows(required Scope) bool {
+	if t == nil {
+		return false
+	}
+	for _, s := range t.Scopes {
+		if scopeRank[s] >= scopeRank[required] {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateToken returns a new random plaintext token.
+func GenerateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating token: %w", err)
+	}
+	return tokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashToken returns the hex-encoded SHA-256 hash of a plaintext token.
+// Only hashes are persisted or compared.
+func HashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// LookupFunc resolves a token hash to a Token. It returns (nil, nil) when
+// the hash is unknown.
+type LookupFunc func(hash string) (*Token, error)
+
+// Authenticator validates bearer tokens against statically configured
+// tokens and an optional dynamic lookup (e.g. the store).
+type Authenticator struct {
+	static map[string]*Token
+	lookup LookupFunc
+}
+
+// NewAuthenticator creates an Authenticator. static maps token hashes to
+// tokens; lookup may be nil.
+func NewAuthenticator(static map[string]*Token, lookup LookupFunc) *Authenticator {
+	if static == nil {
+		static = make(map[string]*Token)
+	}
+	return &Authenticator{static: static, lookup: lookup}
+}
+
+// Authenticate resolves a plaintext token. It returns (nil, nil) when the
+// token is not recognized.
+func (a *Authenticator) Authenticate(token string) (*Token, error) {
+	if token == "" {
+		return nil, nil
+	}
+	hash := HashToken(token)
+	if t, ok := a.static[hash]; ok {
+		return t, nil
+	}
+	if a.lookup != nil {
+		return a.lookup(hash)
+	}
+	return nil, nil
+}
+
+type contextKey struct{}
+
+// WithToken returns a context carrying the authenticated token.
+func WithToken(ctx context.Context, t *Token) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the authenticated token stored in ctx, if any.
+func FromContext(ctx context.Context) (*Token, bool) {
+	t, ok := ctx.Value(contextKey{}).(*Token)
+	return t, ok
+}
+
+// Require wraps next so that it only runs for requests carrying a bearer
+// token with the required scope. Missing or unknown tokens get 401;
+// insufficient scope gets 403.
+func (a *Authenticator) Require(required Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := bearerToken(r)
+		t, err := a.Authenticate(raw)
+		if err != nil {
+			http.Error(w, "authentication unavailable", http.StatusInternalServerError)
+			return
+		}
+		if t == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="triage"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !t.Allows(required) {
+			http.Error(w, fmt.Sprintf("token lacks %q scope", required), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithToken(r.Context(), t)))
+	})
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header or,
+// for callers such as chat webhooks that cannot set headers, from an
+// access_token query parameter (RFC 6750, section 2.3).
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	const prefix = "bearer "
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return r.URL.Query().Get("access_token")
+	}
+	return strings.TrimSpace(h[len(prefix):])
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Scope
+		wantErr bool
+	}{
+		{"read", ScopeRead, false},
+		{"TRIAGE", ScopeTriage, false},
+		{" admin ", ScopeAdmin, false},
+		{"write", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseScope(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseScope(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseScope(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokenAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []Scope
+		required Scope
+		want     bool
+	}{
+		{"read allows read", []Scope{ScopeRead}, ScopeRead, true},
+		{"read denies triage", []Scope{ScopeRead}, ScopeTriage, false},
+		{"triage implies read", []Scope{ScopeTriage}, ScopeRead, true},
+		{"triage denies admin", []Scope{ScopeTriage}, ScopeAdmin, false},
+		{"admin implies all", []Scope{ScopeAdmin}, ScopeTriage, true},
+		{"no scopes", nil, ScopeRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok := &Token{Name: "t", Scopes: tt.scopes}
+			if got := tok.Allows(tt.required); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.required, got, tt.want)
+			}
+		})
+	}
+
+	var nilTok *Token
+	if nilTok.Allows(ScopeRead) {
+		t.Error("nil token should allow nothing")
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	a, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	b, _ := GenerateToken()
+	if a == b {
+		t.Error("expected distinct tokens")
+	}
+	if !strings.HasPrefix(a, tokenPrefix) {
+		t.Errorf("expected %q prefix, got %q", tokenPrefix, a)
+	}
+	if HashToken(a) == a || len(HashToken(a)) != 64 {
+		t.Errorf("unexpected hash %q", HashToken(a))
+	}
+}
+
+func TestAuthenticatorStaticAndLookup(t *testing.T) {
+	static := map[string]*Token{
+		HashToken("static-token"): {Name: "ci", Scopes: []Scope{ScopeRead}},
+	}
+	lookupCalls := 0
+	a := NewAuthenticator(static, func(hash string) (*Token, error) {
+		lookupCalls++
+		if hash == HashToken("db-token") {
+			return &Token{Name: "db", Scopes: []Scope{ScopeAdmin}}, nil
+		}
+		return nil, nil
+	})
+
+	tok, err := a.Authenticate("static-token")
+	if err != nil || tok == nil || tok.Name != "ci" {
+		t.Fatalf("static token: got %+v, %v", tok, err)
+	}
+	if lookupCalls != 0 {
+		t.Error("static hit should not call lookup")
+	}
+
+	tok, err = a.Authenticate("db-token")
+	if err != nil || tok == nil || tok.Name != "db" {
+		t.Fatalf("db token: got %+v, %v", tok, err)
+	}
+
+	tok, err = a.Authenticate("nope")
+	if err != nil || tok != nil {
+		t.Fatalf("unknown token: got %+v, %v", tok, err)
+	}
+
+	tok, _ = a.Authenticate("")
+	if tok != nil {
+		t.Error("empty token should not authenticate")
+	}
+}
+
+func TestRequireMiddleware(t *testing.T) {
+	a := NewAuthenticator(map[string]*Token{
+		HashToken("reader"): {Name: "reader", Scopes: []Scope{ScopeRead}},
+		HashToken("admin"):  {Name: "admin", Scopes: []Scope{ScopeAdmin}},
+	}, nil)
+
+	var gotName string
+	h := a.Require(ScopeTriage, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok, ok := FromContext(r.Context())
+		if ok {
+			gotName = tok.Name
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		want   int
+	}{
+		{"missing header", "/", "", http.StatusUnauthorized},
+		{"wrong scheme", "/", "Basic reader", http.StatusUnauthorized},
+		{"unknown token", "/", "Bearer other", http.StatusUnauthorized},
+		{"insufficient scope", "/", "Bearer reader", http.StatusForbidden},
+		{"sufficient scope", "/", "bearer admin", http.StatusNoContent},
+		{"query parameter", "/?access_token=admin", "", http.StatusNoContent},
+		{"header over query parameter", "/?access_token=admin", "Bearer reader", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+	if gotName != "admin" {
+		t.Errorf("expected token in context, got %q", gotName)
+	}
+}
+
+func TestRequireMiddlewareLookupError(t *testing.T) {
+	a := NewAuthenticator(nil, func(string) (*Token, error) {
+		return nil, errors.New("db down")
+	})
+	h := a.Require(ScopeRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not run")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer x")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+}
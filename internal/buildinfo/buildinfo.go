@@ -0,0 +1,101 @@
+package buildinfo
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, set at build time via ldflags:
+//
+//	go build -ldflags="-X github.com/jacklau/triage/internal/buildinfo.Version=1.0.0 \
+//	  -X github.com/jacklau/triage/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/jacklau/triage/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// When Commit or Date are not set, they are read from the VCS information
+// the Go toolchain embeds in binaries built from a git checkout.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a dirty tree
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build metadata, filling gaps from the embedded VCS info.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = shortCommit(s.Value)
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+func shortCommit(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
+
+// UserAgent returns the User-Agent sent on outgoing API requests, e.g.
+// "triage/1.2.0 (abc1234; linux/amd64)".
+func UserAgent() string {
+	info := Get()
+	ua := "triage/" + info.Version + " ("
+	if info.Commit != "" {
+		ua += info.Commit + "; "
+	}
+	return ua + info.Platform + ")"
+}
+
+// Transport returns an http.RoundTripper that prefixes the User-Agent of
+// each request with UserAgent, keeping any SDK identifier after it. A nil
+// base uses http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &uaTransport{base: base}
+}
+
+type uaTransport struct {
+	base http.RoundTripper
+}
+
+func (t *uaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ua := UserAgent()
+	if existing := req.Header.Get("User-Agent"); existing != "" {
+		ua += " " + existing
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", ua)
+	return t.base.RoundTrip(req)
+}
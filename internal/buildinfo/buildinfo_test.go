@@ -0,0 +1,67 @@
+package buildinfo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	oldV, oldC, oldD := Version, Commit, Date
+	defer func() { Version, Commit, Date = oldV, oldC, oldD }()
+	Version, Commit, Date = "1.2.3", "abc1234", "2025-01-02T03:04:05Z"
+
+	info := Get()
+	if info.Version != "1.2.3" || info.Commit != "abc1234" || info.Date != "2025-01-02T03:04:05Z" {
+		t.Errorf("expected ldflags values to win, got %+v", info)
+	}
+	if info.GoVersion != runtime.Version() || info.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("unexpected runtime info: %+v", info)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	oldV, oldC := Version, Commit
+	defer func() { Version, Commit = oldV, oldC }()
+	Version, Commit = "1.2.3", "abc1234"
+
+	want := "triage/1.2.3 (abc1234; " + runtime.GOOS + "/" + runtime.GOARCH + ")"
+	if got := UserAgent(); got != want {
+		t.Errorf("UserAgent() = %q, want %q", got, want)
+	}
+}
+
+func TestShortCommit(t *testing.T) {
+	if got := shortCommit("0123456789abcdef0123"); got != "0123456789ab" {
+		t.Errorf("shortCommit() = %q", got)
+	}
+	if got := shortCommit("abc"); got != "abc" {
+		t.Errorf("shortCommit() = %q", got)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("User-Agent", "sdk/1.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if !strings.HasPrefix(got, "triage/") || !strings.HasSuffix(got, " sdk/1.0") {
+		t.Errorf("expected triage UA followed by the SDK's, got %q", got)
+	}
+	if req.Header.Get("User-Agent") != "sdk/1.0" {
+		t.Error("expected the caller's request not to be modified")
+	}
+}
@@ -0,0 +1,85 @@
+// Package chatops answers chat slash commands, such as "/triage check 42"
+// in Slack or Discord, by running the matching triage operation and
+// replying in the channel.
+package chatops
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds how long a command may run before its reply is
+// given up on.
+const commandTimeout = 2 * time.Minute
+
+// usage is the reply to an empty or unknown command.
+const usage = "Usage: `/triage check <owner/repo#number>` or `/triage stats [owner/repo]`"
+
+// Runner carries out chat commands. Replies are Markdown.
+type Runner interface {
+	// Check triages an issue and describes the result.
+	Check(ctx context.Context, repo string, number int) (string, error)
+	// Stats summarizes recent triage activity for a repo.
+	Stats(ctx context.Context, repo string) (string, error)
+}
+
+// Dispatch runs the command in text, e.g. "check 42" or "stats org/repo",
+// and returns the reply. Commands that leave out the repo use defaultRepo.
+// Errors are reported in the reply.
+func Dispatch(ctx context.Context, run Runner, text, defaultRepo string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return usage
+	}
+	switch strings.ToLower(fields[0]) {
+	case "check":
+		if len(fields) != 2 {
+			return usage
+		}
+		repo, number, err := parseIssueRef(fields[1], defaultRepo)
+		if err != nil {
+			return "Error: " + err.Error()
+		}
+		return reply(run.Check(ctx, repo, number))
+	case "stats":
+		repo := defaultRepo
+		if len(fields) > 1 {
+			repo = fields[1]
+		}
+		if !strings.Contains(repo, "/") {
+			return "Error: name the repo, e.g. `/triage stats owner/repo`"
+		}
+		return reply(run.Stats(ctx, repo))
+	default:
+		return usage
+	}
+}
+
+func reply(text string, err error) string {
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return text
+}
+
+// parseIssueRef parses "owner/repo#42", or "#42" or "42" in defaultRepo.
+func parseIssueRef(ref, defaultRepo string) (string, int, error) {
+	repo, num, ok := strings.Cut(ref, "#")
+	if !ok {
+		repo, num = "", ref
+	}
+	if repo == "" {
+		repo = defaultRepo
+	}
+	if !strings.Contains(repo, "/") {
+		return "", 0, fmt.Errorf("name the issue as owner/repo#number")
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n <= 0 {
+		return "", 0, fmt.Errorf("invalid issue number %q", num)
+	}
+	return repo, n, nil
+}
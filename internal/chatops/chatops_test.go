@@ -0,0 +1,68 @@
+package chatops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRunner records the commands it is asked to run.
+type fakeRunner struct {
+	mu    sync.Mutex
+	calls []string
+	err   error
+}
+
+func (f *fakeRunner) Check(_ context.Context, repo string, number int) (string, error) {
+	f.record(fmt.Sprintf("check %s#%d", repo, number))
+	return fmt.Sprintf("checked %s#%d", repo, number), f.err
+}
+
+func (f *fakeRunner) Stats(_ context.Context, repo string) (string, error) {
+	f.record("stats " + repo)
+	return "stats for " + repo, f.err
+}
+
+func (f *fakeRunner) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		text, defaultRepo string
+		want              string
+	}{
+		{"check 42", "org/app", "checked org/app#42"},
+		{"check #42", "org/app", "checked org/app#42"},
+		{"check org/lib#7", "org/app", "checked org/lib#7"},
+		{"CHECK org/lib#7", "", "checked org/lib#7"},
+		{"stats", "org/app", "stats for org/app"},
+		{"stats org/lib", "", "stats for org/lib"},
+		{"", "org/app", usage},
+		{"deploy", "org/app", usage},
+		{"check", "org/app", usage},
+		{"check 42", "", "Error: name the issue as owner/repo#number"},
+		{"check org/app#x", "", `Error: invalid issue number "x"`},
+		{"check org/app#0", "", `Error: invalid issue number "0"`},
+		{"stats", "", "Error: name the repo, e.g. `/triage stats owner/repo`"},
+	}
+	for _, tt := range tests {
+		got := Dispatch(context.Background(), &fakeRunner{}, tt.text, tt.defaultRepo)
+		if got != tt.want {
+			t.Errorf("Dispatch(%q, %q) = %q, want %q", tt.text, tt.defaultRepo, got, tt.want)
+		}
+	}
+}
+
+func TestDispatchReportsRunnerErrors(t *testing.T) {
+	run := &fakeRunner{err: errors.New("rate limited")}
+	got := Dispatch(context.Background(), run, "check org/app#1", "")
+	if !strings.HasPrefix(got, "Error: rate limited") {
+		t.Errorf("Dispatch = %q, want the runner's error", got)
+	}
+}
@@ -0,0 +1,185 @@
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// discordAPIURL is the base URL of the Discord API.
+const discordAPIURL = "https://discord.com/api/v10"
+
+// discordContentLimit is the longest message Discord accepts, in
+// characters.
+const discordContentLimit = 2000
+
+// Discord interaction and response types.
+const (
+	interactionPing    = 1
+	interactionCommand = 2
+
+	responsePong            = 1
+	responseDeferredMessage = 5
+	optionSubcommand        = 1
+	optionSubcommandGroup   = 2
+)
+
+// DiscordHandler serves Discord interactions for a "/triage" application
+// command with "check" and "stats" subcommands. It defers its response at
+// once, since Discord waits only three seconds, then edits in the reply.
+type DiscordHandler struct {
+	publicKey   ed25519.PublicKey
+	run         Runner
+	defaultRepo string
+	client      *http.Client
+	apiURL      string
+	logger      *slog.Logger
+}
+
+// NewDiscordHandler creates a DiscordHandler that verifies requests with
+// the application's hex-encoded public key.
+func NewDiscordHandler(publicKeyHex string, run Runner, defaultRepo string, logger *slog.Logger) (*DiscordHandler, error) {
+	key, err := hex.DecodeString(publicKeyHex)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid discord public key: want %d hex-encoded bytes", ed25519.PublicKeySize)
+	}
+	return &DiscordHandler{
+		publicKey:   key,
+		run:         run,
+		defaultRepo: defaultRepo,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		apiURL:      discordAPIURL,
+		logger:      logger,
+	}, nil
+}
+
+// interaction is the part of a Discord interaction that we use.
+type interaction struct {
+	Type          int    `json:"type"`
+	ApplicationID string `json:"application_id"`
+	Token         string `json:"token"`
+	Data          struct {
+		Name    string              `json:"name"`
+		Options []interactionOption `json:"options"`
+	} `json:"data"`
+}
+
+// interactionOption is a subcommand or an argument to one.
+type interactionOption struct {
+	Name    string              `json:"name"`
+	Type    int                 `json:"type"`
+	Value   any                 `json:"value"`
+	Options []interactionOption `json:"options"`
+}
+
+func (h *DiscordHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "reading request", http.StatusBadRequest)
+		return
+	}
+	if !h.verify(r.Header, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var in interaction
+	if err := json.Unmarshal(body, &in); err != nil {
+		http.Error(w, "invalid interaction", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch in.Type {
+	case interactionPing:
+		json.NewEncoder(w).Encode(map[string]int{"type": responsePong})
+	case interactionCommand:
+		text := commandText(in.Data.Options)
+		h.logger.Info("discord command", "text", text)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+			defer cancel()
+			out := Dispatch(ctx, h.run, text, h.defaultRepo)
+			if err := h.respond(ctx, in.ApplicationID, in.Token, out); err != nil {
+				h.logger.Warn("failed to reply to discord command", "error", err)
+			}
+		}()
+		json.NewEncoder(w).Encode(map[string]int{"type": responseDeferredMessage})
+	default:
+		http.Error(w, "unsupported interaction type", http.StatusBadRequest)
+	}
+}
+
+// verify checks Discord's Ed25519 signature of the timestamp and body.
+func (h *DiscordHandler) verify(header http.Header, body []byte) bool {
+	sig, err := hex.DecodeString(header.Get("X-Signature-Ed25519"))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	msg := append([]byte(header.Get("X-Signature-Timestamp")), body...)
+	return ed25519.Verify(h.publicKey, msg, sig)
+}
+
+// commandText flattens the command's options into Dispatch's text form:
+// the subcommand's name followed by its argument values, e.g. "check 42".
+func commandText(opts []interactionOption) string {
+	var parts []string
+	for _, o := range opts {
+		switch o.Type {
+		case optionSubcommand, optionSubcommandGroup:
+			parts = append(parts, o.Name)
+			if rest := commandText(o.Options); rest != "" {
+				parts = append(parts, rest)
+			}
+		default:
+			parts = append(parts, fmt.Sprint(o.Value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// truncateRunes shortens text to at most limit characters, ending it with
+// an ellipsis if it was cut.
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit-1]) + "…"
+}
+
+// respond edits the deferred response to show text.
+func (h *DiscordHandler) respond(ctx context.Context, appID, token, text string) error {
+	text = truncateRunes(text, discordContentLimit)
+	body, err := json.Marshal(map[string]string{"content": text})
+	if err != nil {
+		return fmt.Errorf("marshaling reply: %w", err)
+	}
+	url := fmt.Sprintf("%s/webhooks/%s/%s/messages/@original", h.apiURL, appID, token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending reply: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("discord returned %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package chatops
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func newTestDiscordHandler(t *testing.T, run Runner) (*DiscordHandler, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewDiscordHandler(hex.EncodeToString(pub), run, "org/app", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewDiscordHandler: %v", err)
+	}
+	return h, priv
+}
+
+func discordRequest(key ed25519.PrivateKey, body string) *http.Request {
+	ts := "1700000000"
+	req := httptest.NewRequest(http.MethodPost, "/discord/interactions", strings.NewReader(body))
+	req.Header.Set("X-Signature-Timestamp", ts)
+	req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(key, []byte(ts+body))))
+	return req
+}
+
+func TestDiscordHandler_Ping(t *testing.T) {
+	h, key := newTestDiscordHandler(t, &fakeRunner{})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, discordRequest(key, `{"type":1}`))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"type":1}` {
+		t.Errorf("ping response = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestDiscordHandler_CommandEditsDeferredReply(t *testing.T) {
+	type edit struct {
+		method, path, content string
+	}
+	edits := make(chan edit, 1)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Content string `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		edits <- edit{r.Method, r.URL.Path, body.Content}
+	}))
+	defer api.Close()
+
+	h, key := newTestDiscordHandler(t, &fakeRunner{})
+	h.apiURL = api.URL
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, discordRequest(key, `{
+		"type": 2, "application_id": "app1", "token": "tok",
+		"data": {"name": "triage", "options": [
+			{"name": "check", "type": 1, "options": [{"name": "issue", "type": 3, "value": "org/lib#7"}]}
+		]}
+	}`))
+	if strings.TrimSpace(rec.Body.String()) != `{"type":5}` {
+		t.Errorf("response = %s, want a deferred message", rec.Body)
+	}
+
+	select {
+	case e := <-edits:
+		if e.method != http.MethodPatch || e.path != "/webhooks/app1/tok/messages/@original" {
+			t.Errorf("edit sent to %s %s", e.method, e.path)
+		}
+		if e.content != "checked org/lib#7" {
+			t.Errorf("content = %q", e.content)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deferred reply was not edited")
+	}
+}
+
+func TestDiscordHandler_RejectsBadSignature(t *testing.T) {
+	h, _ := newTestDiscordHandler(t, &fakeRunner{})
+	_, other, _ := ed25519.GenerateKey(nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, discordRequest(other, `{"type":1}`))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncateRunes("héllo", 5); got != "héllo" {
+		t.Errorf("truncateRunes() = %q, want it unchanged", got)
+	}
+	got := truncateRunes(strings.Repeat("é", 2500), discordContentLimit)
+	if !utf8.ValidString(got) || utf8.RuneCountInString(got) != discordContentLimit || !strings.HasSuffix(got, "é…") {
+		t.Errorf("truncateRunes() gave %d runes, valid %t", utf8.RuneCountInString(got), utf8.ValidString(got))
+	}
+}
+
+func TestNewDiscordHandler_InvalidKey(t *testing.T) {
+	if _, err := NewDiscordHandler("abcd", &fakeRunner{}, "", nil); err == nil {
+		t.Error("expected error for a short public key")
+	}
+}
+
+func TestCommandText(t *testing.T) {
+	opts := []interactionOption{{Name: "stats", Type: optionSubcommand, Options: []interactionOption{
+		{Name: "repo", Type: 3, Value: "org/app"},
+	}}}
+	if got := commandText(opts); got != "stats org/app" {
+		t.Errorf("commandText = %q, want %q", got, "stats org/app")
+	}
+	opts = []interactionOption{{Name: "check", Type: optionSubcommand, Options: []interactionOption{
+		{Name: "issue", Type: 4, Value: float64(42)},
+	}}}
+	if got := commandText(opts); got != "check 42" {
+		t.Errorf("commandText = %q, want %q", got, "check 42")
+	}
+}
@@ -0,0 +1,138 @@
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jacklau/triage/internal/notify"
+)
+
+// maxRequestAge is how old a signed request may be before it is rejected
+// as a possible replay.
+const maxRequestAge = 5 * time.Minute
+
+// maxBodySize limits the size of interaction requests.
+const maxBodySize = 1 << 20
+
+// SlackHandler serves a Slack slash command. It acknowledges the command at
+// once, since Slack waits only three seconds, then posts the reply to the
+// command's response_url.
+type SlackHandler struct {
+	signingSecret string
+	run           Runner
+	defaultRepo   string
+	client        *http.Client
+	logger        *slog.Logger
+	now           func() time.Time
+}
+
+// NewSlackHandler creates a SlackHandler that verifies requests with the
+// app's signing secret.
+func NewSlackHandler(signingSecret string, run Runner, defaultRepo string, logger *slog.Logger) *SlackHandler {
+	return &SlackHandler{
+		signingSecret: signingSecret,
+		run:           run,
+		defaultRepo:   defaultRepo,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+func (h *SlackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "reading request", http.StatusBadRequest)
+		return
+	}
+	if !h.verify(r.Header, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	text := form.Get("text")
+	responseURL := form.Get("response_url")
+	h.logger.Info("slack command", "user", form.Get("user_name"), "text", text)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+		defer cancel()
+		out := Dispatch(ctx, h.run, text, h.defaultRepo)
+		if err := h.respond(ctx, responseURL, out); err != nil {
+			h.logger.Warn("failed to reply to slack command", "error", err)
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(slackResponse{
+		ResponseType: "in_channel",
+		Text:         fmt.Sprintf("Running `/triage %s`…", text),
+	})
+}
+
+// verify checks Slack's request signature: an HMAC-SHA256 of
+// "v0:<timestamp>:<body>" keyed by the signing secret.
+func (h *SlackHandler) verify(header http.Header, body []byte) bool {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := h.now().Sub(time.Unix(secs, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.signingSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(want), []byte(header.Get("X-Slack-Signature")))
+}
+
+// slackResponse is a slash command reply.
+type slackResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// respond posts text to the command's response_url, visible to the
+// channel.
+func (h *SlackHandler) respond(ctx context.Context, responseURL, text string) error {
+	body, err := json.Marshal(slackResponse{ResponseType: "in_channel", Text: notify.SlackMarkdown(text)})
+	if err != nil {
+		return fmt.Errorf("marshaling reply: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending reply: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("slack returned %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
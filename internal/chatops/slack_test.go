@@ -0,0 +1,94 @@
+package chatops
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signSlack(secret, ts, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func slackRequest(secret string, ts time.Time, form url.Values) *http.Request {
+	body := form.Encode()
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", stamp)
+	req.Header.Set("X-Slack-Signature", signSlack(secret, stamp, body))
+	return req
+}
+
+func TestSlackHandler_RepliesInChannel(t *testing.T) {
+	replies := make(chan slackResponse, 1)
+	responseSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp slackResponse
+		json.NewDecoder(r.Body).Decode(&resp)
+		replies <- resp
+	}))
+	defer responseSrv.Close()
+
+	run := &fakeRunner{}
+	h := NewSlackHandler("shh", run, "org/app", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, slackRequest("shh", time.Now(), url.Values{
+		"command":      {"/triage"},
+		"text":         {"check 42"},
+		"response_url": {responseSrv.URL},
+	}))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var ack slackResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &ack); err != nil {
+		t.Fatalf("decoding ack: %v", err)
+	}
+	if ack.ResponseType != "in_channel" {
+		t.Errorf("ack response_type = %q, want in_channel", ack.ResponseType)
+	}
+
+	select {
+	case resp := <-replies:
+		if resp.ResponseType != "in_channel" || resp.Text != "checked org/app#42" {
+			t.Errorf("reply = %+v", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reply posted to response_url")
+	}
+}
+
+func TestSlackHandler_RejectsBadSignatures(t *testing.T) {
+	run := &fakeRunner{}
+	h := NewSlackHandler("shh", run, "org/app", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	form := url.Values{"text": {"stats"}}
+
+	tests := map[string]*http.Request{
+		"wrong secret": slackRequest("other", time.Now(), form),
+		"stale":        slackRequest("shh", time.Now().Add(-10*time.Minute), form),
+	}
+	for name, req := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, rec.Code)
+		}
+	}
+	if len(run.calls) != 0 {
+		t.Errorf("runner called for rejected requests: %v", run.calls)
+	}
+}
@@ -2,7 +2,10 @@ package classify
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -13,10 +16,40 @@ import (
 	"github.com/jacklau/triage/internal/provider"
 )
 
+// Default confidence cutoffs for the "suggested" and "possible" levels.
+const (
+	DefaultSuggestedConfidence = 0.9
+	DefaultPossibleConfidence  = 0.7
+)
+
 // Classifier uses an LLM completer to classify GitHub issues.
 type Classifier struct {
 	completer provider.Completer
 	timeout   time.Duration
+	aliases   map[string]string
+
+	// suggestedAt and possibleAt are the lowest confidences reported as
+	// "suggested" and "possible".
+	suggestedAt float64
+	possibleAt  float64
+
+	// multiPassAt is the label count above which classification takes two
+	// passes; zero disables it. See WithMultiPass.
+	multiPassAt int
+
+	// cache, when set, holds results keyed by cacheModel and prompt hash.
+	// See WithCache.
+	cache      Cache
+	cacheModel string
+
+	// language is the default Guidance.Language; see WithLanguage.
+	language string
+}
+
+// Cache stores classification results by model and prompt hash.
+type Cache interface {
+	GetClassification(model, promptHash string) ([]byte, bool, error)
+	PutClassification(model, promptHash string, result []byte) error
 }
 
 // ClassifyResult holds the output of issue classification.
@@ -25,18 +58,75 @@ type ClassifyResult struct {
 	Confidence      float64
 	Reasoning       string
 	ConfidenceLevel string // "suggested", "possible", or "uncertain"
+
+	// Truncated is set when the issue body was shortened to fit the
+	// model's context.
+	Truncated bool
+
+	// RawResponse is the LLM's last reply, as received. A cached result
+	// keeps the reply it was parsed from.
+	RawResponse string
+}
+
+// Option configures a Classifier.
+type Option func(*Classifier)
+
+// WithAliases maps label names the LLM may return, such as "defect", to the
+// configured label they stand for, such as "bug". Aliases match
+// case-insensitively.
+func WithAliases(aliases map[string]string) Option {
+	return func(c *Classifier) {
+		c.aliases = make(map[string]string, len(aliases))
+		for alias, label := range aliases {
+			c.aliases[strings.ToLower(alias)] = label
+		}
+	}
+}
+
+// WithConfidenceLevels sets the lowest confidence reported as "suggested"
+// and as "possible"; anything lower is "uncertain".
+func WithConfidenceLevels(suggested, possible float64) Option {
+	return func(c *Classifier) {
+		c.suggestedAt = suggested
+		c.possibleAt = possible
+	}
+}
+
+// WithCache reuses results from cache for prompts already classified by
+// model, so an unchanged issue is not sent to the LLM again. model should
+// identify both the provider and the model, since either changes the answer.
+func WithCache(cache Cache, model string) Option {
+	return func(c *Classifier) {
+		c.cache = cache
+		c.cacheModel = model
+	}
+}
+
+// WithLanguage has the LLM write its reasoning in lang, a code such as "de"
+// or a language name, unless the call's Guidance names another. Label names
+// are unaffected.
+func WithLanguage(lang string) Option {
+	return func(c *Classifier) {
+		c.language = lang
+	}
 }
 
 // NewClassifier creates a new Classifier with the given completer and timeout.
 // If timeout is zero, defaults to 30 seconds.
-func NewClassifier(completer provider.Completer, timeout time.Duration) *Classifier {
+func NewClassifier(completer provider.Completer, timeout time.Duration, opts ...Option) *Classifier {
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
-	return &Classifier{
-		completer: completer,
-		timeout:   timeout,
+	c := &Classifier{
+		completer:   completer,
+		timeout:     timeout,
+		suggestedAt: DefaultSuggestedConfidence,
+		possibleAt:  DefaultPossibleConfidence,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // llmResponse is the expected JSON structure from the LLM.
@@ -74,12 +164,13 @@ func parseResponse(raw string) (*llmResponse, error) {
 	return &resp, nil
 }
 
-// confidenceLevel returns the confidence level string based on the confidence value.
-func confidenceLevel(confidence float64) string {
+// confidenceLevel returns the confidence level string based on the confidence
+// value and the classifier's cutoffs.
+func (c *Classifier) confidenceLevel(confidence float64) string {
 	switch {
-	case confidence >= 0.9:
+	case confidence >= c.suggestedAt:
 		return "suggested"
-	case confidence >= 0.7:
+	case confidence >= c.possibleAt:
 		return "possible"
 	default:
 		return "uncertain"
@@ -87,22 +178,45 @@ func confidenceLevel(confidence float64) string {
 }
 
 // validateLabels filters the returned labels against the configured label set,
-// rejecting any unknown labels.
-func validateLabels(returned []string, configured []config.LabelConfig) []string {
+// rejecting any unknown labels. A name that is not configured but is a key in
+// aliases (lowercased) is replaced by the label it maps to, if that label is
+// configured. Each label appears at most once in the result.
+func validateLabels(returned []string, configured []config.LabelConfig, aliases map[string]string) []string {
 	valid := make(map[string]bool, len(configured))
 	for _, lc := range configured {
 		valid[lc.Name] = true
 	}
 
 	var result []string
+	seen := make(map[string]bool, len(returned))
 	for _, name := range returned {
-		if valid[name] {
+		if !valid[name] {
+			name = aliases[strings.ToLower(name)]
+		}
+		if valid[name] && !seen[name] {
+			seen[name] = true
 			result = append(result, name)
 		}
 	}
 	return result
 }
 
+// contextRetryLimits are the body lengths, in runes, tried in turn when a
+// prompt is too long for the model's context.
+var contextRetryLimits = []int{8000, 2000, 500}
+
+// truncationNote marks where an issue body was cut short.
+const truncationNote = "\n\n[body truncated to fit the model's context]"
+
+// truncateBody returns body cut to limit runes and whether it was cut.
+func truncateBody(body string, limit int) (string, bool) {
+	runes := []rune(body)
+	if len(runes) <= limit {
+		return body, false
+	}
+	return string(runes[:limit]) + truncationNote, true
+}
+
 const retryPromptSuffix = `
 
 IMPORTANT: You MUST respond with ONLY valid JSON. No markdown, no code fences, no extra text.
@@ -116,26 +230,132 @@ func (c *Classifier) Classify(ctx context.Context, repo string, labels []config.
 // ClassifyWithCustomPrompt classifies a GitHub issue using the LLM completer,
 // appending customPrompt as additional context when non-empty.
 func (c *Classifier) ClassifyWithCustomPrompt(ctx context.Context, repo string, labels []config.LabelConfig, issue github.Issue, customPrompt string) (*ClassifyResult, error) {
-	prompt, err := BuildPromptWithCustom(repo, labels, issue, customPrompt)
+	return c.ClassifyWithGuidance(ctx, repo, labels, issue, Guidance{CustomPrompt: customPrompt})
+}
+
+// ClassifyWithGuidance classifies a GitHub issue using the LLM completer,
+// showing it the repo's examples and custom prompt.
+func (c *Classifier) ClassifyWithGuidance(ctx context.Context, repo string, labels []config.LabelConfig, issue github.Issue, guidance Guidance) (*ClassifyResult, error) {
+	return c.classify(ctx, repo, labels, issue, guidance, nil)
+}
+
+// Exchange is one prompt sent to the LLM and its raw reply.
+type Exchange struct {
+	Prompt   string
+	Response string
+	Err      error
+}
+
+// ClassifyTrace classifies like ClassifyWithGuidance and also returns every
+// prompt sent to the LLM with its raw reply, for iterating on prompts.
+func (c *Classifier) ClassifyTrace(ctx context.Context, repo string, labels []config.LabelConfig, issue github.Issue, guidance Guidance) (*ClassifyResult, []Exchange, error) {
+	var trace []Exchange
+	result, err := c.classify(ctx, repo, labels, issue, guidance, &trace)
+	return result, trace, err
+}
+
+// classify runs the classification, appending each LLM exchange to trace
+// when it is non-nil. Traced runs always reach the LLM; others are served
+// from the cache when one is set.
+func (c *Classifier) classify(ctx context.Context, repo string, labels []config.LabelConfig, issue github.Issue, guidance Guidance, trace *[]Exchange) (*ClassifyResult, error) {
+	if guidance.Language == "" {
+		guidance.Language = c.language
+	}
+	if c.cache == nil || trace != nil {
+		result, _, err := c.classifyUncached(ctx, repo, labels, issue, guidance, trace)
+		return result, err
+	}
+
+	// The key covers everything the LLM sees: the system prompt, the full
+	// label set, the issue, and the repo's guidance.
+	prompt, err := BuildPromptWithGuidance(repo, labels, issue, guidance)
 	if err != nil {
 		return nil, fmt.Errorf("building prompt: %w", err)
 	}
+	sum := sha256.Sum256([]byte(classifySystemPrompt + "\n\n" + prompt))
+	hash := hex.EncodeToString(sum[:])
+
+	if data, ok, err := c.cache.GetClassification(c.cacheModel, hash); err == nil && ok {
+		var cached ClassifyResult
+		if json.Unmarshal(data, &cached) == nil {
+			// Levels follow the current cutoffs, not those when cached
+			cached.ConfidenceLevel = c.confidenceLevel(cached.Confidence)
+			return &cached, nil
+		}
+	}
+
+	result, parsed, err := c.classifyUncached(ctx, repo, labels, issue, guidance, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Fallbacks are not cached, so the next run asks again
+	if parsed {
+		if data, err := json.Marshal(result); err == nil {
+			_ = c.cache.PutClassification(c.cacheModel, hash, data)
+		}
+	}
+	return result, nil
+}
+
+// classifyUncached asks the LLM to classify the issue. parsed reports
+// whether the result came from a valid LLM response rather than the
+// "uncertain" fallback.
+func (c *Classifier) classifyUncached(ctx context.Context, repo string, labels []config.LabelConfig, issue github.Issue, guidance Guidance, trace *[]Exchange) (result *ClassifyResult, parsed bool, err error) {
+	record := func(ctx context.Context, prompt string) (string, error) {
+		raw, err := provider.CompleteRequest(ctx, c.completer, provider.CompletionRequest{System: classifySystemPrompt, Prompt: prompt})
+		if trace != nil {
+			*trace = append(*trace, Exchange{Prompt: prompt, Response: raw, Err: err})
+		}
+		return raw, err
+	}
+
+	// Large label sets are narrowed to the relevant categories first
+	if c.multiPassAt > 0 && len(labels) > c.multiPassAt {
+		if narrowed, ok := c.narrowLabels(ctx, repo, labels, issue, record); ok {
+			labels = narrowed
+		}
+	}
+
+	prompt, err := BuildPromptWithGuidance(repo, labels, issue, guidance)
+	if err != nil {
+		return nil, false, fmt.Errorf("building prompt: %w", err)
+	}
 
 	// Apply timeout
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
-	// First attempt
-	raw, err := c.completer.Complete(ctx, prompt)
+	complete := func(prompt string) (string, error) {
+		return record(ctx, prompt)
+	}
+
+	// First attempt, then shorter bodies while the prompt is too long
+	raw, err := complete(prompt)
+	truncated := false
+	for _, limit := range contextRetryLimits {
+		if !errors.Is(err, provider.ErrContextLength) {
+			break
+		}
+		body, cut := truncateBody(issue.Body, limit)
+		if !cut {
+			continue
+		}
+		issue.Body = body
+		truncated = true
+		if prompt, err = BuildPromptWithGuidance(repo, labels, issue, guidance); err != nil {
+			return nil, false, fmt.Errorf("building prompt: %w", err)
+		}
+		raw, err = complete(prompt)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("completing prompt: %w", err)
+		return nil, false, fmt.Errorf("completing prompt: %w", err)
 	}
 
 	resp, err := parseResponse(raw)
 	if err != nil {
 		// Retry once with stricter prompt
 		retryPrompt := prompt + retryPromptSuffix
-		raw, retryErr := c.completer.Complete(ctx, retryPrompt)
+		retryRaw, retryErr := complete(retryPrompt)
 		if retryErr != nil {
 			// Fall back to uncertain
 			return &ClassifyResult{
@@ -143,9 +363,12 @@ func (c *Classifier) ClassifyWithCustomPrompt(ctx context.Context, repo string,
 				Confidence:      0,
 				Reasoning:       "Failed to get valid response from LLM",
 				ConfidenceLevel: "uncertain",
-			}, nil
+				Truncated:       truncated,
+				RawResponse:     raw,
+			}, false, nil
 		}
 
+		raw = retryRaw
 		resp, err = parseResponse(raw)
 		if err != nil {
 			// Fall back to uncertain
@@ -154,12 +377,14 @@ func (c *Classifier) ClassifyWithCustomPrompt(ctx context.Context, repo string,
 				Confidence:      0,
 				Reasoning:       "Failed to parse LLM response after retry",
 				ConfidenceLevel: "uncertain",
-			}, nil
+				Truncated:       truncated,
+				RawResponse:     raw,
+			}, false, nil
 		}
 	}
 
 	// Validate labels against configured set
-	validLabels := validateLabels(resp.Labels, labels)
+	validLabels := validateLabels(resp.Labels, labels, c.aliases)
 
 	// Build label suggestions
 	suggestions := make([]github.LabelSuggestion, len(validLabels))
@@ -174,6 +399,8 @@ func (c *Classifier) ClassifyWithCustomPrompt(ctx context.Context, repo string,
 		Labels:          suggestions,
 		Confidence:      resp.Confidence,
 		Reasoning:       resp.Reasoning,
-		ConfidenceLevel: confidenceLevel(resp.Confidence),
-	}, nil
+		ConfidenceLevel: c.confidenceLevel(resp.Confidence),
+		Truncated:       truncated,
+		RawResponse:     raw,
+	}, true, nil
 }
@@ -147,6 +147,9 @@ func TestClassify_MalformedJSON_FallsBackToUncertain(t *testing.T) {
 	if len(result.Labels) != 0 {
 		t.Errorf("expected no labels, got %v", result.Labels)
 	}
+	if result.RawResponse != "still not json" {
+		t.Errorf("expected the last raw response, got %q", result.RawResponse)
+	}
 }
 
 func TestClassify_LabelValidation_RejectsUnknown(t *testing.T) {
@@ -280,6 +283,7 @@ func TestValidateLabels(t *testing.T) {
 	result := validateLabels(
 		[]string{"bug", "unknown", "feature", "also-unknown"},
 		testLabels,
+		nil,
 	)
 	if len(result) != 2 {
 		t.Fatalf("expected 2 valid labels, got %d", len(result))
@@ -289,6 +293,33 @@ func TestValidateLabels(t *testing.T) {
 	}
 }
 
+func TestValidateLabels_Aliases(t *testing.T) {
+	aliases := map[string]string{"defect": "bug", "documentation": "docs", "enhancement": "missing"}
+	result := validateLabels(
+		[]string{"defect", "bug", "documentation", "enhancement"},
+		testLabels,
+		aliases,
+	)
+	if len(result) != 2 || result[0] != "bug" || result[1] != "docs" {
+		t.Errorf("expected [bug docs], got %v", result)
+	}
+}
+
+func TestClassify_WithAliases(t *testing.T) {
+	mock := &mockCompleter{
+		responses: []string{`{"labels": ["Defect"], "confidence": 0.9, "reasoning": "crash"}`},
+	}
+	c := NewClassifier(mock, 10*time.Second, WithAliases(map[string]string{"DEFECT": "bug"}))
+
+	result, err := c.Classify(context.Background(), "owner/repo", testLabels, testIssue)
+	if err != nil {
+		t.Fatalf("Classify returned error: %v", err)
+	}
+	if len(result.Labels) != 1 || result.Labels[0].Name != "bug" {
+		t.Errorf("expected alias to resolve to bug, got %+v", result.Labels)
+	}
+}
+
 func TestConfidenceLevel(t *testing.T) {
 	tests := []struct {
 		confidence float64
@@ -302,14 +333,33 @@ func TestConfidenceLevel(t *testing.T) {
 		{0.69, "uncertain"},
 		{0.0, "uncertain"},
 	}
+	c := NewClassifier(nil, 0)
 	for _, tt := range tests {
-		got := confidenceLevel(tt.confidence)
+		got := c.confidenceLevel(tt.confidence)
 		if got != tt.expected {
 			t.Errorf("confidenceLevel(%f) = %q, want %q", tt.confidence, got, tt.expected)
 		}
 	}
 }
 
+func TestConfidenceLevel_Configured(t *testing.T) {
+	c := NewClassifier(nil, 0, WithConfidenceLevels(0.8, 0.5))
+	tests := []struct {
+		confidence float64
+		expected   string
+	}{
+		{0.85, "suggested"},
+		{0.8, "suggested"},
+		{0.6, "possible"},
+		{0.49, "uncertain"},
+	}
+	for _, tt := range tests {
+		if got := c.confidenceLevel(tt.confidence); got != tt.expected {
+			t.Errorf("confidenceLevel(%f) = %q, want %q", tt.confidence, got, tt.expected)
+		}
+	}
+}
+
 func TestClassifyWithCustomPrompt_AppendsCustomPrompt(t *testing.T) {
 	mock := &mockCompleter{
 		responses: []string{`{"labels": ["bug"], "confidence": 0.9, "reasoning": "Bug report"}`},
@@ -650,6 +700,7 @@ func TestValidateLabels_AllUnknown(t *testing.T) {
 	result := validateLabels(
 		[]string{"security", "performance", "networking"},
 		testLabels,
+		nil,
 	)
 	if len(result) != 0 {
 		t.Errorf("expected 0 valid labels, got %d: %v", len(result), result)
@@ -657,15 +708,260 @@ func TestValidateLabels_AllUnknown(t *testing.T) {
 }
 
 func TestValidateLabels_Empty(t *testing.T) {
-	result := validateLabels([]string{}, testLabels)
+	result := validateLabels([]string{}, testLabels, nil)
 	if len(result) != 0 {
 		t.Errorf("expected 0 labels, got %d", len(result))
 	}
 }
 
 func TestValidateLabels_NilInput(t *testing.T) {
-	result := validateLabels(nil, testLabels)
+	result := validateLabels(nil, testLabels, nil)
 	if len(result) != 0 {
 		t.Errorf("expected 0 labels, got %d", len(result))
 	}
 }
+
+func TestClassifyTrace_RecordsEachExchange(t *testing.T) {
+	mock := &mockCompleter{
+		responses: []string{
+			"not valid json",
+			`{"labels": ["bug"], "confidence": 0.9, "reasoning": "Crash"}`,
+		},
+	}
+	c := NewClassifier(mock, 10*time.Second)
+
+	result, trace, err := c.ClassifyTrace(context.Background(), "owner/repo", testLabels, testIssue, Guidance{CustomPrompt: "Mobile app"})
+	if err != nil {
+		t.Fatalf("ClassifyTrace returned error: %v", err)
+	}
+	if len(result.Labels) != 1 || result.Labels[0].Name != "bug" {
+		t.Errorf("expected label 'bug', got %v", result.Labels)
+	}
+	if len(trace) != 2 {
+		t.Fatalf("expected 2 exchanges, got %d", len(trace))
+	}
+	if trace[0].Response != "not valid json" || !strings.HasSuffix(mock.lastPrompts[0], trace[0].Prompt) {
+		t.Errorf("unexpected first exchange: %+v", trace[0])
+	}
+	if !strings.Contains(trace[0].Prompt, "Additional context:\nMobile app") {
+		t.Error("expected custom prompt in the recorded prompt")
+	}
+	if !strings.HasSuffix(trace[1].Prompt, retryPromptSuffix) {
+		t.Error("expected the retry prompt to be recorded")
+	}
+}
+
+func TestClassifyTrace_RecordsError(t *testing.T) {
+	mock := &mockCompleter{err: provider.ErrTimeout}
+	c := NewClassifier(mock, 10*time.Second)
+
+	_, trace, err := c.ClassifyTrace(context.Background(), "owner/repo", testLabels, testIssue, Guidance{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(trace) != 1 || trace[0].Err == nil {
+		t.Errorf("expected the failed exchange to be recorded, got %+v", trace)
+	}
+}
+
+// contextLimitCompleter fails with ErrContextLength for prompts longer than
+// max runes.
+type contextLimitCompleter struct {
+	max     int
+	prompts []string
+}
+
+func (m *contextLimitCompleter) Complete(_ context.Context, prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	if len([]rune(prompt)) > m.max {
+		return "", fmt.Errorf("%w: prompt is too long", provider.ErrContextLength)
+	}
+	return `{"labels": ["bug"], "confidence": 0.9, "reasoning": "Crash report"}`, nil
+}
+
+func TestClassify_ContextLengthTruncatesBody(t *testing.T) {
+	issue := testIssue
+	issue.Body = strings.Repeat("stack frame ", 1000) // 12000 runes
+
+	mock := &contextLimitCompleter{max: 4000}
+	c := NewClassifier(mock, 10*time.Second)
+
+	result, err := c.Classify(context.Background(), "owner/repo", testLabels, issue)
+	if err != nil {
+		t.Fatalf("Classify returned error: %v", err)
+	}
+	if !result.Truncated {
+		t.Error("expected result to note the truncation")
+	}
+	if len(result.Labels) != 1 || result.Labels[0].Name != "bug" {
+		t.Errorf("expected [bug], got %v", result.Labels)
+	}
+	// Full body, then 8000 runes, then 2000 runes.
+	if len(mock.prompts) != 3 {
+		t.Fatalf("expected 3 prompts, got %d", len(mock.prompts))
+	}
+	if !strings.Contains(mock.prompts[2], truncationNote) {
+		t.Error("expected the final prompt to mark the truncation")
+	}
+}
+
+func TestClassify_ContextLengthShortBody(t *testing.T) {
+	mock := &contextLimitCompleter{max: 10}
+	c := NewClassifier(mock, 10*time.Second)
+
+	_, err := c.Classify(context.Background(), "owner/repo", testLabels, testIssue)
+	if !errors.Is(err, provider.ErrContextLength) {
+		t.Fatalf("expected ErrContextLength, got %v", err)
+	}
+	// The body is already under every limit, so there is nothing to retry.
+	if len(mock.prompts) != 1 {
+		t.Errorf("expected 1 prompt, got %d", len(mock.prompts))
+	}
+}
+
+// memCache is an in-memory Cache.
+type memCache map[string][]byte
+
+func (m memCache) GetClassification(model, hash string) ([]byte, bool, error) {
+	data, ok := m[model+"/"+hash]
+	return data, ok, nil
+}
+
+func (m memCache) PutClassification(model, hash string, result []byte) error {
+	m[model+"/"+hash] = result
+	return nil
+}
+
+func TestClassify_CacheReusesResult(t *testing.T) {
+	mock := &mockCompleter{
+		responses: []string{`{"labels": ["bug"], "confidence": 0.95, "reasoning": "crash"}`},
+	}
+	cache := memCache{}
+	c := NewClassifier(mock, 5*time.Second, WithCache(cache, "openai:gpt-4o"))
+	issue := github.Issue{Number: 1, Title: "App crashes", Body: "Segfault on start"}
+
+	first, err := c.Classify(context.Background(), "owner/repo", testLabels, issue)
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	second, err := c.Classify(context.Background(), "owner/repo", testLabels, issue)
+	if err != nil {
+		t.Fatalf("Classify again: %v", err)
+	}
+	if mock.callCount != 1 {
+		t.Errorf("expected 1 LLM call, got %d", mock.callCount)
+	}
+	if len(second.Labels) != 1 || second.Labels[0].Name != "bug" || second.Reasoning != first.Reasoning {
+		t.Errorf("cached result %+v differs from %+v", second, first)
+	}
+
+	// A changed issue is classified again
+	issue.Body = "Segfault on start, now with a stack trace"
+	if _, err := c.Classify(context.Background(), "owner/repo", testLabels, issue); err != nil {
+		t.Fatalf("Classify changed issue: %v", err)
+	}
+	if mock.callCount != 2 {
+		t.Errorf("expected a changed issue to reach the LLM, got %d calls", mock.callCount)
+	}
+
+	// So is the same issue under another model
+	other := NewClassifier(mock, 5*time.Second, WithCache(cache, "ollama:llama3"))
+	if _, err := other.Classify(context.Background(), "owner/repo", testLabels, issue); err != nil {
+		t.Fatalf("Classify with other model: %v", err)
+	}
+	if mock.callCount != 3 {
+		t.Errorf("expected the cache to be per model, got %d calls", mock.callCount)
+	}
+}
+
+func TestClassify_CacheSkipsFallbacks(t *testing.T) {
+	mock := &mockCompleter{responses: []string{"not json"}}
+	cache := memCache{}
+	c := NewClassifier(mock, 5*time.Second, WithCache(cache, "openai:gpt-4o"))
+	issue := github.Issue{Number: 1, Title: "Something", Body: "Unclear"}
+
+	result, err := c.Classify(context.Background(), "owner/repo", testLabels, issue)
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if result.ConfidenceLevel != "uncertain" {
+		t.Fatalf("expected the uncertain fallback, got %q", result.ConfidenceLevel)
+	}
+	if len(cache) != 0 {
+		t.Errorf("expected the fallback not to be cached, got %d entries", len(cache))
+	}
+}
+
+func TestClassifyTrace_BypassesCache(t *testing.T) {
+	mock := &mockCompleter{
+		responses: []string{`{"labels": ["bug"], "confidence": 0.95, "reasoning": "crash"}`},
+	}
+	c := NewClassifier(mock, 5*time.Second, WithCache(memCache{}, "openai:gpt-4o"))
+	issue := github.Issue{Number: 1, Title: "App crashes", Body: "Segfault"}
+
+	for range 2 {
+		if _, trace, err := c.ClassifyTrace(context.Background(), "owner/repo", testLabels, issue, Guidance{}); err != nil || len(trace) != 1 {
+			t.Fatalf("ClassifyTrace: %v, %d exchanges", err, len(trace))
+		}
+	}
+	if mock.callCount != 2 {
+		t.Errorf("expected traced runs to reach the LLM, got %d calls", mock.callCount)
+	}
+}
+
+// systemCompleter records the system prompt of each completion.
+type systemCompleter struct {
+	systems []string
+}
+
+func (s *systemCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	return s.CompleteRequest(ctx, provider.CompletionRequest{Prompt: prompt})
+}
+
+func (s *systemCompleter) CompleteRequest(_ context.Context, req provider.CompletionRequest) (string, error) {
+	s.systems = append(s.systems, req.System)
+	return `{"labels": ["bug"], "confidence": 0.9, "reasoning": "crash"}`, nil
+}
+
+func TestClassify_SendsSystemPrompt(t *testing.T) {
+	sc := &systemCompleter{}
+	c := NewClassifier(sc, 5*time.Second)
+	if _, err := c.Classify(context.Background(), "owner/repo", testLabels, testIssue); err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if len(sc.systems) != 1 || sc.systems[0] != classifySystemPrompt {
+		t.Errorf("system prompts = %q, want the classification system prompt", sc.systems)
+	}
+
+	// Completers without a system role get it ahead of the prompt
+	mock := &mockCompleter{responses: []string{`{"labels": ["bug"], "confidence": 0.9, "reasoning": "crash"}`}}
+	c = NewClassifier(mock, 5*time.Second)
+	if _, err := c.Classify(context.Background(), "owner/repo", testLabels, testIssue); err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if len(mock.lastPrompts) != 1 || !strings.HasPrefix(mock.lastPrompts[0], classifySystemPrompt+"\n\n") {
+		t.Errorf("expected the system prompt ahead of the prompt, got %q", mock.lastPrompts)
+	}
+}
+
+func TestClassify_Language(t *testing.T) {
+	mock := &mockCompleter{
+		responses: []string{`{"labels": ["bug"], "confidence": 0.9, "reasoning": "Absturz beim Start"}`},
+	}
+	c := NewClassifier(mock, 5*time.Second, WithLanguage("de"))
+
+	if _, err := c.Classify(context.Background(), "owner/repo", testLabels, testIssue); err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if !strings.Contains(mock.lastPrompts[0], "Write the reasoning in German") {
+		t.Errorf("expected the language in the prompt, got:\n%s", mock.lastPrompts[0])
+	}
+
+	// A repo's guidance overrides the default
+	if _, err := c.ClassifyWithGuidance(context.Background(), "owner/repo", testLabels, testIssue, Guidance{Language: "fr"}); err != nil {
+		t.Fatalf("ClassifyWithGuidance: %v", err)
+	}
+	if !strings.Contains(mock.lastPrompts[1], "Write the reasoning in French") {
+		t.Errorf("expected the guidance's language in the prompt, got:\n%s", mock.lastPrompts[1])
+	}
+}
@@ -0,0 +1,88 @@
+package classify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/provider"
+)
+
+var otherIssue = github.Issue{
+	Number: 48,
+	Title:  "Crash when launching the app",
+	Body:   "Segfault immediately after opening.",
+}
+
+func TestBuildComparePrompt_IncludesBothIssues(t *testing.T) {
+	prompt, err := BuildComparePrompt("owner/repo", testIssue, otherIssue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"owner/repo", "Issue #42: App crashes on startup", "Issue #48: Crash when launching the app", "<issue_a>", "<issue_b>"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestBuildComparePrompt_EmptyRepo(t *testing.T) {
+	if _, err := BuildComparePrompt("", testIssue, otherIssue); err == nil {
+		t.Fatal("expected error for empty repo")
+	}
+}
+
+func TestJudgeDuplicate_ValidJSON(t *testing.T) {
+	mock := &mockCompleter{
+		responses: []string{"```json\n{\"duplicate\": true, \"confidence\": 1.4, \"reasoning\": \"Same crash\"}\n```"},
+	}
+	c := NewClassifier(mock, 10*time.Second)
+
+	j, err := c.JudgeDuplicate(context.Background(), "owner/repo", testIssue, otherIssue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !j.Duplicate {
+		t.Error("expected duplicate verdict")
+	}
+	if j.Confidence != 1 {
+		t.Errorf("expected confidence clamped to 1, got %f", j.Confidence)
+	}
+	if j.Reasoning != "Same crash" {
+		t.Errorf("unexpected reasoning %q", j.Reasoning)
+	}
+}
+
+func TestJudgeDuplicate_RetriesMalformedResponse(t *testing.T) {
+	mock := &mockCompleter{
+		responses: []string{"not json", `{"duplicate": false, "confidence": 0.6, "reasoning": "Different"}`},
+	}
+	c := NewClassifier(mock, 10*time.Second)
+
+	j, err := c.JudgeDuplicate(context.Background(), "owner/repo", testIssue, otherIssue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if j.Duplicate {
+		t.Error("expected not-duplicate verdict")
+	}
+	if mock.callCount != 2 {
+		t.Errorf("expected 2 calls, got %d", mock.callCount)
+	}
+	if !strings.Contains(mock.lastPrompts[1], "IMPORTANT") {
+		t.Error("expected retry prompt to include strict suffix")
+	}
+}
+
+func TestJudgeDuplicate_MalformedAfterRetry(t *testing.T) {
+	mock := &mockCompleter{responses: []string{"nope"}}
+	c := NewClassifier(mock, 10*time.Second)
+
+	_, err := c.JudgeDuplicate(context.Background(), "owner/repo", testIssue, otherIssue)
+	if !errors.Is(err, provider.ErrInvalidResponse) {
+		t.Fatalf("expected ErrInvalidResponse, got %v", err)
+	}
+}
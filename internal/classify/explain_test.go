@@ -0,0 +1,74 @@
+package classify
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/provider"
+)
+
+func TestBuildExplainPrompt_IncludesBothIssues(t *testing.T) {
+	prompt, err := BuildExplainPrompt("owner/repo", testIssue, otherIssue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"<original_issue>\nTitle: Issue #42: App crashes on startup", "<new_report>\nTitle: Issue #48: Crash when launching the app"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if _, err := BuildExplainPrompt("", testIssue, otherIssue); err == nil {
+		t.Error("expected error for empty repo")
+	}
+}
+
+func TestExplainDuplicate(t *testing.T) {
+	mock := &mockCompleter{responses: []string{
+		`{"overlap": " Both report a crash at launch. ", "new_details": ["Segfault", " ", "1", "2", "3", "4", "5"]}`,
+	}}
+	c := NewClassifier(mock, 10*time.Second)
+
+	e, err := c.ExplainDuplicate(context.Background(), "owner/repo", testIssue, otherIssue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Overlap != "Both report a crash at launch." {
+		t.Errorf("Overlap = %q", e.Overlap)
+	}
+	if want := []string{"Segfault", "1", "2", "3", "4"}; !reflect.DeepEqual(e.NewDetails, want) {
+		t.Errorf("NewDetails = %q, want %q", e.NewDetails, want)
+	}
+}
+
+func TestExplainDuplicate_RetriesMissingOverlap(t *testing.T) {
+	mock := &mockCompleter{responses: []string{
+		`{"new_details": []}`,
+		`{"overlap": "Same crash", "new_details": []}`,
+	}}
+	c := NewClassifier(mock, 10*time.Second)
+
+	e, err := c.ExplainDuplicate(context.Background(), "owner/repo", testIssue, otherIssue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Overlap != "Same crash" || e.NewDetails != nil {
+		t.Errorf("explanation = %+v", e)
+	}
+	if mock.callCount != 2 || !strings.Contains(mock.lastPrompts[1], "IMPORTANT") {
+		t.Error("expected one retry with the strict suffix")
+	}
+}
+
+func TestExplainDuplicate_MalformedAfterRetry(t *testing.T) {
+	mock := &mockCompleter{responses: []string{"nope"}}
+	c := NewClassifier(mock, 10*time.Second)
+
+	_, err := c.ExplainDuplicate(context.Background(), "owner/repo", testIssue, otherIssue)
+	if !errors.Is(err, provider.ErrInvalidResponse) {
+		t.Fatalf("expected ErrInvalidResponse, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package classify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/config"
+)
+
+// manyLabels returns a label set spanning the "area", "type", and "general"
+// categories.
+func manyLabels() []config.LabelConfig {
+	labels := []config.LabelConfig{
+		{Name: "crash", Description: "Exits unexpectedly", Category: "type"},
+		{Name: "type: feature", Description: "New feature request"},
+		{Name: "question", Description: "Needs an answer"},
+	}
+	for i := range 10 {
+		labels = append(labels, config.LabelConfig{Name: fmt.Sprintf("area/part%d", i)})
+	}
+	return labels
+}
+
+func TestLabelCategory(t *testing.T) {
+	tests := []struct {
+		label config.LabelConfig
+		want  string
+	}{
+		{config.LabelConfig{Name: "area/cli"}, "area"},
+		{config.LabelConfig{Name: "type: bug"}, "type"},
+		{config.LabelConfig{Name: "bug"}, defaultCategory},
+		{config.LabelConfig{Name: "area/cli", Category: "tools"}, "tools"},
+		{config.LabelConfig{Name: "/odd"}, defaultCategory},
+	}
+	for _, tt := range tests {
+		if got := labelCategory(tt.label); got != tt.want {
+			t.Errorf("labelCategory(%+v) = %q, want %q", tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestBuildCategoryPrompt(t *testing.T) {
+	issue := testIssue
+	issue.Body = strings.Repeat("x", categoryBodyLimit+100)
+	prompt, err := BuildCategoryPrompt("owner/repo", manyLabels(), issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"- type: crash, type: feature",
+		"- general: question",
+		"- area: area/part0, area/part1, area/part2, area/part3, area/part4, area/part5, area/part6, area/part7, and 2 more",
+		truncationNote,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestClassify_MultiPassNarrowsLabels(t *testing.T) {
+	mock := &mockCompleter{responses: []string{
+		`{"categories": ["Type"], "reasoning": "A crash report"}`,
+		`{"labels": ["crash"], "confidence": 0.9, "reasoning": "Crashes on startup"}`,
+	}}
+	c := NewClassifier(mock, 10*time.Second, WithMultiPass(5))
+
+	result, err := c.Classify(context.Background(), "owner/repo", manyLabels(), testIssue)
+	if err != nil {
+		t.Fatalf("Classify returned error: %v", err)
+	}
+	if len(result.Labels) != 1 || result.Labels[0].Name != "crash" {
+		t.Errorf("labels = %+v, want crash", result.Labels)
+	}
+	if mock.callCount != 2 {
+		t.Fatalf("callCount = %d, want 2", mock.callCount)
+	}
+	second := mock.lastPrompts[1]
+	if !strings.Contains(second, "- crash:") || !strings.Contains(second, "- type: feature:") {
+		t.Errorf("second prompt missing the chosen category's labels:\n%s", second)
+	}
+	if strings.Contains(second, "area/part0") || strings.Contains(second, "question") {
+		t.Errorf("second prompt includes labels outside the chosen category:\n%s", second)
+	}
+}
+
+func TestClassify_MultiPassFallsBackToAllLabels(t *testing.T) {
+	mock := &mockCompleter{responses: []string{
+		`not json`,
+		`{"categories": ["nonexistent"]}`,
+		`{"labels": ["question"], "confidence": 0.8, "reasoning": "A question"}`,
+	}}
+	c := NewClassifier(mock, 10*time.Second, WithMultiPass(5))
+
+	result, err := c.Classify(context.Background(), "owner/repo", manyLabels(), testIssue)
+	if err != nil {
+		t.Fatalf("Classify returned error: %v", err)
+	}
+	if len(result.Labels) != 1 || result.Labels[0].Name != "question" {
+		t.Errorf("labels = %+v, want question", result.Labels)
+	}
+	if !strings.Contains(mock.lastPrompts[1], categoryRetrySuffix) {
+		t.Error("malformed first-pass response was not retried with the stricter prompt")
+	}
+	if last := mock.lastPrompts[2]; !strings.Contains(last, "area/part9") || !strings.Contains(last, "- crash:") {
+		t.Errorf("fallback prompt does not list every label:\n%s", last)
+	}
+}
+
+func TestClassify_MultiPassBelowThreshold(t *testing.T) {
+	mock := &mockCompleter{responses: []string{
+		`{"labels": ["crash"], "confidence": 0.9, "reasoning": "Crashes"}`,
+	}}
+	c := NewClassifier(mock, 10*time.Second, WithMultiPass(len(manyLabels())))
+
+	if _, err := c.Classify(context.Background(), "owner/repo", manyLabels(), testIssue); err != nil {
+		t.Fatal(err)
+	}
+	if mock.callCount != 1 {
+		t.Errorf("callCount = %d, want a single pass", mock.callCount)
+	}
+}
+
+func TestClassifyTrace_RecordsBothPasses(t *testing.T) {
+	mock := &mockCompleter{responses: []string{
+		`{"categories": ["area"]}`,
+		`{"labels": ["area/part3"], "confidence": 0.9, "reasoning": "Part 3"}`,
+	}}
+	c := NewClassifier(mock, 10*time.Second, WithMultiPass(5))
+
+	_, trace, err := c.ClassifyTrace(context.Background(), "owner/repo", manyLabels(), testIssue, Guidance{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != 2 || !strings.Contains(trace[0].Prompt, "grouped into these categories") {
+		t.Errorf("trace = %+v, want the category pass then classification", trace)
+	}
+}
@@ -217,3 +217,127 @@ func TestBuildPromptWithCustom_ValidationErrors(t *testing.T) {
 		t.Error("expected error for no labels")
 	}
 }
+
+func TestBuildPromptWithGuidance_Examples(t *testing.T) {
+	labels := []config.LabelConfig{{Name: "sync", Description: "Sync"}, {Name: "network", Description: "Network"}}
+	issue := github.Issue{Number: 7, Title: "Uploads hang", Body: "On hotel wifi"}
+	guidance := Guidance{
+		CustomPrompt: "Desktop app",
+		Examples: []config.Example{{
+			Title:       "Sync stalls on metered connections",
+			BodyExcerpt: "Nothing uploads while tethered.",
+			Labels:      []string{"sync", "network"},
+		}},
+	}
+
+	prompt, err := BuildPromptWithGuidance("owner/repo", labels, issue, guidance)
+	if err != nil {
+		t.Fatalf("BuildPromptWithGuidance returned error: %v", err)
+	}
+	example := "<example>\nTitle: Sync stalls on metered connections\nBody: Nothing uploads while tethered.\nLabels: sync, network\n</example>"
+	if !strings.Contains(prompt, example) {
+		t.Errorf("prompt missing example:\n%s", prompt)
+	}
+	if strings.Index(prompt, example) > strings.Index(prompt, "<issue_content>") {
+		t.Error("examples should come before the issue")
+	}
+	if !strings.HasSuffix(prompt, "Additional context:\nDesktop app") {
+		t.Error("custom prompt should still be appended")
+	}
+
+	base, _ := BuildPromptWithCustom("owner/repo", labels, issue, "Desktop app")
+	if strings.Contains(base, "Examples of how issues") {
+		t.Error("prompt without examples should not have an examples section")
+	}
+}
+
+func TestBuildPromptWithGuidance_Language(t *testing.T) {
+	labels := []config.LabelConfig{{Name: "bug", Description: "Broken"}}
+	issue := github.Issue{Number: 1, Title: "Crash", Body: "It crashes"}
+
+	prompt, err := BuildPromptWithGuidance("owner/repo", labels, issue, Guidance{Language: "de"})
+	if err != nil {
+		t.Fatalf("BuildPromptWithGuidance returned error: %v", err)
+	}
+	want := "- Provide brief reasoning (1-2 sentences)\n- Write the reasoning in German, but give label names exactly as listed above\n"
+	if !strings.Contains(prompt, want) {
+		t.Errorf("prompt missing language rule:\n%s", prompt)
+	}
+
+	english, _ := BuildPromptWithGuidance("owner/repo", labels, issue, Guidance{Language: "en"})
+	plain, _ := BuildPrompt("owner/repo", labels, issue)
+	if english != plain {
+		t.Error("English should not add a language rule")
+	}
+	if !strings.Contains(plain, "- Provide brief reasoning (1-2 sentences)\n\nNote:") {
+		t.Errorf("prompt without a language changed shape:\n%s", plain)
+	}
+}
+
+func TestBuildPromptWithGuidance_LocalizedLabels(t *testing.T) {
+	labels := []config.LabelConfig{
+		{Name: "bug", Description: "Broken", Descriptions: map[string]string{"de": "Kaputt"}},
+		{Name: "docs", Description: "Documentation"},
+	}
+	issue := github.Issue{Number: 1, Title: "Absturz", Body: "Es stürzt ab"}
+
+	prompt, err := BuildPromptWithGuidance("owner/repo", labels, issue, Guidance{Language: "de-CH"})
+	if err != nil {
+		t.Fatalf("BuildPromptWithGuidance returned error: %v", err)
+	}
+	for _, want := range []string{"- bug: Kaputt\n", "- docs: Documentation\n", "Write the reasoning in German (de-CH)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if labels[0].Description != "Broken" {
+		t.Error("expected the caller's labels to be left alone")
+	}
+
+	plain, _ := BuildPrompt("owner/repo", labels, issue)
+	if !strings.Contains(plain, "- bug: Broken\n") {
+		t.Errorf("expected the default description without a language:\n%s", plain)
+	}
+}
+
+func TestBuildPromptWithGuidance_RepoMetadata(t *testing.T) {
+	labels := []config.LabelConfig{{Name: "bug", Description: "Broken"}}
+	issue := github.Issue{Number: 1, Title: "Crash", Body: "It crashes"}
+
+	prompt, err := BuildPromptWithGuidance("owner/engine", labels, issue, Guidance{
+		Description:  "A 2D game engine ",
+		Topics:       []string{"gamedev", "rust"},
+		RepoLanguage: "Rust",
+	})
+	if err != nil {
+		t.Fatalf("BuildPromptWithGuidance returned error: %v", err)
+	}
+	want := "Repository: owner/engine\nRepository description: A 2D game engine\nRepository topics: gamedev, rust\nPrimary language: Rust\n\nClassify"
+	if !strings.Contains(prompt, want) {
+		t.Errorf("prompt missing repo metadata:\n%s", prompt)
+	}
+
+	plain, _ := BuildPrompt("owner/engine", labels, issue)
+	if !strings.Contains(plain, "Repository: owner/engine\n\nClassify") {
+		t.Errorf("prompt without metadata changed shape:\n%s", plain)
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	tests := map[string]string{
+		"de":      "German",
+		"DE":      "German",
+		"pt-BR":   "Portuguese (pt-BR)",
+		"zh_tw":   "Chinese (zh-TW)",
+		"Klingon": "Klingon",
+		"en":      "",
+		"en-GB":   "",
+		"English": "",
+		"":        "",
+	}
+	for in, want := range tests {
+		if got := languageName(in); got != want {
+			t.Errorf("languageName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jacklau/triage/internal/secrets"
+)
+
+// Check performs deeper checks than the validation done by Parse: webhook
+// URL formats, GitHub App settings, and the existence of referenced files.
+// It returns every problem found rather than stopping at the first.
+func Check(cfg *Config) []error {
+	problems := CheckWebhooks(cfg)
+
+	for _, p := range []struct {
+		key string
+		pc  ProviderConfig
+	}{
+		{"providers.embedding", cfg.Providers.Embedding},
+		{"providers.llm", cfg.Providers.LLM},
+	} {
+		if p.pc.URL == "" {
+			continue
+		}
+		if err := checkURL(p.pc.URL); err != nil {
+			problems = append(problems, fmt.Errorf("%s.url: %w", p.key, err))
+		}
+	}
+
+	if u := cfg.Integrations.Jira.URL; u != "" {
+		if err := checkURL(u); err != nil {
+			problems = append(problems, fmt.Errorf("integrations.jira.url: %w", err))
+		}
+	}
+
+	switch cfg.GitHub.Auth {
+	case "app":
+		if _, err := strconv.ParseInt(cfg.GitHub.AppID, 10, 64); err != nil {
+			problems = append(problems, fmt.Errorf("github.app_id: must be a number, got %q", cfg.GitHub.AppID))
+		}
+		if _, err := strconv.ParseInt(cfg.GitHub.InstallationID, 10, 64); err != nil {
+			problems = append(problems, fmt.Errorf("github.installation_id: must be a number, got %q", cfg.GitHub.InstallationID))
+		}
+		if cfg.GitHub.PrivateKey == "" {
+			if cfg.GitHub.PrivateKeyPath == "" {
+				problems = append(problems, fmt.Errorf("github: one of private_key or private_key_path is required for app auth"))
+			} else if _, err := os.Stat(expandTilde(cfg.GitHub.PrivateKeyPath)); err != nil {
+				problems = append(problems, fmt.Errorf("github.private_key_path: %w", err))
+			}
+		}
+	case "", "token":
+	default:
+		problems = append(problems, fmt.Errorf("github.auth: must be app or token, got %q", cfg.GitHub.Auth))
+	}
+
+	return problems
+}
+
+// webhookPaths are the path prefixes of webhook URLs on Slack's and
+// Discord's own hosts. URLs on other hosts, such as a relay, are only
+// checked to be URLs.
+var webhookPaths = map[string][]string{
+	"hooks.slack.com": {"/services/", "/workflows/", "/triggers/"},
+	"discord.com":     {"/api/webhooks/"},
+	"discordapp.com":  {"/api/webhooks/"},
+}
+
+// Webhook is a configured notification webhook URL.
+type Webhook struct {
+	// Key is its config key, such as "notify.slack_webhook".
+	Key string
+	URL string
+}
+
+// Webhooks returns the notification webhook URLs the config sets.
+func (c *Config) Webhooks() []Webhook {
+	var webhooks []Webhook
+	for _, wh := range []Webhook{
+		{"notify.slack_webhook", c.Notify.SlackWebhook},
+		{"notify.discord_webhook", c.Notify.DiscordWebhook},
+	} {
+		if wh.URL != "" {
+			webhooks = append(webhooks, wh)
+		}
+	}
+	return webhooks
+}
+
+// CheckWebhooks checks the shape of the notification webhook URLs: each
+// must be an absolute http(s) URL, and one on Slack's or Discord's host an
+// https URL with the path of a webhook there. Unresolved secret references
+// are skipped.
+func CheckWebhooks(cfg *Config) []error {
+	var problems []error
+	for _, wh := range cfg.Webhooks() {
+		if secrets.IsRef(wh.URL) {
+			continue
+		}
+		if err := checkWebhookURL(wh.URL); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", wh.Key, err))
+		}
+	}
+	return problems
+}
+
+func checkWebhookURL(s string) error {
+	if err := checkURL(s); err != nil {
+		return err
+	}
+	u, _ := url.Parse(s)
+	prefixes, ok := webhookPaths[strings.ToLower(u.Hostname())]
+	if !ok {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%s webhooks must use https, got %q", u.Hostname(), s)
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(u.Path, prefix) && len(u.Path) > len(prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("not a %s webhook URL: the path should start with %s", u.Hostname(), strings.Join(prefixes, " or "))
+}
+
+// checkURL reports whether s is an absolute http(s) URL.
+func checkURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("URL must use http or https, got %q", s)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL has no host: %q", s)
+	}
+	return nil
+}
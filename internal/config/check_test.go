@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckValidConfig(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{
+		GitHub: GitHubConfig{Auth: "app", AppID: "1", InstallationID: "2", PrivateKeyPath: keyPath},
+		Notify: NotifyConfig{
+			SlackWebhook:   "https://hooks.slack.com/services/x",
+			DiscordWebhook: "https://discord.com/api/webhooks/1/x",
+		},
+		Providers: ProvidersConfig{Embedding: ProviderConfig{Type: "ollama", URL: "http://localhost:11434"}},
+	}
+	if problems := Check(cfg); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
+
+func TestCheckReportsAllProblems(t *testing.T) {
+	cfg := &Config{
+		GitHub: GitHubConfig{
+			Auth:           "app",
+			AppID:          "abc",
+			InstallationID: "2",
+			PrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem"),
+		},
+		Notify: NotifyConfig{
+			SlackWebhook:   "hooks.slack.com/services/x",
+			DiscordWebhook: "ftp://discord.com/x",
+		},
+		Providers: ProvidersConfig{LLM: ProviderConfig{URL: "http://"}},
+	}
+
+	problems := Check(cfg)
+	var msgs []string
+	for _, p := range problems {
+		msgs = append(msgs, p.Error())
+	}
+	joined := strings.Join(msgs, "\n")
+	for _, want := range []string{"notify.slack_webhook", "notify.discord_webhook", "providers.llm.url", "github.app_id", "github.private_key_path"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected a problem mentioning %s, got:\n%s", want, joined)
+		}
+	}
+	if len(problems) != 5 {
+		t.Errorf("expected 5 problems, got %d:\n%s", len(problems), joined)
+	}
+}
+
+func TestCheckUnknownAuth(t *testing.T) {
+	problems := Check(&Config{GitHub: GitHubConfig{Auth: "oauth"}})
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "github.auth") {
+		t.Errorf("expected github.auth problem, got %v", problems)
+	}
+}
+
+func TestCheckSkipsSecretReferences(t *testing.T) {
+	cfg := &Config{Notify: NotifyConfig{SlackWebhook: "vault://secret/slack#webhook"}}
+	if problems := Check(cfg); len(problems) != 0 {
+		t.Errorf("expected secret references to be skipped, got %v", problems)
+	}
+}
+
+func TestCheckWebhooks(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://hooks.slack.com/services/T0/B0/x", ""},
+		{"https://hooks.slack.com/workflows/T0/A0/1/x", ""},
+		{"https://relay.example.com/slack", ""},
+		{"http://127.0.0.1:8080/hook", ""},
+		{"https://hooks.slack.com/T0/B0/x", "path should start with /services/"},
+		{"https://hooks.slack.com/services/", "path should start with"},
+		{"http://hooks.slack.com/services/T0/B0/x", "must use https"},
+		{"https://discord.com/webhooks/1/x", "path should start with /api/webhooks/"},
+		{"slack.com/services/x", "must use http or https"},
+	}
+	for _, tt := range tests {
+		problems := CheckWebhooks(&Config{Notify: NotifyConfig{SlackWebhook: tt.url}})
+		if tt.want == "" {
+			if len(problems) != 0 {
+				t.Errorf("%s: expected no problems, got %v", tt.url, problems)
+			}
+			continue
+		}
+		if len(problems) != 1 || !strings.Contains(problems[0].Error(), tt.want) || !strings.Contains(problems[0].Error(), "notify.slack_webhook") {
+			t.Errorf("%s: expected a notify.slack_webhook problem mentioning %q, got %v", tt.url, tt.want, problems)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -9,8 +14,14 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/jacklau/triage/internal/auth"
 )
 
+// ErrNotConfigured is returned, wrapped, when a command or component needs
+// a setting that is missing from the config, such as github.auth.
+var ErrNotConfigured = errors.New("not configured")
+
 // Config is the top-level configuration.
 type Config struct {
 	GitHub    GitHubConfig    `yaml:"github"`
@@ -18,7 +29,44 @@ type Config struct {
 	Notify    NotifyConfig    `yaml:"notify"`
 	Defaults  DefaultsConfig  `yaml:"defaults"`
 	Store     StoreConfig     `yaml:"store"`
+	Server    ServerConfig    `yaml:"server"`
 	Repos     []RepoConfig    `yaml:"repos"`
+	// OrgDefaults holds settings for all repos of an owner, keyed by the
+	// org or user name. Repo entries override them field by field.
+	OrgDefaults map[string]RepoSettings `yaml:"org_defaults"`
+	// Exclude lists owner/repo glob patterns for repos that OrgDefaults and
+	// pattern entries in Repos should not cover.
+	Exclude []string      `yaml:"exclude"`
+	Secrets SecretsConfig `yaml:"secrets"`
+	// Integrations configures syncing triaged issues to other trackers.
+	Integrations IntegrationsConfig `yaml:"integrations"`
+	// Experiment classifies a share of issues with an alternate prompt or
+	// model for comparison.
+	Experiment ExperimentConfig `yaml:"experiment"`
+	// SLA sets how long triaged issues may wait for a human response
+	// before serve sends a reminder.
+	SLA SLAConfig `yaml:"sla"`
+	// Logging sets the log level, format, and destination.
+	Logging LoggingConfig `yaml:"logging"`
+	// Pipeline tunes how watch processes issue events.
+	Pipeline PipelineConfig `yaml:"pipeline"`
+	// Sources adds event sources to watch besides the GitHub poller.
+	Sources []SourceConfig `yaml:"sources"`
+	// Aliases maps label names the LLM may use, such as "defect", to the
+	// configured label they stand for, such as "bug".
+	Aliases map[string]string `yaml:"aliases"`
+	// Packs defines named label sets that repos use with label_packs. A
+	// pack defined here replaces a built-in pack of the same name.
+	Packs map[string][]LabelConfig `yaml:"packs"`
+
+	// SecretRefs maps the YAML path of each value resolved by
+	// ResolveSecrets to its original reference.
+	SecretRefs map[string]string `yaml:"-"`
+
+	// UnknownKeys describes each key in the parsed YAML that does not
+	// correspond to a config field, such as a misspelled option. It is only
+	// set by Parse; ParseStrict rejects such keys instead.
+	UnknownKeys []string `yaml:"-"`
 }
 
 // GitHubConfig holds GitHub authentication settings.
@@ -28,6 +76,16 @@ type GitHubConfig struct {
 	InstallationID string `yaml:"installation_id"`
 	PrivateKeyPath string `yaml:"private_key_path"`
 	PrivateKey     string `yaml:"private_key"`
+	Token          string `yaml:"token"`
+}
+
+// SourceConfig configures an event source. Type names a registered source,
+// such as "jsonl", whose Path is a file of events; Options holds settings
+// for custom sources.
+type SourceConfig struct {
+	Type    string            `yaml:"type"`
+	Path    string            `yaml:"path"`
+	Options map[string]string `yaml:"options"`
 }
 
 // ProviderConfig holds settings for a single provider (embedding or LLM).
@@ -36,6 +94,20 @@ type ProviderConfig struct {
 	Model  string `yaml:"model"`
 	APIKey string `yaml:"api_key"`
 	URL    string `yaml:"url"`
+	// CostPerMTok is the price in USD per million tokens, used to estimate
+	// spend for scan --budget. Zero means free (e.g. a local model).
+	CostPerMTok float64 `yaml:"cost_per_mtok"`
+	// MaxTokens caps each completion's length and StopSequences end it
+	// early. Only the anthropic LLM provider uses them.
+	MaxTokens     int      `yaml:"max_tokens"`
+	StopSequences []string `yaml:"stop_sequences"`
+	// DocumentPrefix and QueryPrefix are task prefixes the embedding model
+	// expects, such as "search_document: " and "search_query: " for
+	// nomic-embed-text. DocumentPrefix is prepended to stored issues and
+	// QueryPrefix to the issue compared against them. Only the embedding
+	// provider uses them.
+	DocumentPrefix string `yaml:"document_prefix"`
+	QueryPrefix    string `yaml:"query_prefix"`
 }
 
 // ProvidersConfig groups embedding and LLM provider configs.
@@ -48,6 +120,52 @@ type ProvidersConfig struct {
 type NotifyConfig struct {
 	SlackWebhook   string `yaml:"slack_webhook"`
 	DiscordWebhook string `yaml:"discord_webhook"`
+	// SlackBotToken and SlackChannel post to Slack as a bot instead of
+	// through the webhook, so follow-ups about an issue are threaded under
+	// its first message.
+	SlackBotToken string `yaml:"slack_bot_token"`
+	SlackChannel  string `yaml:"slack_channel"`
+	// DiscordForum creates a forum post per issue, titled with the issue's
+	// title, instead of posting to discord_webhook's channel. The webhook
+	// must belong to a forum channel.
+	DiscordForum bool `yaml:"discord_forum"`
+	// Emoji prefixes notification titles by the result's severity.
+	Emoji NotifyEmojiConfig `yaml:"emoji"`
+	// Mentions maps a label to who is pinged when it is suggested.
+	Mentions map[string]Mention `yaml:"mentions"`
+	// Language is the language the LLM writes its reasoning in, as a code
+	// such as "de" or a name such as "German". Labels stay as configured.
+	Language string `yaml:"language"`
+}
+
+// Mention is who to ping on each chat platform, in that platform's syntax:
+// "<!subteam^S0123>" for a Slack user group, "<@&123>" for a Discord role.
+// A plain string is used for both.
+type Mention struct {
+	Slack   string `yaml:"slack"`
+	Discord string `yaml:"discord"`
+}
+
+// UnmarshalYAML accepts a string, used for both platforms, or a mapping
+// with slack and discord keys.
+func (m *Mention) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		m.Slack = value.Value
+		m.Discord = value.Value
+		return nil
+	}
+	type plain Mention
+	return value.Decode((*plain)(m))
+}
+
+// NotifyEmojiConfig holds the title prefix for each notification severity:
+// high for likely duplicates and issues the classifier could not label,
+// medium for low-confidence labels and transfer suggestions, low for the
+// rest. Empty means no prefix.
+type NotifyEmojiConfig struct {
+	High   string `yaml:"high"`
+	Medium string `yaml:"medium"`
+	Low    string `yaml:"low"`
 }
 
 // DefaultsConfig holds default operational parameters.
@@ -57,26 +175,191 @@ type DefaultsConfig struct {
 	ConfidenceThreshold float64 `yaml:"confidence_threshold"`
 	MaxDuplicatesShown  int     `yaml:"max_duplicates_shown"`
 	EmbedMaxTokens      int     `yaml:"embed_max_tokens"`
-	RequestTimeoutRaw   string  `yaml:"request_timeout"`
+	// RequestTimeoutRaw bounds each embedding and LLM request.
+	RequestTimeoutRaw string `yaml:"request_timeout"`
+	// ConfidenceLevels are the cutoffs for reporting a classification as
+	// "suggested" or "possible"; anything lower is "uncertain".
+	ConfidenceLevels ConfidenceLevelsConfig `yaml:"confidence_levels"`
+	// Abstain suggests no labels when the classifier's confidence is below
+	// ConfidenceThreshold, flagging the issue for human triage instead.
+	Abstain bool `yaml:"abstain"`
+	// RemoteConfig reads per-repo settings from each repo's
+	// .github/triage.yml, refetched after RemoteConfigTTLRaw.
+	RemoteConfig       bool   `yaml:"remote_config"`
+	RemoteConfigTTLRaw string `yaml:"remote_config_ttl"`
+	// ReconcileIntervalRaw is how often watch checks that stored issues
+	// still exist on GitHub, tombstoning those deleted, transferred, or
+	// converted to a discussion. "0" disables the check.
+	ReconcileIntervalRaw string `yaml:"reconcile_interval"`
+	// SkipIfLabeled skips classification, but not dedup, for issues that
+	// already carry one of the repo's configured labels.
+	SkipIfLabeled bool `yaml:"skip_if_labeled"`
+	// TransferSuggestions compares new issues with the owner's other
+	// tracked repos and suggests a transfer when one matches better.
+	TransferSuggestions bool `yaml:"transfer_suggestions"`
+	// RetriageCommands are comment commands, such as "/triage", that make
+	// watch reprocess an issue when a maintainer posts one. Empty disables
+	// re-triage by comment.
+	RetriageCommands []string `yaml:"retriage_commands"`
+	// ParallelClassify classifies issues while dedup runs instead of after
+	// it, classifying duplicates too. This roughly halves latency for
+	// issues that are not duplicates.
+	ParallelClassify bool `yaml:"parallel_classify"`
+	// EmbeddingCache keeps decoded embeddings in memory between duplicate
+	// checks; watch loads them for its repos at startup.
+	EmbeddingCache bool `yaml:"embedding_cache"`
+	// MatchPullRequests also compares issues with open pull requests and
+	// reports those that may already address them.
+	MatchPullRequests bool `yaml:"match_pull_requests"`
+	// MultiPassThreshold is the label count above which classification
+	// first picks label categories, then classifies among their labels.
+	// Zero uses the classifier's default; negative always uses one pass.
+	MultiPassThreshold int `yaml:"multi_pass_threshold"`
+}
+
+// ConfidenceLevelsConfig holds the lowest classifier confidence reported at
+// each level. Suitable cutoffs vary by model.
+type ConfidenceLevelsConfig struct {
+	Suggested float64 `yaml:"suggested"`
+	Possible  float64 `yaml:"possible"`
 }
 
 // StoreConfig holds storage settings.
 type StoreConfig struct {
 	Path string `yaml:"path"`
+	// KeepRawResponses stores the classifier's raw reply with each triage
+	// log entry, to debug results that fell back to "uncertain".
+	KeepRawResponses bool `yaml:"keep_raw_responses"`
+	// ReadConnections, if positive, opens a separate pool of that many
+	// read-only connections for stats, reports, and issue listings, so
+	// they do not wait on the pipeline's writes.
+	ReadConnections int `yaml:"read_connections"`
+}
+
+// ServerConfig holds settings for the HTTP surface.
+type ServerConfig struct {
+	// Addr is the address serve listens on, such as ":8080".
+	Addr   string        `yaml:"addr"`
+	Tokens []TokenConfig `yaml:"tokens"`
+	// SlackSigningSecret verifies Slack slash command requests.
+	SlackSigningSecret string `yaml:"slack_signing_secret"`
+	// DiscordPublicKey is the hex-encoded key that verifies Discord
+	// interaction requests.
+	DiscordPublicKey string           `yaml:"discord_public_key"`
+	Similar          SimilarAPIConfig `yaml:"similar"`
+}
+
+// SimilarAPIConfig configures POST /api/similar, which lists stored issues
+// similar to one being written, so a custom issue form can suggest them
+// before it is filed. Browsers call it without an API token, so it only
+// answers for the configured repos that are public or listed in
+// PrivateRepos, and limits each client's requests.
+type SimilarAPIConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// AllowedOrigins are the browser origins, such as
+	// "https://example.com", that may call the endpoint; "*" allows any.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// Threshold is the lowest similarity returned, 0.7 by default: below
+	// the duplicate threshold, as a related issue is worth a look too.
+	Threshold float64 `yaml:"threshold"`
+	// MaxResults caps the issues returned, 5 by default.
+	MaxResults int `yaml:"max_results"`
+	// RateLimit is the most requests a minute from one client address, 30
+	// by default.
+	RateLimit int `yaml:"rate_limit"`
+	// PrivateRepos are private repos ("owner/repo") whose issue titles may
+	// be shown anyway, e.g. to a form only their members can reach.
+	PrivateRepos []string `yaml:"private_repos"`
+}
+
+// TokenConfig defines a statically configured API token. Either Token (the
+// plaintext, typically via ${ENV}) or TokenHash (its SHA-256 hex) must be set.
+type TokenConfig struct {
+	Name      string   `yaml:"name"`
+	Token     string   `yaml:"token"`
+	TokenHash string   `yaml:"token_hash"`
+	Scopes    []string `yaml:"scopes"`
 }
 
 // LabelConfig defines a label with a description.
 type LabelConfig struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
+	// Descriptions translates Description, keyed by language code such as
+	// "de" or "pt-BR". The classification prompt uses the one for the
+	// repo's language; see DescriptionFor.
+	Descriptions map[string]string `yaml:"descriptions"`
+	// Category groups labels for two-pass classification of large label
+	// sets. Without it, a prefix such as "area" in "area/cli" is used.
+	Category string `yaml:"category"`
+}
+
+// DescriptionFor returns the label's description in lang: the translation
+// for lang itself, else for its base language ("pt" for "pt-BR"), else
+// Description.
+func (l LabelConfig) DescriptionFor(lang string) string {
+	lang = strings.ReplaceAll(strings.TrimSpace(lang), "_", "-")
+	if lang == "" {
+		return l.Description
+	}
+	base, _, _ := strings.Cut(lang, "-")
+	fallback := l.Description
+	for code, desc := range l.Descriptions {
+		code = strings.ReplaceAll(code, "_", "-")
+		if strings.EqualFold(code, lang) {
+			return desc
+		}
+		if strings.EqualFold(code, base) {
+			fallback = desc
+		}
+	}
+	return fallback
+}
+
+// validateLabels checks that each label has a name and each translated
+// description a language.
+func validateLabels(labels []LabelConfig) error {
+	for i, l := range labels {
+		if strings.TrimSpace(l.Name) == "" {
+			return fmt.Errorf("labels[%d]: name is required", i)
+		}
+		for code := range l.Descriptions {
+			if strings.TrimSpace(code) == "" {
+				return fmt.Errorf("labels[%d]: descriptions: language code is required", i)
+			}
+		}
+	}
+	return nil
+}
+
+// Example is a labeled issue shown to the classifier to teach it how a
+// repo's labels apply.
+type Example struct {
+	Title       string   `yaml:"title"`
+	BodyExcerpt string   `yaml:"body_excerpt"`
+	Labels      []string `yaml:"labels"`
 }
 
-// RepoConfig holds per-repository overrides.
+// RepoConfig holds per-repository overrides. Name is an owner/repo or a
+// glob pattern such as "myorg/*"; see RepoSet.Resolve.
 type RepoConfig struct {
-	Name                string        `yaml:"name"`
-	Labels              []LabelConfig `yaml:"labels"`
-	CustomPrompt        string        `yaml:"custom_prompt"`
-	SimilarityThreshold *float64      `yaml:"similarity_threshold"`
+	Name   string        `yaml:"name"`
+	Labels []LabelConfig `yaml:"labels"`
+	// LabelPacks names label packs, defined in packs or built in, whose
+	// labels the repo uses ahead of Labels. A label in Labels replaces a
+	// pack label of the same name. Once resolved, Labels holds them all.
+	LabelPacks          []string     `yaml:"label_packs"`
+	CustomPrompt        string       `yaml:"custom_prompt"`
+	Examples            []Example    `yaml:"examples"`
+	SimilarityThreshold *float64     `yaml:"similarity_threshold"`
+	Ignore              *IgnoreRules `yaml:"ignore"`
+	SkipIfLabeled       *bool        `yaml:"skip_if_labeled"`
+	ParallelClassify    *bool        `yaml:"parallel_classify"`
+	// Language is the repo's working language, as a code such as "de":
+	// label descriptions are given to the LLM in it, from their
+	// translations, and the reasoning is written in it. It overrides
+	// notify.language.
+	Language string `yaml:"language"`
 }
 
 // PollInterval returns the parsed poll interval duration.
@@ -95,6 +378,23 @@ func (d DefaultsConfig) RequestTimeout() (time.Duration, error) {
 	return time.ParseDuration(d.RequestTimeoutRaw)
 }
 
+// RemoteConfigTTL returns how long a fetched .github/triage.yml is cached.
+func (d DefaultsConfig) RemoteConfigTTL() (time.Duration, error) {
+	if d.RemoteConfigTTLRaw == "" {
+		return 15 * time.Minute, nil
+	}
+	return time.ParseDuration(d.RemoteConfigTTLRaw)
+}
+
+// ReconcileInterval returns how often stored issues are reconciled with
+// GitHub, or 0 if they are not.
+func (d DefaultsConfig) ReconcileInterval() (time.Duration, error) {
+	if d.ReconcileIntervalRaw == "" {
+		return 24 * time.Hour, nil
+	}
+	return time.ParseDuration(d.ReconcileIntervalRaw)
+}
+
 // envVarPattern matches ${VAR} patterns.
 var envVarPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
 
@@ -128,18 +428,44 @@ func Load(path string) (*Config, error) {
 	return Parse(data)
 }
 
+// LoadStrict is like Load but rejects unknown keys.
+func LoadStrict(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	return ParseStrict(data)
+}
+
 // Parse parses config from raw YAML bytes, expanding env vars and validating.
+// Unknown keys are ignored and listed in Config.UnknownKeys.
 func Parse(data []byte) (*Config, error) {
+	return parse(data, false)
+}
+
+// ParseStrict is like Parse but returns an error naming any key that does
+// not correspond to a config field, e.g. a misspelled option.
+func ParseStrict(data []byte) (*Config, error) {
+	return parse(data, true)
+}
+
+func parse(data []byte, strict bool) (*Config, error) {
 	expanded, err := expandEnvVars(data)
 	if err != nil {
 		return nil, err
 	}
 
 	var cfg Config
-	if err := yaml.Unmarshal(expanded, &cfg); err != nil {
+	dec := yaml.NewDecoder(bytes.NewReader(expanded))
+	dec.KnownFields(strict)
+	if err := dec.Decode(&cfg); err != nil && err != io.EOF {
 		return nil, fmt.Errorf("parsing config YAML: %w", err)
 	}
 
+	if !strict {
+		cfg.UnknownKeys = unknownKeys(expanded)
+	}
+
 	// Apply defaults
 	applyDefaults(&cfg)
 
@@ -150,7 +476,28 @@ func Parse(data []byte) (*Config, error) {
 	return &cfg, nil
 }
 
+// unknownKeys decodes data strictly and returns yaml's description of each
+// key that has no config field, e.g. "line 3: field similarty_threshold not
+// found in type config.DefaultsConfig".
+func unknownKeys(data []byte) []string {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var typeErr *yaml.TypeError
+	if err := dec.Decode(&cfg); !errors.As(err, &typeErr) {
+		return nil
+	}
+	var keys []string
+	for _, msg := range typeErr.Errors {
+		if strings.Contains(msg, " not found in type ") {
+			keys = append(keys, msg)
+		}
+	}
+	return keys
+}
+
 func applyDefaults(cfg *Config) {
+	cfg.Integrations.Jira.applyDefaults()
 	if cfg.Defaults.PollIntervalRaw == "" {
 		cfg.Defaults.PollIntervalRaw = "5m"
 	}
@@ -160,6 +507,12 @@ func applyDefaults(cfg *Config) {
 	if cfg.Defaults.ConfidenceThreshold == 0 {
 		cfg.Defaults.ConfidenceThreshold = 0.7
 	}
+	if cfg.Defaults.ConfidenceLevels.Suggested == 0 {
+		cfg.Defaults.ConfidenceLevels.Suggested = 0.9
+	}
+	if cfg.Defaults.ConfidenceLevels.Possible == 0 {
+		cfg.Defaults.ConfidenceLevels.Possible = 0.7
+	}
 	if cfg.Defaults.MaxDuplicatesShown == 0 {
 		cfg.Defaults.MaxDuplicatesShown = 3
 	}
@@ -169,12 +522,28 @@ func applyDefaults(cfg *Config) {
 	if cfg.Defaults.RequestTimeoutRaw == "" {
 		cfg.Defaults.RequestTimeoutRaw = "30s"
 	}
+	if cfg.Defaults.RemoteConfigTTLRaw == "" {
+		cfg.Defaults.RemoteConfigTTLRaw = "15m"
+	}
+	if cfg.Defaults.ReconcileIntervalRaw == "" {
+		cfg.Defaults.ReconcileIntervalRaw = "24h"
+	}
+	if cfg.Server.Similar.Threshold == 0 {
+		cfg.Server.Similar.Threshold = 0.7
+	}
+	if cfg.Server.Similar.MaxResults == 0 {
+		cfg.Server.Similar.MaxResults = 5
+	}
+	if cfg.Server.Similar.RateLimit == 0 {
+		cfg.Server.Similar.RateLimit = 30
+	}
 	if cfg.Store.Path == "" {
 		cfg.Store.Path = "~/.triage/triage.db"
 	}
 
-	// Expand ~ to user's home directory in store path
+	// Expand ~ to user's home directory in store and log paths
 	cfg.Store.Path = expandTilde(cfg.Store.Path)
+	cfg.Logging.File = expandTilde(cfg.Logging.File)
 }
 
 // expandTilde replaces a leading ~ with the user's home directory.
@@ -197,6 +566,16 @@ func validate(cfg *Config) error {
 	if cfg.Defaults.ConfidenceThreshold < 0 || cfg.Defaults.ConfidenceThreshold > 1 {
 		return fmt.Errorf("confidence_threshold must be between 0 and 1, got %f", cfg.Defaults.ConfidenceThreshold)
 	}
+	levels := cfg.Defaults.ConfidenceLevels
+	if levels.Suggested < 0 || levels.Suggested > 1 {
+		return fmt.Errorf("confidence_levels.suggested must be between 0 and 1, got %f", levels.Suggested)
+	}
+	if levels.Possible < 0 || levels.Possible > 1 {
+		return fmt.Errorf("confidence_levels.possible must be between 0 and 1, got %f", levels.Possible)
+	}
+	if levels.Possible > levels.Suggested {
+		return fmt.Errorf("confidence_levels.possible (%f) must not exceed confidence_levels.suggested (%f)", levels.Possible, levels.Suggested)
+	}
 
 	// Validate durations parse correctly
 	if _, err := time.ParseDuration(cfg.Defaults.PollIntervalRaw); err != nil {
@@ -205,9 +584,47 @@ func validate(cfg *Config) error {
 	if _, err := time.ParseDuration(cfg.Defaults.RequestTimeoutRaw); err != nil {
 		return fmt.Errorf("invalid request_timeout %q: %w", cfg.Defaults.RequestTimeoutRaw, err)
 	}
+	if _, err := time.ParseDuration(cfg.Defaults.RemoteConfigTTLRaw); err != nil {
+		return fmt.Errorf("invalid remote_config_ttl %q: %w", cfg.Defaults.RemoteConfigTTLRaw, err)
+	}
+	if d, err := time.ParseDuration(cfg.Defaults.ReconcileIntervalRaw); err != nil {
+		return fmt.Errorf("invalid reconcile_interval %q: %w", cfg.Defaults.ReconcileIntervalRaw, err)
+	} else if d < 0 {
+		return fmt.Errorf("reconcile_interval must not be negative, got %s", cfg.Defaults.ReconcileIntervalRaw)
+	}
+
+	if cfg.Store.ReadConnections < 0 {
+		return fmt.Errorf("store.read_connections must not be negative, got %d", cfg.Store.ReadConnections)
+	}
 
-	// Validate per-repo similarity thresholds
+	for _, command := range cfg.Defaults.RetriageCommands {
+		if !strings.HasPrefix(command, "/") || len(command) < 2 || strings.ContainsAny(command, " \t\n") {
+			return fmt.Errorf("retriage_commands: invalid command %q: must be a single word starting with /", command)
+		}
+	}
+
+	if err := validatePacks(cfg.Packs); err != nil {
+		return err
+	}
+
+	// Validate repo names, patterns, ignore rules, and per-repo similarity
+	// thresholds
 	for _, repo := range cfg.Repos {
+		if err := validateRepoPattern(repo.Name); err != nil {
+			return fmt.Errorf("repos: %w", err)
+		}
+		if err := repo.Ignore.validate(); err != nil {
+			return fmt.Errorf("repo %s: %w", repo.Name, err)
+		}
+		if err := validateExamples(repo.Examples); err != nil {
+			return fmt.Errorf("repo %s: %w", repo.Name, err)
+		}
+		if err := validateLabels(repo.Labels); err != nil {
+			return fmt.Errorf("repo %s: %w", repo.Name, err)
+		}
+		if err := validatePackRefs(cfg.Packs, repo.LabelPacks); err != nil {
+			return fmt.Errorf("repo %s: %w", repo.Name, err)
+		}
 		if repo.SimilarityThreshold != nil {
 			if *repo.SimilarityThreshold < 0 || *repo.SimilarityThreshold > 1 {
 				return fmt.Errorf("repo %s: similarity_threshold must be between 0 and 1, got %f",
@@ -216,6 +633,122 @@ func validate(cfg *Config) error {
 		}
 	}
 
+	if _, err := cfg.Secrets.Refresh(); err != nil {
+		return fmt.Errorf("invalid secrets.refresh %q: %w", cfg.Secrets.RefreshRaw, err)
+	}
+
+	for org, oc := range cfg.OrgDefaults {
+		if org == "" || strings.Contains(org, "/") || IsRepoPattern(org) {
+			return fmt.Errorf("org_defaults: invalid org name %q", org)
+		}
+		if err := oc.Ignore.validate(); err != nil {
+			return fmt.Errorf("org_defaults %s: %w", org, err)
+		}
+		if err := validateExamples(oc.Examples); err != nil {
+			return fmt.Errorf("org_defaults %s: %w", org, err)
+		}
+		if err := validateLabels(oc.Labels); err != nil {
+			return fmt.Errorf("org_defaults %s: %w", org, err)
+		}
+		if err := validatePackRefs(cfg.Packs, oc.LabelPacks); err != nil {
+			return fmt.Errorf("org_defaults %s: %w", org, err)
+		}
+		if oc.SimilarityThreshold != nil && (*oc.SimilarityThreshold < 0 || *oc.SimilarityThreshold > 1) {
+			return fmt.Errorf("org_defaults %s: similarity_threshold must be between 0 and 1, got %f",
+				org, *oc.SimilarityThreshold)
+		}
+	}
+
+	for alias, label := range cfg.Aliases {
+		if strings.TrimSpace(alias) == "" || strings.TrimSpace(label) == "" {
+			return fmt.Errorf("aliases: alias and label must be non-empty, got %q: %q", alias, label)
+		}
+	}
+
+	for _, pattern := range cfg.Exclude {
+		if err := validateRepoPattern(pattern); err != nil {
+			return fmt.Errorf("exclude: %w", err)
+		}
+	}
+
+	if err := cfg.Experiment.validate(); err != nil {
+		return fmt.Errorf("experiment: %w", err)
+	}
+	if err := cfg.SLA.validate(); err != nil {
+		return fmt.Errorf("sla: %w", err)
+	}
+	if err := cfg.Logging.validate(); err != nil {
+		return fmt.Errorf("logging: %w", err)
+	}
+	if err := cfg.Pipeline.validate(); err != nil {
+		return fmt.Errorf("pipeline: %w", err)
+	}
+
+	for i, src := range cfg.Sources {
+		if src.Type == "" {
+			return fmt.Errorf("sources[%d]: type is required", i)
+		}
+	}
+
+	if err := cfg.Integrations.Jira.validate(); err != nil {
+		return fmt.Errorf("integrations.jira: %w", err)
+	}
+
+	if (cfg.Notify.SlackBotToken == "") != (cfg.Notify.SlackChannel == "") {
+		return fmt.Errorf("notify.slack_bot_token and notify.slack_channel must be set together")
+	}
+
+	if cfg.GitHub.Auth == "token" && cfg.GitHub.Token == "" {
+		return fmt.Errorf("github.token is required when github.auth is token")
+	}
+
+	// Validate API tokens
+	for i, tok := range cfg.Server.Tokens {
+		if tok.Name == "" {
+			return fmt.Errorf("server.tokens[%d]: name is required", i)
+		}
+		if tok.Token == "" && tok.TokenHash == "" {
+			return fmt.Errorf("server token %s: one of token or token_hash is required", tok.Name)
+		}
+		if len(tok.Scopes) == 0 {
+			return fmt.Errorf("server token %s: at least one scope is required", tok.Name)
+		}
+		if _, err := auth.ParseScopes(tok.Scopes); err != nil {
+			return fmt.Errorf("server token %s: %w", tok.Name, err)
+		}
+	}
+
+	if key := cfg.Server.DiscordPublicKey; key != "" {
+		if b, err := hex.DecodeString(key); err != nil || len(b) != 32 {
+			return fmt.Errorf("server.discord_public_key must be 64 hex characters")
+		}
+	}
+
+	if sim := cfg.Server.Similar; sim.Enabled {
+		if sim.Threshold < 0 || sim.Threshold > 1 {
+			return fmt.Errorf("server.similar.threshold must be between 0 and 1")
+		}
+		if sim.MaxResults < 0 {
+			return fmt.Errorf("server.similar.max_results must not be negative")
+		}
+		if sim.RateLimit < 0 {
+			return fmt.Errorf("server.similar.rate_limit must not be negative")
+		}
+		for _, repo := range sim.PrivateRepos {
+			if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+				return fmt.Errorf("server.similar.private_repos: %q is not owner/repo", repo)
+			}
+		}
+		for _, origin := range sim.AllowedOrigins {
+			if origin == "*" {
+				continue
+			}
+			if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+				return fmt.Errorf("server.similar.allowed_origins: %q is not an origin such as https://example.com", origin)
+			}
+		}
+	}
+
 	// Validate provider types if set
 	validEmbedTypes := map[string]bool{"openai": true, "ollama": true, "": true}
 	if !validEmbedTypes[cfg.Providers.Embedding.Type] {
@@ -227,5 +760,12 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("unsupported LLM provider type: %s", cfg.Providers.LLM.Type)
 	}
 
+	if cfg.Providers.Embedding.CostPerMTok < 0 || cfg.Providers.LLM.CostPerMTok < 0 {
+		return fmt.Errorf("providers cost_per_mtok must not be negative")
+	}
+	if cfg.Providers.LLM.MaxTokens < 0 {
+		return fmt.Errorf("providers.llm.max_tokens must not be negative")
+	}
+
 	return nil
 }
@@ -1,8 +1,16 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/secrets"
 )
 
 func TestParseBasicConfig(t *testing.T) {
@@ -96,6 +104,9 @@ github: {}
 	if cfg.Defaults.ConfidenceThreshold != 0.7 {
 		t.Errorf("expected default confidence 0.7, got %f", cfg.Defaults.ConfidenceThreshold)
 	}
+	if got := cfg.Defaults.ConfidenceLevels; got.Suggested != 0.9 || got.Possible != 0.7 {
+		t.Errorf("expected default confidence_levels 0.9/0.7, got %+v", got)
+	}
 	if cfg.Defaults.MaxDuplicatesShown != 3 {
 		t.Errorf("expected default max_duplicates 3, got %d", cfg.Defaults.MaxDuplicatesShown)
 	}
@@ -178,6 +189,20 @@ defaults:
 			yaml: `
 defaults:
   confidence_threshold: 2.0
+`,
+		},
+		{
+			name: "suggested level too high",
+			yaml: `
+defaults:
+  confidence_levels: {suggested: 1.2}
+`,
+		},
+		{
+			name: "possible level above suggested",
+			yaml: `
+defaults:
+  confidence_levels: {suggested: 0.6, possible: 0.8}
 `,
 		},
 	}
@@ -264,6 +289,50 @@ repos:
 	}
 }
 
+func TestParseAliases(t *testing.T) {
+	cfg, err := Parse([]byte(`
+aliases:
+  defect: bug
+  docs: documentation
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Aliases["defect"] != "bug" || cfg.Aliases["docs"] != "documentation" {
+		t.Errorf("unexpected aliases: %v", cfg.Aliases)
+	}
+
+	if _, err := Parse([]byte("aliases:\n  defect: \"\"\n")); err == nil {
+		t.Error("expected validation error for empty alias target, got nil")
+	}
+}
+
+func TestParseConfidenceLevels(t *testing.T) {
+	cfg, err := Parse([]byte("defaults:\n  confidence_levels: {suggested: 0.8, possible: 0.5}\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Defaults.ConfidenceLevels; got.Suggested != 0.8 || got.Possible != 0.5 {
+		t.Errorf("unexpected confidence levels: %+v", got)
+	}
+}
+
+func TestParseRetriageCommands(t *testing.T) {
+	cfg, err := Parse([]byte("defaults:\n  retriage_commands: [/triage, /retriage]\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Defaults.RetriageCommands; len(got) != 2 || got[0] != "/triage" || got[1] != "/retriage" {
+		t.Errorf("unexpected retriage commands: %v", got)
+	}
+
+	for _, bad := range []string{"triage", "/", "\"/re triage\""} {
+		if _, err := Parse([]byte("defaults:\n  retriage_commands: [" + bad + "]\n")); err == nil {
+			t.Errorf("expected validation error for command %s, got nil", bad)
+		}
+	}
+}
+
 func TestExpandTilde(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -425,3 +494,581 @@ github: {}
 		})
 	}
 }
+
+func TestParseServerTokens(t *testing.T) {
+	yaml := `
+server:
+  tokens:
+    - name: ci
+      token: secret-value
+      scopes: [read, triage]
+    - name: dashboard
+      token_hash: abc123
+      scopes: [" Read"]
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Server.Tokens) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(cfg.Server.Tokens))
+	}
+	if cfg.Server.Tokens[0].Name != "ci" || len(cfg.Server.Tokens[0].Scopes) != 2 {
+		t.Errorf("unexpected first token: %+v", cfg.Server.Tokens[0])
+	}
+	if cfg.Server.Tokens[1].TokenHash != "abc123" {
+		t.Errorf("unexpected token_hash: %q", cfg.Server.Tokens[1].TokenHash)
+	}
+}
+
+func TestValidationServerTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			name: "missing name",
+			yaml: `
+server:
+  tokens:
+    - token: x
+      scopes: [read]
+`,
+		},
+		{
+			name: "missing token and hash",
+			yaml: `
+server:
+  tokens:
+    - name: ci
+      scopes: [read]
+`,
+		},
+		{
+			name: "no scopes",
+			yaml: `
+server:
+  tokens:
+    - name: ci
+      token: x
+`,
+		},
+		{
+			name: "unknown scope",
+			yaml: `
+server:
+  tokens:
+    - name: ci
+      token: x
+      scopes: [write]
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tc.yaml)); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestValidationSlackBot(t *testing.T) {
+	if _, err := Parse([]byte("notify:\n  slack_bot_token: xoxb-1\n")); err == nil {
+		t.Error("expected error for slack_bot_token without slack_channel")
+	}
+	cfg, err := Parse([]byte("notify:\n  slack_bot_token: xoxb-1\n  slack_channel: C123\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Notify.SlackBotToken != "xoxb-1" || cfg.Notify.SlackChannel != "C123" {
+		t.Errorf("unexpected notify config %+v", cfg.Notify)
+	}
+}
+
+func TestValidationDiscordPublicKey(t *testing.T) {
+	if _, err := Parse([]byte("server:\n  discord_public_key: not-hex\n")); err == nil {
+		t.Error("expected error for a malformed discord_public_key")
+	}
+	key := strings.Repeat("ab", 32)
+	cfg, err := Parse([]byte("server:\n  addr: \":9000\"\n  discord_public_key: " + key + "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.DiscordPublicKey != key || cfg.Server.Addr != ":9000" {
+		t.Errorf("unexpected server config %+v", cfg.Server)
+	}
+}
+
+func TestParseSimilarAPI(t *testing.T) {
+	cfg, err := Parse([]byte("server:\n  similar:\n    enabled: true\n    allowed_origins: [\"https://example.com\", \"*\"]\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sim := cfg.Server.Similar
+	if !sim.Enabled || len(sim.AllowedOrigins) != 2 || sim.Threshold != 0.7 || sim.MaxResults != 5 || sim.RateLimit != 30 {
+		t.Errorf("unexpected similar config %+v", sim)
+	}
+
+	for _, bad := range []string{
+		"threshold: 1.5",
+		"max_results: -1",
+		"rate_limit: -1",
+		"private_repos: [app]",
+		"allowed_origins: [example.com]",
+		"allowed_origins: [\"https://example.com/form\"]",
+	} {
+		if _, err := Parse([]byte("server:\n  similar:\n    enabled: true\n    " + bad + "\n")); err == nil || !strings.Contains(err.Error(), "server.similar") {
+			t.Errorf("%s: expected a server.similar error, got %v", bad, err)
+		}
+	}
+}
+
+func TestParseMentions(t *testing.T) {
+	cfg, err := Parse([]byte(`
+notify:
+  mentions:
+    security: "<!subteam^S1>"
+    crash:
+      slack: "<!subteam^S2>"
+      discord: "<@&42>"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]Mention{
+		"security": {Slack: "<!subteam^S1>", Discord: "<!subteam^S1>"},
+		"crash":    {Slack: "<!subteam^S2>", Discord: "<@&42>"},
+	}
+	if !reflect.DeepEqual(cfg.Notify.Mentions, want) {
+		t.Errorf("Mentions = %+v, want %+v", cfg.Notify.Mentions, want)
+	}
+}
+
+func TestGitHubTokenAuth(t *testing.T) {
+	t.Setenv("TEST_GH_TOKEN", "ghs_abc")
+	cfg, err := Parse([]byte(`
+github:
+  auth: token
+  token: ${TEST_GH_TOKEN}
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GitHub.Token != "ghs_abc" {
+		t.Errorf("expected token ghs_abc, got %q", cfg.GitHub.Token)
+	}
+
+	if _, err := Parse([]byte("github:\n  auth: token\n")); err == nil {
+		t.Error("expected validation error for token auth without token, got nil")
+	}
+}
+
+func TestParseStrictUnknownKeys(t *testing.T) {
+	yaml := `
+notify:
+  slack_webhok: https://hooks.slack.com/test
+`
+	if _, err := Parse([]byte(yaml)); err != nil {
+		t.Fatalf("lenient parse should ignore unknown keys, got %v", err)
+	}
+	_, err := ParseStrict([]byte(yaml))
+	if err == nil {
+		t.Fatal("expected strict parse to reject unknown key")
+	}
+	if !strings.Contains(err.Error(), "slack_webhok") {
+		t.Errorf("expected error to name the unknown key, got %v", err)
+	}
+
+	if _, err := ParseStrict([]byte("")); err != nil {
+		t.Errorf("expected empty config to parse, got %v", err)
+	}
+}
+
+func TestParseRecordsUnknownKeys(t *testing.T) {
+	cfg, err := Parse([]byte(`
+defaults:
+  similarty_threshold: 0.9
+notify:
+  slack_webhok: https://hooks.slack.com/test
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.UnknownKeys) != 2 {
+		t.Fatalf("expected 2 unknown keys, got %v", cfg.UnknownKeys)
+	}
+	if !strings.Contains(cfg.UnknownKeys[0], "similarty_threshold") || !strings.Contains(cfg.UnknownKeys[1], "slack_webhok") {
+		t.Errorf("unexpected unknown keys: %v", cfg.UnknownKeys)
+	}
+
+	cfg, err = Parse([]byte("defaults:\n  similarity_threshold: 0.9\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.UnknownKeys) != 0 {
+		t.Errorf("expected no unknown keys, got %v", cfg.UnknownKeys)
+	}
+}
+
+func TestProviderCostPerMTok(t *testing.T) {
+	cfg, err := Parse([]byte(`
+providers:
+  embedding:
+    type: openai
+    cost_per_mtok: 0.02
+  llm:
+    type: anthropic
+    cost_per_mtok: 3
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Providers.Embedding.CostPerMTok != 0.02 || cfg.Providers.LLM.CostPerMTok != 3 {
+		t.Errorf("unexpected costs: %+v", cfg.Providers)
+	}
+
+	if _, err := Parse([]byte("providers:\n  llm:\n    cost_per_mtok: -1\n")); err == nil {
+		t.Error("expected validation error for negative cost")
+	}
+}
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(_ context.Context, path, key string) (string, error) {
+	v, ok := s[path]
+	if !ok {
+		return "", fmt.Errorf("no secret at %s", path)
+	}
+	return v, nil
+}
+
+func TestResolveSecrets(t *testing.T) {
+	cfg, err := Parse([]byte(`
+github:
+  auth: token
+  token: aws-sm://prod/github
+providers:
+  llm:
+    type: anthropic
+    api_key: vault://secret/anthropic#key
+notify:
+  slack_webhook: https://hooks.slack.com/plain
+secrets:
+  refresh: 1h
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := secrets.NewResolverWith(map[string]secrets.Backend{
+		"vault":  staticSecrets{"secret/anthropic": "sk-ant"},
+		"aws-sm": staticSecrets{"prod/github": "ghp_x"},
+	})
+	if err := cfg.ResolveSecrets(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GitHub.Token != "ghp_x" || cfg.Providers.LLM.APIKey != "sk-ant" || cfg.Notify.SlackWebhook != "https://hooks.slack.com/plain" {
+		t.Errorf("unexpected resolved values: %+v %+v %+v", cfg.GitHub, cfg.Providers.LLM, cfg.Notify)
+	}
+	if len(cfg.SecretRefs) != 2 || cfg.SecretRefs["providers.llm.api_key"] != "vault://secret/anthropic#key" {
+		t.Errorf("unexpected SecretRefs: %v", cfg.SecretRefs)
+	}
+	if d, _ := cfg.Secrets.Refresh(); d != time.Hour {
+		t.Errorf("Refresh() = %v, want 1h", d)
+	}
+
+	cfg = &Config{}
+	cfg.Providers.Embedding.APIKey = "vault://secret/missing"
+	cfg.Notify.DiscordWebhook = "vault://secret/also-missing"
+	err = cfg.ResolveSecrets(context.Background(), r)
+	if err == nil || !strings.Contains(err.Error(), "providers.embedding.api_key") || !strings.Contains(err.Error(), "notify.discord_webhook") {
+		t.Errorf("expected errors for both fields, got %v", err)
+	}
+
+	if _, err := Parse([]byte("secrets:\n  refresh: soon\n")); err == nil {
+		t.Error("expected invalid secrets.refresh to be rejected")
+	}
+}
+
+func TestParseMultiPass(t *testing.T) {
+	cfg, err := Parse([]byte(`
+defaults:
+  multi_pass_threshold: 40
+repos:
+  - name: org/app
+    labels:
+      - name: crash
+        category: bugs
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Defaults.MultiPassThreshold != 40 {
+		t.Errorf("MultiPassThreshold = %d, want 40", cfg.Defaults.MultiPassThreshold)
+	}
+	if got := cfg.Repos[0].Labels[0].Category; got != "bugs" {
+		t.Errorf("Category = %q, want bugs", got)
+	}
+}
+
+func TestParseLLMCompletionParams(t *testing.T) {
+	cfg, err := Parse([]byte(`
+providers:
+  llm:
+    type: anthropic
+    max_tokens: 2048
+    stop_sequences: ["\n\n"]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Providers.LLM.MaxTokens != 2048 {
+		t.Errorf("MaxTokens = %d, want 2048", cfg.Providers.LLM.MaxTokens)
+	}
+	if len(cfg.Providers.LLM.StopSequences) != 1 || cfg.Providers.LLM.StopSequences[0] != "\n\n" {
+		t.Errorf("StopSequences = %q", cfg.Providers.LLM.StopSequences)
+	}
+
+	if _, err := Parse([]byte(`
+providers:
+  llm:
+    max_tokens: -1
+`)); err == nil {
+		t.Error("expected an error for negative max_tokens")
+	}
+}
+
+func TestParseEmbeddingPrefixes(t *testing.T) {
+	cfg, err := Parse([]byte(`
+providers:
+  embedding:
+    type: ollama
+    model: nomic-embed-text
+    document_prefix: "search_document: "
+    query_prefix: "search_query: "
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if emb := cfg.Providers.Embedding; emb.DocumentPrefix != "search_document: " || emb.QueryPrefix != "search_query: " {
+		t.Errorf("unexpected prefixes %q, %q", emb.DocumentPrefix, emb.QueryPrefix)
+	}
+}
+
+func TestParseNotifyLanguage(t *testing.T) {
+	cfg, err := Parse([]byte(`
+notify:
+  language: de
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Notify.Language != "de" {
+		t.Errorf("Language = %q, want de", cfg.Notify.Language)
+	}
+}
+
+func TestParseKeepRawResponses(t *testing.T) {
+	cfg, err := Parse([]byte(`
+store:
+  keep_raw_responses: true
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Store.KeepRawResponses {
+		t.Error("expected KeepRawResponses to be set")
+	}
+}
+
+func TestParseLogging(t *testing.T) {
+	cfg, err := Parse([]byte(`
+logging:
+  level: debug
+  format: text
+  file: /var/log/triage.log
+  max_size_mb: 10
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lc := cfg.Logging
+	if level, _ := lc.SlogLevel(); level != slog.LevelDebug {
+		t.Errorf("level = %v, want debug", level)
+	}
+	if lc.Format != "text" || lc.File != "/var/log/triage.log" {
+		t.Errorf("unexpected logging config %+v", lc)
+	}
+	if lc.MaxSize() != 10<<20 || lc.Backups() != 5 {
+		t.Errorf("MaxSize = %d, Backups = %d", lc.MaxSize(), lc.Backups())
+	}
+
+	for _, bad := range []string{
+		"logging:\n  level: loud\n",
+		"logging:\n  format: xml\n",
+		"logging:\n  max_size_mb: -1\n",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil || !strings.Contains(err.Error(), "logging") {
+			t.Errorf("expected %q to be rejected, got %v", bad, err)
+		}
+	}
+}
+
+func TestParsePipelineProcessTimeout(t *testing.T) {
+	cfg, err := Parse([]byte("pipeline:\n  process_timeout: 5m\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, _ := cfg.Pipeline.Timeout(); d != 5*time.Minute {
+		t.Errorf("Timeout = %s, want 5m", d)
+	}
+	if d, _ := (PipelineConfig{}).Timeout(); d != 30*time.Second {
+		t.Errorf("default Timeout = %s, want 30s", d)
+	}
+
+	for _, bad := range []string{
+		"pipeline:\n  process_timeout: soon\n",
+		"pipeline:\n  process_timeout: 0s\n",
+		"pipeline:\n  process_timeout: -1m\n",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil || !strings.Contains(err.Error(), "pipeline") {
+			t.Errorf("expected %q to be rejected, got %v", bad, err)
+		}
+	}
+}
+
+func TestParseReadConnections(t *testing.T) {
+	cfg, err := Parse([]byte(`
+store:
+  read_connections: 4
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Store.ReadConnections != 4 {
+		t.Errorf("ReadConnections = %d, want 4", cfg.Store.ReadConnections)
+	}
+
+	if _, err := Parse([]byte("store:\n  read_connections: -1\n")); err == nil || !strings.Contains(err.Error(), "read_connections") {
+		t.Errorf("expected a negative read_connections to be rejected, got %v", err)
+	}
+}
+
+func TestParseReconcileInterval(t *testing.T) {
+	cfg, err := Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, _ := cfg.Defaults.ReconcileInterval(); d != 24*time.Hour {
+		t.Errorf("default ReconcileInterval = %s, want 24h", d)
+	}
+
+	cfg, err = Parse([]byte("defaults:\n  reconcile_interval: \"0\"\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, _ := cfg.Defaults.ReconcileInterval(); d != 0 {
+		t.Errorf("ReconcileInterval = %s, want 0 (disabled)", d)
+	}
+
+	for _, raw := range []string{"soon", "-1h"} {
+		if _, err := Parse([]byte("defaults:\n  reconcile_interval: " + raw + "\n")); err == nil {
+			t.Errorf("expected an error for reconcile_interval %q", raw)
+		}
+	}
+}
+
+func TestParseExamples(t *testing.T) {
+	cfg, err := Parse([]byte(`
+repos:
+  - name: org/app
+    examples:
+      - title: Sync stalls on metered connections
+        body_excerpt: Nothing uploads while tethered to my phone.
+        labels: [sync, network]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Example{{
+		Title:       "Sync stalls on metered connections",
+		BodyExcerpt: "Nothing uploads while tethered to my phone.",
+		Labels:      []string{"sync", "network"},
+	}}
+	if !reflect.DeepEqual(cfg.Repos[0].Examples, want) {
+		t.Errorf("Examples = %+v, want %+v", cfg.Repos[0].Examples, want)
+	}
+
+	if _, err := Parse([]byte("repos:\n  - name: org/app\n    examples:\n      - title: x\n")); err == nil {
+		t.Error("expected error for an example without labels")
+	}
+}
+
+func TestParseSources(t *testing.T) {
+	cfg, err := Parse([]byte("sources:\n  - type: jsonl\n    path: events.jsonl\n  - type: queue\n    options:\n      url: amqp://localhost\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Sources) != 2 || cfg.Sources[0].Path != "events.jsonl" || cfg.Sources[1].Options["url"] != "amqp://localhost" {
+		t.Errorf("unexpected sources: %+v", cfg.Sources)
+	}
+
+	if _, err := Parse([]byte("sources:\n  - path: events.jsonl\n")); err == nil {
+		t.Error("expected an error for a source without a type")
+	}
+}
+
+func TestParseExperiment(t *testing.T) {
+	cfg, err := Parse([]byte("experiment:\n  percent: 20\n  prompt: Be brief.\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Experiment.Enabled() || cfg.Experiment.Variant() != DefaultExperimentName {
+		t.Errorf("unexpected experiment: %+v", cfg.Experiment)
+	}
+
+	cfg, err = Parse([]byte(`{}`))
+	if err != nil || cfg.Experiment.Enabled() {
+		t.Errorf("expected no experiment by default, got %+v, %v", cfg.Experiment, err)
+	}
+
+	for _, raw := range []string{
+		"experiment:\n  percent: 120\n  prompt: x\n",
+		"experiment:\n  percent: 10\n",
+		"experiment:\n  name: control\n  percent: 10\n  prompt: x\n",
+	} {
+		if _, err := Parse([]byte(raw)); err == nil {
+			t.Errorf("expected an error for %q", raw)
+		}
+	}
+}
+
+func TestParseSLA(t *testing.T) {
+	cfg, err := Parse([]byte("sla:\n  high: 4h\n  low: 72h\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	limits, err := cfg.SLA.Limits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Duration{"high": 4 * time.Hour, "low": 72 * time.Hour}
+	if !cfg.SLA.Enabled() || !reflect.DeepEqual(limits, want) {
+		t.Errorf("Limits() = %v, want %v", limits, want)
+	}
+	if interval, _ := cfg.SLA.Interval(); interval != 15*time.Minute {
+		t.Errorf("Interval() = %s, want the 15m default", interval)
+	}
+
+	cfg, err = Parse([]byte(`{}`))
+	if err != nil || cfg.SLA.Enabled() {
+		t.Errorf("expected no SLA by default, got %+v, %v", cfg.SLA, err)
+	}
+
+	for _, bad := range []string{"high: soon", "medium: -1h", "check_interval: 0s"} {
+		if _, err := Parse([]byte("sla:\n  " + bad + "\n")); err == nil || !strings.Contains(err.Error(), "sla:") {
+			t.Errorf("%s: expected an sla error, got %v", bad, err)
+		}
+	}
+}
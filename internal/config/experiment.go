@@ -0,0 +1,53 @@
+package config
+
+import "fmt"
+
+// DefaultExperimentName tags results of the alternate variant when
+// ExperimentConfig.Name is empty.
+const DefaultExperimentName = "experiment"
+
+// ExperimentConfig classifies a share of issues with an alternate prompt,
+// model, or both. Each result is tagged with its variant in the triage log,
+// the alternate one with Name and the rest with "control", so "triage eval
+// --experiment" can compare how humans judged them. It is enabled when
+// Percent is above zero.
+type ExperimentConfig struct {
+	Name string `yaml:"name"`
+	// Percent is the share of issues, from 0 to 100, classified with the
+	// alternate variant.
+	Percent float64 `yaml:"percent"`
+	// Prompt replaces the repo's custom_prompt for the alternate variant.
+	Prompt string `yaml:"prompt"`
+	// LLM is the alternate variant's model. If its type is empty,
+	// providers.llm is used.
+	LLM ProviderConfig `yaml:"llm"`
+}
+
+// Enabled reports whether the experiment runs.
+func (e ExperimentConfig) Enabled() bool {
+	return e.Percent > 0
+}
+
+// Variant returns the tag of the alternate variant.
+func (e ExperimentConfig) Variant() string {
+	if e.Name == "" {
+		return DefaultExperimentName
+	}
+	return e.Name
+}
+
+func (e ExperimentConfig) validate() error {
+	if e.Percent < 0 || e.Percent > 100 {
+		return fmt.Errorf("percent must be between 0 and 100, got %g", e.Percent)
+	}
+	if !e.Enabled() {
+		return nil
+	}
+	if e.Prompt == "" && e.LLM.Type == "" {
+		return fmt.Errorf("set prompt, llm, or both for the alternate variant")
+	}
+	if e.Variant() == "control" {
+		return fmt.Errorf("name %q is reserved for the control variant", e.Name)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IgnoreRules select issues that are skipped before dedup and
+// classification, so they cost no provider calls. An issue is ignored if it
+// matches any rule.
+type IgnoreRules struct {
+	TitleRegex string   `yaml:"title_regex"` // e.g. "^\\[WIP\\]"
+	Labels     []string `yaml:"labels"`      // any of these labels
+	Authors    []string `yaml:"authors"`     // GitHub logins
+}
+
+// Match returns a short description of the first rule an issue matches, or
+// "" if none does. Label and author comparisons ignore case. A nil
+// IgnoreRules matches nothing.
+func (r *IgnoreRules) Match(title, author string, labels []string) string {
+	if r == nil {
+		return ""
+	}
+	if r.TitleRegex != "" {
+		// Compiles are cheap next to the provider calls this saves, and the
+		// pattern was checked when the config was loaded.
+		if re, err := regexp.Compile(r.TitleRegex); err == nil && re.MatchString(title) {
+			return fmt.Sprintf("title matches %q", r.TitleRegex)
+		}
+	}
+	for _, want := range r.Labels {
+		for _, l := range labels {
+			if strings.EqualFold(l, want) {
+				return fmt.Sprintf("has label %q", l)
+			}
+		}
+	}
+	for _, a := range r.Authors {
+		if strings.EqualFold(a, author) {
+			return fmt.Sprintf("opened by %s", author)
+		}
+	}
+	return ""
+}
+
+func (r *IgnoreRules) validate() error {
+	if r == nil || r.TitleRegex == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(r.TitleRegex); err != nil {
+		return fmt.Errorf("ignore.title_regex: %w", err)
+	}
+	return nil
+}
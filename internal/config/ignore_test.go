@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRulesMatch(t *testing.T) {
+	r := &IgnoreRules{
+		TitleRegex: `^\[WIP\]`,
+		Labels:     []string{"triaged"},
+		Authors:    []string{"dependabot[bot]"},
+	}
+	tests := []struct {
+		title, author string
+		labels        []string
+		want          string
+	}{
+		{"[WIP] new parser", "alice", nil, `title matches "^\\[WIP\\]"`},
+		{"Crash on start", "alice", []string{"bug", "Triaged"}, `has label "Triaged"`},
+		{"Bump x", "Dependabot[bot]", nil, "opened by Dependabot[bot]"},
+		{"Crash on start [WIP]", "alice", []string{"bug"}, ""},
+	}
+	for _, tt := range tests {
+		if got := r.Match(tt.title, tt.author, tt.labels); got != tt.want {
+			t.Errorf("Match(%q, %q, %v) = %q, want %q", tt.title, tt.author, tt.labels, got, tt.want)
+		}
+	}
+
+	var none *IgnoreRules
+	if got := none.Match("[WIP]", "alice", nil); got != "" {
+		t.Errorf("nil rules matched: %q", got)
+	}
+}
+
+func TestParseIgnoreRules(t *testing.T) {
+	cfg, err := Parse([]byte(`
+org_defaults:
+  myorg:
+    ignore:
+      labels: [triaged]
+repos:
+  - name: myorg/api
+    ignore:
+      title_regex: "^\\[WIP\\]"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc, _ := cfg.Repo("myorg/web"); rc.Ignore.Match("x", "a", []string{"triaged"}) == "" {
+		t.Error("expected org default ignore rules to apply")
+	}
+	// A repo's ignore rules replace the org's as a whole.
+	rc, _ := cfg.Repo("myorg/api")
+	if rc.Ignore.Match("[WIP] x", "a", nil) == "" || rc.Ignore.Match("x", "a", []string{"triaged"}) != "" {
+		t.Errorf("expected only the repo's ignore rules, got %+v", rc.Ignore)
+	}
+
+	if _, err := Parse([]byte("repos:\n  - name: o/r\n    ignore:\n      title_regex: \"([\"\n")); err == nil || !strings.Contains(err.Error(), "ignore.title_regex") {
+		t.Errorf("expected invalid regex error, got %v", err)
+	}
+}
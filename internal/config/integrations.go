@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IntegrationsConfig holds settings for connecting triage to other trackers.
+type IntegrationsConfig struct {
+	Jira JiraConfig `yaml:"jira"`
+}
+
+// JiraConfig links GitHub issues that carry one of Labels to a Jira ticket
+// in Project, created with the triage summary. Closing or reopening the
+// GitHub issue moves the ticket through CloseTransition or ReopenTransition.
+// The integration is enabled when URL is set.
+type JiraConfig struct {
+	URL       string   `yaml:"url"`
+	Email     string   `yaml:"email"`
+	APIToken  string   `yaml:"api_token"`
+	Project   string   `yaml:"project"`
+	IssueType string   `yaml:"issue_type"`
+	Labels    []string `yaml:"labels"`
+	// CloseTransition and ReopenTransition name the Jira workflow
+	// transitions to apply, such as "Done" and "To Do".
+	CloseTransition  string `yaml:"close_transition"`
+	ReopenTransition string `yaml:"reopen_transition"`
+}
+
+// Enabled reports whether the Jira integration is configured.
+func (j JiraConfig) Enabled() bool {
+	return j.URL != ""
+}
+
+// HasLabel reports whether name is one of the labels that create tickets,
+// ignoring case.
+func (j JiraConfig) HasLabel(name string) bool {
+	for _, l := range j.Labels {
+		if strings.EqualFold(l, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *JiraConfig) applyDefaults() {
+	if j.IssueType == "" {
+		j.IssueType = "Task"
+	}
+	if j.CloseTransition == "" {
+		j.CloseTransition = "Done"
+	}
+	if j.ReopenTransition == "" {
+		j.ReopenTransition = "To Do"
+	}
+}
+
+func (j JiraConfig) validate() error {
+	if !j.Enabled() {
+		return nil
+	}
+	for _, f := range []struct{ key, value string }{
+		{"email", j.Email},
+		{"api_token", j.APIToken},
+		{"project", j.Project},
+	} {
+		if f.value == "" {
+			return fmt.Errorf("%s is required", f.key)
+		}
+	}
+	if len(j.Labels) == 0 {
+		return fmt.Errorf("labels must list at least one label")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJira(t *testing.T) {
+	cfg, err := Parse([]byte(`
+integrations:
+  jira:
+    url: https://example.atlassian.net
+    email: bot@example.com
+    api_token: secret
+    project: OPS
+    labels: [customer-bug]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	j := cfg.Integrations.Jira
+	if !j.Enabled() || j.Project != "OPS" {
+		t.Errorf("unexpected jira config: %+v", j)
+	}
+	if j.IssueType != "Task" || j.CloseTransition != "Done" || j.ReopenTransition != "To Do" {
+		t.Errorf("defaults not applied: %+v", j)
+	}
+	if !j.HasLabel("Customer-Bug") || j.HasLabel("bug") {
+		t.Error("HasLabel should match configured labels case-insensitively")
+	}
+
+	cfg, err = Parse([]byte("defaults:\n  poll_interval: 1m\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Integrations.Jira.Enabled() {
+		t.Error("expected jira to be disabled without a url")
+	}
+
+	_, err = Parse([]byte(`
+integrations:
+  jira:
+    url: https://example.atlassian.net
+    email: bot@example.com
+    api_token: secret
+    labels: [customer-bug]
+`))
+	if err == nil || !strings.Contains(err.Error(), "integrations.jira: project is required") {
+		t.Errorf("expected missing project error, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Defaults for LoggingConfig.MaxSizeMB and LoggingConfig.MaxBackups.
+const (
+	defaultLogMaxSizeMB  = 100
+	defaultLogMaxBackups = 5
+)
+
+// LogFormats and LogLevels are the values of logging.format and
+// logging.level.
+var (
+	LogFormats = []string{"json", "text"}
+	LogLevels  = []string{"debug", "info", "warn", "error"}
+)
+
+// LoggingConfig sets where logs go and in what form. By default they are
+// written to stderr as JSON at info level; --verbose still lowers the level
+// to debug.
+type LoggingConfig struct {
+	// Level is debug, info, warn, or error.
+	Level string `yaml:"level"`
+	// Format is json or text.
+	Format string `yaml:"format"`
+	// File, if set, receives logs instead of stderr. It is rotated once it
+	// reaches MaxSizeMB, keeping MaxBackups old files as File.1, File.2, ...
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxBackups int    `yaml:"max_backups"`
+}
+
+// SlogLevel returns the configured level; info if none is set.
+func (l LoggingConfig) SlogLevel() (slog.Level, error) {
+	switch strings.ToLower(l.Level) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid level %q: expected one of %s", l.Level, strings.Join(LogLevels, ", "))
+}
+
+// MaxSize returns the size in bytes at which the log file is rotated.
+func (l LoggingConfig) MaxSize() int64 {
+	if l.MaxSizeMB == 0 {
+		return defaultLogMaxSizeMB << 20
+	}
+	return int64(l.MaxSizeMB) << 20
+}
+
+// Backups returns how many rotated log files are kept.
+func (l LoggingConfig) Backups() int {
+	if l.MaxBackups == 0 {
+		return defaultLogMaxBackups
+	}
+	return l.MaxBackups
+}
+
+func (l LoggingConfig) validate() error {
+	if _, err := l.SlogLevel(); err != nil {
+		return err
+	}
+	switch l.Format {
+	case "", "json", "text":
+	default:
+		return fmt.Errorf("invalid format %q: expected json or text", l.Format)
+	}
+	if l.MaxSizeMB < 0 {
+		return fmt.Errorf("max_size_mb must not be negative, got %d", l.MaxSizeMB)
+	}
+	if l.MaxBackups < 0 {
+		return fmt.Errorf("max_backups must not be negative, got %d", l.MaxBackups)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// builtinPacks are the label packs available without being defined in
+// packs.
+var builtinPacks = map[string][]LabelConfig{
+	// github is GitHub's default label set, and the labels used for repos
+	// without any configured.
+	"github": {
+		{Name: "bug", Description: "Something isn't working"},
+		{Name: "feature", Description: "New feature or request"},
+		{Name: "question", Description: "Further information is requested"},
+		{Name: "documentation", Description: "Improvements or additions to documentation"},
+		{Name: "enhancement", Description: "Improvement to an existing feature"},
+	},
+	// kubernetes-style is the kind/ label set used across the Kubernetes
+	// project.
+	"kubernetes-style": {
+		{Name: "kind/bug", Description: "Something is broken or behaves incorrectly"},
+		{Name: "kind/feature", Description: "A request for new functionality"},
+		{Name: "kind/documentation", Description: "Missing, wrong, or unclear documentation"},
+		{Name: "kind/support", Description: "A question or request for help rather than a defect"},
+		{Name: "kind/cleanup", Description: "Cleaning up code, process, or technical debt"},
+		{Name: "kind/regression", Description: "Something that worked in a previous release no longer works"},
+		{Name: "kind/failing-test", Description: "A test that fails consistently"},
+		{Name: "kind/flake", Description: "A test that fails intermittently"},
+	},
+	// priority is the Kubernetes-style priority/ label set.
+	"priority": {
+		{Name: "priority/critical-urgent", Description: "Data loss, a security hole, or an outage; needs attention now"},
+		{Name: "priority/important-soon", Description: "Affects many users and should be fixed in the next release"},
+		{Name: "priority/important-longterm", Description: "Important, but can wait beyond the next release"},
+		{Name: "priority/backlog", Description: "Worth doing when someone has time"},
+	},
+}
+
+// BuiltinPack returns a copy of the labels of the built-in label pack name,
+// or nil if there is none.
+func BuiltinPack(name string) []LabelConfig {
+	pack, ok := builtinPacks[name]
+	if !ok {
+		return nil
+	}
+	return append([]LabelConfig(nil), pack...)
+}
+
+// BuiltinPackNames returns the names of the built-in label packs, sorted.
+func BuiltinPackNames() []string {
+	names := make([]string, 0, len(builtinPacks))
+	for name := range builtinPacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pack returns the labels of the pack name: the one defined in s.Packs,
+// else the built-in one.
+func (s RepoSet) pack(name string) ([]LabelConfig, bool) {
+	if pack, ok := s.Packs[name]; ok {
+		return pack, true
+	}
+	pack, ok := builtinPacks[name]
+	return pack, ok
+}
+
+// expandLabels returns the labels of packs, in order, followed by labels. A
+// label replaces an earlier one of the same name, so a repo can redefine a
+// pack label. Unknown packs are skipped.
+func (s RepoSet) expandLabels(packs []string, labels []LabelConfig) []LabelConfig {
+	if len(packs) == 0 {
+		return labels
+	}
+	var out []LabelConfig
+	index := make(map[string]int)
+	add := func(l LabelConfig) {
+		key := strings.ToLower(l.Name)
+		if i, ok := index[key]; ok {
+			out[i] = l
+			return
+		}
+		index[key] = len(out)
+		out = append(out, l)
+	}
+	for _, name := range packs {
+		pack, _ := s.pack(name)
+		for _, l := range pack {
+			add(l)
+		}
+	}
+	for _, l := range labels {
+		add(l)
+	}
+	return out
+}
+
+// validatePacks checks the label packs defined in the config.
+func validatePacks(packs map[string][]LabelConfig) error {
+	for name, labels := range packs {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("packs: pack name is required")
+		}
+		if len(labels) == 0 {
+			return fmt.Errorf("packs %s: at least one label is required", name)
+		}
+		if err := validateLabels(labels); err != nil {
+			return fmt.Errorf("packs %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validatePackRefs checks that each of names is a defined or built-in
+// label pack.
+func validatePackRefs(packs map[string][]LabelConfig, names []string) error {
+	set := RepoSet{Packs: packs}
+	for _, name := range names {
+		if _, ok := set.pack(name); !ok {
+			return fmt.Errorf("label_packs: unknown pack %q (built-in packs: %s)", name, strings.Join(BuiltinPackNames(), ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func labelNames(labels []LabelConfig) []string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+	}
+	return names
+}
+
+func TestBuiltinPacks(t *testing.T) {
+	if got := BuiltinPackNames(); !reflect.DeepEqual(got, []string{"github", "kubernetes-style", "priority"}) {
+		t.Errorf("BuiltinPackNames() = %v", got)
+	}
+	for _, name := range BuiltinPackNames() {
+		if err := validateLabels(BuiltinPack(name)); err != nil || len(BuiltinPack(name)) == 0 {
+			t.Errorf("built-in pack %s is invalid: %v", name, err)
+		}
+	}
+	if BuiltinPack("nope") != nil {
+		t.Error("expected no labels for an unknown pack")
+	}
+
+	// Callers get a copy
+	BuiltinPack("github")[0].Name = "changed"
+	if BuiltinPack("github")[0].Name != "bug" {
+		t.Error("expected BuiltinPack to return a copy")
+	}
+}
+
+func TestResolveLabelPacks(t *testing.T) {
+	cfg, err := Parse([]byte(`
+packs:
+  teams:
+    - name: team/storage
+      description: Owned by the storage team
+    - name: team/api
+      description: Owned by the API team
+  priority:
+    - name: p1
+      description: Urgent
+org_defaults:
+  myorg:
+    label_packs: [kubernetes-style, teams]
+    labels:
+      - name: kind/bug
+        description: Our own wording
+repos:
+  - name: myorg/api
+    label_packs: [priority]
+  - name: myorg/web
+    custom_prompt: Web app
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	web, _ := cfg.Repo("myorg/web")
+	names := labelNames(web.Labels)
+	if len(names) != 10 || names[0] != "kind/bug" || names[8] != "team/storage" || names[9] != "team/api" {
+		t.Errorf("myorg/web labels = %v, want kubernetes-style then teams", names)
+	}
+	if web.Labels[0].Description != "Our own wording" {
+		t.Errorf("expected the org's own label to replace the pack's, got %q", web.Labels[0].Description)
+	}
+
+	// A layer with packs replaces earlier labels and packs; a configured
+	// pack replaces the built-in one of the same name
+	api, _ := cfg.Repo("myorg/api")
+	if got := labelNames(api.Labels); !reflect.DeepEqual(got, []string{"p1"}) {
+		t.Errorf("myorg/api labels = %v, want [p1]", got)
+	}
+
+	// Labels without packs are left as they are
+	set := RepoSet{Repos: []RepoConfig{{Name: "o/r", Labels: []LabelConfig{{Name: "bug"}}}}}
+	if got, _ := set.Resolve("o/r"); !reflect.DeepEqual(got.Labels, []LabelConfig{{Name: "bug"}}) {
+		t.Errorf("unexpected labels %+v", got.Labels)
+	}
+}
+
+func TestValidateLabelPacks(t *testing.T) {
+	for _, tc := range []struct{ yaml, want string }{
+		{"repos:\n  - name: o/r\n    label_packs: [nope]\n", `unknown pack "nope"`},
+		{"org_defaults:\n  o:\n    label_packs: [nope]\n", `org_defaults o: label_packs: unknown pack`},
+		{"packs:\n  empty: []\n", "packs empty: at least one label is required"},
+		{"packs:\n  teams:\n    - description: x\n", "packs teams: labels[0]: name is required"},
+	} {
+		if _, err := Parse([]byte(tc.yaml)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tc.yaml, err, tc.want)
+		}
+	}
+}
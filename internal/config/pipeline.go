@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultProcessTimeout is how long watch may spend on one issue when
+// PipelineConfig.ProcessTimeout is empty.
+const DefaultProcessTimeout = 30 * time.Second
+
+// PipelineConfig tunes how watch processes issue events.
+type PipelineConfig struct {
+	// ProcessTimeout bounds the dedup, classification, and notification of
+	// one issue, such as "5m" for a slow local LLM; 30s by default. It is
+	// separate from the shutdown drain, which gives an in-flight issue at
+	// most 30s more once watch is stopped.
+	ProcessTimeout string `yaml:"process_timeout"`
+}
+
+// Timeout returns how long processing one issue may take.
+func (p PipelineConfig) Timeout() (time.Duration, error) {
+	if p.ProcessTimeout == "" {
+		return DefaultProcessTimeout, nil
+	}
+	d, err := time.ParseDuration(p.ProcessTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid process_timeout %q: %w", p.ProcessTimeout, err)
+	}
+	return d, nil
+}
+
+func (p PipelineConfig) validate() error {
+	d, err := p.Timeout()
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("process_timeout must be positive, got %s", p.ProcessTimeout)
+	}
+	return nil
+}
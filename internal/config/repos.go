@@ -0,0 +1,232 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsRepoPattern reports whether name is a glob pattern such as "myorg/*"
+// rather than a single owner/repo.
+func IsRepoPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// MatchRepo reports whether the repo fullName matches name, which is either
+// an exact owner/repo or a glob pattern as accepted by path.Match. Matching
+// is case-insensitive, like GitHub repo names.
+func MatchRepo(name, fullName string) bool {
+	name, fullName = strings.ToLower(name), strings.ToLower(fullName)
+	if !IsRepoPattern(name) {
+		return name == fullName
+	}
+	ok, err := path.Match(name, fullName)
+	return err == nil && ok
+}
+
+// Excluded reports whether fullName matches one of the exclude patterns.
+func Excluded(exclude []string, fullName string) bool {
+	for _, pattern := range exclude {
+		if MatchRepo(pattern, fullName) {
+			return true
+		}
+	}
+	return false
+}
+
+// RepoSettings holds settings that apply to a repo as a whole: the defaults
+// for an organization's repos, or the contents of a repo's own
+// .github/triage.yml.
+type RepoSettings struct {
+	Labels              []LabelConfig `yaml:"labels"`
+	LabelPacks          []string      `yaml:"label_packs"`
+	CustomPrompt        string        `yaml:"custom_prompt"`
+	Examples            []Example     `yaml:"examples"`
+	SimilarityThreshold *float64      `yaml:"similarity_threshold"`
+	Ignore              *IgnoreRules  `yaml:"ignore"`
+	SkipIfLabeled       *bool         `yaml:"skip_if_labeled"`
+	ParallelClassify    *bool         `yaml:"parallel_classify"`
+	Language            string        `yaml:"language"`
+}
+
+// settings returns the RepoSettings part of a repo entry.
+func (rc RepoConfig) settings() RepoSettings {
+	return RepoSettings{
+		Labels:              rc.Labels,
+		LabelPacks:          rc.LabelPacks,
+		CustomPrompt:        rc.CustomPrompt,
+		Examples:            rc.Examples,
+		SimilarityThreshold: rc.SimilarityThreshold,
+		Ignore:              rc.Ignore,
+		SkipIfLabeled:       rc.SkipIfLabeled,
+		ParallelClassify:    rc.ParallelClassify,
+		Language:            rc.Language,
+	}
+}
+
+// RepoSet is the per-repo part of the config: org defaults, repo entries,
+// exclusions, and the label packs they use.
+type RepoSet struct {
+	OrgDefaults map[string]RepoSettings
+	Repos       []RepoConfig
+	Exclude     []string
+	Packs       map[string][]LabelConfig
+}
+
+// Resolve returns the effective per-repo settings for fullName.
+//
+// Settings are layered, each layer overriding only the fields it sets: the
+// defaults of the repo's owner in OrgDefaults, then matching pattern entries
+// in file order, then an exact entry for the repo. Org defaults and patterns
+// are skipped for repos matching an exclude pattern, but an exact entry
+// always applies. A layer's labels and label packs replace earlier ones
+// together, and the returned Labels include those of the packs. The
+// returned config is named fullName. The bool is false if nothing applies.
+func (s RepoSet) Resolve(fullName string) (RepoConfig, bool) {
+	return s.ResolveWithRemote(fullName, nil)
+}
+
+// ResolveWithRemote is like Resolve but layers remote, the settings from the
+// repo's own .github/triage.yml, between pattern entries and the exact entry:
+// repo maintainers can override org-wide settings, and the operator's exact
+// entry still has the final say. A nil remote is ignored.
+func (s RepoSet) ResolveWithRemote(fullName string, remote *RepoSettings) (RepoConfig, bool) {
+	resolved := RepoConfig{Name: fullName}
+	found := false
+	excluded := Excluded(s.Exclude, fullName)
+
+	apply := func(rs RepoSettings) {
+		found = true
+		// A layer's labels and packs replace earlier ones together
+		if len(rs.Labels) > 0 || len(rs.LabelPacks) > 0 {
+			resolved.Labels = rs.Labels
+			resolved.LabelPacks = rs.LabelPacks
+		}
+		if rs.CustomPrompt != "" {
+			resolved.CustomPrompt = rs.CustomPrompt
+		}
+		if len(rs.Examples) > 0 {
+			resolved.Examples = rs.Examples
+		}
+		if rs.SimilarityThreshold != nil {
+			resolved.SimilarityThreshold = rs.SimilarityThreshold
+		}
+		if rs.Ignore != nil {
+			resolved.Ignore = rs.Ignore
+		}
+		if rs.SkipIfLabeled != nil {
+			resolved.SkipIfLabeled = rs.SkipIfLabeled
+		}
+		if rs.ParallelClassify != nil {
+			resolved.ParallelClassify = rs.ParallelClassify
+		}
+		if rs.Language != "" {
+			resolved.Language = rs.Language
+		}
+	}
+
+	if !excluded {
+		owner, _, _ := strings.Cut(fullName, "/")
+		for org, oc := range s.OrgDefaults {
+			if strings.EqualFold(org, owner) {
+				apply(oc)
+				break
+			}
+		}
+		for _, rc := range s.Repos {
+			if IsRepoPattern(rc.Name) && MatchRepo(rc.Name, fullName) {
+				apply(rc.settings())
+			}
+		}
+	}
+	if remote != nil {
+		apply(*remote)
+	}
+	for _, rc := range s.Repos {
+		if !IsRepoPattern(rc.Name) && MatchRepo(rc.Name, fullName) {
+			apply(rc.settings())
+			break
+		}
+	}
+	resolved.Labels = s.expandLabels(resolved.LabelPacks, resolved.Labels)
+	return resolved, found
+}
+
+// RepoSet returns the per-repo part of the config.
+func (c *Config) RepoSet() RepoSet {
+	return RepoSet{OrgDefaults: c.OrgDefaults, Repos: c.Repos, Exclude: c.Exclude, Packs: c.Packs}
+}
+
+// Repo returns the effective per-repo settings for fullName; see
+// RepoSet.Resolve.
+func (c *Config) Repo(fullName string) (RepoConfig, bool) {
+	return c.RepoSet().Resolve(fullName)
+}
+
+// RemoteConfigPath is where a repository keeps its own triage settings.
+const RemoteConfigPath = ".github/triage.yml"
+
+// ParseRepoSettings parses a repository's .github/triage.yml and validates
+// it like a repo entry of the main config, with its label_packs looked up in
+// packs and the built-in packs. Unlike the main config, environment
+// variables are not expanded, since the file is written by the repo's
+// maintainers, and unknown keys are an error.
+func ParseRepoSettings(data []byte, packs map[string][]LabelConfig) (*RepoSettings, error) {
+	var rs RepoSettings
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rs); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("parsing %s: %w", RemoteConfigPath, err)
+	}
+	if err := validateLabels(rs.Labels); err != nil {
+		return nil, fmt.Errorf("%s: %w", RemoteConfigPath, err)
+	}
+	if err := rs.Ignore.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", RemoteConfigPath, err)
+	}
+	if err := validateExamples(rs.Examples); err != nil {
+		return nil, fmt.Errorf("%s: %w", RemoteConfigPath, err)
+	}
+	if err := validatePackRefs(packs, rs.LabelPacks); err != nil {
+		return nil, fmt.Errorf("%s: %w", RemoteConfigPath, err)
+	}
+	if rs.SimilarityThreshold != nil && (*rs.SimilarityThreshold < 0 || *rs.SimilarityThreshold > 1) {
+		return nil, fmt.Errorf("%s: similarity_threshold must be between 0 and 1, got %f",
+			RemoteConfigPath, *rs.SimilarityThreshold)
+	}
+	return &rs, nil
+}
+
+// validateExamples checks that each few-shot example has a title and labels.
+func validateExamples(examples []Example) error {
+	for i, ex := range examples {
+		if strings.TrimSpace(ex.Title) == "" {
+			return fmt.Errorf("examples[%d]: title is required", i)
+		}
+		if len(ex.Labels) == 0 {
+			return fmt.Errorf("examples[%d]: at least one label is required", i)
+		}
+	}
+	return nil
+}
+
+// validateRepoPattern checks a repos[].name or exclude entry. Patterns may
+// only glob the repo part: the owner must be given literally so watch can
+// list the owner's repos to expand them.
+func validateRepoPattern(name string) error {
+	owner, repo, ok := strings.Cut(name, "/")
+	if !ok || owner == "" || repo == "" {
+		return fmt.Errorf("expected owner/repo or owner/pattern, got %q", name)
+	}
+	if IsRepoPattern(owner) {
+		return fmt.Errorf("owner cannot be a pattern in %q", name)
+	}
+	if _, err := path.Match(repo, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchRepo(t *testing.T) {
+	tests := []struct {
+		name, fullName string
+		want           bool
+	}{
+		{"myorg/api", "myorg/api", true},
+		{"MyOrg/API", "myorg/api", true},
+		{"myorg/api", "myorg/api-v2", false},
+		{"myorg/*", "myorg/api", true},
+		{"myorg/*", "other/api", false},
+		{"myorg/infra-*", "myorg/infra-dns", true},
+		{"myorg/infra-*", "myorg/api", false},
+		{"myorg/svc-[ab]", "myorg/svc-b", true},
+		{"myorg/[", "myorg/[", false}, // malformed patterns match nothing
+	}
+	for _, tt := range tests {
+		if got := MatchRepo(tt.name, tt.fullName); got != tt.want {
+			t.Errorf("MatchRepo(%q, %q) = %v, want %v", tt.name, tt.fullName, got, tt.want)
+		}
+	}
+}
+
+func TestResolveRepo(t *testing.T) {
+	orgThreshold, exactThreshold := 0.9, 0.95
+	repos := []RepoConfig{
+		{Name: "myorg/api", SimilarityThreshold: &exactThreshold},
+		{Name: "myorg/*", Labels: []LabelConfig{{Name: "bug"}}, CustomPrompt: "Org prompt", SimilarityThreshold: &orgThreshold},
+		{Name: "myorg/web-*", CustomPrompt: "Web prompt"},
+	}
+	exclude := []string{"myorg/infra-*"}
+
+	set := RepoSet{Repos: repos, Exclude: exclude}
+
+	rc, ok := set.Resolve("myorg/api")
+	if !ok || rc.Name != "myorg/api" || *rc.SimilarityThreshold != 0.95 || rc.CustomPrompt != "Org prompt" || len(rc.Labels) != 1 {
+		t.Errorf("exact entry should override the pattern field by field, got %+v", rc)
+	}
+
+	rc, _ = set.Resolve("myorg/web-shop")
+	if rc.CustomPrompt != "Web prompt" || *rc.SimilarityThreshold != 0.9 {
+		t.Errorf("later pattern should override earlier one, got %+v", rc)
+	}
+
+	if rc, ok := set.Resolve("myorg/infra-dns"); ok {
+		t.Errorf("excluded repo should not match patterns, got %+v", rc)
+	}
+
+	if _, ok := set.Resolve("other/api"); ok {
+		t.Error("expected no match for another owner")
+	}
+
+	// An exact entry applies even to an excluded repo.
+	set.Repos = append(set.Repos, RepoConfig{Name: "myorg/infra-dns", CustomPrompt: "DNS"})
+	if rc, ok := set.Resolve("myorg/infra-dns"); !ok || rc.CustomPrompt != "DNS" || rc.Labels != nil {
+		t.Errorf("expected only the exact entry, got %+v", rc)
+	}
+}
+
+func TestParseRepoPatterns(t *testing.T) {
+	cfg, err := Parse([]byte("repos:\n  - name: \"myorg/*\"\n    custom_prompt: Org\nexclude:\n  - \"myorg/infra-*\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc, ok := cfg.Repo("myorg/api"); !ok || rc.CustomPrompt != "Org" {
+		t.Errorf("Repo(myorg/api) = %+v, %v", rc, ok)
+	}
+	if _, ok := cfg.Repo("myorg/infra-ci"); ok {
+		t.Error("expected myorg/infra-ci to be excluded")
+	}
+
+	for _, tc := range []struct{ yaml, want string }{
+		{"repos:\n  - name: \"*/api\"\n", "owner cannot be a pattern"},
+		{"repos:\n  - name: \"myorg/[\"\n", "invalid pattern"},
+		{"repos:\n  - name: myorg\n", "expected owner/repo"},
+		{"exclude:\n  - \"*\"\n", "exclude:"},
+	} {
+		if _, err := Parse([]byte(tc.yaml)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tc.yaml, err, tc.want)
+		}
+	}
+}
+
+func TestResolveRepoOrgDefaults(t *testing.T) {
+	orgThreshold, repoThreshold := 0.8, 0.95
+	set := RepoSet{
+		OrgDefaults: map[string]RepoSettings{
+			"MyOrg": {Labels: []LabelConfig{{Name: "bug"}}, CustomPrompt: "Org prompt", SimilarityThreshold: &orgThreshold},
+		},
+		Repos: []RepoConfig{
+			{Name: "myorg/web-*", CustomPrompt: "Web prompt"},
+			{Name: "myorg/web-shop", SimilarityThreshold: &repoThreshold},
+		},
+		Exclude: []string{"myorg/infra-*"},
+	}
+
+	rc, ok := set.Resolve("myorg/api")
+	if !ok || rc.CustomPrompt != "Org prompt" || *rc.SimilarityThreshold != 0.8 || len(rc.Labels) != 1 {
+		t.Errorf("expected org defaults, got %+v", rc)
+	}
+
+	rc, _ = set.Resolve("myorg/web-shop")
+	if rc.CustomPrompt != "Web prompt" || *rc.SimilarityThreshold != 0.95 || len(rc.Labels) != 1 {
+		t.Errorf("expected org < pattern < repo layering, got %+v", rc)
+	}
+
+	if _, ok := set.Resolve("myorg/infra-dns"); ok {
+		t.Error("org defaults should not apply to excluded repos")
+	}
+	if _, ok := set.Resolve("other/api"); ok {
+		t.Error("org defaults should not apply to other owners")
+	}
+}
+
+func TestParseOrgDefaults(t *testing.T) {
+	cfg, err := Parse([]byte("org_defaults:\n  myorg:\n    custom_prompt: Org\n    similarity_threshold: 0.9\nrepos:\n  - name: myorg/api\n    custom_prompt: API\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc, _ := cfg.Repo("myorg/api"); rc.CustomPrompt != "API" || *rc.SimilarityThreshold != 0.9 {
+		t.Errorf("Repo(myorg/api) = %+v", rc)
+	}
+
+	for _, tc := range []struct{ yaml, want string }{
+		{"org_defaults:\n  myorg/api:\n    custom_prompt: x\n", "invalid org name"},
+		{"org_defaults:\n  myorg:\n    similarity_threshold: 2\n", "between 0 and 1"},
+	} {
+		if _, err := Parse([]byte(tc.yaml)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tc.yaml, err, tc.want)
+		}
+	}
+}
+
+func TestParseSkipIfLabeled(t *testing.T) {
+	cfg, err := Parse([]byte(`
+defaults:
+  skip_if_labeled: true
+org_defaults:
+  myorg:
+    skip_if_labeled: false
+repos:
+  - name: myorg/api
+    skip_if_labeled: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Defaults.SkipIfLabeled {
+		t.Error("expected defaults.skip_if_labeled to be true")
+	}
+	if rc, _ := cfg.Repo("myorg/web"); rc.SkipIfLabeled == nil || *rc.SkipIfLabeled {
+		t.Errorf("expected org default false, got %v", rc.SkipIfLabeled)
+	}
+	// false in org defaults is a setting, not "unset": the repo entry must
+	// set it explicitly to override.
+	if rc, _ := cfg.Repo("myorg/api"); rc.SkipIfLabeled == nil || !*rc.SkipIfLabeled {
+		t.Errorf("expected repo override true, got %v", rc.SkipIfLabeled)
+	}
+}
+
+func TestParseParallelClassify(t *testing.T) {
+	cfg, err := Parse([]byte(`
+defaults:
+  parallel_classify: true
+repos:
+  - name: myorg/api
+    parallel_classify: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Defaults.ParallelClassify {
+		t.Error("expected defaults.parallel_classify to be true")
+	}
+	if rc, _ := cfg.Repo("myorg/api"); rc.ParallelClassify == nil || *rc.ParallelClassify {
+		t.Errorf("expected repo override false, got %v", rc.ParallelClassify)
+	}
+}
+
+func TestParseRepoLanguage(t *testing.T) {
+	cfg, err := Parse([]byte(`
+org_defaults:
+  myorg:
+    language: de
+repos:
+  - name: myorg/api
+    labels:
+      - name: bug
+        description: Something is broken
+        descriptions:
+          de: Etwas funktioniert nicht
+          pt: Algo está quebrado
+  - name: myorg/site
+    language: pt-BR
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, _ := cfg.Repo("myorg/api")
+	if rc.Language != "de" {
+		t.Errorf("expected the org language, got %q", rc.Language)
+	}
+	if rc, _ := cfg.Repo("myorg/site"); rc.Language != "pt-BR" {
+		t.Errorf("expected the repo's own language, got %q", rc.Language)
+	}
+
+	bug := rc.Labels[0]
+	for lang, want := range map[string]string{
+		"":      "Something is broken",
+		"de":    "Etwas funktioniert nicht",
+		"DE":    "Etwas funktioniert nicht",
+		"de-AT": "Etwas funktioniert nicht",
+		"pt_BR": "Algo está quebrado",
+		"fr":    "Something is broken",
+	} {
+		if got := bug.DescriptionFor(lang); got != want {
+			t.Errorf("DescriptionFor(%q) = %q, want %q", lang, got, want)
+		}
+	}
+	exact := LabelConfig{Description: "Bug", Descriptions: map[string]string{"pt": "Erro", "pt-BR": "Defeito"}}
+	if got := exact.DescriptionFor("pt-br"); got != "Defeito" {
+		t.Errorf("expected the regional translation to win, got %q", got)
+	}
+
+	if _, err := Parse([]byte("repos:\n  - name: myorg/api\n    labels:\n      - name: bug\n        descriptions:\n          \"\": x\n")); err == nil || !strings.Contains(err.Error(), "language code is required") {
+		t.Errorf("expected an error for a blank language code, got %v", err)
+	}
+}
+
+func TestParseRepoSettings(t *testing.T) {
+	rs, err := ParseRepoSettings([]byte("labels:\n  - name: ios\n    description: iOS only\ncustom_prompt: Mobile app\nsimilarity_threshold: 0.8\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs.Labels) != 1 || rs.CustomPrompt != "Mobile app" || *rs.SimilarityThreshold != 0.8 {
+		t.Errorf("unexpected settings: %+v", rs)
+	}
+
+	if rs, err := ParseRepoSettings(nil, nil); err != nil || rs == nil {
+		t.Errorf("expected an empty file to parse, got %+v, %v", rs, err)
+	}
+
+	for _, tc := range []struct{ yaml, want string }{
+		{"custom_promt: x\n", "custom_promt"},
+		{"labels:\n  - description: x\n", "name is required"},
+		{"similarity_threshold: 1.5\n", "between 0 and 1"},
+		{"examples:\n  - labels: [bug]\n", "examples[0]: title is required"},
+		{"examples:\n  - title: Crash\n", "examples[0]: at least one label is required"},
+		{"label_packs: [mobil]\n", `unknown pack "mobil"`},
+	} {
+		if _, err := ParseRepoSettings([]byte(tc.yaml), nil); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseRepoSettings(%q) error = %v, want %q", tc.yaml, err, tc.want)
+		}
+	}
+
+	// Packs defined in the main config may be used
+	packs := map[string][]LabelConfig{"mobile": {{Name: "ios"}}}
+	if rs, err := ParseRepoSettings([]byte("label_packs: [mobile, priority]\n"), packs); err != nil || len(rs.LabelPacks) != 2 {
+		t.Errorf("expected defined and built-in packs to be accepted, got %+v, %v", rs, err)
+	}
+
+	set := RepoSet{
+		Repos: []RepoConfig{{Name: "org/*", CustomPrompt: "Org"}, {Name: "org/app", SimilarityThreshold: rs.SimilarityThreshold}},
+	}
+	remoteThreshold := 0.6
+	got, ok := set.ResolveWithRemote("org/app", &RepoSettings{CustomPrompt: "Repo", SimilarityThreshold: &remoteThreshold})
+	if !ok || got.CustomPrompt != "Repo" || *got.SimilarityThreshold != 0.8 {
+		t.Errorf("expected repo file over patterns and exact entry over repo file, got %+v", got)
+	}
+}
+
+func TestResolveRepoExamples(t *testing.T) {
+	orgExamples := []Example{{Title: "Org example", Labels: []string{"bug"}}}
+	repoExamples := []Example{{Title: "Repo example", BodyExcerpt: "Fails offline", Labels: []string{"sync"}}}
+	set := RepoSet{
+		OrgDefaults: map[string]RepoSettings{"org": {Examples: orgExamples}},
+		Repos:       []RepoConfig{{Name: "org/app", Examples: repoExamples}},
+	}
+
+	if got, _ := set.Resolve("org/app"); !reflect.DeepEqual(got.Examples, repoExamples) {
+		t.Errorf("org/app examples = %+v, want the repo's own", got.Examples)
+	}
+	if got, _ := set.Resolve("org/lib"); !reflect.DeepEqual(got.Examples, orgExamples) {
+		t.Errorf("org/lib examples = %+v, want the org defaults", got.Examples)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Provider types supported by this build.
+var (
+	EmbeddingProviderTypes = []string{"openai", "ollama"}
+	LLMProviderTypes       = []string{"openai", "anthropic", "ollama"}
+)
+
+// schemaEnums lists the allowed values for enumerated fields, keyed by their
+// dotted YAML path.
+var schemaEnums = map[string][]string{
+	"github.auth":              {"app", "token"},
+	"logging.format":           LogFormats,
+	"logging.level":            LogLevels,
+	"providers.embedding.type": EmbeddingProviderTypes,
+	"providers.llm.type":       LLMProviderTypes,
+	"server.tokens.scopes":     {"read", "triage", "admin"},
+}
+
+// JSONSchema returns a JSON Schema describing the config file, derived from
+// the Config struct's yaml tags. Editors can use it for completion and
+// validation of config.yaml.
+func JSONSchema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "triage config"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func schemaFor(t reflect.Type, path string) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// A Mention may also be a plain string; see its UnmarshalYAML.
+	if t == reflect.TypeOf(Mention{}) {
+		return map[string]any{"anyOf": []any{
+			map[string]any{"type": "string"},
+			schemaFor(reflect.TypeOf(mentionFields{}), path),
+		}}
+	}
+
+	var s map[string]any
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" || !f.IsExported() {
+				continue
+			}
+			props[name] = schemaFor(f.Type, joinPath(path, name))
+		}
+		s = map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	case reflect.Slice:
+		s = map[string]any{"type": "array", "items": schemaFor(t.Elem(), path)}
+		return s
+	case reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), path)}
+	case reflect.String:
+		s = map[string]any{"type": "string"}
+	case reflect.Bool:
+		s = map[string]any{"type": "boolean"}
+	case reflect.Float32, reflect.Float64:
+		s = map[string]any{"type": "number"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = map[string]any{"type": "integer"}
+	default:
+		s = map[string]any{}
+	}
+
+	if enum, ok := schemaEnums[path]; ok {
+		s["enum"] = enum
+	}
+	return s
+}
+
+// mentionFields has Mention's fields without its UnmarshalYAML.
+type mentionFields Mention
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
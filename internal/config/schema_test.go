@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema: %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if schema["additionalProperties"] != false {
+		t.Error("expected top-level additionalProperties false")
+	}
+
+	props := schema["properties"].(map[string]any)
+	for _, key := range []string{"github", "providers", "notify", "defaults", "store", "server", "repos"} {
+		if _, ok := props[key]; !ok {
+			t.Errorf("schema missing top-level property %q", key)
+		}
+	}
+
+	llm := props["providers"].(map[string]any)["properties"].(map[string]any)["llm"].(map[string]any)
+	llmType := llm["properties"].(map[string]any)["type"].(map[string]any)
+	if enum, ok := llmType["enum"].([]any); !ok || len(enum) != 3 {
+		t.Errorf("expected enum on providers.llm.type, got %v", llmType)
+	}
+
+	repos := props["repos"].(map[string]any)
+	if repos["type"] != "array" {
+		t.Errorf("expected repos to be an array, got %v", repos["type"])
+	}
+	threshold := repos["items"].(map[string]any)["properties"].(map[string]any)["similarity_threshold"].(map[string]any)
+	if threshold["type"] != "number" {
+		t.Errorf("expected pointer field to map to number, got %v", threshold["type"])
+	}
+
+	notify := props["notify"].(map[string]any)["properties"].(map[string]any)
+	mention := notify["mentions"].(map[string]any)["additionalProperties"].(map[string]any)
+	if anyOf, ok := mention["anyOf"].([]any); !ok || len(anyOf) != 2 {
+		t.Errorf("expected mentions to accept a string or an object, got %v", mention)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jacklau/triage/internal/secrets"
+)
+
+// SecretsConfig controls secret manager references such as
+// api_key: vault://secret/openai#key.
+type SecretsConfig struct {
+	// RefreshRaw is how often long-running commands re-resolve references
+	// to pick up rotated secrets; empty disables refreshing.
+	RefreshRaw string `yaml:"refresh"`
+}
+
+// Refresh returns the parsed refresh interval, or 0 if refreshing is off.
+func (s SecretsConfig) Refresh() (time.Duration, error) {
+	if s.RefreshRaw == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s.RefreshRaw)
+}
+
+// secretFields returns the config values that may hold secret references,
+// keyed by their YAML path.
+func (c *Config) secretFields() map[string]*string {
+	fields := map[string]*string{
+		"github.token":                &c.GitHub.Token,
+		"github.private_key":          &c.GitHub.PrivateKey,
+		"providers.embedding.api_key": &c.Providers.Embedding.APIKey,
+		"providers.llm.api_key":       &c.Providers.LLM.APIKey,
+		"experiment.llm.api_key":      &c.Experiment.LLM.APIKey,
+		"notify.slack_webhook":        &c.Notify.SlackWebhook,
+		"notify.discord_webhook":      &c.Notify.DiscordWebhook,
+		"notify.slack_bot_token":      &c.Notify.SlackBotToken,
+		"integrations.jira.api_token": &c.Integrations.Jira.APIToken,
+		"server.slack_signing_secret": &c.Server.SlackSigningSecret,
+	}
+	for i := range c.Server.Tokens {
+		fields[fmt.Sprintf("server.tokens[%d].token", i)] = &c.Server.Tokens[i].Token
+	}
+	return fields
+}
+
+// ResolveSecrets replaces secret references in the config with the secrets
+// they point to, recording the references in SecretRefs so they can be
+// resolved again later. All references are tried; the errors are joined.
+func (c *Config) ResolveSecrets(ctx context.Context, r *secrets.Resolver) error {
+	fields := c.secretFields()
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var errs []error
+	for _, path := range paths {
+		field := fields[path]
+		if !secrets.IsRef(*field) {
+			continue
+		}
+		v, err := r.Resolve(ctx, *field)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if c.SecretRefs == nil {
+			c.SecretRefs = make(map[string]string)
+		}
+		c.SecretRefs[path] = *field
+		*field = v
+	}
+	return errors.Join(errs...)
+}
+
+// SecretField returns a pointer to the config value at the YAML path used in
+// SecretRefs, or nil if the path does not name a secret field.
+func (c *Config) SecretField(path string) *string {
+	return c.secretFields()[path]
+}
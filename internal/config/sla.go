@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultSLACheckInterval is how often serve looks for issues past their
+// SLA when SLAConfig.CheckInterval is empty.
+const defaultSLACheckInterval = 15 * time.Minute
+
+// SLAConfig sets how long a triaged issue may wait for a human response,
+// by the severity of its triage result, before serve sends a reminder. An
+// issue has a response once it is closed or assigned, or its suggestion is
+// approved, rejected, or applied. Durations such as "4h"; an empty one sends
+// no reminders for that severity.
+type SLAConfig struct {
+	// High covers likely duplicates and issues the classifier could not
+	// label.
+	High string `yaml:"high"`
+	// Medium covers results without a confident label.
+	Medium string `yaml:"medium"`
+	// Low covers confidently labeled issues.
+	Low string `yaml:"low"`
+	// CheckInterval is how often serve checks; 15m by default.
+	CheckInterval string `yaml:"check_interval"`
+}
+
+// Enabled reports whether any severity has an SLA.
+func (s SLAConfig) Enabled() bool {
+	return s.High != "" || s.Medium != "" || s.Low != ""
+}
+
+// Limits returns the SLA of each severity that has one, keyed by "high",
+// "medium", or "low".
+func (s SLAConfig) Limits() (map[string]time.Duration, error) {
+	limits := make(map[string]time.Duration)
+	for severity, raw := range map[string]string{"high": s.High, "medium": s.Medium, "low": s.Low} {
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", severity, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("%s must be positive, got %s", severity, raw)
+		}
+		limits[severity] = d
+	}
+	return limits, nil
+}
+
+// Interval returns how often serve checks for issues past their SLA.
+func (s SLAConfig) Interval() (time.Duration, error) {
+	if s.CheckInterval == "" {
+		return defaultSLACheckInterval, nil
+	}
+	d, err := time.ParseDuration(s.CheckInterval)
+	if err != nil {
+		return 0, fmt.Errorf("check_interval: %w", err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("check_interval must be positive, got %s", s.CheckInterval)
+	}
+	return d, nil
+}
+
+func (s SLAConfig) validate() error {
+	if _, err := s.Limits(); err != nil {
+		return err
+	}
+	_, err := s.Interval()
+	return err
+}
@@ -0,0 +1,262 @@
+package dedup
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// storedVector is an issue's decoded embedding.
+type storedVector struct {
+	number int
+	vec    []float32
+}
+
+// GenerationStore is implemented by stores that count changes to each repo's
+// embeddings. With it, a cached engine checks the count before each
+// comparison and reloads a repo whose embeddings another process has
+// changed.
+type GenerationStore interface {
+	EmbeddingGeneration(repoID int64) (int64, error)
+}
+
+// vectorCache holds the decoded embeddings of each repo's issues, so
+// comparisons don't decode every stored blob on each check. A repo's vectors
+// are loaded on first use; the engine keeps them current as it stores new
+// embeddings.
+type vectorCache struct {
+	mu    sync.RWMutex
+	repos map[int64]cachedRepo
+}
+
+// cachedRepo is a repo's vectors as of the store's embedding generation gen,
+// which is 0 when the store does not count generations.
+type cachedRepo struct {
+	vecs []storedVector
+	gen  int64
+}
+
+func newVectorCache() *vectorCache {
+	return &vectorCache{repos: make(map[int64]cachedRepo)}
+}
+
+// get returns the cached vectors for a repo, their generation and whether
+// they are loaded. The returned slice must not be modified.
+func (c *vectorCache) get(repoID int64) ([]storedVector, int64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.repos[repoID]
+	return r.vecs, r.gen, ok
+}
+
+// put replaces the cached vectors for a repo.
+func (c *vectorCache) put(repoID int64, vecs []storedVector, gen int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repos[repoID] = cachedRepo{vecs: vecs, gen: gen}
+}
+
+// set records an issue's new embedding, if the repo is loaded. gen is the
+// store's generation after the write; unless it directly follows the cached
+// one, another process has written too and the repo is dropped instead, to
+// be reloaded on next use. The repo's slice is copied so readers holding the
+// old one are unaffected.
+func (c *vectorCache) set(repoID int64, number int, vec []float32, gen int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old, ok := c.repos[repoID]
+	if !ok {
+		return
+	}
+	if gen != 0 && gen != old.gen+1 {
+		delete(c.repos, repoID)
+		return
+	}
+	vecs := make([]storedVector, len(old.vecs), len(old.vecs)+1)
+	copy(vecs, old.vecs)
+	for i := range vecs {
+		if vecs[i].number == number {
+			vecs[i].vec = vec
+			c.repos[repoID] = cachedRepo{vecs: vecs, gen: gen}
+			return
+		}
+	}
+	c.repos[repoID] = cachedRepo{vecs: append(vecs, storedVector{number: number, vec: vec}), gen: gen}
+}
+
+// invalidate drops a repo's cached vectors; they are reloaded on next use.
+func (c *vectorCache) invalidate(repoID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.repos, repoID)
+}
+
+// WithCache keeps each repo's decoded embeddings in memory after they are
+// first loaded. Embeddings stored by the engine update the cache. Those
+// written by other processes are seen on the next comparison if the store is
+// a GenerationStore, as the SQLite store is; otherwise not until Invalidate
+// is called.
+func WithCache() Option {
+	return func(e *Engine) { e.cache = newVectorCache() }
+}
+
+// Preload loads the stored embeddings of each repo into the cache. It does
+// nothing unless the engine was created WithCache.
+func (e *Engine) Preload(repoIDs ...int64) error {
+	if e.cache == nil {
+		return nil
+	}
+	for _, id := range repoIDs {
+		if _, err := e.reloadVectors(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate drops a repo's cached embeddings, e.g. after another process
+// has re-embedded its issues.
+func (e *Engine) Invalidate(repoID int64) {
+	if e.cache != nil {
+		e.cache.invalidate(repoID)
+	}
+}
+
+// vectors returns the decoded embeddings of a repo's issues, from the cache
+// when there is one and the store's generation shows they are current.
+func (e *Engine) vectors(repoID int64) ([]storedVector, error) {
+	if e.cache == nil {
+		return e.loadVectors(repoID)
+	}
+	if vecs, cached, ok := e.cache.get(repoID); ok {
+		gen, err := e.generation(repoID)
+		if err != nil {
+			return nil, err
+		}
+		if gen == cached {
+			return vecs, nil
+		}
+	}
+	return e.reloadVectors(repoID)
+}
+
+// reloadVectors loads a repo's embeddings into the cache. The generation is
+// read first, so a write during the load makes the next check reload again
+// rather than keep vectors missing it.
+func (e *Engine) reloadVectors(repoID int64) ([]storedVector, error) {
+	gen, err := e.generation(repoID)
+	if err != nil {
+		return nil, err
+	}
+	vecs, err := e.loadVectors(repoID)
+	if err != nil {
+		return nil, err
+	}
+	e.cache.put(repoID, vecs, gen)
+	return vecs, nil
+}
+
+// cacheStored records an embedding the engine has just stored for an issue,
+// if it caches vectors.
+func (e *Engine) cacheStored(repoID int64, number int, vec []float32) {
+	if e.cache == nil {
+		return
+	}
+	gen, err := e.generation(repoID)
+	if err != nil {
+		e.cache.invalidate(repoID)
+		return
+	}
+	e.cache.set(repoID, number, vec, gen)
+}
+
+// generation returns the store's embedding generation for a repo, or 0 if it
+// does not count them.
+func (e *Engine) generation(repoID int64) (int64, error) {
+	gs, ok := e.store.(GenerationStore)
+	if !ok {
+		return 0, nil
+	}
+	gen, err := gs.EmbeddingGeneration(repoID)
+	if err != nil {
+		return 0, fmt.Errorf("checking embeddings of repo %d: %w", repoID, err)
+	}
+	return gen, nil
+}
+
+// loadVectors reads and decodes a repo's stored embeddings.
+func (e *Engine) loadVectors(repoID int64) ([]storedVector, error) {
+	existing, err := e.store.GetEmbeddingsForRepo(repoID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching embeddings for repo %d: %w", repoID, err)
+	}
+	vecs := make([]storedVector, 0, len(existing))
+	for _, ie := range existing {
+		if v := DecodeEmbedding(ie.Embedding); len(v) > 0 {
+			vecs = append(vecs, storedVector{number: ie.Number, vec: v})
+		}
+	}
+	return vecs, nil
+}
+
+// maxCachedDrafts is the most draft embeddings Similar keeps.
+const maxCachedDrafts = 256
+
+// draftCache keeps the embeddings of recently looked-up drafts, keyed by
+// their embedded text, so the same draft sent again as a form is edited or
+// reloaded does not call the embedder. The least recently used entry is
+// evicted once it is full.
+type draftCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type draftEntry struct {
+	key string
+	vec []float32
+}
+
+func newDraftCache(size int) *draftCache {
+	return &draftCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the cached embedding of text, if any.
+func (c *draftCache) get(text string) ([]float32, bool) {
+	key := draftKey(text)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*draftEntry).vec, true
+}
+
+// put caches the embedding of text.
+func (c *draftCache) put(text string, vec []float32) {
+	key := draftKey(text)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*draftEntry).vec = vec
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&draftEntry{key: key, vec: vec})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*draftEntry).key)
+	}
+}
+
+// draftKey hashes a draft's text so long bodies are not kept as keys.
+func draftKey(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
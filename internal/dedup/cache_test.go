@@ -260,3 +260,119 @@ func TestStore_GetIssueEmbeddingHash_NoEmbedding(t *testing.T) {
 		t.Error("expected no embedding for issue without embedding")
 	}
 }
+
+func TestEngine_Cache(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	embedder := newMockEmbedder()
+	insertIssueWithEmbedding(t, db, repoID, 1, "Login page broken", []float32{0.9, 0.1, 0.0})
+
+	engine := NewEngine(embedder, db, WithThreshold(0.9), WithCache())
+	if err := engine.Preload(repoID); err != nil {
+		t.Fatalf("preloading: %v", err)
+	}
+
+	// Written behind the engine's back: seen through the store's generation.
+	insertIssueWithEmbedding(t, db, repoID, 2, "Login page dead", []float32{0.9, 0.1, 0.0})
+	result, err := engine.CheckStored(repoID, 1, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].Number != 2 {
+		t.Errorf("expected #2 written by another process, got %+v", result.Candidates)
+	}
+
+	// Embeddings stored by the engine update the cache.
+	if err := db.UpsertIssue(&store.Issue{RepoID: repoID, Number: 3, Title: "Login page not working", State: "open", CreatedAt: time.Now(), UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("upserting issue: %v", err)
+	}
+	embedder.addEmbedding("Login page not working", []float32{0.89, 0.12, 0.01})
+	if _, err := engine.CheckDuplicate(context.Background(), repoID, github.Issue{Number: 3, Title: "Login page not working"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err = engine.CheckStored(repoID, 1, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Candidates) != 2 {
+		t.Errorf("expected #2 and #3, got %+v", result.Candidates)
+	}
+}
+
+func TestEngine_PreloadWithoutCache(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	engine := NewEngine(newMockEmbedder(), db)
+	if err := engine.Preload(repoID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	engine.Invalidate(repoID)
+}
+
+func TestVectorCache_SetCopiesSlice(t *testing.T) {
+	c := newVectorCache()
+	c.set(1, 5, []float32{1}, 0)
+	if _, _, ok := c.get(1); ok {
+		t.Fatal("expected set to ignore repos that are not loaded")
+	}
+
+	c.put(1, []storedVector{{number: 5, vec: []float32{1}}}, 0)
+	before, _, _ := c.get(1)
+	c.set(1, 5, []float32{2}, 0)
+	c.set(1, 6, []float32{3}, 0)
+
+	if before[0].vec[0] != 1 {
+		t.Errorf("expected earlier readers to keep their slice, got %v", before[0].vec)
+	}
+	after, _, _ := c.get(1)
+	if len(after) != 2 || after[0].vec[0] != 2 || after[1].number != 6 {
+		t.Errorf("unexpected cached vectors: %+v", after)
+	}
+}
+
+func TestVectorCache_SetDropsRepoAfterGenerationGap(t *testing.T) {
+	c := newVectorCache()
+	c.put(1, []storedVector{{number: 5, vec: []float32{1}}}, 3)
+
+	c.set(1, 6, []float32{2}, 4)
+	if vecs, gen, ok := c.get(1); !ok || gen != 4 || len(vecs) != 2 {
+		t.Fatalf("expected the next generation to be applied, got %+v gen %d", vecs, gen)
+	}
+
+	// Another process wrote generation 5 before this one.
+	c.set(1, 7, []float32{3}, 6)
+	if _, _, ok := c.get(1); ok {
+		t.Error("expected the repo to be dropped after a generation gap")
+	}
+}
+
+func TestEngine_CacheInvalidatedOnDimensionMismatch(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	insertIssueWithEmbedding(t, db, repoID, 1, "Login page broken", []float32{0.9, 0.1, 0.0})
+
+	engine := NewEngine(newMockEmbedder(), db, WithCache())
+	if err := engine.Preload(repoID); err != nil {
+		t.Fatalf("preloading: %v", err)
+	}
+	if _, err := engine.findSimilar(repoID, 0, []float32{1, 0}, 0.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, ok := engine.cache.get(repoID); ok {
+		t.Error("expected mismatched vectors to be dropped from the cache")
+	}
+}
+
+func TestDraftCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newDraftCache(2)
+	c.put("a", []float32{1})
+	c.put("b", []float32{2})
+	c.get("a")
+	c.put("c", []float32{3})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("expected the least recently used draft to be evicted")
+	}
+	for _, text := range []string{"a", "c"} {
+		if _, ok := c.get(text); !ok {
+			t.Errorf("expected %q to stay cached", text)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package dedup
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"sort"
 
@@ -20,10 +21,21 @@ type EmbeddingStore interface {
 	GetIssue(repoID int64, number int) (*store.Issue, error)
 }
 
+// PullRequestStore is the storage used to match issues against pull
+// requests; see WithPullRequests.
+type PullRequestStore interface {
+	ListOpenPullRequests(repoID int64) ([]store.PullRequest, error)
+	UpdatePullRequestEmbedding(repoID int64, number int, embedding []byte, hash string) error
+}
+
 const (
 	defaultThreshold     = float32(0.85)
 	defaultMaxCandidates = 3
 	defaultMaxChars      = 8000
+
+	// embedBatchSize is the most issues EmbedStale sends the embedder at
+	// once.
+	embedBatchSize = 64
 )
 
 // Engine performs duplicate detection by comparing issue embeddings.
@@ -33,12 +45,53 @@ type Engine struct {
 	threshold     float32
 	maxCandidates int
 	maxChars      int
+	pulls         PullRequestStore
+	cache         *vectorCache
+	drafts        *draftCache
+
+	// documentPrefix and queryPrefix are prepended to the text embedded for
+	// stored issues and for issues compared against them; see WithPrefixes.
+	documentPrefix string
+	queryPrefix    string
 }
 
 // DedupResult contains the outcome of a duplicate check.
 type DedupResult struct {
 	IsDuplicate bool
 	Candidates  []github.DuplicateCandidate
+	// Embedding is the vector the checked issue was compared with, for
+	// reuse by later steps; see ContextWithEmbedding.
+	Embedding []float32
+}
+
+// embeddingKey is the context key for an issue embedding.
+type embeddingKey struct{}
+
+// contextEmbedding is an issue embedding carried in a context, with the
+// content hash it was computed from.
+type contextEmbedding struct {
+	hash string
+	vec  []float32
+}
+
+// ContextWithEmbedding returns a context carrying vec, the embedding of
+// issue as a query (see DedupResult.Embedding). Engine methods given the
+// context use it to compare that issue instead of calling the embedder, as
+// long as the issue's title and body are unchanged.
+func ContextWithEmbedding(ctx context.Context, issue github.Issue, vec []float32) context.Context {
+	return context.WithValue(ctx, embeddingKey{}, contextEmbedding{
+		hash: ContentHash(issue.Title, issue.Body),
+		vec:  vec,
+	})
+}
+
+// embeddingFromContext returns the embedding of issue carried by ctx, or nil.
+func embeddingFromContext(ctx context.Context, issue github.Issue) []float32 {
+	ce, ok := ctx.Value(embeddingKey{}).(contextEmbedding)
+	if !ok || ce.hash != ContentHash(issue.Title, issue.Body) {
+		return nil
+	}
+	return ce.vec
 }
 
 // Option configures an Engine.
@@ -59,6 +112,22 @@ func WithMaxChars(n int) Option {
 	return func(e *Engine) { e.maxChars = n }
 }
 
+// WithPullRequests enables MatchPullRequests, comparing issues with the open
+// pull requests in st.
+func WithPullRequests(st PullRequestStore) Option {
+	return func(e *Engine) { e.pulls = st }
+}
+
+// WithPrefixes sets the task prefixes some embedding models expect:
+// document is prepended to the text of issues embedded to be stored, and
+// query to the text of an issue compared against them, such as
+// "search_document: " and "search_query: " for nomic-embed-text, or
+// "passage: " and "query: " for E5. When they differ, an issue that is both
+// stored and checked is embedded twice.
+func WithPrefixes(document, query string) Option {
+	return func(e *Engine) { e.documentPrefix, e.queryPrefix = document, query }
+}
+
 // NewEngine creates a new dedup Engine.
 func NewEngine(embedder provider.Embedder, store EmbeddingStore, opts ...Option) *Engine {
 	e := &Engine{
@@ -67,6 +136,7 @@ func NewEngine(embedder provider.Embedder, store EmbeddingStore, opts ...Option)
 		threshold:     defaultThreshold,
 		maxCandidates: defaultMaxCandidates,
 		maxChars:      defaultMaxChars,
+		drafts:        newDraftCache(maxCachedDrafts),
 	}
 	for _, opt := range opts {
 		opt(e)
@@ -101,6 +171,30 @@ func (e *Engine) composeText(issue github.Issue) string {
 	return text
 }
 
+// documentText returns the text embedded for issue to be stored.
+func (e *Engine) documentText(issue github.Issue) string {
+	return e.documentPrefix + e.composeText(issue)
+}
+
+// queryText returns the text embedded for issue to compare it against stored
+// issues.
+func (e *Engine) queryText(issue github.Issue) string {
+	return e.queryPrefix + e.composeText(issue)
+}
+
+// asymmetric reports whether queries are embedded differently from stored
+// issues, so an issue's stored embedding cannot stand in for its query.
+func (e *Engine) asymmetric() bool {
+	return e.documentPrefix != e.queryPrefix
+}
+
+// contentHash returns the hash stored with the embedding of an issue's
+// title and body. It covers the document prefix, so stored embeddings are
+// recomputed when the prefix changes.
+func (e *Engine) contentHash(title, body string) string {
+	return ContentHash(e.documentPrefix+title, body)
+}
+
 // ContentHash computes a SHA-256 hash of the issue's title and body content.
 // This is used to determine if an issue's content has changed since it was last embedded.
 func ContentHash(title, body string) string {
@@ -132,79 +226,418 @@ func (e *Engine) CheckDuplicateWithThreshold(ctx context.Context, repoID int64,
 		threshold = thresholdOverride
 	}
 
-	// Compose the text and compute content hash
-	text := e.composeText(issue)
-	hash := ContentHash(issue.Title, issue.Body)
+	// Skip re-embedding if the content is unchanged
+	hash := e.contentHash(issue.Title, issue.Body)
+	if document := e.storedEmbedding(repoID, issue.Number, hash); document != nil {
+		query, err := e.queryEmbedding(ctx, issue, document)
+		if err != nil {
+			return nil, err
+		}
+		return e.findSimilar(repoID, issue.Number, query, threshold)
+	}
 
-	var embedding []float32
+	// If we don't have a cached embedding, compute one
+	var document []float32
+	if !e.asymmetric() {
+		document = embeddingFromContext(ctx, issue)
+	}
+	if document == nil {
+		var err error
+		document, err = e.embedder.Embed(ctx, e.documentText(issue))
+		if err != nil {
+			return nil, fmt.Errorf("embedding issue #%d: %w", issue.Number, err)
+		}
+	}
+	query, err := e.queryEmbedding(ctx, issue, document)
+	if err != nil {
+		return nil, err
+	}
+	return e.storeAndFind(repoID, issue, document, query, threshold)
+}
+
+// queryEmbedding returns the embedding to compare issue with, given the
+// embedding it is stored with: the same one unless queries are prefixed
+// differently.
+func (e *Engine) queryEmbedding(ctx context.Context, issue github.Issue, document []float32) ([]float32, error) {
+	if !e.asymmetric() {
+		return document, nil
+	}
+	if v := embeddingFromContext(ctx, issue); v != nil {
+		return v, nil
+	}
+	v, err := e.embedder.Embed(ctx, e.queryText(issue))
+	if err != nil {
+		return nil, fmt.Errorf("embedding issue #%d: %w", issue.Number, err)
+	}
+	return v, nil
+}
 
-	// Check if we can skip re-embedding (content unchanged)
-	storedHash, hasEmbedding, err := e.store.GetIssueEmbeddingHash(repoID, issue.Number)
-	if err == nil && hasEmbedding && storedHash == hash && hash != "" {
-		// Content unchanged, load existing embedding from store
-		storedIssue, err := e.store.GetIssue(repoID, issue.Number)
-		if err == nil && len(storedIssue.Embedding) > 0 {
-			embedding = DecodeEmbedding(storedIssue.Embedding)
+// CheckDuplicateWithEmbedding is like CheckDuplicate for a caller that
+// already has the issue's embedding: vec is stored and compared without
+// calling the embedder, unless queries are prefixed differently from stored
+// issues.
+func (e *Engine) CheckDuplicateWithEmbedding(ctx context.Context, repoID int64, issue github.Issue, vec []float32) (*DedupResult, error) {
+	query, err := e.queryEmbedding(ctx, issue, vec)
+	if err != nil {
+		return nil, err
+	}
+	return e.storeAndFind(repoID, issue, vec, query, e.threshold)
+}
+
+// EmbedStale embeds, in batches, the issues whose stored embedding is
+// missing or was computed from other content, and stores the results, so
+// later checks of those issues need no embedder call. Embedders that are
+// not a provider.BatchEmbedder are called once per issue. It returns how
+// many issues were embedded; on error, earlier batches stay stored.
+func (e *Engine) EmbedStale(ctx context.Context, repoID int64, issues []github.Issue) (int, error) {
+	var stale []github.Issue
+	for _, issue := range issues {
+		if e.storedEmbedding(repoID, issue.Number, e.contentHash(issue.Title, issue.Body)) == nil {
+			stale = append(stale, issue)
 		}
 	}
 
-	// If we don't have a cached embedding, compute one
+	embedded := 0
+	for start := 0; start < len(stale); start += embedBatchSize {
+		batch := stale[start:min(start+embedBatchSize, len(stale))]
+		texts := make([]string, len(batch))
+		for i, issue := range batch {
+			texts[i] = e.documentText(issue)
+		}
+
+		var vecs [][]float32
+		var err error
+		if b, ok := e.embedder.(provider.BatchEmbedder); ok {
+			vecs, err = b.EmbedBatch(ctx, texts)
+		} else {
+			vecs, err = provider.EmbedBatchSequential(ctx, e.embedder, texts)
+		}
+		if err != nil {
+			return embedded, fmt.Errorf("embedding issues #%d-#%d: %w", batch[0].Number, batch[len(batch)-1].Number, err)
+		}
+
+		for i, issue := range batch {
+			hash := e.contentHash(issue.Title, issue.Body)
+			vec := Normalize(vecs[i])
+			if err := e.store.UpdateEmbeddingWithHash(repoID, issue.Number, EncodeEmbedding(vec), "", hash); err != nil {
+				return embedded, fmt.Errorf("storing embedding for issue #%d: %w", issue.Number, err)
+			}
+			e.cacheStored(repoID, issue.Number, vec)
+			embedded++
+		}
+	}
+	return embedded, nil
+}
+
+// storeAndFind stores document, scaled to unit length and with the issue's
+// content hash, as the issue's embedding and returns the duplicates found
+// comparing query.
+func (e *Engine) storeAndFind(repoID int64, issue github.Issue, document, query []float32, threshold float32) (*DedupResult, error) {
+	document = Normalize(document)
+	hash := e.contentHash(issue.Title, issue.Body)
+	if err := e.store.UpdateEmbeddingWithHash(repoID, issue.Number, EncodeEmbedding(document), "", hash); err != nil {
+		return nil, fmt.Errorf("storing embedding for issue #%d: %w", issue.Number, err)
+	}
+	e.cacheStored(repoID, issue.Number, document)
+	return e.findSimilar(repoID, issue.Number, query, threshold)
+}
+
+// storedEmbedding returns the stored embedding for an issue if it was
+// computed from content matching hash, or nil.
+func (e *Engine) storedEmbedding(repoID int64, number int, hash string) []float32 {
+	storedHash, hasEmbedding, err := e.store.GetIssueEmbeddingHash(repoID, number)
+	if err != nil || !hasEmbedding || storedHash != hash || hash == "" {
+		return nil
+	}
+	storedIssue, err := e.store.GetIssue(repoID, number)
+	if err != nil || len(storedIssue.Embedding) == 0 {
+		return nil
+	}
+	return DecodeEmbedding(storedIssue.Embedding)
+}
+
+// Similarity returns the cosine similarity between two issues, comparing a
+// as a query with b as a stored issue. Stored embeddings are reused when the
+// issue content is unchanged; otherwise the issue is embedded on the fly.
+// Nothing is written to the store. A zero repoID means the issue is not
+// stored.
+func (e *Engine) Similarity(ctx context.Context, repoA int64, a github.Issue, repoB int64, b github.Issue) (float32, error) {
+	va, err := e.vector(ctx, repoA, a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := e.documentVector(ctx, repoB, b)
+	if err != nil {
+		return 0, err
+	}
+	return CosineSimilarity(va, vb)
+}
+
+// vector returns the embedding to compare issue with, reusing its stored
+// embedding when queries are not prefixed differently.
+func (e *Engine) vector(ctx context.Context, repoID int64, issue github.Issue) ([]float32, error) {
+	if v := embeddingFromContext(ctx, issue); v != nil {
+		return v, nil
+	}
+	if repoID != 0 && !e.asymmetric() {
+		if v := e.storedEmbedding(repoID, issue.Number, e.contentHash(issue.Title, issue.Body)); v != nil {
+			return v, nil
+		}
+	}
+	v, err := e.embedder.Embed(ctx, e.queryText(issue))
+	if err != nil {
+		return nil, fmt.Errorf("embedding issue #%d: %w", issue.Number, err)
+	}
+	return v, nil
+}
+
+// documentVector returns issue's embedding as a stored issue, reusing the
+// stored one when the content is unchanged.
+func (e *Engine) documentVector(ctx context.Context, repoID int64, issue github.Issue) ([]float32, error) {
+	if !e.asymmetric() {
+		return e.vector(ctx, repoID, issue)
+	}
+	if repoID != 0 {
+		if v := e.storedEmbedding(repoID, issue.Number, e.contentHash(issue.Title, issue.Body)); v != nil {
+			return v, nil
+		}
+	}
+	v, err := e.embedder.Embed(ctx, e.documentText(issue))
+	if err != nil {
+		return nil, fmt.Errorf("embedding issue #%d: %w", issue.Number, err)
+	}
+	return v, nil
+}
+
+// CheckDraft is like CheckDuplicateWithThreshold for an issue that has not
+// been filed yet: the draft is embedded and compared against the repo, but
+// its embedding is not stored.
+func (e *Engine) CheckDraft(ctx context.Context, repoID int64, draft github.Issue, thresholdOverride float32) (*DedupResult, error) {
+	threshold := e.threshold
+	if thresholdOverride > 0 {
+		threshold = thresholdOverride
+	}
+
+	embedding := embeddingFromContext(ctx, draft)
 	if embedding == nil {
+		var err error
+		embedding, err = e.embedder.Embed(ctx, e.queryText(draft))
+		if err != nil {
+			return nil, fmt.Errorf("embedding draft: %w", err)
+		}
+	}
+	return e.findSimilar(repoID, draft.Number, embedding, threshold)
+}
+
+// Similar returns up to limit stored issues at least threshold similar to
+// draft, best first. Unlike CheckDraft it takes its own cutoff and cap, for
+// suggesting related issues rather than flagging duplicates. The draft's
+// embedding is not stored, but is kept in memory for a repeated lookup.
+func (e *Engine) Similar(ctx context.Context, repoID int64, draft github.Issue, threshold float32, limit int) ([]github.DuplicateCandidate, error) {
+	text := e.queryText(draft)
+	embedding, ok := e.drafts.get(text)
+	if !ok {
+		var err error
 		embedding, err = e.embedder.Embed(ctx, text)
 		if err != nil {
-			return nil, fmt.Errorf("embedding issue #%d: %w", issue.Number, err)
+			return nil, fmt.Errorf("embedding draft: %w", err)
 		}
+		e.drafts.put(text, embedding)
+	}
+	result, err := e.findSimilarN(repoID, draft.Number, embedding, threshold, limit)
+	if err != nil {
+		return nil, err
+	}
+	return result.Candidates, nil
+}
+
+// ErrNoEmbedding is returned by CheckStored for issues without a stored
+// embedding.
+var ErrNoEmbedding = errors.New("issue has no stored embedding")
+
+// CheckStored compares a stored issue's stored embedding against the rest of
+// the repo without calling the embedder, e.g. to re-check issues once more
+// history has been embedded. If thresholdOverride is 0, the engine's
+// configured threshold is used.
+func (e *Engine) CheckStored(repoID int64, number int, thresholdOverride float32) (*DedupResult, error) {
+	threshold := e.threshold
+	if thresholdOverride > 0 {
+		threshold = thresholdOverride
+	}
 
-		// Store the embedding with content hash
-		encoded := EncodeEmbedding(embedding)
-		if err := e.store.UpdateEmbeddingWithHash(repoID, issue.Number, encoded, "", hash); err != nil {
-			return nil, fmt.Errorf("storing embedding for issue #%d: %w", issue.Number, err)
+	issue, err := e.store.GetIssue(repoID, number)
+	if err != nil {
+		return nil, fmt.Errorf("loading issue #%d: %w", number, err)
+	}
+	embedding := DecodeEmbedding(issue.Embedding)
+	if len(embedding) == 0 {
+		return nil, fmt.Errorf("issue #%d: %w", number, ErrNoEmbedding)
+	}
+	return e.findSimilar(repoID, number, embedding, threshold)
+}
+
+// RepoMatch is the closest stored issue to an issue in another repo.
+type RepoMatch struct {
+	RepoID    int64
+	Candidate github.DuplicateCandidate
+}
+
+// BestMatchInRepos compares issue, stored in repoID (0 if it is not stored),
+// with the stored issues of each repo in others and returns the closest match
+// at or above the threshold, or nil if there is none. If thresholdOverride is
+// 0, the engine's configured threshold is used.
+func (e *Engine) BestMatchInRepos(ctx context.Context, repoID int64, issue github.Issue, others []int64, thresholdOverride float32) (*RepoMatch, error) {
+	threshold := e.threshold
+	if thresholdOverride > 0 {
+		threshold = thresholdOverride
+	}
+
+	embedding, err := e.vector(ctx, repoID, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *RepoMatch
+	for _, other := range others {
+		// Issue numbers start at 1, so 0 excludes nothing.
+		result, err := e.findSimilar(other, 0, embedding, threshold)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Candidates) > 0 && (best == nil || result.Candidates[0].Score > best.Candidate.Score) {
+			best = &RepoMatch{RepoID: other, Candidate: result.Candidates[0]}
 		}
 	}
+	return best, nil
+}
+
+// MatchPullRequests compares an issue with the repo's open pull requests and
+// returns those at or above the threshold, best first: work that may already
+// address the issue. Pull requests are embedded when first seen or edited. It
+// returns nil unless the engine was created WithPullRequests. If
+// thresholdOverride is 0, the engine's configured threshold is used.
+func (e *Engine) MatchPullRequests(ctx context.Context, repoID int64, issue github.Issue, thresholdOverride float32) ([]github.DuplicateCandidate, error) {
+	if e.pulls == nil {
+		return nil, nil
+	}
+	threshold := e.threshold
+	if thresholdOverride > 0 {
+		threshold = thresholdOverride
+	}
+
+	prs, err := e.pulls.ListOpenPullRequests(repoID)
+	if err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+
+	embedding, err := e.vector(ctx, repoID, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []github.DuplicateCandidate
+	for _, pr := range prs {
+		hash := e.contentHash(pr.Title, pr.Body)
+		other := DecodeEmbedding(pr.Embedding)
+		if len(other) == 0 || pr.EmbeddingHash != hash {
+			other, err = e.embedder.Embed(ctx, e.documentText(github.Issue{Title: pr.Title, Body: pr.Body}))
+			if err != nil {
+				return nil, fmt.Errorf("embedding pull request #%d: %w", pr.Number, err)
+			}
+			if err := e.pulls.UpdatePullRequestEmbedding(repoID, pr.Number, EncodeEmbedding(other), hash); err != nil {
+				return nil, err
+			}
+		}
+
+		score, err := CosineSimilarity(embedding, other)
+		if err != nil {
+			continue // skip dimension mismatches silently
+		}
+		if score >= threshold {
+			candidates = append(candidates, github.DuplicateCandidate{Number: pr.Number, Score: score, Title: pr.Title, State: pr.State})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > e.maxCandidates {
+		candidates = candidates[:e.maxCandidates]
+	}
+	return candidates, nil
+}
+
+// findSimilar compares embedding against all stored embeddings in the repo,
+// excluding issue self, and returns the best candidates at or above threshold.
+func (e *Engine) findSimilar(repoID int64, self int, embedding []float32, threshold float32) (*DedupResult, error) {
+	return e.findSimilarN(repoID, self, embedding, threshold, e.maxCandidates)
+}
+
+// findSimilarN is findSimilar returning at most limit candidates. Stored
+// embeddings have unit length, so once embedding is normalized each
+// comparison is a dot product.
+func (e *Engine) findSimilarN(repoID int64, self int, embedding []float32, threshold float32, limit int) (*DedupResult, error) {
+	embedding = Normalize(embedding)
 
 	// Fetch all existing embeddings for the repo
-	existing, err := e.store.GetEmbeddingsForRepo(repoID)
+	existing, err := e.vectors(repoID)
 	if err != nil {
-		return nil, fmt.Errorf("fetching embeddings for repo %d: %w", repoID, err)
+		return nil, err
 	}
 
 	// Compare against each existing embedding (excluding the current issue)
 	var candidates []github.DuplicateCandidate
-	for _, ie := range existing {
-		if ie.Number == issue.Number {
+	mismatched := false
+	for _, sv := range existing {
+		if sv.number == self {
 			continue // skip self
 		}
 
-		other := DecodeEmbedding(ie.Embedding)
-		if len(other) == 0 {
-			continue
-		}
-
-		score, err := CosineSimilarity(embedding, other)
+		score, err := DotProduct(embedding, sv.vec)
 		if err != nil {
+			mismatched = true
 			continue // skip dimension mismatches silently
 		}
 
 		if score >= threshold {
 			candidates = append(candidates, github.DuplicateCandidate{
-				Number: ie.Number,
+				Number: sv.number,
 				Score:  score,
 			})
 		}
 	}
 
+	// Vectors of another dimension may be cached from before a re-embed;
+	// drop them so the next comparison reads what is stored now
+	if mismatched {
+		e.Invalidate(repoID)
+	}
+
 	// Sort by descending score
 	sort.Slice(candidates, func(i, j int) bool {
 		return candidates[i].Score > candidates[j].Score
 	})
 
-	// Limit to maxCandidates
-	if len(candidates) > e.maxCandidates {
-		candidates = candidates[:e.maxCandidates]
+	// Limit to the best candidates
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	// Describe each candidate so notifications can show more than a number
+	for i := range candidates {
+		if stored, err := e.store.GetIssue(repoID, candidates[i].Number); err == nil {
+			candidates[i].Title = stored.Title
+			candidates[i].State = stored.State
+			candidates[i].CreatedAt = stored.CreatedAt
+		}
 	}
 
 	return &DedupResult{
 		IsDuplicate: len(candidates) > 0,
 		Candidates:  candidates,
+		Embedding:   embedding,
 	}, nil
 }
@@ -2,7 +2,9 @@ package dedup
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -161,6 +163,9 @@ func TestEngine_CheckDuplicate_FindsDuplicate(t *testing.T) {
 	if result.Candidates[0].Number != 1 {
 		t.Errorf("expected candidate #1, got #%d", result.Candidates[0].Number)
 	}
+	if c := result.Candidates[0]; c.Title != "Login page broken" || c.State != "open" || c.CreatedAt.IsZero() {
+		t.Errorf("expected candidate metadata from the store, got %+v", c)
+	}
 }
 
 func TestEngine_CheckDuplicate_MaxCandidates(t *testing.T) {
@@ -469,4 +474,412 @@ func TestEngine_IncludesClosedIssues(t *testing.T) {
 func TestDBSatisfiesEmbeddingStore(t *testing.T) {
 	// Verify *store.DB satisfies the EmbeddingStore interface at compile time.
 	var _ EmbeddingStore = (*store.DB)(nil)
+	var _ PullRequestStore = (*store.DB)(nil)
+}
+
+func TestEngine_CheckDraft_DoesNotStoreEmbedding(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	embedder := newMockEmbedder()
+
+	insertIssueWithEmbedding(t, db, repoID, 1, "Login page broken", []float32{0.9, 0.1, 0.0})
+	embedder.addEmbedding("Login page not working", []float32{0.89, 0.12, 0.01})
+
+	engine := NewEngine(embedder, db, WithThreshold(0.9))
+
+	result, err := engine.CheckDraft(context.Background(), repoID, github.Issue{Title: "Login page not working"}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].Number != 1 {
+		t.Errorf("expected candidate #1, got %+v", result.Candidates)
+	}
+
+	existing, err := db.GetEmbeddingsForRepo(repoID)
+	if err != nil {
+		t.Fatalf("fetching embeddings: %v", err)
+	}
+	if len(existing) != 1 {
+		t.Errorf("expected draft embedding not to be stored, got %d embeddings", len(existing))
+	}
+}
+
+func TestEngine_Similar(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	embedder := newMockEmbedder()
+
+	insertIssueWithEmbedding(t, db, repoID, 1, "Login page broken", []float32{0.9, 0.1, 0.0})
+	insertIssueWithEmbedding(t, db, repoID, 2, "Login slow", []float32{0.7, 0.5, 0.0})
+	insertIssueWithEmbedding(t, db, repoID, 3, "Dark mode", []float32{0.0, 0.0, 1.0})
+	embedder.addEmbedding("Login page not working", []float32{0.89, 0.12, 0.01})
+
+	// Well below the engine's threshold and over its candidate cap
+	engine := NewEngine(embedder, db, WithThreshold(0.99), WithMaxCandidates(1))
+	draft := github.Issue{Title: "Login page not working"}
+	similar, err := engine.Similar(context.Background(), repoID, draft, 0.5, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(similar) != 2 || similar[0].Number != 1 || similar[1].Number != 2 {
+		t.Fatalf("expected #1 then #2, got %+v", similar)
+	}
+	if similar[0].Title != "Login page broken" {
+		t.Errorf("expected the candidate's title, got %q", similar[0].Title)
+	}
+
+	similar, err = engine.Similar(context.Background(), repoID, draft, 0.5, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(similar) != 1 {
+		t.Errorf("expected the limit to apply, got %+v", similar)
+	}
+	if embedder.callCount != 1 {
+		t.Errorf("expected a repeated draft to reuse its embedding, got %d embedder calls", embedder.callCount)
+	}
+	if existing, _ := db.GetEmbeddingsForRepo(repoID); len(existing) != 3 {
+		t.Errorf("expected the draft embedding not to be stored, got %d embeddings", len(existing))
+	}
+}
+
+func TestEngine_CheckDuplicateWithEmbedding(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	embedder := newMockEmbedder()
+
+	insertIssueWithEmbedding(t, db, repoID, 1, "Login page broken", []float32{0.9, 0.1, 0.0})
+	issue := github.Issue{Number: 2, Title: "Login page not working"}
+	if err := db.UpsertIssue(&store.Issue{RepoID: repoID, Number: 2, Title: issue.Title, State: "open", CreatedAt: time.Now(), UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("upserting issue: %v", err)
+	}
+
+	engine := NewEngine(embedder, db, WithThreshold(0.9))
+	result, err := engine.CheckDuplicateWithEmbedding(context.Background(), repoID, issue, []float32{0.89, 0.12, 0.01})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedder.callCount != 0 {
+		t.Errorf("expected no embedder calls, got %d", embedder.callCount)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].Number != 1 {
+		t.Errorf("expected candidate #1, got %+v", result.Candidates)
+	}
+
+	// The vector is stored, so a later check reuses it.
+	if _, err := engine.CheckDuplicate(context.Background(), repoID, issue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedder.callCount != 0 {
+		t.Errorf("expected the stored vector to be reused, got %d embedder calls", embedder.callCount)
+	}
+}
+
+func TestEngine_ContextEmbedding(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	embedder := newMockEmbedder()
+	insertIssueWithEmbedding(t, db, repoID, 1, "Login page broken", []float32{0.9, 0.1, 0.0})
+	engine := NewEngine(embedder, db, WithThreshold(0.9))
+
+	draft := github.Issue{Title: "Login page not working"}
+	ctx := ContextWithEmbedding(context.Background(), draft, []float32{0.89, 0.12, 0.01})
+
+	result, err := engine.CheckDraft(ctx, repoID, draft, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedder.callCount != 0 {
+		t.Errorf("expected the context embedding to be used, got %d embedder calls", embedder.callCount)
+	}
+	if len(result.Candidates) != 1 || len(result.Embedding) != 3 {
+		t.Errorf("expected 1 candidate and the draft's embedding, got %+v", result)
+	}
+
+	// A context embedding for different content is ignored.
+	if _, err := engine.CheckDraft(ctx, repoID, github.Issue{Title: "Something else"}, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedder.callCount != 1 {
+		t.Errorf("expected edited content to be embedded, got %d embedder calls", embedder.callCount)
+	}
+}
+
+func TestEngine_Similarity_UsesStoredVectors(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	embedder := newMockEmbedder()
+	embedder.addEmbedding("Stored issue\n\nStored body", []float32{1, 0, 0})
+	embedder.addEmbedding("Other issue\n\nOther body", []float32{1, 1, 0})
+
+	stored := github.Issue{Number: 1, Title: "Stored issue", Body: "Stored body"}
+	if err := db.UpsertIssue(&store.Issue{
+		RepoID:    repoID,
+		Number:    1,
+		Title:     stored.Title,
+		Body:      stored.Body,
+		State:     "open",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("upserting issue: %v", err)
+	}
+
+	engine := NewEngine(embedder, db)
+	if _, err := engine.CheckDuplicate(context.Background(), repoID, stored); err != nil {
+		t.Fatalf("CheckDuplicate: %v", err)
+	}
+	embedder.callCount = 0
+
+	other := github.Issue{Number: 2, Title: "Other issue", Body: "Other body"}
+	score, err := engine.Similarity(context.Background(), repoID, stored, repoID, other)
+	if err != nil {
+		t.Fatalf("Similarity: %v", err)
+	}
+	if embedder.callCount != 1 {
+		t.Errorf("expected only the unstored issue to be embedded, got %d calls", embedder.callCount)
+	}
+	if score < 0.70 || score > 0.71 {
+		t.Errorf("expected score ~0.707, got %f", score)
+	}
+
+	if _, hasEmbedding, _ := db.GetIssueEmbeddingHash(repoID, 2); hasEmbedding {
+		t.Error("expected Similarity not to store embeddings")
+	}
+}
+
+func TestEngine_Similarity_EmbedderError(t *testing.T) {
+	engine := NewEngine(&mockEmbedderErr{}, nil)
+	_, err := engine.Similarity(context.Background(), 0, github.Issue{Number: 1, Title: "a"}, 0, github.Issue{Number: 2, Title: "b"})
+	if err == nil {
+		t.Fatal("expected error when embedding fails")
+	}
+}
+
+func TestEngine_BestMatchInRepos(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	cli, err := db.CreateRepo("test-owner", "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	web, err := db.CreateRepo("test-owner", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	insertIssueWithEmbedding(t, db, cli.ID, 12, "CLI crash", []float32{1, 0.05, 0})
+	insertIssueWithEmbedding(t, db, web.ID, 4, "Web crash", []float32{1, 0.3, 0})
+	insertIssueWithEmbedding(t, db, web.ID, 5, "Unrelated", []float32{0, 0, 1})
+
+	embedder := newMockEmbedder()
+	embedder.addEmbedding("CLI crashes", []float32{1, 0, 0})
+	engine := NewEngine(embedder, db, WithThreshold(0.9))
+
+	issue := github.Issue{Number: 1, Title: "CLI crashes"}
+	match, err := engine.BestMatchInRepos(context.Background(), repoID, issue, []int64{web.ID, cli.ID}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match == nil || match.RepoID != cli.ID || match.Candidate.Number != 12 {
+		t.Fatalf("expected best match cli#12, got %+v", match)
+	}
+
+	match, err = engine.BestMatchInRepos(context.Background(), repoID, issue, []int64{web.ID, cli.ID}, 0.9999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match != nil {
+		t.Errorf("expected no match above threshold, got %+v", match)
+	}
+}
+
+func TestEngine_CheckStored(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	insertIssueWithEmbedding(t, db, repoID, 1, "Old", []float32{1, 0, 0})
+	insertIssueWithEmbedding(t, db, repoID, 2, "New", []float32{1, 0.01, 0})
+	if err := db.UpsertIssue(&store.Issue{RepoID: repoID, Number: 3, Title: "Unembedded", State: "open"}); err != nil {
+		t.Fatal(err)
+	}
+
+	embedder := newMockEmbedder()
+	engine := NewEngine(embedder, db, WithThreshold(0.9))
+
+	result, err := engine.CheckStored(repoID, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].Number != 1 {
+		t.Errorf("expected #1 as the only candidate, got %+v", result.Candidates)
+	}
+	if embedder.callCount != 0 {
+		t.Errorf("expected no embedder calls, got %d", embedder.callCount)
+	}
+
+	if _, err := engine.CheckStored(repoID, 3, 0); !errors.Is(err, ErrNoEmbedding) {
+		t.Errorf("expected ErrNoEmbedding, got %v", err)
+	}
+}
+
+func TestEngine_MatchPullRequests(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	now := time.Now()
+	for _, pr := range []store.PullRequest{
+		{RepoID: repoID, Number: 7, Title: "Fix crash on start", State: "open", UpdatedAt: now},
+		{RepoID: repoID, Number: 8, Title: "Add dark mode", State: "open", UpdatedAt: now},
+		{RepoID: repoID, Number: 9, Title: "Old crash fix", State: "closed", UpdatedAt: now},
+	} {
+		if err := db.UpsertPullRequest(&pr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	embedder := newMockEmbedder()
+	engine := NewEngine(embedder, db, WithThreshold(0.9), WithPullRequests(db))
+	embedder.addEmbedding(engine.ComposeText(github.Issue{Title: "App crashes on start"}), []float32{1, 0, 0})
+	embedder.addEmbedding(engine.ComposeText(github.Issue{Title: "Fix crash on start"}), []float32{1, 0.1, 0})
+	embedder.addEmbedding(engine.ComposeText(github.Issue{Title: "Add dark mode"}), []float32{0, 1, 0})
+
+	issue := github.Issue{Number: 1, Title: "App crashes on start"}
+	matches, err := engine.MatchPullRequests(context.Background(), repoID, issue, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Number != 7 {
+		t.Fatalf("expected PR #7, got %+v", matches)
+	}
+	if embedder.callCount != 3 {
+		t.Errorf("expected the issue and both open PRs to be embedded, got %d calls", embedder.callCount)
+	}
+
+	// Stored PR embeddings are reused.
+	if _, err := engine.MatchPullRequests(context.Background(), repoID, issue, 0); err != nil {
+		t.Fatal(err)
+	}
+	if embedder.callCount != 4 {
+		t.Errorf("expected only the unstored issue to be embedded again, got %d calls", embedder.callCount)
+	}
+
+	// Without WithPullRequests, nothing is matched.
+	matches, err = NewEngine(embedder, db).MatchPullRequests(context.Background(), repoID, issue, 0)
+	if err != nil || matches != nil {
+		t.Errorf("expected no matches, got %+v, %v", matches, err)
+	}
+}
+
+// batchEmbedder is a mockEmbedder that also embeds in batches.
+type batchEmbedder struct {
+	mockEmbedder
+	batches []int
+}
+
+func (b *batchEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	b.batches = append(b.batches, len(texts))
+	vecs := make([][]float32, len(texts))
+	for i, text := range texts {
+		vecs[i], _ = b.mockEmbedder.Embed(ctx, text)
+	}
+	return vecs, nil
+}
+
+func TestEngine_EmbedStale(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	embedder := &batchEmbedder{mockEmbedder: *newMockEmbedder()}
+	engine := NewEngine(embedder, db)
+
+	var issues []github.Issue
+	for n := 1; n <= embedBatchSize+6; n++ {
+		issue := github.Issue{Number: n, Title: fmt.Sprintf("Issue %d", n), Body: "body"}
+		issues = append(issues, issue)
+		if err := db.UpsertIssue(&store.Issue{RepoID: repoID, Number: n, Title: issue.Title, Body: issue.Body, State: "open"}); err != nil {
+			t.Fatalf("upserting issue: %v", err)
+		}
+	}
+	// #1 is already embedded from its current content
+	hash := ContentHash(issues[0].Title, issues[0].Body)
+	if err := db.UpdateEmbeddingWithHash(repoID, 1, EncodeEmbedding([]float32{1, 0, 0}), "", hash); err != nil {
+		t.Fatalf("storing embedding: %v", err)
+	}
+
+	n, err := engine.EmbedStale(context.Background(), repoID, issues)
+	if err != nil {
+		t.Fatalf("EmbedStale: %v", err)
+	}
+	if n != embedBatchSize+5 {
+		t.Errorf("embedded %d issues, want %d", n, embedBatchSize+5)
+	}
+	if len(embedder.batches) != 2 || embedder.batches[0] != embedBatchSize || embedder.batches[1] != 5 {
+		t.Errorf("batches = %v, want [%d 5]", embedder.batches, embedBatchSize)
+	}
+
+	// Checking a prepared issue needs no further embedder call
+	calls := embedder.callCount
+	if _, err := engine.CheckDuplicate(context.Background(), repoID, issues[10]); err != nil {
+		t.Fatalf("CheckDuplicate: %v", err)
+	}
+	if embedder.callCount != calls {
+		t.Error("expected the stored embedding to be reused")
+	}
+
+	// Nothing is left to embed
+	if n, err := engine.EmbedStale(context.Background(), repoID, issues); err != nil || n != 0 {
+		t.Errorf("second EmbedStale = %d, %v; want 0", n, err)
+	}
+}
+
+func TestEngine_Prefixes(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	embedder := newMockEmbedder()
+	engine := NewEngine(embedder, db, WithThreshold(0.8), WithPrefixes("search_document: ", "search_query: "))
+
+	insertIssueWithEmbedding(t, db, repoID, 1, "Login page broken", []float32{0.9, 0.1, 0.0})
+	embedder.addEmbedding("search_document: Login page not working", []float32{0.0, 0.0, 1.0})
+	embedder.addEmbedding("search_query: Login page not working", []float32{0.89, 0.12, 0.01})
+	issue := github.Issue{Number: 2, Title: "Login page not working"}
+	if err := db.UpsertIssue(&store.Issue{RepoID: repoID, Number: 2, Title: issue.Title, State: "open"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := engine.CheckDuplicate(context.Background(), repoID, issue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].Number != 1 {
+		t.Fatalf("expected the query embedding to match #1, got %+v", result.Candidates)
+	}
+	if result.Embedding[0] < 0.9 {
+		t.Errorf("expected the query embedding in the result, got %v", result.Embedding)
+	}
+	stored, err := db.GetIssue(repoID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := DecodeEmbedding(stored.Embedding); len(got) != 3 || got[2] != 1 {
+		t.Errorf("expected the document embedding to be stored, got %v", got)
+	}
+	if embedder.callCount != 2 {
+		t.Errorf("expected a document and a query embedding, got %d calls", embedder.callCount)
+	}
+
+	// Re-checking reuses the stored document but embeds the query
+	if _, err := engine.CheckDuplicate(context.Background(), repoID, issue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedder.callCount != 3 {
+		t.Errorf("expected only the query to be embedded again, got %d calls", embedder.callCount)
+	}
+
+	// Embeddings stored before the prefix was set are stale
+	if err := db.UpsertIssue(&store.Issue{RepoID: repoID, Number: 3, Title: "Dark mode", State: "open"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateEmbeddingWithHash(repoID, 3, EncodeEmbedding([]float32{0, 1, 0}), "", ContentHash("Dark mode", "")); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := engine.EmbedStale(context.Background(), repoID, []github.Issue{issue, {Number: 3, Title: "Dark mode"}}); err != nil || n != 1 {
+		t.Errorf("EmbedStale = %d, %v; want only #3 re-embedded", n, err)
+	}
+}
+
+func TestEngine_EmbedStale_EmbedderError(t *testing.T) {
+	db, repoID := setupTestDB(t)
+	engine := NewEngine(&mockEmbedderErr{}, db)
+
+	_, err := engine.EmbedStale(context.Background(), repoID, []github.Issue{{Number: 1, Title: "Crash"}})
+	if err == nil || !strings.Contains(err.Error(), "embedding issues #1-#1") {
+		t.Errorf("expected an embedding error, got %v", err)
+	}
 }
@@ -0,0 +1,20 @@
+//go:build !purego
+
+package dedup
+
+// dotAndNorms returns the dot product of a and b and their squared norms,
+// accumulated in float64. len(b) must be at least len(a).
+//
+// It is implemented with SSE2 in kernel_amd64.s, two to three times as fast as the
+// plain loop in kernel_generic.go for 1536-dimension vectors; build with
+// -tags purego to use the plain loop instead.
+//
+//go:noescape
+func dotAndNorms(a, b []float32) (dot, normA, normB float64)
+
+// dot returns the dot product of a and b, accumulated in float64. len(b)
+// must be at least len(a). It is dotAndNorms without the norms, for unit
+// vectors; see kernel_amd64.s.
+//
+//go:noescape
+func dot(a, b []float32) float64
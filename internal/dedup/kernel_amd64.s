@@ -0,0 +1,150 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func dotAndNorms(a, b []float32) (dot, normA, normB float64)
+//
+// SSE2 is part of the amd64 baseline, so no CPU feature check is needed.
+// Each iteration widens four floats of a and of b to float64, two at a time
+// with CVTPS2PD, and accumulates into two independent sets of registers:
+// X0/X3 the dot product, X1/X4 a's squared norm, X2/X5 b's.
+TEXT ·dotAndNorms(SB), NOSPLIT, $0-72
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	XORPD X0, X0
+	XORPD X1, X1
+	XORPD X2, X2
+	XORPD X3, X3
+	XORPD X4, X4
+	XORPD X5, X5
+	MOVQ CX, BX
+	SHRQ $2, BX // groups of four
+	JZ   reduce
+
+loop:
+	MOVSD    (SI), X6
+	CVTPS2PD X6, X6
+	MOVSD    (DI), X7
+	CVTPS2PD X7, X7
+	MOVSD    8(SI), X8
+	CVTPS2PD X8, X8
+	MOVSD    8(DI), X9
+	CVTPS2PD X9, X9
+	MOVAPD   X6, X10
+	MULPD    X7, X10
+	ADDPD    X10, X0
+	MOVAPD   X8, X11
+	MULPD    X9, X11
+	ADDPD    X11, X3
+	MULPD    X6, X6
+	ADDPD    X6, X1
+	MULPD    X8, X8
+	ADDPD    X8, X4
+	MULPD    X7, X7
+	ADDPD    X7, X2
+	MULPD    X9, X9
+	ADDPD    X9, X5
+	ADDQ     $16, SI
+	ADDQ     $16, DI
+	DECQ     BX
+	JNZ      loop
+
+reduce:
+	// Combine the accumulator sets, then the two lanes of each.
+	ADDPD    X3, X0
+	ADDPD    X4, X1
+	ADDPD    X5, X2
+	MOVAPD   X0, X6
+	UNPCKHPD X6, X6
+	ADDSD    X6, X0
+	MOVAPD   X1, X6
+	UNPCKHPD X6, X6
+	ADDSD    X6, X1
+	MOVAPD   X2, X6
+	UNPCKHPD X6, X6
+	ADDSD    X6, X2
+
+	// The remaining zero to three elements, one at a time.
+	ANDQ $3, CX
+	JZ   done
+
+tail:
+	MOVSS    (SI), X6
+	CVTSS2SD X6, X6
+	MOVSS    (DI), X7
+	CVTSS2SD X7, X7
+	MOVSD    X6, X8
+	MULSD    X7, X8
+	ADDSD    X8, X0
+	MULSD    X6, X6
+	ADDSD    X6, X1
+	MULSD    X7, X7
+	ADDSD    X7, X2
+	ADDQ     $4, SI
+	ADDQ     $4, DI
+	DECQ     CX
+	JNZ      tail
+
+done:
+	MOVSD X0, dot+48(FP)
+	MOVSD X1, normA+56(FP)
+	MOVSD X2, normB+64(FP)
+	RET
+
+// func dot(a, b []float32) float64
+//
+// The dot product loop of dotAndNorms alone, accumulating into X0 and X3.
+TEXT ·dot(SB), NOSPLIT, $0-56
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	XORPD X0, X0
+	XORPD X3, X3
+	MOVQ CX, BX
+	SHRQ $2, BX // groups of four
+	JZ   dotreduce
+
+dotloop:
+	MOVSD    (SI), X6
+	CVTPS2PD X6, X6
+	MOVSD    (DI), X7
+	CVTPS2PD X7, X7
+	MOVSD    8(SI), X8
+	CVTPS2PD X8, X8
+	MOVSD    8(DI), X9
+	CVTPS2PD X9, X9
+	MULPD    X7, X6
+	ADDPD    X6, X0
+	MULPD    X9, X8
+	ADDPD    X8, X3
+	ADDQ     $16, SI
+	ADDQ     $16, DI
+	DECQ     BX
+	JNZ      dotloop
+
+dotreduce:
+	ADDPD    X3, X0
+	MOVAPD   X0, X6
+	UNPCKHPD X6, X6
+	ADDSD    X6, X0
+
+	// The remaining zero to three elements, one at a time.
+	ANDQ $3, CX
+	JZ   dotdone
+
+dottail:
+	MOVSS    (SI), X6
+	CVTSS2SD X6, X6
+	MOVSS    (DI), X7
+	CVTSS2SD X7, X7
+	MULSD    X7, X6
+	ADDSD    X6, X0
+	ADDQ     $4, SI
+	ADDQ     $4, DI
+	DECQ     CX
+	JNZ      dottail
+
+dotdone:
+	MOVSD X0, ret+48(FP)
+	RET
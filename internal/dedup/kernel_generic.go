@@ -0,0 +1,26 @@
+//go:build !amd64 || purego
+
+package dedup
+
+// dotAndNorms returns the dot product of a and b and their squared norms,
+// accumulated in float64. len(b) must be at least len(a).
+func dotAndNorms(a, b []float32) (dot, normA, normB float64) {
+	for i := range a {
+		ai := float64(a[i])
+		bi := float64(b[i])
+		dot += ai * bi
+		normA += ai * ai
+		normB += bi * bi
+	}
+	return dot, normA, normB
+}
+
+// dot returns the dot product of a and b, accumulated in float64. len(b)
+// must be at least len(a).
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
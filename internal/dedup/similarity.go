@@ -7,7 +7,7 @@ import (
 
 // CosineSimilarity computes the cosine similarity between two float32 vectors.
 // Returns 0 for zero vectors, and an error if dimensions don't match.
-// Uses a single-pass computation for performance.
+// Uses a single pass over both vectors; see dotAndNorms.
 func CosineSimilarity(a, b []float32) (float32, error) {
 	if len(a) != len(b) {
 		return 0, fmt.Errorf("dimension mismatch: %d vs %d", len(a), len(b))
@@ -17,15 +17,7 @@ func CosineSimilarity(a, b []float32) (float32, error) {
 		return 0, nil
 	}
 
-	var dot, normA, normB float64
-
-	for i := range a {
-		ai := float64(a[i])
-		bi := float64(b[i])
-		dot += ai * bi
-		normA += ai * ai
-		normB += bi * bi
-	}
+	dot, normA, normB := dotAndNorms(a, b)
 
 	// Handle zero vectors
 	if normA == 0 || normB == 0 {
@@ -34,3 +26,31 @@ func CosineSimilarity(a, b []float32) (float32, error) {
 
 	return float32(dot / math.Sqrt(normA*normB)), nil
 }
+
+// DotProduct returns the dot product of two float32 vectors, which is their
+// cosine similarity when both have unit length; see Normalize. Returns an
+// error if dimensions don't match.
+func DotProduct(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("dimension mismatch: %d vs %d", len(a), len(b))
+	}
+	return float32(dot(a, b)), nil
+}
+
+// Normalize returns v scaled to unit length. Zero and empty vectors are
+// returned unchanged.
+func Normalize(v []float32) []float32 {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := math.Sqrt(sum)
+	out := make([]float32, len(v))
+	for i, f := range v {
+		out[i] = float32(float64(f) / norm)
+	}
+	return out
+}
@@ -169,3 +169,105 @@ func TestCosineSimilarity_NearZeroVectors(t *testing.T) {
 		t.Errorf("expected ~1.0 for identical near-zero vectors, got %f", score)
 	}
 }
+
+// referenceDotAndNorms is the plain loop that dotAndNorms must agree with.
+func referenceDotAndNorms(a, b []float32) (dot, normA, normB float64) {
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	return dot, normA, normB
+}
+
+func TestDotAndNorms_MatchesReference(t *testing.T) {
+	// Lengths around the unroll width exercise the remainder loop.
+	for _, n := range []int{0, 1, 3, 4, 5, 7, 8, 1536} {
+		a, b := testVector(n, 1), testVector(n, 2)
+		dot, na, nb := dotAndNorms(a, b)
+		wantDot, wantA, wantB := referenceDotAndNorms(a, b)
+		for _, c := range [][2]float64{{dot, wantDot}, {na, wantA}, {nb, wantB}} {
+			if math.Abs(c[0]-c[1]) > 1e-9*math.Max(1, math.Abs(c[1])) {
+				t.Errorf("n=%d: dotAndNorms = %v, %v, %v; want %v, %v, %v", n, dot, na, nb, wantDot, wantA, wantB)
+				break
+			}
+		}
+	}
+}
+
+func TestDot_MatchesReference(t *testing.T) {
+	for _, n := range []int{0, 1, 3, 4, 5, 7, 8, 1536} {
+		a, b := testVector(n, 1), testVector(n, 2)
+		want, _, _ := referenceDotAndNorms(a, b)
+		if got := dot(a, b); math.Abs(got-want) > 1e-9*math.Max(1, math.Abs(want)) {
+			t.Errorf("n=%d: dot = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestDotProduct_MatchesCosineForUnitVectors(t *testing.T) {
+	a, b := testVector(1536, 1), testVector(1536, 2)
+	want, err := CosineSimilarity(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DotProduct(Normalize(a), Normalize(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(float64(got-want)) > 1e-5 {
+		t.Errorf("DotProduct of unit vectors = %v, want the cosine %v", got, want)
+	}
+	if _, err := DotProduct([]float32{1}, []float32{1, 2}); err == nil {
+		t.Error("expected a dimension mismatch error")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := Normalize([]float32{3, 4})
+	if math.Abs(float64(got[0])-0.6) > 1e-6 || math.Abs(float64(got[1])-0.8) > 1e-6 {
+		t.Errorf("Normalize(3, 4) = %v, want [0.6 0.8]", got)
+	}
+	if got := Normalize([]float32{0, 0}); got[0] != 0 || got[1] != 0 {
+		t.Errorf("expected a zero vector to stay zero, got %v", got)
+	}
+	if got := Normalize(nil); got != nil {
+		t.Errorf("Normalize(nil) = %v", got)
+	}
+}
+
+// testVector returns a deterministic vector of length n.
+func testVector(n int, seed float64) []float32 {
+	v := make([]float32, n)
+	for i := range v {
+		v[i] = float32(math.Sin(float64(i)*seed + seed))
+	}
+	return v
+}
+
+func BenchmarkCosineSimilarity1536(b *testing.B) {
+	x, y := testVector(1536, 1), testVector(1536, 2)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := CosineSimilarity(x, y); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindSimilar10k(b *testing.B) {
+	e := NewEngine(nil, nil, WithCache())
+	vecs := make([]storedVector, 10000)
+	for i := range vecs {
+		// Stored embeddings have unit length
+		vecs[i] = storedVector{number: i + 1, vec: Normalize(testVector(1536, float64(i+1)))}
+	}
+	e.cache.put(1, vecs, 0)
+	query := testVector(1536, 0.5)
+	b.ResetTimer()
+	for b.Loop() {
+		if _, err := e.findSimilar(1, 0, query, 0.99); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	gogithub "github.com/google/go-github/v60/github"
+
+	"github.com/jacklau/triage/internal/buildinfo"
 )
 
 // NewGitHubClient creates a GitHub API client authenticated as a GitHub App
@@ -33,9 +35,18 @@ func NewGitHubClient(appID, installationID int64, privateKey []byte, privateKeyP
 	}
 
 	client := gogithub.NewClient(&http.Client{Transport: transport})
+	client.UserAgent = buildinfo.UserAgent()
 	return client, nil
 }
 
+// NewTokenClient creates a GitHub API client authenticated with a personal
+// access token or the GITHUB_TOKEN provided to GitHub Actions workflows.
+func NewTokenClient(token string) *gogithub.Client {
+	client := gogithub.NewClient(nil).WithAuthToken(token)
+	client.UserAgent = buildinfo.UserAgent()
+	return client
+}
+
 // resolvePrivateKey returns PEM-encoded private key bytes from either the
 // provided raw/base64-encoded key or by reading from a file path.
 func resolvePrivateKey(key []byte, keyPath string) ([]byte, error) {
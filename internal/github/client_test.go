@@ -0,0 +1,13 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTokenClientUserAgent(t *testing.T) {
+	client := NewTokenClient("token")
+	if !strings.HasPrefix(client.UserAgent, "triage/") {
+		t.Errorf("expected triage User-Agent, got %q", client.UserAgent)
+	}
+}
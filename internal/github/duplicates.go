@@ -0,0 +1,39 @@
+package github
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// duplicateOfRe matches GitHub's "Duplicate of #N" keyword, with the issue
+// given as #N, owner/repo#N, or its URL.
+var duplicateOfRe = regexp.MustCompile(`(?i)\bduplicate\s+of\s+(?:https?://github\.com/([\w.-]+/[\w.-]+)/issues/|([\w.-]+/[\w.-]+)?#)(\d+)\b`)
+
+// DuplicateRefs returns the issues of repo that text says it duplicates
+// with "Duplicate of", in ascending order. References to other repos and
+// quoted lines are ignored.
+func DuplicateRefs(text, repo string) []int {
+	seen := make(map[int]bool)
+	var refs []int
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), ">") {
+			continue
+		}
+		for _, m := range duplicateOfRe.FindAllStringSubmatch(line, -1) {
+			other := m[1] + m[2]
+			if other != "" && !strings.EqualFold(other, repo) {
+				continue
+			}
+			n, err := strconv.Atoi(m[3])
+			if err != nil || n <= 0 || seen[n] {
+				continue
+			}
+			seen[n] = true
+			refs = append(refs, n)
+		}
+	}
+	sort.Ints(refs)
+	return refs
+}
@@ -0,0 +1,31 @@
+package github
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDuplicateRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []int
+	}{
+		{"keyword", "Duplicate of #12", []int{12}},
+		{"lowercase in a sentence", "Closing, this is a duplicate of #7.", []int{7}},
+		{"same repo", "duplicate of Org/App#3", []int{3}},
+		{"URL", "Duplicate of https://github.com/org/app/issues/40", []int{40}},
+		{"other repo", "Duplicate of org/other#3 and https://github.com/org/other/issues/4", nil},
+		{"several, sorted and unique", "Duplicate of #9\nalso duplicate of #2, duplicate of #9", []int{2, 9}},
+		{"quoted", "> Duplicate of #5\nI don't think so", nil},
+		{"no keyword", "Related to #5, see #6", nil},
+		{"not a number", "Duplicate of #12abc", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DuplicateRefs(tt.text, "org/app"); !slices.Equal(got, tt.want) {
+				t.Errorf("DuplicateRefs(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
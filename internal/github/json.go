@@ -0,0 +1,145 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TriageResultSchemaVersion is the version of the JSON form of
+// TriageResult. Adding fields keeps the version; it is bumped only when a
+// field is removed, renamed, or changes meaning, so consumers can check it
+// and ignore fields they don't know.
+const TriageResultSchemaVersion = 1
+
+// TriageResultJSON is the stable JSON form of a TriageResult. Use it, or
+// marshal the TriageResult directly, wherever results leave the process.
+type TriageResultJSON struct {
+	SchemaVersion    int             `json:"schema_version"`
+	Repo             string          `json:"repo,omitempty"`
+	IssueNumber      int             `json:"issue_number,omitempty"`
+	Duplicates       []CandidateJSON `json:"duplicates"`
+	Labels           []LabelJSON     `json:"labels"`
+	Reasoning        string          `json:"reasoning"`
+	Transfer         *TransferJSON   `json:"transfer,omitempty"`
+	PullRequests     []CandidateJSON `json:"pull_requests,omitempty"`
+	NeedsHumanTriage bool            `json:"needs_human_triage,omitempty"`
+}
+
+// CandidateJSON is a duplicate or pull request candidate in TriageResultJSON.
+type CandidateJSON struct {
+	Number    int        `json:"number"`
+	Score     float64    `json:"score"`
+	Title     string     `json:"title,omitempty"`
+	State     string     `json:"state,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// LabelJSON is a suggested label in TriageResultJSON.
+type LabelJSON struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// TransferJSON is a transfer suggestion in TriageResultJSON.
+type TransferJSON struct {
+	Repo   string  `json:"repo"`
+	Number int     `json:"number"`
+	Score  float64 `json:"score"`
+}
+
+// JSON returns the versioned JSON form of r. Duplicates and Labels are
+// never nil, so they encode as [] rather than null.
+func (r TriageResult) JSON() TriageResultJSON {
+	out := TriageResultJSON{
+		SchemaVersion:    TriageResultSchemaVersion,
+		Repo:             r.Repo,
+		IssueNumber:      r.IssueNumber,
+		Duplicates:       make([]CandidateJSON, 0, len(r.Duplicates)),
+		Labels:           make([]LabelJSON, 0, len(r.SuggestedLabels)),
+		Reasoning:        r.Reasoning,
+		NeedsHumanTriage: r.NeedsHumanTriage,
+	}
+	for _, d := range r.Duplicates {
+		out.Duplicates = append(out.Duplicates, candidateJSON(d))
+	}
+	for _, l := range r.SuggestedLabels {
+		out.Labels = append(out.Labels, LabelJSON{Name: l.Name, Confidence: l.Confidence})
+	}
+	for _, pr := range r.PullRequests {
+		out.PullRequests = append(out.PullRequests, candidateJSON(pr))
+	}
+	if t := r.Transfer; t != nil {
+		out.Transfer = &TransferJSON{Repo: t.Repo, Number: t.Number, Score: float64(t.Score)}
+	}
+	return out
+}
+
+func candidateJSON(c DuplicateCandidate) CandidateJSON {
+	out := CandidateJSON{
+		Number: c.Number,
+		Score:  float64(c.Score),
+		Title:  c.Title,
+		State:  c.State,
+	}
+	if !c.CreatedAt.IsZero() {
+		createdAt := c.CreatedAt
+		out.CreatedAt = &createdAt
+	}
+	return out
+}
+
+// Result converts the JSON form back to a TriageResult.
+func (j TriageResultJSON) Result() TriageResult {
+	r := TriageResult{
+		Repo:             j.Repo,
+		IssueNumber:      j.IssueNumber,
+		Reasoning:        j.Reasoning,
+		NeedsHumanTriage: j.NeedsHumanTriage,
+	}
+	for _, d := range j.Duplicates {
+		r.Duplicates = append(r.Duplicates, d.candidate())
+	}
+	for _, l := range j.Labels {
+		r.SuggestedLabels = append(r.SuggestedLabels, LabelSuggestion{Name: l.Name, Confidence: l.Confidence})
+	}
+	for _, pr := range j.PullRequests {
+		r.PullRequests = append(r.PullRequests, pr.candidate())
+	}
+	if t := j.Transfer; t != nil {
+		r.Transfer = &TransferSuggestion{Repo: t.Repo, Number: t.Number, Score: float32(t.Score)}
+	}
+	return r
+}
+
+func (c CandidateJSON) candidate() DuplicateCandidate {
+	d := DuplicateCandidate{
+		Number: c.Number,
+		Score:  float32(c.Score),
+		Title:  c.Title,
+		State:  c.State,
+	}
+	if c.CreatedAt != nil {
+		d.CreatedAt = *c.CreatedAt
+	}
+	return d
+}
+
+// MarshalJSON encodes r in its versioned JSON form.
+func (r TriageResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.JSON())
+}
+
+// UnmarshalJSON decodes the versioned JSON form. It rejects schema versions
+// newer than this build understands.
+func (r *TriageResult) UnmarshalJSON(data []byte) error {
+	var j TriageResultJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if j.SchemaVersion > TriageResultSchemaVersion {
+		return fmt.Errorf("triage result schema version %d is newer than supported version %d", j.SchemaVersion, TriageResultSchemaVersion)
+	}
+	*r = j.Result()
+	return nil
+}
@@ -0,0 +1,97 @@
+package github
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTriageResultJSONRoundTrip(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	in := TriageResult{
+		Repo:        "owner/repo",
+		IssueNumber: 42,
+		Duplicates: []DuplicateCandidate{
+			{Number: 38, Score: 0.91, Title: "Crash on start", State: "closed", CreatedAt: created},
+		},
+		SuggestedLabels:  []LabelSuggestion{{Name: "bug", Confidence: 0.95}},
+		Reasoning:        "Crash report",
+		Transfer:         &TransferSuggestion{Repo: "owner/other", Number: 7, Score: 0.9},
+		PullRequests:     []DuplicateCandidate{{Number: 50, Score: 0.8}},
+		NeedsHumanTriage: true,
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal raw: %v", err)
+	}
+	if string(raw["schema_version"]) != "1" {
+		t.Errorf("schema_version = %s, want 1", raw["schema_version"])
+	}
+	if _, ok := raw["issue_number"]; !ok {
+		t.Errorf("expected snake_case keys, got %s", data)
+	}
+
+	var out TriageResult
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Repo != in.Repo || out.IssueNumber != in.IssueNumber || out.Reasoning != in.Reasoning || !out.NeedsHumanTriage {
+		t.Errorf("round trip = %+v", out)
+	}
+	if len(out.Duplicates) != 1 || out.Duplicates[0].Title != "Crash on start" || !out.Duplicates[0].CreatedAt.Equal(created) {
+		t.Errorf("duplicates = %+v", out.Duplicates)
+	}
+	if len(out.SuggestedLabels) != 1 || out.SuggestedLabels[0].Name != "bug" {
+		t.Errorf("labels = %+v", out.SuggestedLabels)
+	}
+	if out.Transfer == nil || out.Transfer.Repo != "owner/other" || out.Transfer.Number != 7 {
+		t.Errorf("transfer = %+v", out.Transfer)
+	}
+	if len(out.PullRequests) != 1 || out.PullRequests[0].Number != 50 {
+		t.Errorf("pull requests = %+v", out.PullRequests)
+	}
+}
+
+func TestTriageResultJSONEmpty(t *testing.T) {
+	data, err := json.Marshal(TriageResult{})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{`"duplicates":[]`, `"labels":[]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in %s", want, got)
+		}
+	}
+	for _, absent := range []string{"transfer", "pull_requests", "needs_human_triage"} {
+		if strings.Contains(got, absent) {
+			t.Errorf("expected %s to be omitted from %s", absent, got)
+		}
+	}
+}
+
+func TestTriageResultJSONNewerVersion(t *testing.T) {
+	var r TriageResult
+	err := json.Unmarshal([]byte(`{"schema_version": 2, "duplicates": [], "labels": []}`), &r)
+	if err == nil {
+		t.Fatal("expected error for a newer schema version")
+	}
+}
+
+func TestTriageResultJSONIgnoresUnknownFields(t *testing.T) {
+	var r TriageResult
+	err := json.Unmarshal([]byte(`{"schema_version": 1, "reasoning": "ok", "added_later": true}`), &r)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if r.Reasoning != "ok" {
+		t.Errorf("reasoning = %q, want ok", r.Reasoning)
+	}
+}
@@ -3,12 +3,13 @@ package github
 import (
 	"context"
 	"crypto/sha256"
-	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,41 +19,136 @@ import (
 	"github.com/jacklau/triage/internal/store"
 )
 
+// ErrRepoUnavailable is returned by Poll for a repo that GitHub no longer
+// finds, such as one renamed, made private, or deleted. The repo is disabled
+// in the store and not polled until it is enabled again.
+var ErrRepoUnavailable = errors.New("repository unavailable")
+
 // watermarkBuffer is subtracted from the latest issue UpdatedAt to guard
 // against clock skew and missed updates at page boundaries.
 const watermarkBuffer = 2 * time.Minute
 
+// backPressureCheck is how often a paused poll checks whether the pipeline
+// has caught up.
+var backPressureCheck = time.Second
+
 // Poller watches GitHub repositories for issue changes and publishes events.
 type Poller struct {
 	client *gogithub.Client
 	store  *store.DB
 	broker *pubsub.Broker[IssueEvent]
+	// name is the owner/repo the poller was created for, which events are
+	// published under. owner and repo are the repo's current name on
+	// GitHub, which differs after a rename.
+	name   string
 	owner  string
 	repo   string
 	logger *log.Logger
+
+	// retriageCommands are the comment commands that request a re-triage;
+	// see SetRetriageCommands.
+	retriageCommands []string
+	// trackPullRequests stores pull requests from the issues listing; see
+	// SetTrackPullRequests.
+	trackPullRequests bool
+	// lastCommentID is the newest comment checked for a command, so comments
+	// seen again within the watermark buffer are not acted on twice.
+	lastCommentID int64
+	// reconcileInterval is how often stored issues are checked against
+	// GitHub; see SetReconcileInterval.
+	reconcileInterval time.Duration
+	// onDisabled is called when the repo is disabled; see
+	// SetDisabledHandler.
+	onDisabled func(ctx context.Context, repo, reason string)
+	// disabled is whether the last poll found the repo disabled, so Run
+	// logs the change only once.
+	disabled bool
+	// onPolled is called after each poll Run makes; see SetPollHandler.
+	onPolled func(PollResult)
+	// rate is GitHub's rate limit as of the last response, if any.
+	rate gogithub.Rate
 }
 
-// NewPoller creates a new issue Poller for a specific repository.
+// PollResult describes a poll made by Run.
+type PollResult struct {
+	// Repo is the owner/repo the poller was created for.
+	Repo string
+	At   time.Time
+	// Err is the poll's error, or nil.
+	Err error
+	// Rate is GitHub's core rate limit as of the poll's last response. Its
+	// Limit is 0 if no response was received.
+	Rate gogithub.Rate
+}
+
+// NewPoller creates a new issue Poller for a specific repository. It logs
+// through the log package's default output and flags, which slog.SetDefault
+// points at the default slog handler.
 func NewPoller(client *gogithub.Client, st *store.DB, broker *pubsub.Broker[IssueEvent], owner, repo string) *Poller {
 	return &Poller{
 		client: client,
 		store:  st,
 		broker: broker,
+		name:   owner + "/" + repo,
 		owner:  owner,
 		repo:   repo,
-		logger: log.New(log.Writer(), fmt.Sprintf("[poller %s/%s] ", owner, repo), log.LstdFlags),
+		logger: log.New(log.Writer(), fmt.Sprintf("[poller %s/%s] ", owner, repo), log.Flags()),
 	}
 }
 
+// retriageAssociations are the author associations, as reported by GitHub,
+// of users allowed to request a re-triage.
+var retriageAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// SetRetriageCommands enables re-triage by comment. When a repo owner, member,
+// or collaborator posts a comment starting with one of commands, such as
+// "/triage", the issue is published again as a ChangeRetriage event.
+func (p *Poller) SetRetriageCommands(commands []string) {
+	p.retriageCommands = commands
+}
+
+// SetTrackPullRequests makes the poller store the pull requests it sees, so
+// issues can be matched against them.
+func (p *Poller) SetTrackPullRequests(track bool) {
+	p.trackPullRequests = track
+}
+
+// SetReconcileInterval makes the poller check, at most once per interval,
+// that every stored issue is still in the repo. Issues that were deleted,
+// transferred, or converted to a discussion never show up in the updated
+// issues listing, so without it they stay in the store and keep being
+// suggested as duplicates. Zero disables the check.
+func (p *Poller) SetReconcileInterval(interval time.Duration) {
+	p.reconcileInterval = interval
+}
+
+// SetDisabledHandler sets a function called once when the poller disables
+// the repo because GitHub no longer finds it, so the operator can be told.
+func (p *Poller) SetDisabledHandler(fn func(ctx context.Context, repo, reason string)) {
+	p.onDisabled = fn
+}
+
+// SetPollHandler sets a function called after each poll Run makes, such as
+// to report when the repo was last polled.
+func (p *Poller) SetPollHandler(fn func(PollResult)) {
+	p.onPolled = fn
+}
+
 // Run starts the continuous poll loop, polling at the given interval until
 // the context is cancelled.
 func (p *Poller) Run(ctx context.Context, interval time.Duration) error {
 	p.logger.Printf("starting poll loop with interval %s", interval)
 
 	// Do an immediate poll
-	if err := p.Poll(ctx); err != nil {
-		p.logger.Printf("initial poll error: %v", err)
+	err := p.Poll(ctx)
+	if err != nil {
+		p.logPollError("initial poll error", err)
 	}
+	p.polled(err)
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -63,22 +159,102 @@ func (p *Poller) Run(ctx context.Context, interval time.Duration) error {
 			p.logger.Printf("shutting down: %v", ctx.Err())
 			return ctx.Err()
 		case <-ticker.C:
-			if err := p.Poll(ctx); err != nil {
-				p.logger.Printf("poll error: %v", err)
-				// Continue polling; transient errors are expected.
+			err := p.Poll(ctx)
+			if err != nil {
+				p.logPollError("poll error", err)
+				// Continue polling; transient errors are expected, and a
+				// disabled repo is checked in the store without API calls
+				// so that enabling it again takes effect.
 			}
+			p.polled(err)
 		}
 	}
 }
 
+// polled reports a poll that ended with err to the poll handler, if any.
+func (p *Poller) polled(err error) {
+	if p.onPolled != nil {
+		p.onPolled(PollResult{Repo: p.name, At: time.Now(), Err: err, Rate: p.rate})
+	}
+}
+
+// logPollError logs a failed poll. A disabled repo is logged only when it
+// becomes disabled.
+func (p *Poller) logPollError(prefix string, err error) {
+	disabled := errors.Is(err, ErrRepoUnavailable)
+	if disabled && p.disabled {
+		return
+	}
+	p.disabled = disabled
+	if disabled {
+		p.logger.Printf("%v; not polling until it is enabled with \"triage repos enable %s\"", err, p.name)
+		return
+	}
+	p.logger.Printf("%s: %v", prefix, err)
+}
+
 // Poll performs a single poll cycle: fetch updated issues, diff against
-// stored snapshots, publish events, and update the watermark.
+// stored snapshots, publish events, and update the watermark. Stored issues
+// are reconciled with GitHub too, when due.
 func (p *Poller) Poll(ctx context.Context) error {
 	// Ensure the repo record exists in the store.
 	repoRecord, err := p.ensureRepo()
 	if err != nil {
 		return fmt.Errorf("ensuring repo record: %w", err)
 	}
+	if repoRecord.DisabledAt != nil {
+		return fmt.Errorf("%w: disabled after %s", ErrRepoUnavailable, repoRecord.DisabledReason)
+	}
+	p.follow(repoRecord)
+	if p.disabled {
+		p.logger.Printf("repo enabled again, resuming polling")
+		p.disabled = false
+	}
+	if err := p.pollIssues(ctx, repoRecord.ID); err != nil {
+		return err
+	}
+	if p.reconcileInterval > 0 {
+		if err := p.reconcileIfDue(ctx, repoRecord.ID); err != nil {
+			p.logger.Printf("reconciling stored issues: %v", err)
+		}
+	}
+	return nil
+}
+
+// repoGoneReason returns the status, such as "404 Not Found", if err is
+// GitHub saying the repo is not there, else "".
+func repoGoneReason(err error) string {
+	var ghErr *gogithub.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil {
+		return ""
+	}
+	switch code := ghErr.Response.StatusCode; code {
+	case http.StatusNotFound, http.StatusGone:
+		return fmt.Sprintf("%d %s", code, http.StatusText(code))
+	}
+	return ""
+}
+
+// disable marks the repo as disabled for reason and, the first time, calls
+// the disabled handler. It returns the ErrRepoUnavailable to report.
+func (p *Poller) disable(ctx context.Context, repoID int64, reason string) error {
+	newly, err := p.store.DisableRepo(repoID, reason)
+	if err != nil {
+		return err
+	}
+	if newly && p.onDisabled != nil {
+		p.onDisabled(ctx, p.name, reason)
+	}
+	return fmt.Errorf("%w: GitHub returned %s", ErrRepoUnavailable, reason)
+}
+
+// pollIssues fetches the issues updated since the watermark and publishes
+// their changes.
+func (p *Poller) pollIssues(ctx context.Context, repoID int64) error {
+	cursor, err := p.store.GetPollCursor(repoID, store.EndpointIssues)
+	if err != nil {
+		return err
+	}
 
 	// Build list options with watermark.
 	opts := &gogithub.IssueListByRepoOptions{
@@ -90,8 +266,8 @@ func (p *Poller) Poll(ctx context.Context) error {
 		},
 	}
 
-	if repoRecord.LastPolledAt != nil {
-		opts.Since = *repoRecord.LastPolledAt
+	if cursor.Watermark != nil {
+		opts.Since = *cursor.Watermark
 	}
 
 	var latestUpdatedAt time.Time
@@ -100,15 +276,23 @@ func (p *Poller) Poll(ctx context.Context) error {
 
 	// Paginate through all results.
 	for {
-		if err := ctx.Err(); err != nil {
+		if err := p.waitForRoom(ctx); err != nil {
 			return err
 		}
 
-		issues, resp, err := p.fetchIssuesWithRetry(ctx, opts, repoRecord.ETag)
+		issues, resp, err := p.fetchIssuesWithRetry(ctx, opts, cursor.ETag)
 		if err != nil {
+			// Only the first page says whether the repo itself is there
+			if reason := repoGoneReason(err); reason != "" && opts.ListOptions.Page <= 1 {
+				return p.disable(ctx, repoID, reason)
+			}
 			return fmt.Errorf("fetching issues: %w", err)
 		}
 
+		if resp != nil && resp.Rate.Limit > 0 {
+			p.rate = resp.Rate
+		}
+
 		// 304 Not Modified — nothing new.
 		if resp != nil && resp.StatusCode == http.StatusNotModified {
 			p.logger.Printf("no changes (304 Not Modified)")
@@ -136,14 +320,29 @@ func (p *Poller) Poll(ctx context.Context) error {
 			}
 		}
 
+		if len(issues) > 0 && !p.isOwnIssue(issues[0]) {
+			if err := p.checkRename(ctx, repoID); err != nil {
+				p.logger.Printf("checking for a repo rename: %v", err)
+			}
+		}
+
 		for _, ghIssue := range issues {
 			// Skip pull requests (GitHub API returns PRs as issues).
 			if ghIssue.PullRequestLinks != nil {
+				if p.trackPullRequests {
+					if err := p.store.UpsertPullRequest(ConvertPullRequest(repoID, ghIssue)); err != nil {
+						p.logger.Printf("error storing pull request #%d: %v", ghIssue.GetNumber(), err)
+					}
+				}
 				continue
 			}
 
 			issue := convertIssue(ghIssue)
-			changes, err := p.diffAndPublish(repoRecord.ID, issue)
+			changes, err := p.diffAndPublish(ctx, repoID, issue)
+			if ctx.Err() != nil {
+				// Not published, so the watermark must not pass it
+				return ctx.Err()
+			}
 			if err != nil {
 				p.logger.Printf("error processing issue #%d: %v", issue.Number, err)
 				continue
@@ -163,21 +362,40 @@ func (p *Poller) Poll(ctx context.Context) error {
 			break
 		}
 		opts.ListOptions.Page = resp.NextPage
+
+		// Results are in ascending update order, so everything up to this
+		// page is done. Save that progress so a failure on a later page
+		// doesn't refetch it. The ETag is dropped: it belongs to a request
+		// whose since parameter no longer matches the saved watermark.
+		if !latestUpdatedAt.IsZero() {
+			if err := p.store.SavePollCursor(repoID, store.EndpointIssues, latestUpdatedAt.Add(-watermarkBuffer), ""); err != nil {
+				return fmt.Errorf("updating poll state: %w", err)
+			}
+		}
+	}
+
+	// A command comment also bumps the issue's UpdatedAt, so the 304 above
+	// means there are none to look for. On the first poll there is no
+	// watermark, and old commands should not be replayed.
+	if len(p.retriageCommands) > 0 && cursor.Watermark != nil {
+		if err := p.checkRetriageCommands(ctx, *cursor.Watermark); err != nil {
+			p.logger.Printf("checking retriage commands: %v", err)
+		}
 	}
 
 	// Advance watermark: latest UpdatedAt minus buffer.
 	if !latestUpdatedAt.IsZero() {
 		watermark := latestUpdatedAt.Add(-watermarkBuffer)
-		if err := p.store.UpdatePollState(repoRecord.ID, watermark, newETag); err != nil {
+		if err := p.store.SavePollCursor(repoID, store.EndpointIssues, watermark, newETag); err != nil {
 			return fmt.Errorf("updating poll state: %w", err)
 		}
 	} else if newETag != "" {
 		// No issues but got a new ETag, still save it.
 		polledAt := time.Now().UTC()
-		if repoRecord.LastPolledAt != nil {
-			polledAt = *repoRecord.LastPolledAt
+		if cursor.Watermark != nil {
+			polledAt = *cursor.Watermark
 		}
-		if err := p.store.UpdatePollState(repoRecord.ID, polledAt, newETag); err != nil {
+		if err := p.store.SavePollCursor(repoID, store.EndpointIssues, polledAt, newETag); err != nil {
 			return fmt.Errorf("updating poll state: %w", err)
 		}
 	}
@@ -186,6 +404,279 @@ func (p *Poller) Poll(ctx context.Context) error {
 	return nil
 }
 
+// waitForRoom pauses while the broker's subscribers are saturated, so a poll
+// does not fetch more issues than the pipeline can take. It returns ctx's
+// error if ctx ends first.
+func (p *Poller) waitForRoom(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !p.broker.Saturated() {
+		return nil
+	}
+	p.logger.Printf("pipeline is behind (%d events queued), pausing poll", p.broker.Pending())
+	ticker := time.NewTicker(backPressureCheck)
+	defer ticker.Stop()
+	for p.broker.Saturated() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	p.logger.Printf("pipeline caught up, resuming poll")
+	return nil
+}
+
+// reconcileIfDue reconciles the repo's stored issues with GitHub if
+// reconcileInterval has passed since the last time. The first call only
+// starts the clock, since a new repo's issues were all just fetched.
+func (p *Poller) reconcileIfDue(ctx context.Context, repoID int64) error {
+	cursor, err := p.store.GetPollCursor(repoID, store.EndpointReconcile)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if cursor.Watermark != nil {
+		if now.Sub(*cursor.Watermark) < p.reconcileInterval {
+			return nil
+		}
+		if err := p.reconcile(ctx, repoID); err != nil {
+			return err
+		}
+	}
+	return p.store.SavePollCursor(repoID, store.EndpointReconcile, now, "")
+}
+
+// reconcile lists every issue in the repo and tombstones the stored issues
+// that are missing from it and confirmed gone, publishing a ChangeRemoved
+// event for each.
+func (p *Poller) reconcile(ctx context.Context, repoID int64) error {
+	// After a rename every issue would look transferred
+	if err := p.checkRename(ctx, repoID); err != nil {
+		return err
+	}
+	stored, err := p.store.IssueNumbers(repoID)
+	if err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+
+	seen := make(map[int]bool)
+	opts := &gogithub.IssueListByRepoOptions{
+		State:       "all",
+		ListOptions: gogithub.ListOptions{PerPage: 100},
+	}
+	for {
+		issues, resp, err := p.client.Issues.ListByRepo(ctx, p.owner, p.repo, opts)
+		if err != nil {
+			return fmt.Errorf("listing issues: %w", err)
+		}
+		for _, issue := range issues {
+			seen[issue.GetNumber()] = true
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+
+	removed := 0
+	for _, number := range stored {
+		if seen[number] {
+			continue
+		}
+		// The listing may have missed an issue changed mid-pagination, so
+		// each one is confirmed before it is tombstoned.
+		reason, err := p.goneReason(ctx, number)
+		if err != nil {
+			p.logger.Printf("checking issue #%d: %v", number, err)
+			continue
+		}
+		if reason == "" {
+			continue
+		}
+		if err := p.store.TombstoneIssue(repoID, number, reason); err != nil {
+			return err
+		}
+		removed++
+		p.logger.Printf("issue #%d is gone (%s)", number, reason)
+		if err := p.broker.PublishWait(ctx, pubsub.Deleted, IssueEvent{
+			Repo:       p.name,
+			Issue:      Issue{Number: number},
+			ChangeType: ChangeRemoved,
+		}); err != nil {
+			return err
+		}
+	}
+	p.logger.Printf("reconcile complete: %d of %d stored issues gone", removed, len(stored))
+	return nil
+}
+
+// goneReason fetches an issue and returns why it is no longer in the repo:
+// "deleted" (410 Gone), "not_found" (404, as for issues converted to a
+// discussion), or "transferred" (GitHub redirects to its new repo). It
+// returns "" if the issue is still there.
+func (p *Poller) goneReason(ctx context.Context, number int) (string, error) {
+	issue, resp, err := p.client.Issues.Get(ctx, p.owner, p.repo, number)
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusGone:
+			return "deleted", nil
+		case http.StatusNotFound:
+			return "not_found", nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if !p.isOwnIssue(issue) {
+		return "transferred", nil
+	}
+	return "", nil
+}
+
+// isOwnIssue reports whether GitHub lists issue as belonging to the repo
+// under its current name. Issues without a repository URL are assumed to.
+func (p *Poller) isOwnIssue(issue *gogithub.Issue) bool {
+	suffix := strings.ToLower("/repos/" + p.owner + "/" + p.repo)
+	u := issue.GetRepositoryURL()
+	return u == "" || strings.HasSuffix(strings.ToLower(u), suffix)
+}
+
+// checkRename asks GitHub for the repo's current name, which differs if it
+// was renamed or transferred (GitHub redirects the old name). If so, the
+// stored record is renamed, keeping its issues, embeddings, and history,
+// and the poller uses the new name from then on.
+func (p *Poller) checkRename(ctx context.Context, repoID int64) error {
+	r, _, err := p.client.Repositories.Get(ctx, p.owner, p.repo)
+	if err != nil {
+		return fmt.Errorf("fetching repo: %w", err)
+	}
+	owner, name := r.GetOwner().GetLogin(), r.GetName()
+	if owner == "" || name == "" || strings.EqualFold(owner+"/"+name, p.owner+"/"+p.repo) {
+		return nil
+	}
+	if err := p.store.RenameRepo(repoID, owner, name); err != nil {
+		return err
+	}
+	p.logger.Printf("repo renamed on GitHub from %s/%s to %s/%s; stored data kept", p.owner, p.repo, owner, name)
+	if !strings.EqualFold(p.name, owner+"/"+name) {
+		p.logger.Printf("update the config to watch %s/%s instead of %s", owner, name, p.name)
+	}
+	p.owner, p.repo = owner, name
+	return nil
+}
+
+// follow switches the poller to the stored name of repo, which differs from
+// the configured one once a rename was detected.
+func (p *Poller) follow(repo *store.Repo) {
+	p.owner, p.repo = repo.Owner, repo.RepoName
+}
+
+// checkRetriageCommands looks for command comments created since the
+// watermark and publishes a ChangeRetriage event for each issue that got one.
+func (p *Poller) checkRetriageCommands(ctx context.Context, since time.Time) error {
+	opts := &gogithub.IssueListCommentsOptions{
+		Sort:      gogithub.String("created"),
+		Direction: gogithub.String("asc"),
+		Since:     &since,
+		ListOptions: gogithub.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var numbers []int
+	requested := make(map[int]bool)
+	newest := p.lastCommentID
+	for {
+		// Number 0 lists comments on every issue in the repo.
+		comments, resp, err := p.client.Issues.ListComments(ctx, p.owner, p.repo, 0, opts)
+		if err != nil {
+			return fmt.Errorf("listing comments: %w", err)
+		}
+
+		for _, c := range comments {
+			if c.GetID() <= p.lastCommentID {
+				continue
+			}
+			newest = max(newest, c.GetID())
+			// Since filters on UpdatedAt; an edited old comment is not a
+			// new request.
+			if c.CreatedAt == nil || c.CreatedAt.Before(since) {
+				continue
+			}
+			if !retriageAssociations[c.GetAuthorAssociation()] || !p.isRetriageCommand(c.GetBody()) {
+				continue
+			}
+			number, ok := issueNumberFromURL(c.GetIssueURL())
+			if !ok || requested[number] {
+				continue
+			}
+			requested[number] = true
+			numbers = append(numbers, number)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+	p.lastCommentID = newest
+
+	for _, number := range numbers {
+		ghIssue, _, err := p.client.Issues.Get(ctx, p.owner, p.repo, number)
+		if err != nil {
+			p.logger.Printf("fetching issue #%d for retriage: %v", number, err)
+			continue
+		}
+		// Comments on pull requests are listed too.
+		if ghIssue.PullRequestLinks != nil {
+			continue
+		}
+		p.logger.Printf("retriage requested for issue #%d", number)
+		if err := p.broker.PublishWait(ctx, pubsub.Updated, IssueEvent{
+			Repo:       p.name,
+			Issue:      convertIssue(ghIssue),
+			ChangeType: ChangeRetriage,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isRetriageCommand reports whether a comment body starts with one of the
+// configured commands.
+func (p *Poller) isRetriageCommand(body string) bool {
+	fields := strings.Fields(body)
+	if len(fields) == 0 {
+		return false
+	}
+	for _, command := range p.retriageCommands {
+		if strings.EqualFold(fields[0], command) {
+			return true
+		}
+	}
+	return false
+}
+
+// issueNumberFromURL returns the issue number at the end of a comment's
+// issue_url, such as https://api.github.com/repos/o/r/issues/12.
+func issueNumberFromURL(u string) (int, bool) {
+	idx := strings.LastIndex(u, "/")
+	if idx == -1 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(u[idx+1:])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
 // fetchIssuesWithRetry wraps the GitHub API call with retry logic for server
 // errors and rate limit handling.
 func (p *Poller) fetchIssuesWithRetry(ctx context.Context, opts *gogithub.IssueListByRepoOptions, etag string) ([]*gogithub.Issue, *gogithub.Response, error) {
@@ -283,8 +774,10 @@ func (p *Poller) listIssuesWithETag(ctx context.Context, opts *gogithub.IssueLis
 }
 
 // diffAndPublish compares the incoming issue against the stored snapshot,
-// publishes events for detected changes, and upserts the snapshot.
-func (p *Poller) diffAndPublish(repoID int64, issue Issue) ([]ChangeType, error) {
+// publishes events for detected changes, and upserts the snapshot. Events
+// wait for room in the broker rather than being dropped; if ctx ends first,
+// the snapshot is left as it was so the changes are found again.
+func (p *Poller) diffAndPublish(ctx context.Context, repoID int64, issue Issue) ([]ChangeType, error) {
 	bodyHash := hashBody(issue.Body)
 
 	existing, err := p.store.GetIssue(repoID, issue.Number)
@@ -305,15 +798,16 @@ func (p *Poller) diffAndPublish(repoID int64, issue Issue) ([]ChangeType, error)
 		changes = DiffSnapshot(existing, &issue, bodyHash)
 	}
 
-	// Publish events for actionable changes.
+	// Publish an event per change; subscribers act on the types they need,
+	// e.g. the pipeline on new and edited issues.
 	for _, ct := range changes {
-		if ct == ChangeNew || ct == ChangeTitleEdited || ct == ChangeBodyEdited {
-			evt := IssueEvent{
-				Repo:       fmt.Sprintf("%s/%s", p.owner, p.repo),
-				Issue:      issue,
-				ChangeType: ct,
-			}
-			p.broker.Publish(pubsub.Created, evt)
+		evt := IssueEvent{
+			Repo:       p.name,
+			Issue:      issue,
+			ChangeType: ct,
+		}
+		if err := p.broker.PublishWait(ctx, pubsub.Created, evt); err != nil {
+			return nil, err
 		}
 	}
 
@@ -327,6 +821,8 @@ func (p *Poller) diffAndPublish(repoID int64, issue Issue) ([]ChangeType, error)
 		State:     issue.State,
 		Author:    issue.Author,
 		Labels:    issue.Labels,
+		Assignees: issue.Assignees,
+		Milestone: issue.Milestone,
 		CreatedAt: issue.CreatedAt,
 		UpdatedAt: issue.UpdatedAt,
 	}
@@ -358,15 +854,27 @@ func DiffSnapshot(stored *store.Issue, incoming *Issue, incomingBodyHash string)
 		changes = append(changes, ChangeLabelsChanged)
 	}
 
+	// Snapshots from before assignees and milestones were recorded have nil
+	// Assignees; diffing those would report a change for every such issue.
+	if stored.Assignees != nil {
+		if !labelsEqual(stored.Assignees, incoming.Assignees) {
+			changes = append(changes, ChangeAssigneesChanged)
+		}
+		if stored.Milestone != incoming.Milestone {
+			changes = append(changes, ChangeMilestoneChanged)
+		}
+	}
+
 	return changes
 }
 
 // ensureRepo gets or creates the repo record in the store.
 func (p *Poller) ensureRepo() (*store.Repo, error) {
-	repo, err := p.store.GetRepoByOwnerRepo(p.owner, p.repo)
+	owner, name, _ := strings.Cut(p.name, "/")
+	repo, err := p.store.GetRepoByOwnerRepo(owner, name)
 	if err != nil {
 		if isNotFound(err) {
-			return p.store.CreateRepo(p.owner, p.repo)
+			return p.store.CreateRepo(owner, name)
 		}
 		return nil, err
 	}
@@ -389,6 +897,12 @@ func convertIssue(gh *gogithub.Issue) Issue {
 	for _, label := range gh.Labels {
 		issue.Labels = append(issue.Labels, label.GetName())
 	}
+	for _, a := range gh.Assignees {
+		issue.Assignees = append(issue.Assignees, a.GetLogin())
+	}
+	if gh.Milestone != nil {
+		issue.Milestone = gh.Milestone.GetTitle()
+	}
 
 	if gh.CreatedAt != nil {
 		issue.CreatedAt = gh.CreatedAt.Time
@@ -400,6 +914,22 @@ func convertIssue(gh *gogithub.Issue) Issue {
 	return issue
 }
 
+// ConvertPullRequest converts a pull request from the issues listing, which
+// GitHub returns alongside issues, to a store record.
+func ConvertPullRequest(repoID int64, gh *gogithub.Issue) *store.PullRequest {
+	pr := &store.PullRequest{
+		RepoID: repoID,
+		Number: gh.GetNumber(),
+		Title:  gh.GetTitle(),
+		Body:   gh.GetBody(),
+		State:  gh.GetState(),
+	}
+	if gh.UpdatedAt != nil {
+		pr.UpdatedAt = gh.UpdatedAt.Time
+	}
+	return pr
+}
+
 // hashBody returns the hex-encoded SHA-256 hash of the body text.
 func hashBody(body string) string {
 	h := sha256.Sum256([]byte(body))
@@ -430,8 +960,5 @@ func labelsEqual(a, b []string) bool {
 
 // isNotFound checks if an error is a "not found" type error from the store.
 func isNotFound(err error) bool {
-	if err == nil {
-		return false
-	}
-	return err == sql.ErrNoRows || strings.Contains(err.Error(), "no rows")
+	return errors.Is(err, store.ErrNotFound)
 }
@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -252,6 +253,97 @@ func TestPollerWatermarkAdvancement(t *testing.T) {
 	}
 }
 
+func TestPollerWatermarkSavedPerPage(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	page1Time := now.Add(-30 * time.Minute)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var failPage2 atomic.Bool
+	failPage2.Store(true)
+	var sinces []string
+
+	mux.HandleFunc("/repos/testowner/testrepo/issues", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" || page == "1" {
+			sinces = append(sinces, r.URL.Query().Get("since"))
+		}
+
+		var issues []map[string]interface{}
+		switch page {
+		case "", "1":
+			issues = []map[string]interface{}{
+				makeGitHubIssueJSON(1, "Issue 1", "Body 1", "open", page1Time.Add(-time.Minute)),
+				makeGitHubIssueJSON(2, "Issue 2", "Body 2", "open", page1Time),
+			}
+			w.Header().Set("ETag", `"page-one"`)
+			w.Header().Set("Link", fmt.Sprintf("<%s/repos/testowner/testrepo/issues?page=2>; rel=\"next\"", srv.URL))
+		case "2":
+			if failPage2.Load() {
+				http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+				return
+			}
+			issues = []map[string]interface{}{
+				makeGitHubIssueJSON(3, "Issue 3", "Body 3", "open", now),
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(issues)
+	})
+
+	client := gogithub.NewClient(nil)
+	baseURL, err := client.BaseURL.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("parsing base URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	defer db.Close()
+
+	poller := NewPoller(client, db, pubsub.NewBroker[IssueEvent](), "testowner", "testrepo")
+
+	if err := poller.Poll(context.Background()); err == nil {
+		t.Fatal("expected error when page 2 fails")
+	}
+
+	repo, err := db.GetRepoByOwnerRepo("testowner", "testrepo")
+	if err != nil {
+		t.Fatalf("getting repo: %v", err)
+	}
+	if repo.LastPolledAt == nil {
+		t.Fatal("expected watermark from page 1 to be saved")
+	}
+	want := page1Time.Add(-watermarkBuffer)
+	if !repo.LastPolledAt.Equal(want) {
+		t.Errorf("watermark = %v, want %v", *repo.LastPolledAt, want)
+	}
+	if repo.ETag != "" {
+		t.Errorf("ETag = %q, want empty after a partial poll", repo.ETag)
+	}
+
+	failPage2.Store(false)
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("second Poll() error: %v", err)
+	}
+
+	if len(sinces) != 2 || sinces[1] != want.Format(time.RFC3339) {
+		t.Errorf("since params = %v, want second poll to resume from %s", sinces, want.Format(time.RFC3339))
+	}
+	repo, err = db.GetRepoByOwnerRepo("testowner", "testrepo")
+	if err != nil {
+		t.Fatalf("getting repo: %v", err)
+	}
+	if want := now.Add(-watermarkBuffer); !repo.LastPolledAt.Equal(want) {
+		t.Errorf("watermark after full poll = %v, want %v", *repo.LastPolledAt, want)
+	}
+}
+
 func TestPollerRateLimitBackoff(t *testing.T) {
 	var requestCount atomic.Int32
 
@@ -588,6 +680,32 @@ func TestPollerSkipsPullRequests(t *testing.T) {
 	case <-time.After(200 * time.Millisecond):
 		// Good: no extra events
 	}
+
+	repo, err := db.GetRepoByOwnerRepo("testowner", "testrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prs, _ := db.ListOpenPullRequests(repo.ID); len(prs) != 0 {
+		t.Errorf("expected pull requests not to be stored by default, got %d", len(prs))
+	}
+
+	// With tracking on, the PR is stored for matching but still not published.
+	poller.SetTrackPullRequests(true)
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("second Poll() error: %v", err)
+	}
+	prs, err := db.ListOpenPullRequests(repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 1 || prs[0].Number != 2 || prs[0].Body != "PR body" {
+		t.Errorf("expected PR #2 to be stored, got %+v", prs)
+	}
+	select {
+	case evt := <-sub:
+		t.Errorf("unexpected event for #%d", evt.Payload.Issue.Number)
+	case <-time.After(100 * time.Millisecond):
+	}
 }
 
 func TestPollerUpdatedIssueDetected(t *testing.T) {
@@ -658,3 +776,465 @@ func TestPollerUpdatedIssueDetected(t *testing.T) {
 		t.Fatal("timed out waiting for title change event")
 	}
 }
+
+func TestPollerRetriageCommand(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	var commentRequests atomic.Int32
+
+	comment := func(id int64, number int, association, body string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":                 id,
+			"body":               body,
+			"author_association": association,
+			"issue_url":          fmt.Sprintf("https://api.github.com/repos/testowner/testrepo/issues/%d", number),
+			"created_at":         now.Format(time.RFC3339),
+			"updated_at":         now.Format(time.RFC3339),
+		}
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/testowner/testrepo/issues":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				makeGitHubIssueJSON(5, "Crash", "Body", "open", now),
+			})
+		case "/repos/testowner/testrepo/issues/comments":
+			commentRequests.Add(1)
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				comment(1, 5, "NONE", "/triage"),
+				comment(2, 7, "MEMBER", "looks fine to me"),
+				comment(3, 5, "MEMBER", "/Retriage\nwrong labels"),
+				comment(4, 5, "OWNER", "/triage"),
+				comment(5, 6, "OWNER", "/triage"),
+			})
+		case "/repos/testowner/testrepo/issues/5":
+			json.NewEncoder(w).Encode(makeGitHubIssueJSON(5, "Crash", "Body", "open", now))
+		case "/repos/testowner/testrepo/issues/6":
+			pr := makeGitHubIssueJSON(6, "Fix", "", "open", now)
+			pr["pull_request"] = map[string]interface{}{"url": "https://api.github.com/repos/testowner/testrepo/pulls/6"}
+			json.NewEncoder(w).Encode(pr)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	poller, srv, db, broker := newTestPoller(t, handler)
+	defer srv.Close()
+	defer db.Close()
+	poller.SetRetriageCommands([]string{"/triage", "/retriage"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := broker.Subscribe(ctx)
+
+	// The first poll has no watermark, so earlier commands are not replayed.
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("first Poll() error: %v", err)
+	}
+	if evt := <-sub; evt.Payload.ChangeType != ChangeNew {
+		t.Fatalf("expected ChangeNew, got %s", evt.Payload.ChangeType)
+	}
+	if commentRequests.Load() != 0 {
+		t.Errorf("expected no comment requests on the first poll, got %d", commentRequests.Load())
+	}
+
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("second Poll() error: %v", err)
+	}
+	select {
+	case evt := <-sub:
+		if evt.Payload.ChangeType != ChangeRetriage || evt.Payload.Issue.Number != 5 {
+			t.Errorf("expected retriage of #5, got %s of #%d", evt.Payload.ChangeType, evt.Payload.Issue.Number)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for retriage event")
+	}
+
+	// The same comments are seen again within the watermark buffer but
+	// must not trigger another retriage; nor should the pull request.
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("third Poll() error: %v", err)
+	}
+	select {
+	case evt := <-sub:
+		t.Errorf("unexpected event %s for #%d", evt.Payload.ChangeType, evt.Payload.Issue.Number)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestIsRetriageCommand(t *testing.T) {
+	p := &Poller{retriageCommands: []string{"/triage"}}
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{"/triage", true},
+		{"  /TRIAGE\nplease", true},
+		{"/triage-me", false},
+		{"please /triage", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := p.isRetriageCommand(tt.body); got != tt.want {
+			t.Errorf("isRetriageCommand(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestPollerReconcileTombstonesGoneIssues(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/testowner/testrepo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"testrepo","owner":{"login":"testowner"}}`)
+	})
+	mux.HandleFunc("/repos/testowner/testrepo/issues", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			makeGitHubIssueJSON(1, "Issue 1", "Body 1", "open", now.Add(-48*time.Hour)),
+			makeGitHubIssueJSON(3, "Issue 3", "Body 3", "closed", now.Add(-48*time.Hour)),
+		})
+	})
+	mux.HandleFunc("/repos/testowner/testrepo/issues/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+		fmt.Fprint(w, `{"message":"This issue was deleted"}`)
+	})
+	mux.HandleFunc("/repos/testowner/testrepo/issues/4", func(w http.ResponseWriter, r *http.Request) {
+		issue := makeGitHubIssueJSON(17, "Moved", "", "open", now)
+		issue["repository_url"] = "https://api.github.com/repos/testowner/elsewhere"
+		json.NewEncoder(w).Encode(issue)
+	})
+	mux.HandleFunc("/repos/testowner/testrepo/issues/5", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	})
+	mux.HandleFunc("/repos/testowner/testrepo/issues/6", func(w http.ResponseWriter, r *http.Request) {
+		// Missed by the listing, but still there
+		issue := makeGitHubIssueJSON(6, "Issue 6", "", "open", now)
+		issue["repository_url"] = "https://api.github.com/repos/TestOwner/TestRepo"
+		json.NewEncoder(w).Encode(issue)
+	})
+
+	poller, srv, db, broker := newTestPoller(t, mux)
+	defer srv.Close()
+	defer db.Close()
+	poller.SetReconcileInterval(time.Hour)
+
+	repo, err := db.CreateRepo("testowner", "testrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 1; n <= 6; n++ {
+		if err := db.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: n, Title: fmt.Sprintf("Issue %d", n), State: "open", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first poll only starts the clock
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	if numbers, _ := db.IssueNumbers(repo.ID); len(numbers) != 6 {
+		t.Fatalf("expected no tombstones on the first poll, got numbers %v", numbers)
+	}
+
+	// Once the interval has passed, gone issues are tombstoned
+	if err := db.SavePollCursor(repo.ID, store.EndpointReconcile, now.Add(-2*time.Hour), ""); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := broker.Subscribe(ctx)
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+
+	wantReasons := map[int]string{1: "", 2: "deleted", 3: "", 4: "transferred", 5: "not_found", 6: ""}
+	for n, want := range wantReasons {
+		issue, err := db.GetIssue(repo.ID, n)
+		if err != nil {
+			t.Fatalf("GetIssue(%d): %v", n, err)
+		}
+		if issue.TombstoneReason != want || (want == "") != (issue.TombstonedAt == nil) {
+			t.Errorf("issue #%d: tombstone %q at %v, want %q", n, issue.TombstoneReason, issue.TombstonedAt, want)
+		}
+	}
+
+	var removed []int
+	for len(removed) < 3 {
+		select {
+		case evt := <-sub:
+			if evt.Payload.ChangeType == ChangeRemoved {
+				removed = append(removed, evt.Payload.Issue.Number)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for removal events, got %v", removed)
+		}
+	}
+	if fmt.Sprint(removed) != "[2 4 5]" {
+		t.Errorf("removed = %v, want [2 4 5]", removed)
+	}
+
+	// Not again until the interval passes
+	cursor, _ := db.GetPollCursor(repo.ID, store.EndpointReconcile)
+	if cursor.Watermark == nil || time.Since(*cursor.Watermark) > time.Minute {
+		t.Errorf("expected the reconcile time to be recorded, got %v", cursor.Watermark)
+	}
+}
+
+func TestPollerDisablesMissingRepo(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var requestCount atomic.Int32
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestCount.Add(1)
+				w.WriteHeader(status)
+				fmt.Fprint(w, `{"message":"Not Found"}`)
+			})
+			poller, srv, db, _ := newTestPoller(t, handler)
+			defer srv.Close()
+			defer db.Close()
+
+			var notified []string
+			poller.SetDisabledHandler(func(_ context.Context, repo, reason string) {
+				notified = append(notified, repo+": "+reason)
+			})
+
+			for i := 0; i < 3; i++ {
+				err := poller.Poll(context.Background())
+				if !errors.Is(err, ErrRepoUnavailable) {
+					t.Fatalf("poll %d: expected ErrRepoUnavailable, got %v", i, err)
+				}
+			}
+			if got := requestCount.Load(); got != 1 {
+				t.Errorf("expected no API calls once disabled, got %d requests", got)
+			}
+			want := fmt.Sprintf("testowner/testrepo: %d %s", status, http.StatusText(status))
+			if len(notified) != 1 || notified[0] != want {
+				t.Errorf("expected one notification %q, got %v", want, notified)
+			}
+
+			repo, _ := db.GetRepoByOwnerRepo("testowner", "testrepo")
+			if repo.DisabledAt == nil {
+				t.Fatal("expected the repo to be disabled in the store")
+			}
+
+			// Enabling the repo resumes polling
+			if err := db.EnableRepo(repo.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := poller.Poll(context.Background()); !errors.Is(err, ErrRepoUnavailable) || requestCount.Load() != 2 {
+				t.Errorf("expected a new poll after enabling, got %d requests, err %v", requestCount.Load(), err)
+			}
+			if len(notified) != 2 {
+				t.Errorf("expected a notification after disabling again, got %v", notified)
+			}
+		})
+	}
+}
+
+func TestPollerFollowsRename(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	renamed := func(n int) map[string]interface{} {
+		issue := makeGitHubIssueJSON(n, fmt.Sprintf("Issue %d", n), "", "open", now.Add(-time.Minute))
+		issue["repository_url"] = "https://api.github.com/repos/neworg/newrepo"
+		return issue
+	}
+
+	var newPathRequests atomic.Int32
+	mux := http.NewServeMux()
+	// GitHub redirects the old name, so the old path serves the new repo
+	mux.HandleFunc("/repos/testowner/testrepo/issues", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{renamed(1)})
+	})
+	for _, path := range []string{"/repos/testowner/testrepo", "/repos/neworg/newrepo"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"name":"newrepo","owner":{"login":"neworg"}}`)
+		})
+	}
+	mux.HandleFunc("/repos/neworg/newrepo/issues", func(w http.ResponseWriter, r *http.Request) {
+		newPathRequests.Add(1)
+		json.NewEncoder(w).Encode([]map[string]interface{}{renamed(1), renamed(2)})
+	})
+
+	poller, srv, db, broker := newTestPoller(t, mux)
+	defer srv.Close()
+	defer db.Close()
+	poller.SetReconcileInterval(time.Hour)
+
+	repo, err := db.CreateRepo("testowner", "testrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpsertIssue(&store.Issue{RepoID: repo.ID, Number: 2, Title: "Issue 2", State: "open", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := broker.Subscribe(ctx)
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+
+	got, err := db.GetRepoByOwnerRepo("neworg", "newrepo")
+	if err != nil || got.ID != repo.ID {
+		t.Fatalf("expected the record to be renamed in place, got %+v, %v", got, err)
+	}
+	if old, err := db.GetRepoByOwnerRepo("testowner", "testrepo"); err != nil || old.ID != repo.ID {
+		t.Errorf("expected the old name to still resolve, got %+v, %v", old, err)
+	}
+	select {
+	case evt := <-sub:
+		if evt.Payload.Repo != "testowner/testrepo" {
+			t.Errorf("expected events under the configured name, got %q", evt.Payload.Repo)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+
+	// Later polls use the new name, and reconcile does not take the
+	// stored issues for transferred ones
+	if err := db.SavePollCursor(repo.ID, store.EndpointReconcile, now.Add(-2*time.Hour), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := poller.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	if newPathRequests.Load() == 0 {
+		t.Error("expected the issues of the new name to be listed")
+	}
+	issue, err := db.GetIssue(repo.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.TombstonedAt != nil {
+		t.Errorf("expected issue #2 to stay, got tombstone %q", issue.TombstoneReason)
+	}
+}
+
+func TestPollerRunReportsPolls(t *testing.T) {
+	poller, srv, db, _ := newTestPoller(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+	defer db.Close()
+
+	results := make(chan PollResult, 10)
+	poller.SetPollHandler(func(r PollResult) { results <- r })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- poller.Run(ctx, 10*time.Millisecond) }()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-results:
+			if r.Repo != "testowner/testrepo" || r.Err != nil || r.At.IsZero() {
+				t.Errorf("unexpected poll result %+v", r)
+			}
+			if r.Rate.Limit != 5000 || r.Rate.Remaining != 4321 {
+				t.Errorf("rate = %+v, want 4321/5000", r.Rate)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a poll")
+		}
+	}
+	cancel()
+	<-done
+}
+
+func TestPollerBackPressure(t *testing.T) {
+	defer func(d time.Duration) { backPressureCheck = d }(backPressureCheck)
+	backPressureCheck = 5 * time.Millisecond
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var requests atomic.Int32
+	poller, srv, db, broker := newTestPoller(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		issues := make([]map[string]interface{}, 100)
+		for i := range issues {
+			issues[i] = makeGitHubIssueJSON(i+1, "Issue", "Body", "open", now.Add(time.Duration(i)*time.Second))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(issues)
+	}))
+	defer srv.Close()
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := broker.Subscribe(ctx)
+
+	// A saturated pipeline pauses the poll before it fetches anything
+	for i := 0; i < 60; i++ {
+		broker.Publish(pubsub.Updated, IssueEvent{})
+	}
+	done := make(chan error, 1)
+	go func() { done <- poller.Poll(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("expected no fetch while the pipeline is behind, got %d", n)
+	}
+
+	// Once it catches up, every event is delivered rather than dropped,
+	// even though the page holds more than the broker buffers
+	created := 0
+	for created < 100 {
+		select {
+		case evt := <-events:
+			if evt.Type == pubsub.Created {
+				created++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out after %d of 100 events", created)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+}
+
+func TestPollerBackPressureCancel(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	poller, srv, db, broker := newTestPoller(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issues := make([]map[string]interface{}, 100)
+		for i := range issues {
+			issues[i] = makeGitHubIssueJSON(i+1, "Issue", "Body", "open", now.Add(time.Duration(i)*time.Second))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(issues)
+	}))
+	defer srv.Close()
+	defer db.Close()
+
+	subCtx, subCancel := context.WithCancel(context.Background())
+	defer subCancel()
+	broker.Subscribe(subCtx)
+
+	// Nobody reads, so publishing blocks once the buffer is full
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := poller.Poll(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the poll to end with its context, got %v", err)
+	}
+
+	repo, err := db.GetRepoByOwnerRepo("testowner", "testrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := db.GetPollCursor(repo.ID, store.EndpointIssues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor.Watermark != nil {
+		t.Errorf("expected the watermark not to pass unpublished issues, got %v", cursor.Watermark)
+	}
+	if _, err := db.GetIssue(repo.ID, 100); err == nil {
+		t.Error("expected the unpublished issue not to be stored, so the next poll finds it again")
+	}
+}
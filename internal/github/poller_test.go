@@ -177,6 +177,70 @@ func TestDiffSnapshot(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("assignees and milestone not recorded", func(t *testing.T) {
+		incoming := &Issue{
+			Number:    1,
+			Title:     "Original Title",
+			Body:      "Original body content",
+			State:     "open",
+			Labels:    []string{"bug"},
+			Assignees: []string{"octocat"},
+			Milestone: "v1.0",
+		}
+		changes := DiffSnapshot(stored, incoming, hashBody(incoming.Body))
+		if len(changes) != 0 {
+			t.Errorf("expected no changes against an old snapshot, got %v", changes)
+		}
+	})
+
+	tracked := *stored
+	tracked.Assignees = []string{"octocat", "hubot"}
+	tracked.Milestone = "v1.0"
+
+	t.Run("assignees reordered", func(t *testing.T) {
+		incoming := &Issue{
+			Title:     "Original Title",
+			State:     "open",
+			Body:      "Original body content",
+			Labels:    []string{"bug"},
+			Assignees: []string{"hubot", "octocat"},
+			Milestone: "v1.0",
+		}
+		changes := DiffSnapshot(&tracked, incoming, hashBody(incoming.Body))
+		if len(changes) != 0 {
+			t.Errorf("expected no changes, got %v", changes)
+		}
+	})
+
+	t.Run("assignees changed", func(t *testing.T) {
+		incoming := &Issue{
+			Title:     "Original Title",
+			State:     "open",
+			Body:      "Original body content",
+			Labels:    []string{"bug"},
+			Assignees: []string{"octocat"},
+			Milestone: "v1.0",
+		}
+		changes := DiffSnapshot(&tracked, incoming, hashBody(incoming.Body))
+		if len(changes) != 1 || changes[0] != ChangeAssigneesChanged {
+			t.Errorf("expected [ChangeAssigneesChanged], got %v", changes)
+		}
+	})
+
+	t.Run("milestone cleared", func(t *testing.T) {
+		incoming := &Issue{
+			Title:     "Original Title",
+			State:     "open",
+			Body:      "Original body content",
+			Labels:    []string{"bug"},
+			Assignees: []string{"octocat", "hubot"},
+		}
+		changes := DiffSnapshot(&tracked, incoming, hashBody(incoming.Body))
+		if len(changes) != 1 || changes[0] != ChangeMilestoneChanged {
+			t.Errorf("expected [ChangeMilestoneChanged], got %v", changes)
+		}
+	})
 }
 
 func TestConvertIssue(t *testing.T) {
@@ -400,6 +464,9 @@ func TestChangeTypeString(t *testing.T) {
 		{ChangeStateChanged, "state_changed"},
 		{ChangeLabelsChanged, "labels_changed"},
 		{ChangeOther, "other"},
+		{ChangeRetriage, "retriage"},
+		{ChangeAssigneesChanged, "assignees_changed"},
+		{ChangeMilestoneChanged, "milestone_changed"},
 		{ChangeType(99), "unknown"},
 	}
 
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v60/github"
+
+	"github.com/jacklau/triage/internal/config"
+)
+
+// RemoteConfigCache fetches each repository's .github/triage.yml and caches
+// it, so repo maintainers can tune their labels, prompt, and threshold
+// without access to the daemon's config.
+type RemoteConfigCache struct {
+	client *gogithub.Client
+	ttl    time.Duration
+	packs  map[string][]config.LabelConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]remoteConfigEntry
+	inflight map[string]*remoteConfigFetch
+}
+
+type remoteConfigEntry struct {
+	settings *config.RepoSettings // nil if the repo has no file
+	fetched  time.Time
+}
+
+// remoteConfigFetch is a fetch in progress, which concurrent callers for the
+// same repo wait on instead of fetching again.
+type remoteConfigFetch struct {
+	done     chan struct{}
+	settings *config.RepoSettings
+	err      error
+}
+
+// NewRemoteConfigCache creates a cache that refetches a repo's file once it
+// is older than ttl. packs are the label packs defined in the config, which
+// a file's label_packs may name along with the built-in ones.
+func NewRemoteConfigCache(client *gogithub.Client, ttl time.Duration, packs map[string][]config.LabelConfig) *RemoteConfigCache {
+	return &RemoteConfigCache{
+		client:   client,
+		ttl:      ttl,
+		packs:    packs,
+		now:      time.Now,
+		entries:  make(map[string]remoteConfigEntry),
+		inflight: make(map[string]*remoteConfigFetch),
+	}
+}
+
+// Get returns the settings from the repo's .github/triage.yml, or nil if it
+// has none. If the file cannot be fetched or is invalid, Get returns the last
+// good settings along with the error and waits for the TTL before trying
+// again, so a broken file does not cost an API call per issue.
+//
+// The fetch runs without holding the cache's lock, so a slow repo does not
+// hold up the others; concurrent calls for the same repo share one fetch.
+func (c *RemoteConfigCache) Get(ctx context.Context, fullName string) (*config.RepoSettings, error) {
+	key := strings.ToLower(fullName)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && c.now().Sub(entry.fetched) < c.ttl {
+		c.mu.Unlock()
+		return entry.settings, nil
+	}
+	if f, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.settings, f.err
+		case <-ctx.Done():
+			return entry.settings, ctx.Err()
+		}
+	}
+	f := &remoteConfigFetch{done: make(chan struct{})}
+	c.inflight[key] = f
+	c.mu.Unlock()
+
+	settings, err := c.fetch(ctx, fullName)
+
+	c.mu.Lock()
+	entry = c.entries[key]
+	entry.fetched = c.now()
+	if err == nil {
+		entry.settings = settings
+	}
+	c.entries[key] = entry
+	delete(c.inflight, key)
+	c.mu.Unlock()
+
+	f.settings, f.err = entry.settings, err
+	close(f.done)
+	return f.settings, f.err
+}
+
+func (c *RemoteConfigCache) fetch(ctx context.Context, fullName string) (*config.RepoSettings, error) {
+	owner, repo, ok := strings.Cut(fullName, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid repo name %q", fullName)
+	}
+
+	file, _, resp, err := c.client.Repositories.GetContents(ctx, owner, repo, config.RemoteConfigPath, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s from %s: %w", config.RemoteConfigPath, fullName, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s in %s is a directory", config.RemoteConfigPath, fullName)
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s from %s: %w", config.RemoteConfigPath, fullName, err)
+	}
+	settings, err := config.ParseRepoSettings([]byte(content), c.packs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fullName, err)
+	}
+	return settings, nil
+}
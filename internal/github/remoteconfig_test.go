@@ -0,0 +1,133 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRemoteConfigCache(t *testing.T) {
+	content := "custom_prompt: Mobile app\nlabels:\n  - name: ios\n"
+	requests := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/repos/org/app/contents/.github/triage.yml":
+			fmt.Fprintf(w, `{"type":"file","encoding":"base64","content":%q}`, base64.StdEncoding.EncodeToString([]byte(content)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewRemoteConfigCache(client, 10*time.Minute, nil)
+	cache.now = func() time.Time { return now }
+
+	rs, err := cache.Get(context.Background(), "org/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs == nil || rs.CustomPrompt != "Mobile app" || len(rs.Labels) != 1 {
+		t.Fatalf("unexpected settings: %+v", rs)
+	}
+
+	if _, err := cache.Get(context.Background(), "Org/App"); err != nil || requests != 1 {
+		t.Errorf("expected a cached result, got %d requests, err %v", requests, err)
+	}
+
+	// A broken file keeps the last good settings until the next refresh.
+	now = now.Add(11 * time.Minute)
+	content = "custom_promt: typo\n"
+	rs, err = cache.Get(context.Background(), "org/app")
+	if err == nil || !strings.Contains(err.Error(), "custom_promt") {
+		t.Errorf("expected parse error naming the bad key, got %v", err)
+	}
+	if rs == nil || rs.CustomPrompt != "Mobile app" {
+		t.Errorf("expected last good settings, got %+v", rs)
+	}
+	if _, err := cache.Get(context.Background(), "org/app"); err != nil || requests != 2 {
+		t.Errorf("expected the failure to be cached, got %d requests, err %v", requests, err)
+	}
+
+	// Packs are checked like those of the main config
+	now = now.Add(11 * time.Minute)
+	content = "label_packs: [mobil]\n"
+	rs, err = cache.Get(context.Background(), "org/app")
+	if err == nil || !strings.Contains(err.Error(), `unknown pack "mobil"`) {
+		t.Errorf("expected an unknown pack error, got %v", err)
+	}
+	if rs == nil || rs.CustomPrompt != "Mobile app" {
+		t.Errorf("expected last good settings, got %+v", rs)
+	}
+
+	// A repo without the file has no settings.
+	if rs, err := cache.Get(context.Background(), "org/other"); err != nil || rs != nil {
+		t.Errorf("expected nil settings for a repo without the file, got %+v, %v", rs, err)
+	}
+}
+
+func TestRemoteConfigCacheConcurrentFetch(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/repos/org/slow/contents/.github/triage.yml" {
+			<-release
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	}))
+	cache := NewRemoteConfigCache(client, 10*time.Minute, nil)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Get(context.Background(), "org/slow"); err != nil {
+				t.Errorf("Get: %v", err)
+			}
+		}()
+	}
+
+	// Another repo is not held up by the slow fetch
+	done := make(chan struct{})
+	go func() {
+		cache.Get(context.Background(), "org/fast")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a slow fetch blocked another repo")
+	}
+
+	// A caller that gives up waiting gets its context's error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for {
+		cache.mu.Lock()
+		_, waiting := cache.inflight["org/slow"]
+		cache.mu.Unlock()
+		if waiting {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := cache.Get(ctx, "org/slow"); err != context.Canceled {
+		t.Errorf("expected context.Canceled while waiting, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected one fetch per repo, got %d requests", n)
+	}
+}
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v60/github"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+// DefaultRepoMetadataTTL is how long a repo's stored metadata is used
+// before it is fetched again. Descriptions and topics rarely change.
+const DefaultRepoMetadataTTL = 24 * time.Hour
+
+// RepoMetadataStore is the subset of store.DB that RepoMetadataCache uses.
+type RepoMetadataStore interface {
+	GetRepoMetadata(repoID int64) (*store.RepoMetadata, error)
+	SetRepoMetadata(repoID int64, m *store.RepoMetadata) error
+}
+
+// RepoMetadataCache keeps each repository's description, topics, and
+// language in the store, fetching them from GitHub once they are older than
+// its TTL. They give the classifier context a label list alone does not,
+// such as the repo being a game engine written in Rust.
+type RepoMetadataCache struct {
+	client *gogithub.Client
+	store  RepoMetadataStore
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	failed map[int64]time.Time
+}
+
+// NewRepoMetadataCache creates a cache that refetches a repo's metadata once
+// it is older than ttl. client may be nil, in which case only stored
+// metadata is returned.
+func NewRepoMetadataCache(client *gogithub.Client, st RepoMetadataStore, ttl time.Duration) *RepoMetadataCache {
+	return &RepoMetadataCache{
+		client: client,
+		store:  st,
+		ttl:    ttl,
+		now:    time.Now,
+		failed: make(map[int64]time.Time),
+	}
+}
+
+// Get returns the metadata of the repo with the given ID and full name
+// (owner/repo). If it is stale and cannot be fetched, Get returns the stored
+// metadata along with the error and waits for the TTL before trying again,
+// so an unreachable repo does not cost an API call per issue.
+func (c *RepoMetadataCache) Get(ctx context.Context, repoID int64, fullName string) (*store.RepoMetadata, error) {
+	m, err := c.store.GetRepoMetadata(repoID)
+	if err != nil {
+		return nil, err
+	}
+	if c.client == nil || c.now().Sub(m.FetchedAt) < c.ttl {
+		return m, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if failedAt, ok := c.failed[repoID]; ok && c.now().Sub(failedAt) < c.ttl {
+		return m, nil
+	}
+
+	owner, repo, ok := strings.Cut(fullName, "/")
+	if !ok {
+		return m, fmt.Errorf("invalid repo name %q", fullName)
+	}
+	fetched, err := FetchRepoMetadata(ctx, c.client, owner, repo)
+	if err != nil {
+		c.failed[repoID] = c.now()
+		return m, err
+	}
+	delete(c.failed, repoID)
+	fetched.FetchedAt = c.now()
+	if err := c.store.SetRepoMetadata(repoID, fetched); err != nil {
+		return fetched, err
+	}
+	return fetched, nil
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestRepoMetadataCache(t *testing.T) {
+	requests := 0
+	fail := false
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if fail || r.URL.Path != "/repos/org/engine" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message":"boom"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"description":"A game engine, take %d","topics":["rust"],"language":"Rust"}`, requests)
+	}))
+
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo, _ := db.CreateRepo("org", "engine")
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewRepoMetadataCache(client, db, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	m, err := cache.Get(context.Background(), repo.ID, "org/engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Description != "A game engine, take 1" || m.Language != "Rust" {
+		t.Fatalf("unexpected metadata: %+v", m)
+	}
+	stored, _ := db.GetRepoMetadata(repo.ID)
+	if stored.Description != m.Description || !stored.FetchedAt.Equal(now) {
+		t.Errorf("expected the metadata to be stored, got %+v", stored)
+	}
+
+	// Fresh metadata comes from the store
+	if _, err := cache.Get(context.Background(), repo.ID, "org/engine"); err != nil || requests != 1 {
+		t.Errorf("expected the stored metadata, got %d requests, err %v", requests, err)
+	}
+
+	// Stale metadata that cannot be refetched is still returned, and the
+	// fetch is not retried until the TTL passes again
+	now = now.Add(2 * time.Hour)
+	fail = true
+	m, err = cache.Get(context.Background(), repo.ID, "org/engine")
+	if err == nil {
+		t.Error("expected the fetch error")
+	}
+	if m == nil || m.Description != "A game engine, take 1" {
+		t.Errorf("expected the stored metadata with the error, got %+v", m)
+	}
+	if _, err := cache.Get(context.Background(), repo.ID, "org/engine"); err != nil || requests != 2 {
+		t.Errorf("expected no retry within the TTL, got %d requests, err %v", requests, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	fail = false
+	m, err = cache.Get(context.Background(), repo.ID, "org/engine")
+	if err != nil || m.Description != "A game engine, take 3" {
+		t.Errorf("expected refetched metadata, got %+v, err %v", m, err)
+	}
+}
+
+func TestRepoMetadataCacheWithoutClient(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo, _ := db.CreateRepo("org", "engine")
+	if err := db.SetRepoMetadata(repo.ID, &store.RepoMetadata{Description: "Stored"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := NewRepoMetadataCache(nil, db, time.Nanosecond)
+	m, err := cache.Get(context.Background(), repo.ID, "org/engine")
+	if err != nil || m.Description != "Stored" {
+		t.Errorf("expected the stored metadata, got %+v, err %v", m, err)
+	}
+}
@@ -0,0 +1,70 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	gogithub "github.com/google/go-github/v60/github"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+// ListOwnerRepos returns the full names of the non-archived repositories
+// owned by owner, which may be an organization or a user.
+func ListOwnerRepos(ctx context.Context, client *gogithub.Client, owner string) ([]string, error) {
+	names, err := listRepos(func(page int) ([]*gogithub.Repository, *gogithub.Response, error) {
+		return client.Repositories.ListByOrg(ctx, owner, &gogithub.RepositoryListByOrgOptions{
+			ListOptions: gogithub.ListOptions{PerPage: 100, Page: page},
+		})
+	})
+	var ghErr *gogithub.ErrorResponse
+	if errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusNotFound {
+		// Not an organization; try a user account.
+		names, err = listRepos(func(page int) ([]*gogithub.Repository, *gogithub.Response, error) {
+			return client.Repositories.ListByUser(ctx, owner, &gogithub.RepositoryListByUserOptions{
+				ListOptions: gogithub.ListOptions{PerPage: 100, Page: page},
+			})
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing repos of %s: %w", owner, err)
+	}
+	return names, nil
+}
+
+func listRepos(list func(page int) ([]*gogithub.Repository, *gogithub.Response, error)) ([]string, error) {
+	var names []string
+	page := 1
+	for {
+		repos, resp, err := list(page)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range repos {
+			if !r.GetArchived() {
+				names = append(names, r.GetFullName())
+			}
+		}
+		if resp.NextPage == 0 {
+			return names, nil
+		}
+		page = resp.NextPage
+	}
+}
+
+// FetchRepoMetadata returns a repository's description, topics, primary
+// language, and visibility.
+func FetchRepoMetadata(ctx context.Context, client *gogithub.Client, owner, repo string) (*store.RepoMetadata, error) {
+	r, _, err := client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s/%s: %w", owner, repo, err)
+	}
+	return &store.RepoMetadata{
+		Description: r.GetDescription(),
+		Topics:      r.Topics,
+		Language:    r.GetLanguage(),
+		Private:     r.GetPrivate(),
+	}, nil
+}
@@ -0,0 +1,92 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+func newTestClient(t *testing.T, h http.Handler) *gogithub.Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	client := gogithub.NewClient(nil)
+	base, err := client.BaseURL.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = base
+	return client
+}
+
+func TestListOwnerReposOrg(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/myorg/repos" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"full_name":"myorg/c"}]`)
+			return
+		}
+		w.Header().Set("Link", `<http://example.com/orgs/myorg/repos?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"full_name":"myorg/a"},{"full_name":"myorg/old","archived":true}]`)
+	}))
+
+	names, err := ListOwnerRepos(context.Background(), client, "myorg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"myorg/a", "myorg/c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
+
+func TestListOwnerReposUserFallback(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/octocat/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+			return
+		}
+		fmt.Fprint(w, `[{"full_name":"octocat/hello"}]`)
+	}))
+
+	names, err := ListOwnerRepos(context.Background(), client, "octocat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "octocat/hello" {
+		t.Errorf("names = %v", names)
+	}
+}
+
+func TestFetchRepoMetadata(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/octocat/engine" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"full_name":"octocat/engine","description":"A game engine","topics":["gamedev","rust"],"language":"Rust"}`)
+	}))
+
+	m, err := FetchRepoMetadata(context.Background(), client, "octocat", "engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Description != "A game engine" || m.Language != "Rust" {
+		t.Errorf("unexpected metadata: %+v", m)
+	}
+	if want := []string{"gamedev", "rust"}; !reflect.DeepEqual(m.Topics, want) {
+		t.Errorf("Topics = %v, want %v", m.Topics, want)
+	}
+
+	if _, err := FetchRepoMetadata(context.Background(), client, "octocat", "missing"); err == nil {
+		t.Error("expected an error for a missing repo")
+	}
+}
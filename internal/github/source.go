@@ -0,0 +1,230 @@
+package github
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/pubsub"
+)
+
+// EventSource produces issue events for the pipeline. Run publishes events
+// to the source's broker until ctx is cancelled or the source fails.
+type EventSource interface {
+	Run(ctx context.Context) error
+}
+
+// SourceFactory creates an event source from its config entry, publishing
+// to broker.
+type SourceFactory func(cfg config.SourceConfig, broker *pubsub.Broker[IssueEvent]) (EventSource, error)
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = map[string]SourceFactory{
+		"jsonl": newJSONLSource,
+	}
+)
+
+// RegisterSource makes a source type available to the sources config
+// section, for example one reading events from a message queue. It panics
+// if typ is empty or already registered, so call it from an init function.
+func RegisterSource(typ string, factory SourceFactory) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if typ == "" || factory == nil {
+		panic("github: RegisterSource needs a type and a factory")
+	}
+	if _, dup := sources[typ]; dup {
+		panic("github: RegisterSource called twice for source type " + typ)
+	}
+	sources[typ] = factory
+}
+
+// SourceTypes returns the registered source types in sorted order.
+func SourceTypes() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	types := make([]string, 0, len(sources))
+	for typ := range sources {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewSource creates the event source cfg describes.
+func NewSource(cfg config.SourceConfig, broker *pubsub.Broker[IssueEvent]) (EventSource, error) {
+	sourcesMu.RLock()
+	factory, ok := sources[cfg.Type]
+	sourcesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown source type %q (known: %s)", cfg.Type, strings.Join(SourceTypes(), ", "))
+	}
+	src, err := factory(cfg, broker)
+	if err != nil {
+		return nil, fmt.Errorf("%s source: %w", cfg.Type, err)
+	}
+	return src, nil
+}
+
+// Source returns the poller as an event source polling every interval.
+func (p *Poller) Source(interval time.Duration) EventSource {
+	return pollerSource{poller: p, interval: interval}
+}
+
+type pollerSource struct {
+	poller   *Poller
+	interval time.Duration
+}
+
+func (s pollerSource) Run(ctx context.Context) error {
+	return s.poller.Run(ctx, s.interval)
+}
+
+// EventLine is the JSON form of an IssueEvent read by the jsonl source, one
+// per line.
+type EventLine struct {
+	Repo string `json:"repo"`
+	// Change is a ChangeType name such as "new" or "body_edited"; it
+	// defaults to "new".
+	Change string    `json:"change,omitempty"`
+	Issue  IssueLine `json:"issue"`
+}
+
+// IssueLine is the issue of an EventLine.
+type IssueLine struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body,omitempty"`
+	State     string    `json:"state,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Labels    []string  `json:"labels,omitempty"`
+	Assignees []string  `json:"assignees,omitempty"`
+	Milestone string    `json:"milestone,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Event converts l to an IssueEvent.
+func (l EventLine) Event() (IssueEvent, error) {
+	if _, _, ok := strings.Cut(l.Repo, "/"); !ok {
+		return IssueEvent{}, fmt.Errorf("invalid repo %q: expected owner/repo", l.Repo)
+	}
+	if l.Issue.Number <= 0 {
+		return IssueEvent{}, fmt.Errorf("issue number is required")
+	}
+	change := ChangeNew
+	if l.Change != "" {
+		var ok bool
+		if change, ok = ParseChangeType(l.Change); !ok {
+			return IssueEvent{}, fmt.Errorf("unknown change %q", l.Change)
+		}
+	}
+	state := l.Issue.State
+	if state == "" {
+		state = "open"
+	}
+	return IssueEvent{
+		Repo: l.Repo,
+		Issue: Issue{
+			Number:    l.Issue.Number,
+			Title:     l.Issue.Title,
+			Body:      l.Issue.Body,
+			State:     state,
+			Author:    l.Issue.Author,
+			Labels:    l.Issue.Labels,
+			Assignees: l.Issue.Assignees,
+			Milestone: l.Issue.Milestone,
+			CreatedAt: l.Issue.CreatedAt,
+			UpdatedAt: l.Issue.UpdatedAt,
+		},
+		ChangeType: change,
+	}, nil
+}
+
+// jsonlSource publishes the events in a file of EventLines, then waits for
+// ctx to be cancelled so the pipeline can finish with them. Unlike the
+// poller it waits for a subscriber and for room in its buffer, since the
+// whole file arrives at once.
+type jsonlSource struct {
+	path   string
+	broker *pubsub.Broker[IssueEvent]
+}
+
+func newJSONLSource(cfg config.SourceConfig, broker *pubsub.Broker[IssueEvent]) (EventSource, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	return &jsonlSource{path: cfg.Path, broker: broker}, nil
+}
+
+func (s *jsonlSource) Run(ctx context.Context) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("opening events: %w", err)
+	}
+	defer f.Close()
+
+	if err := waitForSubscriber(ctx, s.broker); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var l EventLine
+		if err := json.Unmarshal([]byte(text), &l); err != nil {
+			return fmt.Errorf("%s:%d: %w", s.path, line, err)
+		}
+		evt, err := l.Event()
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", s.path, line, err)
+		}
+		if err := s.broker.PublishWait(ctx, eventType(evt.ChangeType), evt); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// waitForSubscriber returns once broker has a subscriber, so events
+// published at startup are not lost before the pipeline subscribes.
+func waitForSubscriber(ctx context.Context, broker *pubsub.Broker[IssueEvent]) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for broker.Subscribers() == 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// eventType returns the broker event type the poller uses for change.
+func eventType(change ChangeType) pubsub.EventType {
+	switch change {
+	case ChangeNew:
+		return pubsub.Created
+	case ChangeRemoved:
+		return pubsub.Deleted
+	default:
+		return pubsub.Updated
+	}
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/pubsub"
+)
+
+type stubSource struct{ opts map[string]string }
+
+func (stubSource) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRegisterSource(t *testing.T) {
+	RegisterSource("test-stub", func(cfg config.SourceConfig, _ *pubsub.Broker[IssueEvent]) (EventSource, error) {
+		if cfg.Options["fail"] != "" {
+			return nil, errors.New(cfg.Options["fail"])
+		}
+		return stubSource{opts: cfg.Options}, nil
+	})
+	t.Cleanup(func() {
+		sourcesMu.Lock()
+		delete(sources, "test-stub")
+		sourcesMu.Unlock()
+	})
+
+	broker := pubsub.NewBroker[IssueEvent]()
+	src, err := NewSource(config.SourceConfig{Type: "test-stub", Options: map[string]string{"queue": "issues"}}, broker)
+	if err != nil {
+		t.Fatalf("NewSource() error: %v", err)
+	}
+	if s, ok := src.(stubSource); !ok || s.opts["queue"] != "issues" {
+		t.Errorf("expected the registered source with its options, got %#v", src)
+	}
+
+	_, err = NewSource(config.SourceConfig{Type: "test-stub", Options: map[string]string{"fail": "no queue"}}, broker)
+	if err == nil || err.Error() != "test-stub source: no queue" {
+		t.Errorf("expected the factory's error, got %v", err)
+	}
+	_, err = NewSource(config.SourceConfig{Type: "kafka"}, broker)
+	if err == nil || !strings.Contains(err.Error(), "known: jsonl, test-stub") {
+		t.Errorf("expected an unknown type error listing the known types, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a type twice to panic")
+		}
+	}()
+	RegisterSource("jsonl", newJSONLSource)
+}
+
+func TestJSONLSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	lines := []string{
+		`# recorded events`,
+		`{"repo":"o/r","issue":{"number":1,"title":"Crash on start","body":"It crashes","labels":["bug"]}}`,
+		``,
+		`{"repo":"o/r","change":"body_edited","issue":{"number":2,"title":"Slow","state":"closed"}}`,
+	}
+	// More than a subscriber's buffer, none of which may be dropped
+	for i := 3; i < 100; i++ {
+		lines = append(lines, `{"repo":"o/r","change":"removed","issue":{"number":9}}`)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	broker := pubsub.NewBroker[IssueEvent]()
+	src, err := NewSource(config.SourceConfig{Type: "jsonl", Path: path}, broker)
+	if err != nil {
+		t.Fatalf("NewSource() error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- src.Run(ctx) }()
+
+	// Events published before anyone subscribes are not lost
+	time.Sleep(20 * time.Millisecond)
+	sub := broker.Subscribe(ctx)
+
+	var events []pubsub.Event[IssueEvent]
+	for len(events) < 99 {
+		select {
+		case evt := <-sub:
+			events = append(events, evt)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out after %d events", len(events))
+		}
+	}
+	first, second := events[0], events[1]
+	if first.Type != pubsub.Created || first.Payload.ChangeType != ChangeNew || first.Payload.Repo != "o/r" ||
+		first.Payload.Issue.Title != "Crash on start" || first.Payload.Issue.State != "open" || first.Payload.Issue.Labels[0] != "bug" {
+		t.Errorf("unexpected first event: %+v", first)
+	}
+	if second.Type != pubsub.Updated || second.Payload.ChangeType != ChangeBodyEdited || second.Payload.Issue.State != "closed" {
+		t.Errorf("unexpected second event: %+v", second)
+	}
+	if last := events[98]; last.Type != pubsub.Deleted || last.Payload.ChangeType != ChangeRemoved {
+		t.Errorf("unexpected last event: %+v", last)
+	}
+
+	// The source keeps running until cancelled
+	select {
+	case err := <-done:
+		t.Fatalf("expected the source to wait for cancellation, got %v", err)
+	default:
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestJSONLSourceErrors(t *testing.T) {
+	broker := pubsub.NewBroker[IssueEvent]()
+	if _, err := NewSource(config.SourceConfig{Type: "jsonl"}, broker); err == nil {
+		t.Error("expected an error without a path")
+	}
+
+	tests := []struct {
+		line, want string
+	}{
+		{`not json`, "events.jsonl:1: invalid character"},
+		{`{"repo":"nope","issue":{"number":1}}`, `events.jsonl:1: invalid repo "nope"`},
+		{`{"repo":"o/r","issue":{}}`, "events.jsonl:1: issue number is required"},
+		{`{"repo":"o/r","change":"renamed","issue":{"number":1}}`, `events.jsonl:1: unknown change "renamed"`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		if err := os.WriteFile(path, []byte(tt.line+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		src, err := NewSource(config.SourceConfig{Type: "jsonl", Path: path}, broker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = broker.Subscribe(ctx)
+		err = src.Run(ctx)
+		cancel()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("line %s: expected an error containing %q, got %v", tt.line, tt.want, err)
+		}
+	}
+}
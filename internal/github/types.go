@@ -1,6 +1,13 @@
 package github
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Issue represents a GitHub issue.
 type Issue struct {
@@ -10,6 +17,8 @@ type Issue struct {
 	State     string
 	Author    string
 	Labels    []string
+	Assignees []string
+	Milestone string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -18,12 +27,16 @@ type Issue struct {
 type ChangeType int
 
 const (
-	ChangeNew           ChangeType = iota // Newly opened issue
-	ChangeTitleEdited                     // Title was modified
-	ChangeBodyEdited                      // Body was modified
-	ChangeStateChanged                    // State changed (open/closed)
-	ChangeLabelsChanged                   // Labels were added/removed
-	ChangeOther                           // Other change
+	ChangeNew              ChangeType = iota // Newly opened issue
+	ChangeTitleEdited                        // Title was modified
+	ChangeBodyEdited                         // Body was modified
+	ChangeStateChanged                       // State changed (open/closed)
+	ChangeLabelsChanged                      // Labels were added/removed
+	ChangeOther                              // Other change
+	ChangeRetriage                           // A maintainer asked for re-triage
+	ChangeAssigneesChanged                   // Assignees were added/removed
+	ChangeMilestoneChanged                   // Milestone was set, changed, or cleared
+	ChangeRemoved                            // Deleted, transferred, or otherwise gone from the repo
 )
 
 // String returns a human-readable name for the change type.
@@ -41,11 +54,30 @@ func (c ChangeType) String() string {
 		return "labels_changed"
 	case ChangeOther:
 		return "other"
+	case ChangeRetriage:
+		return "retriage"
+	case ChangeAssigneesChanged:
+		return "assignees_changed"
+	case ChangeMilestoneChanged:
+		return "milestone_changed"
+	case ChangeRemoved:
+		return "removed"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseChangeType returns the change type named name, as returned by
+// ChangeType.String.
+func ParseChangeType(name string) (ChangeType, bool) {
+	for c := ChangeNew; c <= ChangeRemoved; c++ {
+		if c.String() == name {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
 // IssueEvent is emitted when an issue is created or changed.
 type IssueEvent struct {
 	Repo       string
@@ -57,6 +89,11 @@ type IssueEvent struct {
 type DuplicateCandidate struct {
 	Number int
 	Score  float32
+	// Title, State, and CreatedAt describe the candidate, when it is known
+	// to the store.
+	Title     string
+	State     string
+	CreatedAt time.Time
 }
 
 // LabelSuggestion is a label suggestion with a confidence score.
@@ -65,11 +102,91 @@ type LabelSuggestion struct {
 	Confidence float64
 }
 
+// RankLabels returns labels ordered by descending confidence, keeping the
+// order of ties, without those below cutoff.
+func RankLabels(labels []LabelSuggestion, cutoff float64) []LabelSuggestion {
+	var ranked []LabelSuggestion
+	for _, l := range labels {
+		if l.Confidence >= cutoff {
+			ranked = append(ranked, l)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Confidence > ranked[j].Confidence })
+	return ranked
+}
+
+// TransferSuggestion proposes moving an issue to another repo of the same
+// owner that has a closely matching issue.
+type TransferSuggestion struct {
+	Repo   string // owner/repo to transfer to
+	Number int    // the matching issue in Repo
+	Score  float32
+}
+
 // TriageResult is the output of the triage pipeline for a single issue.
 type TriageResult struct {
-	Repo            string
-	IssueNumber     int
-	Duplicates      []DuplicateCandidate
+	Repo        string
+	IssueNumber int
+	// IssueTitle is the issue's title, where known. Notifiers that title
+	// their messages per issue use it.
+	IssueTitle string
+	Duplicates []DuplicateCandidate
+	// SuggestedLabels are ordered by descending confidence. The pipeline
+	// leaves out labels below the configured confidence threshold, so
+	// consumers can act on them as they are.
 	SuggestedLabels []LabelSuggestion
 	Reasoning       string
+	// Transfer is set when the issue looks like it belongs in another repo.
+	Transfer *TransferSuggestion
+	// Variant names the experiment variant the issue was classified with,
+	// if an experiment is running.
+	Variant string
+	// PullRequests are open pull requests that may already address the
+	// issue, best match first.
+	PullRequests []DuplicateCandidate
+	// NeedsHumanTriage is set when the classifier abstained: its confidence
+	// was too low to suggest any labels.
+	NeedsHumanTriage bool
+	// Truncated is set when the issue body was shortened to fit the
+	// model's context before it was classified.
+	Truncated bool
+	// RawResponse is the classifier's raw reply, set only when the
+	// pipeline keeps raw responses for debugging.
+	RawResponse string
+	// TriageID identifies the pipeline run that produced the result. It is
+	// on the run's log lines, triage log entry, and provider requests.
+	TriageID string
+}
+
+// ContentHash returns a hash of what result asks a maintainer to act on: its
+// labels, duplicates, transfer, pull requests, and whether it needs human
+// triage. Reasoning and scores are left out, since they vary between runs
+// that reach the same conclusion.
+func (r TriageResult) ContentHash() string {
+	labels := make([]string, len(r.SuggestedLabels))
+	for i, l := range r.SuggestedLabels {
+		labels[i] = l.Name
+	}
+	sort.Strings(labels)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "repo=%s\nissue=%d\nlabels=%s\n", r.Repo, r.IssueNumber, strings.Join(labels, ","))
+	fmt.Fprintf(&b, "duplicates=%v\npulls=%v", candidateNumbers(r.Duplicates), candidateNumbers(r.PullRequests))
+	if r.Transfer != nil {
+		fmt.Fprintf(&b, "\ntransfer=%s#%d", r.Transfer.Repo, r.Transfer.Number)
+	}
+	fmt.Fprintf(&b, "\nhuman=%t", r.NeedsHumanTriage)
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// candidateNumbers returns the issue numbers of cs in ascending order.
+func candidateNumbers(cs []DuplicateCandidate) []int {
+	nums := make([]int, len(cs))
+	for i, c := range cs {
+		nums[i] = c.Number
+	}
+	sort.Ints(nums)
+	return nums
 }
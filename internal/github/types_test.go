@@ -0,0 +1,76 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTriageResultContentHash(t *testing.T) {
+	base := TriageResult{
+		Repo:            "o/r",
+		IssueNumber:     4,
+		SuggestedLabels: []LabelSuggestion{{Name: "bug", Confidence: 0.9}, {Name: "ui", Confidence: 0.8}},
+		Duplicates:      []DuplicateCandidate{{Number: 2, Score: 0.9}, {Number: 3, Score: 0.88}},
+		Reasoning:       "first run",
+	}
+	hash := base.ContentHash()
+
+	same := base
+	same.Reasoning = "worded differently"
+	same.SuggestedLabels = []LabelSuggestion{{Name: "ui", Confidence: 0.7}, {Name: "bug", Confidence: 0.95}}
+	same.Duplicates = []DuplicateCandidate{{Number: 3, Score: 0.91}, {Number: 2, Score: 0.9}}
+	if same.ContentHash() != hash {
+		t.Error("expected reasoning, scores, and order not to change the hash")
+	}
+
+	changes := map[string]func(r *TriageResult){
+		"label":     func(r *TriageResult) { r.SuggestedLabels = r.SuggestedLabels[:1] },
+		"duplicate": func(r *TriageResult) { r.Duplicates = nil },
+		"transfer":  func(r *TriageResult) { r.Transfer = &TransferSuggestion{Repo: "o/cli", Number: 9} },
+		"pulls":     func(r *TriageResult) { r.PullRequests = []DuplicateCandidate{{Number: 7}} },
+		"human":     func(r *TriageResult) { r.NeedsHumanTriage = true },
+		"issue":     func(r *TriageResult) { r.IssueNumber = 5 },
+	}
+	for name, change := range changes {
+		r := base
+		change(&r)
+		if r.ContentHash() == hash {
+			t.Errorf("expected a %s change to change the hash", name)
+		}
+	}
+}
+
+func TestRankLabels(t *testing.T) {
+	labels := []LabelSuggestion{
+		{Name: "docs", Confidence: 0.6},
+		{Name: "bug", Confidence: 0.8},
+		{Name: "crash", Confidence: 0.95},
+		{Name: "ui", Confidence: 0.8},
+	}
+	got := RankLabels(labels, 0.7)
+	want := []LabelSuggestion{
+		{Name: "crash", Confidence: 0.95},
+		{Name: "bug", Confidence: 0.8},
+		{Name: "ui", Confidence: 0.8},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RankLabels = %+v, want %+v", got, want)
+	}
+	if labels[0].Name != "docs" {
+		t.Error("RankLabels reordered its input")
+	}
+	if got := RankLabels(labels, 0.99); got != nil {
+		t.Errorf("RankLabels above every confidence = %+v, want nil", got)
+	}
+}
+
+func TestParseChangeType(t *testing.T) {
+	for c := ChangeNew; c <= ChangeRemoved; c++ {
+		if got, ok := ParseChangeType(c.String()); !ok || got != c {
+			t.Errorf("ParseChangeType(%q) = %v, %v", c.String(), got, ok)
+		}
+	}
+	if _, ok := ParseChangeType("unknown"); ok {
+		t.Error("expected no change type for an unknown name")
+	}
+}
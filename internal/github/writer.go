@@ -0,0 +1,195 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	gogithub "github.com/google/go-github/v60/github"
+)
+
+// Close reasons accepted by Writer.Close.
+const (
+	CloseCompleted  = "completed"
+	CloseNotPlanned = "not_planned"
+)
+
+// Writer makes changes to GitHub issues. Features that mutate GitHub go
+// through a Writer so they share retry and rate limit handling, and so a dry
+// run can swap in a DryRunWriter.
+type Writer interface {
+	// ApplyLabels adds labels to an issue, keeping its existing labels.
+	ApplyLabels(ctx context.Context, owner, repo string, number int, labels []string) error
+	// Comment posts a comment on an issue.
+	Comment(ctx context.Context, owner, repo string, number int, body string) error
+	// Close closes an issue with a reason, CloseCompleted or CloseNotPlanned.
+	Close(ctx context.Context, owner, repo string, number int, reason string) error
+	// Assign adds assignees to an issue.
+	Assign(ctx context.Context, owner, repo string, number int, assignees []string) error
+}
+
+// ClientWriter is a Writer backed by the GitHub API. Requests that hit a rate
+// limit wait for it to reset and are retried, as are server errors, up to
+// maxRetries times.
+type ClientWriter struct {
+	client *gogithub.Client
+	logger *slog.Logger
+	// sleep waits for d or until ctx is done; replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewWriter creates a Writer that makes changes with client.
+func NewWriter(client *gogithub.Client, logger *slog.Logger) *ClientWriter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ClientWriter{client: client, logger: logger, sleep: sleepCtx}
+}
+
+// ApplyLabels implements Writer.
+func (w *ClientWriter) ApplyLabels(ctx context.Context, owner, repo string, number int, labels []string) error {
+	err := w.do(ctx, func() (*gogithub.Response, error) {
+		_, resp, err := w.client.Issues.AddLabelsToIssue(ctx, owner, repo, number, labels)
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("applying labels to %s/%s#%d: %w", owner, repo, number, err)
+	}
+	return nil
+}
+
+// Comment implements Writer.
+func (w *ClientWriter) Comment(ctx context.Context, owner, repo string, number int, body string) error {
+	comment := &gogithub.IssueComment{Body: gogithub.String(body)}
+	err := w.do(ctx, func() (*gogithub.Response, error) {
+		_, resp, err := w.client.Issues.CreateComment(ctx, owner, repo, number, comment)
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("commenting on %s/%s#%d: %w", owner, repo, number, err)
+	}
+	return nil
+}
+
+// Close implements Writer.
+func (w *ClientWriter) Close(ctx context.Context, owner, repo string, number int, reason string) error {
+	if reason != CloseCompleted && reason != CloseNotPlanned {
+		return fmt.Errorf("invalid close reason %q: must be %s or %s", reason, CloseCompleted, CloseNotPlanned)
+	}
+	req := &gogithub.IssueRequest{State: gogithub.String("closed"), StateReason: gogithub.String(reason)}
+	err := w.do(ctx, func() (*gogithub.Response, error) {
+		_, resp, err := w.client.Issues.Edit(ctx, owner, repo, number, req)
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("closing %s/%s#%d: %w", owner, repo, number, err)
+	}
+	return nil
+}
+
+// Assign implements Writer.
+func (w *ClientWriter) Assign(ctx context.Context, owner, repo string, number int, assignees []string) error {
+	err := w.do(ctx, func() (*gogithub.Response, error) {
+		_, resp, err := w.client.Issues.AddAssignees(ctx, owner, repo, number, assignees)
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("assigning %s/%s#%d: %w", owner, repo, number, err)
+	}
+	return nil
+}
+
+// do runs call, retrying rate limit and server errors. Unlike reads, a plain
+// 403 on a write usually means missing permissions, so only responses GitHub
+// marks as rate limited are waited out.
+func (w *ClientWriter) do(ctx context.Context, call func() (*gogithub.Response, error)) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		var resp *gogithub.Response
+		resp, err = call()
+		if err == nil {
+			return nil
+		}
+		if attempt == maxRetries {
+			break
+		}
+
+		var wait time.Duration
+		switch {
+		case resp != nil && isWriteRateLimited(err, resp.Response):
+			wait, _ = HandleRateLimitError(resp.Response)
+			w.logger.Warn("GitHub rate limit hit, waiting", "wait", wait)
+		case resp != nil && IsServerError(resp.Response):
+			wait = BackoffDuration(attempt)
+			w.logger.Warn("GitHub server error, retrying", "status", resp.StatusCode, "attempt", attempt+1, "wait", wait)
+		default:
+			return err
+		}
+		if err := w.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// isWriteRateLimited reports whether a failed write was rejected by a primary
+// or secondary rate limit rather than, say, missing permissions.
+func isWriteRateLimited(err error, resp *http.Response) bool {
+	var rateErr *gogithub.RateLimitError
+	var abuseErr *gogithub.AbuseRateLimitError
+	if errors.As(err, &rateErr) || errors.As(err, &abuseErr) {
+		return true
+	}
+	return resp != nil && (resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("Retry-After") != ""))
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// DryRunWriter is a Writer that logs each change instead of making it.
+type DryRunWriter struct {
+	logger *slog.Logger
+}
+
+// NewDryRunWriter creates a Writer that only logs what it would do.
+func NewDryRunWriter(logger *slog.Logger) *DryRunWriter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DryRunWriter{logger: logger}
+}
+
+// ApplyLabels implements Writer.
+func (w *DryRunWriter) ApplyLabels(_ context.Context, owner, repo string, number int, labels []string) error {
+	w.logger.Info("dry run: would apply labels", "repo", owner+"/"+repo, "number", number, "labels", labels)
+	return nil
+}
+
+// Comment implements Writer.
+func (w *DryRunWriter) Comment(_ context.Context, owner, repo string, number int, body string) error {
+	w.logger.Info("dry run: would comment", "repo", owner+"/"+repo, "number", number, "body", body)
+	return nil
+}
+
+// Close implements Writer.
+func (w *DryRunWriter) Close(_ context.Context, owner, repo string, number int, reason string) error {
+	w.logger.Info("dry run: would close", "repo", owner+"/"+repo, "number", number, "reason", reason)
+	return nil
+}
+
+// Assign implements Writer.
+func (w *DryRunWriter) Assign(_ context.Context, owner, repo string, number int, assignees []string) error {
+	w.logger.Info("dry run: would assign", "repo", owner+"/"+repo, "number", number, "assignees", assignees)
+	return nil
+}
@@ -0,0 +1,134 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestWriter returns a ClientWriter against h that records waits instead
+// of sleeping.
+func newTestWriter(t *testing.T, h http.Handler) (*ClientWriter, *[]time.Duration) {
+	t.Helper()
+	w := NewWriter(newTestClient(t, h), slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	var waits []time.Duration
+	w.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return w, &waits
+}
+
+func TestClientWriterRequests(t *testing.T) {
+	var got []string
+	w, _ := newTestWriter(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body == nil {
+			// AddLabelsToIssue sends a bare JSON array.
+			got = append(got, r.Method+" "+r.URL.Path)
+		} else {
+			b, _ := json.Marshal(body)
+			got = append(got, r.Method+" "+r.URL.Path+" "+string(b))
+		}
+		if strings.HasSuffix(r.URL.Path, "/labels") {
+			fmt.Fprint(rw, `[]`)
+			return
+		}
+		fmt.Fprint(rw, `{}`)
+	}))
+	ctx := context.Background()
+
+	if err := w.ApplyLabels(ctx, "o", "r", 1, []string{"bug"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Comment(ctx, "o", "r", 1, "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(ctx, "o", "r", 1, CloseNotPlanned); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Assign(ctx, "o", "r", 1, []string{"alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"POST /repos/o/r/issues/1/labels",
+		`POST /repos/o/r/issues/1/comments {"body":"hi"}`,
+		`PATCH /repos/o/r/issues/1 {"state":"closed","state_reason":"not_planned"}`,
+		`POST /repos/o/r/issues/1/assignees {"assignees":["alice"]}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err := w.Close(ctx, "o", "r", 1, "wontfix"); err == nil {
+		t.Error("expected error for invalid close reason")
+	}
+}
+
+func TestClientWriterRetries(t *testing.T) {
+	var calls atomic.Int32
+	w, waits := newTestWriter(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			rw.Header().Set("Retry-After", "7")
+			rw.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			rw.WriteHeader(http.StatusBadGateway)
+		default:
+			fmt.Fprint(rw, `{}`)
+		}
+	}))
+
+	if err := w.Comment(context.Background(), "o", "r", 1, "hi"); err != nil {
+		t.Fatalf("expected retries to succeed, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls, got %d", calls.Load())
+	}
+	if len(*waits) != 2 || (*waits)[0] != 7*time.Second || (*waits)[1] != 2*time.Second {
+		t.Errorf("unexpected waits: %v", *waits)
+	}
+}
+
+func TestClientWriterForbiddenNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	w, _ := newTestWriter(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		rw.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(rw, `{"message":"Resource not accessible by integration"}`)
+	}))
+
+	err := w.ApplyLabels(context.Background(), "o", "r", 1, []string{"bug"})
+	if err == nil || !strings.Contains(err.Error(), "applying labels to o/r#1") {
+		t.Fatalf("expected wrapped error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a permissions error not to be retried, got %d calls", calls.Load())
+	}
+}
+
+func TestDryRunWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var w Writer = NewDryRunWriter(slog.New(slog.NewTextHandler(&buf, nil)))
+	ctx := context.Background()
+
+	_ = w.ApplyLabels(ctx, "o", "r", 1, []string{"bug"})
+	_ = w.Comment(ctx, "o", "r", 1, "hi")
+	_ = w.Close(ctx, "o", "r", 1, CloseCompleted)
+	_ = w.Assign(ctx, "o", "r", 1, []string{"alice"})
+
+	for _, want := range []string{"would apply labels", "would comment", "would close", "would assign"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected log %q in:\n%s", want, buf.String())
+		}
+	}
+}
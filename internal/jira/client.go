@@ -0,0 +1,140 @@
+// Package jira creates Jira tickets for triaged GitHub issues and keeps their
+// status in sync with the issue.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is a minimal Jira REST API (v2) client, authenticated with an
+// account email and API token.
+type Client struct {
+	baseURL  string
+	email    string
+	apiToken string
+	client   *http.Client
+}
+
+// NewClient creates a Client for the Jira site at baseURL, such as
+// https://example.atlassian.net.
+func NewClient(baseURL, email, apiToken string) *Client {
+	return &Client{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		email:    email,
+		apiToken: apiToken,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Ticket is the content of a Jira ticket to create.
+type Ticket struct {
+	Project     string
+	IssueType   string
+	Summary     string
+	Description string
+	Labels      []string
+}
+
+// CreateIssue creates a ticket and returns its key, such as "OPS-123".
+func (c *Client) CreateIssue(ctx context.Context, t Ticket) (string, error) {
+	fields := map[string]any{
+		"project":     map[string]string{"key": t.Project},
+		"issuetype":   map[string]string{"name": t.IssueType},
+		"summary":     t.Summary,
+		"description": t.Description,
+	}
+	if len(t.Labels) > 0 {
+		fields["labels"] = t.Labels
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return "", fmt.Errorf("creating jira issue: %w", err)
+	}
+	if created.Key == "" {
+		return "", fmt.Errorf("creating jira issue: response has no key")
+	}
+	return created.Key, nil
+}
+
+// Transition moves a ticket through the workflow transition with the given
+// name, matched case-insensitively.
+func (c *Client) Transition(ctx context.Context, key, name string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+
+	var available struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &available); err != nil {
+		return fmt.Errorf("listing transitions for %s: %w", key, err)
+	}
+
+	for _, t := range available.Transitions {
+		if strings.EqualFold(t.Name, name) {
+			body := map[string]any{"transition": map[string]string{"id": t.ID}}
+			if err := c.do(ctx, http.MethodPost, path, body, nil); err != nil {
+				return fmt.Errorf("transitioning %s to %q: %w", key, name, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("transitioning %s: no transition named %q is available", key, name)
+}
+
+// do sends a JSON request and decodes the response into out, if non-nil.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.SetBasicAuth(c.email, c.apiToken)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("jira returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
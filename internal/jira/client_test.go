@@ -0,0 +1,95 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateIssue(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/issue" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "bot@example.com" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"id":"10001","key":"OPS-7"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL+"/", "bot@example.com", "secret")
+	key, err := c.CreateIssue(context.Background(), Ticket{
+		Project:     "OPS",
+		IssueType:   "Bug",
+		Summary:     "org/repo#1: Crash",
+		Description: "details",
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue: %v", err)
+	}
+	if key != "OPS-7" {
+		t.Errorf("key = %q, want OPS-7", key)
+	}
+	fields, _ := got["fields"].(map[string]any)
+	if fields["summary"] != "org/repo#1: Crash" || fields["project"].(map[string]any)["key"] != "OPS" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
+
+func TestTransition(t *testing.T) {
+	var posted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/OPS-7/transitions" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"transitions":[{"id":"11","name":"To Do"},{"id":"31","name":"Done"}]}`)
+			return
+		}
+		var body struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		posted = body.Transition.ID
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "bot@example.com", "secret")
+	if err := c.Transition(context.Background(), "OPS-7", "done"); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if posted != "31" {
+		t.Errorf("posted transition %q, want 31", posted)
+	}
+
+	err := c.Transition(context.Background(), "OPS-7", "Closed")
+	if err == nil || !strings.Contains(err.Error(), `no transition named "Closed"`) {
+		t.Errorf("expected missing transition error, got %v", err)
+	}
+}
+
+func TestClientErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errors":{"project":"project is required"}}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "a", "b").CreateIssue(context.Background(), Ticket{})
+	if err == nil || !strings.Contains(err.Error(), "jira returned 400") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/pubsub"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// summaryLimit is the longest summary Jira accepts.
+const summaryLimit = 255
+
+// Store is the storage a Syncer needs.
+type Store interface {
+	GetRepoByOwnerRepo(owner, repo string) (*store.Repo, error)
+	GetIssue(repoID int64, number int) (*store.Issue, error)
+	GetJiraLink(repoID int64, number int) (string, error)
+	LinkJiraIssue(repoID int64, number int, key string) error
+}
+
+// Syncer creates Jira tickets for issues with one of the configured labels
+// and keeps the tickets' status in sync with the issues.
+//
+// As a notify.Notifier it acts on triage results, creating a ticket when a
+// suggested label matches. Run covers labels applied on GitHub and issues
+// being closed or reopened.
+type Syncer struct {
+	client *Client
+	store  Store
+	cfg    config.JiraConfig
+	logger *slog.Logger
+
+	// mu serializes ticket creation so an issue is never linked twice.
+	mu sync.Mutex
+}
+
+// NewSyncer creates a Syncer that makes tickets with client as cfg
+// describes.
+func NewSyncer(client *Client, st Store, cfg config.JiraConfig, logger *slog.Logger) *Syncer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Syncer{client: client, store: st, cfg: cfg, logger: logger}
+}
+
+// Name identifies the Syncer in the triage log's notified_via column.
+func (s *Syncer) Name() string {
+	return "jira"
+}
+
+// Notify creates a ticket for a triaged issue if one of its suggested labels
+// is configured and it has no ticket yet.
+func (s *Syncer) Notify(ctx context.Context, result github.TriageResult) error {
+	matched := false
+	for _, l := range result.SuggestedLabels {
+		if s.cfg.HasLabel(l.Name) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil
+	}
+
+	repoID, err := s.repoID(result.Repo)
+	if err != nil {
+		return err
+	}
+	var issue github.Issue
+	if stored, err := s.store.GetIssue(repoID, result.IssueNumber); err == nil {
+		issue = github.Issue{Number: stored.Number, Title: stored.Title, Body: stored.Body}
+	} else {
+		issue.Number = result.IssueNumber
+	}
+	return s.ensureTicket(ctx, repoID, result.Repo, issue, FormatTriage(result))
+}
+
+// Run syncs tickets from issue events until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context, broker *pubsub.Broker[github.IssueEvent]) {
+	for evt := range broker.Subscribe(ctx) {
+		if err := s.handleEvent(ctx, evt.Payload); err != nil {
+			s.logger.Error("jira sync failed", "repo", evt.Payload.Repo, "issue", evt.Payload.Issue.Number, "error", err)
+		}
+	}
+}
+
+// handleEvent creates a ticket when a configured label is applied on GitHub,
+// and moves a linked ticket when its issue is closed or reopened.
+func (s *Syncer) handleEvent(ctx context.Context, ie github.IssueEvent) error {
+	switch ie.ChangeType {
+	case github.ChangeLabelsChanged:
+		var matched []string
+		for _, l := range ie.Issue.Labels {
+			if s.cfg.HasLabel(l) {
+				matched = append(matched, l)
+			}
+		}
+		if len(matched) == 0 {
+			return nil
+		}
+		repoID, err := s.repoID(ie.Repo)
+		if err != nil {
+			return err
+		}
+		return s.ensureTicket(ctx, repoID, ie.Repo, ie.Issue,
+			fmt.Sprintf("Labeled %s on GitHub.", strings.Join(matched, ", ")))
+
+	case github.ChangeStateChanged:
+		repoID, err := s.repoID(ie.Repo)
+		if err != nil {
+			return err
+		}
+		key, err := s.store.GetJiraLink(repoID, ie.Issue.Number)
+		if err != nil || key == "" {
+			return err
+		}
+		transition := s.cfg.ReopenTransition
+		if ie.Issue.State == "closed" {
+			transition = s.cfg.CloseTransition
+		}
+		if err := s.client.Transition(ctx, key, transition); err != nil {
+			return err
+		}
+		s.logger.Info("jira ticket transitioned", "repo", ie.Repo, "issue", ie.Issue.Number, "key", key, "transition", transition)
+	}
+	return nil
+}
+
+// ensureTicket creates and links a ticket for an issue unless it has one.
+func (s *Syncer) ensureTicket(ctx context.Context, repoID int64, repo string, issue github.Issue, summary string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, err := s.store.GetJiraLink(repoID, issue.Number)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		return nil
+	}
+
+	key, err = s.client.CreateIssue(ctx, Ticket{
+		Project:     s.cfg.Project,
+		IssueType:   s.cfg.IssueType,
+		Summary:     ticketSummary(repo, issue),
+		Description: ticketDescription(repo, issue, summary),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.store.LinkJiraIssue(repoID, issue.Number, key); err != nil {
+		return err
+	}
+	s.logger.Info("jira ticket created", "repo", repo, "issue", issue.Number, "key", key)
+	return nil
+}
+
+func (s *Syncer) repoID(fullName string) (int64, error) {
+	owner, name, ok := strings.Cut(fullName, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid repo format: %s", fullName)
+	}
+	r, err := s.store.GetRepoByOwnerRepo(owner, name)
+	if err != nil {
+		return 0, fmt.Errorf("looking up repo %s: %w", fullName, err)
+	}
+	return r.ID, nil
+}
+
+// FormatTriage summarizes a triage result in Jira wiki markup.
+func FormatTriage(result github.TriageResult) string {
+	var b strings.Builder
+	if len(result.SuggestedLabels) > 0 {
+		labels := make([]string, len(result.SuggestedLabels))
+		for i, l := range result.SuggestedLabels {
+			labels[i] = fmt.Sprintf("%s (%d%%)", l.Name, int(math.Round(l.Confidence*100)))
+		}
+		fmt.Fprintf(&b, "*Suggested labels:* %s\n", strings.Join(labels, ", "))
+	}
+	if len(result.Duplicates) > 0 {
+		dups := make([]string, len(result.Duplicates))
+		for i, d := range result.Duplicates {
+			dups[i] = fmt.Sprintf("#%d (%d%%)", d.Number, int(math.Round(float64(d.Score)*100)))
+		}
+		fmt.Fprintf(&b, "*Possible duplicates:* %s\n", strings.Join(dups, ", "))
+	}
+	if result.Reasoning != "" {
+		fmt.Fprintf(&b, "*Reasoning:* %s\n", result.Reasoning)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func ticketSummary(repo string, issue github.Issue) string {
+	summary := fmt.Sprintf("%s#%d", repo, issue.Number)
+	if issue.Title != "" {
+		summary += ": " + issue.Title
+	}
+	if len(summary) > summaryLimit {
+		summary = strings.ToValidUTF8(summary[:summaryLimit-3], "") + "..."
+	}
+	return summary
+}
+
+func ticketDescription(repo string, issue github.Issue, summary string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "GitHub issue: https://github.com/%s/issues/%d\n", repo, issue.Number)
+	if summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", summary)
+	}
+	if issue.Body != "" {
+		fmt.Fprintf(&b, "\n----\n%s\n", issue.Body)
+	}
+	return b.String()
+}
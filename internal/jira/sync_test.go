@@ -0,0 +1,175 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/config"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// fakeJira records the tickets created and transitions applied.
+type fakeJira struct {
+	mu          sync.Mutex
+	created     []map[string]any
+	transitions []string
+}
+
+func (f *fakeJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.URL.Path == "/rest/api/2/issue":
+		var body struct {
+			Fields map[string]any `json:"fields"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.created = append(f.created, body.Fields)
+		fmt.Fprintf(w, `{"key":"OPS-%d"}`, len(f.created))
+	case strings.HasSuffix(r.URL.Path, "/transitions") && r.Method == http.MethodGet:
+		fmt.Fprint(w, `{"transitions":[{"id":"11","name":"To Do"},{"id":"31","name":"Done"}]}`)
+	case strings.HasSuffix(r.URL.Path, "/transitions"):
+		var body struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.transitions = append(f.transitions, body.Transition.ID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func setupSyncer(t *testing.T) (*Syncer, *fakeJira, *store.DB, int64) {
+	t.Helper()
+	fake := &fakeJira{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo, err := db.CreateRepo("org", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpsertIssue(&store.Issue{
+		RepoID: repo.ID, Number: 5, Title: "Checkout fails", Body: "Stack trace", State: "open",
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.JiraConfig{
+		URL: srv.URL, Project: "OPS", IssueType: "Bug", Labels: []string{"customer-bug"},
+		CloseTransition: "Done", ReopenTransition: "To Do",
+	}
+	return NewSyncer(NewClient(srv.URL, "bot@example.com", "secret"), db, cfg, nil), fake, db, repo.ID
+}
+
+func TestSyncerNotify(t *testing.T) {
+	s, fake, db, repoID := setupSyncer(t)
+	ctx := context.Background()
+
+	// Results without a configured label are ignored.
+	if err := s.Notify(ctx, github.TriageResult{Repo: "org/repo", IssueNumber: 5,
+		SuggestedLabels: []github.LabelSuggestion{{Name: "bug", Confidence: 0.9}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.created) != 0 {
+		t.Fatalf("expected no ticket, got %d", len(fake.created))
+	}
+
+	result := github.TriageResult{
+		Repo:            "org/repo",
+		IssueNumber:     5,
+		SuggestedLabels: []github.LabelSuggestion{{Name: "Customer-Bug", Confidence: 0.88}},
+		Duplicates:      []github.DuplicateCandidate{{Number: 2, Score: 0.91}},
+		Reasoning:       "Reported by a paying customer",
+	}
+	if err := s.Notify(ctx, result); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	// A second result for the same issue does not create another ticket.
+	if err := s.Notify(ctx, result); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if len(fake.created) != 1 {
+		t.Fatalf("expected 1 ticket, got %d", len(fake.created))
+	}
+	fields := fake.created[0]
+	if fields["summary"] != "org/repo#5: Checkout fails" {
+		t.Errorf("summary = %v", fields["summary"])
+	}
+	desc, _ := fields["description"].(string)
+	for _, want := range []string{
+		"https://github.com/org/repo/issues/5",
+		"*Suggested labels:* Customer-Bug (88%)",
+		"*Possible duplicates:* #2 (91%)",
+		"*Reasoning:* Reported by a paying customer",
+		"Stack trace",
+	} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("expected %q in description:\n%s", want, desc)
+		}
+	}
+	if key, _ := db.GetJiraLink(repoID, 5); key != "OPS-1" {
+		t.Errorf("link = %q, want OPS-1", key)
+	}
+}
+
+func TestSyncerHandleEvent(t *testing.T) {
+	s, fake, db, repoID := setupSyncer(t)
+	ctx := context.Background()
+	issue := github.Issue{Number: 5, Title: "Checkout fails", State: "open", Labels: []string{"customer-bug"}}
+
+	// Closing an unlinked issue does nothing.
+	closed := issue
+	closed.State = "closed"
+	if err := s.handleEvent(ctx, github.IssueEvent{Repo: "org/repo", Issue: closed, ChangeType: github.ChangeStateChanged}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.transitions) != 0 {
+		t.Fatalf("expected no transitions, got %v", fake.transitions)
+	}
+
+	if err := s.handleEvent(ctx, github.IssueEvent{Repo: "org/repo", Issue: issue, ChangeType: github.ChangeLabelsChanged}); err != nil {
+		t.Fatalf("labels changed: %v", err)
+	}
+	if key, _ := db.GetJiraLink(repoID, 5); key != "OPS-1" {
+		t.Fatalf("expected labeling to create OPS-1, got %q", key)
+	}
+	if desc := fake.created[0]["description"].(string); !strings.Contains(desc, "Labeled customer-bug on GitHub.") {
+		t.Errorf("unexpected description:\n%s", desc)
+	}
+
+	if err := s.handleEvent(ctx, github.IssueEvent{Repo: "org/repo", Issue: closed, ChangeType: github.ChangeStateChanged}); err != nil {
+		t.Fatalf("closed: %v", err)
+	}
+	if err := s.handleEvent(ctx, github.IssueEvent{Repo: "org/repo", Issue: issue, ChangeType: github.ChangeStateChanged}); err != nil {
+		t.Fatalf("reopened: %v", err)
+	}
+	if strings.Join(fake.transitions, ",") != "31,11" {
+		t.Errorf("transitions = %v, want Done then To Do", fake.transitions)
+	}
+}
+
+func TestTicketSummaryTruncates(t *testing.T) {
+	got := ticketSummary("org/repo", github.Issue{Number: 1, Title: strings.Repeat("é", 200)})
+	if len(got) > summaryLimit || !strings.HasSuffix(got, "...") {
+		t.Errorf("summary not truncated to %d bytes: %d", summaryLimit, len(got))
+	}
+}
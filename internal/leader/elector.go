@@ -0,0 +1,161 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultTTL is how long a lease stays valid without renewal. If the
+	// leader dies, a standby takes over after at most this long.
+	defaultTTL = 30 * time.Second
+
+	// defaultRetryInterval is how often a standby instance retries acquiring
+	// the lease.
+	defaultRetryInterval = 10 * time.Second
+)
+
+// LeaseStore is the subset of the store used for leader election.
+type LeaseStore interface {
+	AcquireLease(name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(name, holder string) error
+}
+
+// Elector campaigns for a named lease and runs a callback only while this
+// instance holds it, so redundant instances can run without double work.
+type Elector struct {
+	store         LeaseStore
+	name          string
+	id            string
+	ttl           time.Duration
+	retryInterval time.Duration
+	logger        *slog.Logger
+	leading       atomic.Bool
+}
+
+// Option configures an Elector.
+type Option func(*Elector)
+
+// WithTTL sets the lease duration. Renewals happen every TTL/3.
+func WithTTL(d time.Duration) Option {
+	return func(e *Elector) { e.ttl = d }
+}
+
+// WithRetryInterval sets how often a standby retries acquiring the lease.
+func WithRetryInterval(d time.Duration) Option {
+	return func(e *Elector) { e.retryInterval = d }
+}
+
+// WithLogger sets the logger.
+func WithLogger(l *slog.Logger) Option {
+	return func(e *Elector) { e.logger = l }
+}
+
+// NewElector creates an Elector for the lease name, identified by id.
+func NewElector(st LeaseStore, name, id string, opts ...Option) *Elector {
+	e := &Elector{
+		store:         st,
+		name:          name,
+		id:            id,
+		ttl:           defaultTTL,
+		retryInterval: defaultRetryInterval,
+		logger:        slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.logger = e.logger.With("lease", name, "instance", id)
+	return e
+}
+
+// DefaultID returns an instance identifier derived from the hostname and PID.
+func DefaultID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// IsLeader reports whether this instance currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// Run campaigns for the lease until ctx is cancelled. Each time the lease is
+// acquired, lead is called with a context that is cancelled if the lease is
+// lost. If lead returns an error while still leading, Run releases the lease
+// and returns that error.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context) error) error {
+	for {
+		acquired, err := e.store.AcquireLease(e.name, e.id, e.ttl)
+		if err != nil {
+			e.logger.Warn("failed to acquire lease", "error", err)
+		}
+
+		if acquired {
+			if err := e.leadTerm(ctx, lead); err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		} else {
+			e.logger.Debug("standing by, lease held by another instance")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// leadTerm runs lead while renewing the lease. It returns nil when the lease
+// is lost (so the caller resumes campaigning) and lead's error otherwise.
+func (e *Elector) leadTerm(ctx context.Context, lead func(ctx context.Context) error) error {
+	e.logger.Info("acquired lease, starting as leader")
+	e.leading.Store(true)
+	defer e.leading.Store(false)
+
+	termCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- lead(termCtx)
+	}()
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			e.release()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		case <-ticker.C:
+			held, err := e.store.AcquireLease(e.name, e.id, e.ttl)
+			if err != nil || !held {
+				e.logger.Warn("lost lease, stepping down", "error", err)
+				cancel()
+				<-done
+				return nil
+			}
+		}
+	}
+}
+
+func (e *Elector) release() {
+	if err := e.store.ReleaseLease(e.name, e.id); err != nil {
+		e.logger.Warn("failed to release lease", "error", err)
+	}
+}
@@ -0,0 +1,153 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memLeaseStore is an in-memory LeaseStore for testing.
+type memLeaseStore struct {
+	mu      sync.Mutex
+	holder  string
+	expires time.Time
+	// steal, when set, makes the next renewal by the current holder fail.
+	steal bool
+}
+
+func (m *memLeaseStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.steal && m.holder == holder {
+		m.holder = "thief"
+		m.expires = time.Now().Add(time.Hour)
+		m.steal = false
+		return false, nil
+	}
+	if m.holder == "" || m.holder == holder || time.Now().After(m.expires) {
+		m.holder = holder
+		m.expires = time.Now().Add(ttl)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *memLeaseStore) ReleaseLease(name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.holder == holder {
+		m.holder = ""
+	}
+	return nil
+}
+
+func TestElectorOnlyOneLeader(t *testing.T) {
+	st := &memLeaseStore{}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	var active, maxActive int32
+	lead := func(ctx context.Context) error {
+		n := atomic.AddInt32(&active, 1)
+		if n > atomic.LoadInt32(&maxActive) {
+			atomic.StoreInt32(&maxActive, n)
+		}
+		<-ctx.Done()
+		atomic.AddInt32(&active, -1)
+		return ctx.Err()
+	}
+
+	a := NewElector(st, "watch", "a", WithTTL(time.Second), WithRetryInterval(10*time.Millisecond))
+	b := NewElector(st, "watch", "b", WithTTL(time.Second), WithRetryInterval(10*time.Millisecond))
+
+	var wg sync.WaitGroup
+	for _, e := range []*Elector{a, b} {
+		wg.Add(1)
+		go func(e *Elector) {
+			defer wg.Done()
+			e.Run(ctx, lead)
+		}(e)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if a.IsLeader() == b.IsLeader() {
+		t.Errorf("expected exactly one leader, a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+
+	wg.Wait()
+	if maxActive != 1 {
+		t.Errorf("expected at most one active leader, saw %d", maxActive)
+	}
+}
+
+func TestElectorFailover(t *testing.T) {
+	st := &memLeaseStore{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	aCtx, aCancel := context.WithCancel(ctx)
+	a := NewElector(st, "watch", "a", WithTTL(time.Second), WithRetryInterval(10*time.Millisecond))
+	b := NewElector(st, "watch", "b", WithTTL(time.Second), WithRetryInterval(10*time.Millisecond))
+
+	bLed := make(chan struct{})
+	go a.Run(aCtx, func(ctx context.Context) error { <-ctx.Done(); return nil })
+	time.Sleep(30 * time.Millisecond)
+	go b.Run(ctx, func(ctx context.Context) error {
+		close(bLed)
+		<-ctx.Done()
+		return nil
+	})
+
+	// Shutting down a releases the lease so b takes over.
+	aCancel()
+	select {
+	case <-bLed:
+	case <-time.After(time.Second):
+		t.Fatal("b never became leader after a stopped")
+	}
+}
+
+func TestElectorStepsDownWhenLeaseLost(t *testing.T) {
+	st := &memLeaseStore{}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	e := NewElector(st, "watch", "a", WithTTL(30*time.Millisecond), WithRetryInterval(time.Hour))
+
+	stepped := make(chan struct{})
+	go e.Run(ctx, func(lctx context.Context) error {
+		st.mu.Lock()
+		st.steal = true
+		st.mu.Unlock()
+		<-lctx.Done()
+		close(stepped)
+		return nil
+	})
+
+	select {
+	case <-stepped:
+	case <-ctx.Done():
+		t.Fatal("leader did not step down after losing the lease")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if e.IsLeader() {
+		t.Error("expected IsLeader false after losing lease")
+	}
+}
+
+func TestElectorPropagatesLeadError(t *testing.T) {
+	st := &memLeaseStore{}
+	e := NewElector(st, "watch", "a", WithTTL(time.Second))
+
+	want := errors.New("boom")
+	err := e.Run(context.Background(), func(context.Context) error { return want })
+	if !errors.Is(err, want) {
+		t.Fatalf("expected lead error, got %v", err)
+	}
+	if st.holder != "" {
+		t.Error("expected lease to be released after lead error")
+	}
+}
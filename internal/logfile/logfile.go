@@ -0,0 +1,116 @@
+// Package logfile writes logs to a file that is rotated by size, so a
+// long-running process keeps a bounded amount of log history.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File is an io.WriteCloser appending to a log file. Once a write would
+// take the file past its size limit, the file is renamed to path.1 (older
+// files moving to path.2, and so on, up to the number of backups kept) and
+// a new one is started. It is safe for concurrent use.
+type File struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// Open opens the log file at path for appending, creating it and its
+// directory if needed. It is rotated once it would exceed maxSize bytes,
+// keeping maxBackups old files; with maxBackups 0 the old file is removed.
+func Open(path string, maxSize int64, maxBackups int) (*File, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("log file size limit must be positive, got %d", maxSize)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	l := &File{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *File) open() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	l.f, l.size = f, info.Size()
+	return nil
+}
+
+// Write appends p to the log file, rotating it first if p would take it
+// past its size limit. A write larger than the limit goes to a new file of
+// its own.
+func (l *File) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return 0, os.ErrClosed
+	}
+	if l.size > 0 && l.size+int64(len(p)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups along, moves the current file to path.1, and
+// starts a new one.
+func (l *File) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+	l.f = nil
+	if l.maxBackups == 0 {
+		if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+		return l.open()
+	}
+	os.Remove(l.backup(l.maxBackups))
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	}
+	if err := os.Rename(l.path, l.backup(1)); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	return l.open()
+}
+
+// backup returns the path of the ith most recent rotated file.
+func (l *File) backup(i int) string {
+	return fmt.Sprintf("%s.%d", l.path, i)
+}
+
+// Close closes the log file.
+func (l *File) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
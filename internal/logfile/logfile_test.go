@@ -0,0 +1,100 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "triage.log")
+	l, err := Open(path, 10, 2)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		if _, err := l.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	if got := readFile(t, path); got != "four\nfive\n" {
+		t.Errorf("current file = %q, want the last lines", got)
+	}
+	if got := readFile(t, path+".1"); got != "three\n" {
+		t.Errorf("backup 1 = %q", got)
+	}
+	if got := readFile(t, path+".2"); got != "one\ntwo\n" {
+		t.Errorf("backup 2 = %q", got)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept, got %v", err)
+	}
+}
+
+func TestFileAppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.log")
+	if err := os.WriteFile(path, []byte("earlier\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Open(path, 12, 1)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	l.Write([]byte("now\n"))
+	if got := readFile(t, path); got != "earlier\nnow\n" {
+		t.Errorf("expected to append, got %q", got)
+	}
+	// The existing size counts toward the limit
+	l.Write([]byte("later\n"))
+	l.Close()
+	if got := readFile(t, path); got != "later\n" {
+		t.Errorf("expected a rotation, got %q", got)
+	}
+	if got := readFile(t, path+".1"); got != "earlier\nnow\n" {
+		t.Errorf("backup = %q", got)
+	}
+}
+
+func TestFileWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.log")
+	l, err := Open(path, 5, 0)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+	l.Write([]byte("first\n"))
+	l.Write([]byte("second\n"))
+	if got := readFile(t, path); got != "second\n" {
+		t.Errorf("current file = %q", got)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("expected no backups, got %v", err)
+	}
+}
+
+func TestFileErrors(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "x.log"), 0, 1); err == nil || !strings.Contains(err.Error(), "positive") {
+		t.Errorf("expected a size limit error, got %v", err)
+	}
+	l, err := Open(filepath.Join(t.TempDir(), "x.log"), 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	if _, err := l.Write([]byte("x")); err == nil {
+		t.Error("expected a write after Close to fail")
+	}
+}
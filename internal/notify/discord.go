@@ -7,34 +7,82 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jacklau/triage/internal/github"
 )
 
-// DiscordNotifier sends triage notifications to a Discord webhook.
+// discordThreadNameLimit is the longest forum post title Discord accepts.
+const discordThreadNameLimit = 100
+
+// DiscordNotifier sends triage notifications to a Discord webhook, or, with
+// WithDiscordForum, creates a forum post per issue.
 type DiscordNotifier struct {
 	webhookURL string
 	client     *http.Client
+
+	// Set by WithDiscordForum.
+	forum   bool
+	threads ThreadStore
+
+	style    Style
+	mentions Mentions
+}
+
+// DiscordOption configures a DiscordNotifier.
+type DiscordOption func(*DiscordNotifier)
+
+// WithDiscordForum treats the webhook as a forum channel's: each triaged
+// issue gets its own post, titled with the issue's title. The post is
+// recorded in threads, if non-nil, and later messages about the issue are
+// posted in it rather than starting a new one.
+func WithDiscordForum(threads ThreadStore) DiscordOption {
+	return func(d *DiscordNotifier) {
+		d.forum = true
+		d.threads = threads
+	}
+}
+
+// WithDiscordStyle sets how embeds mark a result's severity.
+func WithDiscordStyle(style Style) DiscordOption {
+	return func(d *DiscordNotifier) {
+		d.style = style
+	}
+}
+
+// WithDiscordMentions pings the mapped users or roles when their labels are
+// suggested. Discord only pings for mentions in the message content, not
+// the embed, so they are sent there.
+func WithDiscordMentions(m Mentions) DiscordOption {
+	return func(d *DiscordNotifier) {
+		d.mentions = m
+	}
 }
 
 // NewDiscordNotifier creates a DiscordNotifier with the given webhook URL.
-func NewDiscordNotifier(webhookURL string) *DiscordNotifier {
-	return &DiscordNotifier{
+func NewDiscordNotifier(webhookURL string, opts ...DiscordOption) *DiscordNotifier {
+	d := &DiscordNotifier{
 		webhookURL: webhookURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
 // discordEmbed represents a Discord embed object.
 type discordEmbed struct {
-	Title  string         `json:"title"`
-	URL    string         `json:"url"`
-	Color  int            `json:"color"`
-	Fields []discordField `json:"fields"`
-	Footer *discordFooter `json:"footer,omitempty"`
+	Title       string         `json:"title"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
 }
 
 // discordField represents a field in a Discord embed.
@@ -49,22 +97,32 @@ type discordFooter struct {
 	Text string `json:"text"`
 }
 
-// discordPayload is the top-level Discord webhook payload.
+// discordPayload is the top-level Discord webhook payload. ThreadName
+// starts a forum post and is only set when posting to a forum channel.
 type discordPayload struct {
-	Embeds []discordEmbed `json:"embeds"`
+	Content    string         `json:"content,omitempty"`
+	ThreadName string         `json:"thread_name,omitempty"`
+	Embeds     []discordEmbed `json:"embeds"`
 }
 
 // BuildDiscordPayload creates the Discord embed message payload for a triage result.
 func BuildDiscordPayload(result github.TriageResult) discordPayload {
+	return buildDiscordPayload(result, Style{})
+}
+
+// buildDiscordPayload creates the payload for a triage result, styled by
+// style.
+func buildDiscordPayload(result github.TriageResult, style Style) discordPayload {
 	issueURL := fmt.Sprintf("https://github.com/%s/issues/%d",
 		result.Repo, result.IssueNumber)
+	sev := style.Severity(result)
 
-	title := fmt.Sprintf("#%d", result.IssueNumber)
+	title := style.title(sev, fmt.Sprintf("#%d", result.IssueNumber))
 
 	fields := []discordField{
 		{
 			Name:   "Labels",
-			Value:  FormatLabels(result.SuggestedLabels),
+			Value:  FormatSuggestedLabels(result),
 			Inline: true,
 		},
 		{
@@ -74,6 +132,22 @@ func BuildDiscordPayload(result github.TriageResult) discordPayload {
 		},
 	}
 
+	if len(result.PullRequests) > 0 {
+		fields = append(fields, discordField{
+			Name:   "Pull Requests",
+			Value:  FormatPullRequests(result.PullRequests),
+			Inline: false,
+		})
+	}
+
+	if result.Transfer != nil {
+		fields = append(fields, discordField{
+			Name:   "Transfer",
+			Value:  FormatTransfer(result.Transfer),
+			Inline: false,
+		})
+	}
+
 	if result.Reasoning != "" {
 		fields = append(fields, discordField{
 			Name:   "Reasoning",
@@ -82,14 +156,16 @@ func BuildDiscordPayload(result github.TriageResult) discordPayload {
 		})
 	}
 
+	footer := fmt.Sprintf("triage - %s", result.Repo)
+	if result.TriageID != "" {
+		footer += " · " + result.TriageID
+	}
 	embed := discordEmbed{
 		Title:  title,
 		URL:    issueURL,
-		Color:  15158332, // Red for issues
+		Color:  sev.discordColor(),
 		Fields: fields,
-		Footer: &discordFooter{
-			Text: fmt.Sprintf("triage - %s", result.Repo),
-		},
+		Footer: &discordFooter{Text: footer},
 	}
 
 	return discordPayload{
@@ -100,14 +176,130 @@ func BuildDiscordPayload(result github.TriageResult) discordPayload {
 // Notify sends a Discord notification for the given triage result.
 // Callers are expected to wrap this with retry logic if needed.
 func (d *DiscordNotifier) Notify(ctx context.Context, result github.TriageResult) error {
-	payload := BuildDiscordPayload(result)
+	payload := buildDiscordPayload(result, d.style)
+	payload.Content = strings.Join(d.mentions.For(result), " ")
+
+	if !d.forum {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshaling discord payload: %w", err)
+		}
+		return d.post(ctx, body)
+	}
+
+	thread, err := d.thread(result.Repo, result.IssueNumber)
+	if err != nil {
+		return err
+	}
+	if thread != "" {
+		_, err := d.execute(ctx, payload, thread)
+		return err
+	}
+
+	payload.ThreadName = forumPostName(result)
+	channelID, err := d.execute(ctx, payload, "")
+	if err != nil {
+		return err
+	}
+	// Results without an issue number always get a post of their own.
+	if d.threads != nil && result.IssueNumber > 0 {
+		// The post exists; returning an error would make callers create
+		// another. Without the record, the next message starts a new post.
+		_ = d.threads.SaveNotifyThread(result.Repo, result.IssueNumber, "discord", channelID)
+	}
+	return nil
+}
+
+// NotifyFollowUp posts text in the issue's forum post. It does nothing
+// unless posting to a forum channel with a recorded post for the issue.
+func (d *DiscordNotifier) NotifyFollowUp(ctx context.Context, repo string, number int, text string) error {
+	if !d.forum {
+		return nil
+	}
+	thread, err := d.thread(repo, number)
+	if err != nil || thread == "" {
+		return err
+	}
+	_, err = d.execute(ctx, discordPayload{Content: text}, thread)
+	return err
+}
+
+// forumPostName returns the title of the forum post for result: the issue's
+// title, or its reference when the title is unknown.
+func forumPostName(result github.TriageResult) string {
+	name := result.IssueTitle
+	if name == "" && result.IssueNumber > 0 {
+		name = fmt.Sprintf("%s#%d", result.Repo, result.IssueNumber)
+	}
+	if name == "" {
+		name = result.Repo
+	}
+	return truncate(name, discordThreadNameLimit)
+}
+
+// thread returns the recorded forum post for an issue, or "" if there is
+// none.
+func (d *DiscordNotifier) thread(repo string, number int) (string, error) {
+	if d.threads == nil || number <= 0 {
+		return "", nil
+	}
+	id, err := d.threads.NotifyThread(repo, number, "discord")
+	if err != nil {
+		return "", fmt.Errorf("looking up discord thread: %w", err)
+	}
+	return id, nil
+}
+
+// discordMessage is the part of the message Discord returns from a webhook
+// executed with wait=true that we use.
+type discordMessage struct {
+	ChannelID string `json:"channel_id"`
+}
+
+// execute posts payload to the webhook, in the thread threadID if it is
+// set, waits for the message to be created, and returns the ID of the
+// channel it was posted in. For a new forum post that is the post's thread.
+func (d *DiscordNotifier) execute(ctx context.Context, payload discordPayload, threadID string) (string, error) {
+	u, err := url.Parse(d.webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing discord webhook URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("wait", "true")
+	if threadID != "" {
+		q.Set("thread_id", threadID)
+	}
+	u.RawQuery = q.Encode()
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshaling discord payload: %w", err)
+		return "", fmt.Errorf("marshaling discord payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doRateLimited(d.client, req)
+	if err != nil {
+		return "", fmt.Errorf("sending request: %w", err)
 	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
 
-	return d.post(ctx, body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("discord webhook returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var msg discordMessage
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return "", fmt.Errorf("decoding discord response: %w", err)
+	}
+	return msg.ChannelID, nil
 }
 
 func (d *DiscordNotifier) post(ctx context.Context, body []byte) error {
@@ -117,7 +309,7 @@ func (d *DiscordNotifier) post(ctx context.Context, body []byte) error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := d.client.Do(req)
+	resp, err := doRateLimited(d.client, req)
 	if err != nil {
 		return fmt.Errorf("sending request: %w", err)
 	}
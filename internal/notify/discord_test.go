@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -136,6 +137,40 @@ func TestBuildDiscordPayload_NoReasoning(t *testing.T) {
 	}
 }
 
+func TestBuildDiscordPayload_Transfer(t *testing.T) {
+	payload := BuildDiscordPayload(github.TriageResult{
+		Repo:        "myorg/docs",
+		IssueNumber: 10,
+		Transfer:    &github.TransferSuggestion{Repo: "myorg/cli", Number: 12, Score: 0.91},
+	})
+
+	fields := payload.Embeds[0].Fields
+	// Labels + Duplicates + Transfer
+	if len(fields) != 3 || fields[2].Name != "Transfer" {
+		t.Fatalf("expected Transfer field, got %+v", fields)
+	}
+	if !strings.Contains(fields[2].Value, "myorg/cli#12") {
+		t.Errorf("unexpected transfer value %q", fields[2].Value)
+	}
+}
+
+func TestBuildDiscordPayload_PullRequests(t *testing.T) {
+	payload := BuildDiscordPayload(github.TriageResult{
+		Repo:         "myorg/cli",
+		IssueNumber:  10,
+		PullRequests: []github.DuplicateCandidate{{Number: 123, Score: 0.88}},
+	})
+
+	fields := payload.Embeds[0].Fields
+	// Labels + Duplicates + Pull Requests
+	if len(fields) != 3 || fields[2].Name != "Pull Requests" {
+		t.Fatalf("expected Pull Requests field, got %+v", fields)
+	}
+	if !strings.Contains(fields[2].Value, "PR #123 (88%)") {
+		t.Errorf("unexpected pull requests value %q", fields[2].Value)
+	}
+}
+
 func TestDiscordNotifier_Notify_Success(t *testing.T) {
 	var receivedBody []byte
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -364,3 +399,113 @@ func TestDiscordNotifier_Notify_TimesOut(t *testing.T) {
 		t.Errorf("expected timeout-related error, got: %v", err)
 	}
 }
+
+func TestDiscordNotifier_ForumPosts(t *testing.T) {
+	type request struct {
+		query url.Values
+		body  map[string]any
+	}
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		requests = append(requests, request{query: r.URL.Query(), body: body})
+		w.Write([]byte(`{"id":"m1","channel_id":"900"}`))
+	}))
+	defer server.Close()
+
+	threads := memThreads{}
+	n := NewDiscordNotifier(server.URL+"/webhooks/1/abc", WithDiscordForum(threads))
+	result := github.TriageResult{Repo: "owner/repo", IssueNumber: 7, IssueTitle: "App crashes on start"}
+
+	ctx := context.Background()
+	if err := n.Notify(ctx, result); err != nil {
+		t.Fatalf("first notify: %v", err)
+	}
+	if err := n.Notify(ctx, result); err != nil {
+		t.Fatalf("second notify: %v", err)
+	}
+	if err := n.NotifyFollowUp(ctx, "owner/repo", 7, "Approved"); err != nil {
+		t.Fatalf("follow-up: %v", err)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(requests))
+	}
+	first := requests[0]
+	if first.body["thread_name"] != "App crashes on start" {
+		t.Errorf("expected post titled with the issue title, got %v", first.body["thread_name"])
+	}
+	if first.query.Get("wait") != "true" || first.query.Has("thread_id") {
+		t.Errorf("unexpected query for new post: %v", first.query)
+	}
+	for i, req := range requests[1:] {
+		if req.query.Get("thread_id") != "900" {
+			t.Errorf("request %d: expected post in thread 900, got %v", i+1, req.query)
+		}
+		if _, ok := req.body["thread_name"]; ok {
+			t.Errorf("request %d: should not start a new post", i+1)
+		}
+	}
+	if requests[2].body["content"] != "Approved" {
+		t.Errorf("unexpected follow-up content %v", requests[2].body["content"])
+	}
+}
+
+func TestDiscordNotifier_ForumPostName(t *testing.T) {
+	long := strings.Repeat("x", 150)
+	tests := []struct {
+		result github.TriageResult
+		want   string
+	}{
+		{github.TriageResult{Repo: "o/r", IssueNumber: 3, IssueTitle: "Title"}, "Title"},
+		{github.TriageResult{Repo: "o/r", IssueNumber: 3}, "o/r#3"},
+		{github.TriageResult{Repo: "o/r"}, "o/r"},
+		{github.TriageResult{Repo: "o/r", IssueNumber: 3, IssueTitle: long}, long[:discordThreadNameLimit]},
+	}
+	for _, tc := range tests {
+		if got := forumPostName(tc.result); got != tc.want {
+			t.Errorf("forumPostName(%+v) = %q, want %q", tc.result, got, tc.want)
+		}
+	}
+}
+
+func TestDiscordNotifier_FollowUpWithoutForum(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	n := NewDiscordNotifier(server.URL)
+	if err := n.NotifyFollowUp(context.Background(), "owner/repo", 7, "x"); err != nil {
+		t.Fatalf("follow-up: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no posts outside forum mode, got %d", calls)
+	}
+}
+
+func TestDiscordNotifier_MentionsInContent(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := NewDiscordNotifier(server.URL, WithDiscordMentions(Mentions{"security": "<@&42>"}))
+	result := github.TriageResult{
+		Repo:            "o/r",
+		IssueNumber:     5,
+		SuggestedLabels: []github.LabelSuggestion{{Name: "security", Confidence: 0.95}},
+	}
+	if err := n.Notify(context.Background(), result); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if body["content"] != "<@&42>" {
+		t.Errorf("expected role mention in content, got %v", body["content"])
+	}
+}
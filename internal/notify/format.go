@@ -23,8 +23,30 @@ func FormatLabels(labels []github.LabelSuggestion) string {
 	return strings.Join(parts, ", ")
 }
 
-// FormatDuplicates formats duplicate candidates as a readable string.
-// Example: "- #38 — 91% similar\n- #25 — 86% similar"
+// needsHumanTriage is shown in place of labels when the classifier abstained.
+const needsHumanTriage = "Needs human triage"
+
+// truncatedNote follows the labels of an issue classified from a truncated
+// body, as they may miss what was cut.
+const truncatedNote = " (classified from a truncated body)"
+
+// FormatSuggestedLabels formats a result's label suggestions, or says the
+// issue needs human triage if the classifier abstained, noting when the
+// issue body was truncated to fit the model.
+func FormatSuggestedLabels(result github.TriageResult) string {
+	s := FormatLabels(result.SuggestedLabels)
+	if result.NeedsHumanTriage {
+		s = needsHumanTriage
+	}
+	if result.Truncated {
+		s += truncatedNote
+	}
+	return s
+}
+
+// FormatDuplicates formats duplicate candidates as a readable string, with
+// each candidate's title, state, and age when known.
+// Example: "- #38 Crash on start (closed, 3 days ago) — 91% similar\n- #25 — 86% similar"
 func FormatDuplicates(candidates []github.DuplicateCandidate) string {
 	if len(candidates) == 0 {
 		return "None found"
@@ -32,11 +54,49 @@ func FormatDuplicates(candidates []github.DuplicateCandidate) string {
 	parts := make([]string, len(candidates))
 	for i, d := range candidates {
 		pct := int(math.Round(float64(d.Score) * 100))
-		parts[i] = fmt.Sprintf("- #%d — %d%% similar", d.Number, pct)
+		parts[i] = fmt.Sprintf("- #%d%s — %d%% similar", d.Number, describeCandidate(d), pct)
 	}
 	return strings.Join(parts, "\n")
 }
 
+// describeCandidate returns " <title> (<state>, <age>)" for a candidate,
+// leaving out whatever is unknown.
+func describeCandidate(d github.DuplicateCandidate) string {
+	var s string
+	if d.Title != "" {
+		s = " " + d.Title
+	}
+	var details []string
+	if d.State != "" {
+		details = append(details, d.State)
+	}
+	if !d.CreatedAt.IsZero() {
+		details = append(details, TimeAgo(d.CreatedAt))
+	}
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, ", ") + ")"
+	}
+	return s
+}
+
+// FormatTransfer formats a transfer suggestion as a readable string.
+// Example: "Consider transferring to myorg/cli — similar to myorg/cli#12 (91%)"
+func FormatTransfer(t *github.TransferSuggestion) string {
+	pct := int(math.Round(float64(t.Score) * 100))
+	return fmt.Sprintf("Consider transferring to %s — similar to %s#%d (%d%%)", t.Repo, t.Repo, t.Number, pct)
+}
+
+// FormatPullRequests formats pull requests that may address an issue.
+// Example: "Possibly addressed by PR #123 (88%), PR #130 (86%)"
+func FormatPullRequests(prs []github.DuplicateCandidate) string {
+	parts := make([]string, len(prs))
+	for i, pr := range prs {
+		pct := int(math.Round(float64(pr.Score) * 100))
+		parts[i] = fmt.Sprintf("PR #%d (%d%%)", pr.Number, pct)
+	}
+	return "Possibly addressed by " + strings.Join(parts, ", ")
+}
+
 // FormatConfidence returns a human-readable confidence level.
 func FormatConfidence(level string) string {
 	switch strings.ToLower(level) {
@@ -78,6 +78,14 @@ func TestFormatDuplicates(t *testing.T) {
 			},
 			want: "- #38 — 91% similar\n- #25 — 86% similar",
 		},
+		{
+			name: "with stored metadata",
+			candidates: []github.DuplicateCandidate{
+				{Number: 38, Score: 0.91, Title: "Crash on start", State: "closed", CreatedAt: time.Now().Add(-73 * time.Hour)},
+				{Number: 25, Score: 0.86, Title: "Crash on exit"},
+			},
+			want: "- #38 Crash on start (closed, 3 days ago) — 91% similar\n- #25 Crash on exit — 86% similar",
+		},
 	}
 
 	for _, tt := range tests {
@@ -90,6 +98,35 @@ func TestFormatDuplicates(t *testing.T) {
 	}
 }
 
+func TestFormatTransfer(t *testing.T) {
+	got := FormatTransfer(&github.TransferSuggestion{Repo: "myorg/cli", Number: 12, Score: 0.914})
+	want := "Consider transferring to myorg/cli — similar to myorg/cli#12 (91%)"
+	if got != want {
+		t.Errorf("FormatTransfer() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatPullRequests(t *testing.T) {
+	got := FormatPullRequests([]github.DuplicateCandidate{{Number: 123, Score: 0.884}, {Number: 130, Score: 0.86}})
+	want := "Possibly addressed by PR #123 (88%), PR #130 (86%)"
+	if got != want {
+		t.Errorf("FormatPullRequests() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatSuggestedLabels(t *testing.T) {
+	labels := []github.LabelSuggestion{{Name: "bug", Confidence: 0.6}}
+	if got := FormatSuggestedLabels(github.TriageResult{SuggestedLabels: labels}); got != "`bug` (60%)" {
+		t.Errorf("FormatSuggestedLabels() = %q, want the labels", got)
+	}
+	if got := FormatSuggestedLabels(github.TriageResult{NeedsHumanTriage: true}); got != "Needs human triage" {
+		t.Errorf("FormatSuggestedLabels() = %q, want %q", got, "Needs human triage")
+	}
+	if got := FormatSuggestedLabels(github.TriageResult{SuggestedLabels: labels, Truncated: true}); got != "`bug` (60%) (classified from a truncated body)" {
+		t.Errorf("FormatSuggestedLabels() = %q, want the labels with a truncation note", got)
+	}
+}
+
 func TestFormatConfidence(t *testing.T) {
 	tests := []struct {
 		input string
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+// Mentions maps a label name to who should be pinged when it is suggested,
+// written in the notifier's own mention syntax: "<!subteam^S0123>" for a
+// Slack user group, "<@&123>" for a Discord role.
+type Mentions map[string]string
+
+// For returns the mentions for the labels suggested in result, sorted and
+// without repeats, so a message is the same however the map is iterated.
+// Labels match regardless of case.
+func (m Mentions) For(result github.TriageResult) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	var out []string
+	seen := make(map[string]bool)
+	for _, l := range result.SuggestedLabels {
+		for label, mention := range m {
+			if mention == "" || seen[mention] || !strings.EqualFold(label, l.Name) {
+				continue
+			}
+			seen[mention] = true
+			out = append(out, mention)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
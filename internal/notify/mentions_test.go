@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+func TestMentionsFor(t *testing.T) {
+	m := Mentions{
+		"security": "<!subteam^S1>",
+		"Crash":    "<!subteam^S2>",
+		"bug":      "<!subteam^S2>",
+		"BUG":      "<!subteam^S0>",
+		"docs":     "",
+	}
+	result := github.TriageResult{SuggestedLabels: []github.LabelSuggestion{
+		{Name: "crash"}, {Name: "security"}, {Name: "bug"}, {Name: "docs"}, {Name: "ui"},
+	}}
+	want := []string{"<!subteam^S0>", "<!subteam^S1>", "<!subteam^S2>"}
+	for range 10 {
+		if got := m.For(result); !reflect.DeepEqual(got, want) {
+			t.Fatalf("For() = %v, want %v", got, want)
+		}
+	}
+	if got := Mentions(nil).For(result); got != nil {
+		t.Errorf("expected no mentions, got %v", got)
+	}
+}
+
+func TestNotifiersIncludeMentions(t *testing.T) {
+	result := github.TriageResult{
+		Repo:            "o/r",
+		IssueNumber:     5,
+		SuggestedLabels: []github.LabelSuggestion{{Name: "security", Confidence: 0.95}},
+	}
+
+	slack := NewSlackNotifier("", WithSlackMentions(Mentions{"security": "<!subteam^S1>"}))
+	payload := slack.build(result, "New Issue Needs Triage")
+	last := payload.Blocks[len(payload.Blocks)-1]
+	if last.Text == nil || !strings.Contains(last.Text.Text, "<!subteam^S1>") {
+		t.Errorf("expected a mention block, got %+v", last)
+	}
+
+	plain := NewSlackNotifier("").build(result, "New Issue Needs Triage")
+	if len(plain.Blocks) != len(payload.Blocks)-1 {
+		t.Errorf("expected no mention block without mentions")
+	}
+}
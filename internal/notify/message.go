@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Message size limits imposed by the webhook APIs.
+const (
+	slackHeaderLimit        = 150
+	slackSectionLimit       = 3000
+	discordTitleLimit       = 256
+	discordDescriptionLimit = 4096
+)
+
+// MessageNotifier is implemented by notifiers that can post a free-form
+// Markdown message, such as a periodic report.
+type MessageNotifier interface {
+	NotifyMessage(ctx context.Context, title, markdown string) error
+}
+
+// SendMessage posts a message through n, which must implement
+// MessageNotifier.
+func SendMessage(ctx context.Context, n Notifier, title, markdown string) error {
+	m, ok := n.(MessageNotifier)
+	if !ok {
+		return fmt.Errorf("notifier %T does not support messages", n)
+	}
+	return m.NotifyMessage(ctx, title, markdown)
+}
+
+// NotifyMessage posts the message to all configured notifiers, collecting
+// errors as Notify does.
+func (m *MultiNotifier) NotifyMessage(ctx context.Context, title, markdown string) error {
+	var errs []error
+	for _, n := range m.notifiers {
+		if err := SendMessage(ctx, n, title, markdown); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyMessage posts a message as a header followed by mrkdwn sections.
+func (s *SlackNotifier) NotifyMessage(ctx context.Context, title, markdown string) error {
+	return s.send(ctx, BuildSlackMessagePayload(title, markdown))
+}
+
+// BuildSlackMessagePayload creates the Slack payload for a free-form message,
+// splitting long text across sections at line boundaries.
+func BuildSlackMessagePayload(title, markdown string) slackPayload {
+	blocks := []slackBlock{{
+		Type: "header",
+		Text: &slackText{Type: "plain_text", Text: truncate(title, slackHeaderLimit)},
+	}}
+	for _, chunk := range splitLines(SlackMarkdown(markdown), slackSectionLimit) {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: chunk},
+		})
+	}
+	return slackPayload{Blocks: blocks}
+}
+
+// NotifyMessage posts a message as an embed with the text as its description.
+func (d *DiscordNotifier) NotifyMessage(ctx context.Context, title, markdown string) error {
+	payload := BuildDiscordMessagePayload(title, markdown)
+	if d.forum {
+		// Forum channels only accept messages that start a post or reply
+		// in one.
+		payload.ThreadName = truncate(title, discordThreadNameLimit)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling discord payload: %w", err)
+	}
+	return d.post(ctx, body)
+}
+
+// BuildDiscordMessagePayload creates the Discord payload for a free-form
+// message.
+func BuildDiscordMessagePayload(title, markdown string) discordPayload {
+	return discordPayload{Embeds: []discordEmbed{{
+		Title:       truncate(title, discordTitleLimit),
+		Description: truncate(markdown, discordDescriptionLimit),
+		Color:       3447003, // Blue for informational messages
+	}}}
+}
+
+var (
+	mdBold    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	mdHeading = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+)
+
+// SlackMarkdown converts the Markdown used in messages to Slack mrkdwn:
+// **bold** and headings become *bold*.
+func SlackMarkdown(md string) string {
+	md = mdBold.ReplaceAllString(md, "*$1*")
+	return mdHeading.ReplaceAllString(md, "*$1*")
+}
+
+// splitLines splits s into chunks of at most limit bytes, breaking between
+// lines where possible.
+func splitLines(s string, limit int) []string {
+	var chunks []string
+	var cur strings.Builder
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if cur.Len()+len(line) > limit && cur.Len() > 0 {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+		for len(line) > limit {
+			chunks = append(chunks, truncate(line, limit))
+			line = line[len(truncate(line, limit)):]
+		}
+		cur.WriteString(line)
+	}
+	if strings.TrimSpace(cur.String()) != "" {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+// truncate shortens s to at most limit bytes without splitting a rune.
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	s = s[:limit]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
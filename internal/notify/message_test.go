@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+func TestSlackMarkdown(t *testing.T) {
+	got := SlackMarkdown("## Summary\n- **Issues triaged:** 5\n- `bug`: 2")
+	want := "*Summary*\n- *Issues triaged:* 5\n- `bug`: 2"
+	if got != want {
+		t.Errorf("SlackMarkdown() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildSlackMessagePayload(t *testing.T) {
+	long := strings.Repeat("- line of report text\n", 300) // ~6600 bytes
+	payload := BuildSlackMessagePayload("Weekly report", long)
+
+	if payload.Blocks[0].Type != "header" || payload.Blocks[0].Text.Text != "Weekly report" {
+		t.Errorf("unexpected header block: %+v", payload.Blocks[0])
+	}
+	if len(payload.Blocks) != 4 {
+		t.Fatalf("expected header plus 3 sections, got %d blocks", len(payload.Blocks))
+	}
+	var joined string
+	for _, b := range payload.Blocks[1:] {
+		if len(b.Text.Text) > slackSectionLimit {
+			t.Errorf("section exceeds limit: %d bytes", len(b.Text.Text))
+		}
+		if !strings.HasSuffix(b.Text.Text, "\n") {
+			t.Error("expected sections to break between lines")
+		}
+		joined += b.Text.Text
+	}
+	if joined != long {
+		t.Error("expected sections to reassemble the full text")
+	}
+}
+
+func TestBuildDiscordMessagePayload(t *testing.T) {
+	payload := BuildDiscordMessagePayload("Weekly report", "- **Issues triaged:** 5")
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	if !strings.Contains(s, `"description":"- **Issues triaged:** 5"`) {
+		t.Errorf("expected Markdown description, got %s", s)
+	}
+	if strings.Contains(s, `"url"`) || strings.Contains(s, `"fields"`) {
+		t.Errorf("expected url and fields to be omitted, got %s", s)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("héllo", 2); got != "h" {
+		t.Errorf("truncate() split a rune: %q", got)
+	}
+	if got := truncate("abc", 5); got != "abc" {
+		t.Errorf("truncate() = %q, want abc", got)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "Weekly report") {
+			t.Errorf("expected title in body, got %s", body)
+		}
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewMultiNotifier(NewSlackNotifier(server.URL), NewDiscordNotifier(server.URL))
+	if err := SendMessage(context.Background(), n, "Weekly report", "text"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 posts, got %d", calls.Load())
+	}
+
+	if err := SendMessage(context.Background(), plainNotifier{}, "t", "m"); err == nil {
+		t.Error("expected error for a notifier without message support")
+	}
+}
+
+type plainNotifier struct{}
+
+func (plainNotifier) Notify(context.Context, github.TriageResult) error { return nil }
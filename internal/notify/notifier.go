@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/jacklau/triage/internal/github"
 )
@@ -39,30 +40,61 @@ func (m *MultiNotifier) Notify(ctx context.Context, result github.TriageResult)
 	return errors.Join(errs...)
 }
 
+// Name returns a short name for where n sends results, such as "slack", or
+// "slack,discord" for a MultiNotifier. It is recorded in the triage log.
+// Notifiers from other packages can name themselves with a Name method.
+func Name(n Notifier) string {
+	switch n := n.(type) {
+	case interface{ Name() string }:
+		return n.Name()
+	case *SlackNotifier:
+		return "slack"
+	case *DiscordNotifier:
+		return "discord"
+	case *MultiNotifier:
+		names := make([]string, len(n.notifiers))
+		for i, sub := range n.notifiers {
+			names[i] = Name(sub)
+		}
+		return strings.Join(names, ",")
+	default:
+		return "other"
+	}
+}
+
+// Options configures the notifiers NewNotifier creates.
+type Options struct {
+	// Slack options. With WithSlackBot, no Slack webhook URL is needed.
+	Slack   []SlackOption
+	Discord []DiscordOption
+}
+
 // NewNotifier creates a Notifier based on the notifyType.
 // Supported types: "slack", "discord", "both".
-func NewNotifier(notifyType string, slackURL, discordURL string) (Notifier, error) {
+func NewNotifier(notifyType string, slackURL, discordURL string, opts Options) (Notifier, error) {
+	slack := NewSlackNotifier(slackURL, opts.Slack...)
+	hasSlack := slackURL != "" || slack.token != ""
 	switch notifyType {
 	case "slack":
-		if slackURL == "" {
+		if !hasSlack {
 			return nil, fmt.Errorf("slack webhook URL is required for slack notifier")
 		}
-		return NewSlackNotifier(slackURL), nil
+		return slack, nil
 	case "discord":
 		if discordURL == "" {
 			return nil, fmt.Errorf("discord webhook URL is required for discord notifier")
 		}
-		return NewDiscordNotifier(discordURL), nil
+		return NewDiscordNotifier(discordURL, opts.Discord...), nil
 	case "both":
-		if slackURL == "" {
+		if !hasSlack {
 			return nil, fmt.Errorf("slack webhook URL is required for 'both' notifier")
 		}
 		if discordURL == "" {
 			return nil, fmt.Errorf("discord webhook URL is required for 'both' notifier")
 		}
 		return NewMultiNotifier(
-			NewSlackNotifier(slackURL),
-			NewDiscordNotifier(discordURL),
+			slack,
+			NewDiscordNotifier(discordURL, opts.Discord...),
 		), nil
 	default:
 		return nil, fmt.Errorf("unsupported notifier type: %q", notifyType)
@@ -96,7 +96,7 @@ func TestMultiNotifier_ReturnsJoinedErrors(t *testing.T) {
 }
 
 func TestNewNotifier_Slack(t *testing.T) {
-	n, err := NewNotifier("slack", "https://hooks.slack.com/test", "")
+	n, err := NewNotifier("slack", "https://hooks.slack.com/test", "", Options{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -106,7 +106,7 @@ func TestNewNotifier_Slack(t *testing.T) {
 }
 
 func TestNewNotifier_Discord(t *testing.T) {
-	n, err := NewNotifier("discord", "", "https://discord.com/api/webhooks/test")
+	n, err := NewNotifier("discord", "", "https://discord.com/api/webhooks/test", Options{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -116,7 +116,7 @@ func TestNewNotifier_Discord(t *testing.T) {
 }
 
 func TestNewNotifier_Both(t *testing.T) {
-	n, err := NewNotifier("both", "https://hooks.slack.com/test", "https://discord.com/api/webhooks/test")
+	n, err := NewNotifier("both", "https://hooks.slack.com/test", "https://discord.com/api/webhooks/test", Options{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -130,36 +130,58 @@ func TestNewNotifier_Both(t *testing.T) {
 }
 
 func TestNewNotifier_SlackMissingURL(t *testing.T) {
-	_, err := NewNotifier("slack", "", "")
+	_, err := NewNotifier("slack", "", "", Options{})
 	if err == nil {
 		t.Fatal("expected error for missing slack URL")
 	}
 }
 
 func TestNewNotifier_DiscordMissingURL(t *testing.T) {
-	_, err := NewNotifier("discord", "", "")
+	_, err := NewNotifier("discord", "", "", Options{})
 	if err == nil {
 		t.Fatal("expected error for missing discord URL")
 	}
 }
 
 func TestNewNotifier_BothMissingSlack(t *testing.T) {
-	_, err := NewNotifier("both", "", "https://discord.com/api/webhooks/test")
+	_, err := NewNotifier("both", "", "https://discord.com/api/webhooks/test", Options{})
 	if err == nil {
 		t.Fatal("expected error for missing slack URL")
 	}
 }
 
 func TestNewNotifier_BothMissingDiscord(t *testing.T) {
-	_, err := NewNotifier("both", "https://hooks.slack.com/test", "")
+	_, err := NewNotifier("both", "https://hooks.slack.com/test", "", Options{})
 	if err == nil {
 		t.Fatal("expected error for missing discord URL")
 	}
 }
 
 func TestNewNotifier_UnsupportedType(t *testing.T) {
-	_, err := NewNotifier("email", "", "")
+	_, err := NewNotifier("email", "", "", Options{})
 	if err == nil {
 		t.Fatal("expected error for unsupported type")
 	}
 }
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		n    Notifier
+		want string
+	}{
+		{NewSlackNotifier("https://example.com"), "slack"},
+		{NewDiscordNotifier("https://example.com"), "discord"},
+		{NewMultiNotifier(NewSlackNotifier("a"), NewDiscordNotifier("b")), "slack,discord"},
+		{&mockNotifier{}, "other"},
+		{NewMultiNotifier(NewSlackNotifier("a"), &namedNotifier{}), "slack,custom"},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.n); got != tt.want {
+			t.Errorf("Name(%T) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+type namedNotifier struct{ mockNotifier }
+
+func (namedNotifier) Name() string { return "custom" }
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRateLimitWaits is how many 429 responses in a row a notifier waits
+	// out before returning the last one to the caller.
+	maxRateLimitWaits = 5
+	// maxRateLimitWait is the longest Retry-After a notifier waits for.
+	// Anything longer is returned to the caller, whose retry loop or next
+	// cycle picks it up.
+	maxRateLimitWait = 2 * time.Minute
+	// defaultRateLimitWait is used when a 429 has no usable Retry-After.
+	defaultRateLimitWait = time.Second
+)
+
+// doRateLimited sends req with client, waiting out 429 responses for as
+// long as their Retry-After asks. Slack and Discord both rate limit
+// webhooks this way, and bursts such as digests hit the limit routinely, so
+// waiting here keeps the notification from failing over to the caller's
+// retry loop. req must have a replayable body (GetBody), as requests built
+// from a bytes.Reader do.
+func doRateLimited(client *http.Client, req *http.Request) (*http.Response, error) {
+	for waits := 0; ; waits++ {
+		resp, err := client.Do(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+		wait := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if waits >= maxRateLimitWaits || wait > maxRateLimitWait || req.GetBody == nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+}
+
+// retryAfter parses a Retry-After header, given in seconds (Discord may
+// send fractions) or as an HTTP date, into a wait from now.
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return defaultRateLimitWait
+	}
+	if secs, err := strconv.ParseFloat(header, 64); err == nil && !math.IsNaN(secs) {
+		switch {
+		case secs < 0:
+			return 0
+		case secs >= float64(math.MaxInt64)/float64(time.Second):
+			return math.MaxInt64
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return defaultRateLimitWait
+}
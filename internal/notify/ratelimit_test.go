@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", defaultRateLimitWait},
+		{"3", 3 * time.Second},
+		{"0.25", 250 * time.Millisecond},
+		{"-1", 0},
+		{"soon", defaultRateLimitWait},
+		{"NaN", defaultRateLimitWait},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+	for _, tc := range tests {
+		if got := retryAfter(tc.header, now); got != tc.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestSlackNotifier_WaitsOutRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewSlackNotifier(server.URL)
+	if err := n.Notify(context.Background(), github.TriageResult{Repo: "o/r", IssueNumber: 1}); err != nil {
+		t.Fatalf("expected success after rate limit, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestDiscordNotifier_RateLimitTooLong(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	n := NewDiscordNotifier(server.URL)
+	err := n.Notify(context.Background(), github.TriageResult{Repo: "o/r", IssueNumber: 1})
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("expected 429 error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected no retry for a long Retry-After, got %d calls", calls.Load())
+	}
+}
+
+func TestDoRateLimited_GivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+	resp, err := doRateLimited(http.DefaultClient, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the last 429 back, got %d", resp.StatusCode)
+	}
+	if got := calls.Load(); got != maxRateLimitWaits+1 {
+		t.Errorf("expected %d calls, got %d", maxRateLimitWaits+1, got)
+	}
+}
+
+func TestDoRateLimited_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader("{}"))
+	if _, err := doRateLimited(http.DefaultClient, req); err == nil {
+		t.Fatal("expected context error")
+	}
+}
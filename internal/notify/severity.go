@@ -0,0 +1,100 @@
+package notify
+
+import "github.com/jacklau/triage/internal/github"
+
+// Severity ranks how much attention a triage result needs from a
+// maintainer. Notifiers color messages by it.
+type Severity int
+
+const (
+	// SeverityLow is a confidently labeled issue with nothing else to act
+	// on.
+	SeverityLow Severity = iota
+	// SeverityMedium is a result worth checking: no confident label, or a
+	// suggestion to transfer the issue.
+	SeverityMedium
+	// SeverityHigh is a likely duplicate, or an issue the classifier could
+	// not label.
+	SeverityHigh
+)
+
+// String returns "low", "medium", or "high".
+func (s Severity) String() string {
+	switch s {
+	case SeverityHigh:
+		return "high"
+	case SeverityMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// slackColor returns the attachment color for s: red, yellow, or green.
+func (s Severity) slackColor() string {
+	switch s {
+	case SeverityHigh:
+		return "#e74c3c"
+	case SeverityMedium:
+		return "#f1c40f"
+	default:
+		return "#2ecc71"
+	}
+}
+
+// discordColor returns the embed color for s: red, yellow, or green.
+func (s Severity) discordColor() int {
+	switch s {
+	case SeverityHigh:
+		return 0xe74c3c
+	case SeverityMedium:
+		return 0xf1c40f
+	default:
+		return 0x2ecc71
+	}
+}
+
+// defaultSuggestedConfidence is the label confidence below which a result
+// is SeverityMedium when the Style does not set one.
+const defaultSuggestedConfidence = 0.9
+
+// Style controls how notifications mark a result's severity.
+type Style struct {
+	// Emoji maps a severity to a prefix for the message title. Severities
+	// without one get no prefix.
+	Emoji map[Severity]string
+	// SuggestedConfidence is the lowest label confidence that counts as
+	// confident. Zero means 0.9, the default "suggested" cutoff.
+	SuggestedConfidence float64
+}
+
+// Severity returns the severity of result. Results without an issue number
+// are SeverityLow.
+func (st Style) Severity(result github.TriageResult) Severity {
+	if result.IssueNumber == 0 {
+		return SeverityLow
+	}
+	if result.NeedsHumanTriage || len(result.Duplicates) > 0 {
+		return SeverityHigh
+	}
+	cutoff := st.SuggestedConfidence
+	if cutoff == 0 {
+		cutoff = defaultSuggestedConfidence
+	}
+	var top float64
+	for _, l := range result.SuggestedLabels {
+		top = max(top, l.Confidence)
+	}
+	if result.Transfer != nil || top < cutoff {
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+// title prefixes title with the emoji configured for sev, if any.
+func (st Style) title(sev Severity, title string) string {
+	if e := st.Emoji[sev]; e != "" {
+		return e + " " + title
+	}
+	return title
+}
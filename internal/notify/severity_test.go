@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+func TestStyleSeverity(t *testing.T) {
+	labels := func(conf float64) []github.LabelSuggestion {
+		return []github.LabelSuggestion{{Name: "bug", Confidence: conf}}
+	}
+	tests := []struct {
+		name   string
+		style  Style
+		result github.TriageResult
+		want   Severity
+	}{
+		{"confident labels", Style{}, github.TriageResult{IssueNumber: 1, SuggestedLabels: labels(0.95)}, SeverityLow},
+		{"uncertain labels", Style{}, github.TriageResult{IssueNumber: 1, SuggestedLabels: labels(0.8)}, SeverityMedium},
+		{"custom cutoff", Style{SuggestedConfidence: 0.75}, github.TriageResult{IssueNumber: 1, SuggestedLabels: labels(0.8)}, SeverityLow},
+		{"no labels", Style{}, github.TriageResult{IssueNumber: 1}, SeverityMedium},
+		{"transfer", Style{}, github.TriageResult{IssueNumber: 1, SuggestedLabels: labels(0.95), Transfer: &github.TransferSuggestion{Repo: "o/cli"}}, SeverityMedium},
+		{"duplicate", Style{}, github.TriageResult{IssueNumber: 1, SuggestedLabels: labels(0.95), Duplicates: []github.DuplicateCandidate{{Number: 2}}}, SeverityHigh},
+		{"abstained", Style{}, github.TriageResult{IssueNumber: 1, NeedsHumanTriage: true}, SeverityHigh},
+		{"no issue number", Style{}, github.TriageResult{Reasoning: "Scan complete"}, SeverityLow},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.style.Severity(tc.result); got != tc.want {
+				t.Errorf("Severity() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStyleEmojiPrefix(t *testing.T) {
+	style := Style{Emoji: map[Severity]string{SeverityHigh: "🔴"}}
+	result := github.TriageResult{Repo: "o/r", IssueNumber: 3, NeedsHumanTriage: true}
+
+	slack := buildSlackPayload(result, style, "New Issue Needs Triage")
+	if got := slack.Blocks[0].Text.Text; got != "🔴 New Issue Needs Triage" {
+		t.Errorf("unexpected slack header %q", got)
+	}
+	if len(slack.Attachments) != 1 || slack.Attachments[0].Color != "#e74c3c" {
+		t.Errorf("expected a red attachment, got %+v", slack.Attachments)
+	}
+
+	discord := buildDiscordPayload(result, style)
+	if got := discord.Embeds[0].Title; got != "🔴 #3" {
+		t.Errorf("unexpected discord title %q", got)
+	}
+	if got := discord.Embeds[0].Color; got != 0xe74c3c {
+		t.Errorf("expected red embed, got %#x", got)
+	}
+
+	// Severities without an emoji get no prefix.
+	result = github.TriageResult{Repo: "o/r", IssueNumber: 3, SuggestedLabels: []github.LabelSuggestion{{Name: "bug", Confidence: 0.99}}}
+	discord = buildDiscordPayload(result, style)
+	if got := discord.Embeds[0].Title; got != "#3" {
+		t.Errorf("unexpected discord title %q", got)
+	}
+	if got := discord.Embeds[0].Color; got != 0x2ecc71 {
+		t.Errorf("expected green embed, got %#x", got)
+	}
+}
+
+func TestSlackPayloadSeverityContext(t *testing.T) {
+	payload := BuildSlackPayload(github.TriageResult{Repo: "o/r", IssueNumber: 3})
+	data, err := json.Marshal(payload.Attachments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"color":"#f1c40f","blocks":[{"type":"context","elements":[{"type":"mrkdwn","text":"Severity: *medium*"}]}]}]`
+	if string(data) != want {
+		t.Errorf("unexpected attachments:\n got %s\nwant %s", data, want)
+	}
+}
+
+func TestPayloadTriageIDFooter(t *testing.T) {
+	result := github.TriageResult{Repo: "o/r", IssueNumber: 3, TriageID: "5f2c9a0e1b7d4c36"}
+
+	slack := BuildSlackPayload(result)
+	if got := slack.Attachments[0].Blocks[0].Elements[0].Text; got != "Severity: *medium* · Triage ID: `5f2c9a0e1b7d4c36`" {
+		t.Errorf("unexpected slack context %q", got)
+	}
+
+	discord := BuildDiscordPayload(result)
+	if got := discord.Embeds[0].Footer.Text; got != "triage - o/r · 5f2c9a0e1b7d4c36" {
+		t.Errorf("unexpected discord footer %q", got)
+	}
+}
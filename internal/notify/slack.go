@@ -7,31 +7,83 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/jacklau/triage/internal/github"
 )
 
-// SlackNotifier sends triage notifications to a Slack webhook.
+// slackAPIURL is the base URL of the Slack Web API.
+const slackAPIURL = "https://slack.com/api"
+
+// SlackNotifier sends triage notifications to a Slack webhook, or, with
+// WithSlackBot, posts them as a bot so later posts about an issue are
+// threaded under its first message.
 type SlackNotifier struct {
 	webhookURL string
 	client     *http.Client
+
+	// Set by WithSlackBot.
+	token   string
+	channel string
+	threads ThreadStore
+	apiURL  string
+
+	style    Style
+	mentions Mentions
+}
+
+// SlackOption configures a SlackNotifier.
+type SlackOption func(*SlackNotifier)
+
+// WithSlackBot posts through the Slack Web API with a bot token instead of
+// the webhook. The first message about an issue is recorded in threads, if
+// non-nil, and later ones (re-triage after edits, maintainers' decisions)
+// are posted as replies to it.
+func WithSlackBot(token, channel string, threads ThreadStore) SlackOption {
+	return func(s *SlackNotifier) {
+		s.token = token
+		s.channel = channel
+		s.threads = threads
+	}
+}
+
+// WithSlackStyle sets how messages mark a result's severity.
+func WithSlackStyle(style Style) SlackOption {
+	return func(s *SlackNotifier) {
+		s.style = style
+	}
+}
+
+// WithSlackMentions pings the mapped users or groups when their labels are
+// suggested.
+func WithSlackMentions(m Mentions) SlackOption {
+	return func(s *SlackNotifier) {
+		s.mentions = m
+	}
 }
 
 // NewSlackNotifier creates a SlackNotifier with the given webhook URL.
-func NewSlackNotifier(webhookURL string) *SlackNotifier {
-	return &SlackNotifier{
+func NewSlackNotifier(webhookURL string, opts ...SlackOption) *SlackNotifier {
+	s := &SlackNotifier{
 		webhookURL: webhookURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		apiURL: slackAPIURL,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// slackBlock represents a Slack Block Kit block.
+// slackBlock represents a Slack Block Kit block. Elements is used by
+// context blocks.
 type slackBlock struct {
-	Type string     `json:"type"`
-	Text *slackText `json:"text,omitempty"`
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
 }
 
 // slackText represents a text object in Slack Block Kit.
@@ -40,22 +92,41 @@ type slackText struct {
 	Text string `json:"text"`
 }
 
-// slackPayload is the top-level Slack message payload.
-type slackPayload struct {
+// slackAttachment is a Slack attachment, used for the colored bar that
+// marks a result's severity.
+type slackAttachment struct {
+	Color  string       `json:"color"`
 	Blocks []slackBlock `json:"blocks"`
 }
 
+// slackPayload is the top-level Slack message payload. Channel, ThreadTS,
+// and Text are only used by the Web API.
+type slackPayload struct {
+	Channel     string            `json:"channel,omitempty"`
+	ThreadTS    string            `json:"thread_ts,omitempty"`
+	Text        string            `json:"text,omitempty"`
+	Blocks      []slackBlock      `json:"blocks"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
 // BuildSlackPayload creates the Slack Block Kit message payload for a triage result.
 func BuildSlackPayload(result github.TriageResult) slackPayload {
+	return buildSlackPayload(result, Style{}, "New Issue Needs Triage")
+}
+
+// buildSlackPayload creates the payload for a triage result with the given
+// header, styled by style.
+func buildSlackPayload(result github.TriageResult, style Style, header string) slackPayload {
 	issueLink := fmt.Sprintf("*<https://github.com/%s/issues/%d|#%d>*",
 		result.Repo, result.IssueNumber, result.IssueNumber)
+	sev := style.Severity(result)
 
 	blocks := []slackBlock{
 		{
 			Type: "header",
 			Text: &slackText{
 				Type: "plain_text",
-				Text: "New Issue Needs Triage",
+				Text: style.title(sev, header),
 			},
 		},
 		{
@@ -69,7 +140,7 @@ func BuildSlackPayload(result github.TriageResult) slackPayload {
 			Type: "section",
 			Text: &slackText{
 				Type: "mrkdwn",
-				Text: fmt.Sprintf("*Suggested Labels:* %s", FormatLabels(result.SuggestedLabels)),
+				Text: fmt.Sprintf("*Suggested Labels:* %s", FormatSuggestedLabels(result)),
 			},
 		},
 	}
@@ -84,6 +155,26 @@ func BuildSlackPayload(result github.TriageResult) slackPayload {
 		})
 	}
 
+	if len(result.PullRequests) > 0 {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf(":hammer_and_wrench: %s", FormatPullRequests(result.PullRequests)),
+			},
+		})
+	}
+
+	if result.Transfer != nil {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf(":arrow_right: %s", FormatTransfer(result.Transfer)),
+			},
+		})
+	}
+
 	if result.Reasoning != "" {
 		blocks = append(blocks, slackBlock{
 			Type: "section",
@@ -94,22 +185,171 @@ func BuildSlackPayload(result github.TriageResult) slackPayload {
 		})
 	}
 
-	return slackPayload{Blocks: blocks}
+	footer := fmt.Sprintf("Severity: *%s*", sev)
+	if result.TriageID != "" {
+		footer += fmt.Sprintf(" · Triage ID: `%s`", result.TriageID)
+	}
+	return slackPayload{
+		Blocks: blocks,
+		Attachments: []slackAttachment{{
+			Color: sev.slackColor(),
+			Blocks: []slackBlock{{
+				Type: "context",
+				Elements: []slackText{{
+					Type: "mrkdwn",
+					Text: footer,
+				}},
+			}},
+		}},
+	}
 }
 
 // Notify sends a Slack notification for the given triage result.
 // Callers are expected to wrap this with retry logic if needed.
 func (s *SlackNotifier) Notify(ctx context.Context, result github.TriageResult) error {
-	payload := BuildSlackPayload(result)
+	payload := s.build(result, "New Issue Needs Triage")
+
+	if s.token == "" {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshaling slack payload: %w", err)
+		}
+		return s.post(ctx, body)
+	}
+
+	thread, err := s.thread(result.Repo, result.IssueNumber)
+	if err != nil {
+		return err
+	}
+	if thread != "" {
+		payload = s.build(result, "Issue Re-triaged")
+	}
+	payload.Text = fmt.Sprintf("%s#%d needs triage", result.Repo, result.IssueNumber)
 
+	ts, err := s.postMessage(ctx, payload, thread)
+	if err != nil {
+		return err
+	}
+	if thread == "" && s.threads != nil {
+		// The message is posted; returning an error would make callers
+		// post it again. Without the record, the next post starts a new
+		// thread.
+		_ = s.threads.SaveNotifyThread(result.Repo, result.IssueNumber, "slack", ts)
+	}
+	return nil
+}
+
+// build creates the payload for result with the given header, mentioning
+// whoever is mapped to its suggested labels.
+func (s *SlackNotifier) build(result github.TriageResult, header string) slackPayload {
+	payload := buildSlackPayload(result, s.style, header)
+	if mentions := s.mentions.For(result); len(mentions) > 0 {
+		payload.Blocks = append(payload.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: "*cc:* " + strings.Join(mentions, " "),
+			},
+		})
+	}
+	return payload
+}
+
+// NotifyFollowUp replies to the issue's triage message with text. It does
+// nothing unless posting as a bot with a recorded thread for the issue.
+func (s *SlackNotifier) NotifyFollowUp(ctx context.Context, repo string, number int, text string) error {
+	if s.token == "" {
+		return nil
+	}
+	thread, err := s.thread(repo, number)
+	if err != nil || thread == "" {
+		return err
+	}
+	payload := slackPayload{
+		Text: text,
+		Blocks: []slackBlock{{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: text},
+		}},
+	}
+	_, err = s.postMessage(ctx, payload, thread)
+	return err
+}
+
+// thread returns the recorded thread for an issue, or "" if there is none.
+func (s *SlackNotifier) thread(repo string, number int) (string, error) {
+	if s.threads == nil {
+		return "", nil
+	}
+	ts, err := s.threads.NotifyThread(repo, number, "slack")
+	if err != nil {
+		return "", fmt.Errorf("looking up slack thread: %w", err)
+	}
+	return ts, nil
+}
+
+// send posts payload through the Web API when posting as a bot, else
+// through the webhook.
+func (s *SlackNotifier) send(ctx context.Context, payload slackPayload) error {
+	if s.token != "" {
+		_, err := s.postMessage(ctx, payload, "")
+		return err
+	}
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshaling slack payload: %w", err)
 	}
-
 	return s.post(ctx, body)
 }
 
+// slackAPIResponse is the envelope of a Slack Web API response.
+type slackAPIResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+// postMessage posts payload with chat.postMessage, as a reply to threadTS
+// if it is set, and returns the new message's timestamp.
+func (s *SlackNotifier) postMessage(ctx context.Context, payload slackPayload, threadTS string) (string, error) {
+	payload.Channel = s.channel
+	payload.ThreadTS = threadTS
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshaling slack payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiURL+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := doRateLimited(s.client, req)
+	if err != nil {
+		return "", fmt.Errorf("sending request: %w", err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("slack API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var out slackAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("decoding slack API response: %w", err)
+	}
+	if !out.OK {
+		return "", fmt.Errorf("slack API error: %s", out.Error)
+	}
+	return out.TS, nil
+}
+
 func (s *SlackNotifier) post(ctx context.Context, body []byte) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
 	if err != nil {
@@ -117,7 +357,7 @@ func (s *SlackNotifier) post(ctx context.Context, body []byte) error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.client.Do(req)
+	resp, err := doRateLimited(s.client, req)
 	if err != nil {
 		return fmt.Errorf("sending request: %w", err)
 	}
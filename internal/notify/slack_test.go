@@ -3,6 +3,7 @@ package notify
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -130,6 +131,38 @@ func TestBuildSlackPayload_NoDuplicates(t *testing.T) {
 	}
 }
 
+func TestBuildSlackPayload_Transfer(t *testing.T) {
+	payload := BuildSlackPayload(github.TriageResult{
+		Repo:        "myorg/docs",
+		IssueNumber: 10,
+		Transfer:    &github.TransferSuggestion{Repo: "myorg/cli", Number: 12, Score: 0.91},
+	})
+
+	// header + issue + labels + transfer
+	if len(payload.Blocks) != 4 {
+		t.Fatalf("expected 4 blocks, got %d", len(payload.Blocks))
+	}
+	if text := payload.Blocks[3].Text.Text; !strings.Contains(text, "Consider transferring to myorg/cli") {
+		t.Errorf("expected transfer block, got %q", text)
+	}
+}
+
+func TestBuildSlackPayload_PullRequests(t *testing.T) {
+	payload := BuildSlackPayload(github.TriageResult{
+		Repo:         "myorg/cli",
+		IssueNumber:  10,
+		PullRequests: []github.DuplicateCandidate{{Number: 123, Score: 0.88}},
+	})
+
+	// header + issue + labels + pull requests
+	if len(payload.Blocks) != 4 {
+		t.Fatalf("expected 4 blocks, got %d", len(payload.Blocks))
+	}
+	if text := payload.Blocks[3].Text.Text; !strings.Contains(text, "Possibly addressed by PR #123") {
+		t.Errorf("expected pull request block, got %q", text)
+	}
+}
+
 func TestSlackNotifier_Notify_Success(t *testing.T) {
 	var receivedBody []byte
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -347,3 +380,119 @@ func TestSlackNotifier_Notify_TimesOut(t *testing.T) {
 		t.Errorf("expected timeout-related error, got: %v", err)
 	}
 }
+
+// memThreads is an in-memory ThreadStore.
+type memThreads map[string]string
+
+func (m memThreads) key(repo string, number int, notifier string) string {
+	return fmt.Sprintf("%s#%d/%s", repo, number, notifier)
+}
+
+func (m memThreads) NotifyThread(repo string, number int, notifier string) (string, error) {
+	return m[m.key(repo, number, notifier)], nil
+}
+
+func (m memThreads) SaveNotifyThread(repo string, number int, notifier, threadID string) error {
+	m[m.key(repo, number, notifier)] = threadID
+	return nil
+}
+
+func TestSlackNotifier_BotThreadsFollowUps(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer xoxb-test" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		requests = append(requests, body)
+		fmt.Fprintf(w, `{"ok":true,"ts":"1700000000.%06d"}`, len(requests))
+	}))
+	defer server.Close()
+
+	threads := memThreads{}
+	n := NewSlackNotifier("", WithSlackBot("xoxb-test", "C123", threads))
+	n.apiURL = server.URL
+
+	result := github.TriageResult{Repo: "owner/repo", IssueNumber: 7}
+	ctx := context.Background()
+	if err := n.Notify(ctx, result); err != nil {
+		t.Fatalf("first notify: %v", err)
+	}
+	if err := n.Notify(ctx, result); err != nil {
+		t.Fatalf("second notify: %v", err)
+	}
+	if err := n.NotifyFollowUp(ctx, "owner/repo", 7, "Approved"); err != nil {
+		t.Fatalf("follow-up: %v", err)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(requests))
+	}
+	if requests[0]["channel"] != "C123" {
+		t.Errorf("expected channel C123, got %v", requests[0]["channel"])
+	}
+	if _, ok := requests[0]["thread_ts"]; ok {
+		t.Error("first message should not be a reply")
+	}
+	for i, req := range requests[1:] {
+		if req["thread_ts"] != "1700000000.000001" {
+			t.Errorf("request %d: expected reply to first message, got thread_ts %v", i+1, req["thread_ts"])
+		}
+	}
+	header := requests[1]["blocks"].([]any)[0].(map[string]any)["text"].(map[string]any)["text"]
+	if header != "Issue Re-triaged" {
+		t.Errorf("unexpected re-triage header %v", header)
+	}
+	if requests[2]["text"] != "Approved" {
+		t.Errorf("unexpected follow-up text %v", requests[2]["text"])
+	}
+}
+
+func TestSlackNotifier_FollowUpWithoutThread(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"ok":true,"ts":"1"}`))
+	}))
+	defer server.Close()
+
+	webhook := NewSlackNotifier(server.URL)
+	if err := webhook.NotifyFollowUp(context.Background(), "owner/repo", 7, "x"); err != nil {
+		t.Fatalf("webhook follow-up: %v", err)
+	}
+
+	bot := NewSlackNotifier("", WithSlackBot("xoxb-test", "C123", memThreads{}))
+	bot.apiURL = server.URL
+	if err := bot.NotifyFollowUp(context.Background(), "owner/repo", 7, "x"); err != nil {
+		t.Fatalf("bot follow-up: %v", err)
+	}
+
+	if calls.Load() != 0 {
+		t.Errorf("expected no posts without a thread, got %d", calls.Load())
+	}
+}
+
+func TestSlackNotifier_BotAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+	}))
+	defer server.Close()
+
+	threads := memThreads{}
+	n := NewSlackNotifier("", WithSlackBot("xoxb-test", "C404", threads))
+	n.apiURL = server.URL
+
+	err := n.Notify(context.Background(), github.TriageResult{Repo: "owner/repo", IssueNumber: 7})
+	if err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Fatalf("expected channel_not_found error, got %v", err)
+	}
+	if len(threads) != 0 {
+		t.Error("no thread should be recorded for a failed post")
+	}
+}
@@ -0,0 +1,244 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+// Limits on what a scan summary lists.
+const (
+	maxSummaryClusters = 5
+	maxSummaryLabels   = 10
+	discordFieldLimit  = 1024
+)
+
+// ScanSummary is the outcome of a scan, sent as one notification when the
+// scan finishes.
+type ScanSummary struct {
+	Repo       string
+	Scanned    int
+	Duplicates int // issues with potential duplicates
+	Classified int // issues with suggested labels
+
+	Clusters []SummaryCluster // largest first, at most maxSummaryClusters
+	Labels   []SummaryLabel   // most suggested first, at most maxSummaryLabels
+}
+
+// SummaryCluster is a group of issues whose best duplicate match is the
+// same issue.
+type SummaryCluster struct {
+	Target int   // the issue the others duplicate
+	Issues []int // duplicates of Target, ascending
+}
+
+// SummaryLabel is the number of issues a label was suggested for.
+type SummaryLabel struct {
+	Name  string
+	Count int
+}
+
+// NewScanSummary summarizes the results of scanning repo. scanned is the
+// number of issues processed, which may exceed len(results) if some failed.
+func NewScanSummary(repo string, scanned int, results []github.TriageResult) ScanSummary {
+	s := ScanSummary{Repo: repo, Scanned: scanned}
+
+	clusters := make(map[int][]int)
+	labels := make(map[string]int)
+	for _, r := range results {
+		if len(r.Duplicates) > 0 {
+			s.Duplicates++
+			target := r.Duplicates[0].Number
+			clusters[target] = append(clusters[target], r.IssueNumber)
+		}
+		if len(r.SuggestedLabels) > 0 {
+			s.Classified++
+		}
+		for _, l := range r.SuggestedLabels {
+			labels[l.Name]++
+		}
+	}
+
+	for target, issues := range clusters {
+		sort.Ints(issues)
+		s.Clusters = append(s.Clusters, SummaryCluster{Target: target, Issues: issues})
+	}
+	sort.Slice(s.Clusters, func(i, j int) bool {
+		a, b := s.Clusters[i], s.Clusters[j]
+		if len(a.Issues) != len(b.Issues) {
+			return len(a.Issues) > len(b.Issues)
+		}
+		return a.Target < b.Target
+	})
+	if len(s.Clusters) > maxSummaryClusters {
+		s.Clusters = s.Clusters[:maxSummaryClusters]
+	}
+
+	for name, n := range labels {
+		s.Labels = append(s.Labels, SummaryLabel{Name: name, Count: n})
+	}
+	sort.Slice(s.Labels, func(i, j int) bool {
+		if s.Labels[i].Count != s.Labels[j].Count {
+			return s.Labels[i].Count > s.Labels[j].Count
+		}
+		return s.Labels[i].Name < s.Labels[j].Name
+	})
+	if len(s.Labels) > maxSummaryLabels {
+		s.Labels = s.Labels[:maxSummaryLabels]
+	}
+	return s
+}
+
+// Title returns the summary heading, e.g. "Scan complete: org/repo".
+func (s ScanSummary) Title() string {
+	return "Scan complete: " + s.Repo
+}
+
+// Text returns a one-line description of the counts.
+func (s ScanSummary) Text() string {
+	return fmt.Sprintf("%d issues scanned, %d potential duplicates, %d classified", s.Scanned, s.Duplicates, s.Classified)
+}
+
+// formatClusters renders each cluster on its own line, with issue numbers
+// formatted by link.
+func (s ScanSummary) formatClusters(link func(number int) string) string {
+	lines := make([]string, len(s.Clusters))
+	for i, c := range s.Clusters {
+		refs := make([]string, len(c.Issues))
+		for j, n := range c.Issues {
+			refs[j] = link(n)
+		}
+		lines[i] = fmt.Sprintf("- %s ← %s", link(c.Target), strings.Join(refs, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatLabels renders the label distribution, e.g. "`bug` 12, `docs` 3".
+func (s ScanSummary) formatLabels() string {
+	parts := make([]string, len(s.Labels))
+	for i, l := range s.Labels {
+		parts[i] = fmt.Sprintf("`%s` %d", l.Name, l.Count)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// issueURL returns the GitHub URL of an issue in repo.
+func issueURL(repo string, number int) string {
+	return fmt.Sprintf("https://github.com/%s/issues/%d", repo, number)
+}
+
+// SummaryNotifier is implemented by notifiers with a dedicated layout for
+// scan summaries.
+type SummaryNotifier interface {
+	NotifySummary(ctx context.Context, summary ScanSummary) error
+}
+
+// SendSummary posts a scan summary through n. Notifiers without a summary
+// layout get the counts as the reasoning of a result with no issue number.
+func SendSummary(ctx context.Context, n Notifier, summary ScanSummary) error {
+	if sn, ok := n.(SummaryNotifier); ok {
+		return sn.NotifySummary(ctx, summary)
+	}
+	return n.Notify(ctx, github.TriageResult{
+		Repo:      summary.Repo,
+		Reasoning: summary.Title() + ": " + summary.Text(),
+	})
+}
+
+// NotifySummary posts the summary to all configured notifiers, collecting
+// errors as Notify does.
+func (m *MultiNotifier) NotifySummary(ctx context.Context, summary ScanSummary) error {
+	var errs []error
+	for _, n := range m.notifiers {
+		if err := SendSummary(ctx, n, summary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifySummary posts the summary with linked duplicate clusters and the
+// label distribution.
+func (s *SlackNotifier) NotifySummary(ctx context.Context, summary ScanSummary) error {
+	return s.send(ctx, BuildSlackSummaryPayload(summary))
+}
+
+// BuildSlackSummaryPayload creates the Slack payload for a scan summary.
+func BuildSlackSummaryPayload(summary ScanSummary) slackPayload {
+	blocks := []slackBlock{
+		{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: truncate(summary.Title(), slackHeaderLimit)},
+		},
+		{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf(":mag: *<https://github.com/%s/issues|%s>*: %s", summary.Repo, summary.Repo, summary.Text()),
+			},
+		},
+	}
+	if len(summary.Clusters) > 0 {
+		clusters := summary.formatClusters(func(n int) string {
+			return fmt.Sprintf("<%s|#%d>", issueURL(summary.Repo, n), n)
+		})
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: truncate("*Top Duplicate Clusters:*\n"+clusters, slackSectionLimit),
+			},
+		})
+	}
+	if len(summary.Labels) > 0 {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: "*Labels:* " + summary.formatLabels()},
+		})
+	}
+	return slackPayload{Text: summary.Title(), Blocks: blocks}
+}
+
+// NotifySummary posts the summary as an embed with linked duplicate
+// clusters and the label distribution.
+func (d *DiscordNotifier) NotifySummary(ctx context.Context, summary ScanSummary) error {
+	payload := BuildDiscordSummaryPayload(summary)
+	if d.forum {
+		payload.ThreadName = truncate(summary.Title(), discordThreadNameLimit)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling discord payload: %w", err)
+	}
+	return d.post(ctx, body)
+}
+
+// BuildDiscordSummaryPayload creates the Discord payload for a scan summary.
+func BuildDiscordSummaryPayload(summary ScanSummary) discordPayload {
+	fields := []discordField{
+		{Name: "Scanned", Value: fmt.Sprint(summary.Scanned), Inline: true},
+		{Name: "Potential Duplicates", Value: fmt.Sprint(summary.Duplicates), Inline: true},
+		{Name: "Classified", Value: fmt.Sprint(summary.Classified), Inline: true},
+	}
+	if len(summary.Clusters) > 0 {
+		clusters := summary.formatClusters(func(n int) string {
+			return fmt.Sprintf("[#%d](%s)", n, issueURL(summary.Repo, n))
+		})
+		fields = append(fields, discordField{Name: "Top Duplicate Clusters", Value: truncate(clusters, discordFieldLimit)})
+	}
+	if len(summary.Labels) > 0 {
+		fields = append(fields, discordField{Name: "Labels", Value: truncate(summary.formatLabels(), discordFieldLimit)})
+	}
+	return discordPayload{Embeds: []discordEmbed{{
+		Title:  truncate(summary.Title(), discordTitleLimit),
+		URL:    fmt.Sprintf("https://github.com/%s/issues", summary.Repo),
+		Color:  3447003, // Blue for informational messages
+		Fields: fields,
+		Footer: &discordFooter{Text: fmt.Sprintf("triage - %s", summary.Repo)},
+	}}}
+}
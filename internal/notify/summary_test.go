@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+)
+
+func summaryResults() []github.TriageResult {
+	dup := func(n int) []github.DuplicateCandidate {
+		return []github.DuplicateCandidate{{Number: n, Score: 0.9}}
+	}
+	label := func(names ...string) []github.LabelSuggestion {
+		var out []github.LabelSuggestion
+		for _, n := range names {
+			out = append(out, github.LabelSuggestion{Name: n, Confidence: 0.9})
+		}
+		return out
+	}
+	return []github.TriageResult{
+		{IssueNumber: 40, Duplicates: dup(12)},
+		{IssueNumber: 31, Duplicates: dup(12), SuggestedLabels: label("bug")},
+		{IssueNumber: 50, Duplicates: dup(7)},
+		{IssueNumber: 51, SuggestedLabels: label("bug", "ui")},
+		{IssueNumber: 52, SuggestedLabels: label("docs")},
+		{IssueNumber: 53},
+	}
+}
+
+func TestNewScanSummary(t *testing.T) {
+	s := NewScanSummary("o/r", 8, summaryResults())
+
+	if s.Scanned != 8 || s.Duplicates != 3 || s.Classified != 3 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	wantClusters := []SummaryCluster{{Target: 12, Issues: []int{31, 40}}, {Target: 7, Issues: []int{50}}}
+	if !reflect.DeepEqual(s.Clusters, wantClusters) {
+		t.Errorf("Clusters = %+v, want %+v", s.Clusters, wantClusters)
+	}
+	wantLabels := []SummaryLabel{{"bug", 2}, {"docs", 1}, {"ui", 1}}
+	if !reflect.DeepEqual(s.Labels, wantLabels) {
+		t.Errorf("Labels = %+v, want %+v", s.Labels, wantLabels)
+	}
+}
+
+func TestBuildSlackSummaryPayload(t *testing.T) {
+	payload := BuildSlackSummaryPayload(NewScanSummary("o/r", 8, summaryResults()))
+	var texts []string
+	for _, b := range payload.Blocks {
+		texts = append(texts, b.Text.Text)
+	}
+	text := strings.Join(texts, "\n")
+	for _, want := range []string{
+		"Scan complete: o/r",
+		"8 issues scanned, 3 potential duplicates, 3 classified",
+		"<https://github.com/o/r/issues/12|#12> ← <https://github.com/o/r/issues/31|#31>, <https://github.com/o/r/issues/40|#40>",
+		"*Labels:* `bug` 2, `docs` 1, `ui` 1",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("payload missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuildDiscordSummaryPayload(t *testing.T) {
+	payload := BuildDiscordSummaryPayload(NewScanSummary("o/r", 8, summaryResults()))
+	embed := payload.Embeds[0]
+	if embed.Title != "Scan complete: o/r" {
+		t.Errorf("unexpected title %q", embed.Title)
+	}
+	var clusters string
+	for _, f := range embed.Fields {
+		if f.Name == "Top Duplicate Clusters" {
+			clusters = f.Value
+		}
+	}
+	if !strings.Contains(clusters, "- [#7](https://github.com/o/r/issues/7) ← [#50](https://github.com/o/r/issues/50)") {
+		t.Errorf("unexpected clusters field %q", clusters)
+	}
+}
+
+func TestSendSummaryFallsBackToNotify(t *testing.T) {
+	n := &recordingSummaryFallback{}
+	if err := SendSummary(context.Background(), n, NewScanSummary("o/r", 2, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.sent) != 1 || n.sent[0].IssueNumber != 0 || !strings.Contains(n.sent[0].Reasoning, "2 issues scanned") {
+		t.Errorf("unexpected fallback result %+v", n.sent)
+	}
+}
+
+type recordingSummaryFallback struct {
+	sent []github.TriageResult
+}
+
+func (r *recordingSummaryFallback) Notify(ctx context.Context, result github.TriageResult) error {
+	r.sent = append(r.sent, result)
+	return nil
+}
+
+func TestMultiNotifierSummary(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		data, _ := json.Marshal(body)
+		bodies = append(bodies, string(data))
+	}))
+	defer server.Close()
+
+	m := NewMultiNotifier(NewSlackNotifier(server.URL), NewDiscordNotifier(server.URL))
+	if err := SendSummary(context.Background(), m, NewScanSummary("o/r", 1, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || !strings.Contains(bodies[0], `"blocks"`) || !strings.Contains(bodies[1], `"embeds"`) {
+		t.Errorf("expected a Slack and a Discord summary, got %v", bodies)
+	}
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// ThreadStore records, per issue and notifier, the message that later posts
+// about the issue are threaded under. repo is owner/repo.
+type ThreadStore interface {
+	NotifyThread(repo string, number int, notifier string) (string, error)
+	SaveNotifyThread(repo string, number int, notifier, threadID string) error
+}
+
+// FollowUpNotifier is implemented by notifiers that can post a short update
+// about an issue they were notified of, such as a maintainer's decision.
+type FollowUpNotifier interface {
+	NotifyFollowUp(ctx context.Context, repo string, number int, text string) error
+}
+
+// SendFollowUp posts text about an issue through n if it supports
+// follow-ups, and does nothing otherwise.
+func SendFollowUp(ctx context.Context, n Notifier, repo string, number int, text string) error {
+	f, ok := n.(FollowUpNotifier)
+	if !ok {
+		return nil
+	}
+	return f.NotifyFollowUp(ctx, repo, number, text)
+}
+
+// NotifyFollowUp posts the follow-up to all configured notifiers, collecting
+// errors as Notify does.
+func (m *MultiNotifier) NotifyFollowUp(ctx context.Context, repo string, number int, text string) error {
+	var errs []error
+	for _, n := range m.notifiers {
+		if err := SendFollowUp(ctx, n, repo, number, text); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
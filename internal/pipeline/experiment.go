@@ -0,0 +1,38 @@
+package pipeline
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/jacklau/triage/internal/classify"
+)
+
+// ControlVariant tags results classified as usual while an experiment runs.
+const ControlVariant = "control"
+
+// Experiment classifies a share of issues with an alternate prompt,
+// classifier, or both. An issue's variant follows from a hash of its repo
+// and number, so re-triaging it keeps the variant.
+type Experiment struct {
+	// Name tags results of the alternate variant.
+	Name string
+	// Percent is the share of issues, from 0 to 100, in the alternate
+	// variant.
+	Percent float64
+	// Prompt, if set, replaces the repo's custom prompt.
+	Prompt string
+	// Classifier, if set, replaces the pipeline's classifier.
+	Classifier *classify.Classifier
+	// Provider names the provider behind Classifier in stage timings.
+	Provider string
+}
+
+// variant returns the variant issue number of repo is classified with.
+func (e *Experiment) variant(repo string, number int) string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s#%d", repo, number)
+	if float64(h.Sum32()%10000) < e.Percent*100 {
+		return e.Name
+	}
+	return ControlVariant
+}
@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/classify"
+	"github.com/jacklau/triage/internal/github"
+)
+
+func TestExperimentVariant(t *testing.T) {
+	e := &Experiment{Name: "b", Percent: 30}
+	counts := map[string]int{}
+	for n := 1; n <= 1000; n++ {
+		v := e.variant("owner/repo", n)
+		if again := e.variant("owner/repo", n); again != v {
+			t.Fatalf("issue #%d: variant changed from %q to %q", n, v, again)
+		}
+		counts[v]++
+	}
+	if counts["b"] < 250 || counts["b"] > 350 || counts["b"]+counts[ControlVariant] != 1000 {
+		t.Errorf("expected about 30%% in the alternate variant, got %v", counts)
+	}
+
+	if v := (&Experiment{Name: "b", Percent: 100}).variant("owner/repo", 1); v != "b" {
+		t.Errorf("expected every issue in the variant at 100%%, got %q", v)
+	}
+}
+
+func TestPipelineExperiment(t *testing.T) {
+	p, mockSt, _, _, completer, _ := setupTestPipeline(t)
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+	p.deps.Dedup = nil // every issue is classified
+	alt := &mockCompleter{response: `{"labels": ["feature"], "confidence": 0.9, "reasoning": "A request"}`}
+	p.deps.Experiment = &Experiment{
+		Name:       "short-prompt",
+		Percent:    50,
+		Prompt:     "Prefer feature over bug.",
+		Classifier: classify.NewClassifier(alt, 10*time.Second),
+	}
+
+	ctx := context.Background()
+	for n := 1; n <= 20; n++ {
+		result, err := p.ProcessSingleIssue(ctx, "owner/repo", github.Issue{Number: n, Title: "Crash on start", State: "open"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := p.deps.Experiment.variant("owner/repo", n)
+		if result.Variant != want {
+			t.Errorf("issue #%d: variant %q, want %q", n, result.Variant, want)
+		}
+		if label := result.SuggestedLabels[0].Name; (label == "feature") != (want == "short-prompt") {
+			t.Errorf("issue #%d in %q was labeled %q", n, want, label)
+		}
+	}
+
+	if completer.callCount == 0 || alt.callCount == 0 {
+		t.Fatalf("expected both variants to run, got %d control and %d alternate calls", completer.callCount, alt.callCount)
+	}
+	for _, prompt := range alt.lastPrompts {
+		if !strings.Contains(prompt, "Prefer feature over bug.") {
+			t.Errorf("expected the alternate prompt, got:\n%s", prompt)
+		}
+	}
+	for _, prompt := range completer.lastPrompts {
+		if strings.Contains(prompt, "Prefer feature over bug.") {
+			t.Error("expected the control variant to keep its prompt")
+		}
+	}
+	for _, l := range mockSt.triageLogs {
+		if l.Variant != p.deps.Experiment.variant("owner/repo", l.IssueNumber) {
+			t.Errorf("issue #%d logged with variant %q", l.IssueNumber, l.Variant)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package pipeline
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -13,16 +16,15 @@ import (
 	"github.com/jacklau/triage/internal/dedup"
 	"github.com/jacklau/triage/internal/github"
 	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/provider"
 	"github.com/jacklau/triage/internal/pubsub"
 	"github.com/jacklau/triage/internal/retry"
 	"github.com/jacklau/triage/internal/store"
 )
 
-const (
-	// drainTimeout is the maximum time allowed for an in-flight event to
-	// complete during graceful shutdown.
-	drainTimeout = 30 * time.Second
-)
+// drainTimeout is the maximum time allowed for an in-flight event to
+// complete during graceful shutdown.
+const drainTimeout = 30 * time.Second
 
 // PipelineStore is the subset of store.Store used by the pipeline.
 // It allows injecting a mock for testing.
@@ -30,8 +32,47 @@ type PipelineStore interface {
 	GetRepoByOwnerRepo(owner, repo string) (*store.Repo, error)
 	CreateRepo(owner, repo string) (*store.Repo, error)
 	LogTriageAction(log *store.TriageLog) error
+	ListRepos() ([]store.Repo, error)
+	GetIssuesByRepo(repoID int64) ([]store.Issue, error)
+	GetTriageLog(repoID int64, issueNumber int) ([]store.TriageLog, error)
+}
+
+// NotificationStore records which results have been notified. A
+// PipelineStore that implements it keeps identical results from being
+// notified twice, e.g. when scan is re-run or watch restarts.
+type NotificationStore interface {
+	WasNotified(repoID int64, number int, hash string) (bool, error)
+	RecordNotification(repoID int64, number int, hash string) error
 }
 
+// RepoCounterStore keeps running counters of each repo's triage activity. A
+// PipelineStore that implements it has them updated as issues are processed,
+// except in dry runs.
+type RepoCounterStore interface {
+	AddRepoCounts(repoID int64, delta store.RepoCounters) error
+	RecordRepoError(repoID int64, msg string) error
+}
+
+// RemoteConfigSource provides the settings from a repo's own
+// .github/triage.yml, or nil if it has none.
+type RemoteConfigSource interface {
+	Get(ctx context.Context, fullName string) (*config.RepoSettings, error)
+}
+
+// RepoMetadataSource provides what GitHub says a repo is about, for the
+// classification prompt.
+type RepoMetadataSource interface {
+	Get(ctx context.Context, repoID int64, fullName string) (*store.RepoMetadata, error)
+}
+
+// ErrIgnored is returned for issues that match the repo's ignore rules.
+var ErrIgnored = errors.New("issue ignored by config")
+
+// ErrIncomplete is returned along with the result when dedup or
+// classification failed for an issue, so the result holds only what the
+// other steps found.
+var ErrIncomplete = errors.New("triage incomplete")
+
 // PipelineDeps holds the dependencies for the Pipeline.
 type PipelineDeps struct {
 	Dedup       *dedup.Engine
@@ -41,12 +82,103 @@ type PipelineDeps struct {
 	Broker      *pubsub.Broker[github.IssueEvent]
 	Labels      []config.LabelConfig
 	RepoConfigs []config.RepoConfig
-	Logger      *slog.Logger
+	// OrgDefaults are per-owner settings that RepoConfigs override.
+	OrgDefaults map[string]config.RepoSettings
+	// RepoExclude lists patterns for repos that OrgDefaults and pattern
+	// entries in RepoConfigs do not apply to.
+	RepoExclude []string
+	// LabelPacks are the label packs defined in the config, which repo
+	// settings may use besides the built-in ones.
+	LabelPacks map[string][]config.LabelConfig
+	// RemoteConfig, if set, supplies settings from each repo's
+	// .github/triage.yml.
+	RemoteConfig RemoteConfigSource
+	// RepoMetadata, if set, supplies each repo's description, topics, and
+	// language as context for the classifier.
+	RepoMetadata RepoMetadataSource
+	// SkipIfLabeled skips classification for issues that already have one
+	// of the configured labels, unless a repo's skip_if_labeled overrides it.
+	SkipIfLabeled bool
+	// SkipIfLabeledOverride, if set, replaces SkipIfLabeled and every repo's
+	// skip_if_labeled, such as for scan's --only-unlabeled.
+	SkipIfLabeledOverride *bool
+	// TransferSuggestions compares issues with the stored issues of the
+	// owner's other repos and suggests a transfer when one matches better
+	// than anything in the issue's own repo.
+	TransferSuggestions bool
+	// ParallelClassify runs classification concurrently with dedup, always
+	// classifying, unless a repo's parallel_classify overrides it.
+	ParallelClassify bool
+	// Abstain drops the classifier's labels when its confidence is below
+	// ConfidenceThreshold and marks the result as needing human triage.
+	// Without it, only the labels below ConfidenceThreshold are dropped.
+	Abstain             bool
+	ConfidenceThreshold float64
+	Logger              *slog.Logger
+	// DryRun runs dedup and classification but skips the triage_log write
+	// and notifications, logging what would have been done instead.
+	DryRun bool
+	// Renotify sends results even if an identical one was already
+	// notified for the issue.
+	Renotify bool
+	// KeepRawResponses records the classifier's raw reply in the triage
+	// log.
+	KeepRawResponses bool
+	// Experiment, if set, classifies a share of issues with an alternate
+	// prompt or classifier, tagging each result with its variant.
+	Experiment *Experiment
+	// EmbeddingProvider and LLMProvider name the providers behind Dedup and
+	// Classifier, such as "ollama:llama3", in recorded stage timings.
+	EmbeddingProvider string
+	LLMProvider       string
+	// ProcessTimeout bounds the processing of one event by Run;
+	// config.DefaultProcessTimeout if zero. Once Run's context is
+	// cancelled, an in-flight event gets at most the drain timeout on top.
+	ProcessTimeout time.Duration
 }
 
 // Pipeline orchestrates the issue triage workflow: dedup, classify, notify.
 type Pipeline struct {
 	deps PipelineDeps
+
+	statsMu sync.Mutex
+	stats   Stats
+}
+
+// Stats counts the events Run has handled.
+type Stats struct {
+	// Processed counts issues triaged, including those only partly
+	// processed.
+	Processed int64
+	// Skipped counts issues matching the repo's ignore rules.
+	Skipped int64
+	// Failed counts issues that could not be processed.
+	Failed int64
+	// LastProcessed is when the last issue was handled, or zero.
+	LastProcessed time.Time
+}
+
+// Stats returns the counts of events handled so far.
+func (p *Pipeline) Stats() Stats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	return p.stats
+}
+
+// count records the outcome of handling an issue, by the error processing
+// it returned.
+func (p *Pipeline) count(err error) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	switch {
+	case errors.Is(err, ErrIgnored):
+		p.stats.Skipped++
+	case err == nil || errors.Is(err, ErrIncomplete):
+		p.stats.Processed++
+	default:
+		p.stats.Failed++
+	}
+	p.stats.LastProcessed = time.Now()
 }
 
 // New creates a new Pipeline with the given dependencies.
@@ -54,13 +186,17 @@ func New(deps PipelineDeps) *Pipeline {
 	if deps.Logger == nil {
 		deps.Logger = slog.Default()
 	}
+	if deps.ProcessTimeout <= 0 {
+		deps.ProcessTimeout = config.DefaultProcessTimeout
+	}
 	return &Pipeline{deps: deps}
 }
 
 // Run subscribes to the broker and processes IssueEvents until the context is cancelled.
 // When the context is cancelled, Run waits for the current in-flight event to finish
 // processing before returning, ensuring graceful shutdown. In-flight events use a
-// detached context so they are not interrupted by pipeline cancellation.
+// detached context so they are not interrupted by pipeline cancellation; each is
+// bounded by ProcessTimeout, and by the drain timeout once ctx is cancelled.
 func (p *Pipeline) Run(ctx context.Context) error {
 	events := p.deps.Broker.Subscribe(ctx)
 	p.deps.Logger.Info("pipeline started, listening for events")
@@ -82,13 +218,7 @@ func (p *Pipeline) Run(ctx context.Context) error {
 				return nil
 			}
 			wg.Add(1)
-			// Use a detached context with a timeout for processing so that
-			// in-flight events are not interrupted by pipeline context
-			// cancellation but still have a bounded lifetime.
-			processCtx, processCancel := context.WithTimeout(
-				context.WithoutCancel(ctx),
-				drainTimeout,
-			)
+			processCtx, processCancel := processContext(ctx, p.deps.ProcessTimeout, drainTimeout)
 			p.handleEvent(processCtx, evt)
 			processCancel()
 			wg.Done()
@@ -96,13 +226,40 @@ func (p *Pipeline) Run(ctx context.Context) error {
 	}
 }
 
+// processContext returns the context an event is processed in. It is
+// detached from ctx, so in-flight events are not interrupted by pipeline
+// cancellation, but ends after timeout, or drain after ctx is cancelled,
+// whichever comes first.
+func processContext(ctx context.Context, timeout, drain time.Duration) (context.Context, context.CancelFunc) {
+	processCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	var mu sync.Mutex
+	var timer *time.Timer
+	stop := context.AfterFunc(ctx, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		timer = time.AfterFunc(drain, cancel)
+	})
+	return processCtx, func() {
+		stop()
+		mu.Lock()
+		if timer != nil {
+			timer.Stop()
+		}
+		mu.Unlock()
+		cancel()
+	}
+}
+
 func (p *Pipeline) handleEvent(ctx context.Context, evt pubsub.Event[github.IssueEvent]) {
 	ie := evt.Payload
 
 	// Only process actionable change types
 	switch ie.ChangeType {
-	case github.ChangeNew, github.ChangeTitleEdited, github.ChangeBodyEdited:
+	case github.ChangeNew, github.ChangeTitleEdited, github.ChangeBodyEdited, github.ChangeRetriage:
 		// proceed
+	case github.ChangeRemoved:
+		p.forgetIssue(ie)
+		return
 	default:
 		return
 	}
@@ -112,11 +269,21 @@ func (p *Pipeline) handleEvent(ctx context.Context, evt pubsub.Event[github.Issu
 		"issue", ie.Issue.Number,
 		"change", ie.ChangeType.String(),
 	)
+	ctx, logger = startTriage(ctx, logger)
 
 	start := time.Now()
 	logger.Info("processing issue")
 
 	result, err := p.processIssue(ctx, ie, logger)
+	p.count(err)
+	if errors.Is(err, ErrIgnored) {
+		logger.Info("skipping issue", "reason", err)
+		return
+	}
+	if errors.Is(err, ErrIncomplete) {
+		logger.Warn("issue partly processed", "error", err, "duration", time.Since(start))
+		return
+	}
 	if err != nil {
 		logger.Error("failed to process issue", "error", err, "duration", time.Since(start))
 		return
@@ -129,9 +296,50 @@ func (p *Pipeline) handleEvent(ctx context.Context, evt pubsub.Event[github.Issu
 	)
 }
 
+// countRepo adds delta to a repo's counters, if the store keeps them.
+func (p *Pipeline) countRepo(repoID int64, delta store.RepoCounters, logger *slog.Logger) {
+	counters, ok := p.deps.Store.(RepoCounterStore)
+	if !ok || p.deps.DryRun {
+		return
+	}
+	if err := counters.AddRepoCounts(repoID, delta); err != nil {
+		logger.Warn("failed to update repo counters", "error", err)
+	}
+}
+
+// recordRepoError records err as a repo's last error, if the store keeps
+// one.
+func (p *Pipeline) recordRepoError(repoID int64, err error, logger *slog.Logger) {
+	counters, ok := p.deps.Store.(RepoCounterStore)
+	if !ok || p.deps.DryRun {
+		return
+	}
+	if recErr := counters.RecordRepoError(repoID, err.Error()); recErr != nil {
+		logger.Warn("failed to record repo error", "error", recErr)
+	}
+}
+
+// forgetIssue drops a removed issue from the dedup engine's cached
+// embeddings. The store has already tombstoned it, so reloading the repo's
+// embeddings leaves it out.
+func (p *Pipeline) forgetIssue(ie github.IssueEvent) {
+	p.deps.Logger.Info("issue removed from GitHub", "repo", ie.Repo, "issue", ie.Issue.Number)
+	if p.deps.Dedup == nil {
+		return
+	}
+	owner, name, ok := strings.Cut(ie.Repo, "/")
+	if !ok {
+		return
+	}
+	if repo, err := p.deps.Store.GetRepoByOwnerRepo(owner, name); err == nil {
+		p.deps.Dedup.Invalidate(repo.ID)
+	}
+}
+
 // ProcessSingleIssue exposes processing a single issue for use by scan/check commands.
 func (p *Pipeline) ProcessSingleIssue(ctx context.Context, repo string, issue github.Issue) (*github.TriageResult, error) {
 	logger := p.deps.Logger.With("repo", repo, "issue", issue.Number)
+	ctx, logger = startTriage(ctx, logger)
 	ie := github.IssueEvent{
 		Repo:       repo,
 		Issue:      issue,
@@ -140,87 +348,108 @@ func (p *Pipeline) ProcessSingleIssue(ctx context.Context, repo string, issue gi
 	return p.processIssue(ctx, ie, logger)
 }
 
-// findRepoConfig looks up the RepoConfig for the given full repo name (owner/repo).
-// Returns nil if no per-repo config is found.
-func (p *Pipeline) findRepoConfig(repoFullName string) *config.RepoConfig {
-	for i := range p.deps.RepoConfigs {
-		if p.deps.RepoConfigs[i].Name == repoFullName {
-			return &p.deps.RepoConfigs[i]
+// findRepoConfig looks up the RepoConfig for the given full repo name
+// (owner/repo), merging org defaults, matching pattern entries, and the
+// repo's .github/triage.yml. Returns nil if no per-repo config is found.
+func (p *Pipeline) findRepoConfig(ctx context.Context, repoFullName string, logger *slog.Logger) *config.RepoConfig {
+	var remote *config.RepoSettings
+	if p.deps.RemoteConfig != nil {
+		var err error
+		remote, err = p.deps.RemoteConfig.Get(ctx, repoFullName)
+		if err != nil {
+			logger.Warn("failed to load repo config file, using last known settings", "error", err)
 		}
 	}
-	return nil
+	rc, ok := config.RepoSet{
+		OrgDefaults: p.deps.OrgDefaults,
+		Repos:       p.deps.RepoConfigs,
+		Exclude:     p.deps.RepoExclude,
+		Packs:       p.deps.LabelPacks,
+	}.ResolveWithRemote(repoFullName, remote)
+	if !ok {
+		return nil
+	}
+	return &rc
+}
+
+// ReplayIssue re-triages a stored issue with the current config, as a
+// maintainer's re-triage request would, so it is classified even if
+// labeled. Only issues filed before it count as its duplicates, as when it
+// arrived, and the repo's counters are left alone. It also returns the
+// action logged for the result: "triaged", "duplicate", or "abstained".
+func (p *Pipeline) ReplayIssue(ctx context.Context, repo string, issue github.Issue) (*github.TriageResult, string, error) {
+	logger := p.deps.Logger.With("repo", repo, "issue", issue.Number, "replay", true)
+	ctx, logger = startTriage(ctx, logger)
+	ie := github.IssueEvent{
+		Repo:       repo,
+		Issue:      issue,
+		ChangeType: github.ChangeRetriage,
+	}
+	return p.process(ctx, ie, modeReplay, logger)
 }
 
 func (p *Pipeline) processIssue(ctx context.Context, ie github.IssueEvent, logger *slog.Logger) (*github.TriageResult, error) {
+	result, _, err := p.process(ctx, ie, modeIssue, logger)
+	return result, err
+}
+
+// triageMode is what an issue is analyzed for.
+type triageMode int
+
+const (
+	// modeIssue triages an issue as it arrives.
+	modeIssue triageMode = iota
+	// modeDraft checks an issue not yet filed, storing nothing for it.
+	modeDraft
+	// modeReplay triages a stored issue again, as it was when it arrived.
+	modeReplay
+)
+
+// process triages ie, returning the result and the action logged for it.
+func (p *Pipeline) process(ctx context.Context, ie github.IssueEvent, mode triageMode, logger *slog.Logger) (*github.TriageResult, string, error) {
 	parts := strings.SplitN(ie.Repo, "/", 2)
 	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid repo format: %s", ie.Repo)
+		return nil, "", fmt.Errorf("invalid repo format: %s", ie.Repo)
 	}
 	owner, repoName := parts[0], parts[1]
 
 	// Get or create repo record
 	repo, err := p.deps.Store.GetRepoByOwnerRepo(owner, repoName)
-	if err != nil {
+	if errors.Is(err, store.ErrRepoNotFound) {
 		repo, err = p.deps.Store.CreateRepo(owner, repoName)
 		if err != nil {
-			return nil, fmt.Errorf("creating repo record: %w", err)
+			return nil, "", fmt.Errorf("creating repo record: %w", err)
 		}
+	} else if err != nil {
+		return nil, "", fmt.Errorf("looking up repo: %w", err)
 	}
 
-	// Look up per-repo config overrides
-	rc := p.findRepoConfig(ie.Repo)
-
-	result := &github.TriageResult{
-		Repo:        ie.Repo,
-		IssueNumber: ie.Issue.Number,
-	}
+	start := time.Now()
+	timings := &stageTimings{}
 
-	// Step 1: Run dedup with retry and optional per-repo threshold
-	var dedupResult *dedup.DedupResult
-	if p.deps.Dedup != nil {
-		var thresholdOverride float32
-		if rc != nil && rc.SimilarityThreshold != nil {
-			thresholdOverride = float32(*rc.SimilarityThreshold)
-		}
-		retryErr := retry.Do(ctx, retry.DefaultMaxAttempts, func() error {
-			var dedupErr error
-			dedupResult, dedupErr = p.deps.Dedup.CheckDuplicateWithThreshold(ctx, repo.ID, ie.Issue, thresholdOverride)
-			return dedupErr
-		})
-		if retryErr != nil {
-			logger.Warn("embedding/dedup failed after retries, skipping dedup", "error", retryErr)
-			// Continue to classify
-		} else {
-			result.Duplicates = dedupResult.Candidates
+	// Skip issues matching the repo's ignore rules before any provider call
+	if rc := p.findRepoConfig(ctx, ie.Repo, logger); rc != nil {
+		if reason := rc.Ignore.Match(ie.Issue.Title, ie.Issue.Author, ie.Issue.Labels); reason != "" {
+			if mode != modeReplay {
+				p.countRepo(repo.ID, store.RepoCounters{IssuesSeen: 1}, logger)
+			}
+			return nil, "", fmt.Errorf("%w: %s", ErrIgnored, reason)
 		}
 	}
 
-	// Step 2: If not a duplicate, run classifier with retry and optional custom prompt
-	isDuplicate := dedupResult != nil && dedupResult.IsDuplicate
-	if !isDuplicate && p.deps.Classifier != nil && len(p.deps.Labels) > 0 {
-		var customPrompt string
-		if rc != nil {
-			customPrompt = rc.CustomPrompt
-		}
-		var classResult *classify.ClassifyResult
-		retryErr := retry.Do(ctx, retry.DefaultMaxAttempts, func() error {
-			var classErr error
-			classResult, classErr = p.deps.Classifier.ClassifyWithCustomPrompt(ctx, ie.Repo, p.deps.Labels, ie.Issue, customPrompt)
-			return classErr
-		})
-		if retryErr != nil {
-			logger.Error("classification failed after retries", "error", retryErr)
-			// Send notification with dedup results only
-		} else {
-			result.SuggestedLabels = classResult.Labels
-			result.Reasoning = classResult.Reasoning
-		}
+	// Steps 1-2: dedup, then classify if not a duplicate
+	result, isDuplicate, stepErr := p.analyze(ctx, ie, repo.ID, mode, timings, logger)
+	result.TriageID = provider.TriageID(ctx)
+	if stepErr != nil {
+		p.recordRepoError(repo.ID, fmt.Errorf("issue #%d: %w", ie.Issue.Number, stepErr), logger)
 	}
 
 	// Step 3: Log in triage_log
 	action := "triaged"
 	if isDuplicate {
 		action = "duplicate"
+	} else if result.NeedsHumanTriage {
+		action = "abstained"
 	}
 
 	duplicateOf := ""
@@ -233,32 +462,393 @@ func (p *Pipeline) processIssue(ctx context.Context, ie github.IssueEvent, logge
 	}
 
 	labelNames := make([]string, len(result.SuggestedLabels))
+	confidences := make(map[string]float64, len(result.SuggestedLabels))
 	for i, l := range result.SuggestedLabels {
 		labelNames[i] = l.Name
+		confidences[l.Name] = l.Confidence
 	}
 
 	triageLog := &store.TriageLog{
-		RepoID:          repo.ID,
-		IssueNumber:     ie.Issue.Number,
-		Action:          action,
-		DuplicateOf:     duplicateOf,
-		SuggestedLabels: strings.Join(labelNames, ", "),
-		Reasoning:       result.Reasoning,
+		RepoID:           repo.ID,
+		IssueNumber:      ie.Issue.Number,
+		Action:           action,
+		DuplicateOf:      duplicateOf,
+		SuggestedLabels:  strings.Join(labelNames, ", "),
+		Reasoning:        result.Reasoning,
+		LabelConfidences: confidences,
+		RawResponse:      result.RawResponse,
+		Variant:          result.Variant,
+		TriageID:         result.TriageID,
+		Truncated:        result.Truncated,
+	}
+
+	if p.deps.DryRun {
+		logger.Info("dry run: skipping triage log and notification",
+			"action", action,
+			"duplicate_of", duplicateOf,
+			"labels", triageLog.SuggestedLabels,
+			"would_notify", p.deps.Notifier != nil,
+		)
+		return result, action, incomplete(stepErr)
+	}
+
+	// Step 4: Send notification with retry, recording where it went
+	notifyStart := time.Now()
+	triageLog.NotifiedVia = p.notify(ctx, repo.ID, result, logger)
+	if triageLog.NotifiedVia != "" {
+		timings.since(store.StageNotify, triageLog.NotifiedVia, notifyStart)
 	}
 
 	if err := p.deps.Store.LogTriageAction(triageLog); err != nil {
 		logger.Error("failed to log triage action", "error", err)
+		p.recordRepoError(repo.ID, fmt.Errorf("issue #%d: logging triage: %w", ie.Issue.Number, err), logger)
 	}
+	timings.since(store.StageTotal, "", start)
+	p.logTimings(repo.ID, ie.Issue.Number, result.TriageID, timings, logger)
 
-	// Step 4: Send notification with retry
-	if p.deps.Notifier != nil {
-		notifyErr := retry.Do(ctx, retry.DefaultMaxAttempts, func() error {
-			return p.deps.Notifier.Notify(ctx, *result)
-		})
-		if notifyErr != nil {
-			logger.Error("notification failed after retries", "error", notifyErr)
+	// A replayed issue was counted when it arrived
+	if mode != modeReplay {
+		counts := store.RepoCounters{IssuesSeen: 1, Triaged: 1}
+		if isDuplicate {
+			counts = store.RepoCounters{IssuesSeen: 1, Duplicates: 1}
+		}
+		p.countRepo(repo.ID, counts, logger)
+	}
+
+	return result, action, incomplete(stepErr)
+}
+
+// earlierIssues returns the candidates numbered below number.
+func earlierIssues(candidates []github.DuplicateCandidate, number int) []github.DuplicateCandidate {
+	var earlier []github.DuplicateCandidate
+	for _, c := range candidates {
+		if c.Number < number {
+			earlier = append(earlier, c)
 		}
 	}
+	return earlier
+}
+
+// startTriage gives the processing of one event a new triage ID, carried
+// by the returned context to provider requests and logged by the returned
+// logger. process records it with the result.
+func startTriage(ctx context.Context, logger *slog.Logger) (context.Context, *slog.Logger) {
+	id := newTriageID()
+	return provider.WithTriageID(ctx, id), logger.With("triage_id", id)
+}
 
+// newTriageID returns a random 16-character hex ID.
+func newTriageID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// incomplete wraps a failed step's error in ErrIncomplete, or returns nil.
+func incomplete(stepErr error) error {
+	if stepErr == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrIncomplete, stepErr)
+}
+
+// notify sends result to the notifier, if any, with retry. Failures are
+// logged rather than returned. Unless Renotify is set, a result identical to
+// one already notified for the issue is skipped. It returns the notifier's
+// name if the result was sent, else "".
+func (p *Pipeline) notify(ctx context.Context, repoID int64, result *github.TriageResult, logger *slog.Logger) string {
+	if p.deps.Notifier == nil {
+		return ""
+	}
+
+	sent, _ := p.deps.Store.(NotificationStore)
+	hash := result.ContentHash()
+	if sent != nil && !p.deps.Renotify {
+		already, err := sent.WasNotified(repoID, result.IssueNumber, hash)
+		if err != nil {
+			logger.Warn("failed to check sent notifications", "error", err)
+		} else if already {
+			logger.Debug("identical result already notified, skipping")
+			return ""
+		}
+	}
+
+	notifyErr := retry.Do(ctx, retry.DefaultMaxAttempts, func() error {
+		return p.deps.Notifier.Notify(ctx, *result)
+	})
+	if notifyErr != nil {
+		logger.Error("notification failed after retries", "error", notifyErr)
+		p.recordRepoError(repoID, fmt.Errorf("issue #%d: notifying: %w", result.IssueNumber, notifyErr), logger)
+		return ""
+	}
+	if sent != nil {
+		if err := sent.RecordNotification(repoID, result.IssueNumber, hash); err != nil {
+			logger.Warn("failed to record notification", "error", err)
+		}
+	}
+	return notify.Name(p.deps.Notifier)
+}
+
+// ProcessDraft runs dedup and classification on an issue that has not been
+// filed yet. Nothing is stored, logged, or notified. If the repo has never
+// been scanned there is nothing to compare against, so dedup is skipped.
+func (p *Pipeline) ProcessDraft(ctx context.Context, repo string, draft github.Issue) (*github.TriageResult, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repo format: %s", repo)
+	}
+	logger := p.deps.Logger.With("repo", repo, "draft", true)
+
+	var repoID int64
+	if r, err := p.deps.Store.GetRepoByOwnerRepo(parts[0], parts[1]); err == nil {
+		repoID = r.ID
+	} else {
+		logger.Warn("repo has no stored issues, skipping dedup (run scan first)")
+	}
+
+	ie := github.IssueEvent{Repo: repo, Issue: draft, ChangeType: github.ChangeNew}
+	result, _, _ := p.analyze(ctx, ie, repoID, modeDraft, nil, logger)
 	return result, nil
 }
+
+// analyze runs dedup and, unless the issue is a duplicate, classification.
+// A zero repoID skips dedup. Drafts are compared without storing their
+// embedding; replayed issues only with earlier issues. A step that fails is
+// skipped, with its error in stepErr. The steps that succeed are timed in
+// timings, if not nil.
+func (p *Pipeline) analyze(ctx context.Context, ie github.IssueEvent, repoID int64, mode triageMode, timings *stageTimings, logger *slog.Logger) (result *github.TriageResult, isDuplicate bool, stepErr error) {
+	draft := mode == modeDraft
+	// Look up per-repo config overrides
+	rc := p.findRepoConfig(ctx, ie.Repo, logger)
+
+	result = &github.TriageResult{
+		Repo:        ie.Repo,
+		IssueNumber: ie.Issue.Number,
+		IssueTitle:  ie.Issue.Title,
+	}
+
+	labels := p.deps.Labels
+	if rc != nil && len(rc.Labels) > 0 {
+		labels = rc.Labels
+	}
+	skipIfLabeled := p.deps.SkipIfLabeled
+	if rc != nil && rc.SkipIfLabeled != nil {
+		skipIfLabeled = *rc.SkipIfLabeled
+	}
+	if p.deps.SkipIfLabeledOverride != nil {
+		skipIfLabeled = *p.deps.SkipIfLabeledOverride
+	}
+	// A maintainer's re-triage request classifies even labeled issues
+	skipClassify := skipIfLabeled && ie.ChangeType != github.ChangeRetriage && hasAnyLabel(ie.Issue.Labels, labels)
+	if skipClassify {
+		logger.Debug("issue already labeled, skipping classification")
+	}
+	canClassify := !skipClassify && p.deps.Classifier != nil && len(labels) > 0
+	variant := ""
+	if p.deps.Experiment != nil {
+		variant = p.deps.Experiment.variant(ie.Repo, ie.Issue.Number)
+	}
+
+	// In parallel mode, classification starts alongside dedup and runs even
+	// if the issue turns out to be a duplicate.
+	parallel := p.deps.ParallelClassify
+	if rc != nil && rc.ParallelClassify != nil {
+		parallel = *rc.ParallelClassify
+	}
+	var classDone chan *classify.ClassifyResult
+	var classErr error
+	if parallel && canClassify {
+		classDone = make(chan *classify.ClassifyResult, 1)
+		go func() {
+			res, err := p.classify(ctx, ie, repoID, labels, rc, variant, timings, logger)
+			classErr = err
+			classDone <- res
+		}()
+	}
+
+	// Step 1: Run dedup with retry and optional per-repo threshold
+	var thresholdOverride float32
+	if rc != nil && rc.SimilarityThreshold != nil {
+		thresholdOverride = float32(*rc.SimilarityThreshold)
+	}
+	var dedupResult *dedup.DedupResult
+	if p.deps.Dedup != nil && repoID != 0 {
+		dedupStart := time.Now()
+		retryErr := retry.Do(ctx, retry.DefaultMaxAttempts, func() error {
+			var dedupErr error
+			if draft {
+				dedupResult, dedupErr = p.deps.Dedup.CheckDraft(ctx, repoID, ie.Issue, thresholdOverride)
+			} else {
+				dedupResult, dedupErr = p.deps.Dedup.CheckDuplicateWithThreshold(ctx, repoID, ie.Issue, thresholdOverride)
+			}
+			return dedupErr
+		})
+		if retryErr != nil {
+			logger.Warn("embedding/dedup failed after retries, skipping dedup", "error", retryErr)
+			stepErr = fmt.Errorf("dedup: %w", retryErr)
+			// Continue to classify
+		} else {
+			if mode == modeReplay {
+				// Later issues were not filed yet when this one arrived
+				dedupResult.Candidates = earlierIssues(dedupResult.Candidates, ie.Issue.Number)
+				dedupResult.IsDuplicate = len(dedupResult.Candidates) > 0
+			}
+			result.Duplicates = dedupResult.Candidates
+			timings.since(store.StageDedup, p.deps.EmbeddingProvider, dedupStart)
+		}
+	}
+
+	// Later steps reuse the issue's embedding rather than embedding it again
+	embedCtx := ctx
+	if dedupResult != nil && dedupResult.Embedding != nil {
+		embedCtx = dedup.ContextWithEmbedding(ctx, ie.Issue, dedupResult.Embedding)
+	}
+
+	// Drafts are skipped: matching may embed and store pull requests, and
+	// nothing is written for a draft.
+	if p.deps.Dedup != nil && repoID != 0 && !draft {
+		prs, err := p.deps.Dedup.MatchPullRequests(embedCtx, repoID, ie.Issue, thresholdOverride)
+		if err != nil {
+			logger.Warn("pull request matching failed", "error", err)
+		}
+		result.PullRequests = prs
+	}
+
+	if p.deps.TransferSuggestions && p.deps.Dedup != nil {
+		result.Transfer = p.suggestTransfer(embedCtx, ie, repoID, thresholdOverride, result.Duplicates, logger)
+	}
+
+	// Step 2: If not a duplicate, run classifier with retry and optional
+	// custom prompt, unless it already ran in parallel
+	isDuplicate = dedupResult != nil && dedupResult.IsDuplicate
+	var classResult *classify.ClassifyResult
+	if classDone != nil {
+		classResult = <-classDone
+	} else if !isDuplicate && canClassify {
+		classResult, classErr = p.classify(ctx, ie, repoID, labels, rc, variant, timings, logger)
+	}
+	if classErr != nil {
+		stepErr = errors.Join(stepErr, fmt.Errorf("classification: %w", classErr))
+	}
+	if classResult != nil {
+		result.Variant = variant
+		result.Truncated = classResult.Truncated
+	}
+	if classResult != nil && p.deps.KeepRawResponses {
+		result.RawResponse = classResult.RawResponse
+	}
+	switch {
+	case classResult == nil:
+		// Send notification with dedup results only
+	case p.deps.Abstain && classResult.Confidence < p.deps.ConfidenceThreshold:
+		logger.Info("classifier abstained", "confidence", classResult.Confidence)
+		result.NeedsHumanTriage = true
+		result.Reasoning = classResult.Reasoning
+	default:
+		result.SuggestedLabels = github.RankLabels(classResult.Labels, p.deps.ConfidenceThreshold)
+		if dropped := len(classResult.Labels) - len(result.SuggestedLabels); dropped > 0 {
+			logger.Info("dropped labels below confidence threshold", "dropped", dropped, "threshold", p.deps.ConfidenceThreshold)
+		}
+		result.Reasoning = classResult.Reasoning
+	}
+
+	return result, isDuplicate, stepErr
+}
+
+// classify runs the classifier with retry and the repo's custom prompt,
+// examples, and metadata, timing it in timings if it succeeds.
+func (p *Pipeline) classify(ctx context.Context, ie github.IssueEvent, repoID int64, labels []config.LabelConfig, rc *config.RepoConfig, variant string, timings *stageTimings, logger *slog.Logger) (*classify.ClassifyResult, error) {
+	var guidance classify.Guidance
+	if rc != nil {
+		guidance = classify.Guidance{CustomPrompt: rc.CustomPrompt, Examples: rc.Examples, Language: rc.Language}
+	}
+	classifier, llm := p.deps.Classifier, p.deps.LLMProvider
+	if exp := p.deps.Experiment; exp != nil && variant == exp.Name {
+		if exp.Prompt != "" {
+			guidance.CustomPrompt = exp.Prompt
+		}
+		if exp.Classifier != nil {
+			classifier, llm = exp.Classifier, exp.Provider
+		}
+		logger = logger.With("variant", variant)
+	}
+	if p.deps.RepoMetadata != nil && repoID != 0 {
+		m, err := p.deps.RepoMetadata.Get(ctx, repoID, ie.Repo)
+		if err != nil {
+			logger.Warn("failed to refresh repo metadata, using last known", "error", err)
+		}
+		if m != nil {
+			guidance.Description = m.Description
+			guidance.Topics = m.Topics
+			guidance.RepoLanguage = m.Language
+		}
+	}
+	var classResult *classify.ClassifyResult
+	classStart := time.Now()
+	retryErr := retry.Do(ctx, retry.DefaultMaxAttempts, func() error {
+		var classErr error
+		classResult, classErr = classifier.ClassifyWithGuidance(ctx, ie.Repo, labels, ie.Issue, guidance)
+		return classErr
+	})
+	if retryErr != nil {
+		logger.Error("classification failed after retries", "error", retryErr)
+		return nil, retryErr
+	}
+	timings.since(store.StageClassify, llm, classStart)
+	if classResult.Truncated {
+		logger.Info("classified with a truncated body to fit the model's context")
+	}
+	return classResult, nil
+}
+
+// suggestTransfer looks for a closer match to the issue among the owner's
+// other stored repos than the best of own, the candidates from the issue's
+// repo, and returns a suggestion to transfer it there, or nil.
+func (p *Pipeline) suggestTransfer(ctx context.Context, ie github.IssueEvent, repoID int64, threshold float32, own []github.DuplicateCandidate, logger *slog.Logger) *github.TransferSuggestion {
+	repos, err := p.deps.Store.ListRepos()
+	if err != nil {
+		logger.Warn("listing repos for transfer suggestion failed", "error", err)
+		return nil
+	}
+	owner, _, _ := strings.Cut(ie.Repo, "/")
+	names := make(map[int64]string)
+	var others []int64
+	for _, r := range repos {
+		name := r.Owner + "/" + r.RepoName
+		if r.ID == repoID || !strings.EqualFold(r.Owner, owner) || strings.EqualFold(name, ie.Repo) {
+			continue
+		}
+		names[r.ID] = name
+		others = append(others, r.ID)
+	}
+	if len(others) == 0 {
+		return nil
+	}
+
+	match, err := p.deps.Dedup.BestMatchInRepos(ctx, repoID, ie.Issue, others, threshold)
+	if err != nil {
+		logger.Warn("cross-repo comparison failed, skipping transfer suggestion", "error", err)
+		return nil
+	}
+	if match == nil || (len(own) > 0 && own[0].Score >= match.Candidate.Score) {
+		return nil
+	}
+	return &github.TransferSuggestion{
+		Repo:   names[match.RepoID],
+		Number: match.Candidate.Number,
+		Score:  match.Candidate.Score,
+	}
+}
+
+// hasAnyLabel reports whether any of issueLabels is one of the configured
+// labels. GitHub label names are case-insensitive.
+func hasAnyLabel(issueLabels []string, configured []config.LabelConfig) bool {
+	for _, name := range issueLabels {
+		for _, lc := range configured {
+			if strings.EqualFold(name, lc.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
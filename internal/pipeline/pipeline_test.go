@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -14,7 +15,9 @@ import (
 	"github.com/jacklau/triage/internal/config"
 	"github.com/jacklau/triage/internal/dedup"
 	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/provider"
 	"github.com/jacklau/triage/internal/pubsub"
+	"github.com/jacklau/triage/internal/retry"
 	"github.com/jacklau/triage/internal/store"
 )
 
@@ -53,13 +56,15 @@ type mockCompleter struct {
 	err         error
 	callCount   int
 	lastPrompts []string
+	triageIDs   []string
 }
 
-func (m *mockCompleter) Complete(_ context.Context, prompt string) (string, error) {
+func (m *mockCompleter) Complete(ctx context.Context, prompt string) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.callCount++
 	m.lastPrompts = append(m.lastPrompts, prompt)
+	m.triageIDs = append(m.triageIDs, provider.TriageID(ctx))
 	if m.err != nil {
 		return "", m.err
 	}
@@ -112,7 +117,7 @@ func (m *mockStore) GetRepoByOwnerRepo(owner, repo string) (*store.Repo, error)
 	key := owner + "/" + repo
 	r, ok := m.repos[key]
 	if !ok {
-		return nil, errors.New("scanning repo: no rows in result set")
+		return nil, fmt.Errorf("scanning repo: %w", store.ErrRepoNotFound)
 	}
 	return r, nil
 }
@@ -144,6 +149,32 @@ func (m *mockStore) LogTriageAction(log *store.TriageLog) error {
 	return nil
 }
 
+func (m *mockStore) ListRepos() ([]store.Repo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	repos := make([]store.Repo, 0, len(m.repos))
+	for _, r := range m.repos {
+		repos = append(repos, *r)
+	}
+	return repos, nil
+}
+
+func (m *mockStore) GetIssuesByRepo(int64) ([]store.Issue, error) {
+	return nil, nil
+}
+
+func (m *mockStore) GetTriageLog(repoID int64, issueNumber int) ([]store.TriageLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var logs []store.TriageLog
+	for _, l := range m.triageLogs {
+		if l.RepoID == repoID && l.IssueNumber == issueNumber {
+			logs = append(logs, *l)
+		}
+	}
+	return logs, nil
+}
+
 // mockEmbeddingStore implements dedup.EmbeddingStore for testing without SQLite.
 type mockEmbeddingStore struct {
 	mu         sync.Mutex
@@ -298,6 +329,55 @@ func TestPipelineProcessesNewIssue(t *testing.T) {
 	}
 }
 
+func TestProcessContext(t *testing.T) {
+	// Without shutdown, the process timeout applies
+	ctx, cancel := context.WithCancel(context.Background())
+	processCtx, processCancel := processContext(ctx, time.Hour, time.Millisecond)
+	deadline, ok := processCtx.Deadline()
+	if !ok || time.Until(deadline) < 59*time.Minute {
+		t.Errorf("deadline = %v, want about an hour away", deadline)
+	}
+	processCancel()
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+
+	// Once the pipeline is cancelled, the drain timeout cuts it short
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	processCtx, processCancel = processContext(ctx, time.Hour, 20*time.Millisecond)
+	defer processCancel()
+	cancel()
+	if processCtx.Err() != nil {
+		t.Fatal("expected the event to keep running right after cancellation")
+	}
+	select {
+	case <-processCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the drain timeout to end processing")
+	}
+
+	// A short process timeout still applies during the drain
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	processCtx, processCancel = processContext(ctx, 10*time.Millisecond, time.Hour)
+	defer processCancel()
+	cancel()
+	select {
+	case <-processCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the process timeout to end processing")
+	}
+}
+
+func TestNewDefaultsProcessTimeout(t *testing.T) {
+	if p := New(PipelineDeps{}); p.deps.ProcessTimeout != config.DefaultProcessTimeout {
+		t.Errorf("ProcessTimeout = %s, want %s", p.deps.ProcessTimeout, config.DefaultProcessTimeout)
+	}
+	if p := New(PipelineDeps{ProcessTimeout: 5 * time.Minute}); p.deps.ProcessTimeout != 5*time.Minute {
+		t.Errorf("ProcessTimeout = %s, want 5m", p.deps.ProcessTimeout)
+	}
+}
+
 func TestPipelineIgnoresNonActionableEvents(t *testing.T) {
 	p, _, broker, _, _, notifier := setupTestPipeline(t)
 
@@ -486,6 +566,11 @@ func TestPipelineHandlesNotificationFailure(t *testing.T) {
 	if notifier.callCount != 3 {
 		t.Errorf("expected 3 notification calls (retry.DefaultMaxAttempts), got %d", notifier.callCount)
 	}
+	mockSt.mu.Lock()
+	defer mockSt.mu.Unlock()
+	if len(mockSt.triageLogs) != 1 || mockSt.triageLogs[0].NotifiedVia != "" {
+		t.Error("expected the triage log not to record a failed notification")
+	}
 }
 
 func TestPipelineGracefulDrain(t *testing.T) {
@@ -646,9 +731,23 @@ func TestPipelineProcessSingleIssue(t *testing.T) {
 	}
 }
 
+func TestPipelineProcessSingleIssueRepoLookupError(t *testing.T) {
+	p, mockSt, _, _, _, _ := setupTestPipeline(t)
+	mockSt.getRepoErr = errors.New("database is locked")
+
+	_, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{Number: 10, Title: "Check this issue"})
+	if err == nil || !strings.Contains(err.Error(), "database is locked") {
+		t.Fatalf("expected the lookup error, got %v", err)
+	}
+	if len(mockSt.repos) != 0 {
+		t.Error("expected no repo to be created when the lookup fails")
+	}
+}
+
 func TestPipelineStoreInterface(t *testing.T) {
 	// Verify that *store.DB satisfies PipelineStore interface at compile time.
 	var _ PipelineStore = (*store.DB)(nil)
+	var _ NotificationStore = (*store.DB)(nil)
 }
 
 func TestPipelineMockStoreLogsTriageAction(t *testing.T) {
@@ -682,6 +781,221 @@ func TestPipelineMockStoreLogsTriageAction(t *testing.T) {
 	}
 }
 
+func TestPipelineTriageID(t *testing.T) {
+	p, mockSt, _, _, completer, notifier := setupTestPipeline(t)
+	var logs bytes.Buffer
+	p.deps.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+	issue := github.Issue{Number: 5, Title: "Triage me", Body: "Please triage", State: "open", Author: "test"}
+	first, err := p.ProcessSingleIssue(context.Background(), "owner/repo", issue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _, err := p.ReplayIssue(context.Background(), "owner/repo", issue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	id := first.TriageID
+	if len(id) != 16 || second.TriageID == "" || second.TriageID == id {
+		t.Fatalf("expected a distinct triage ID per run, got %q and %q", id, second.TriageID)
+	}
+	if len(completer.triageIDs) == 0 || completer.triageIDs[0] != id {
+		t.Errorf("expected provider calls to carry %q, got %q", id, completer.triageIDs)
+	}
+	if len(mockSt.triageLogs) != 2 || mockSt.triageLogs[0].TriageID != id || mockSt.triageLogs[1].TriageID != second.TriageID {
+		t.Errorf("expected the triage log to record the IDs, got %+v", mockSt.triageLogs)
+	}
+	if len(notifier.results) == 0 || notifier.results[0].TriageID != id {
+		t.Errorf("expected the notification to carry %q", id)
+	}
+
+	p.deps.DryRun = true
+	third, err := p.ProcessSingleIssue(context.Background(), "owner/repo", issue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(logs.String(), "triage_id="+third.TriageID) {
+		t.Errorf("expected log lines tagged with the triage ID:\n%s", logs.String())
+	}
+}
+
+func TestPipelineStats(t *testing.T) {
+	p, mockSt, _, _, _, _ := setupTestPipeline(t)
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	p.handleEvent(context.Background(), pubsub.Event[github.IssueEvent]{Payload: github.IssueEvent{
+		Repo:       "owner/repo",
+		Issue:      github.Issue{Number: 1, Title: "Crash", Body: "It crashes", State: "open"},
+		ChangeType: github.ChangeNew,
+	}})
+	// Removals and other changes are not counted
+	p.handleEvent(context.Background(), pubsub.Event[github.IssueEvent]{Payload: github.IssueEvent{
+		Repo:       "owner/repo",
+		Issue:      github.Issue{Number: 1},
+		ChangeType: github.ChangeRemoved,
+	}})
+	p.count(fmt.Errorf("%w: notifying", ErrIncomplete))
+	p.count(fmt.Errorf("%w: wip", ErrIgnored))
+	p.count(errors.New("boom"))
+
+	got := p.Stats()
+	if got.Processed != 2 || got.Skipped != 1 || got.Failed != 1 || got.LastProcessed.IsZero() {
+		t.Errorf("unexpected stats %+v", got)
+	}
+}
+
+func TestPipelineDryRunSkipsLogAndNotify(t *testing.T) {
+	p, mockSt, _, _, completer, notifier := setupTestPipeline(t)
+	p.deps.DryRun = true
+
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{
+		Number: 6,
+		Title:  "Dry run",
+		Body:   "Should classify but not record",
+		State:  "open",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.SuggestedLabels) == 0 {
+		t.Error("expected classification to run in dry-run mode")
+	}
+	if completer.callCount == 0 {
+		t.Error("expected LLM to be called in dry-run mode")
+	}
+
+	mockSt.mu.Lock()
+	logs := len(mockSt.triageLogs)
+	mockSt.mu.Unlock()
+	if logs != 0 {
+		t.Errorf("expected no triage log writes in dry-run mode, got %d", logs)
+	}
+	if notifier.callCount != 0 {
+		t.Errorf("expected no notifications in dry-run mode, got %d", notifier.callCount)
+	}
+}
+
+// sentStore is a mockStore that also records sent notifications.
+type sentStore struct {
+	*mockStore
+	sent map[string]bool
+}
+
+func (s *sentStore) WasNotified(repoID int64, number int, hash string) (bool, error) {
+	return s.sent[fmt.Sprintf("%d/%d/%s", repoID, number, hash)], nil
+}
+
+func (s *sentStore) RecordNotification(repoID int64, number int, hash string) error {
+	s.sent[fmt.Sprintf("%d/%d/%s", repoID, number, hash)] = true
+	return nil
+}
+
+func TestPipelineSkipsAlreadyNotifiedResults(t *testing.T) {
+	p, mockSt, _, _, completer, notifier := setupTestPipeline(t)
+	st := &sentStore{mockStore: mockSt, sent: make(map[string]bool)}
+	p.deps.Store = st
+
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+	issue := github.Issue{Number: 9, Title: "Crash", Body: "It crashes", State: "open"}
+	process := func() {
+		t.Helper()
+		if _, err := p.ProcessSingleIssue(context.Background(), "owner/repo", issue); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	process()
+	process()
+	if notifier.callCount != 1 {
+		t.Fatalf("expected an identical result to be notified once, got %d", notifier.callCount)
+	}
+
+	// A different result is notified.
+	completer.response = `{"labels": ["feature"], "confidence": 0.9, "reasoning": "A request"}`
+	process()
+	if notifier.callCount != 2 {
+		t.Fatalf("expected a changed result to be notified, got %d calls", notifier.callCount)
+	}
+
+	// Renotify sends repeats.
+	p.deps.Renotify = true
+	process()
+	if notifier.callCount != 3 {
+		t.Errorf("expected --renotify to send the repeat, got %d calls", notifier.callCount)
+	}
+
+	mockSt.mu.Lock()
+	defer mockSt.mu.Unlock()
+	if got := mockSt.triageLogs[1].NotifiedVia; got != "" {
+		t.Errorf("expected the skipped repeat to be logged as not notified, got %q", got)
+	}
+}
+
+func TestPipelineProcessDraftHasNoSideEffects(t *testing.T) {
+	p, mockSt, _, embedder, _, notifier := setupTestPipeline(t)
+
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	result, err := p.ProcessDraft(context.Background(), "owner/repo", github.Issue{
+		Title: "Draft issue",
+		Body:  "Not filed yet",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.SuggestedLabels) == 0 {
+		t.Error("expected draft to be classified")
+	}
+	if embedder.callCount == 0 {
+		t.Error("expected draft to be embedded for dedup")
+	}
+
+	mockSt.mu.Lock()
+	logs := len(mockSt.triageLogs)
+	mockSt.mu.Unlock()
+	if logs != 0 {
+		t.Errorf("expected no triage log writes for a draft, got %d", logs)
+	}
+	if notifier.callCount != 0 {
+		t.Errorf("expected no notifications for a draft, got %d", notifier.callCount)
+	}
+}
+
+func TestPipelineProcessDraftUnknownRepoSkipsDedup(t *testing.T) {
+	p, mockSt, _, embedder, _, _ := setupTestPipeline(t)
+
+	result, err := p.ProcessDraft(context.Background(), "owner/unscanned", github.Issue{
+		Title: "Draft issue",
+		Body:  "Not filed yet",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedder.callCount != 0 {
+		t.Errorf("expected dedup to be skipped for an unknown repo, got %d embed calls", embedder.callCount)
+	}
+	if len(result.SuggestedLabels) == 0 {
+		t.Error("expected draft to still be classified")
+	}
+	if _, err := mockSt.GetRepoByOwnerRepo("owner", "unscanned"); err == nil {
+		t.Error("expected ProcessDraft not to create a repo record")
+	}
+}
+
 func TestPipelineCustomPromptWiredToClassifier(t *testing.T) {
 	db, err := store.Open(":memory:")
 	if err != nil {
@@ -768,6 +1082,96 @@ func TestPipelineCustomPromptWiredToClassifier(t *testing.T) {
 	}
 }
 
+func TestPipelineExamplesWiredToClassifier(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	completer := &mockCompleter{
+		response: `{"labels": ["bug"], "confidence": 0.9, "reasoning": "Bug report"}`,
+	}
+	p := New(PipelineDeps{
+		Dedup:      dedup.NewEngine(newMockEmbedder(), db),
+		Classifier: classify.NewClassifier(completer, 10*time.Second),
+		Notifier:   &mockNotifier{},
+		Store:      db,
+		Broker:     pubsub.NewBroker[github.IssueEvent](),
+		Labels:     testLabels(),
+		RepoConfigs: []config.RepoConfig{{
+			Name:     "owner/repo",
+			Examples: []config.Example{{Title: "Export drops the last row", Labels: []string{"bug"}}},
+		}},
+		Logger: slog.Default(),
+	})
+	if _, err := db.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	_, err = p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{
+		Number: 1, Title: "Test issue", Body: "Test body", State: "open",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	completer.mu.Lock()
+	defer completer.mu.Unlock()
+	if len(completer.lastPrompts) == 0 || !strings.Contains(completer.lastPrompts[0], "Title: Export drops the last row") {
+		t.Errorf("expected the repo's example in the LLM prompt, got %q", completer.lastPrompts)
+	}
+}
+
+func TestPipelineLabelPacksWiredToClassifier(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	completer := &mockCompleter{
+		response: `{"labels": ["team/storage"], "confidence": 0.9, "reasoning": "Disk issue"}`,
+	}
+	p := New(PipelineDeps{
+		Dedup:      dedup.NewEngine(newMockEmbedder(), db),
+		Classifier: classify.NewClassifier(completer, 10*time.Second),
+		Notifier:   &mockNotifier{},
+		Store:      db,
+		Broker:     pubsub.NewBroker[github.IssueEvent](),
+		Labels:     testLabels(),
+		RepoConfigs: []config.RepoConfig{{
+			Name:       "owner/repo",
+			LabelPacks: []string{"teams", "kubernetes-style"},
+		}},
+		LabelPacks: map[string][]config.LabelConfig{
+			"teams": {{Name: "team/storage", Description: "Owned by the storage team"}},
+		},
+		Logger: slog.Default(),
+	})
+	if _, err := db.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{
+		Number: 1, Title: "Disk full", Body: "Writes fail", State: "open",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.SuggestedLabels) != 1 || result.SuggestedLabels[0].Name != "team/storage" {
+		t.Errorf("expected the pack label to be suggested, got %+v", result.SuggestedLabels)
+	}
+
+	completer.mu.Lock()
+	defer completer.mu.Unlock()
+	for _, want := range []string{"- team/storage: Owned by the storage team", "- kind/bug:"} {
+		if len(completer.lastPrompts) == 0 || !strings.Contains(completer.lastPrompts[0], want) {
+			t.Errorf("expected %q in the LLM prompt, got %q", want, completer.lastPrompts)
+		}
+	}
+}
+
 func TestPipelineCustomPromptNotIncludedWhenEmpty(t *testing.T) {
 	db, err := store.Open(":memory:")
 	if err != nil {
@@ -1055,7 +1459,7 @@ func TestPipelineFindRepoConfig(t *testing.T) {
 	})
 
 	// Found
-	rc := p.findRepoConfig("owner/repo1")
+	rc := p.findRepoConfig(context.Background(), "owner/repo1", slog.Default())
 	if rc == nil {
 		t.Fatal("expected to find config for owner/repo1")
 	}
@@ -1064,7 +1468,7 @@ func TestPipelineFindRepoConfig(t *testing.T) {
 	}
 
 	// Found with threshold
-	rc = p.findRepoConfig("owner/repo2")
+	rc = p.findRepoConfig(context.Background(), "owner/repo2", slog.Default())
 	if rc == nil {
 		t.Fatal("expected to find config for owner/repo2")
 	}
@@ -1073,8 +1477,715 @@ func TestPipelineFindRepoConfig(t *testing.T) {
 	}
 
 	// Not found
-	rc = p.findRepoConfig("unknown/repo")
+	rc = p.findRepoConfig(context.Background(), "unknown/repo", slog.Default())
 	if rc != nil {
 		t.Errorf("expected nil for unknown repo, got %+v", rc)
 	}
 }
+
+type fakeRemoteConfig struct {
+	settings map[string]*config.RepoSettings
+	err      error
+}
+
+func (f *fakeRemoteConfig) Get(_ context.Context, fullName string) (*config.RepoSettings, error) {
+	return f.settings[fullName], f.err
+}
+
+func TestPipelineFindRepoConfigRemote(t *testing.T) {
+	remoteThreshold := 0.7
+	remote := &fakeRemoteConfig{settings: map[string]*config.RepoSettings{
+		"owner/repo1": {CustomPrompt: "from repo", SimilarityThreshold: &remoteThreshold, Labels: []config.LabelConfig{{Name: "ios"}}},
+		"owner/repo2": {CustomPrompt: "from repo"},
+	}}
+	p := New(PipelineDeps{
+		OrgDefaults:  map[string]config.RepoSettings{"owner": {CustomPrompt: "org"}},
+		RepoConfigs:  []config.RepoConfig{{Name: "owner/repo2", CustomPrompt: "operator"}},
+		RemoteConfig: remote,
+	})
+
+	rc := p.findRepoConfig(context.Background(), "owner/repo1", slog.Default())
+	if rc == nil || rc.CustomPrompt != "from repo" || *rc.SimilarityThreshold != 0.7 || rc.Labels[0].Name != "ios" {
+		t.Errorf("expected the repo file to override org defaults, got %+v", rc)
+	}
+
+	rc = p.findRepoConfig(context.Background(), "owner/repo2", slog.Default())
+	if rc == nil || rc.CustomPrompt != "operator" {
+		t.Errorf("expected the exact config entry to override the repo file, got %+v", rc)
+	}
+
+	// Errors fall back to whatever settings the source still returns.
+	remote.err = errors.New("boom")
+	rc = p.findRepoConfig(context.Background(), "owner/repo1", slog.Default())
+	if rc == nil || rc.CustomPrompt != "from repo" {
+		t.Errorf("expected stale settings on error, got %+v", rc)
+	}
+}
+
+func TestPipelineUsesRepoLabels(t *testing.T) {
+	completer := &mockCompleter{response: `{"labels": ["ios"], "confidence": 0.9, "reasoning": "iOS crash"}`}
+	p := New(PipelineDeps{
+		Classifier: classify.NewClassifier(completer, 10*time.Second),
+		Labels:     []config.LabelConfig{{Name: "bug"}},
+		RemoteConfig: &fakeRemoteConfig{settings: map[string]*config.RepoSettings{
+			"owner/repo": {Labels: []config.LabelConfig{{Name: "ios", Description: "iOS only"}}},
+		}},
+	})
+
+	result, _, _ := p.analyze(context.Background(), github.IssueEvent{Repo: "owner/repo", Issue: github.Issue{Number: 1, Title: "Crash"}}, 0, modeIssue, nil, slog.Default())
+	if len(completer.lastPrompts) != 1 {
+		t.Fatalf("expected one classification call, got %d", len(completer.lastPrompts))
+	}
+	if prompt := completer.lastPrompts[0]; !strings.Contains(prompt, "iOS only") || strings.Contains(prompt, "- bug") {
+		t.Errorf("expected the repo's labels in the prompt, got:\n%s", prompt)
+	}
+	if len(result.SuggestedLabels) != 1 || result.SuggestedLabels[0].Name != "ios" {
+		t.Errorf("unexpected labels: %+v", result.SuggestedLabels)
+	}
+}
+
+type fakeRepoMetadata struct {
+	metadata *store.RepoMetadata
+	err      error
+}
+
+func (f *fakeRepoMetadata) Get(_ context.Context, _ int64, _ string) (*store.RepoMetadata, error) {
+	return f.metadata, f.err
+}
+
+func TestPipelineUsesRepoMetadata(t *testing.T) {
+	completer := &mockCompleter{response: `{"labels": ["bug"], "confidence": 0.9, "reasoning": "Crash"}`}
+	source := &fakeRepoMetadata{metadata: &store.RepoMetadata{
+		Description: "A game engine",
+		Topics:      []string{"gamedev"},
+		Language:    "Rust",
+	}}
+	p := New(PipelineDeps{
+		Classifier:   classify.NewClassifier(completer, 10*time.Second),
+		Labels:       []config.LabelConfig{{Name: "bug"}},
+		RepoMetadata: source,
+	})
+
+	ie := github.IssueEvent{Repo: "owner/repo", Issue: github.Issue{Number: 1, Title: "Crash"}}
+	p.analyze(context.Background(), ie, 1, modeDraft, nil, slog.Default())
+	if len(completer.lastPrompts) != 1 {
+		t.Fatalf("expected one classification call, got %d", len(completer.lastPrompts))
+	}
+	prompt := completer.lastPrompts[0]
+	for _, want := range []string{"Repository description: A game engine", "Repository topics: gamedev", "Primary language: Rust"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected %q in the prompt, got:\n%s", want, prompt)
+		}
+	}
+
+	// A failed refresh still uses the last known metadata
+	source.err = errors.New("boom")
+	p.analyze(context.Background(), ie, 1, modeDraft, nil, slog.Default())
+	if !strings.Contains(completer.lastPrompts[1], "A game engine") {
+		t.Errorf("expected the last known metadata on error, got:\n%s", completer.lastPrompts[1])
+	}
+
+	// Repos without a record have no metadata to look up
+	source.metadata.Description = "unused"
+	p.analyze(context.Background(), ie, 0, modeDraft, nil, slog.Default())
+	if strings.Contains(completer.lastPrompts[2], "Repository description") {
+		t.Errorf("expected no metadata without a repo ID, got:\n%s", completer.lastPrompts[2])
+	}
+}
+
+func TestPipelineIgnoreRulesSkipProviders(t *testing.T) {
+	p, mockSt, _, embedder, completer, notifier := setupTestPipeline(t)
+	p.deps.RepoConfigs = []config.RepoConfig{
+		{Name: "owner/repo", Ignore: &config.IgnoreRules{Labels: []string{"triaged"}}},
+	}
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	_, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{
+		Number: 1,
+		Title:  "Crash on start",
+		State:  "open",
+		Author: "test",
+		Labels: []string{"triaged"},
+	})
+	if !errors.Is(err, ErrIgnored) {
+		t.Fatalf("expected ErrIgnored, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `has label "triaged"`) {
+		t.Errorf("expected reason in error, got %v", err)
+	}
+	if embedder.callCount != 0 || completer.callCount != 0 || notifier.callCount != 0 {
+		t.Errorf("expected no provider or notifier calls, got embed=%d complete=%d notify=%d",
+			embedder.callCount, completer.callCount, notifier.callCount)
+	}
+	if len(mockSt.triageLogs) != 0 {
+		t.Errorf("expected no triage log, got %d", len(mockSt.triageLogs))
+	}
+}
+
+func TestPipelineSkipIfLabeled(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaultSkip  bool
+		repoSkip     *bool
+		override     *bool
+		issueLabels  []string
+		wantClassify bool
+	}{
+		{"skip labeled issue", true, nil, nil, []string{"Bug"}, false},
+		{"unconfigured label does not count", true, nil, nil, []string{"needs-info"}, true},
+		{"disabled by default", false, nil, nil, []string{"bug"}, true},
+		{"repo override enables", false, ptrBool(true), nil, []string{"bug"}, false},
+		{"repo override disables", true, ptrBool(false), nil, []string{"bug"}, true},
+		{"only unlabeled beats repo", false, ptrBool(false), ptrBool(true), []string{"bug"}, false},
+		{"only unlabeled classifies unlabeled", false, nil, ptrBool(true), nil, true},
+		{"include labeled beats repo", true, ptrBool(true), ptrBool(false), []string{"bug"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mockSt, _, embedder, completer, _ := setupTestPipeline(t)
+			p.deps.SkipIfLabeled = tt.defaultSkip
+			p.deps.SkipIfLabeledOverride = tt.override
+			if tt.repoSkip != nil {
+				p.deps.RepoConfigs = []config.RepoConfig{{Name: "owner/repo", SkipIfLabeled: tt.repoSkip}}
+			}
+			if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+				t.Fatalf("creating repo: %v", err)
+			}
+
+			result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{
+				Number: 1,
+				Title:  "Crash on start",
+				State:  "open",
+				Labels: tt.issueLabels,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if embedder.callCount == 0 {
+				t.Error("expected dedup to run")
+			}
+			if got := completer.callCount > 0; got != tt.wantClassify {
+				t.Errorf("classified = %v, want %v", got, tt.wantClassify)
+			}
+			if !tt.wantClassify && len(result.SuggestedLabels) != 0 {
+				t.Errorf("expected no label suggestions, got %v", result.SuggestedLabels)
+			}
+		})
+	}
+}
+
+func ptrBool(b bool) *bool { return &b }
+
+func TestPipelineRetriageIgnoresSkipIfLabeled(t *testing.T) {
+	p, mockSt, _, _, completer, _ := setupTestPipeline(t)
+	p.deps.SkipIfLabeled = true
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	p.handleEvent(context.Background(), pubsub.Event[github.IssueEvent]{
+		Type: pubsub.Updated,
+		Payload: github.IssueEvent{
+			Repo:       "owner/repo",
+			Issue:      github.Issue{Number: 1, Title: "Crash on start", State: "open", Labels: []string{"bug"}},
+			ChangeType: github.ChangeRetriage,
+		},
+	})
+
+	if completer.callCount == 0 {
+		t.Error("expected a retriage request to classify a labeled issue")
+	}
+	if len(mockSt.triageLogs) != 1 {
+		t.Fatalf("expected 1 triage log, got %d", len(mockSt.triageLogs))
+	}
+	if got := mockSt.triageLogs[0].NotifiedVia; got != "other" {
+		t.Errorf("NotifiedVia = %q, want the notifier's name", got)
+	}
+}
+
+func TestPipelineReplayIssue(t *testing.T) {
+	p, mockSt, _, _, completer, _ := setupTestPipeline(t)
+	p.deps.SkipIfLabeled = true
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	result, action, err := p.ReplayIssue(context.Background(), "owner/repo",
+		github.Issue{Number: 1, Title: "Crash on start", State: "open", Labels: []string{"bug"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if completer.callCount == 0 {
+		t.Error("expected a replay to classify a labeled issue")
+	}
+	if action != "triaged" || result.IssueNumber != 1 {
+		t.Errorf("got action %q for %+v, want triaged", action, result)
+	}
+	if len(mockSt.triageLogs) != 1 || mockSt.triageLogs[0].Action != action {
+		t.Errorf("expected the replay to be logged as %q, got %+v", action, mockSt.triageLogs)
+	}
+}
+
+func TestPipelineReplayIssueOnlyMatchesEarlierIssues(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	embedder := newMockEmbedder()
+	completer := &mockCompleter{
+		response: `{"labels": ["bug"], "confidence": 0.9, "reasoning": "Bug report"}`,
+	}
+	p := New(PipelineDeps{
+		Dedup:      dedup.NewEngine(embedder, db, dedup.WithThreshold(0.5)),
+		Classifier: classify.NewClassifier(completer, 10*time.Second),
+		Notifier:   &mockNotifier{},
+		Store:      db,
+		Broker:     pubsub.NewBroker[github.IssueEvent](),
+		Labels:     testLabels(),
+		Logger:     slog.Default(),
+	})
+
+	repo, err := db.CreateRepo("owner", "repo")
+	if err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+	// Issues #1 and #3 were filed before and after the replayed #2, and
+	// both match it.
+	for _, n := range []int{1, 3} {
+		if err := db.UpsertIssue(&store.Issue{
+			RepoID:    repo.ID,
+			Number:    n,
+			Title:     fmt.Sprintf("Crash %d", n),
+			State:     "open",
+			Author:    "test",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("upserting issue: %v", err)
+		}
+		if err := db.UpdateEmbedding(repo.ID, n, dedup.EncodeEmbedding([]float32{0.1, 0.2, 0.3, 0.4}), "test-model"); err != nil {
+			t.Fatalf("updating embedding: %v", err)
+		}
+	}
+
+	result, action, err := p.ReplayIssue(context.Background(), "owner/repo",
+		github.Issue{Number: 2, Title: "Crash on start", State: "open"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action != "duplicate" {
+		t.Errorf("action = %q, want duplicate", action)
+	}
+	if len(result.Duplicates) != 1 || result.Duplicates[0].Number != 1 {
+		t.Errorf("expected only #1 as a duplicate, got %+v", result.Duplicates)
+	}
+
+	got, err := db.GetRepo(repo.ID)
+	if err != nil {
+		t.Fatalf("GetRepo: %v", err)
+	}
+	if got.Counters != (store.RepoCounters{}) {
+		t.Errorf("expected a replay to leave the repo counters alone, got %+v", got.Counters)
+	}
+}
+
+// countingStore is a mockStore that keeps repo counters.
+type countingStore struct {
+	*mockStore
+	counts store.RepoCounters
+	errs   []string
+}
+
+func (c *countingStore) AddRepoCounts(_ int64, delta store.RepoCounters) error {
+	c.counts.IssuesSeen += delta.IssuesSeen
+	c.counts.Triaged += delta.Triaged
+	c.counts.Duplicates += delta.Duplicates
+	return nil
+}
+
+func (c *countingStore) RecordRepoError(_ int64, msg string) error {
+	c.errs = append(c.errs, msg)
+	return nil
+}
+
+func TestPipelineUpdatesRepoCounters(t *testing.T) {
+	p, mockSt, _, embedder, completer, notifier := setupTestPipeline(t)
+	st := &countingStore{mockStore: mockSt}
+	p.deps.Store = st
+	p.deps.RepoConfigs = []config.RepoConfig{
+		{Name: "owner/repo", Ignore: &config.IgnoreRules{Labels: []string{"wontfix"}}},
+	}
+
+	ctx := context.Background()
+	if _, err := p.ProcessSingleIssue(ctx, "owner/repo", github.Issue{Number: 1, Title: "Crash on start", State: "open"}); err != nil {
+		t.Fatalf("ProcessSingleIssue: %v", err)
+	}
+	if _, err := p.ProcessSingleIssue(ctx, "owner/repo", github.Issue{Number: 2, Title: "Old", State: "open", Labels: []string{"wontfix"}}); !errors.Is(err, ErrIgnored) {
+		t.Fatalf("expected ErrIgnored, got %v", err)
+	}
+
+	// Failures are recorded as the repo's last error. They are permanent
+	// so the test does not wait out the retries.
+	completer.err = fmt.Errorf("llm down: %w", retry.ErrPermanent)
+	embedder.err = fmt.Errorf("embedder down: %w", retry.ErrPermanent)
+	notifier.err = fmt.Errorf("webhook down: %w", retry.ErrPermanent)
+	result, err := p.ProcessSingleIssue(ctx, "owner/repo", github.Issue{Number: 3, Title: "Another crash", State: "open"})
+	if !errors.Is(err, ErrIncomplete) || result == nil {
+		t.Fatalf("expected a result with ErrIncomplete, got %v, %v", result, err)
+	}
+	if !strings.Contains(err.Error(), "embedder down") || !strings.Contains(err.Error(), "llm down") {
+		t.Errorf("expected both step errors, got %v", err)
+	}
+
+	if st.counts.IssuesSeen != 3 || st.counts.Triaged != 2 || st.counts.Duplicates != 0 {
+		t.Errorf("unexpected counts: %+v", st.counts)
+	}
+	joined := strings.Join(st.errs, "\n")
+	for _, want := range []string{"issue #3: dedup: ", "classification: ", "llm down", "issue #3: notifying: "} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in the recorded errors, got:\n%s", want, joined)
+		}
+	}
+
+	// Dry runs record nothing
+	p.deps.DryRun = true
+	st.counts, st.errs = store.RepoCounters{}, nil
+	if _, err := p.ProcessSingleIssue(ctx, "owner/repo", github.Issue{Number: 4, Title: "Dry", State: "open"}); !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("expected ErrIncomplete, got %v", err)
+	}
+	if st.counts.IssuesSeen != 0 || len(st.errs) != 0 {
+		t.Errorf("expected no counter updates in a dry run, got %+v, %v", st.counts, st.errs)
+	}
+}
+
+func TestPipelineRemovedIssueLeavesDedupCache(t *testing.T) {
+	p, mockSt, _, embedder, _, _ := setupTestPipeline(t)
+	embStore := newMockEmbeddingStore()
+	p.deps.Dedup = dedup.NewEngine(embedder, embStore, dedup.WithCache())
+	repo, err := mockSt.CreateRepo("owner", "repo")
+	if err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+	_ = embStore.UpdateEmbedding(repo.ID, 7, dedup.EncodeEmbedding([]float32{0.1, 0.2, 0.3, 0.4}), "")
+	if err := p.deps.Dedup.Preload(repo.ID); err != nil {
+		t.Fatalf("Preload: %v", err)
+	}
+
+	// The poller has tombstoned #7, so the store no longer returns it
+	embStore.mu.Lock()
+	delete(embStore.embeddings[repo.ID], 7)
+	embStore.mu.Unlock()
+	p.handleEvent(context.Background(), pubsub.Event[github.IssueEvent]{
+		Type:    pubsub.Deleted,
+		Payload: github.IssueEvent{Repo: "owner/repo", Issue: github.Issue{Number: 7}, ChangeType: github.ChangeRemoved},
+	})
+
+	result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{Number: 8, Title: "Crash", State: "open"})
+	if err != nil {
+		t.Fatalf("ProcessSingleIssue: %v", err)
+	}
+	for _, d := range result.Duplicates {
+		if d.Number == 7 {
+			t.Errorf("expected the removed issue not to be suggested, got %+v", result.Duplicates)
+		}
+	}
+}
+
+func TestPipelineTransferSuggestion(t *testing.T) {
+	p, mockSt, _, embedder, _, _ := setupTestPipeline(t)
+	embStore := newMockEmbeddingStore()
+	p.deps.Dedup = dedup.NewEngine(embedder, embStore, dedup.WithThreshold(0.9))
+	p.deps.TransferSuggestions = true
+
+	docs, _ := mockSt.CreateRepo("myorg", "docs")
+	cli, _ := mockSt.CreateRepo("myorg", "cli")
+	other, _ := mockSt.CreateRepo("otherorg", "cli")
+	_ = embStore.UpdateEmbedding(docs.ID, 3, dedup.EncodeEmbedding([]float32{0, 1, 0}), "")
+	_ = embStore.UpdateEmbedding(cli.ID, 12, dedup.EncodeEmbedding([]float32{1, 0.05, 0}), "")
+	_ = embStore.UpdateEmbedding(other.ID, 7, dedup.EncodeEmbedding([]float32{1, 0, 0}), "")
+	embedder.embeddings["CLI crashes on start"] = []float32{1, 0, 0}
+
+	result, err := p.ProcessSingleIssue(context.Background(), "myorg/docs", github.Issue{
+		Number: 20,
+		Title:  "CLI crashes on start",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Transfer == nil || result.Transfer.Repo != "myorg/cli" || result.Transfer.Number != 12 {
+		t.Fatalf("expected transfer to myorg/cli#12, got %+v", result.Transfer)
+	}
+
+	// A closer match in the issue's own repo wins over the sibling repo.
+	_ = embStore.UpdateEmbedding(docs.ID, 4, dedup.EncodeEmbedding([]float32{1, 0, 0}), "")
+	result, err = p.ProcessSingleIssue(context.Background(), "myorg/docs", github.Issue{
+		Number: 21,
+		Title:  "CLI crashes on start",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Transfer != nil {
+		t.Errorf("expected no transfer when the own repo matches better, got %+v", result.Transfer)
+	}
+}
+
+// mockPullStore serves fixed open pull requests with precomputed embeddings.
+type mockPullStore struct {
+	prs []store.PullRequest
+}
+
+func (m *mockPullStore) ListOpenPullRequests(int64) ([]store.PullRequest, error) {
+	return m.prs, nil
+}
+
+func (m *mockPullStore) UpdatePullRequestEmbedding(int64, int, []byte, string) error {
+	return nil
+}
+
+func TestPipelinePullRequestMatch(t *testing.T) {
+	p, mockSt, _, embedder, _, _ := setupTestPipeline(t)
+	pulls := &mockPullStore{prs: []store.PullRequest{
+		{Number: 123, Title: "Fix crash", State: "open",
+			Embedding: dedup.EncodeEmbedding([]float32{1, 0.05, 0}), EmbeddingHash: dedup.ContentHash("Fix crash", "")},
+		{Number: 124, Title: "Docs", State: "open",
+			Embedding: dedup.EncodeEmbedding([]float32{0, 1, 0}), EmbeddingHash: dedup.ContentHash("Docs", "")},
+	}}
+	p.deps.Dedup = dedup.NewEngine(embedder, newMockEmbeddingStore(), dedup.WithThreshold(0.9), dedup.WithPullRequests(pulls))
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatal(err)
+	}
+	embedder.embeddings["App crashes on start"] = []float32{1, 0, 0}
+
+	result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{Number: 200, Title: "App crashes on start"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.PullRequests) != 1 || result.PullRequests[0].Number != 123 {
+		t.Errorf("expected PR #123, got %+v", result.PullRequests)
+	}
+	if len(result.Duplicates) != 0 {
+		t.Errorf("pull requests should not be reported as duplicates, got %+v", result.Duplicates)
+	}
+}
+
+func TestPipelineAbstainsBelowConfidenceThreshold(t *testing.T) {
+	tests := []struct {
+		name          string
+		abstain       bool
+		threshold     float64
+		wantAbstained bool
+		wantLabels    bool
+	}{
+		// Without abstaining, labels below the threshold are still dropped.
+		{"disabled", false, 0.95, false, false},
+		{"disabled, confident enough", false, 0.7, false, true},
+		{"confident enough", true, 0.7, false, true},
+		{"below threshold", true, 0.95, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mockSt, _, _, _, _ := setupTestPipeline(t)
+			p.deps.Abstain = tt.abstain
+			p.deps.ConfidenceThreshold = tt.threshold
+			if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+				t.Fatalf("creating repo: %v", err)
+			}
+
+			result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{Number: 1, Title: "Crash on start"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.NeedsHumanTriage != tt.wantAbstained {
+				t.Errorf("NeedsHumanTriage = %v, want %v", result.NeedsHumanTriage, tt.wantAbstained)
+			}
+			if !tt.wantLabels && len(result.SuggestedLabels) != 0 {
+				t.Errorf("expected no labels, got %v", result.SuggestedLabels)
+			}
+			if tt.wantLabels && len(result.SuggestedLabels) == 0 {
+				t.Error("expected suggested labels")
+			}
+
+			mockSt.mu.Lock()
+			defer mockSt.mu.Unlock()
+			wantAction := "triaged"
+			if tt.wantAbstained {
+				wantAction = "abstained"
+			}
+			if len(mockSt.triageLogs) != 1 || mockSt.triageLogs[0].Action != wantAction {
+				t.Errorf("expected one %q log entry, got %+v", wantAction, mockSt.triageLogs)
+			}
+		})
+	}
+}
+
+func TestPipelineParallelClassify(t *testing.T) {
+	tests := []struct {
+		name         string
+		parallel     bool
+		repoOverride *bool
+		wantLabels   bool
+	}{
+		{"sequential skips duplicates", false, nil, false},
+		{"parallel classifies duplicates", true, nil, true},
+		{"repo override enables", false, ptrBool(true), true},
+		{"repo override disables", true, ptrBool(false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mockSt, _, embedder, completer, _ := setupTestPipeline(t)
+			embStore := newMockEmbeddingStore()
+			p.deps.Dedup = dedup.NewEngine(embedder, embStore, dedup.WithThreshold(0.9))
+			p.deps.ParallelClassify = tt.parallel
+			p.deps.RepoConfigs = []config.RepoConfig{{Name: "owner/repo", ParallelClassify: tt.repoOverride}}
+
+			repo, _ := mockSt.CreateRepo("owner", "repo")
+			_ = embStore.UpdateEmbedding(repo.ID, 1, dedup.EncodeEmbedding([]float32{1, 0, 0}), "")
+			embedder.embeddings["Crash on start"] = []float32{1, 0, 0}
+
+			result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{Number: 2, Title: "Crash on start"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Duplicates) != 1 {
+				t.Fatalf("expected 1 duplicate, got %+v", result.Duplicates)
+			}
+			if got := len(result.SuggestedLabels) > 0; got != tt.wantLabels {
+				t.Errorf("labels suggested = %v, want %v", got, tt.wantLabels)
+			}
+			completer.mu.Lock()
+			defer completer.mu.Unlock()
+			if got := completer.callCount > 0; got != tt.wantLabels {
+				t.Errorf("classifier called = %v, want %v", got, tt.wantLabels)
+			}
+		})
+	}
+}
+
+func TestPipelineReusesEmbeddingAcrossSteps(t *testing.T) {
+	p, mockSt, _, embedder, _, _ := setupTestPipeline(t)
+	embStore := newMockEmbeddingStore()
+	p.deps.Dedup = dedup.NewEngine(embedder, embStore, dedup.WithThreshold(0.9))
+	p.deps.TransferSuggestions = true
+
+	docs, _ := mockSt.CreateRepo("myorg", "docs")
+	cli, _ := mockSt.CreateRepo("myorg", "cli")
+	_ = embStore.UpdateEmbedding(docs.ID, 3, dedup.EncodeEmbedding([]float32{0, 1, 0}), "")
+	_ = embStore.UpdateEmbedding(cli.ID, 12, dedup.EncodeEmbedding([]float32{1, 0.05, 0}), "")
+	embedder.embeddings["CLI crashes on start"] = []float32{1, 0, 0}
+
+	// A draft's embedding is never stored, so only the context can carry it
+	// from dedup to the transfer check.
+	result, err := p.ProcessDraft(context.Background(), "myorg/docs", github.Issue{Title: "CLI crashes on start"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Transfer == nil || result.Transfer.Repo != "myorg/cli" {
+		t.Fatalf("expected transfer to myorg/cli, got %+v", result.Transfer)
+	}
+	embedder.mu.Lock()
+	defer embedder.mu.Unlock()
+	if embedder.callCount != 1 {
+		t.Errorf("expected the draft to be embedded once, got %d calls", embedder.callCount)
+	}
+}
+
+// contextLimitCompleter fails with ErrContextLength for prompts longer than
+// limit bytes.
+type contextLimitCompleter struct {
+	limit int
+}
+
+func (c contextLimitCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	if len(prompt) > c.limit {
+		return "", fmt.Errorf("%w: prompt is too long", provider.ErrContextLength)
+	}
+	return `{"labels": ["bug"], "confidence": 0.9, "reasoning": "Bug report"}`, nil
+}
+
+func TestPipelineRecordsTruncation(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	p := New(PipelineDeps{
+		Dedup:      dedup.NewEngine(newMockEmbedder(), db),
+		Classifier: classify.NewClassifier(contextLimitCompleter{limit: 6000}, 10*time.Second),
+		Store:      db,
+		Broker:     pubsub.NewBroker[github.IssueEvent](),
+		Labels:     testLabels(),
+		Logger:     slog.Default(),
+	})
+	repo, err := db.CreateRepo("owner", "repo")
+	if err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{
+		Number: 1, Title: "Huge log", Body: strings.Repeat("x", 20000), State: "open",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Truncated {
+		t.Error("expected the result to be marked truncated")
+	}
+	logs, err := db.GetTriageLog(repo.ID, 1)
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("GetTriageLog: %v, %d entries", err, len(logs))
+	}
+	if !logs[0].Truncated {
+		t.Errorf("expected the triage log entry to be marked truncated, got %+v", logs[0])
+	}
+}
+
+func TestPipelineKeepsRawResponses(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		t.Run(fmt.Sprintf("keep=%t", keep), func(t *testing.T) {
+			db, err := store.Open(":memory:")
+			if err != nil {
+				t.Fatalf("opening test db: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+
+			completer := &mockCompleter{response: "I think this is a bug."}
+			p := New(PipelineDeps{
+				Dedup:            dedup.NewEngine(newMockEmbedder(), db),
+				Classifier:       classify.NewClassifier(completer, 10*time.Second),
+				Store:            db,
+				Broker:           pubsub.NewBroker[github.IssueEvent](),
+				Labels:           testLabels(),
+				Logger:           slog.Default(),
+				KeepRawResponses: keep,
+			})
+			repo, err := db.CreateRepo("owner", "repo")
+			if err != nil {
+				t.Fatalf("creating repo: %v", err)
+			}
+
+			if _, err := p.ProcessSingleIssue(context.Background(), "owner/repo", github.Issue{
+				Number: 1, Title: "Test issue", Body: "Test body", State: "open",
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			logs, err := db.GetTriageLog(repo.ID, 1)
+			if err != nil || len(logs) != 1 {
+				t.Fatalf("GetTriageLog: %v, %d entries", err, len(logs))
+			}
+			want := ""
+			if keep {
+				want = "I think this is a bug."
+			}
+			if logs[0].RawResponse != want {
+				t.Errorf("raw response = %q, want %q", logs[0].RawResponse, want)
+			}
+		})
+	}
+}
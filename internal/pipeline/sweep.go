@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// Sweep re-checks the open issues of repo created since since against the
+// repo's current embeddings and reports duplicates that were not found when
+// the issues were triaged, typically because the matching issue's embedding
+// arrived later (e.g. from a backfill). Only stored embeddings are used, so
+// no provider calls are made.
+//
+// Only earlier issues count as late duplicates: the later of two similar
+// issues is the duplicate. Issues that were never triaged are skipped.
+// Each finding is logged as a duplicate and notified, unless DryRun is set,
+// and returned.
+func (p *Pipeline) Sweep(ctx context.Context, repo string, since time.Time) ([]*github.TriageResult, error) {
+	if p.deps.Dedup == nil {
+		return nil, fmt.Errorf("sweep requires an embedding provider")
+	}
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid repo format: %s", repo)
+	}
+	repoRecord, err := p.deps.Store.GetRepoByOwnerRepo(owner, name)
+	if errors.Is(err, store.ErrRepoNotFound) {
+		return nil, fmt.Errorf("no stored issues for %s (run scan first): %w", repo, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up repo: %w", err)
+	}
+	issues, err := p.deps.Store.GetIssuesByRepo(repoRecord.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing issues for %s: %w", repo, err)
+	}
+
+	logger := p.deps.Logger.With("repo", repo, "sweep", true)
+	var thresholdOverride float32
+	if rc := p.findRepoConfig(ctx, repo, logger); rc != nil && rc.SimilarityThreshold != nil {
+		thresholdOverride = float32(*rc.SimilarityThreshold)
+	}
+
+	var findings []*github.TriageResult
+	for _, issue := range issues {
+		if err := ctx.Err(); err != nil {
+			return findings, err
+		}
+		if issue.State != "open" || issue.CreatedAt.Before(since) {
+			continue
+		}
+		logs, err := p.deps.Store.GetTriageLog(repoRecord.ID, issue.Number)
+		if err != nil {
+			return findings, fmt.Errorf("reading triage log for #%d: %w", issue.Number, err)
+		}
+		if len(logs) == 0 {
+			continue
+		}
+
+		result, err := p.deps.Dedup.CheckStored(repoRecord.ID, issue.Number, thresholdOverride)
+		if errors.Is(err, dedup.ErrNoEmbedding) {
+			continue
+		}
+		if err != nil {
+			logger.Warn("sweep dedup failed", "issue", issue.Number, "error", err)
+			continue
+		}
+
+		reported := reportedDuplicates(logs)
+		var late []github.DuplicateCandidate
+		for _, c := range result.Candidates {
+			if c.Number < issue.Number && !reported[c.Number] {
+				late = append(late, c)
+			}
+		}
+		if len(late) == 0 {
+			continue
+		}
+
+		finding := &github.TriageResult{
+			Repo:        repo,
+			IssueNumber: issue.Number,
+			IssueTitle:  issue.Title,
+			Duplicates:  late,
+			Reasoning:   "Late duplicate found by sweep",
+		}
+		findings = append(findings, finding)
+		p.logLateDuplicate(ctx, repoRecord.ID, finding, pendingLog(logs), logger.With("issue", issue.Number))
+	}
+	return findings, nil
+}
+
+// logLateDuplicate records and notifies a sweep finding. The entry logged
+// becomes the issue's latest, so it carries over the suggestion of pending,
+// the entry still awaiting a human decision if any, for apply to find.
+func (p *Pipeline) logLateDuplicate(ctx context.Context, repoID int64, finding *github.TriageResult, pending *store.TriageLog, logger *slog.Logger) {
+	refs := make([]string, len(finding.Duplicates))
+	for i, d := range finding.Duplicates {
+		refs[i] = fmt.Sprintf("#%d", d.Number)
+	}
+	duplicateOf := strings.Join(refs, ", ")
+
+	if p.deps.DryRun {
+		logger.Info("dry run: skipping late duplicate log and notification", "duplicate_of", duplicateOf)
+		return
+	}
+	logger.Info("late duplicate found", "duplicate_of", duplicateOf)
+	notifiedVia := p.notify(ctx, repoID, finding, logger)
+	entry := &store.TriageLog{
+		RepoID:      repoID,
+		IssueNumber: finding.IssueNumber,
+		Action:      "duplicate",
+		DuplicateOf: duplicateOf,
+		Reasoning:   finding.Reasoning,
+		NotifiedVia: notifiedVia,
+	}
+	if pending != nil {
+		entry.SuggestedLabels = pending.SuggestedLabels
+		entry.LabelConfidences = pending.LabelConfidences
+		entry.Variant = pending.Variant
+		entry.TriageID = pending.TriageID
+		if pending.DuplicateOf != "" {
+			entry.DuplicateOf = pending.DuplicateOf + ", " + duplicateOf
+		}
+	}
+	if err := p.deps.Store.LogTriageAction(entry); err != nil {
+		logger.Error("failed to log triage action", "error", err)
+	}
+}
+
+// pendingLog returns the latest of an issue's logs if it is a suggestion
+// awaiting a human decision, or nil.
+func pendingLog(logs []store.TriageLog) *store.TriageLog {
+	var latest *store.TriageLog
+	for i := range logs {
+		if latest == nil || logs[i].ID > latest.ID {
+			latest = &logs[i]
+		}
+	}
+	if latest == nil || latest.HumanDecision != "" || (latest.Action != "triaged" && latest.Action != "duplicate") {
+		return nil
+	}
+	return latest
+}
+
+// reportedDuplicates returns the issue numbers already reported as
+// duplicates in logs' DuplicateOf fields, e.g. "#12, #15".
+func reportedDuplicates(logs []store.TriageLog) map[int]bool {
+	reported := make(map[int]bool)
+	for _, l := range logs {
+		for _, ref := range strings.Split(l.DuplicateOf, ",") {
+			if n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(ref), "#")); err == nil {
+				reported[n] = true
+			}
+		}
+	}
+	return reported
+}
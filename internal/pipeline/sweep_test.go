@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/dedup"
+	"github.com/jacklau/triage/internal/store"
+)
+
+func TestPipelineSweep(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo, err := db.CreateRepo("owner", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	addIssue := func(number int, state string, created time.Time, vec []float32) {
+		t.Helper()
+		if err := db.UpsertIssue(&store.Issue{
+			RepoID: repo.ID, Number: number, Title: "issue", State: state,
+			CreatedAt: created, UpdatedAt: created,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if vec != nil {
+			if err := db.UpdateEmbedding(repo.ID, number, dedup.EncodeEmbedding(vec), "m"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	triaged := func(number int, duplicateOf string) {
+		t.Helper()
+		action := "triaged"
+		if duplicateOf != "" {
+			action = "duplicate"
+		}
+		if err := db.LogTriageAction(&store.TriageLog{
+			RepoID: repo.ID, IssueNumber: number, Action: action, DuplicateOf: duplicateOf,
+			SuggestedLabels: "bug", TriageID: fmt.Sprintf("t%d", number),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// #1 and #2 are old issues backfilled after #10-#13 were triaged.
+	addIssue(1, "open", now.Add(-90*24*time.Hour), []float32{1, 0, 0})
+	addIssue(2, "closed", now.Add(-60*24*time.Hour), []float32{0, 1, 0})
+	// #10 matches #1 but was triaged before #1 was embedded.
+	addIssue(10, "open", now.Add(-2*24*time.Hour), []float32{1, 0.01, 0})
+	triaged(10, "")
+	// #11 matches #2, which was already reported.
+	addIssue(11, "open", now.Add(-2*24*time.Hour), []float32{0, 1, 0.01})
+	triaged(11, "#2")
+	// #12 matches #1 but is older than the window.
+	addIssue(12, "open", now.Add(-30*24*time.Hour), []float32{1, 0, 0.01})
+	triaged(12, "")
+	// #13 matches #1 but was never triaged.
+	addIssue(13, "open", now.Add(-24*time.Hour), []float32{1, 0.02, 0})
+
+	notifier := &mockNotifier{}
+	p := New(PipelineDeps{
+		Dedup:    dedup.NewEngine(newMockEmbedder(), db, dedup.WithThreshold(0.9)),
+		Notifier: notifier,
+		Store:    db,
+		Logger:   slog.Default(),
+	})
+
+	findings, err := p.Sweep(context.Background(), "owner/repo", now.Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if len(findings) != 1 || findings[0].IssueNumber != 10 {
+		t.Fatalf("expected a single finding for #10, got %+v", findings)
+	}
+	var dups []int
+	for _, d := range findings[0].Duplicates {
+		dups = append(dups, d.Number)
+	}
+	if !reflect.DeepEqual(dups, []int{1}) {
+		t.Errorf("expected #10 to be a late duplicate of #1 only, got %v", dups)
+	}
+	if notifier.callCount != 1 {
+		t.Errorf("expected 1 notification, got %d", notifier.callCount)
+	}
+
+	logs, err := db.GetTriageLog(repo.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("expected late duplicate to be logged, got %+v", logs)
+	}
+
+	// The pending suggestion carries over to the late duplicate's entry
+	pending, err := db.ListPendingTriage(repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, entry := range pending {
+		if entry.IssueNumber != 10 {
+			continue
+		}
+		found = true
+		if entry.Action != "duplicate" || entry.DuplicateOf != "#1" || entry.SuggestedLabels != "bug" || entry.TriageID != "t10" {
+			t.Errorf("unexpected pending entry for #10: %+v", entry)
+		}
+	}
+	if !found {
+		t.Errorf("expected #10 to stay pending, got %+v", pending)
+	}
+
+	// A second sweep finds nothing new.
+	findings, err = p.Sweep(context.Background(), "owner/repo", now.Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("expected reported duplicates not to be found again, got %+v", findings)
+	}
+}
+
+func TestPipelineSweepUnknownRepo(t *testing.T) {
+	p := New(PipelineDeps{
+		Dedup: dedup.NewEngine(newMockEmbedder(), newMockEmbeddingStore()),
+		Store: newMockStore(),
+	})
+	if _, err := p.Sweep(context.Background(), "owner/missing", time.Time{}); err == nil {
+		t.Error("expected error for a repo with no stored issues")
+	}
+}
+
+func TestPendingLog(t *testing.T) {
+	logs := []store.TriageLog{
+		{ID: 1, Action: "triaged", SuggestedLabels: "bug"},
+		{ID: 2, Action: "duplicate", DuplicateOf: "#3"},
+	}
+	if got := pendingLog(logs); got == nil || got.ID != 2 {
+		t.Errorf("expected the latest entry, got %+v", got)
+	}
+	logs[1].HumanDecision = "approved"
+	if got := pendingLog(logs); got != nil {
+		t.Errorf("expected no pending entry once decided, got %+v", got)
+	}
+	if got := pendingLog([]store.TriageLog{{ID: 1, Action: "skipped"}}); got != nil {
+		t.Errorf("expected no pending entry for a skipped issue, got %+v", got)
+	}
+	if got := pendingLog(nil); got != nil {
+		t.Errorf("expected no pending entry without logs, got %+v", got)
+	}
+}
+
+func TestReportedDuplicates(t *testing.T) {
+	got := reportedDuplicates([]store.TriageLog{{DuplicateOf: "#12, #15"}, {DuplicateOf: ""}, {DuplicateOf: "#3"}})
+	want := map[int]bool{12: true, 15: true, 3: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reportedDuplicates() = %v, want %v", got, want)
+	}
+}
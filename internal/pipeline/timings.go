@@ -0,0 +1,58 @@
+package pipeline
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+// TimingStore records how long each stage of a triage took. A PipelineStore
+// that implements it has the timings of each processed issue recorded,
+// except in dry runs.
+type TimingStore interface {
+	LogTriageTimings(timings []store.TriageTiming) error
+}
+
+// stageTimings collects the stage timings of one triage. Stages may finish
+// concurrently, as classification does in parallel mode. A nil
+// *stageTimings records nothing.
+type stageTimings struct {
+	mu      sync.Mutex
+	timings []store.TriageTiming
+}
+
+// add records that stage took d with provider.
+func (t *stageTimings) add(stage, provider string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timings = append(t.timings, store.TriageTiming{Stage: stage, Provider: provider, Duration: d})
+}
+
+// since records that stage, started at start, took until now.
+func (t *stageTimings) since(stage, provider string, start time.Time) {
+	t.add(stage, provider, time.Since(start))
+}
+
+// logTimings records the stage timings of issue number's triage, if the
+// store keeps them.
+func (p *Pipeline) logTimings(repoID int64, number int, triageID string, t *stageTimings, logger *slog.Logger) {
+	timings, ok := p.deps.Store.(TimingStore)
+	if !ok || p.deps.DryRun {
+		return
+	}
+	t.mu.Lock()
+	records := make([]store.TriageTiming, len(t.timings))
+	for i, rec := range t.timings {
+		rec.RepoID, rec.IssueNumber, rec.TriageID = repoID, number, triageID
+		records[i] = rec
+	}
+	t.mu.Unlock()
+	if err := timings.LogTriageTimings(records); err != nil {
+		logger.Warn("failed to record triage timings", "error", err)
+	}
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// timingStore is a mockStore that keeps stage timings.
+type timingStore struct {
+	*mockStore
+	timings []store.TriageTiming
+}
+
+func (s *timingStore) LogTriageTimings(timings []store.TriageTiming) error {
+	s.timings = append(s.timings, timings...)
+	return nil
+}
+
+// stages returns the stage and provider of each recorded timing, keyed by
+// stage.
+func (s *timingStore) stages() map[string]string {
+	stages := make(map[string]string, len(s.timings))
+	for _, t := range s.timings {
+		stages[t.Stage] = t.Provider
+	}
+	return stages
+}
+
+func TestPipelineRecordsTimings(t *testing.T) {
+	p, mockSt, _, _, completer, _ := setupTestPipeline(t)
+	st := &timingStore{mockStore: mockSt}
+	p.deps.Store = st
+	p.deps.EmbeddingProvider = "ollama:nomic-embed-text"
+	p.deps.LLMProvider = "ollama:llama3"
+	if _, err := mockSt.CreateRepo("owner", "repo"); err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	issue := github.Issue{Number: 1, Title: "Crash", Body: "It crashes", State: "open"}
+	result, err := p.ProcessSingleIssue(context.Background(), "owner/repo", issue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		store.StageDedup:    "ollama:nomic-embed-text",
+		store.StageClassify: "ollama:llama3",
+		store.StageNotify:   "other",
+		store.StageTotal:    "",
+	}
+	got := st.stages()
+	if len(got) != len(want) {
+		t.Fatalf("got stages %v, want %v", got, want)
+	}
+	for stage, provider := range want {
+		if have, ok := got[stage]; !ok || have != provider {
+			t.Errorf("stage %s has provider %q, want %q", stage, have, provider)
+		}
+	}
+	for _, timing := range st.timings {
+		if timing.RepoID != 1 || timing.IssueNumber != 1 || timing.TriageID != result.TriageID {
+			t.Errorf("unexpected timing %+v", timing)
+		}
+	}
+
+	// A failed stage is not timed
+	st.timings = nil
+	completer.mu.Lock()
+	completer.err = errors.New("llm down")
+	completer.mu.Unlock()
+	if _, err := p.ProcessSingleIssue(context.Background(), "owner/repo", issue); !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("expected an incomplete triage, got %v", err)
+	}
+	if _, ok := st.stages()[store.StageClassify]; ok {
+		t.Errorf("expected failed classification not to be timed, got %+v", st.timings)
+	}
+
+	// Dry runs record nothing
+	st.timings = nil
+	p.deps.DryRun = true
+	if _, err := p.ProcessSingleIssue(context.Background(), "owner/repo", issue); !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("expected an incomplete triage, got %v", err)
+	}
+	if len(st.timings) != 0 {
+		t.Errorf("expected a dry run to record no timings, got %+v", st.timings)
+	}
+}
+
+func TestExperimentProviderTimed(t *testing.T) {
+	p, mockSt, _, _, _, _ := setupTestPipeline(t)
+	st := &timingStore{mockStore: mockSt}
+	p.deps.Store = st
+	p.deps.LLMProvider = "ollama:llama3"
+	p.deps.Experiment = &Experiment{Name: "alt", Percent: 100, Classifier: p.deps.Classifier, Provider: "openai:gpt-4o-mini"}
+
+	issue := github.Issue{Number: 2, Title: "Crash", Body: "It crashes", State: "open"}
+	if _, err := p.ProcessSingleIssue(context.Background(), "owner/repo", issue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := st.stages()[store.StageClassify]; got != "openai:gpt-4o-mini" {
+		t.Errorf("classify provider = %q, want the experiment's", got)
+	}
+}
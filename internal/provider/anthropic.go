@@ -4,50 +4,106 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 )
 
-const defaultAnthropicModel = "claude-sonnet-4-20250514"
+const (
+	defaultAnthropicModel     = "claude-sonnet-4-20250514"
+	defaultAnthropicMaxTokens = 1024
+)
 
 // AnthropicCompleter implements the Completer interface using the Anthropic API.
 type AnthropicCompleter struct {
-	client *anthropic.Client
-	model  string
+	client    *anthropic.Client
+	model     string
+	maxTokens int
+	stop      []string
+}
+
+// AnthropicOption configures an AnthropicCompleter.
+type AnthropicOption func(*AnthropicCompleter)
+
+// WithMaxTokens caps the length of each completion. Zero keeps the default
+// of 1024. Limits too large to answer within the API's non-streaming timeout
+// are streamed.
+func WithMaxTokens(n int) AnthropicOption {
+	return func(a *AnthropicCompleter) {
+		if n > 0 {
+			a.maxTokens = n
+		}
+	}
+}
+
+// WithStopSequences ends a completion at the first of stop it produces.
+func WithStopSequences(stop []string) AnthropicOption {
+	return func(a *AnthropicCompleter) {
+		a.stop = stop
+	}
 }
 
 // NewAnthropicCompleter creates a new AnthropicCompleter.
 // If model is empty, it defaults to claude-sonnet-4-20250514.
-func NewAnthropicCompleter(apiKey, model string) *AnthropicCompleter {
+func NewAnthropicCompleter(apiKey, model string, opts ...AnthropicOption) *AnthropicCompleter {
+	client := anthropic.NewClient(
+		option.WithAPIKey(apiKey),
+		option.WithHTTPClient(&http.Client{Transport: transport()}),
+	)
+	return newAnthropicCompleterWithClient(&client, model, opts...)
+}
+
+// newAnthropicCompleterWithClient creates an AnthropicCompleter using a
+// pre-configured client. This is useful for testing against a local server.
+func newAnthropicCompleterWithClient(client *anthropic.Client, model string, opts ...AnthropicOption) *AnthropicCompleter {
 	if model == "" {
 		model = defaultAnthropicModel
 	}
-	client := anthropic.NewClient(option.WithAPIKey(apiKey))
-	return &AnthropicCompleter{
-		client: &client,
-		model:  model,
+	a := &AnthropicCompleter{
+		client:    client,
+		model:     model,
+		maxTokens: defaultAnthropicMaxTokens,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // Complete sends a prompt to Anthropic and returns the text completion.
 func (a *AnthropicCompleter) Complete(ctx context.Context, prompt string) (string, error) {
-	msg, err := a.client.Messages.New(ctx, anthropic.MessageNewParams{
-		Model:     anthropic.Model(a.model),
-		MaxTokens: 1024,
+	return a.CompleteRequest(ctx, CompletionRequest{Prompt: prompt})
+}
+
+// CompleteRequest sends req to Anthropic, with its system prompt as the
+// request's system parameter, and returns the text completion.
+func (a *AnthropicCompleter) CompleteRequest(ctx context.Context, req CompletionRequest) (string, error) {
+	params := anthropic.MessageNewParams{
+		Model:         anthropic.Model(a.model),
+		MaxTokens:     int64(a.maxTokens),
+		StopSequences: a.stop,
 		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
+			anthropic.NewUserMessage(anthropic.NewTextBlock(req.Prompt)),
 		},
-	})
+	}
+	if req.System != "" {
+		params.System = []anthropic.TextBlockParam{{Text: req.System}}
+	}
+
+	var msg *anthropic.Message
+	var err error
+	if a.needsStreaming() {
+		msg, err = a.stream(ctx, params)
+	} else {
+		msg, err = a.client.Messages.New(ctx, params)
+	}
 	if err != nil {
-		// Check for rate limit errors
+		// Classify by status so callers know whether to retry
 		var apiErr *anthropic.Error
 		if errors.As(err, &apiErr) {
-			if apiErr.StatusCode == 429 {
-				return "", fmt.Errorf("%w: %s", ErrRateLimit, err)
-			}
-			if apiErr.StatusCode == 408 || apiErr.StatusCode == 504 {
-				return "", fmt.Errorf("%w: %s", ErrTimeout, err)
+			if classified := classifyStatus(apiErr.StatusCode, err); classified != nil {
+				return "", classified
 			}
 		}
 		if ctx.Err() != nil {
@@ -65,3 +121,28 @@ func (a *AnthropicCompleter) Complete(ctx context.Context, prompt string) (strin
 
 	return "", fmt.Errorf("%w: no text content in response", ErrInvalidResponse)
 }
+
+// needsStreaming reports whether a completion of up to maxTokens may take
+// longer than the API allows a non-streaming request.
+func (a *AnthropicCompleter) needsStreaming() bool {
+	_, err := anthropic.CalculateNonStreamingTimeout(a.maxTokens, anthropic.Model(a.model), nil)
+	return err != nil
+}
+
+// stream sends params as a streaming request and assembles the events into
+// the complete message.
+func (a *AnthropicCompleter) stream(ctx context.Context, params anthropic.MessageNewParams) (*anthropic.Message, error) {
+	stream := a.client.Messages.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	var msg anthropic.Message
+	for stream.Next() {
+		if err := msg.Accumulate(stream.Current()); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidResponse, err)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
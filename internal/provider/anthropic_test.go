@@ -1,6 +1,129 @@
 package provider
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// anthropicRequest is the part of a Messages API request the tests check.
+type anthropicRequest struct {
+	MaxTokens     int      `json:"max_tokens"`
+	StopSequences []string `json:"stop_sequences"`
+	Stream        bool     `json:"stream"`
+	System        []struct {
+		Text string `json:"text"`
+	} `json:"system"`
+}
+
+// newTestAnthropicCompleter returns a completer sending requests to a server
+// that records each request and replies with handler.
+func newTestAnthropicCompleter(t *testing.T, handler func(w http.ResponseWriter, req anthropicRequest), opts ...AnthropicOption) (*AnthropicCompleter, *anthropicRequest) {
+	t.Helper()
+	var got anthropicRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		handler(w, got)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := anthropic.NewClient(
+		option.WithAPIKey("test-key"),
+		option.WithBaseURL(srv.URL),
+		option.WithMaxRetries(0),
+	)
+	return newAnthropicCompleterWithClient(&client, "", opts...), &got
+}
+
+func writeAnthropicMessage(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[{"type":"text","text":%q}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":5}}`, text)
+}
+
+func TestAnthropicCompleter_RequestParams(t *testing.T) {
+	c, got := newTestAnthropicCompleter(t, func(w http.ResponseWriter, _ anthropicRequest) {
+		writeAnthropicMessage(w, `{"labels": ["bug"]}`)
+	}, WithMaxTokens(300), WithStopSequences([]string{"\n\n"}))
+
+	out, err := c.CompleteRequest(context.Background(), CompletionRequest{System: "Reply with JSON only.", Prompt: "Classify this issue"})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if out != `{"labels": ["bug"]}` {
+		t.Errorf("completion = %q", out)
+	}
+	if got.MaxTokens != 300 {
+		t.Errorf("max_tokens = %d, want 300", got.MaxTokens)
+	}
+	if !reflect.DeepEqual(got.StopSequences, []string{"\n\n"}) {
+		t.Errorf("stop_sequences = %q", got.StopSequences)
+	}
+	if len(got.System) != 1 || got.System[0].Text != "Reply with JSON only." {
+		t.Errorf("system = %+v, want the request's system prompt", got.System)
+	}
+	if got.Stream {
+		t.Error("expected a non-streaming request")
+	}
+}
+
+func TestAnthropicCompleter_Defaults(t *testing.T) {
+	c, got := newTestAnthropicCompleter(t, func(w http.ResponseWriter, _ anthropicRequest) {
+		writeAnthropicMessage(w, "ok")
+	}, WithMaxTokens(0))
+
+	if _, err := c.Complete(context.Background(), "hi"); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if got.MaxTokens != defaultAnthropicMaxTokens {
+		t.Errorf("max_tokens = %d, want the default %d", got.MaxTokens, defaultAnthropicMaxTokens)
+	}
+	if len(got.System) != 0 || len(got.StopSequences) != 0 {
+		t.Errorf("expected no system prompt or stop sequences, got %+v", got)
+	}
+}
+
+func TestAnthropicCompleter_StreamsLargeMaxTokens(t *testing.T) {
+	c, got := newTestAnthropicCompleter(t, func(w http.ResponseWriter, _ anthropicRequest) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[],"usage":{"input_tokens":10,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"{\"labels\": "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"[\"bug\"]}"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`,
+			`{"type":"message_stop"}`,
+		} {
+			var typ struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal([]byte(event), &typ)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typ.Type, event)
+		}
+	}, WithMaxTokens(64000))
+
+	out, err := c.Complete(context.Background(), "Explain at length")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if !got.Stream {
+		t.Error("expected a streaming request")
+	}
+	if out != `{"labels": ["bug"]}` {
+		t.Errorf("completion = %q, want the assembled stream", out)
+	}
+}
 
 func TestNewAnthropicCompleter_DefaultModel(t *testing.T) {
 	c := NewAnthropicCompleter("test-key", "")
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// EstimateTokens approximates the token count of text at four characters
+// per token, which is close enough for budgeting across common tokenizers.
+func EstimateTokens(text string) int64 {
+	return int64((len(text) + 3) / 4)
+}
+
+// Usage is a snapshot of the estimated tokens sent to and received from
+// providers, and of rate limit responses seen.
+type Usage struct {
+	EmbedTokens      int64
+	PromptTokens     int64
+	CompletionTokens int64
+	RateLimits       int64
+	LastRateLimit    time.Time
+}
+
+// Meter records estimated provider usage. Wrap providers with Embedder and
+// Completer to meter them; the wrappers are safe for concurrent use.
+type Meter struct {
+	embedTokens      atomic.Int64
+	promptTokens     atomic.Int64
+	completionTokens atomic.Int64
+	rateLimits       atomic.Int64
+	lastRateLimit    atomic.Int64 // unix nanos
+}
+
+// Usage returns the usage recorded so far.
+func (m *Meter) Usage() Usage {
+	u := Usage{
+		EmbedTokens:      m.embedTokens.Load(),
+		PromptTokens:     m.promptTokens.Load(),
+		CompletionTokens: m.completionTokens.Load(),
+		RateLimits:       m.rateLimits.Load(),
+	}
+	if ns := m.lastRateLimit.Load(); ns != 0 {
+		u.LastRateLimit = time.Unix(0, ns)
+	}
+	return u
+}
+
+func (m *Meter) observe(err error) {
+	if errors.Is(err, ErrRateLimit) {
+		m.rateLimits.Add(1)
+		m.lastRateLimit.Store(time.Now().UnixNano())
+	}
+}
+
+// Embedder returns e wrapped so its calls are metered. A nil e stays nil.
+func (m *Meter) Embedder(e Embedder) Embedder {
+	if e == nil {
+		return nil
+	}
+	return &meteredEmbedder{inner: e, meter: m}
+}
+
+// Completer returns c wrapped so its calls are metered. A nil c stays nil.
+func (m *Meter) Completer(c Completer) Completer {
+	if c == nil {
+		return nil
+	}
+	return &meteredCompleter{inner: c, meter: m}
+}
+
+type meteredEmbedder struct {
+	inner Embedder
+	meter *Meter
+}
+
+func (e *meteredEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vec, err := e.inner.Embed(ctx, text)
+	e.meter.observe(err)
+	if err == nil {
+		e.meter.embedTokens.Add(EstimateTokens(text))
+	}
+	return vec, err
+}
+
+func (e *meteredEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	b, ok := e.inner.(BatchEmbedder)
+	if !ok {
+		return EmbedBatchSequential(ctx, e, texts)
+	}
+	vecs, err := b.EmbedBatch(ctx, texts)
+	e.meter.observe(err)
+	if err == nil {
+		for _, t := range texts {
+			e.meter.embedTokens.Add(EstimateTokens(t))
+		}
+	}
+	return vecs, err
+}
+
+// Verify meteredEmbedder implements BatchEmbedder.
+var _ BatchEmbedder = (*meteredEmbedder)(nil)
+
+type meteredCompleter struct {
+	inner Completer
+	meter *Meter
+}
+
+func (c *meteredCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.CompleteRequest(ctx, CompletionRequest{Prompt: prompt})
+}
+
+func (c *meteredCompleter) CompleteRequest(ctx context.Context, req CompletionRequest) (string, error) {
+	out, err := CompleteRequest(ctx, c.inner, req)
+	c.meter.observe(err)
+	if err == nil {
+		c.meter.promptTokens.Add(EstimateTokens(req.Prompt) + EstimateTokens(req.System))
+		c.meter.completionTokens.Add(EstimateTokens(out))
+	}
+	return out, err
+}
+
+// Verify meteredCompleter implements RequestCompleter.
+var _ RequestCompleter = (*meteredCompleter)(nil)
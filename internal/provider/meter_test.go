@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type stubEmbedder struct{ err error }
+
+func (s *stubEmbedder) Embed(_ context.Context, _ string) ([]float32, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []float32{1, 0}, nil
+}
+
+type stubCompleter struct {
+	out string
+	err error
+}
+
+func (s *stubCompleter) Complete(_ context.Context, _ string) (string, error) {
+	return s.out, s.err
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{strings.Repeat("x", 400), 100},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%d chars) = %d, want %d", len(tt.text), got, tt.want)
+		}
+	}
+}
+
+func TestMeterCountsSuccessfulCalls(t *testing.T) {
+	var m Meter
+	e := m.Embedder(&stubEmbedder{})
+	c := m.Completer(&stubCompleter{out: strings.Repeat("o", 40)})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.Embed(context.Background(), strings.Repeat("e", 80))
+			c.Complete(context.Background(), strings.Repeat("p", 400))
+		}()
+	}
+	wg.Wait()
+
+	u := m.Usage()
+	if u.EmbedTokens != 200 || u.PromptTokens != 1000 || u.CompletionTokens != 100 {
+		t.Errorf("unexpected usage: %+v", u)
+	}
+	if u.RateLimits != 0 || !u.LastRateLimit.IsZero() {
+		t.Errorf("expected no rate limits, got %+v", u)
+	}
+}
+
+func TestMeterRecordsRateLimits(t *testing.T) {
+	var m Meter
+	e := m.Embedder(&stubEmbedder{err: fmt.Errorf("%w: HTTP 429", ErrRateLimit)})
+	c := m.Completer(&stubCompleter{err: ErrTimeout})
+
+	if _, err := e.Embed(context.Background(), "text"); err == nil {
+		t.Fatal("expected error to pass through")
+	}
+	c.Complete(context.Background(), "prompt")
+
+	u := m.Usage()
+	if u.RateLimits != 1 || u.LastRateLimit.IsZero() {
+		t.Errorf("expected one rate limit recorded, got %+v", u)
+	}
+	if u.EmbedTokens != 0 || u.PromptTokens != 0 {
+		t.Errorf("expected failed calls not to be counted, got %+v", u)
+	}
+}
+
+func TestMeterNilProviders(t *testing.T) {
+	var m Meter
+	if m.Embedder(nil) != nil || m.Completer(nil) != nil {
+		t.Error("expected nil providers to stay nil")
+	}
+}
+
+func TestMeteredEmbedBatchFallsBackToSequential(t *testing.T) {
+	var m Meter
+	e := m.Embedder(&stubEmbedder{}).(BatchEmbedder)
+	vecs, err := e.EmbedBatch(context.Background(), []string{"abcd", "abcdabcd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vecs) != 2 {
+		t.Fatalf("expected 2 vectors, got %d", len(vecs))
+	}
+	if got := m.Usage().EmbedTokens; got != 3 {
+		t.Errorf("expected 3 embed tokens, got %d", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,20 +22,22 @@ type OllamaEmbedder struct {
 	url    string
 	model  string
 	client *http.Client
+
+	// noBatch is set once the server turns out not to support /api/embed.
+	noBatch atomic.Bool
 }
 
-// NewOllamaEmbedder creates a new Ollama embedding provider.
+// NewOllamaEmbedder creates a new Ollama embedding provider. Requests are
+// bounded only by their context; see TimeoutEmbedder.
 // Supported models: "nomic-embed-text" (768 dims), "mxbai-embed-large" (1024 dims).
 func NewOllamaEmbedder(url, model string) *OllamaEmbedder {
 	// Normalize URL: strip trailing slash
 	url = strings.TrimRight(url, "/")
 
 	return &OllamaEmbedder{
-		url:   url,
-		model: model,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		url:    url,
+		model:  model,
+		client: &http.Client{Transport: transport()},
 	}
 }
 
@@ -77,20 +80,20 @@ func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, err
 		if ctx.Err() != nil {
 			return nil, fmt.Errorf("%w: %v", ErrTimeout, err)
 		}
-		return nil, fmt.Errorf("ollama embedding request: %w", err)
+		return nil, fmt.Errorf("%w: ollama embedding request: %w", ErrUnavailable, err)
 	}
 	defer func() {
 		io.Copy(io.Discard, resp.Body)
 		resp.Body.Close()
 	}()
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, fmt.Errorf("%w: ollama returned 429", ErrRateLimit)
-	}
-
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(respBody))
+		err := fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(respBody))
+		if classified := classifyStatus(resp.StatusCode, err); classified != nil {
+			return nil, classified
+		}
+		return nil, err
 	}
 
 	var result ollamaEmbeddingResponse
@@ -111,10 +114,97 @@ func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, err
 	return embedding, nil
 }
 
-// EmbedBatch returns vector embeddings for multiple texts by calling Embed sequentially.
-// Ollama does not support native batch embedding, so this falls back to sequential processing.
+// ollamaBatchRequest is the request body for Ollama's /api/embed, which
+// embeds several inputs in one call.
+type ollamaBatchRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaBatchResponse is the response body from Ollama's /api/embed.
+type ollamaBatchResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// EmbedBatch returns vector embeddings for multiple texts in one call to
+// Ollama's /api/embed. Servers older than that endpoint (before 0.3) answer
+// 404; the embedder then remembers to fall back to one /api/embeddings call
+// per text.
 func (e *OllamaEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
-	return EmbedBatchSequential(ctx, e, texts)
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	for i, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("cannot embed empty text at index %d", i)
+		}
+	}
+	if e.noBatch.Load() {
+		return EmbedBatchSequential(ctx, e, texts)
+	}
+
+	bodyBytes, err := json.Marshal(ollamaBatchRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling ollama request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url+"/api/embed", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("creating ollama request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTimeout, err)
+		}
+		return nil, fmt.Errorf("%w: ollama batch embedding request: %w", ErrUnavailable, err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		if isMissingEndpoint(resp.StatusCode, respBody) {
+			e.noBatch.Store(true)
+			return EmbedBatchSequential(ctx, e, texts)
+		}
+		err := fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(respBody))
+		if classified := classifyStatus(resp.StatusCode, err); classified != nil {
+			return nil, classified
+		}
+		return nil, err
+	}
+
+	var result ollamaBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: decoding ollama response: %v", ErrInvalidResponse, err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("%w: expected %d embeddings, got %d", ErrInvalidResponse, len(texts), len(result.Embeddings))
+	}
+	for i, vec := range result.Embeddings {
+		if len(vec) == 0 {
+			return nil, fmt.Errorf("%w: no embedding returned from ollama for text %d", ErrInvalidResponse, i)
+		}
+	}
+	return result.Embeddings, nil
+}
+
+// isMissingEndpoint reports whether an Ollama error response means the
+// server has no such endpoint, rather than, say, no such model: Ollama
+// reports API errors as JSON, but unknown routes as plain text.
+func isMissingEndpoint(status int, body []byte) bool {
+	if status != http.StatusNotFound && status != http.StatusMethodNotAllowed {
+		return false
+	}
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	return json.Unmarshal(body, &apiErr) != nil || apiErr.Error == ""
 }
 
 // Verify OllamaEmbedder implements BatchEmbedder.
@@ -129,7 +219,8 @@ type OllamaCompleter struct {
 
 // NewOllamaCompleter creates a new OllamaCompleter.
 // If url is empty, it defaults to http://localhost:11434.
-// If model is empty, it defaults to llama3.1:8b.
+// If model is empty, it defaults to llama3.1:8b. Requests are bounded only
+// by their context; see TimeoutCompleter.
 func NewOllamaCompleter(url, model string) *OllamaCompleter {
 	if url == "" {
 		url = defaultOllamaURL
@@ -138,17 +229,16 @@ func NewOllamaCompleter(url, model string) *OllamaCompleter {
 		model = defaultOllamaModel
 	}
 	return &OllamaCompleter{
-		url:   url,
-		model: model,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		url:    url,
+		model:  model,
+		client: &http.Client{Transport: transport()},
 	}
 }
 
 type ollamaCompletionRequest struct {
 	Model  string `json:"model"`
 	Prompt string `json:"prompt"`
+	System string `json:"system,omitempty"`
 	Stream bool   `json:"stream"`
 }
 
@@ -159,9 +249,16 @@ type ollamaCompletionResponse struct {
 
 // Complete sends a prompt to the Ollama server and returns the text completion.
 func (o *OllamaCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	return o.CompleteRequest(ctx, CompletionRequest{Prompt: prompt})
+}
+
+// CompleteRequest sends req to the Ollama server, with its system prompt as
+// the request's system field, and returns the text completion.
+func (o *OllamaCompleter) CompleteRequest(ctx context.Context, req CompletionRequest) (string, error) {
 	reqBody := ollamaCompletionRequest{
 		Model:  o.model,
-		Prompt: prompt,
+		Prompt: req.Prompt,
+		System: req.System,
 		Stream: false,
 	}
 
@@ -170,38 +267,35 @@ func (o *OllamaCompleter) Complete(ctx context.Context, prompt string) (string,
 		return "", fmt.Errorf("marshaling ollama request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url+"/api/generate", bytes.NewReader(bodyBytes))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url+"/api/generate", bytes.NewReader(bodyBytes))
 	if err != nil {
 		return "", fmt.Errorf("creating ollama request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := o.client.Do(req)
+	resp, err := o.client.Do(httpReq)
 	if err != nil {
 		if ctx.Err() != nil {
 			return "", fmt.Errorf("%w: %s", ErrTimeout, ctx.Err())
 		}
-		return "", fmt.Errorf("ollama request: %w", err)
+		return "", fmt.Errorf("%w: ollama request: %w", ErrUnavailable, err)
 	}
 	defer func() {
 		io.Copy(io.Discard, resp.Body)
 		resp.Body.Close()
 	}()
 
-	if resp.StatusCode == 429 {
-		return "", fmt.Errorf("%w: HTTP 429", ErrRateLimit)
-	}
-	if resp.StatusCode == 408 || resp.StatusCode == 504 {
-		return "", fmt.Errorf("%w: HTTP %d", ErrTimeout, resp.StatusCode)
-	}
-
 	respBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("reading ollama response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(respBytes))
+		err := fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(respBytes))
+		if classified := classifyStatus(resp.StatusCode, err); classified != nil {
+			return "", classified
+		}
+		return "", err
 	}
 
 	var ollamaResp ollamaCompletionResponse
@@ -215,3 +309,44 @@ func (o *OllamaCompleter) Complete(ctx context.Context, prompt string) (string,
 
 	return ollamaResp.Response, nil
 }
+
+// DefaultOllamaURL is the address of a local Ollama server.
+const DefaultOllamaURL = defaultOllamaURL
+
+// ProbeOllama checks for an Ollama server at url (DefaultOllamaURL if empty)
+// and returns the names of its installed models.
+func ProbeOllama(ctx context.Context, url string) ([]string, error) {
+	if url == "" {
+		url = defaultOllamaURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(url, "/")+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating ollama request: %w", err)
+	}
+	client := &http.Client{Timeout: 2 * time.Second, Transport: transport()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: contacting ollama: %w", ErrUnavailable, err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("%w: decoding ollama tags: %v", ErrInvalidResponse, err)
+	}
+	names := make([]string, len(tags.Models))
+	for i, m := range tags.Models {
+		names[i] = m.Name
+	}
+	return names, nil
+}
@@ -8,6 +8,8 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/jacklau/triage/internal/retry"
 )
 
 // --- Embedder tests ---
@@ -81,6 +83,9 @@ func TestOllamaEmbedder_HTTPError500(t *testing.T) {
 	if errors.Is(err, ErrRateLimit) {
 		t.Error("should not be rate limit error")
 	}
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got: %v", err)
+	}
 }
 
 func TestOllamaEmbedder_HTTPError503(t *testing.T) {
@@ -92,8 +97,19 @@ func TestOllamaEmbedder_HTTPError503(t *testing.T) {
 
 	embedder := NewOllamaEmbedder(srv.URL, "test-model")
 	_, err := embedder.Embed(context.Background(), "test text")
-	if err == nil {
-		t.Fatal("expected error for 503 response")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable for 503 response, got: %v", err)
+	}
+}
+
+func TestOllamaEmbedder_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	embedder := NewOllamaEmbedder(srv.URL, "test-model")
+	_, err := embedder.Embed(context.Background(), "test text")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable for a closed server, got: %v", err)
 	}
 }
 
@@ -208,6 +224,9 @@ func TestOllamaCompleter_Success(t *testing.T) {
 		if req.Prompt != "say hello" {
 			t.Errorf("expected prompt 'say hello', got %q", req.Prompt)
 		}
+		if req.System != "Be brief." {
+			t.Errorf("expected the request's system prompt, got %q", req.System)
+		}
 
 		resp := ollamaCompletionResponse{Response: "Hello, world!"}
 		w.Header().Set("Content-Type", "application/json")
@@ -216,7 +235,7 @@ func TestOllamaCompleter_Success(t *testing.T) {
 	defer srv.Close()
 
 	completer := NewOllamaCompleter(srv.URL, "llama3.1:8b")
-	got, err := completer.Complete(context.Background(), "say hello")
+	got, err := completer.CompleteRequest(context.Background(), CompletionRequest{System: "Be brief.", Prompt: "say hello"})
 	if err != nil {
 		t.Fatalf("Complete returned error: %v", err)
 	}
@@ -279,6 +298,52 @@ func TestOllamaCompleter_RateLimit429(t *testing.T) {
 	}
 }
 
+func TestOllamaCompleter_PermanentErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusNotFound, ErrBadRequest},
+		{http.StatusUnauthorized, ErrAuth},
+		{http.StatusForbidden, ErrAuth},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			completer := NewOllamaCompleter(srv.URL, "test-model")
+			_, err := completer.Complete(context.Background(), "test prompt")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got: %v", tt.want, err)
+			}
+			if !errors.Is(err, retry.ErrPermanent) {
+				t.Errorf("expected a permanent error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestOllamaCompleter_ContextLength(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"input exceeds maximum context length"}`))
+	}))
+	defer srv.Close()
+
+	completer := NewOllamaCompleter(srv.URL, "test-model")
+	_, err := completer.Complete(context.Background(), "test prompt")
+	if !errors.Is(err, ErrContextLength) {
+		t.Errorf("expected ErrContextLength, got: %v", err)
+	}
+	if !errors.Is(err, ErrBadRequest) {
+		t.Errorf("expected ErrContextLength to be a bad request, got: %v", err)
+	}
+}
+
 func TestOllamaCompleter_Timeout408(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusRequestTimeout)
@@ -399,3 +464,112 @@ func TestOllamaEmbedder_URLTrailingSlash(t *testing.T) {
 		t.Fatalf("Embed with trailing-slash URL returned error: %v", err)
 	}
 }
+
+func TestProbeOllama(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("expected path /api/tags, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"models":[{"name":"llama3:latest"},{"name":"nomic-embed-text:latest"}]}`))
+	}))
+	defer srv.Close()
+
+	models, err := ProbeOllama(context.Background(), srv.URL+"/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(models) != 2 || models[0] != "llama3:latest" || models[1] != "nomic-embed-text:latest" {
+		t.Errorf("unexpected models: %v", models)
+	}
+}
+
+func TestProbeOllama_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	if _, err := ProbeOllama(context.Background(), url); err == nil {
+		t.Error("expected error for an unreachable server")
+	}
+}
+
+func TestOllamaEmbedder_EmbedBatch(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("expected path /api/embed, got %s", r.URL.Path)
+		}
+		var req ollamaBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "nomic-embed-text" || len(req.Input) != 2 {
+			t.Errorf("unexpected request %+v", req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"nomic-embed-text","embeddings":[[0.1,0.2],[0.3,0.4]]}`))
+	}))
+	defer srv.Close()
+
+	embedder := NewOllamaEmbedder(srv.URL, "nomic-embed-text")
+	got, err := embedder.EmbedBatch(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected one request, got %d", calls)
+	}
+	if len(got) != 2 || got[1][0] != 0.3 {
+		t.Errorf("unexpected embeddings %v", got)
+	}
+}
+
+func TestOllamaEmbedder_EmbedBatchFallsBackOnOldServers(t *testing.T) {
+	var batchCalls, singleCalls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/embed":
+			batchCalls++
+			http.NotFound(w, r)
+		case "/api/embeddings":
+			singleCalls++
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"embedding":[0.5,0.6]}`))
+		}
+	}))
+	defer srv.Close()
+
+	embedder := NewOllamaEmbedder(srv.URL, "nomic-embed-text")
+	for range 2 {
+		got, err := embedder.EmbedBatch(context.Background(), []string{"first", "second"})
+		if err != nil {
+			t.Fatalf("EmbedBatch: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected 2 embeddings, got %d", len(got))
+		}
+	}
+	if batchCalls != 1 {
+		t.Errorf("expected /api/embed to be tried once, got %d", batchCalls)
+	}
+	if singleCalls != 4 {
+		t.Errorf("expected 4 /api/embeddings calls, got %d", singleCalls)
+	}
+}
+
+func TestOllamaEmbedder_EmbedBatchModelNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("unexpected fallback to %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"missing\" not found, try pulling it first"}`))
+	}))
+	defer srv.Close()
+
+	embedder := NewOllamaEmbedder(srv.URL, "missing")
+	if _, err := embedder.EmbedBatch(context.Background(), []string{"text"}); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
 	openai "github.com/sashabaranov/go-openai"
@@ -20,10 +21,18 @@ type OpenAIEmbedder struct {
 // NewOpenAIEmbedder creates a new OpenAI embedding provider.
 // Supported models: "text-embedding-3-small" (1536 dims), "text-embedding-3-large" (3072 dims).
 func NewOpenAIEmbedder(apiKey, model string) *OpenAIEmbedder {
-	client := openai.NewClient(apiKey)
+	client := newOpenAIClient(apiKey)
 	return newOpenAIEmbedderWithClient(client, model)
 }
 
+// newOpenAIClient creates an OpenAI client that identifies triage in its
+// User-Agent and sends the triage ID of each request.
+func newOpenAIClient(apiKey string) *openai.Client {
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.HTTPClient = &http.Client{Transport: transport()}
+	return openai.NewClientWithConfig(cfg)
+}
+
 // newOpenAIEmbedderWithClient creates an OpenAIEmbedder using a pre-configured client.
 // This is useful for testing with custom HTTP transports.
 func newOpenAIEmbedderWithClient(client *openai.Client, model string) *OpenAIEmbedder {
@@ -52,6 +61,12 @@ func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, err
 		Model: e.model,
 	})
 	if err != nil {
+		var apiErr *openai.APIError
+		if errors.As(err, &apiErr) {
+			if classified := classifyStatus(apiErr.HTTPStatusCode, err); classified != nil {
+				return nil, classified
+			}
+		}
 		// Check for rate limit errors by inspecting the error message.
 		if strings.Contains(err.Error(), "429") || strings.Contains(strings.ToLower(err.Error()), "rate limit") {
 			return nil, fmt.Errorf("%w: %v", ErrRateLimit, err)
@@ -87,6 +102,12 @@ func (e *OpenAIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]fl
 		Model: e.model,
 	})
 	if err != nil {
+		var apiErr *openai.APIError
+		if errors.As(err, &apiErr) {
+			if classified := classifyStatus(apiErr.HTTPStatusCode, err); classified != nil {
+				return nil, classified
+			}
+		}
 		if strings.Contains(err.Error(), "429") || strings.Contains(strings.ToLower(err.Error()), "rate limit") {
 			return nil, fmt.Errorf("%w: %v", ErrRateLimit, err)
 		}
@@ -120,7 +141,7 @@ type OpenAICompleter struct {
 // NewOpenAICompleter creates a new OpenAICompleter.
 // If model is empty, it defaults to gpt-4o-mini.
 func NewOpenAICompleter(apiKey, model string) *OpenAICompleter {
-	client := openai.NewClient(apiKey)
+	client := newOpenAIClient(apiKey)
 	return newOpenAICompleterWithClient(client, model)
 }
 
@@ -138,25 +159,34 @@ func newOpenAICompleterWithClient(client *openai.Client, model string) *OpenAICo
 
 // Complete sends a prompt to OpenAI and returns the text completion.
 func (o *OpenAICompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	return o.CompleteRequest(ctx, CompletionRequest{Prompt: prompt})
+}
+
+// CompleteRequest sends req to OpenAI, with its system prompt as a system
+// message, and returns the text completion.
+func (o *OpenAICompleter) CompleteRequest(ctx context.Context, req CompletionRequest) (string, error) {
+	var messages []openai.ChatCompletionMessage
+	if req.System != "" {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: req.System,
+		})
+	}
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: req.Prompt,
+	})
 	resp, err := o.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: o.model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
+		Model:     o.model,
+		Messages:  messages,
 		MaxTokens: 1024,
 	})
 	if err != nil {
-		// Check for rate limit errors
+		// Classify by status so callers know whether to retry
 		var apiErr *openai.APIError
 		if errors.As(err, &apiErr) {
-			if apiErr.HTTPStatusCode == 429 {
-				return "", fmt.Errorf("%w: %s", ErrRateLimit, err)
-			}
-			if apiErr.HTTPStatusCode == 408 || apiErr.HTTPStatusCode == 504 {
-				return "", fmt.Errorf("%w: %s", ErrTimeout, err)
+			if classified := classifyStatus(apiErr.HTTPStatusCode, err); classified != nil {
+				return "", classified
 			}
 		}
 		if ctx.Err() != nil {
@@ -4,15 +4,77 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jacklau/triage/internal/retry"
 )
 
-// Sentinel errors for provider operations.
+// Sentinel errors for provider operations. ErrBadRequest and ErrAuth wrap
+// retry.ErrPermanent, as repeating the same request cannot succeed.
 var (
 	ErrRateLimit       = errors.New("rate limit exceeded")
 	ErrTimeout         = errors.New("request timed out")
 	ErrInvalidResponse = errors.New("invalid response from provider")
+	ErrBadRequest      = fmt.Errorf("request rejected by provider: %w", retry.ErrPermanent)
+	ErrAuth            = fmt.Errorf("provider authentication failed: %w", retry.ErrPermanent)
+
+	// ErrContextLength is the ErrBadRequest returned when the prompt is too
+	// long for the model; a shorter prompt may succeed.
+	ErrContextLength = fmt.Errorf("prompt exceeds model context length: %w", ErrBadRequest)
+
+	// ErrUnavailable is returned when a provider cannot be reached or
+	// reports a server error; a later attempt may succeed.
+	ErrUnavailable = errors.New("provider unavailable")
 )
 
+// contextLengthMarkers are substrings, lowercased, of the messages providers
+// return when a prompt is too long for the model.
+var contextLengthMarkers = []string{
+	"context length",
+	"context_length_exceeded",
+	"context window",
+	"maximum context",
+	"prompt is too long",
+	"too many tokens",
+}
+
+// isContextLengthError reports whether err's message says the prompt was
+// too long for the model.
+func isContextLengthError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, m := range contextLengthMarkers {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyStatus wraps err with the sentinel error for an HTTP status code,
+// or returns nil if the status has none.
+func classifyStatus(status int, err error) error {
+	switch status {
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %s", ErrRateLimit, err)
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: %s", ErrTimeout, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrAuth, err)
+	case http.StatusRequestEntityTooLarge:
+		return fmt.Errorf("%w: %s", ErrContextLength, err)
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity:
+		if isContextLengthError(err) {
+			return fmt.Errorf("%w: %s", ErrContextLength, err)
+		}
+		return fmt.Errorf("%w: %s", ErrBadRequest, err)
+	}
+	if status >= 500 {
+		return fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	return nil
+}
+
 // Embedder generates vector embeddings from text.
 type Embedder interface {
 	// Embed returns a vector embedding for the given text.
@@ -51,6 +113,34 @@ type Completer interface {
 	Complete(ctx context.Context, prompt string) (string, error)
 }
 
+// CompletionRequest asks for a completion of Prompt. System, if set, holds
+// the instructions for the completion, which providers send in the API's
+// system role rather than as part of the prompt.
+type CompletionRequest struct {
+	System string
+	Prompt string
+}
+
+// RequestCompleter extends Completer with requests carrying a system
+// prompt. Providers whose API has a system role should implement this.
+type RequestCompleter interface {
+	Completer
+	// CompleteRequest returns a text completion for req.
+	CompleteRequest(ctx context.Context, req CompletionRequest) (string, error)
+}
+
+// CompleteRequest completes req with c. A Completer that is not a
+// RequestCompleter gets the system prompt ahead of the prompt.
+func CompleteRequest(ctx context.Context, c Completer, req CompletionRequest) (string, error) {
+	if rc, ok := c.(RequestCompleter); ok {
+		return rc.CompleteRequest(ctx, req)
+	}
+	if req.System == "" {
+		return c.Complete(ctx, req.Prompt)
+	}
+	return c.Complete(ctx, req.System+"\n\n"+req.Prompt)
+}
+
 // EmbedderConfig holds configuration for creating an Embedder.
 type EmbedderConfig struct {
 	Type   string
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// SwappableEmbedder forwards calls to an Embedder that can be replaced while
+// in use, such as when a rotated API key is picked up.
+type SwappableEmbedder struct {
+	inner atomic.Pointer[Embedder]
+}
+
+// NewSwappableEmbedder returns a SwappableEmbedder forwarding to e.
+func NewSwappableEmbedder(e Embedder) *SwappableEmbedder {
+	s := &SwappableEmbedder{}
+	s.Swap(e)
+	return s
+}
+
+// Swap makes later calls use e.
+func (s *SwappableEmbedder) Swap(e Embedder) {
+	s.inner.Store(&e)
+}
+
+func (s *SwappableEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return (*s.inner.Load()).Embed(ctx, text)
+}
+
+func (s *SwappableEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e := *s.inner.Load()
+	if b, ok := e.(BatchEmbedder); ok {
+		return b.EmbedBatch(ctx, texts)
+	}
+	return EmbedBatchSequential(ctx, e, texts)
+}
+
+// Verify SwappableEmbedder implements BatchEmbedder.
+var _ BatchEmbedder = (*SwappableEmbedder)(nil)
+
+// SwappableCompleter forwards calls to a Completer that can be replaced
+// while in use.
+type SwappableCompleter struct {
+	inner atomic.Pointer[Completer]
+}
+
+// NewSwappableCompleter returns a SwappableCompleter forwarding to c.
+func NewSwappableCompleter(c Completer) *SwappableCompleter {
+	s := &SwappableCompleter{}
+	s.Swap(c)
+	return s
+}
+
+// Swap makes later calls use c.
+func (s *SwappableCompleter) Swap(c Completer) {
+	s.inner.Store(&c)
+}
+
+func (s *SwappableCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	return (*s.inner.Load()).Complete(ctx, prompt)
+}
+
+func (s *SwappableCompleter) CompleteRequest(ctx context.Context, req CompletionRequest) (string, error) {
+	return CompleteRequest(ctx, *s.inner.Load(), req)
+}
+
+// Verify SwappableCompleter implements RequestCompleter.
+var _ RequestCompleter = (*SwappableCompleter)(nil)
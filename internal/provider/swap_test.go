@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+type constEmbedder []float32
+
+func (c constEmbedder) Embed(context.Context, string) ([]float32, error) { return c, nil }
+
+type constCompleter string
+
+func (c constCompleter) Complete(context.Context, string) (string, error) { return string(c), nil }
+
+func TestSwappableEmbedder(t *testing.T) {
+	s := NewSwappableEmbedder(constEmbedder{1})
+	if v, _ := s.Embed(context.Background(), "x"); v[0] != 1 {
+		t.Fatalf("Embed() = %v", v)
+	}
+	s.Swap(constEmbedder{2})
+	vecs, err := s.EmbedBatch(context.Background(), []string{"a", "b"})
+	if err != nil || len(vecs) != 2 || vecs[1][0] != 2 {
+		t.Errorf("EmbedBatch() after swap = %v, %v", vecs, err)
+	}
+}
+
+func TestSwappableCompleter(t *testing.T) {
+	s := NewSwappableCompleter(constCompleter("old"))
+	s.Swap(constCompleter("new"))
+	if out, _ := s.Complete(context.Background(), "x"); out != "new" {
+		t.Errorf("Complete() after swap = %q", out)
+	}
+}
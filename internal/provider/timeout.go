@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutEmbedder returns e wrapped so each call, single or batch, is
+// cancelled after d, and a call cut short returns ErrTimeout so it can be
+// retried. A hung request then cannot stall its caller indefinitely. A nil
+// e or non-positive d returns e unchanged.
+func TimeoutEmbedder(e Embedder, d time.Duration) Embedder {
+	if e == nil || d <= 0 {
+		return e
+	}
+	return &timeoutEmbedder{inner: e, timeout: d}
+}
+
+// TimeoutCompleter returns c wrapped so each call is cancelled after d, as
+// for TimeoutEmbedder. A nil c or non-positive d returns c unchanged.
+func TimeoutCompleter(c Completer, d time.Duration) Completer {
+	if c == nil || d <= 0 {
+		return c
+	}
+	return &timeoutCompleter{inner: c, timeout: d}
+}
+
+// callTimeout returns err for a call made with callCtx, derived from ctx
+// with timeout d. If the call ran out of time while ctx is still live, err
+// is wrapped in ErrTimeout; a cancelled ctx is left alone, so callers
+// stopping work do not retry.
+func callTimeout(ctx, callCtx context.Context, d time.Duration, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(callCtx.Err(), context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+		return err
+	}
+	return fmt.Errorf("%w after %s: %v", ErrTimeout, d, err)
+}
+
+type timeoutEmbedder struct {
+	inner   Embedder
+	timeout time.Duration
+}
+
+func (e *timeoutEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	vec, err := e.inner.Embed(callCtx, text)
+	return vec, callTimeout(ctx, callCtx, e.timeout, err)
+}
+
+func (e *timeoutEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	b, ok := e.inner.(BatchEmbedder)
+	if !ok {
+		return EmbedBatchSequential(ctx, e, texts)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	vecs, err := b.EmbedBatch(callCtx, texts)
+	return vecs, callTimeout(ctx, callCtx, e.timeout, err)
+}
+
+// Verify timeoutEmbedder implements BatchEmbedder.
+var _ BatchEmbedder = (*timeoutEmbedder)(nil)
+
+type timeoutCompleter struct {
+	inner   Completer
+	timeout time.Duration
+}
+
+func (c *timeoutCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.CompleteRequest(ctx, CompletionRequest{Prompt: prompt})
+}
+
+func (c *timeoutCompleter) CompleteRequest(ctx context.Context, req CompletionRequest) (string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	out, err := CompleteRequest(callCtx, c.inner, req)
+	return out, callTimeout(ctx, callCtx, c.timeout, err)
+}
+
+// Verify timeoutCompleter implements RequestCompleter.
+var _ RequestCompleter = (*timeoutCompleter)(nil)
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// hangingEmbedder blocks until its context is done.
+type hangingEmbedder struct{}
+
+func (hangingEmbedder) Embed(ctx context.Context, _ string) ([]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTimeoutEmbedder(t *testing.T) {
+	e := TimeoutEmbedder(hangingEmbedder{}, 10*time.Millisecond)
+
+	_, err := e.Embed(context.Background(), "text")
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+	_, err = e.(BatchEmbedder).EmbedBatch(context.Background(), []string{"a", "b"})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout from a batch, got %v", err)
+	}
+
+	// Cancelling the caller's context is not a timeout, so it is not retried
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Embed(ctx, "text"); errors.Is(err, ErrTimeout) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancellation, got %v", err)
+	}
+
+	if TimeoutEmbedder(nil, time.Second) != nil {
+		t.Error("expected a nil embedder to stay nil")
+	}
+	inner := &stubEmbedder{}
+	if TimeoutEmbedder(inner, 0) != Embedder(inner) {
+		t.Error("expected no wrapper without a timeout")
+	}
+}
+
+func TestTimeoutCompleterOllama(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := TimeoutCompleter(NewOllamaCompleter(srv.URL, "llama3"), 20*time.Millisecond)
+	start := time.Now()
+	_, err := c.Complete(context.Background(), "hello")
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the hung request to be cut off, took %s", elapsed)
+	}
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jacklau/triage/internal/buildinfo"
+)
+
+// TriageIDHeader is the request header carrying the triage ID of the run a
+// provider request is made for, so provider-side logs can be matched to it.
+const TriageIDHeader = "X-Triage-ID"
+
+type triageIDKey struct{}
+
+// WithTriageID returns ctx carrying the triage ID id. Provider requests
+// made with it send id in TriageIDHeader.
+func WithTriageID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, triageIDKey{}, id)
+}
+
+// TriageID returns the triage ID carried by ctx, or "".
+func TriageID(ctx context.Context) string {
+	id, _ := ctx.Value(triageIDKey{}).(string)
+	return id
+}
+
+// transport returns the round tripper provider clients use: it sets the
+// User-Agent and the triage ID header.
+func transport() http.RoundTripper {
+	return &triageIDTransport{base: buildinfo.Transport(nil)}
+}
+
+type triageIDTransport struct {
+	base http.RoundTripper
+}
+
+func (t *triageIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := TriageID(req.Context()); id != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(TriageIDHeader, id)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTriageID(t *testing.T) {
+	if got := TriageID(context.Background()); got != "" {
+		t.Errorf("TriageID of a bare context = %q, want empty", got)
+	}
+	if got := TriageID(WithTriageID(context.Background(), "abc123")); got != "abc123" {
+		t.Errorf("TriageID = %q, want abc123", got)
+	}
+}
+
+func TestTriageIDHeader(t *testing.T) {
+	var ids, agents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(TriageIDHeader))
+		agents = append(agents, r.Header.Get("User-Agent"))
+		json.NewEncoder(w).Encode(ollamaCompletionResponse{Response: "ok"})
+	}))
+	defer srv.Close()
+
+	completer := NewOllamaCompleter(srv.URL, "llama3.1:8b")
+	if _, err := completer.Complete(WithTriageID(context.Background(), "abc123"), "hi"); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if _, err := completer.Complete(context.Background(), "hi"); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "abc123" || ids[1] != "" {
+		t.Errorf("triage ID headers = %q, want [abc123 \"\"]", ids)
+	}
+	for _, ua := range agents {
+		if !strings.HasPrefix(ua, "triage/") {
+			t.Errorf("User-Agent = %q, want it to identify triage", ua)
+		}
+	}
+}
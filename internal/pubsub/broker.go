@@ -23,16 +23,21 @@ type Event[T any] struct {
 // subscriberBufferSize is the channel buffer size for each subscriber.
 const subscriberBufferSize = 64
 
+// highWaterMark is how many buffered events make a subscriber saturated;
+// see Saturated.
+const highWaterMark = subscriberBufferSize * 3 / 4
+
 // Broker is a generic, thread-safe publish/subscribe broker.
 type Broker[T any] struct {
-	mu   sync.RWMutex
-	subs map[chan Event[T]]struct{}
+	mu sync.RWMutex
+	// subs maps each subscriber's channel to its context.
+	subs map[chan Event[T]]context.Context
 }
 
 // NewBroker creates a new Broker.
 func NewBroker[T any]() *Broker[T] {
 	return &Broker[T]{
-		subs: make(map[chan Event[T]]struct{}),
+		subs: make(map[chan Event[T]]context.Context),
 	}
 }
 
@@ -43,7 +48,7 @@ func (b *Broker[T]) Subscribe(ctx context.Context) <-chan Event[T] {
 	ch := make(chan Event[T], subscriberBufferSize)
 
 	b.mu.Lock()
-	b.subs[ch] = struct{}{}
+	b.subs[ch] = ctx
 	b.mu.Unlock()
 
 	go func() {
@@ -73,3 +78,52 @@ func (b *Broker[T]) Publish(eventType EventType, payload T) {
 		}
 	}
 }
+
+// PublishWait broadcasts an event to all active subscribers like Publish, but
+// waits for room in each subscriber's buffer instead of dropping the event.
+// It returns ctx's error if ctx is done first. Use it where events come in
+// bursts that must not be lost, such as a replayed file.
+func (b *Broker[T]) PublishWait(ctx context.Context, eventType EventType, payload T) error {
+	evt := Event[T]{Type: eventType, Payload: payload}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch, subCtx := range b.subs {
+		select {
+		case ch <- evt:
+		case <-subCtx.Done():
+			// Unsubscribing
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribers returns the number of active subscriptions.
+func (b *Broker[T]) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Pending returns the number of events waiting in the fullest subscriber's
+// buffer: how far the slowest subscriber is behind.
+func (b *Broker[T]) Pending() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	pending := 0
+	for ch := range b.subs {
+		pending = max(pending, len(ch))
+	}
+	return pending
+}
+
+// Saturated reports whether the slowest subscriber's buffer is filled to
+// the high-water mark. Publishers that can hold off, such as a poller,
+// should pause until it is not, rather than publish events that Publish
+// would soon drop.
+func (b *Broker[T]) Saturated() bool {
+	return b.Pending() >= highWaterMark
+}
@@ -526,3 +526,109 @@ func TestStressManySubscribersCreatedAndDestroyed(t *testing.T) {
 		t.Errorf("expected 0 remaining subscribers, got %d", remaining)
 	}
 }
+
+func TestPublishWait(t *testing.T) {
+	broker := NewBroker[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if broker.Subscribers() != 0 {
+		t.Fatalf("expected no subscribers, got %d", broker.Subscribers())
+	}
+	ch := broker.Subscribe(ctx)
+	if broker.Subscribers() != 1 {
+		t.Fatalf("expected one subscriber, got %d", broker.Subscribers())
+	}
+
+	// Nothing is dropped once the buffer is full
+	total := subscriberBufferSize + 10
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < total; i++ {
+			if err := broker.PublishWait(context.Background(), Created, i); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	for i := 0; i < total; i++ {
+		select {
+		case evt := <-ch:
+			if evt.Payload != i {
+				t.Fatalf("event %d: got payload %d", i, evt.Payload)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out after %d events", i)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("PublishWait() error: %v", err)
+	}
+
+	// A full buffer blocks until the publisher gives up
+	for i := 0; i < subscriberBufferSize; i++ {
+		broker.Publish(Created, i)
+	}
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer waitCancel()
+	if err := broker.PublishWait(waitCtx, Created, 0); err != context.DeadlineExceeded {
+		t.Errorf("expected the publisher's deadline, got %v", err)
+	}
+
+	// or the subscriber leaves
+	subCtx, subCancel := context.WithCancel(context.Background())
+	_ = broker.Subscribe(subCtx)
+	cancel()
+	subCancel()
+	if err := broker.PublishWait(context.Background(), Created, 0); err != nil {
+		t.Errorf("expected cancelled subscribers to be skipped, got %v", err)
+	}
+}
+
+func TestPending(t *testing.T) {
+	broker := NewBroker[int]()
+	if got := broker.Pending(); got != 0 {
+		t.Errorf("Pending() without subscribers = %d, want 0", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slow := broker.Subscribe(ctx)
+	fast := broker.Subscribe(ctx)
+	for i := 0; i < 3; i++ {
+		broker.Publish(Created, i)
+	}
+	<-fast
+	<-fast
+	<-fast
+	<-slow
+	if got := broker.Pending(); got != 2 {
+		t.Errorf("Pending() = %d, want the slowest subscriber's 2", got)
+	}
+}
+
+func TestSaturated(t *testing.T) {
+	broker := NewBroker[int]()
+	if broker.Saturated() {
+		t.Error("expected a broker without subscribers not to be saturated")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := broker.Subscribe(ctx)
+	for i := 0; i < highWaterMark-1; i++ {
+		broker.Publish(Created, i)
+	}
+	if broker.Saturated() {
+		t.Errorf("expected %d pending events to be below the high-water mark", highWaterMark-1)
+	}
+	broker.Publish(Created, highWaterMark)
+	if !broker.Saturated() {
+		t.Errorf("expected %d pending events to saturate the broker", highWaterMark)
+	}
+	<-ch
+	if broker.Saturated() {
+		t.Error("expected the broker to recover once the subscriber reads")
+	}
+}
@@ -0,0 +1,202 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+// MaxClusters is the number of duplicate clusters listed in a report.
+const MaxClusters = 5
+
+// Report summarizes triage activity for one repository over a period.
+type Report struct {
+	Repo  string
+	Since time.Time
+	Until time.Time
+
+	IssuesTriaged int
+	Duplicates    int
+	Clusters      []Cluster    // largest first, at most MaxClusters
+	Labels        []LabelCount // most suggested first
+
+	// Abstained counts issues the classifier suggested no labels for
+	// because its confidence was too low.
+	Abstained int
+
+	// AvgConfidence is the mean confidence of suggested labels, or 0 if no
+	// labels were suggested.
+	AvgConfidence float64
+
+	// Reviewed counts suggestions a human approved or rejected; Overridden
+	// counts the rejected ones.
+	Reviewed   int
+	Overridden int
+}
+
+// Cluster is a group of issues flagged as duplicates of the same issue.
+type Cluster struct {
+	Target int   // the issue the others duplicate
+	Issues []int // duplicates of Target, ascending
+}
+
+// LabelCount is the number of issues a label was suggested for.
+type LabelCount struct {
+	Name  string
+	Count int
+}
+
+// OverrideRate returns the fraction of reviewed suggestions a human
+// rejected, or false if none were reviewed.
+func (r *Report) OverrideRate() (float64, bool) {
+	if r.Reviewed == 0 {
+		return 0, false
+	}
+	return float64(r.Overridden) / float64(r.Reviewed), true
+}
+
+// Build summarizes triage log entries for repo. Only the latest triage,
+// duplicate, or abstention entry for each issue is counted, so re-triaged
+// issues are not counted twice; other actions (such as applied labels) are
+// ignored.
+func Build(repo string, since, until time.Time, logs []store.TriageLog) *Report {
+	latest := make(map[int]store.TriageLog)
+	for _, l := range logs {
+		if l.Action != "triaged" && l.Action != "duplicate" && l.Action != "abstained" {
+			continue
+		}
+		if prev, ok := latest[l.IssueNumber]; !ok || l.ID > prev.ID {
+			latest[l.IssueNumber] = l
+		}
+	}
+
+	r := &Report{Repo: repo, Since: since, Until: until, IssuesTriaged: len(latest)}
+
+	clusters := make(map[int][]int)
+	labels := make(map[string]int)
+	var confSum float64
+	var confCount int
+	for _, l := range latest {
+		if l.Action == "abstained" {
+			r.Abstained++
+		}
+		if l.Action == "duplicate" {
+			r.Duplicates++
+			if target, ok := firstIssueRef(l.DuplicateOf); ok {
+				clusters[target] = append(clusters[target], l.IssueNumber)
+			}
+		}
+		for _, name := range strings.Split(l.SuggestedLabels, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				labels[name]++
+			}
+		}
+		for _, c := range l.LabelConfidences {
+			confSum += c
+			confCount++
+		}
+		switch l.HumanDecision {
+		case store.DecisionApproved:
+			r.Reviewed++
+		case store.DecisionRejected:
+			r.Reviewed++
+			r.Overridden++
+		}
+	}
+
+	if confCount > 0 {
+		r.AvgConfidence = confSum / float64(confCount)
+	}
+
+	for target, issues := range clusters {
+		sort.Ints(issues)
+		r.Clusters = append(r.Clusters, Cluster{Target: target, Issues: issues})
+	}
+	sort.Slice(r.Clusters, func(i, j int) bool {
+		a, b := r.Clusters[i], r.Clusters[j]
+		if len(a.Issues) != len(b.Issues) {
+			return len(a.Issues) > len(b.Issues)
+		}
+		return a.Target < b.Target
+	})
+	if len(r.Clusters) > MaxClusters {
+		r.Clusters = r.Clusters[:MaxClusters]
+	}
+
+	for name, n := range labels {
+		r.Labels = append(r.Labels, LabelCount{Name: name, Count: n})
+	}
+	sort.Slice(r.Labels, func(i, j int) bool {
+		if r.Labels[i].Count != r.Labels[j].Count {
+			return r.Labels[i].Count > r.Labels[j].Count
+		}
+		return r.Labels[i].Name < r.Labels[j].Name
+	})
+
+	return r
+}
+
+// firstIssueRef parses the first "#N" reference in a duplicate_of value such
+// as "#12, #40".
+func firstIssueRef(s string) (int, bool) {
+	first, _, _ := strings.Cut(s, ",")
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(first), "#"))
+	return n, err == nil
+}
+
+// Title returns the report heading, e.g. "Triage report: org/repo (Mar 3 – Mar 10)".
+func (r *Report) Title() string {
+	return fmt.Sprintf("Triage report: %s (%s – %s)",
+		r.Repo, r.Since.Format("Jan 2"), r.Until.Format("Jan 2"))
+}
+
+// Markdown renders the report body as Markdown, without the title.
+func (r *Report) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "- **Issues triaged:** %d\n", r.IssuesTriaged)
+	fmt.Fprintf(&b, "- **Flagged as duplicates:** %d\n", r.Duplicates)
+	if r.Abstained > 0 {
+		fmt.Fprintf(&b, "- **Needed human triage:** %d (%s)\n", r.Abstained,
+			percent(float64(r.Abstained)/float64(r.IssuesTriaged)))
+	}
+	if r.AvgConfidence > 0 {
+		fmt.Fprintf(&b, "- **Average label confidence:** %s\n", percent(r.AvgConfidence))
+	} else {
+		b.WriteString("- **Average label confidence:** n/a\n")
+	}
+	if rate, ok := r.OverrideRate(); ok {
+		fmt.Fprintf(&b, "- **Human override rate:** %s (%d of %d reviewed)\n", percent(rate), r.Overridden, r.Reviewed)
+	} else {
+		b.WriteString("- **Human override rate:** n/a (no suggestions reviewed)\n")
+	}
+
+	if len(r.Clusters) > 0 {
+		b.WriteString("\n**Top duplicate clusters**\n")
+		for _, c := range r.Clusters {
+			refs := make([]string, len(c.Issues))
+			for i, n := range c.Issues {
+				refs[i] = fmt.Sprintf("#%d", n)
+			}
+			fmt.Fprintf(&b, "- #%d ← %s\n", c.Target, strings.Join(refs, ", "))
+		}
+	}
+
+	if len(r.Labels) > 0 {
+		b.WriteString("\n**Label distribution**\n")
+		for _, l := range r.Labels {
+			fmt.Fprintf(&b, "- `%s`: %d\n", l.Name, l.Count)
+		}
+	}
+
+	return b.String()
+}
+
+func percent(f float64) string {
+	return fmt.Sprintf("%d%%", int(math.Round(f*100)))
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/store"
+)
+
+func testLogs() []store.TriageLog {
+	return []store.TriageLog{
+		{ID: 1, IssueNumber: 10, Action: "triaged", SuggestedLabels: "bug",
+			LabelConfidences: map[string]float64{"bug": 0.5}},
+		// Re-triaged: only this entry counts for #10.
+		{ID: 2, IssueNumber: 10, Action: "triaged", SuggestedLabels: "bug, ui",
+			LabelConfidences: map[string]float64{"bug": 0.9, "ui": 0.7}, HumanDecision: store.DecisionApproved},
+		{ID: 3, IssueNumber: 11, Action: "triaged", SuggestedLabels: "bug",
+			LabelConfidences: map[string]float64{"bug": 0.8}, HumanDecision: store.DecisionRejected},
+		{ID: 4, IssueNumber: 12, Action: "duplicate", DuplicateOf: "#5, #7"},
+		{ID: 5, IssueNumber: 13, Action: "duplicate", DuplicateOf: "#5"},
+		{ID: 6, IssueNumber: 14, Action: "duplicate", DuplicateOf: "#9"},
+		{ID: 7, IssueNumber: 10, Action: "apply_labels", SuggestedLabels: "bug"},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r := Build("org/repo", time.Now().Add(-7*24*time.Hour), time.Now(), testLogs())
+
+	if r.IssuesTriaged != 5 {
+		t.Errorf("IssuesTriaged = %d, want 5", r.IssuesTriaged)
+	}
+	if r.Duplicates != 3 {
+		t.Errorf("Duplicates = %d, want 3", r.Duplicates)
+	}
+	if len(r.Clusters) != 2 || r.Clusters[0].Target != 5 || len(r.Clusters[0].Issues) != 2 || r.Clusters[1].Target != 9 {
+		t.Errorf("unexpected clusters: %+v", r.Clusters)
+	}
+	if len(r.Labels) != 2 || r.Labels[0] != (LabelCount{"bug", 2}) || r.Labels[1] != (LabelCount{"ui", 1}) {
+		t.Errorf("unexpected labels: %+v", r.Labels)
+	}
+	if r.AvgConfidence < 0.799 || r.AvgConfidence > 0.801 {
+		t.Errorf("AvgConfidence = %v, want 0.8", r.AvgConfidence)
+	}
+	if rate, ok := r.OverrideRate(); !ok || rate != 0.5 {
+		t.Errorf("OverrideRate() = %v, %v; want 0.5, true", rate, ok)
+	}
+}
+
+func TestBuildCountsAbstentions(t *testing.T) {
+	logs := append(testLogs(),
+		store.TriageLog{ID: 8, IssueNumber: 15, Action: "abstained"},
+		// Re-triaged with enough confidence: no longer an abstention.
+		store.TriageLog{ID: 9, IssueNumber: 16, Action: "abstained"},
+		store.TriageLog{ID: 10, IssueNumber: 16, Action: "triaged", SuggestedLabels: "ui"},
+	)
+	r := Build("org/repo", time.Now(), time.Now(), logs)
+	if r.IssuesTriaged != 7 || r.Abstained != 1 {
+		t.Errorf("IssuesTriaged, Abstained = %d, %d; want 7, 1", r.IssuesTriaged, r.Abstained)
+	}
+	if md := r.Markdown(); !strings.Contains(md, "**Needed human triage:** 1 (14%)") {
+		t.Errorf("markdown missing abstentions:\n%s", md)
+	}
+}
+
+func TestBuildLimitsClusters(t *testing.T) {
+	var logs []store.TriageLog
+	for i := 0; i < MaxClusters+2; i++ {
+		logs = append(logs, store.TriageLog{ID: int64(i + 1), IssueNumber: 100 + i, Action: "duplicate", DuplicateOf: fmt.Sprintf("#%d", i+1)})
+	}
+	r := Build("org/repo", time.Time{}, time.Time{}, logs)
+	if len(r.Clusters) != MaxClusters {
+		t.Errorf("expected %d clusters, got %d", MaxClusters, len(r.Clusters))
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	r := Build("org/repo", time.Now(), time.Now(), testLogs())
+	md := r.Markdown()
+	for _, want := range []string{
+		"**Issues triaged:** 5",
+		"**Flagged as duplicates:** 3",
+		"**Average label confidence:** 80%",
+		"**Human override rate:** 50% (1 of 2 reviewed)",
+		"- #5 ← #12, #13",
+		"- `bug`: 2",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownEmpty(t *testing.T) {
+	md := Build("org/repo", time.Now(), time.Now(), nil).Markdown()
+	if !strings.Contains(md, "n/a (no suggestions reviewed)") {
+		t.Errorf("expected n/a override rate, got:\n%s", md)
+	}
+	if strings.Contains(md, "clusters") || strings.Contains(md, "Label distribution") {
+		t.Errorf("expected empty sections to be omitted, got:\n%s", md)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	since := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	r := &Report{Repo: "org/repo", Since: since, Until: since.AddDate(0, 0, 7)}
+	if got := r.Title(); got != "Triage report: org/repo (Mar 3 – Mar 10)" {
+		t.Errorf("Title() = %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"math"
 	"math/rand/v2"
 	"time"
@@ -21,8 +22,13 @@ const (
 	jitterFraction = 0.25
 )
 
+// ErrPermanent marks errors that retrying cannot fix, such as a rejected
+// request or bad credentials. Wrap it to make Do stop at once.
+var ErrPermanent = errors.New("permanent error")
+
 // Do retries fn up to maxAttempts times with exponential backoff and jitter.
 // It respects context cancellation and returns the last error if all attempts fail.
+// An error wrapping ErrPermanent is returned immediately without retrying.
 // The backoff progression is: 1s, 2s, 4s (with up to 25% jitter).
 func Do(ctx context.Context, maxAttempts int, fn func() error) error {
 	if maxAttempts <= 0 {
@@ -39,6 +45,9 @@ func Do(ctx context.Context, maxAttempts int, fn func() error) error {
 		if lastErr == nil {
 			return nil
 		}
+		if errors.Is(lastErr, ErrPermanent) {
+			return lastErr
+		}
 
 		// Don't sleep after the last attempt.
 		if attempt < maxAttempts-1 {
@@ -3,6 +3,7 @@ package retry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -60,6 +61,22 @@ func TestDoExceedsMaxRetries(t *testing.T) {
 	}
 }
 
+func TestDoStopsOnPermanentError(t *testing.T) {
+	var calls int
+	permErr := fmt.Errorf("bad request: %w", ErrPermanent)
+
+	err := Do(context.Background(), 3, func() error {
+		calls++
+		return permErr
+	})
+	if !errors.Is(err, permErr) {
+		t.Errorf("expected permanent error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
 func TestDoRespectsContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	var calls atomic.Int32
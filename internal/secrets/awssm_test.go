@@ -0,0 +1,85 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignV4 checks the signer against the example request in the AWS
+// Signature Version 4 documentation.
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	signV4(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "iam",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want)
+	}
+}
+
+func newTestAWS(t *testing.T, h http.HandlerFunc) *AWSSecretsManager {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return &AWSSecretsManager{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Endpoint:        srv.URL,
+		Client:          srv.Client(),
+	}
+}
+
+func TestAWSSecretsManagerGet(t *testing.T) {
+	var gotID string
+	a := newTestAWS(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.Contains(r.Header.Get("Authorization"), "/us-west-2/secretsmanager/aws4_request") {
+			t.Errorf("unexpected request headers: %v", r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var in struct{ SecretId string }
+		json.Unmarshal(body, &in)
+		gotID = in.SecretId
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"openai":"sk-1","other":"x"}`})
+	})
+
+	arn := "arn:aws:secretsmanager:us-west-2:123456789012:secret:triage"
+	v, err := a.Get(context.Background(), arn, "openai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "sk-1" || gotID != arn {
+		t.Errorf("Get() = %q for %q", v, gotID)
+	}
+
+	if _, err := a.Get(context.Background(), arn, "missing"); err == nil || !strings.Contains(err.Error(), "no field") {
+		t.Errorf("expected missing field error, got %v", err)
+	}
+}
+
+func TestAWSSecretsManagerErrors(t *testing.T) {
+	a := newTestAWS(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`)
+	})
+	if _, err := a.Get(context.Background(), "nope", ""); err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Errorf("expected AWS error type in error, got %v", err)
+	}
+
+	a.AccessKeyID = ""
+	if _, err := a.Get(context.Background(), "nope", ""); err == nil || !strings.Contains(err.Error(), "AWS_ACCESS_KEY_ID") {
+		t.Errorf("expected missing credentials error, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+// Package secrets resolves references to secrets held in external secret
+// managers, such as vault://secret/openai#key, so config files and the
+// environment need not contain raw credentials.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Ref is a parsed secret reference of the form scheme://path#key.
+type Ref struct {
+	Scheme string // "vault" or "aws-sm"
+	Path   string // secret path or name
+	Key    string // field within the secret; may be empty
+}
+
+// String returns the reference in its original form.
+func (r Ref) String() string {
+	s := r.Scheme + "://" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// Backend fetches the value of a secret. If key is non-empty it names a
+// field of the secret; otherwise the secret must hold a single value.
+type Backend interface {
+	Get(ctx context.Context, path, key string) (string, error)
+}
+
+// ParseRef parses s as a secret reference. It returns false if s does not
+// use one of the supported schemes, so ordinary values pass through.
+func ParseRef(s string) (Ref, bool) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || (scheme != "vault" && scheme != "aws-sm") {
+		return Ref{}, false
+	}
+	path, key, _ := strings.Cut(rest, "#")
+	return Ref{Scheme: scheme, Path: path, Key: key}, true
+}
+
+// IsRef reports whether s is a secret reference.
+func IsRef(s string) bool {
+	_, ok := ParseRef(s)
+	return ok
+}
+
+// Resolver resolves secret references using a backend per scheme.
+type Resolver struct {
+	backends map[string]Backend
+}
+
+// NewResolver returns a resolver for vault:// and aws-sm:// references,
+// configured from the standard VAULT_* and AWS_* environment variables.
+func NewResolver() *Resolver {
+	return NewResolverWith(map[string]Backend{
+		"vault":  VaultFromEnv(),
+		"aws-sm": AWSSecretsManagerFromEnv(),
+	})
+}
+
+// NewResolverWith returns a resolver using the given backends, keyed by scheme.
+func NewResolverWith(backends map[string]Backend) *Resolver {
+	return &Resolver{backends: backends}
+}
+
+// Resolve returns the secret s refers to, or s itself if it is not a
+// reference.
+func (r *Resolver) Resolve(ctx context.Context, s string) (string, error) {
+	ref, ok := ParseRef(s)
+	if !ok {
+		return s, nil
+	}
+	if ref.Path == "" {
+		return "", fmt.Errorf("secret reference %s has no path", ref)
+	}
+	b, ok := r.backends[ref.Scheme]
+	if !ok {
+		return "", fmt.Errorf("no backend for %s:// references", ref.Scheme)
+	}
+	v, err := b.Get(ctx, ref.Path, ref.Key)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	return v, nil
+}
+
+// pickField returns fields[key], or the only field if key is empty.
+func pickField(fields map[string]any, key string) (string, error) {
+	if key == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("secret has %d fields; add #field to the reference", len(fields))
+		}
+		for k := range fields {
+			key = k
+		}
+	}
+	v, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("secret field %q is not a string", key)
+	}
+	return s, nil
+}
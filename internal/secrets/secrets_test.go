@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeBackend map[string]string
+
+func (f fakeBackend) Get(_ context.Context, path, key string) (string, error) {
+	v, ok := f[path+"#"+key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Ref
+		ok   bool
+	}{
+		{"vault://secret/openai#key", Ref{"vault", "secret/openai", "key"}, true},
+		{"aws-sm://prod/triage", Ref{"aws-sm", "prod/triage", ""}, true},
+		{"https://hooks.slack.com/x", Ref{}, false},
+		{"sk-plain", Ref{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRef(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRef(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+		if ok && got.String() != tt.in {
+			t.Errorf("Ref.String() = %q, want %q", got.String(), tt.in)
+		}
+	}
+}
+
+func TestResolverResolve(t *testing.T) {
+	r := NewResolverWith(map[string]Backend{"vault": fakeBackend{"secret/openai#key": "sk-1"}})
+
+	if got, err := r.Resolve(context.Background(), "vault://secret/openai#key"); err != nil || got != "sk-1" {
+		t.Errorf("Resolve() = %q, %v", got, err)
+	}
+	if got, err := r.Resolve(context.Background(), "plain"); err != nil || got != "plain" {
+		t.Errorf("expected plain values to pass through, got %q, %v", got, err)
+	}
+	if _, err := r.Resolve(context.Background(), "vault://secret/missing"); err == nil || !strings.Contains(err.Error(), "vault://secret/missing") {
+		t.Errorf("expected error naming the reference, got %v", err)
+	}
+	if _, err := r.Resolve(context.Background(), "aws-sm://x"); err == nil || !strings.Contains(err.Error(), "no backend") {
+		t.Errorf("expected missing backend error, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Vault reads secrets from a HashiCorp Vault KV secrets engine over its HTTP
+// API, authenticating with a token.
+type Vault struct {
+	Addr      string // e.g. https://vault.example.com:8200
+	Token     string
+	Namespace string // Vault Enterprise namespace, optional
+	Client    *http.Client
+}
+
+// VaultFromEnv configures Vault from VAULT_ADDR, VAULT_TOKEN, and
+// VAULT_NAMESPACE.
+func VaultFromEnv() *Vault {
+	return &Vault{
+		Addr:      os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		Client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Get reads the secret at path, given as mount/secret such as
+// "secret/openai". Version 2 KV engines are tried first, then version 1.
+func (v *Vault) Get(ctx context.Context, path, key string) (string, error) {
+	if v.Addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	if v.Token == "" {
+		return "", fmt.Errorf("VAULT_TOKEN is not set")
+	}
+	path = strings.Trim(path, "/")
+
+	// KV v2 serves secrets under <mount>/data/<path>, nested in data.data.
+	if mount, rest, ok := strings.Cut(path, "/"); ok {
+		var v2 struct {
+			Data struct {
+				Data map[string]any `json:"data"`
+			} `json:"data"`
+		}
+		found, err := v.read(ctx, mount+"/data/"+rest, &v2)
+		if err != nil {
+			return "", err
+		}
+		if found && v2.Data.Data != nil {
+			return pickField(v2.Data.Data, key)
+		}
+	}
+
+	var v1 struct {
+		Data map[string]any `json:"data"`
+	}
+	found, err := v.read(ctx, path, &v1)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("vault secret %s not found", path)
+	}
+	return pickField(v1.Data, key)
+}
+
+// read GETs /v1/<path> into out. It returns false if the secret does not
+// exist.
+func (v *Vault) read(ctx context.Context, path string, out any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(v.Addr, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("vault request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, fmt.Errorf("vault returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decoding vault response: %w", err)
+	}
+	return true, nil
+}
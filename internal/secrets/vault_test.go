@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestVault(t *testing.T, h http.HandlerFunc) *Vault {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return &Vault{Addr: srv.URL, Token: "root", Client: srv.Client()}
+}
+
+func TestVaultGetKVv2(t *testing.T) {
+	v := newTestVault(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/openai" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"data":{"key":"sk-v2","org":"o"},"metadata":{"version":3}}}`)
+	})
+
+	got, err := v.Get(context.Background(), "secret/openai", "key")
+	if err != nil || got != "sk-v2" {
+		t.Errorf("Get() = %q, %v", got, err)
+	}
+	if _, err := v.Get(context.Background(), "secret/openai", ""); err == nil || !strings.Contains(err.Error(), "add #field") {
+		t.Errorf("expected ambiguity error without a key, got %v", err)
+	}
+}
+
+func TestVaultGetKVv1(t *testing.T) {
+	v := newTestVault(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/slack" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"webhook":"https://hooks.slack.com/x"}}`)
+	})
+
+	got, err := v.Get(context.Background(), "kv/slack", "")
+	if err != nil || got != "https://hooks.slack.com/x" {
+		t.Errorf("Get() = %q, %v", got, err)
+	}
+	if _, err := v.Get(context.Background(), "kv/missing", ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestVaultRequiresAddrAndToken(t *testing.T) {
+	if _, err := (&Vault{Token: "t"}).Get(context.Background(), "secret/x", ""); err == nil || !strings.Contains(err.Error(), "VAULT_ADDR") {
+		t.Errorf("expected VAULT_ADDR error, got %v", err)
+	}
+	if _, err := (&Vault{Addr: "http://vault"}).Get(context.Background(), "secret/x", ""); err == nil || !strings.Contains(err.Error(), "VAULT_TOKEN") {
+		t.Errorf("expected VAULT_TOKEN error, got %v", err)
+	}
+}
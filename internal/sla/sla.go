@@ -0,0 +1,206 @@
+// Package sla reminds maintainers about triaged issues that have waited
+// longer than their severity's SLA for a human response.
+package sla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/store"
+)
+
+// Store is the subset of the store the checker uses.
+type Store interface {
+	ListAwaitingResponse(repoID int64) ([]store.TriageLog, error)
+	GetIssue(repoID int64, number int) (*store.Issue, error)
+	RecordSLAReminder(repoID int64, issueNumber int, logID int64) error
+}
+
+// Overdue is a triaged issue past its SLA.
+type Overdue struct {
+	Number    int
+	Title     string
+	Severity  notify.Severity
+	TriagedAt time.Time
+	// Waiting is how long the issue has waited since it was triaged.
+	Waiting time.Duration
+	// Limit is the SLA of its severity.
+	Limit time.Duration
+
+	logID int64
+}
+
+// Checker finds triaged issues past their SLA and sends reminders about
+// them.
+type Checker struct {
+	store    Store
+	limits   map[notify.Severity]time.Duration
+	style    notify.Style
+	notifier notify.Notifier
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewChecker creates a Checker. limits is the SLA of each severity; issues
+// of a severity without one are never overdue. style grades the severity of
+// triage results as notifications do.
+func NewChecker(s Store, limits map[notify.Severity]time.Duration, style notify.Style, notifier notify.Notifier, logger *slog.Logger) *Checker {
+	return &Checker{
+		store:    s,
+		limits:   limits,
+		style:    style,
+		notifier: notifier,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Overdue returns the issues of a repo that have waited longer than their
+// SLA for a human response and have not been reminded about, longest
+// waiting first.
+func (c *Checker) Overdue(repoID int64) ([]Overdue, error) {
+	logs, err := c.store.ListAwaitingResponse(repoID)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	var overdue []Overdue
+	for _, l := range logs {
+		sev := c.style.Severity(loggedResult(l))
+		limit, ok := c.limits[sev]
+		if !ok {
+			continue
+		}
+		waiting := now.Sub(l.CreatedAt)
+		if waiting <= limit {
+			continue
+		}
+		o := Overdue{
+			Number:    l.IssueNumber,
+			Severity:  sev,
+			TriagedAt: l.CreatedAt,
+			Waiting:   waiting,
+			Limit:     limit,
+			logID:     l.ID,
+		}
+		if issue, err := c.store.GetIssue(repoID, l.IssueNumber); err == nil {
+			o.Title = issue.Title
+		}
+		overdue = append(overdue, o)
+	}
+	return overdue, nil
+}
+
+// Check sends one reminder listing the overdue issues of repo, the
+// "owner/repo" name of repoID, and records them so later checks skip them.
+// It returns the number of issues reminded about.
+func (c *Checker) Check(ctx context.Context, repoID int64, repo string) (int, error) {
+	overdue, err := c.Overdue(repoID)
+	if err != nil {
+		return 0, err
+	}
+	if len(overdue) == 0 {
+		return 0, nil
+	}
+	title := fmt.Sprintf("%d issue(s) in %s awaiting a response past their SLA", len(overdue), repo)
+	if err := notify.SendMessage(ctx, c.notifier, title, Markdown(repo, overdue)); err != nil {
+		return 0, fmt.Errorf("sending SLA reminder: %w", err)
+	}
+	var errs []error
+	for _, o := range overdue {
+		if err := c.store.RecordSLAReminder(repoID, o.Number, o.logID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(overdue), errors.Join(errs...)
+}
+
+// Run checks the repos returned by repos every interval until ctx is
+// cancelled. Failures are logged and retried at the next check.
+func (c *Checker) Run(ctx context.Context, interval time.Duration, repos func() ([]store.Repo, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			list, err := repos()
+			if err != nil {
+				c.logger.Warn("listing repos for SLA check failed", "error", err)
+				continue
+			}
+			for _, r := range list {
+				name := r.Owner + "/" + r.RepoName
+				n, err := c.Check(ctx, r.ID, name)
+				if err != nil {
+					c.logger.Warn("SLA check failed", "repo", name, "error", err)
+					continue
+				}
+				if n > 0 {
+					c.logger.Info("sent SLA reminder", "repo", name, "overdue", n)
+				}
+			}
+		}
+	}
+}
+
+// Markdown lists overdue issues of repo, one per line.
+func Markdown(repo string, overdue []Overdue) string {
+	var b strings.Builder
+	for _, o := range overdue {
+		fmt.Fprintf(&b, "- [#%d](https://github.com/%s/issues/%d)", o.Number, repo, o.Number)
+		if o.Title != "" {
+			fmt.Fprintf(&b, " %s", o.Title)
+		}
+		fmt.Fprintf(&b, " — %s severity, waiting %s (SLA %s)\n", o.Severity, formatDuration(o.Waiting), formatDuration(o.Limit))
+	}
+	return b.String()
+}
+
+// formatDuration rounds d to whole hours, or minutes under an hour, and
+// writes days for a day or more.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		days := d / (24 * time.Hour)
+		hours := (d % (24 * time.Hour)).Round(time.Hour) / time.Hour
+		if hours == 0 {
+			return fmt.Sprintf("%dd", days)
+		}
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d.Round(time.Hour)/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d.Round(time.Minute)/time.Minute)
+	}
+}
+
+// loggedResult rebuilds the parts of a triage result that grade its
+// severity from its log entry.
+func loggedResult(l store.TriageLog) github.TriageResult {
+	result := github.TriageResult{
+		IssueNumber:      l.IssueNumber,
+		NeedsHumanTriage: l.Action == "abstained",
+	}
+	if l.DuplicateOf != "" {
+		for _, ref := range strings.Split(l.DuplicateOf, ", ") {
+			if n, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+				result.Duplicates = append(result.Duplicates, github.DuplicateCandidate{Number: n})
+			}
+		}
+	}
+	if l.SuggestedLabels != "" {
+		for _, name := range strings.Split(l.SuggestedLabels, ", ") {
+			result.SuggestedLabels = append(result.SuggestedLabels, github.LabelSuggestion{Name: name, Confidence: l.LabelConfidences[name]})
+		}
+	}
+	return result
+}
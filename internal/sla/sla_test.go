@@ -0,0 +1,150 @@
+package sla
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacklau/triage/internal/github"
+	"github.com/jacklau/triage/internal/notify"
+	"github.com/jacklau/triage/internal/store"
+)
+
+type fakeStore struct {
+	logs     []store.TriageLog
+	reminded map[int64]bool
+}
+
+func (s *fakeStore) ListAwaitingResponse(repoID int64) ([]store.TriageLog, error) {
+	var out []store.TriageLog
+	for _, l := range s.logs {
+		if !s.reminded[l.ID] {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) GetIssue(repoID int64, number int) (*store.Issue, error) {
+	if number == 3 {
+		return nil, sql.ErrNoRows
+	}
+	return &store.Issue{Number: number, Title: "Issue title"}, nil
+}
+
+func (s *fakeStore) RecordSLAReminder(repoID int64, issueNumber int, logID int64) error {
+	s.reminded[logID] = true
+	return nil
+}
+
+type fakeNotifier struct {
+	titles, texts []string
+	err           error
+}
+
+func (n *fakeNotifier) Notify(context.Context, github.TriageResult) error { return nil }
+
+func (n *fakeNotifier) NotifyMessage(ctx context.Context, title, markdown string) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.titles = append(n.titles, title)
+	n.texts = append(n.texts, markdown)
+	return nil
+}
+
+func newTestChecker(s *fakeStore, n notify.Notifier) *Checker {
+	limits := map[notify.Severity]time.Duration{
+		notify.SeverityHigh:   4 * time.Hour,
+		notify.SeverityMedium: 24 * time.Hour,
+	}
+	c := NewChecker(s, limits, notify.Style{}, n, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.now = func() time.Time { return time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC) }
+	return c
+}
+
+func TestOverdue(t *testing.T) {
+	at := func(hoursAgo int) time.Time {
+		return time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC).Add(-time.Duration(hoursAgo) * time.Hour)
+	}
+	s := &fakeStore{reminded: map[int64]bool{}, logs: []store.TriageLog{
+		// High: abstained, past 4h
+		{ID: 1, IssueNumber: 1, Action: "abstained", CreatedAt: at(5)},
+		// High: duplicate, within 4h
+		{ID: 2, IssueNumber: 2, Action: "duplicate", DuplicateOf: "#1", CreatedAt: at(3)},
+		// Medium: no confident label, past 24h
+		{ID: 3, IssueNumber: 3, Action: "triaged", SuggestedLabels: "bug", LabelConfidences: map[string]float64{"bug": 0.6}, CreatedAt: at(30)},
+		// Low: no SLA
+		{ID: 4, IssueNumber: 4, Action: "triaged", SuggestedLabels: "bug", LabelConfidences: map[string]float64{"bug": 0.95}, CreatedAt: at(100)},
+	}}
+	overdue, err := newTestChecker(s, &fakeNotifier{}).Overdue(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overdue) != 2 {
+		t.Fatalf("expected #1 and #3 to be overdue, got %+v", overdue)
+	}
+	if o := overdue[0]; o.Number != 1 || o.Severity != notify.SeverityHigh || o.Waiting != 5*time.Hour || o.Limit != 4*time.Hour || o.Title != "Issue title" {
+		t.Errorf("unexpected overdue issue %+v", o)
+	}
+	if o := overdue[1]; o.Number != 3 || o.Severity != notify.SeverityMedium || o.Title != "" {
+		t.Errorf("unexpected overdue issue %+v", o)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	s := &fakeStore{reminded: map[int64]bool{}, logs: []store.TriageLog{
+		{ID: 1, IssueNumber: 7, Action: "abstained", CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+	}}
+	n := &fakeNotifier{}
+	c := newTestChecker(s, n)
+
+	sent, err := c.Check(context.Background(), 1, "org/app")
+	if err != nil || sent != 1 {
+		t.Fatalf("Check() = %d, %v; want 1 reminder", sent, err)
+	}
+	if len(n.titles) != 1 || !strings.Contains(n.titles[0], "1 issue(s) in org/app") {
+		t.Errorf("unexpected titles %q", n.titles)
+	}
+	want := "- [#7](https://github.com/org/app/issues/7) Issue title — high severity, waiting 2d2h (SLA 4h)\n"
+	if n.texts[0] != want {
+		t.Errorf("text = %q, want %q", n.texts[0], want)
+	}
+
+	// Reminded issues are not reminded about again
+	sent, err = c.Check(context.Background(), 1, "org/app")
+	if err != nil || sent != 0 || len(n.titles) != 1 {
+		t.Errorf("expected no second reminder, got %d, %v", sent, err)
+	}
+}
+
+func TestCheckNotifyFailure(t *testing.T) {
+	s := &fakeStore{reminded: map[int64]bool{}, logs: []store.TriageLog{
+		{ID: 1, IssueNumber: 7, Action: "abstained", CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+	}}
+	c := newTestChecker(s, &fakeNotifier{err: errors.New("webhook down")})
+	if _, err := c.Check(context.Background(), 1, "org/app"); err == nil {
+		t.Fatal("expected an error when the reminder cannot be sent")
+	}
+	if s.reminded[1] {
+		t.Error("expected an unsent reminder not to be recorded")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		20 * time.Minute:           "20m",
+		90 * time.Minute:           "2h",
+		48 * time.Hour:             "2d",
+		50*time.Hour + time.Minute: "2d2h",
+	} {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
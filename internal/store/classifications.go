@@ -0,0 +1,42 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetClassification returns the cached classification result for a model
+// and prompt hash, and whether there is one.
+func (d *DB) GetClassification(model, promptHash string) ([]byte, bool, error) {
+	var result string
+	err := d.db.QueryRow(`
+		SELECT result FROM classification_cache WHERE model = ? AND prompt_hash = ?`,
+		model, promptHash,
+	).Scan(&result)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading cached classification: %w", err)
+	}
+	return []byte(result), true, nil
+}
+
+// PutClassification caches a classification result for a model and prompt
+// hash, replacing any earlier one.
+func (d *DB) PutClassification(model, promptHash string, result []byte) error {
+	_, err := d.db.Exec(`
+		INSERT INTO classification_cache (model, prompt_hash, result, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(model, prompt_hash) DO UPDATE SET
+			result = excluded.result,
+			created_at = excluded.created_at`,
+		model, promptHash, string(result), time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("caching classification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package store
+
+import "testing"
+
+func TestClassificationCache(t *testing.T) {
+	db := setupTestDB(t)
+
+	if _, ok, err := db.GetClassification("openai:gpt-4o", "abc"); err != nil || ok {
+		t.Fatalf("expected a miss, got %v, %v", ok, err)
+	}
+
+	if err := db.PutClassification("openai:gpt-4o", "abc", []byte(`{"labels":[]}`)); err != nil {
+		t.Fatalf("PutClassification: %v", err)
+	}
+	// Storing again replaces the earlier result.
+	if err := db.PutClassification("openai:gpt-4o", "abc", []byte(`{"labels":["bug"]}`)); err != nil {
+		t.Fatalf("PutClassification again: %v", err)
+	}
+
+	got, ok, err := db.GetClassification("openai:gpt-4o", "abc")
+	if err != nil || !ok {
+		t.Fatalf("expected a hit, got %v, %v", ok, err)
+	}
+	if string(got) != `{"labels":["bug"]}` {
+		t.Errorf("got %s, want the latest result", got)
+	}
+
+	if _, ok, _ := db.GetClassification("ollama:llama3", "abc"); ok {
+		t.Error("expected results to be cached per model")
+	}
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EndpointIssues is the poll cursor endpoint for a repo's issues listing,
+// which also carries its pull requests.
+const EndpointIssues = "issues"
+
+// EndpointReconcile is the cursor recording, as its watermark, when a repo's
+// stored issues were last reconciled with GitHub's full issue listing.
+const EndpointReconcile = "reconcile"
+
+// PollCursor is the conditional-request state for one polled endpoint of a
+// repo. Each endpoint keeps its own ETag, since an ETag is only valid for
+// the request that returned it.
+type PollCursor struct {
+	RepoID    int64
+	Endpoint  string
+	ETag      string
+	Watermark *time.Time
+	UpdatedAt time.Time
+}
+
+// GetPollCursor returns the cursor for a repo's endpoint. An endpoint that
+// has never been polled gets an empty cursor, not an error.
+func (d *DB) GetPollCursor(repoID int64, endpoint string) (*PollCursor, error) {
+	c := PollCursor{RepoID: repoID, Endpoint: endpoint}
+	var etag, watermark sql.NullString
+	var updatedAt string
+
+	err := d.db.QueryRow(
+		`SELECT etag, watermark, updated_at FROM poll_cursors WHERE repo_id = ? AND endpoint = ?`,
+		repoID, endpoint,
+	).Scan(&etag, &watermark, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting poll cursor %s: %w", endpoint, err)
+	}
+
+	c.ETag = etag.String
+	if watermark.Valid {
+		t, _ := time.Parse(time.RFC3339, watermark.String)
+		c.Watermark = &t
+	}
+	c.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &c, nil
+}
+
+// SavePollCursor records the watermark and ETag for a repo's endpoint.
+func (d *DB) SavePollCursor(repoID int64, endpoint string, watermark time.Time, etag string) error {
+	_, err := d.db.Exec(`
+		INSERT INTO poll_cursors (repo_id, endpoint, etag, watermark, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(repo_id, endpoint) DO UPDATE SET
+			etag = excluded.etag,
+			watermark = excluded.watermark,
+			updated_at = excluded.updated_at`,
+		repoID, endpoint, etag,
+		watermark.UTC().Format(time.RFC3339),
+		time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("saving poll cursor %s: %w", endpoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPollCursors(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo: %v", err)
+	}
+
+	// An endpoint that was never polled has an empty cursor.
+	c, err := db.GetPollCursor(repo.ID, EndpointIssues)
+	if err != nil {
+		t.Fatalf("GetPollCursor: %v", err)
+	}
+	if c.Watermark != nil || c.ETag != "" {
+		t.Errorf("expected empty cursor, got %+v", c)
+	}
+
+	issuesAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	commentsAt := issuesAt.Add(-time.Hour)
+	if err := db.SavePollCursor(repo.ID, EndpointIssues, issuesAt, `"issues-1"`); err != nil {
+		t.Fatalf("SavePollCursor issues: %v", err)
+	}
+	if err := db.SavePollCursor(repo.ID, "comments", commentsAt, `"comments-1"`); err != nil {
+		t.Fatalf("SavePollCursor comments: %v", err)
+	}
+
+	// Each endpoint keeps its own state.
+	c, err = db.GetPollCursor(repo.ID, "comments")
+	if err != nil {
+		t.Fatalf("GetPollCursor comments: %v", err)
+	}
+	if c.ETag != `"comments-1"` || c.Watermark == nil || !c.Watermark.Equal(commentsAt) {
+		t.Errorf("comments cursor = %+v", c)
+	}
+
+	// Saving again replaces the state.
+	if err := db.SavePollCursor(repo.ID, EndpointIssues, issuesAt.Add(time.Hour), ""); err != nil {
+		t.Fatalf("SavePollCursor update: %v", err)
+	}
+	c, err = db.GetPollCursor(repo.ID, EndpointIssues)
+	if err != nil {
+		t.Fatalf("GetPollCursor issues: %v", err)
+	}
+	if c.ETag != "" || !c.Watermark.Equal(issuesAt.Add(time.Hour)) {
+		t.Errorf("issues cursor = %+v", c)
+	}
+
+	// The repo record reflects the issues cursor.
+	got, err := db.GetRepo(repo.ID)
+	if err != nil {
+		t.Fatalf("GetRepo: %v", err)
+	}
+	if got.LastPolledAt == nil || !got.LastPolledAt.Equal(issuesAt.Add(time.Hour)) || got.ETag != "" {
+		t.Errorf("repo poll state = %v %q", got.LastPolledAt, got.ETag)
+	}
+}
+
+func TestMigrateV9CopiesPollState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo: %v", err)
+	}
+
+	// Put the database back in its version 8 shape, with poll state on repos,
+	// undoing later migrations too.
+	for _, stmt := range []string{
+		`DROP TRIGGER issues_embedding_updated`,
+		`DROP TRIGGER issues_embedding_deleted`,
+		`DROP TABLE poll_cursors`,
+		`ALTER TABLE issues DROP COLUMN assignees`,
+		`ALTER TABLE issues DROP COLUMN milestone`,
+		`ALTER TABLE triage_log DROP COLUMN raw_response`,
+		`ALTER TABLE repos DROP COLUMN description`,
+		`ALTER TABLE repos DROP COLUMN topics`,
+		`ALTER TABLE repos DROP COLUMN language`,
+		`ALTER TABLE repos DROP COLUMN metadata_fetched_at`,
+		`ALTER TABLE issues DROP COLUMN tombstoned_at`,
+		`ALTER TABLE issues DROP COLUMN tombstone_reason`,
+		`ALTER TABLE repos DROP COLUMN issues_seen`,
+		`ALTER TABLE repos DROP COLUMN triaged_count`,
+		`ALTER TABLE repos DROP COLUMN duplicates_count`,
+		`ALTER TABLE repos DROP COLUMN last_error`,
+		`ALTER TABLE repos DROP COLUMN last_error_at`,
+		`ALTER TABLE repos DROP COLUMN disabled_at`,
+		`ALTER TABLE repos DROP COLUMN disabled_reason`,
+		`ALTER TABLE triage_log DROP COLUMN variant`,
+		`DROP INDEX idx_triage_log_triage_id`,
+		`ALTER TABLE triage_log DROP COLUMN triage_id`,
+		`ALTER TABLE triage_log DROP COLUMN truncated`,
+		`ALTER TABLE repos DROP COLUMN private`,
+		`ALTER TABLE repos DROP COLUMN embedding_generation`,
+		`UPDATE repos SET last_polled_at = '2024-03-01T12:00:00Z', etag = '"old"'`,
+		`PRAGMA user_version = 8`,
+	} {
+		if _, err := db.Conn().Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer db.Close()
+
+	c, err := db.GetPollCursor(repo.ID, EndpointIssues)
+	if err != nil {
+		t.Fatalf("GetPollCursor: %v", err)
+	}
+	want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if c.ETag != `"old"` || c.Watermark == nil || !c.Watermark.Equal(want) {
+		t.Errorf("migrated cursor = %+v", c)
+	}
+}
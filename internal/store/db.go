@@ -7,16 +7,42 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-const currentVersion = 1
+const currentVersion = 30
 
 // DB wraps a SQLite database connection for triage storage.
 type DB struct {
 	db *sql.DB
+	// read serves heavy reads such as stats and issue listings. It is a
+	// separate read-only pool when opened WithReadReplica, else db.
+	read *sql.DB
+}
+
+// Option configures Open.
+type Option func(*openOptions)
+
+type openOptions struct {
+	readConns int
+}
+
+// WithReadReplica opens a separate pool of up to conns read-only
+// connections for heavy reads: stats, triage logs, and issue listings. In
+// WAL mode they read alongside the single writer instead of queueing behind
+// it, so dashboards and reports do not hold up the pipeline. It has no
+// effect on ":memory:", whose data only the writer's connection can see.
+func WithReadReplica(conns int) Option {
+	return func(o *openOptions) {
+		o.readConns = conns
+	}
 }
 
 // Open opens (or creates) a SQLite database at the given path and runs migrations.
 // Use ":memory:" for an in-memory database (useful for testing).
-func Open(path string) (*DB, error) {
+func Open(path string, opts ...Option) (*DB, error) {
+	var o openOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	var dsn string
 	if path != ":memory:" {
 		dsn = path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(ON)"
@@ -37,17 +63,36 @@ func Open(path string) (*DB, error) {
 		return nil, fmt.Errorf("pinging database: %w", err)
 	}
 
-	store := &DB{db: sqlDB}
+	store := &DB{db: sqlDB, read: sqlDB}
 	if err := store.migrate(); err != nil {
 		sqlDB.Close()
 		return nil, fmt.Errorf("running migrations: %w", err)
 	}
 
+	if o.readConns > 0 && path != ":memory:" {
+		// Opened after migrating, so it never sees an old schema
+		readDB, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=query_only(1)")
+		if err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("opening read replica: %w", err)
+		}
+		readDB.SetMaxOpenConns(o.readConns)
+		if err := readDB.Ping(); err != nil {
+			readDB.Close()
+			sqlDB.Close()
+			return nil, fmt.Errorf("pinging read replica: %w", err)
+		}
+		store.read = readDB
+	}
+
 	return store, nil
 }
 
-// Close closes the database connection.
+// Close closes the database connections.
 func (d *DB) Close() error {
+	if d.read != d.db {
+		d.read.Close()
+	}
 	return d.db.Close()
 }
 
@@ -56,6 +101,24 @@ func (d *DB) Conn() *sql.DB {
 	return d.db
 }
 
+// CheckWritable proves the database accepts writes by creating and filling
+// a scratch table in a transaction that is rolled back, leaving no trace.
+func (d *DB) CheckWritable() error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`CREATE TABLE write_check (id INTEGER)`); err != nil {
+		return fmt.Errorf("creating scratch table: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO write_check (id) VALUES (1)`); err != nil {
+		return fmt.Errorf("writing scratch table: %w", err)
+	}
+	return nil
+}
+
 func (d *DB) migrate() error {
 	var version int
 	err := d.db.QueryRow("PRAGMA user_version").Scan(&version)
@@ -73,6 +136,179 @@ func (d *DB) migrate() error {
 		}
 	}
 
+	if version < 2 {
+		if err := d.migrateV2(); err != nil {
+			return err
+		}
+	}
+
+	if version < 3 {
+		if err := d.migrateV3(); err != nil {
+			return err
+		}
+	}
+
+	if version < 4 {
+		if err := d.migrateV4(); err != nil {
+			return err
+		}
+	}
+
+	if version < 5 {
+		if err := d.migrateV5(); err != nil {
+			return err
+		}
+	}
+
+	if version < 6 {
+		if err := d.migrateV6(); err != nil {
+			return err
+		}
+	}
+
+	if version < 7 {
+		if err := d.migrateV7(); err != nil {
+			return err
+		}
+	}
+
+	if version < 8 {
+		if err := d.migrateV8(); err != nil {
+			return err
+		}
+	}
+
+	if version < 9 {
+		if err := d.migrateV9(); err != nil {
+			return err
+		}
+	}
+
+	if version < 10 {
+		if err := d.migrateV10(); err != nil {
+			return err
+		}
+	}
+
+	if version < 11 {
+		if err := d.migrateV11(); err != nil {
+			return err
+		}
+	}
+
+	if version < 12 {
+		if err := d.migrateV12(); err != nil {
+			return err
+		}
+	}
+
+	if version < 13 {
+		if err := d.migrateV13(); err != nil {
+			return err
+		}
+	}
+
+	if version < 14 {
+		if err := d.migrateV14(); err != nil {
+			return err
+		}
+	}
+
+	if version < 15 {
+		if err := d.migrateV15(); err != nil {
+			return err
+		}
+	}
+
+	if version < 16 {
+		if err := d.migrateV16(); err != nil {
+			return err
+		}
+	}
+
+	if version < 17 {
+		if err := d.migrateV17(); err != nil {
+			return err
+		}
+	}
+
+	if version < 18 {
+		if err := d.migrateV18(); err != nil {
+			return err
+		}
+	}
+
+	if version < 19 {
+		if err := d.migrateV19(); err != nil {
+			return err
+		}
+	}
+
+	if version < 20 {
+		if err := d.migrateV20(); err != nil {
+			return err
+		}
+	}
+
+	if version < 21 {
+		if err := d.migrateV21(); err != nil {
+			return err
+		}
+	}
+
+	if version < 22 {
+		if err := d.migrateV22(); err != nil {
+			return err
+		}
+	}
+
+	if version < 23 {
+		if err := d.migrateV23(); err != nil {
+			return err
+		}
+	}
+
+	if version < 24 {
+		if err := d.migrateV24(); err != nil {
+			return err
+		}
+	}
+
+	if version < 25 {
+		if err := d.migrateV25(); err != nil {
+			return err
+		}
+	}
+
+	if version < 26 {
+		if err := d.migrateV26(); err != nil {
+			return err
+		}
+	}
+	if version < 27 {
+		if err := d.migrateV27(); err != nil {
+			return err
+		}
+	}
+
+	if version < 28 {
+		if err := d.migrateV28(); err != nil {
+			return err
+		}
+	}
+
+	if version < 29 {
+		if err := d.migrateV29(); err != nil {
+			return err
+		}
+	}
+
+	if version < 30 {
+		if err := d.migrateV30(); err != nil {
+			return err
+		}
+	}
+
 	_, err = d.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", currentVersion))
 	if err != nil {
 		return fmt.Errorf("setting user_version: %w", err)
@@ -126,6 +362,482 @@ func (d *DB) migrateV1() error {
 		`CREATE INDEX IF NOT EXISTS idx_triage_repo_issue ON triage_log(repo_id, issue_number)`,
 	}
 
+	return d.execMigration(statements)
+}
+
+// migrateV2 adds the api_tokens table used to authenticate HTTP clients.
+func (d *DB) migrateV2() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS api_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			token_hash TEXT NOT NULL UNIQUE,
+			scopes TEXT NOT NULL,
+			created_at TEXT NOT NULL DEFAULT (datetime('now')),
+			last_used_at TEXT,
+			revoked_at TEXT
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV3 adds the leases table used for leader election between
+// instances sharing the same database.
+func (d *DB) migrateV3() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS leases (
+			name TEXT PRIMARY KEY,
+			holder TEXT NOT NULL,
+			expires_at INTEGER NOT NULL
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV4 records per-label confidences on triage_log so stored
+// suggestions can be filtered when applied later.
+func (d *DB) migrateV4() error {
+	statements := []string{
+		`ALTER TABLE triage_log ADD COLUMN label_confidences TEXT`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV5 adds scan session checkpoints so interrupted scans can resume.
+func (d *DB) migrateV5() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS scan_sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			params TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL,
+			total INTEGER NOT NULL DEFAULT 0,
+			started_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_scan_sessions_repo ON scan_sessions(repo_id, params, status)`,
+		`CREATE TABLE IF NOT EXISTS scan_processed (
+			session_id INTEGER NOT NULL REFERENCES scan_sessions(id) ON DELETE CASCADE,
+			issue_number INTEGER NOT NULL,
+			PRIMARY KEY (session_id, issue_number)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV6 adds repos managed from the CLI, with per-repo options, so the
+// watched set can be changed without editing the config file.
+func (d *DB) migrateV6() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS managed_repos (
+			repo_id INTEGER PRIMARY KEY REFERENCES repos(id),
+			options TEXT NOT NULL DEFAULT '{}',
+			added_at TEXT NOT NULL
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV7 adds links from GitHub issues to the Jira tickets created for
+// them.
+func (d *DB) migrateV7() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS jira_links (
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			issue_number INTEGER NOT NULL,
+			jira_key TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (repo_id, issue_number)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV8 adds pull requests, kept so issues can be matched against open
+// pull requests that may already address them.
+func (d *DB) migrateV8() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS pull_requests (
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			number INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			body TEXT,
+			state TEXT NOT NULL,
+			updated_at TEXT NOT NULL,
+			embedding BLOB,
+			embedding_hash TEXT,
+			PRIMARY KEY (repo_id, number)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV9 moves poll state from the repos table to per-endpoint cursors,
+// so each polled resource keeps its own ETag. Existing state becomes the
+// issues cursor; the old repos columns are left in place but unused.
+func (d *DB) migrateV9() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS poll_cursors (
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			endpoint TEXT NOT NULL,
+			etag TEXT,
+			watermark TEXT,
+			updated_at TEXT NOT NULL,
+			PRIMARY KEY (repo_id, endpoint)
+		)`,
+		`INSERT OR IGNORE INTO poll_cursors (repo_id, endpoint, etag, watermark, updated_at)
+			SELECT id, 'issues', etag, last_polled_at, strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+			FROM repos WHERE last_polled_at IS NOT NULL OR etag IS NOT NULL`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV10 records issue assignees and milestones so changes to them can
+// be detected. Existing rows keep NULL until they are next stored.
+func (d *DB) migrateV10() error {
+	statements := []string{
+		`ALTER TABLE issues ADD COLUMN assignees TEXT`,
+		`ALTER TABLE issues ADD COLUMN milestone TEXT`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV11 adds the message each issue's notifications are threaded
+// under, per notifier.
+func (d *DB) migrateV11() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS notify_threads (
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			issue_number INTEGER NOT NULL,
+			notifier TEXT NOT NULL,
+			thread_id TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (repo_id, issue_number, notifier)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV12 records which results have been notified, so identical
+// results are not sent again.
+func (d *DB) migrateV12() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS sent_notifications (
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			issue_number INTEGER NOT NULL,
+			result_hash TEXT NOT NULL,
+			sent_at TEXT NOT NULL,
+			PRIMARY KEY (repo_id, issue_number, result_hash)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV13 adds the classification cache, so unchanged prompts are not
+// sent to the LLM again.
+func (d *DB) migrateV13() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS classification_cache (
+			model TEXT NOT NULL,
+			prompt_hash TEXT NOT NULL,
+			result TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (model, prompt_hash)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV14 lets triage_log keep the classifier's raw response, for
+// debugging results that fell back to "uncertain".
+func (d *DB) migrateV14() error {
+	statements := []string{
+		`ALTER TABLE triage_log ADD COLUMN raw_response TEXT`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV15 records which embedding model, and how many dimensions, each
+// repo's stored embeddings come from.
+func (d *DB) migrateV15() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS repo_embeddings (
+			repo_id INTEGER PRIMARY KEY REFERENCES repos(id),
+			model TEXT NOT NULL,
+			dims INTEGER NOT NULL,
+			recorded_at TEXT NOT NULL
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV16 adds the repo's description, topics, and primary language,
+// fetched from GitHub for the classification prompt.
+func (d *DB) migrateV16() error {
+	statements := []string{
+		`ALTER TABLE repos ADD COLUMN description TEXT`,
+		`ALTER TABLE repos ADD COLUMN topics TEXT`,
+		`ALTER TABLE repos ADD COLUMN language TEXT`,
+		`ALTER TABLE repos ADD COLUMN metadata_fetched_at TEXT`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV17 adds tombstones for issues that have disappeared from GitHub.
+func (d *DB) migrateV17() error {
+	statements := []string{
+		`ALTER TABLE issues ADD COLUMN tombstoned_at TEXT`,
+		`ALTER TABLE issues ADD COLUMN tombstone_reason TEXT`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV18 adds running counters of each repo's triage activity and its
+// last error.
+func (d *DB) migrateV18() error {
+	statements := []string{
+		`ALTER TABLE repos ADD COLUMN issues_seen INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE repos ADD COLUMN triaged_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE repos ADD COLUMN duplicates_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE repos ADD COLUMN last_error TEXT`,
+		`ALTER TABLE repos ADD COLUMN last_error_at TEXT`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV19 lets a repo be disabled, such as when GitHub no longer finds
+// it.
+func (d *DB) migrateV19() error {
+	statements := []string{
+		`ALTER TABLE repos ADD COLUMN disabled_at TEXT`,
+		`ALTER TABLE repos ADD COLUMN disabled_reason TEXT`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV20 adds the previous names of renamed repos, so lookups by an old
+// name find the same record.
+func (d *DB) migrateV20() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS repo_aliases (
+			owner TEXT NOT NULL,
+			repo TEXT NOT NULL,
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			renamed_at TEXT NOT NULL,
+			PRIMARY KEY (owner, repo)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV21 records which experiment variant classified each triage log
+// entry, so variants can be compared by how their suggestions were judged.
+func (d *DB) migrateV21() error {
+	statements := []string{
+		`ALTER TABLE triage_log ADD COLUMN variant TEXT`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV22 adds duplicate pairs confirmed by people, such as issues closed
+// with a "Duplicate of #N" comment.
+func (d *DB) migrateV22() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS duplicate_pairs (
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			issue_number INTEGER NOT NULL,
+			duplicate_of INTEGER NOT NULL,
+			source TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (repo_id, issue_number, duplicate_of)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV23 records the SLA reminders sent for triage results, so each
+// result is reminded about once.
+func (d *DB) migrateV23() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS sla_reminders (
+			triage_log_id INTEGER PRIMARY KEY REFERENCES triage_log(id),
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			issue_number INTEGER NOT NULL,
+			sent_at TEXT NOT NULL
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV24 scales the stored issue and pull request embeddings to unit
+// length, as they are written from now on, so comparing them is a plain dot
+// product. Rows are rewritten a page at a time to bound memory.
+func (d *DB) migrateV24() error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const pageSize = 500
+	for _, table := range []string{"issues", "pull_requests"} {
+		var after int64
+		for {
+			rows, err := tx.Query(`
+				SELECT rowid, embedding FROM `+table+`
+				WHERE rowid > ? AND embedding IS NOT NULL
+				ORDER BY rowid LIMIT ?`,
+				after, pageSize,
+			)
+			if err != nil {
+				return fmt.Errorf("reading %s embeddings: %w", table, err)
+			}
+			type row struct {
+				id        int64
+				embedding []byte
+			}
+			var page []row
+			for rows.Next() {
+				var r row
+				if err := rows.Scan(&r.id, &r.embedding); err != nil {
+					rows.Close()
+					return fmt.Errorf("scanning %s embedding: %w", table, err)
+				}
+				page = append(page, r)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("reading %s embeddings: %w", table, err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			for _, r := range page {
+				if _, err := tx.Exec(`UPDATE `+table+` SET embedding = ? WHERE rowid = ?`, normalizeEmbedding(r.embedding), r.id); err != nil {
+					return fmt.Errorf("normalizing %s embedding: %w", table, err)
+				}
+			}
+			after = page[len(page)-1].id
+		}
+	}
+
+	return tx.Commit()
+}
+
+// migrateV25 records the triage ID of each triage log entry, which ties it
+// to the log lines, notification, and provider requests of the same run.
+func (d *DB) migrateV25() error {
+	statements := []string{
+		`ALTER TABLE triage_log ADD COLUMN triage_id TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_triage_log_triage_id ON triage_log(triage_id)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV26 records how long each stage of a triage took, and with which
+// provider, for latency statistics.
+func (d *DB) migrateV26() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS triage_timings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			issue_number INTEGER NOT NULL,
+			triage_id TEXT,
+			stage TEXT NOT NULL,
+			provider TEXT NOT NULL DEFAULT '',
+			duration_ms INTEGER NOT NULL,
+			created_at TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_triage_timings_created ON triage_timings(created_at)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV27 records whether each triage log entry was classified from an
+// issue body truncated to fit the model's context.
+func (d *DB) migrateV27() error {
+	statements := []string{
+		`ALTER TABLE triage_log ADD COLUMN truncated INTEGER NOT NULL DEFAULT 0`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV28 records whether each repo is private. Stored metadata is marked
+// stale so it is fetched again with the flag rather than read as public.
+func (d *DB) migrateV28() error {
+	statements := []string{
+		`ALTER TABLE repos ADD COLUMN private INTEGER NOT NULL DEFAULT 0`,
+		`UPDATE repos SET metadata_fetched_at = NULL`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV29 counts changes to each repo's issue embeddings, so processes
+// that cache decoded embeddings can tell when another process has written
+// them. Triggers keep the count, whichever code path changes the rows.
+func (d *DB) migrateV29() error {
+	statements := []string{
+		`ALTER TABLE repos ADD COLUMN embedding_generation INTEGER NOT NULL DEFAULT 0`,
+		`CREATE TRIGGER IF NOT EXISTS issues_embedding_updated
+			AFTER UPDATE OF embedding, tombstoned_at ON issues
+			WHEN NEW.embedding IS NOT OLD.embedding OR NEW.tombstoned_at IS NOT OLD.tombstoned_at
+			BEGIN
+				UPDATE repos SET embedding_generation = embedding_generation + 1 WHERE id = NEW.repo_id;
+			END`,
+		`CREATE TRIGGER IF NOT EXISTS issues_embedding_deleted
+			AFTER DELETE ON issues WHEN OLD.embedding IS NOT NULL
+			BEGIN
+				UPDATE repos SET embedding_generation = embedding_generation + 1 WHERE id = OLD.repo_id;
+			END`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// migrateV30 records which closed issues scan has read for confirmed
+// duplicates, as of their last update, so later scans only read new or
+// changed ones.
+func (d *DB) migrateV30() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS duplicate_reads (
+			repo_id INTEGER NOT NULL REFERENCES repos(id),
+			issue_number INTEGER NOT NULL,
+			updated_at TEXT NOT NULL,
+			PRIMARY KEY (repo_id, issue_number)
+		)`,
+	}
+
+	return d.execMigration(statements)
+}
+
+// execMigration runs the given schema statements in a single transaction.
+func (d *DB) execMigration(statements []string) error {
 	tx, err := d.db.Begin()
 	if err != nil {
 		return fmt.Errorf("beginning migration transaction: %w", err)
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sources of a DuplicatePair.
+const (
+	// DuplicateSourceComment is a "Duplicate of #N" comment or body on a
+	// closed issue.
+	DuplicateSourceComment = "comment"
+)
+
+// DuplicatePair records that a person marked an issue a duplicate of an
+// earlier one. The pairs are ground truth for duplicate detection.
+type DuplicatePair struct {
+	RepoID      int64
+	IssueNumber int
+	DuplicateOf int
+	Source      string
+	CreatedAt   time.Time
+}
+
+// AddDuplicatePair records p, reporting whether it was new.
+func (d *DB) AddDuplicatePair(p DuplicatePair) (bool, error) {
+	res, err := d.db.Exec(`
+		INSERT INTO duplicate_pairs (repo_id, issue_number, duplicate_of, source, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		p.RepoID, p.IssueNumber, p.DuplicateOf, p.Source, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, fmt.Errorf("adding duplicate pair #%d/#%d: %w", p.IssueNumber, p.DuplicateOf, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("adding duplicate pair #%d/#%d: %w", p.IssueNumber, p.DuplicateOf, err)
+	}
+	return n > 0, nil
+}
+
+// ListDuplicatePairs returns a repo's confirmed duplicate pairs, ordered by
+// issue number.
+func (d *DB) ListDuplicatePairs(repoID int64) ([]DuplicatePair, error) {
+	rows, err := d.read.Query(`
+		SELECT repo_id, issue_number, duplicate_of, source, created_at FROM duplicate_pairs
+		WHERE repo_id = ? ORDER BY issue_number, duplicate_of`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying duplicate pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []DuplicatePair
+	for rows.Next() {
+		var p DuplicatePair
+		var created string
+		if err := rows.Scan(&p.RepoID, &p.IssueNumber, &p.DuplicateOf, &p.Source, &created); err != nil {
+			return nil, fmt.Errorf("scanning duplicate pair: %w", err)
+		}
+		p.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// MarkDuplicatesRead records that a closed issue's body and comments were
+// read for confirmed duplicates, as of updatedAt, whether or not any were
+// found.
+func (d *DB) MarkDuplicatesRead(repoID int64, number int, updatedAt time.Time) error {
+	_, err := d.db.Exec(`
+		INSERT INTO duplicate_reads (repo_id, issue_number, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(repo_id, issue_number) DO UPDATE SET updated_at = excluded.updated_at`,
+		repoID, number, updatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("marking #%d read for duplicates: %w", number, err)
+	}
+	return nil
+}
+
+// ListDuplicatesRead returns when each of a repo's closed issues was last
+// updated as of being read for confirmed duplicates, by issue number.
+func (d *DB) ListDuplicatesRead(repoID int64) (map[int]time.Time, error) {
+	rows, err := d.read.Query(`
+		SELECT issue_number, updated_at FROM duplicate_reads WHERE repo_id = ?`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying issues read for duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	read := make(map[int]time.Time)
+	for rows.Next() {
+		var number int
+		var updated string
+		if err := rows.Scan(&number, &updated); err != nil {
+			return nil, fmt.Errorf("scanning issue read for duplicates: %w", err)
+		}
+		read[number], _ = time.Parse(time.RFC3339, updated)
+	}
+	return read, rows.Err()
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuplicatePairs(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo failed: %v", err)
+	}
+	other, err := db.CreateRepo("octocat", "other")
+	if err != nil {
+		t.Fatalf("CreateRepo failed: %v", err)
+	}
+
+	for _, p := range []DuplicatePair{
+		{RepoID: repo.ID, IssueNumber: 9, DuplicateOf: 2, Source: DuplicateSourceComment},
+		{RepoID: repo.ID, IssueNumber: 5, DuplicateOf: 1, Source: DuplicateSourceComment},
+		{RepoID: other.ID, IssueNumber: 5, DuplicateOf: 1, Source: DuplicateSourceComment},
+	} {
+		added, err := db.AddDuplicatePair(p)
+		if err != nil || !added {
+			t.Fatalf("AddDuplicatePair(%+v) = %v, %v; want added", p, added, err)
+		}
+	}
+	added, err := db.AddDuplicatePair(DuplicatePair{RepoID: repo.ID, IssueNumber: 5, DuplicateOf: 1, Source: DuplicateSourceComment})
+	if err != nil || added {
+		t.Errorf("expected a repeated pair not to be added, got %v, %v", added, err)
+	}
+
+	pairs, err := db.ListDuplicatePairs(repo.ID)
+	if err != nil {
+		t.Fatalf("ListDuplicatePairs failed: %v", err)
+	}
+	if len(pairs) != 2 || pairs[0].IssueNumber != 5 || pairs[0].DuplicateOf != 1 || pairs[1].IssueNumber != 9 {
+		t.Fatalf("unexpected pairs %+v", pairs)
+	}
+	if pairs[0].Source != DuplicateSourceComment || pairs[0].CreatedAt.IsZero() {
+		t.Errorf("expected the source and time to be kept, got %+v", pairs[0])
+	}
+}
+
+func TestDuplicatesRead(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo failed: %v", err)
+	}
+	first := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := db.MarkDuplicatesRead(repo.ID, 5, first); err != nil {
+		t.Fatalf("MarkDuplicatesRead failed: %v", err)
+	}
+	if err := db.MarkDuplicatesRead(repo.ID, 5, first.Add(time.Hour)); err != nil {
+		t.Fatalf("MarkDuplicatesRead again failed: %v", err)
+	}
+
+	read, err := db.ListDuplicatesRead(repo.ID)
+	if err != nil {
+		t.Fatalf("ListDuplicatesRead failed: %v", err)
+	}
+	if len(read) != 1 || !read[5].Equal(first.Add(time.Hour)) {
+		t.Errorf("expected #5 read as of its latest update, got %v", read)
+	}
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// EmbeddingInfo describes the embeddings stored for a repo: the model that
+// computed them and their dimension.
+type EmbeddingInfo struct {
+	Model string
+	Dims  int
+}
+
+// GetEmbeddingInfo returns the recorded embedding model and dimension for a
+// repo, and whether one has been recorded.
+func (d *DB) GetEmbeddingInfo(repoID int64) (EmbeddingInfo, bool, error) {
+	var info EmbeddingInfo
+	err := d.db.QueryRow(
+		`SELECT model, dims FROM repo_embeddings WHERE repo_id = ?`, repoID,
+	).Scan(&info.Model, &info.Dims)
+	if errors.Is(err, sql.ErrNoRows) {
+		return EmbeddingInfo{}, false, nil
+	}
+	if err != nil {
+		return EmbeddingInfo{}, false, fmt.Errorf("reading embedding info: %w", err)
+	}
+	return info, true, nil
+}
+
+// SetEmbeddingInfo records the embedding model and dimension for a repo.
+func (d *DB) SetEmbeddingInfo(repoID int64, info EmbeddingInfo) error {
+	_, err := d.db.Exec(`
+		INSERT INTO repo_embeddings (repo_id, model, dims, recorded_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(repo_id) DO UPDATE SET
+			model = excluded.model,
+			dims = excluded.dims,
+			recorded_at = excluded.recorded_at`,
+		repoID, info.Model, info.Dims, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("recording embedding info: %w", err)
+	}
+	return nil
+}
+
+// StoredEmbeddingDims returns the dimension of one of a repo's stored issue
+// embeddings, or 0 if it has none. It is for repos embedded before their
+// dimension was recorded.
+func (d *DB) StoredEmbeddingDims(repoID int64) (int, error) {
+	var size int
+	err := d.db.QueryRow(`
+		SELECT length(embedding) FROM issues
+		WHERE repo_id = ? AND embedding IS NOT NULL AND length(embedding) > 0
+		LIMIT 1`,
+		repoID,
+	).Scan(&size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading stored embedding size: %w", err)
+	}
+	// Embeddings are stored as little-endian float32s
+	return size / 4, nil
+}
+
+// EmbeddingGeneration returns a count of the changes to a repo's issue
+// embeddings, by any process. It changes whenever an embedding is stored,
+// cleared or tombstoned, so a cached copy is current while it is unchanged.
+func (d *DB) EmbeddingGeneration(repoID int64) (int64, error) {
+	var gen int64
+	err := d.db.QueryRow(
+		`SELECT embedding_generation FROM repos WHERE id = ?`, repoID,
+	).Scan(&gen)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading embedding generation: %w", err)
+	}
+	return gen, nil
+}
+
+// ClearEmbeddings deletes a repo's stored issue and pull request embeddings
+// and its recorded embedding info, so they are all computed again. It
+// returns how many issue embeddings were cleared.
+func (d *DB) ClearEmbeddings(repoID int64) (int64, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("beginning clear transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE issues SET embedding = NULL, embedding_model = NULL, embedded_at = NULL
+		WHERE repo_id = ? AND embedding IS NOT NULL`,
+		repoID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("clearing issue embeddings: %w", err)
+	}
+	cleared, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting cleared embeddings: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE pull_requests SET embedding = NULL, embedding_hash = NULL WHERE repo_id = ?`,
+		repoID,
+	); err != nil {
+		return 0, fmt.Errorf("clearing pull request embeddings: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM repo_embeddings WHERE repo_id = ?`, repoID); err != nil {
+		return 0, fmt.Errorf("clearing embedding info: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing clear: %w", err)
+	}
+	return cleared, nil
+}
+
+// normalizeEmbedding scales an encoded embedding, little-endian float32s as
+// dedup.EncodeEmbedding writes them, to unit length. Stored embeddings are
+// kept normalized so similarity is a plain dot product at query time. Empty,
+// malformed, and zero vectors are returned unchanged.
+func normalizeEmbedding(b []byte) []byte {
+	if len(b) == 0 || len(b)%4 != 0 {
+		return b
+	}
+	var sum float64
+	for i := 0; i < len(b); i += 4 {
+		f := float64(math.Float32frombits(binary.LittleEndian.Uint32(b[i:])))
+		sum += f * f
+	}
+	if sum == 0 {
+		return b
+	}
+	norm := math.Sqrt(sum)
+	out := make([]byte, len(b))
+	for i := 0; i < len(b); i += 4 {
+		f := float64(math.Float32frombits(binary.LittleEndian.Uint32(b[i:])))
+		binary.LittleEndian.PutUint32(out[i:], math.Float32bits(float32(f/norm)))
+	}
+	return out
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmbeddingInfo(t *testing.T) {
+	db := setupTestDB(t)
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo: %v", err)
+	}
+
+	if _, ok, err := db.GetEmbeddingInfo(repo.ID); err != nil || ok {
+		t.Fatalf("expected no info recorded, got %v, %v", ok, err)
+	}
+	if err := db.SetEmbeddingInfo(repo.ID, EmbeddingInfo{Model: "openai:small", Dims: 1536}); err != nil {
+		t.Fatalf("SetEmbeddingInfo: %v", err)
+	}
+	if err := db.SetEmbeddingInfo(repo.ID, EmbeddingInfo{Model: "ollama:nomic", Dims: 768}); err != nil {
+		t.Fatalf("SetEmbeddingInfo again: %v", err)
+	}
+	info, ok, err := db.GetEmbeddingInfo(repo.ID)
+	if err != nil || !ok || info != (EmbeddingInfo{Model: "ollama:nomic", Dims: 768}) {
+		t.Errorf("GetEmbeddingInfo = %+v, %v, %v", info, ok, err)
+	}
+}
+
+func TestStoredEmbeddingDimsAndClear(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+
+	if dims, err := db.StoredEmbeddingDims(repo.ID); err != nil || dims != 0 {
+		t.Fatalf("expected no stored embeddings, got %d, %v", dims, err)
+	}
+
+	for _, n := range []int{1, 2} {
+		if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: n, Title: "t", State: "open"}); err != nil {
+			t.Fatalf("UpsertIssue: %v", err)
+		}
+	}
+	if err := db.UpdateEmbeddingWithHash(repo.ID, 1, make([]byte, 3*4), "m", "h"); err != nil {
+		t.Fatalf("UpdateEmbeddingWithHash: %v", err)
+	}
+	if err := db.UpsertPullRequest(&PullRequest{RepoID: repo.ID, Number: 3, Title: "Fix", State: "open"}); err != nil {
+		t.Fatalf("UpsertPullRequest: %v", err)
+	}
+	if err := db.UpdatePullRequestEmbedding(repo.ID, 3, []byte{1, 2, 3, 4}, "h"); err != nil {
+		t.Fatalf("UpdatePullRequestEmbedding: %v", err)
+	}
+	if err := db.SetEmbeddingInfo(repo.ID, EmbeddingInfo{Model: "m", Dims: 3}); err != nil {
+		t.Fatalf("SetEmbeddingInfo: %v", err)
+	}
+
+	if dims, _ := db.StoredEmbeddingDims(repo.ID); dims != 3 {
+		t.Errorf("StoredEmbeddingDims = %d, want 3", dims)
+	}
+
+	cleared, err := db.ClearEmbeddings(repo.ID)
+	if err != nil || cleared != 1 {
+		t.Fatalf("ClearEmbeddings = %d, %v; want 1", cleared, err)
+	}
+	if _, hasEmbedding, _ := db.GetIssueEmbeddingHash(repo.ID, 1); hasEmbedding {
+		t.Error("expected the issue embedding to be cleared")
+	}
+	if prs, _ := db.ListOpenPullRequests(repo.ID); len(prs) != 1 || prs[0].Embedding != nil {
+		t.Errorf("expected the pull request embedding to be cleared, got %+v", prs)
+	}
+	if _, ok, _ := db.GetEmbeddingInfo(repo.ID); ok {
+		t.Error("expected the embedding info to be cleared")
+	}
+}
+
+func TestEmbeddingGeneration(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	other, _ := db.CreateRepo("octocat", "other")
+
+	generation := func(repoID int64) int64 {
+		t.Helper()
+		gen, err := db.EmbeddingGeneration(repoID)
+		if err != nil {
+			t.Fatalf("EmbeddingGeneration: %v", err)
+		}
+		return gen
+	}
+
+	if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: 1, Title: "t", State: "open"}); err != nil {
+		t.Fatalf("UpsertIssue: %v", err)
+	}
+	start := generation(repo.ID)
+
+	if err := db.UpdateEmbedding(repo.ID, 1, encodeFloats(1, 0), "m"); err != nil {
+		t.Fatalf("UpdateEmbedding: %v", err)
+	}
+	afterEmbed := generation(repo.ID)
+	if afterEmbed <= start {
+		t.Errorf("expected storing an embedding to advance the generation from %d, got %d", start, afterEmbed)
+	}
+
+	// Updating other columns leaves it alone.
+	if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: 1, Title: "renamed", State: "open"}); err != nil {
+		t.Fatalf("UpsertIssue: %v", err)
+	}
+	if gen := generation(repo.ID); gen != afterEmbed {
+		t.Errorf("expected an upsert to keep generation %d, got %d", afterEmbed, gen)
+	}
+
+	if err := db.TombstoneIssue(repo.ID, 1, "deleted"); err != nil {
+		t.Fatalf("TombstoneIssue: %v", err)
+	}
+	if gen := generation(repo.ID); gen <= afterEmbed {
+		t.Errorf("expected a tombstone to advance the generation from %d, got %d", afterEmbed, gen)
+	}
+	if gen := generation(other.ID); gen != 0 {
+		t.Errorf("expected other repos to keep generation 0, got %d", gen)
+	}
+}
+
+// encodeFloats encodes v as stored embeddings are.
+func encodeFloats(v ...float32) []byte {
+	b := make([]byte, len(v)*4)
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(f))
+	}
+	return b
+}
+
+// decodeFloats decodes a stored embedding.
+func decodeFloats(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return v
+}
+
+func TestNormalizeEmbedding(t *testing.T) {
+	got := decodeFloats(normalizeEmbedding(encodeFloats(3, 4)))
+	if len(got) != 2 || math.Abs(float64(got[0])-0.6) > 1e-6 || math.Abs(float64(got[1])-0.8) > 1e-6 {
+		t.Errorf("normalizeEmbedding(3, 4) = %v, want [0.6 0.8]", got)
+	}
+	for _, b := range [][]byte{nil, {1, 2, 3}, encodeFloats(0, 0)} {
+		if got := normalizeEmbedding(b); string(got) != string(b) {
+			t.Errorf("expected %v to be left unchanged, got %v", b, got)
+		}
+	}
+}
+
+func TestUpdateEmbeddingNormalizes(t *testing.T) {
+	db := setupTestDB(t)
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo: %v", err)
+	}
+	if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: 1, Title: "a", State: "open"}); err != nil {
+		t.Fatalf("UpsertIssue: %v", err)
+	}
+	if err := db.UpdateEmbedding(repo.ID, 1, encodeFloats(0, 2), "m"); err != nil {
+		t.Fatalf("UpdateEmbedding: %v", err)
+	}
+	issue, err := db.GetIssue(repo.ID, 1)
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if got := decodeFloats(issue.Embedding); got[0] != 0 || got[1] != 1 {
+		t.Errorf("stored embedding = %v, want [0 1]", got)
+	}
+}
+
+func TestMigrateV24NormalizesEmbeddings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo: %v", err)
+	}
+	for n := 1; n <= 3; n++ {
+		if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: n, Title: "a", State: "open"}); err != nil {
+			t.Fatalf("UpsertIssue: %v", err)
+		}
+	}
+	if err := db.UpsertPullRequest(&PullRequest{RepoID: repo.ID, Number: 4, Title: "fix", State: "open"}); err != nil {
+		t.Fatalf("UpsertPullRequest: %v", err)
+	}
+
+	// Store embeddings as they were before version 24, without scaling
+	for _, stmt := range []struct {
+		sql  string
+		args []any
+	}{
+		{`UPDATE issues SET embedding = ? WHERE number = 1`, []any{encodeFloats(3, 4)}},
+		{`UPDATE issues SET embedding = ? WHERE number = 2`, []any{encodeFloats(0, 0)}},
+		{`UPDATE pull_requests SET embedding = ? WHERE number = 4`, []any{encodeFloats(0, 5)}},
+		{`DROP TRIGGER issues_embedding_updated`, nil},
+		{`DROP TRIGGER issues_embedding_deleted`, nil},
+		{`DROP INDEX idx_triage_log_triage_id`, nil},
+		{`ALTER TABLE triage_log DROP COLUMN triage_id`, nil},
+		{`ALTER TABLE triage_log DROP COLUMN truncated`, nil},
+		{`ALTER TABLE repos DROP COLUMN private`, nil},
+		{`ALTER TABLE repos DROP COLUMN embedding_generation`, nil},
+		{`PRAGMA user_version = 23`, nil},
+	} {
+		if _, err := db.Conn().Exec(stmt.sql, stmt.args...); err != nil {
+			t.Fatalf("%s: %v", stmt.sql, err)
+		}
+	}
+	db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer db.Close()
+
+	for n, want := range map[int][]float32{1: {0.6, 0.8}, 2: {0, 0}, 3: nil} {
+		issue, err := db.GetIssue(repo.ID, n)
+		if err != nil {
+			t.Fatalf("GetIssue: %v", err)
+		}
+		got := decodeFloats(issue.Embedding)
+		if len(got) != len(want) {
+			t.Errorf("#%d: embedding = %v, want %v", n, got, want)
+			continue
+		}
+		for i := range want {
+			if math.Abs(float64(got[i]-want[i])) > 1e-6 {
+				t.Errorf("#%d: embedding = %v, want %v", n, got, want)
+				break
+			}
+		}
+	}
+	prs, err := db.ListOpenPullRequests(repo.ID)
+	if err != nil {
+		t.Fatalf("ListOpenPullRequests: %v", err)
+	}
+	if got := decodeFloats(prs[0].Embedding); len(got) != 2 || got[1] != 1 {
+		t.Errorf("pull request embedding = %v, want [0 1]", got)
+	}
+}
@@ -0,0 +1,33 @@
+package store
+
+import "database/sql"
+
+// Errors returned, wrapped, by store lookups, so callers can tell a missing
+// record from a failed query with errors.Is. Both also match sql.ErrNoRows,
+// which the store returned before they existed.
+var (
+	// ErrNotFound is returned when a requested record does not exist.
+	ErrNotFound error = &notFoundError{"not found"}
+
+	// ErrRepoNotFound is returned when a repo is not tracked. It matches
+	// ErrNotFound.
+	ErrRepoNotFound error = &notFoundError{"repository not found"}
+)
+
+type notFoundError struct {
+	msg string
+}
+
+func (e *notFoundError) Error() string { return e.msg }
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound || target == sql.ErrNoRows
+}
+
+// notFound returns kind in place of a missing row, or err unchanged.
+func notFound(err, kind error) error {
+	if err == sql.ErrNoRows {
+		return kind
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestNotFoundErrors(t *testing.T) {
+	db := setupTestDB(t)
+
+	_, err := db.GetRepoByOwnerRepo("octocat", "missing")
+	if !errors.Is(err, ErrRepoNotFound) || !errors.Is(err, ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetRepoByOwnerRepo: expected ErrRepoNotFound, got %v", err)
+	}
+	if _, err := db.GetRepo(99); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("GetRepo: expected ErrRepoNotFound, got %v", err)
+	}
+
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.GetIssue(repo.ID, 1)
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetIssue: expected ErrNotFound, got %v", err)
+	}
+	if errors.Is(err, ErrRepoNotFound) {
+		t.Error("a missing issue should not match ErrRepoNotFound")
+	}
+	if _, err := db.GetLease("leader"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetLease: expected ErrNotFound, got %v", err)
+	}
+	if err := db.RevokeAPIToken("ci"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RevokeAPIToken: expected ErrNotFound, got %v", err)
+	}
+
+	// Other failures are not mistaken for a missing record
+	db.Close()
+	if _, err := db.GetRepoByOwnerRepo("octocat", "hello-world"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a query error on a closed store, got %v", err)
+	}
+}
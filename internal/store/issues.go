@@ -4,10 +4,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
-// Issue represents a stored GitHub issue.
+// Issue represents a stored GitHub issue. Assignees is nil for issues stored
+// before assignees and milestones were recorded, and empty when the issue has
+// none. TombstonedAt is set once the issue has disappeared from GitHub; see
+// TombstoneIssue.
 type Issue struct {
 	ID             int64
 	RepoID         int64
@@ -18,11 +22,17 @@ type Issue struct {
 	State          string
 	Author         string
 	Labels         []string
+	Assignees      []string
+	Milestone      string
 	Embedding      []byte
 	EmbeddingModel string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	EmbeddedAt     *time.Time
+	// TombstonedAt and TombstoneReason record when and why the issue
+	// disappeared from GitHub, such as "deleted" or "transferred".
+	TombstonedAt    *time.Time
+	TombstoneReason string
 }
 
 // IssueEmbedding holds an issue number and its embedding vector.
@@ -32,54 +42,191 @@ type IssueEmbedding struct {
 	Model     string
 }
 
-// UpsertIssue inserts or updates an issue.
-func (d *DB) UpsertIssue(issue *Issue) error {
+const upsertIssueSQL = `
+	INSERT INTO issues (repo_id, number, title, body, body_hash, state, author, labels, assignees, milestone, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(repo_id, number) DO UPDATE SET
+		title = excluded.title,
+		body = excluded.body,
+		body_hash = excluded.body_hash,
+		state = excluded.state,
+		author = excluded.author,
+		labels = excluded.labels,
+		assignees = excluded.assignees,
+		milestone = excluded.milestone,
+		updated_at = excluded.updated_at,
+		tombstoned_at = NULL,
+		tombstone_reason = NULL`
+
+// upsertIssueArgs returns the arguments to upsertIssueSQL for issue.
+func upsertIssueArgs(issue *Issue) ([]any, error) {
 	labelsJSON, err := json.Marshal(issue.Labels)
 	if err != nil {
-		return fmt.Errorf("marshaling labels: %w", err)
-	}
-
-	_, err = d.db.Exec(`
-		INSERT INTO issues (repo_id, number, title, body, body_hash, state, author, labels, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(repo_id, number) DO UPDATE SET
-			title = excluded.title,
-			body = excluded.body,
-			body_hash = excluded.body_hash,
-			state = excluded.state,
-			author = excluded.author,
-			labels = excluded.labels,
-			updated_at = excluded.updated_at`,
+		return nil, fmt.Errorf("marshaling labels: %w", err)
+	}
+	// Stored as "[]" rather than null when there are none, so the snapshot
+	// reads back as recorded.
+	assignees := issue.Assignees
+	if assignees == nil {
+		assignees = []string{}
+	}
+	assigneesJSON, err := json.Marshal(assignees)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling assignees: %w", err)
+	}
+	return []any{
 		issue.RepoID, issue.Number, issue.Title, issue.Body, issue.BodyHash,
 		issue.State, issue.Author, string(labelsJSON),
+		string(assigneesJSON), issue.Milestone,
 		issue.CreatedAt.UTC().Format(time.RFC3339),
 		issue.UpdatedAt.UTC().Format(time.RFC3339),
-	)
+	}, nil
+}
+
+// UpsertIssue inserts or updates an issue.
+func (d *DB) UpsertIssue(issue *Issue) error {
+	args, err := upsertIssueArgs(issue)
 	if err != nil {
+		return err
+	}
+	if _, err := d.db.Exec(upsertIssueSQL, args...); err != nil {
 		return fmt.Errorf("upserting issue: %w", err)
 	}
 	return nil
 }
 
-// GetIssue retrieves an issue by repo ID and number.
+// UpsertIssues inserts or updates issues in a single transaction, which is
+// much faster than one UpsertIssue call per issue and holds the write lock
+// once. If any issue fails, none are stored.
+func (d *DB) UpsertIssues(issues []*Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning upsert transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(upsertIssueSQL)
+	if err != nil {
+		return fmt.Errorf("preparing upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, issue := range issues {
+		args, err := upsertIssueArgs(issue)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return fmt.Errorf("upserting issue #%d: %w", issue.Number, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing upserts: %w", err)
+	}
+	return nil
+}
+
+// GetIssue retrieves an issue by repo ID and number. It returns an error
+// wrapping ErrNotFound if the issue is not stored.
 func (d *DB) GetIssue(repoID int64, number int) (*Issue, error) {
 	row := d.db.QueryRow(`
 		SELECT id, repo_id, number, title, body, body_hash, state, author, labels,
-		       embedding, embedding_model, created_at, updated_at, embedded_at
+		       assignees, milestone, embedding, embedding_model, created_at, updated_at, embedded_at,
+		       tombstoned_at, tombstone_reason
 		FROM issues WHERE repo_id = ? AND number = ?`,
 		repoID, number,
 	)
 	return scanIssue(row)
 }
 
-// GetIssuesByRepo returns all issues for a given repo.
+// GetIssuesByRepo returns all issues for a given repo, except tombstoned
+// ones. Use ListIssues to filter them or read them a page at a time.
 func (d *DB) GetIssuesByRepo(repoID int64) ([]Issue, error) {
-	rows, err := d.db.Query(`
+	return d.ListIssues(repoID, IssueQuery{})
+}
+
+// IssueQuery selects and pages a repo's stored issues for ListIssues and
+// CountIssues. Zero fields do not filter, except that tombstoned issues are
+// left out unless IncludeTombstoned is set.
+type IssueQuery struct {
+	// State is "open" or "closed".
+	State string
+	// Label matches issues with this label, ignoring case.
+	Label string
+	// HasEmbedding, if set, matches issues with (true) or without (false)
+	// a stored embedding.
+	HasEmbedding *bool
+	// UpdatedSince matches issues updated at or after this time.
+	UpdatedSince time.Time
+	// CreatedSince matches issues created at or after this time.
+	CreatedSince time.Time
+	// IncludeTombstoned includes issues that have disappeared from GitHub.
+	IncludeTombstoned bool
+	// AfterNumber starts the page after this issue number. Pass the last
+	// number of the previous page to get the next one.
+	AfterNumber int
+	// Limit caps the number of issues returned. It is ignored by
+	// CountIssues.
+	Limit int
+}
+
+// where returns the WHERE clause and arguments selecting q's issues of a
+// repo.
+func (q IssueQuery) where(repoID int64) (string, []any) {
+	clauses := []string{"repo_id = ?"}
+	args := []any{repoID}
+	if !q.IncludeTombstoned {
+		clauses = append(clauses, "tombstoned_at IS NULL")
+	}
+	if q.State != "" {
+		clauses = append(clauses, "state = ?")
+		args = append(args, q.State)
+	}
+	if q.Label != "" {
+		clauses = append(clauses, "EXISTS (SELECT 1 FROM json_each(issues.labels) WHERE value = ? COLLATE NOCASE)")
+		args = append(args, q.Label)
+	}
+	if q.HasEmbedding != nil {
+		if *q.HasEmbedding {
+			clauses = append(clauses, "embedding IS NOT NULL")
+		} else {
+			clauses = append(clauses, "embedding IS NULL")
+		}
+	}
+	if !q.UpdatedSince.IsZero() {
+		clauses = append(clauses, "updated_at >= ?")
+		args = append(args, q.UpdatedSince.UTC().Format(time.RFC3339))
+	}
+	if !q.CreatedSince.IsZero() {
+		clauses = append(clauses, "created_at >= ?")
+		args = append(args, q.CreatedSince.UTC().Format(time.RFC3339))
+	}
+	if q.AfterNumber > 0 {
+		clauses = append(clauses, "number > ?")
+		args = append(args, q.AfterNumber)
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// ListIssues returns a repo's issues matching q, ordered by number.
+func (d *DB) ListIssues(repoID int64, q IssueQuery) ([]Issue, error) {
+	where, args := q.where(repoID)
+	query := `
 		SELECT id, repo_id, number, title, body, body_hash, state, author, labels,
-		       embedding, embedding_model, created_at, updated_at, embedded_at
-		FROM issues WHERE repo_id = ? ORDER BY number`,
-		repoID,
-	)
+		       assignees, milestone, embedding, embedding_model, created_at, updated_at, embedded_at,
+		       tombstoned_at, tombstone_reason
+		FROM issues WHERE ` + where + ` ORDER BY number`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit)
+	}
+
+	rows, err := d.read.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying issues: %w", err)
 	}
@@ -96,13 +243,25 @@ func (d *DB) GetIssuesByRepo(repoID int64) ([]Issue, error) {
 	return issues, rows.Err()
 }
 
-// UpdateEmbedding sets the embedding vector for an issue.
+// CountIssues returns the number of a repo's issues matching q, ignoring
+// its Limit.
+func (d *DB) CountIssues(repoID int64, q IssueQuery) (int, error) {
+	where, args := q.where(repoID)
+	var n int
+	if err := d.read.QueryRow(`SELECT COUNT(*) FROM issues WHERE `+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting issues: %w", err)
+	}
+	return n, nil
+}
+
+// UpdateEmbedding sets the embedding vector for an issue, scaled to unit
+// length.
 func (d *DB) UpdateEmbedding(repoID int64, number int, embedding []byte, model string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	_, err := d.db.Exec(`
 		UPDATE issues SET embedding = ?, embedding_model = ?, embedded_at = ?
 		WHERE repo_id = ? AND number = ?`,
-		embedding, model, now, repoID, number,
+		normalizeEmbedding(embedding), model, now, repoID, number,
 	)
 	if err != nil {
 		return fmt.Errorf("updating embedding: %w", err)
@@ -110,13 +269,14 @@ func (d *DB) UpdateEmbedding(repoID int64, number int, embedding []byte, model s
 	return nil
 }
 
-// UpdateEmbeddingWithHash sets the embedding vector and content hash for an issue.
+// UpdateEmbeddingWithHash sets the embedding vector and content hash for an
+// issue. Like UpdateEmbedding, it stores the vector scaled to unit length.
 func (d *DB) UpdateEmbeddingWithHash(repoID int64, number int, embedding []byte, model, bodyHash string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	_, err := d.db.Exec(`
 		UPDATE issues SET embedding = ?, embedding_model = ?, embedded_at = ?, body_hash = ?
 		WHERE repo_id = ? AND number = ?`,
-		embedding, model, now, bodyHash, repoID, number,
+		normalizeEmbedding(embedding), model, now, bodyHash, repoID, number,
 	)
 	if err != nil {
 		return fmt.Errorf("updating embedding with hash: %w", err)
@@ -142,11 +302,13 @@ func (d *DB) GetIssueEmbeddingHash(repoID int64, number int) (hash string, hasEm
 	return bodyHash.String, len(embedding) > 0, nil
 }
 
-// GetEmbeddingsForRepo returns all issue embeddings for a repo that have been embedded.
+// GetEmbeddingsForRepo returns all issue embeddings for a repo that have been
+// embedded, except those of tombstoned issues, so they are never suggested as
+// duplicates.
 func (d *DB) GetEmbeddingsForRepo(repoID int64) ([]IssueEmbedding, error) {
 	rows, err := d.db.Query(`
 		SELECT number, embedding, embedding_model
-		FROM issues WHERE repo_id = ? AND embedding IS NOT NULL`,
+		FROM issues WHERE repo_id = ? AND embedding IS NOT NULL AND tombstoned_at IS NULL`,
 		repoID,
 	)
 	if err != nil {
@@ -165,24 +327,66 @@ func (d *DB) GetEmbeddingsForRepo(repoID int64) ([]IssueEmbedding, error) {
 	return results, rows.Err()
 }
 
+// TombstoneIssue marks an issue as gone from GitHub for reason, such as
+// "deleted" or "transferred". Its row is kept, but it is left out of dedup,
+// stats, and issue listings until it is seen on GitHub again.
+func (d *DB) TombstoneIssue(repoID int64, number int, reason string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := d.db.Exec(`
+		UPDATE issues SET tombstoned_at = ?, tombstone_reason = ?
+		WHERE repo_id = ? AND number = ?`,
+		now, reason, repoID, number,
+	)
+	if err != nil {
+		return fmt.Errorf("tombstoning issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// IssueNumbers returns the numbers of a repo's stored issues that are not
+// tombstoned, in order.
+func (d *DB) IssueNumbers(repoID int64) ([]int, error) {
+	rows, err := d.db.Query(
+		`SELECT number FROM issues WHERE repo_id = ? AND tombstoned_at IS NULL ORDER BY number`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing issue numbers: %w", err)
+	}
+	defer rows.Close()
+
+	var numbers []int
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			return nil, fmt.Errorf("scanning issue number: %w", err)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, rows.Err()
+}
+
 func scanIssue(row *sql.Row) (*Issue, error) {
 	var issue Issue
-	var body, bodyHash, author, labels, embeddingModel, embeddedAt sql.NullString
+	var body, bodyHash, author, labels, assignees, milestone, embeddingModel, embeddedAt sql.NullString
+	var tombstonedAt, tombstoneReason sql.NullString
 	var embedding []byte
 	var createdAt, updatedAt string
 
 	err := row.Scan(
 		&issue.ID, &issue.RepoID, &issue.Number, &issue.Title,
 		&body, &bodyHash, &issue.State, &author, &labels,
-		&embedding, &embeddingModel, &createdAt, &updatedAt, &embeddedAt,
+		&assignees, &milestone, &embedding, &embeddingModel, &createdAt, &updatedAt, &embeddedAt,
+		&tombstonedAt, &tombstoneReason,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("scanning issue: %w", err)
+		return nil, fmt.Errorf("scanning issue: %w", notFound(err, ErrNotFound))
 	}
 
 	issue.Body = body.String
 	issue.BodyHash = bodyHash.String
 	issue.Author = author.String
+	issue.Milestone = milestone.String
 	issue.Embedding = embedding
 	issue.EmbeddingModel = embeddingModel.String
 	issue.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
@@ -192,24 +396,36 @@ func scanIssue(row *sql.Row) (*Issue, error) {
 		t, _ := time.Parse(time.RFC3339, embeddedAt.String)
 		issue.EmbeddedAt = &t
 	}
+	if tombstonedAt.Valid {
+		t, _ := time.Parse(time.RFC3339, tombstonedAt.String)
+		issue.TombstonedAt = &t
+		issue.TombstoneReason = tombstoneReason.String
+	}
 
 	if labels.Valid && labels.String != "" {
 		_ = json.Unmarshal([]byte(labels.String), &issue.Labels)
 	}
 
+	if assignees.Valid && assignees.String != "" {
+		issue.Assignees = []string{}
+		_ = json.Unmarshal([]byte(assignees.String), &issue.Assignees)
+	}
+
 	return &issue, nil
 }
 
 func scanIssueRows(rows *sql.Rows) (*Issue, error) {
 	var issue Issue
-	var body, bodyHash, author, labels, embeddingModel, embeddedAt sql.NullString
+	var body, bodyHash, author, labels, assignees, milestone, embeddingModel, embeddedAt sql.NullString
+	var tombstonedAt, tombstoneReason sql.NullString
 	var embedding []byte
 	var createdAt, updatedAt string
 
 	err := rows.Scan(
 		&issue.ID, &issue.RepoID, &issue.Number, &issue.Title,
 		&body, &bodyHash, &issue.State, &author, &labels,
-		&embedding, &embeddingModel, &createdAt, &updatedAt, &embeddedAt,
+		&assignees, &milestone, &embedding, &embeddingModel, &createdAt, &updatedAt, &embeddedAt,
+		&tombstonedAt, &tombstoneReason,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("scanning issue: %w", err)
@@ -218,6 +434,7 @@ func scanIssueRows(rows *sql.Rows) (*Issue, error) {
 	issue.Body = body.String
 	issue.BodyHash = bodyHash.String
 	issue.Author = author.String
+	issue.Milestone = milestone.String
 	issue.Embedding = embedding
 	issue.EmbeddingModel = embeddingModel.String
 	issue.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
@@ -227,10 +444,20 @@ func scanIssueRows(rows *sql.Rows) (*Issue, error) {
 		t, _ := time.Parse(time.RFC3339, embeddedAt.String)
 		issue.EmbeddedAt = &t
 	}
+	if tombstonedAt.Valid {
+		t, _ := time.Parse(time.RFC3339, tombstonedAt.String)
+		issue.TombstonedAt = &t
+		issue.TombstoneReason = tombstoneReason.String
+	}
 
 	if labels.Valid && labels.String != "" {
 		_ = json.Unmarshal([]byte(labels.String), &issue.Labels)
 	}
 
+	if assignees.Valid && assignees.String != "" {
+		issue.Assignees = []string{}
+		_ = json.Unmarshal([]byte(assignees.String), &issue.Assignees)
+	}
+
 	return &issue, nil
 }
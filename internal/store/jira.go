@@ -0,0 +1,39 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LinkJiraIssue records the Jira ticket created for an issue. Linking an
+// already-linked issue replaces its ticket key.
+func (d *DB) LinkJiraIssue(repoID int64, number int, key string) error {
+	_, err := d.db.Exec(`
+		INSERT INTO jira_links (repo_id, issue_number, jira_key, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(repo_id, issue_number) DO UPDATE SET jira_key = excluded.jira_key`,
+		repoID, number, key, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("linking issue #%d to %s: %w", number, key, err)
+	}
+	return nil
+}
+
+// GetJiraLink returns the key of the Jira ticket linked to an issue, or ""
+// if there is none.
+func (d *DB) GetJiraLink(repoID int64, number int) (string, error) {
+	var key string
+	err := d.db.QueryRow(
+		`SELECT jira_key FROM jira_links WHERE repo_id = ? AND issue_number = ?`,
+		repoID, number,
+	).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("querying jira link: %w", err)
+	}
+	return key, nil
+}
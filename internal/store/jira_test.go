@@ -0,0 +1,32 @@
+package store
+
+import "testing"
+
+func TestJiraLinks(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo failed: %v", err)
+	}
+
+	key, err := db.GetJiraLink(repo.ID, 1)
+	if err != nil || key != "" {
+		t.Fatalf("expected no link, got %q, %v", key, err)
+	}
+
+	if err := db.LinkJiraIssue(repo.ID, 1, "OPS-1"); err != nil {
+		t.Fatalf("LinkJiraIssue failed: %v", err)
+	}
+	if err := db.LinkJiraIssue(repo.ID, 1, "OPS-2"); err != nil {
+		t.Fatalf("relinking failed: %v", err)
+	}
+
+	key, err = db.GetJiraLink(repo.ID, 1)
+	if err != nil || key != "OPS-2" {
+		t.Errorf("GetJiraLink = %q, %v; want OPS-2", key, err)
+	}
+	if key, _ := db.GetJiraLink(repo.ID, 2); key != "" {
+		t.Errorf("expected issue #2 to be unlinked, got %q", key)
+	}
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lease describes the current holder of a named lease.
+type Lease struct {
+	Name      string
+	Holder    string
+	ExpiresAt time.Time
+}
+
+// AcquireLease tries to take or renew the named lease for holder. It succeeds
+// if the lease is free, expired, or already held by holder, and returns
+// whether holder owns the lease afterwards.
+func (d *DB) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	expires := now.Add(ttl).UnixMilli()
+
+	_, err := d.db.Exec(`
+		INSERT INTO leases (name, holder, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			holder = excluded.holder,
+			expires_at = excluded.expires_at
+		WHERE leases.holder = excluded.holder OR leases.expires_at < ?`,
+		name, holder, expires, now.UnixMilli(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("acquiring lease %s: %w", name, err)
+	}
+
+	lease, err := d.GetLease(name)
+	if err != nil {
+		return false, err
+	}
+	return lease.Holder == holder, nil
+}
+
+// ReleaseLease gives up the named lease if it is held by holder.
+func (d *DB) ReleaseLease(name, holder string) error {
+	_, err := d.db.Exec(`DELETE FROM leases WHERE name = ? AND holder = ?`, name, holder)
+	if err != nil {
+		return fmt.Errorf("releasing lease %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetLease returns the current state of the named lease.
+func (d *DB) GetLease(name string) (*Lease, error) {
+	var l Lease
+	var expires int64
+	err := d.db.QueryRow(
+		`SELECT name, holder, expires_at FROM leases WHERE name = ?`, name,
+	).Scan(&l.Name, &l.Holder, &expires)
+	if err != nil {
+		return nil, fmt.Errorf("getting lease %s: %w", name, notFound(err, ErrNotFound))
+	}
+	l.ExpiresAt = time.UnixMilli(expires)
+	return &l, nil
+}
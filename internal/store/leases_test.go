@@ -0,0 +1,71 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireLease(t *testing.T) {
+	db := setupTestDB(t)
+
+	ok, err := db.AcquireLease("watch", "a", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("first acquire: ok=%v err=%v", ok, err)
+	}
+
+	// Another holder cannot take an unexpired lease.
+	ok, err = db.AcquireLease("watch", "b", time.Minute)
+	if err != nil {
+		t.Fatalf("contended acquire: %v", err)
+	}
+	if ok {
+		t.Fatal("expected b to be refused while a holds the lease")
+	}
+
+	// The holder can renew.
+	ok, err = db.AcquireLease("watch", "a", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("renew: ok=%v err=%v", ok, err)
+	}
+
+	lease, err := db.GetLease("watch")
+	if err != nil {
+		t.Fatalf("GetLease: %v", err)
+	}
+	if lease.Holder != "a" || lease.ExpiresAt.Before(time.Now()) {
+		t.Errorf("unexpected lease: %+v", lease)
+	}
+}
+
+func TestAcquireLeaseAfterExpiry(t *testing.T) {
+	db := setupTestDB(t)
+
+	if ok, _ := db.AcquireLease("watch", "a", -time.Second); !ok {
+		t.Fatal("expected a to acquire")
+	}
+	ok, err := db.AcquireLease("watch", "b", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected b to take over expired lease: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestReleaseLease(t *testing.T) {
+	db := setupTestDB(t)
+
+	db.AcquireLease("watch", "a", time.Minute)
+
+	// Releasing as a non-holder is a no-op.
+	if err := db.ReleaseLease("watch", "b"); err != nil {
+		t.Fatalf("ReleaseLease: %v", err)
+	}
+	if ok, _ := db.AcquireLease("watch", "b", time.Minute); ok {
+		t.Fatal("lease should still be held by a")
+	}
+
+	if err := db.ReleaseLease("watch", "a"); err != nil {
+		t.Fatalf("ReleaseLease: %v", err)
+	}
+	if ok, _ := db.AcquireLease("watch", "b", time.Minute); !ok {
+		t.Fatal("expected b to acquire released lease")
+	}
+}
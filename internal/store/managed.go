@@ -0,0 +1,120 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RepoLabel is a label name and description used for classification.
+type RepoLabel struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// RepoOptions are the per-repo overrides stored for a managed repo. They
+// mirror the per-repo settings in the config file.
+type RepoOptions struct {
+	SimilarityThreshold *float64    `json:"similarity_threshold,omitempty"`
+	CustomPrompt        string      `json:"custom_prompt,omitempty"`
+	Labels              []RepoLabel `json:"labels,omitempty"`
+}
+
+// ManagedRepo is a repo added to the watched set from the CLI.
+type ManagedRepo struct {
+	Repo
+	Options RepoOptions
+	AddedAt time.Time
+}
+
+// FullName returns the repo as owner/repo.
+func (r *Repo) FullName() string {
+	return r.Owner + "/" + r.RepoName
+}
+
+// ManageRepo adds a repo to the managed set, creating its repo record if
+// needed. Managing an already-managed repo replaces its options.
+func (d *DB) ManageRepo(owner, repo string, opts RepoOptions) (*ManagedRepo, error) {
+	r, err := d.GetRepoByOwnerRepo(owner, repo)
+	if errors.Is(err, ErrRepoNotFound) {
+		r, err = d.CreateRepo(owner, repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	optsJSON, err := json.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling repo options: %w", err)
+	}
+
+	addedAt := time.Now().UTC()
+	_, err = d.db.Exec(`
+		INSERT INTO managed_repos (repo_id, options, added_at) VALUES (?, ?, ?)
+		ON CONFLICT(repo_id) DO UPDATE SET options = excluded.options`,
+		r.ID, string(optsJSON), addedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("managing repo %s/%s: %w", owner, repo, err)
+	}
+	return &ManagedRepo{Repo: *r, Options: opts, AddedAt: addedAt}, nil
+}
+
+// UnmanageRepo removes a repo from the managed set. Its issues and triage
+// history are kept. It returns an error wrapping ErrNotFound if the repo
+// was not managed.
+func (d *DB) UnmanageRepo(owner, repo string) error {
+	result, err := d.db.Exec(`
+		DELETE FROM managed_repos WHERE repo_id =
+			(SELECT id FROM repos WHERE owner = ? AND repo = ?)`,
+		owner, repo,
+	)
+	if err != nil {
+		return fmt.Errorf("unmanaging repo %s/%s: %w", owner, repo, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unmanaging repo %s/%s: %w", owner, repo, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("repo %s/%s is not managed: %w", owner, repo, ErrNotFound)
+	}
+	return nil
+}
+
+// ListManagedRepos returns all managed repos ordered by name.
+func (d *DB) ListManagedRepos() ([]ManagedRepo, error) {
+	rows, err := d.db.Query(`
+		SELECT r.id, r.owner, r.repo, c.watermark, c.etag, r.created_at, m.options, m.added_at
+		FROM managed_repos m JOIN repos r ON r.id = m.repo_id
+		LEFT JOIN poll_cursors c ON c.repo_id = r.id AND c.endpoint = 'issues'
+		ORDER BY r.owner, r.repo`)
+	if err != nil {
+		return nil, fmt.Errorf("listing managed repos: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []ManagedRepo
+	for rows.Next() {
+		var m ManagedRepo
+		var lastPolled, etag sql.NullString
+		var createdAt, optsJSON, addedAt string
+		if err := rows.Scan(&m.ID, &m.Owner, &m.RepoName, &lastPolled, &etag, &createdAt, &optsJSON, &addedAt); err != nil {
+			return nil, fmt.Errorf("scanning managed repo: %w", err)
+		}
+		if lastPolled.Valid {
+			t, _ := time.Parse(time.RFC3339, lastPolled.String)
+			m.LastPolledAt = &t
+		}
+		m.ETag = etag.String
+		m.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		m.AddedAt, _ = time.Parse(time.RFC3339, addedAt)
+		if err := json.Unmarshal([]byte(optsJSON), &m.Options); err != nil {
+			return nil, fmt.Errorf("decoding options for %s: %w", m.FullName(), err)
+		}
+		repos = append(repos, m)
+	}
+	return repos, rows.Err()
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestManagedRepoLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+
+	threshold := 0.9
+	opts := RepoOptions{
+		SimilarityThreshold: &threshold,
+		CustomPrompt:        "Mobile app",
+		Labels:              []RepoLabel{{Name: "ios", Description: "iOS only"}},
+	}
+	m, err := db.ManageRepo("octocat", "hello-world", opts)
+	if err != nil {
+		t.Fatalf("ManageRepo failed: %v", err)
+	}
+	if m.FullName() != "octocat/hello-world" || m.ID == 0 {
+		t.Errorf("unexpected managed repo: %+v", m)
+	}
+
+	// The repo record is created on demand and reused afterwards.
+	if _, err := db.GetRepoByOwnerRepo("octocat", "hello-world"); err != nil {
+		t.Fatalf("expected repo record to be created: %v", err)
+	}
+	if _, err := db.ManageRepo("octocat", "hello-world", RepoOptions{CustomPrompt: "Updated"}); err != nil {
+		t.Fatalf("re-managing repo failed: %v", err)
+	}
+	if _, err := db.ManageRepo("acme", "api", RepoOptions{}); err != nil {
+		t.Fatalf("ManageRepo failed: %v", err)
+	}
+
+	repos, err := db.ListManagedRepos()
+	if err != nil {
+		t.Fatalf("ListManagedRepos failed: %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("expected 2 managed repos, got %d", len(repos))
+	}
+	if repos[0].FullName() != "acme/api" || repos[1].FullName() != "octocat/hello-world" {
+		t.Errorf("expected repos ordered by name, got %s, %s", repos[0].FullName(), repos[1].FullName())
+	}
+	got := repos[1].Options
+	if got.CustomPrompt != "Updated" || got.SimilarityThreshold != nil || len(got.Labels) != 0 {
+		t.Errorf("expected options to be replaced, got %+v", got)
+	}
+
+	if err := db.UnmanageRepo("octocat", "hello-world"); err != nil {
+		t.Fatalf("UnmanageRepo failed: %v", err)
+	}
+	if err := db.UnmanageRepo("octocat", "hello-world"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected ErrNoRows unmanaging twice, got %v", err)
+	}
+	if _, err := db.GetRepoByOwnerRepo("octocat", "hello-world"); err != nil {
+		t.Errorf("expected repo record to be kept after unmanaging: %v", err)
+	}
+
+	repos, err = db.ListManagedRepos()
+	if err != nil {
+		t.Fatalf("ListManagedRepos failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].FullName() != "acme/api" {
+		t.Errorf("unexpected managed repos after remove: %+v", repos)
+	}
+}
+
+func TestManagedRepoOptionsRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+
+	threshold := 0.75
+	if _, err := db.ManageRepo("octocat", "hello-world", RepoOptions{
+		SimilarityThreshold: &threshold,
+		Labels:              []RepoLabel{{Name: "bug"}, {Name: "ui", Description: "Frontend"}},
+	}); err != nil {
+		t.Fatalf("ManageRepo failed: %v", err)
+	}
+
+	repos, err := db.ListManagedRepos()
+	if err != nil {
+		t.Fatalf("ListManagedRepos failed: %v", err)
+	}
+	opts := repos[0].Options
+	if opts.SimilarityThreshold == nil || *opts.SimilarityThreshold != 0.75 {
+		t.Errorf("unexpected threshold: %v", opts.SimilarityThreshold)
+	}
+	if len(opts.Labels) != 2 || opts.Labels[1].Description != "Frontend" {
+		t.Errorf("unexpected labels: %+v", opts.Labels)
+	}
+	if repos[0].AddedAt.IsZero() {
+		t.Error("expected AddedAt to be set")
+	}
+}
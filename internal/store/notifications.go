@@ -0,0 +1,36 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// WasNotified reports whether a result with the given content hash has
+// already been notified for an issue.
+func (d *DB) WasNotified(repoID int64, number int, hash string) (bool, error) {
+	var n int
+	err := d.db.QueryRow(`
+		SELECT COUNT(*) FROM sent_notifications
+		WHERE repo_id = ? AND issue_number = ? AND result_hash = ?`,
+		repoID, number, hash,
+	).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("checking sent notifications for #%d: %w", number, err)
+	}
+	return n > 0, nil
+}
+
+// RecordNotification records that a result with the given content hash was
+// notified for an issue.
+func (d *DB) RecordNotification(repoID int64, number int, hash string) error {
+	_, err := d.db.Exec(`
+		INSERT INTO sent_notifications (repo_id, issue_number, result_hash, sent_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(repo_id, issue_number, result_hash) DO UPDATE SET sent_at = excluded.sent_at`,
+		repoID, number, hash, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("recording notification for #%d: %w", number, err)
+	}
+	return nil
+}
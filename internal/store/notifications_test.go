@@ -0,0 +1,34 @@
+package store
+
+import "testing"
+
+func TestSentNotifications(t *testing.T) {
+	db := setupTestDB(t)
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo: %v", err)
+	}
+
+	sent, err := db.WasNotified(repo.ID, 1, "abc")
+	if err != nil || sent {
+		t.Fatalf("expected nothing sent, got %v, %v", sent, err)
+	}
+
+	if err := db.RecordNotification(repo.ID, 1, "abc"); err != nil {
+		t.Fatalf("RecordNotification: %v", err)
+	}
+	// Recording again is not an error.
+	if err := db.RecordNotification(repo.ID, 1, "abc"); err != nil {
+		t.Fatalf("RecordNotification again: %v", err)
+	}
+
+	if sent, _ := db.WasNotified(repo.ID, 1, "abc"); !sent {
+		t.Error("expected hash abc to be recorded for #1")
+	}
+	if sent, _ := db.WasNotified(repo.ID, 1, "def"); sent {
+		t.Error("expected a different hash not to be recorded")
+	}
+	if sent, _ := db.WasNotified(repo.ID, 2, "abc"); sent {
+		t.Error("expected hashes to be per issue")
+	}
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PullRequest is a stored pull request. Its embedding is computed from the
+// content EmbeddingHash was taken from; a stale hash means it needs
+// re-embedding.
+type PullRequest struct {
+	RepoID        int64
+	Number        int
+	Title         string
+	Body          string
+	State         string
+	UpdatedAt     time.Time
+	Embedding     []byte
+	EmbeddingHash string
+}
+
+// UpsertPullRequest inserts or updates a pull request, keeping any stored
+// embedding.
+func (d *DB) UpsertPullRequest(pr *PullRequest) error {
+	_, err := d.db.Exec(`
+		INSERT INTO pull_requests (repo_id, number, title, body, state, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(repo_id, number) DO UPDATE SET
+			title = excluded.title,
+			body = excluded.body,
+			state = excluded.state,
+			updated_at = excluded.updated_at`,
+		pr.RepoID, pr.Number, pr.Title, nullStr(pr.Body), pr.State,
+		pr.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("upserting pull request #%d: %w", pr.Number, err)
+	}
+	return nil
+}
+
+// ListOpenPullRequests returns a repo's open pull requests, with their
+// embeddings if computed.
+func (d *DB) ListOpenPullRequests(repoID int64) ([]PullRequest, error) {
+	rows, err := d.db.Query(`
+		SELECT repo_id, number, title, body, state, updated_at, embedding, embedding_hash
+		FROM pull_requests WHERE repo_id = ? AND state = 'open'
+		ORDER BY number`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying pull requests: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []PullRequest
+	for rows.Next() {
+		var pr PullRequest
+		var body, hash sql.NullString
+		var updatedAt string
+		if err := rows.Scan(&pr.RepoID, &pr.Number, &pr.Title, &body, &pr.State, &updatedAt, &pr.Embedding, &hash); err != nil {
+			return nil, fmt.Errorf("scanning pull request: %w", err)
+		}
+		pr.Body = body.String
+		pr.EmbeddingHash = hash.String
+		pr.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		prs = append(prs, pr)
+	}
+	return prs, rows.Err()
+}
+
+// UpdatePullRequestEmbedding stores a pull request's embedding, scaled to
+// unit length, and the hash of the content it was computed from.
+func (d *DB) UpdatePullRequestEmbedding(repoID int64, number int, embedding []byte, hash string) error {
+	_, err := d.db.Exec(
+		`UPDATE pull_requests SET embedding = ?, embedding_hash = ? WHERE repo_id = ? AND number = ?`,
+		normalizeEmbedding(embedding), hash, repoID, number,
+	)
+	if err != nil {
+		return fmt.Errorf("updating pull request embedding: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPullRequests(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo failed: %v", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, pr := range []PullRequest{
+		{RepoID: repo.ID, Number: 3, Title: "Fix crash", Body: "Fixes the crash", State: "open", UpdatedAt: now},
+		{RepoID: repo.ID, Number: 4, Title: "Old work", State: "closed", UpdatedAt: now},
+	} {
+		if err := db.UpsertPullRequest(&pr); err != nil {
+			t.Fatalf("UpsertPullRequest failed: %v", err)
+		}
+	}
+	if err := db.UpdatePullRequestEmbedding(repo.ID, 3, []byte{1, 2}, "h1"); err != nil {
+		t.Fatalf("UpdatePullRequestEmbedding failed: %v", err)
+	}
+	// An update keeps the stored embedding; the hash tells it is stale.
+	if err := db.UpsertPullRequest(&PullRequest{RepoID: repo.ID, Number: 3, Title: "Fix crash on start", State: "open", UpdatedAt: now}); err != nil {
+		t.Fatalf("UpsertPullRequest failed: %v", err)
+	}
+
+	prs, err := db.ListOpenPullRequests(repo.ID)
+	if err != nil {
+		t.Fatalf("ListOpenPullRequests failed: %v", err)
+	}
+	if len(prs) != 1 {
+		t.Fatalf("expected 1 open pull request, got %d", len(prs))
+	}
+	pr := prs[0]
+	if pr.Number != 3 || pr.Title != "Fix crash on start" || pr.EmbeddingHash != "h1" || len(pr.Embedding) != 2 || !pr.UpdatedAt.Equal(now) {
+		t.Errorf("unexpected pull request: %+v", pr)
+	}
+}
@@ -2,11 +2,14 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 )
 
-// Repo represents a tracked GitHub repository.
+// Repo represents a tracked GitHub repository. LastPolledAt and ETag are
+// those of its issues poll cursor.
 type Repo struct {
 	ID           int64
 	Owner        string
@@ -14,8 +17,35 @@ type Repo struct {
 	LastPolledAt *time.Time
 	ETag         string
 	CreatedAt    time.Time
+	Counters     RepoCounters
+	// DisabledAt is set when the repo stopped being polled, such as after
+	// GitHub no longer found it, and DisabledReason says why.
+	DisabledAt     *time.Time
+	DisabledReason string
 }
 
+// RepoCounters are running totals of a repo's triage activity, kept on its
+// record so they can be shown without aggregating the triage log.
+type RepoCounters struct {
+	// IssuesSeen counts issues the pipeline processed, including ignored
+	// ones.
+	IssuesSeen int
+	// Triaged counts issues classified, or flagged for human triage, and
+	// Duplicates those found to be duplicates.
+	Triaged    int
+	Duplicates int
+	// LastError is the most recent error triaging one of the repo's
+	// issues, and LastErrorAt when it happened.
+	LastError   string
+	LastErrorAt *time.Time
+}
+
+// repoSelect selects repo columns joined with the issues poll cursor.
+const repoSelect = `SELECT r.id, r.owner, r.repo, c.watermark, c.etag, r.created_at,
+	r.issues_seen, r.triaged_count, r.duplicates_count, r.last_error, r.last_error_at,
+	r.disabled_at, r.disabled_reason
+	FROM repos r LEFT JOIN poll_cursors c ON c.repo_id = r.id AND c.endpoint = 'issues'`
+
 // CreateRepo inserts a new repo record.
 func (d *DB) CreateRepo(owner, repo string) (*Repo, error) {
 	result, err := d.db.Exec(
@@ -37,37 +67,87 @@ func (d *DB) CreateRepo(owner, repo string) (*Repo, error) {
 // GetRepo retrieves a repo by its ID.
 func (d *DB) GetRepo(id int64) (*Repo, error) {
 	row := d.db.QueryRow(
-		`SELECT id, owner, repo, last_polled_at, etag, created_at FROM repos WHERE id = ?`,
+		repoSelect+` WHERE r.id = ?`,
 		id,
 	)
 	return scanRepo(row)
 }
 
-// GetRepoByOwnerRepo retrieves a repo by owner and name.
+// GetRepoByOwnerRepo retrieves a repo by owner and name. A name the repo
+// had before RenameRepo finds it too. It returns an error wrapping
+// ErrRepoNotFound if the repo is not tracked.
 func (d *DB) GetRepoByOwnerRepo(owner, repo string) (*Repo, error) {
 	row := d.db.QueryRow(
-		`SELECT id, owner, repo, last_polled_at, etag, created_at FROM repos WHERE owner = ? AND repo = ?`,
+		repoSelect+` WHERE r.owner = ? AND r.repo = ?`,
 		owner, repo,
 	)
-	return scanRepo(row)
+	r, err := scanRepo(row)
+	if !errors.Is(err, ErrRepoNotFound) {
+		return r, err
+	}
+	var id int64
+	aliasErr := d.db.QueryRow(
+		`SELECT repo_id FROM repo_aliases WHERE owner = ? AND repo = ?`,
+		owner, repo,
+	).Scan(&id)
+	if aliasErr != nil {
+		return nil, err
+	}
+	return d.GetRepo(id)
 }
 
-// UpdatePollState updates the last_polled_at and etag for a repo.
-func (d *DB) UpdatePollState(id int64, polledAt time.Time, etag string) error {
-	_, err := d.db.Exec(
-		`UPDATE repos SET last_polled_at = ?, etag = ? WHERE id = ?`,
-		polledAt.UTC().Format(time.RFC3339), etag, id,
-	)
+// RenameRepo changes a repo's owner and name, such as after it was renamed
+// or transferred on GitHub, keeping its ID and so its issues, embeddings,
+// and history. The old name is kept as an alias for GetRepoByOwnerRepo. It
+// fails if another record already has the new name.
+func (d *DB) RenameRepo(repoID int64, owner, repo string) error {
+	tx, err := d.db.Begin()
 	if err != nil {
-		return fmt.Errorf("updating poll state: %w", err)
+		return fmt.Errorf("beginning rename: %w", err)
 	}
-	return nil
+	defer tx.Rollback()
+
+	var oldOwner, oldRepo string
+	if err := tx.QueryRow(`SELECT owner, repo FROM repos WHERE id = ?`, repoID).Scan(&oldOwner, &oldRepo); err != nil {
+		return fmt.Errorf("renaming repo: %w", err)
+	}
+	var other int64
+	err = tx.QueryRow(`SELECT id FROM repos WHERE owner = ? AND repo = ? AND id != ?`, owner, repo, repoID).Scan(&other)
+	if err == nil {
+		return fmt.Errorf("renaming %s/%s: %s/%s already has a separate record", oldOwner, oldRepo, owner, repo)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("renaming repo: %w", err)
+	}
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE repos SET owner = ?, repo = ? WHERE id = ?`, []any{owner, repo, repoID}},
+		{`INSERT INTO repo_aliases (owner, repo, repo_id, renamed_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT(owner, repo) DO UPDATE SET repo_id = excluded.repo_id, renamed_at = excluded.renamed_at`,
+			[]any{oldOwner, oldRepo, repoID, time.Now().UTC().Format(time.RFC3339)}},
+		// A repo renamed back to an old name no longer needs the alias
+		{`DELETE FROM repo_aliases WHERE owner = ? AND repo = ?`, []any{owner, repo}},
+	}
+	for _, st := range statements {
+		if _, err := tx.Exec(st.query, st.args...); err != nil {
+			return fmt.Errorf("renaming repo: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// UpdatePollState updates the watermark and etag of a repo's issues cursor.
+func (d *DB) UpdatePollState(id int64, polledAt time.Time, etag string) error {
+	return d.SavePollCursor(id, EndpointIssues, polledAt, etag)
 }
 
 // ListRepos returns all tracked repos.
 func (d *DB) ListRepos() ([]Repo, error) {
 	rows, err := d.db.Query(
-		`SELECT id, owner, repo, last_polled_at, etag, created_at FROM repos ORDER BY id`,
+		repoSelect + ` ORDER BY r.id`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("listing repos: %w", err)
@@ -85,14 +165,133 @@ func (d *DB) ListRepos() ([]Repo, error) {
 	return repos, rows.Err()
 }
 
+// AddRepoCounts adds delta's IssuesSeen, Triaged, and Duplicates to a
+// repo's counters.
+func (d *DB) AddRepoCounts(repoID int64, delta RepoCounters) error {
+	_, err := d.db.Exec(`
+		UPDATE repos SET
+			issues_seen = issues_seen + ?,
+			triaged_count = triaged_count + ?,
+			duplicates_count = duplicates_count + ?
+		WHERE id = ?`,
+		delta.IssuesSeen, delta.Triaged, delta.Duplicates, repoID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating repo counters: %w", err)
+	}
+	return nil
+}
+
+// RecordRepoError records msg as a repo's last error.
+func (d *DB) RecordRepoError(repoID int64, msg string) error {
+	_, err := d.db.Exec(
+		`UPDATE repos SET last_error = ?, last_error_at = ? WHERE id = ?`,
+		msg, time.Now().UTC().Format(time.RFC3339), repoID,
+	)
+	if err != nil {
+		return fmt.Errorf("recording repo error: %w", err)
+	}
+	return nil
+}
+
+// DisableRepo marks a repo as disabled for reason, so it is no longer
+// polled. It reports whether the repo was newly disabled; disabling it again
+// keeps the original time and reason.
+func (d *DB) DisableRepo(repoID int64, reason string) (bool, error) {
+	res, err := d.db.Exec(
+		`UPDATE repos SET disabled_at = ?, disabled_reason = ? WHERE id = ? AND disabled_at IS NULL`,
+		time.Now().UTC().Format(time.RFC3339), reason, repoID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("disabling repo: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("disabling repo: %w", err)
+	}
+	return n > 0, nil
+}
+
+// EnableRepo clears a repo's disabled mark.
+func (d *DB) EnableRepo(repoID int64) error {
+	_, err := d.db.Exec(
+		`UPDATE repos SET disabled_at = NULL, disabled_reason = NULL WHERE id = ?`,
+		repoID,
+	)
+	if err != nil {
+		return fmt.Errorf("enabling repo: %w", err)
+	}
+	return nil
+}
+
+// RepoMetadata is what GitHub says a repo is about, kept as context for the
+// classifier. FetchedAt is zero if it has never been fetched.
+type RepoMetadata struct {
+	Description string
+	Topics      []string
+	Language    string
+	// Private is set for repos only their collaborators can see.
+	Private   bool
+	FetchedAt time.Time
+}
+
+// GetRepoMetadata returns the stored metadata of a repo.
+func (d *DB) GetRepoMetadata(repoID int64) (*RepoMetadata, error) {
+	var description, topics, language, fetchedAt sql.NullString
+	var private bool
+	err := d.db.QueryRow(
+		`SELECT description, topics, language, private, metadata_fetched_at FROM repos WHERE id = ?`,
+		repoID,
+	).Scan(&description, &topics, &language, &private, &fetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("repo %d: %w", repoID, ErrRepoNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading repo metadata: %w", err)
+	}
+
+	m := &RepoMetadata{Description: description.String, Language: language.String, Private: private}
+	if topics.Valid && topics.String != "" {
+		_ = json.Unmarshal([]byte(topics.String), &m.Topics)
+	}
+	if fetchedAt.Valid {
+		m.FetchedAt, _ = time.Parse(time.RFC3339, fetchedAt.String)
+	}
+	return m, nil
+}
+
+// SetRepoMetadata stores a repo's metadata, fetched at m.FetchedAt or now if
+// that is zero.
+func (d *DB) SetRepoMetadata(repoID int64, m *RepoMetadata) error {
+	topicsJSON, err := json.Marshal(m.Topics)
+	if err != nil {
+		return fmt.Errorf("marshaling topics: %w", err)
+	}
+	fetchedAt := m.FetchedAt
+	if fetchedAt.IsZero() {
+		fetchedAt = time.Now()
+	}
+	_, err = d.db.Exec(`
+		UPDATE repos SET description = ?, topics = ?, language = ?, private = ?, metadata_fetched_at = ?
+		WHERE id = ?`,
+		m.Description, string(topicsJSON), m.Language, m.Private, fetchedAt.UTC().Format(time.RFC3339), repoID,
+	)
+	if err != nil {
+		return fmt.Errorf("storing repo metadata: %w", err)
+	}
+	return nil
+}
+
 func scanRepo(row *sql.Row) (*Repo, error) {
 	var r Repo
-	var lastPolled, etag sql.NullString
+	var lastPolled, etag, lastError, lastErrorAt, disabledAt, disabledReason sql.NullString
 	var createdAt string
 
-	err := row.Scan(&r.ID, &r.Owner, &r.RepoName, &lastPolled, &etag, &createdAt)
+	err := row.Scan(&r.ID, &r.Owner, &r.RepoName, &lastPolled, &etag, &createdAt,
+		&r.Counters.IssuesSeen, &r.Counters.Triaged, &r.Counters.Duplicates, &lastError, &lastErrorAt,
+		&disabledAt, &disabledReason)
 	if err != nil {
-		return nil, fmt.Errorf("scanning repo: %w", err)
+		return nil, fmt.Errorf("scanning repo: %w", notFound(err, ErrRepoNotFound))
 	}
 
 	if lastPolled.Valid {
@@ -101,16 +300,28 @@ func scanRepo(row *sql.Row) (*Repo, error) {
 	}
 	r.ETag = etag.String
 	r.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	r.Counters.LastError = lastError.String
+	if lastErrorAt.Valid {
+		t, _ := time.Parse(time.RFC3339, lastErrorAt.String)
+		r.Counters.LastErrorAt = &t
+	}
+	if disabledAt.Valid {
+		t, _ := time.Parse(time.RFC3339, disabledAt.String)
+		r.DisabledAt = &t
+	}
+	r.DisabledReason = disabledReason.String
 
 	return &r, nil
 }
 
 func scanRepoRows(rows *sql.Rows) (*Repo, error) {
 	var r Repo
-	var lastPolled, etag sql.NullString
+	var lastPolled, etag, lastError, lastErrorAt, disabledAt, disabledReason sql.NullString
 	var createdAt string
 
-	err := rows.Scan(&r.ID, &r.Owner, &r.RepoName, &lastPolled, &etag, &createdAt)
+	err := rows.Scan(&r.ID, &r.Owner, &r.RepoName, &lastPolled, &etag, &createdAt,
+		&r.Counters.IssuesSeen, &r.Counters.Triaged, &r.Counters.Duplicates, &lastError, &lastErrorAt,
+		&disabledAt, &disabledReason)
 	if err != nil {
 		return nil, fmt.Errorf("scanning repo: %w", err)
 	}
@@ -121,6 +332,16 @@ func scanRepoRows(rows *sql.Rows) (*Repo, error) {
 	}
 	r.ETag = etag.String
 	r.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	r.Counters.LastError = lastError.String
+	if lastErrorAt.Valid {
+		t, _ := time.Parse(time.RFC3339, lastErrorAt.String)
+		r.Counters.LastErrorAt = &t
+	}
+	if disabledAt.Valid {
+		t, _ := time.Parse(time.RFC3339, disabledAt.String)
+		r.DisabledAt = &t
+	}
+	r.DisabledReason = disabledReason.String
 
 	return &r, nil
 }
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Scan session statuses.
+const (
+	ScanRunning   = "running"
+	ScanComplete  = "complete"
+	ScanAbandoned = "abandoned"
+)
+
+// ScanSession is a checkpoint for a scan run. Params identifies the scan's
+// filters so a resumed scan only picks up a session started with the same
+// options.
+type ScanSession struct {
+	ID        int64
+	RepoID    int64
+	Params    string
+	Status    string
+	Total     int
+	StartedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CreateScanSession starts a new running scan session. Any earlier running
+// session for the same repo and params is marked abandoned.
+func (d *DB) CreateScanSession(repoID int64, params string, total int) (*ScanSession, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning scan session transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`UPDATE scan_sessions SET status = ?, updated_at = ? WHERE repo_id = ? AND params = ? AND status = ?`,
+		ScanAbandoned, now, repoID, params, ScanRunning,
+	); err != nil {
+		return nil, fmt.Errorf("abandoning previous scan sessions: %w", err)
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO scan_sessions (repo_id, params, status, total, started_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		repoID, params, ScanRunning, total, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating scan session: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("getting scan session id: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing scan session: %w", err)
+	}
+
+	return scanScanSession(d.db.QueryRow(
+		`SELECT id, repo_id, params, status, total, started_at, updated_at FROM scan_sessions WHERE id = ?`, id,
+	))
+}
+
+// GetRunningScanSession returns the most recent unfinished scan session for
+// a repo and params. It returns an error wrapping ErrNotFound if there is none.
+func (d *DB) GetRunningScanSession(repoID int64, params string) (*ScanSession, error) {
+	return scanScanSession(d.db.QueryRow(`
+		SELECT id, repo_id, params, status, total, started_at, updated_at
+		FROM scan_sessions WHERE repo_id = ? AND params = ? AND status = ?
+		ORDER BY id DESC LIMIT 1`,
+		repoID, params, ScanRunning,
+	))
+}
+
+// MarkScanProcessed records that an issue was processed in a scan session.
+func (d *DB) MarkScanProcessed(sessionID int64, issueNumber int) error {
+	_, err := d.db.Exec(
+		`INSERT OR IGNORE INTO scan_processed (session_id, issue_number) VALUES (?, ?)`,
+		sessionID, issueNumber,
+	)
+	if err != nil {
+		return fmt.Errorf("marking issue %d processed: %w", issueNumber, err)
+	}
+	_, err = d.db.Exec(
+		`UPDATE scan_sessions SET updated_at = ? WHERE id = ?`,
+		time.Now().UTC().Format(time.RFC3339), sessionID,
+	)
+	if err != nil {
+		return fmt.Errorf("touching scan session: %w", err)
+	}
+	return nil
+}
+
+// GetScanProcessed returns the set of issue numbers already processed in a
+// scan session.
+func (d *DB) GetScanProcessed(sessionID int64) (map[int]bool, error) {
+	rows, err := d.db.Query(`SELECT issue_number FROM scan_processed WHERE session_id = ?`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("querying processed issues: %w", err)
+	}
+	defer rows.Close()
+
+	processed := make(map[int]bool)
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			return nil, fmt.Errorf("scanning processed issue: %w", err)
+		}
+		processed[n] = true
+	}
+	return processed, rows.Err()
+}
+
+// SetScanTotal records the number of issues a scan session covers, for
+// scans that count them as pages arrive.
+func (d *DB) SetScanTotal(sessionID int64, total int) error {
+	_, err := d.db.Exec(
+		`UPDATE scan_sessions SET total = ?, updated_at = ? WHERE id = ?`,
+		total, time.Now().UTC().Format(time.RFC3339), sessionID,
+	)
+	if err != nil {
+		return fmt.Errorf("setting scan total: %w", err)
+	}
+	return nil
+}
+
+// CompleteScanSession marks a scan session as finished.
+func (d *DB) CompleteScanSession(sessionID int64) error {
+	_, err := d.db.Exec(
+		`UPDATE scan_sessions SET status = ?, updated_at = ? WHERE id = ?`,
+		ScanComplete, time.Now().UTC().Format(time.RFC3339), sessionID,
+	)
+	if err != nil {
+		return fmt.Errorf("completing scan session: %w", err)
+	}
+	return nil
+}
+
+func scanScanSession(row *sql.Row) (*ScanSession, error) {
+	var s ScanSession
+	var startedAt, updatedAt string
+	err := row.Scan(&s.ID, &s.RepoID, &s.Params, &s.Status, &s.Total, &startedAt, &updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("getting scan session: %w", notFound(err, ErrNotFound))
+	}
+	s.StartedAt, _ = time.Parse(time.RFC3339, startedAt)
+	s.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &s, nil
+}
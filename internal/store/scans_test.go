@@ -0,0 +1,80 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestScanSessionLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+
+	if _, err := db.GetRunningScanSession(repo.ID, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows with no sessions, got %v", err)
+	}
+
+	sess, err := db.CreateScanSession(repo.ID, "", 3)
+	if err != nil {
+		t.Fatalf("CreateScanSession failed: %v", err)
+	}
+	if sess.Status != ScanRunning || sess.Total != 3 {
+		t.Errorf("unexpected session: %+v", sess)
+	}
+
+	for _, n := range []int{1, 2, 2} {
+		if err := db.MarkScanProcessed(sess.ID, n); err != nil {
+			t.Fatalf("MarkScanProcessed(%d) failed: %v", n, err)
+		}
+	}
+
+	if err := db.SetScanTotal(sess.ID, 5); err != nil {
+		t.Fatalf("SetScanTotal failed: %v", err)
+	}
+
+	running, err := db.GetRunningScanSession(repo.ID, "")
+	if err != nil || running.ID != sess.ID {
+		t.Fatalf("expected running session %d, got %+v, %v", sess.ID, running, err)
+	}
+	if running.Total != 5 {
+		t.Errorf("expected the total to be updated, got %d", running.Total)
+	}
+
+	processed, err := db.GetScanProcessed(sess.ID)
+	if err != nil {
+		t.Fatalf("GetScanProcessed failed: %v", err)
+	}
+	if len(processed) != 2 || !processed[1] || !processed[2] {
+		t.Errorf("expected issues 1 and 2 processed, got %v", processed)
+	}
+
+	if err := db.CompleteScanSession(sess.ID); err != nil {
+		t.Fatalf("CompleteScanSession failed: %v", err)
+	}
+	if _, err := db.GetRunningScanSession(repo.ID, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected no running session after completion, got %v", err)
+	}
+}
+
+func TestScanSessionParamsAndAbandon(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+
+	first, _ := db.CreateScanSession(repo.ID, "since=24h", 10)
+	other, _ := db.CreateScanSession(repo.ID, "", 10)
+	second, _ := db.CreateScanSession(repo.ID, "since=24h", 10)
+
+	running, err := db.GetRunningScanSession(repo.ID, "since=24h")
+	if err != nil || running.ID != second.ID {
+		t.Fatalf("expected newest session %d, got %+v, %v", second.ID, running, err)
+	}
+	if running, err := db.GetRunningScanSession(repo.ID, ""); err != nil || running.ID != other.ID {
+		t.Errorf("sessions with other params should be untouched, got %+v, %v", running, err)
+	}
+
+	var status string
+	db.Conn().QueryRow(`SELECT status FROM scan_sessions WHERE id = ?`, first.ID).Scan(&status)
+	if status != ScanAbandoned {
+		t.Errorf("expected superseded session abandoned, got %q", status)
+	}
+}
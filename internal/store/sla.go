@@ -0,0 +1,48 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// ListAwaitingResponse returns the latest triage result of each of a repo's
+// issues that still awaits a human response, oldest first. An issue awaits
+// one while it is open, unassigned, and its latest result was neither
+// judged nor applied. Results already reminded about with
+// RecordSLAReminder are left out.
+func (d *DB) ListAwaitingResponse(repoID int64) ([]TriageLog, error) {
+	rows, err := d.db.Query(`
+		SELECT `+triageLogColumns+`
+		FROM triage_log t
+		WHERE t.id IN (SELECT MAX(id) FROM triage_log WHERE repo_id = ? GROUP BY issue_number)
+		  AND t.action IN ('triaged', 'duplicate', 'abstained')
+		  AND t.human_decision IS NULL
+		  AND EXISTS (
+			SELECT 1 FROM issues i
+			WHERE i.repo_id = t.repo_id AND i.number = t.issue_number
+			  AND i.state = 'open' AND i.tombstoned_at IS NULL
+			  AND COALESCE(i.assignees, '[]') = '[]'
+		  )
+		  AND NOT EXISTS (SELECT 1 FROM sla_reminders r WHERE r.triage_log_id = t.id)
+		ORDER BY t.id`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying issues awaiting a response: %w", err)
+	}
+	return collectTriageLogs(rows)
+}
+
+// RecordSLAReminder records that a reminder was sent for the triage log
+// entry logID of an issue.
+func (d *DB) RecordSLAReminder(repoID int64, issueNumber int, logID int64) error {
+	_, err := d.db.Exec(`
+		INSERT INTO sla_reminders (triage_log_id, repo_id, issue_number, sent_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		logID, repoID, issueNumber, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("recording SLA reminder for #%d: %w", issueNumber, err)
+	}
+	return nil
+}
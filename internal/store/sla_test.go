@@ -0,0 +1,87 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListAwaitingResponse(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("CreateRepo failed: %v", err)
+	}
+	now := time.Now()
+	for _, is := range []Issue{
+		{Number: 1, State: "open"},
+		{Number: 2, State: "open"},
+		{Number: 3, State: "closed"},
+		{Number: 4, State: "open", Assignees: []string{"alice"}},
+		{Number: 5, State: "open"},
+		{Number: 6, State: "open"},
+		{Number: 7, State: "open"},
+	} {
+		is.RepoID, is.Title, is.CreatedAt, is.UpdatedAt = repo.ID, "issue", now, now
+		if err := db.UpsertIssue(&is); err != nil {
+			t.Fatalf("UpsertIssue failed: %v", err)
+		}
+	}
+	if err := db.TombstoneIssue(repo.ID, 7, "deleted"); err != nil {
+		t.Fatalf("TombstoneIssue failed: %v", err)
+	}
+
+	for _, l := range []TriageLog{
+		{IssueNumber: 1, Action: "triaged"},
+		{IssueNumber: 2, Action: "abstained"},
+		{IssueNumber: 3, Action: "triaged"},
+		{IssueNumber: 4, Action: "duplicate"},
+		{IssueNumber: 5, Action: "triaged"},
+		{IssueNumber: 5, Action: "apply_labels"},
+		{IssueNumber: 6, Action: "triaged"},
+		{IssueNumber: 7, Action: "triaged"},
+	} {
+		l.RepoID = repo.ID
+		if err := db.LogTriageAction(&l); err != nil {
+			t.Fatalf("LogTriageAction failed: %v", err)
+		}
+	}
+	// #6's result, the seventh entry, was judged
+	if err := db.UpdateHumanDecision(7, "approved"); err != nil {
+		t.Fatalf("UpdateHumanDecision failed: %v", err)
+	}
+
+	awaiting, err := db.ListAwaitingResponse(repo.ID)
+	if err != nil {
+		t.Fatalf("ListAwaitingResponse failed: %v", err)
+	}
+	if len(awaiting) != 2 || awaiting[0].IssueNumber != 1 || awaiting[1].IssueNumber != 2 {
+		t.Fatalf("expected #1 and #2 to await a response, got %+v", awaiting)
+	}
+
+	if err := db.RecordSLAReminder(repo.ID, 1, awaiting[0].ID); err != nil {
+		t.Fatalf("RecordSLAReminder failed: %v", err)
+	}
+	if err := db.RecordSLAReminder(repo.ID, 1, awaiting[0].ID); err != nil {
+		t.Fatalf("expected a repeated reminder to be ignored, got %v", err)
+	}
+	awaiting, err = db.ListAwaitingResponse(repo.ID)
+	if err != nil {
+		t.Fatalf("ListAwaitingResponse failed: %v", err)
+	}
+	if len(awaiting) != 1 || awaiting[0].IssueNumber != 2 {
+		t.Errorf("expected reminded results to be left out, got %+v", awaiting)
+	}
+
+	// A new result is reminded about again
+	if err := db.LogTriageAction(&TriageLog{RepoID: repo.ID, IssueNumber: 1, Action: "triaged"}); err != nil {
+		t.Fatalf("LogTriageAction failed: %v", err)
+	}
+	awaiting, err = db.ListAwaitingResponse(repo.ID)
+	if err != nil {
+		t.Fatalf("ListAwaitingResponse failed: %v", err)
+	}
+	if len(awaiting) != 2 {
+		t.Errorf("expected a re-triaged issue to await a response again, got %+v", awaiting)
+	}
+}
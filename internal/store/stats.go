@@ -8,9 +8,21 @@ type RepoStats struct {
 	IssueCount      int
 	EmbeddingCount  int
 	ClassifiedCount int
+	// AbstainedCount is the number of classified issues whose latest triage
+	// the classifier abstained from.
+	AbstainedCount int
 }
 
-// GetRepoStats returns aggregate statistics for a single repo.
+// notTombstoned excludes triage_log rows, aliased t, of issues that have disappeared
+// from GitHub.
+const notTombstoned = `NOT EXISTS (
+	SELECT 1 FROM issues i
+	WHERE i.repo_id = t.repo_id AND i.number = t.issue_number
+	AND i.tombstoned_at IS NOT NULL
+)`
+
+// GetRepoStats returns aggregate statistics for a single repo. Issues that
+// have disappeared from GitHub are not counted.
 func (d *DB) GetRepoStats(repoID int64) (*RepoStats, error) {
 	repo, err := d.GetRepo(repoID)
 	if err != nil {
@@ -19,30 +31,44 @@ func (d *DB) GetRepoStats(repoID int64) (*RepoStats, error) {
 
 	stats := &RepoStats{Repo: *repo}
 
-	// Total issues
-	err = d.db.QueryRow(
-		`SELECT COUNT(*) FROM issues WHERE repo_id = ?`, repoID,
+	// Total issues, not counting those gone from GitHub
+	err = d.read.QueryRow(
+		`SELECT COUNT(*) FROM issues WHERE repo_id = ? AND tombstoned_at IS NULL`, repoID,
 	).Scan(&stats.IssueCount)
 	if err != nil {
 		return nil, fmt.Errorf("counting issues: %w", err)
 	}
 
 	// Issues with embeddings
-	err = d.db.QueryRow(
-		`SELECT COUNT(*) FROM issues WHERE repo_id = ? AND embedding IS NOT NULL`, repoID,
+	err = d.read.QueryRow(
+		`SELECT COUNT(*) FROM issues WHERE repo_id = ? AND embedding IS NOT NULL AND tombstoned_at IS NULL`, repoID,
 	).Scan(&stats.EmbeddingCount)
 	if err != nil {
 		return nil, fmt.Errorf("counting embeddings: %w", err)
 	}
 
 	// Classified issues (distinct issue numbers in triage_log)
-	err = d.db.QueryRow(
-		`SELECT COUNT(DISTINCT issue_number) FROM triage_log WHERE repo_id = ?`, repoID,
+	err = d.read.QueryRow(
+		`SELECT COUNT(DISTINCT t.issue_number) FROM triage_log t WHERE t.repo_id = ? AND `+notTombstoned, repoID,
 	).Scan(&stats.ClassifiedCount)
 	if err != nil {
 		return nil, fmt.Errorf("counting classified issues: %w", err)
 	}
 
+	// Issues whose latest triage or duplicate entry is an abstention
+	err = d.read.QueryRow(
+		`SELECT COUNT(*) FROM triage_log t
+		WHERE t.repo_id = ? AND t.action = 'abstained' AND `+notTombstoned+`
+		AND t.id = (
+			SELECT MAX(id) FROM triage_log
+			WHERE repo_id = t.repo_id AND issue_number = t.issue_number
+			AND action IN ('triaged', 'duplicate', 'abstained')
+		)`, repoID,
+	).Scan(&stats.AbstainedCount)
+	if err != nil {
+		return nil, fmt.Errorf("counting abstained issues: %w", err)
+	}
+
 	return stats, nil
 }
 
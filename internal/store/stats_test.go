@@ -152,6 +152,40 @@ func TestGetRepoStats_MultipleTriageLogsForSameIssue(t *testing.T) {
 	}
 }
 
+func TestGetRepoStats_Abstentions(t *testing.T) {
+	db, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	defer db.Close()
+
+	repo, err := db.CreateRepo("org", "myrepo")
+	if err != nil {
+		t.Fatalf("creating repo: %v", err)
+	}
+
+	// #1 abstained; #2 abstained, then was re-triaged with labels; #3 triaged.
+	for _, l := range []TriageLog{
+		{RepoID: repo.ID, IssueNumber: 1, Action: "abstained"},
+		{RepoID: repo.ID, IssueNumber: 2, Action: "abstained"},
+		{RepoID: repo.ID, IssueNumber: 2, Action: "triaged"},
+		{RepoID: repo.ID, IssueNumber: 3, Action: "triaged"},
+		{RepoID: repo.ID, IssueNumber: 1, Action: "apply_labels"},
+	} {
+		if err := db.LogTriageAction(&l); err != nil {
+			t.Fatalf("logging triage action: %v", err)
+		}
+	}
+
+	stats, err := db.GetRepoStats(repo.ID)
+	if err != nil {
+		t.Fatalf("getting stats: %v", err)
+	}
+	if stats.AbstainedCount != 1 {
+		t.Errorf("expected 1 abstained, got %d", stats.AbstainedCount)
+	}
+}
+
 func TestGetAllRepoStats(t *testing.T) {
 	db, err := Open(":memory:")
 	if err != nil {
@@ -223,3 +257,32 @@ func TestGetRepoStats_InvalidRepo(t *testing.T) {
 		t.Error("expected error for non-existent repo")
 	}
 }
+
+func TestGetRepoStats_SkipsTombstoned(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("org", "myrepo")
+
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: i, Title: "Issue", State: "open", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("upserting issue: %v", err)
+		}
+		if err := db.UpdateEmbedding(repo.ID, i, []byte{1, 2, 3, 4}, "m"); err != nil {
+			t.Fatalf("updating embedding: %v", err)
+		}
+		if err := db.LogTriageAction(&TriageLog{RepoID: repo.ID, IssueNumber: i, Action: "triaged"}); err != nil {
+			t.Fatalf("logging triage: %v", err)
+		}
+	}
+	if err := db.TombstoneIssue(repo.ID, 2, "deleted"); err != nil {
+		t.Fatalf("TombstoneIssue: %v", err)
+	}
+
+	stats, err := db.GetRepoStats(repo.ID)
+	if err != nil {
+		t.Fatalf("getting stats: %v", err)
+	}
+	if stats.IssueCount != 2 || stats.EmbeddingCount != 2 || stats.ClassifiedCount != 2 {
+		t.Errorf("expected the tombstoned issue not to be counted, got %+v", stats)
+	}
+}
@@ -1,8 +1,14 @@
 package store
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func setupTestDB(t *testing.T) *DB {
@@ -15,6 +21,64 @@ func setupTestDB(t *testing.T) *DB {
 	return db
 }
 
+func TestOpenWithReadReplica(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "triage.db"), WithReadReplica(2))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	if db.read == db.db {
+		t.Fatal("expected a separate read pool")
+	}
+
+	repo, err := db.CreateRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: 1, Title: "Crash", State: "open", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reads go ahead while the writer's only connection is busy
+	tx, err := db.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE issues SET title = 'Crash on start' WHERE number = 1`); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan []Issue, 1)
+	go func() {
+		issues, _ := db.ListIssues(repo.ID, IssueQuery{})
+		done <- issues
+	}()
+	select {
+	case issues := <-done:
+		if len(issues) != 1 || issues[0].Title != "Crash" {
+			t.Errorf("expected the committed issue, got %+v", issues)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("read blocked behind the open write transaction")
+	}
+
+	if _, err := db.read.Exec(`DELETE FROM issues`); err == nil {
+		t.Error("expected the read pool to reject writes")
+	}
+}
+
+func TestOpenWithReadReplicaInMemory(t *testing.T) {
+	db, err := Open(":memory:", WithReadReplica(2))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	if db.read != db.db {
+		t.Error("expected an in-memory store to read from its only connection")
+	}
+}
+
 func TestMigration(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -23,8 +87,8 @@ func TestMigration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to read user_version: %v", err)
 	}
-	if version != 1 {
-		t.Errorf("expected user_version 1, got %d", version)
+	if version != currentVersion {
+		t.Errorf("expected user_version %d, got %d", currentVersion, version)
 	}
 }
 
@@ -105,6 +169,160 @@ func TestRepoDuplicate(t *testing.T) {
 	}
 }
 
+func TestRepoMetadata(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, _ := db.CreateRepo("octocat", "engine")
+
+	m, err := db.GetRepoMetadata(repo.ID)
+	if err != nil {
+		t.Fatalf("GetRepoMetadata: %v", err)
+	}
+	if !m.FetchedAt.IsZero() || m.Description != "" || m.Topics != nil {
+		t.Errorf("expected no metadata before it is set, got %+v", m)
+	}
+
+	fetched := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := db.SetRepoMetadata(repo.ID, &RepoMetadata{
+		Description: "A game engine written in Rust",
+		Topics:      []string{"gamedev", "rust"},
+		Language:    "Rust",
+		Private:     true,
+		FetchedAt:   fetched,
+	}); err != nil {
+		t.Fatalf("SetRepoMetadata: %v", err)
+	}
+
+	m, err = db.GetRepoMetadata(repo.ID)
+	if err != nil {
+		t.Fatalf("GetRepoMetadata: %v", err)
+	}
+	if m.Description != "A game engine written in Rust" || m.Language != "Rust" || !m.Private {
+		t.Errorf("unexpected metadata: %+v", m)
+	}
+	if strings.Join(m.Topics, ",") != "gamedev,rust" {
+		t.Errorf("Topics = %v, want [gamedev rust]", m.Topics)
+	}
+	if !m.FetchedAt.Equal(fetched) {
+		t.Errorf("FetchedAt = %v, want %v", m.FetchedAt, fetched)
+	}
+
+	if _, err := db.GetRepoMetadata(9999); err == nil {
+		t.Error("expected an error for an unknown repo")
+	}
+}
+
+func TestRepoCounters(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+
+	if repo.Counters.IssuesSeen != 0 || repo.Counters.LastErrorAt != nil {
+		t.Errorf("expected zero counters on a new repo, got %+v", repo.Counters)
+	}
+
+	for _, delta := range []RepoCounters{
+		{IssuesSeen: 1, Triaged: 1},
+		{IssuesSeen: 1, Duplicates: 1},
+		{IssuesSeen: 1},
+	} {
+		if err := db.AddRepoCounts(repo.ID, delta); err != nil {
+			t.Fatalf("AddRepoCounts: %v", err)
+		}
+	}
+	if err := db.RecordRepoError(repo.ID, "classification failed"); err != nil {
+		t.Fatalf("RecordRepoError: %v", err)
+	}
+
+	got, err := db.GetRepoByOwnerRepo("octocat", "hello-world")
+	if err != nil {
+		t.Fatalf("GetRepoByOwnerRepo: %v", err)
+	}
+	c := got.Counters
+	if c.IssuesSeen != 3 || c.Triaged != 1 || c.Duplicates != 1 {
+		t.Errorf("unexpected counts: %+v", c)
+	}
+	if c.LastError != "classification failed" || c.LastErrorAt == nil {
+		t.Errorf("expected the last error, got %q at %v", c.LastError, c.LastErrorAt)
+	}
+
+	repos, _ := db.ListRepos()
+	if len(repos) != 1 || repos[0].Counters.IssuesSeen != 3 {
+		t.Errorf("expected ListRepos to include the counters, got %+v", repos)
+	}
+}
+
+func TestDisableRepo(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	if repo.DisabledAt != nil {
+		t.Fatalf("expected a new repo to be enabled, got %v", repo.DisabledAt)
+	}
+
+	newly, err := db.DisableRepo(repo.ID, "not_found")
+	if err != nil || !newly {
+		t.Fatalf("DisableRepo = %v, %v, want newly disabled", newly, err)
+	}
+	newly, err = db.DisableRepo(repo.ID, "gone")
+	if err != nil || newly {
+		t.Errorf("expected disabling again to be a no-op, got %v, %v", newly, err)
+	}
+	got, _ := db.GetRepo(repo.ID)
+	if got.DisabledAt == nil || got.DisabledReason != "not_found" {
+		t.Errorf("expected the first reason to be kept, got %v %q", got.DisabledAt, got.DisabledReason)
+	}
+
+	if err := db.EnableRepo(repo.ID); err != nil {
+		t.Fatalf("EnableRepo: %v", err)
+	}
+	repos, _ := db.ListRepos()
+	if len(repos) != 1 || repos[0].DisabledAt != nil || repos[0].DisabledReason != "" {
+		t.Errorf("expected the repo to be enabled, got %+v", repos)
+	}
+}
+
+func TestRenameRepo(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: 1, Title: "Crash", State: "open"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.RenameRepo(repo.ID, "octo-org", "hello"); err != nil {
+		t.Fatalf("RenameRepo: %v", err)
+	}
+	for _, name := range [][2]string{{"octo-org", "hello"}, {"octocat", "hello-world"}} {
+		got, err := db.GetRepoByOwnerRepo(name[0], name[1])
+		if err != nil {
+			t.Fatalf("GetRepoByOwnerRepo(%s/%s): %v", name[0], name[1], err)
+		}
+		if got.ID != repo.ID || got.FullName() != "octo-org/hello" {
+			t.Errorf("GetRepoByOwnerRepo(%s/%s) = %d %s, want the renamed record", name[0], name[1], got.ID, got.FullName())
+		}
+	}
+	if issues, _ := db.GetIssuesByRepo(repo.ID); len(issues) != 1 {
+		t.Errorf("expected the issues to be kept, got %d", len(issues))
+	}
+	if _, err := db.GetRepoByOwnerRepo("octocat", "other"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected ErrNoRows for an unknown repo, got %v", err)
+	}
+
+	// Renaming back drops the alias for the current name
+	if err := db.RenameRepo(repo.ID, "octocat", "hello-world"); err != nil {
+		t.Fatalf("renaming back: %v", err)
+	}
+	if got, err := db.GetRepoByOwnerRepo("octo-org", "hello"); err != nil || got.FullName() != "octocat/hello-world" {
+		t.Errorf("expected the intermediate name to resolve, got %v, %v", got, err)
+	}
+
+	other, _ := db.CreateRepo("octocat", "taken")
+	if err := db.RenameRepo(repo.ID, "octocat", "taken"); err == nil {
+		t.Error("expected an error renaming onto another record")
+	}
+	if got, _ := db.GetRepo(other.ID); got.FullName() != "octocat/taken" {
+		t.Errorf("expected the other record to be untouched, got %s", got.FullName())
+	}
+}
+
 func TestIssuesCRUD(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -184,6 +402,221 @@ func TestIssuesCRUD(t *testing.T) {
 	}
 }
 
+func TestListIssues(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	other, _ := db.CreateRepo("octocat", "other")
+
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, issue := range []*Issue{
+		{RepoID: repo.ID, Number: 1, Title: "one", State: "open", Labels: []string{"bug"}, CreatedAt: old, UpdatedAt: old},
+		{RepoID: repo.ID, Number: 2, Title: "two", State: "closed", Labels: []string{"Bug", "ui"}, CreatedAt: old, UpdatedAt: recent},
+		{RepoID: repo.ID, Number: 3, Title: "three", State: "open", CreatedAt: old, UpdatedAt: recent},
+		{RepoID: repo.ID, Number: 4, Title: "four", State: "open", Labels: []string{"docs"}, CreatedAt: recent, UpdatedAt: recent},
+		{RepoID: other.ID, Number: 5, Title: "elsewhere", State: "open", Labels: []string{"bug"}, CreatedAt: old, UpdatedAt: recent},
+	} {
+		if err := db.UpsertIssue(issue); err != nil {
+			t.Fatalf("UpsertIssue: %v", err)
+		}
+	}
+	if err := db.UpdateEmbedding(repo.ID, 3, []byte{1, 2, 3, 4}, "m"); err != nil {
+		t.Fatalf("UpdateEmbedding: %v", err)
+	}
+
+	numbers := func(issues []Issue) []int {
+		var ns []int
+		for _, i := range issues {
+			ns = append(ns, i.Number)
+		}
+		return ns
+	}
+	yes, no := true, false
+	tests := []struct {
+		name string
+		q    IssueQuery
+		want []int
+	}{
+		{"all", IssueQuery{}, []int{1, 2, 3, 4}},
+		{"state", IssueQuery{State: "open"}, []int{1, 3, 4}},
+		{"label ignores case", IssueQuery{Label: "bug"}, []int{1, 2}},
+		{"embedded", IssueQuery{HasEmbedding: &yes}, []int{3}},
+		{"not embedded", IssueQuery{HasEmbedding: &no}, []int{1, 2, 4}},
+		{"updated since", IssueQuery{UpdatedSince: recent}, []int{2, 3, 4}},
+		{"created since", IssueQuery{CreatedSince: recent}, []int{4}},
+		{"combined", IssueQuery{State: "open", UpdatedSince: recent, HasEmbedding: &no}, []int{4}},
+		{"first page", IssueQuery{Limit: 2}, []int{1, 2}},
+		{"next page", IssueQuery{AfterNumber: 2, Limit: 2}, []int{3, 4}},
+		{"last page", IssueQuery{AfterNumber: 4, Limit: 2}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := db.ListIssues(repo.ID, tt.q)
+			if err != nil {
+				t.Fatalf("ListIssues: %v", err)
+			}
+			if got := numbers(issues); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ListIssues = %v, want %v", got, tt.want)
+			}
+
+			n, err := db.CountIssues(repo.ID, tt.q)
+			if err != nil {
+				t.Fatalf("CountIssues: %v", err)
+			}
+			if tt.q.Limit == 0 && n != len(tt.want) {
+				t.Errorf("CountIssues = %d, want %d", n, len(tt.want))
+			}
+		})
+	}
+
+	if n, _ := db.CountIssues(repo.ID, IssueQuery{Limit: 1}); n != 4 {
+		t.Errorf("CountIssues should ignore Limit, got %d", n)
+	}
+}
+
+func TestTombstoneIssue(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+
+	now := time.Now().UTC()
+	for n := 1; n <= 3; n++ {
+		if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: n, Title: "Issue", State: "open", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("UpsertIssue: %v", err)
+		}
+		if err := db.UpdateEmbedding(repo.ID, n, []byte{1, 2, 3, 4}, "m"); err != nil {
+			t.Fatalf("UpdateEmbedding: %v", err)
+		}
+	}
+
+	if err := db.TombstoneIssue(repo.ID, 2, "transferred"); err != nil {
+		t.Fatalf("TombstoneIssue: %v", err)
+	}
+
+	got, err := db.GetIssue(repo.ID, 2)
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if got.TombstonedAt == nil || got.TombstoneReason != "transferred" {
+		t.Errorf("expected a tombstone, got at=%v reason=%q", got.TombstonedAt, got.TombstoneReason)
+	}
+
+	numbers, err := db.IssueNumbers(repo.ID)
+	if err != nil {
+		t.Fatalf("IssueNumbers: %v", err)
+	}
+	if fmt.Sprint(numbers) != "[1 3]" {
+		t.Errorf("IssueNumbers = %v, want [1 3]", numbers)
+	}
+	embeddings, _ := db.GetEmbeddingsForRepo(repo.ID)
+	if len(embeddings) != 2 {
+		t.Errorf("expected 2 embeddings without the tombstoned issue, got %d", len(embeddings))
+	}
+	if issues, _ := db.GetIssuesByRepo(repo.ID); len(issues) != 2 {
+		t.Errorf("expected 2 issues without the tombstoned one, got %d", len(issues))
+	}
+	if issues, _ := db.ListIssues(repo.ID, IssueQuery{IncludeTombstoned: true}); len(issues) != 3 {
+		t.Errorf("expected 3 issues including the tombstoned one, got %d", len(issues))
+	}
+
+	// An issue seen on GitHub again is restored
+	if err := db.UpsertIssue(&Issue{RepoID: repo.ID, Number: 2, Title: "Issue", State: "open", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("UpsertIssue: %v", err)
+	}
+	got, _ = db.GetIssue(repo.ID, 2)
+	if got.TombstonedAt != nil || got.TombstoneReason != "" {
+		t.Errorf("expected the tombstone to be cleared, got at=%v reason=%q", got.TombstonedAt, got.TombstoneReason)
+	}
+}
+
+func TestIssueAssigneesAndMilestone(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	now := time.Now().UTC()
+
+	issue := &Issue{
+		RepoID:    repo.ID,
+		Number:    1,
+		Title:     "Assigned",
+		State:     "open",
+		Assignees: []string{"octocat", "hubot"},
+		Milestone: "v1.0",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := db.UpsertIssue(issue); err != nil {
+		t.Fatalf("UpsertIssue: %v", err)
+	}
+	got, err := db.GetIssue(repo.ID, 1)
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if len(got.Assignees) != 2 || got.Assignees[0] != "octocat" || got.Milestone != "v1.0" {
+		t.Errorf("got assignees %v, milestone %q", got.Assignees, got.Milestone)
+	}
+
+	// No assignees reads back as recorded-but-empty, not nil.
+	issue.Assignees = nil
+	issue.Milestone = ""
+	if err := db.UpsertIssue(issue); err != nil {
+		t.Fatalf("UpsertIssue (update): %v", err)
+	}
+	got, _ = db.GetIssue(repo.ID, 1)
+	if got.Assignees == nil || len(got.Assignees) != 0 || got.Milestone != "" {
+		t.Errorf("got assignees %#v, milestone %q", got.Assignees, got.Milestone)
+	}
+
+	// Rows from before the columns existed read back with nil assignees.
+	if _, err := db.Conn().Exec(`UPDATE issues SET assignees = NULL, milestone = NULL`); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = db.GetIssue(repo.ID, 1)
+	if got.Assignees != nil {
+		t.Errorf("expected nil assignees for an old row, got %#v", got.Assignees)
+	}
+}
+
+func TestUpsertIssues(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	now := time.Now().UTC()
+
+	if err := db.UpsertIssues(nil); err != nil {
+		t.Fatalf("UpsertIssues(nil) failed: %v", err)
+	}
+
+	batch := []*Issue{
+		{RepoID: repo.ID, Number: 1, Title: "First", State: "open", Labels: []string{"bug"}, CreatedAt: now, UpdatedAt: now},
+		{RepoID: repo.ID, Number: 2, Title: "Second", State: "open", CreatedAt: now, UpdatedAt: now},
+	}
+	if err := db.UpsertIssues(batch); err != nil {
+		t.Fatalf("UpsertIssues failed: %v", err)
+	}
+	batch[1].Title = "Second, edited"
+	if err := db.UpsertIssues(batch); err != nil {
+		t.Fatalf("UpsertIssues (update) failed: %v", err)
+	}
+
+	issues, err := db.GetIssuesByRepo(repo.ID)
+	if err != nil {
+		t.Fatalf("GetIssuesByRepo failed: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Labels[0] != "bug" || issues[1].Title != "Second, edited" {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+
+	// An issue for an unknown repo fails the foreign key; nothing is stored.
+	bad := []*Issue{
+		{RepoID: repo.ID, Number: 3, Title: "Third", State: "open", CreatedAt: now, UpdatedAt: now},
+		{RepoID: repo.ID + 100, Number: 4, Title: "Orphan", State: "open", CreatedAt: now, UpdatedAt: now},
+	}
+	if err := db.UpsertIssues(bad); err == nil {
+		t.Fatal("expected an error for an unknown repo")
+	}
+	if _, err := db.GetIssue(repo.ID, 3); err == nil {
+		t.Error("expected the failed batch to be rolled back")
+	}
+}
+
 func TestUpdateEmbedding(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -306,3 +739,182 @@ func TestTriageLogDuplicate(t *testing.T) {
 		t.Errorf("expected duplicate_of '#5', got %q", logs[0].DuplicateOf)
 	}
 }
+
+func TestTriageLogRawResponse(t *testing.T) {
+	db := setupTestDB(t)
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+
+	if err := db.LogTriageAction(&TriageLog{RepoID: repo.ID, IssueNumber: 1, Action: "triaged", RawResponse: "not json"}); err != nil {
+		t.Fatalf("LogTriageAction: %v", err)
+	}
+	// Long responses are capped without splitting a multi-byte rune
+	long := strings.Repeat("é", MaxRawResponseBytes)
+	if err := db.LogTriageAction(&TriageLog{RepoID: repo.ID, IssueNumber: 2, Action: "triaged", RawResponse: long}); err != nil {
+		t.Fatalf("LogTriageAction long: %v", err)
+	}
+
+	logs, _ := db.GetTriageLog(repo.ID, 1)
+	if logs[0].RawResponse != "not json" {
+		t.Errorf("expected the raw response, got %q", logs[0].RawResponse)
+	}
+	logs, _ = db.GetTriageLog(repo.ID, 2)
+	got := logs[0].RawResponse
+	if len(got) != MaxRawResponseBytes || !utf8.ValidString(got) {
+		t.Errorf("expected a valid %d-byte response, got %d bytes", MaxRawResponseBytes, len(got))
+	}
+}
+
+func TestListPendingTriage(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	other, _ := db.CreateRepo("octocat", "other")
+
+	entries := []*TriageLog{
+		{RepoID: repo.ID, IssueNumber: 1, Action: "triaged", SuggestedLabels: "bug"},
+		// A newer suggestion for the same issue supersedes the first.
+		{RepoID: repo.ID, IssueNumber: 1, Action: "triaged", SuggestedLabels: "bug, ui",
+			LabelConfidences: map[string]float64{"bug": 0.95, "ui": 0.6}},
+		{RepoID: repo.ID, IssueNumber: 2, Action: "duplicate", DuplicateOf: "#1"},
+		{RepoID: repo.ID, IssueNumber: 3, Action: "triaged", SuggestedLabels: "feature"},
+		{RepoID: repo.ID, IssueNumber: 4, Action: "apply_labels", SuggestedLabels: "bug"},
+		{RepoID: other.ID, IssueNumber: 9, Action: "triaged", SuggestedLabels: "bug"},
+	}
+	for _, e := range entries {
+		if err := db.LogTriageAction(e); err != nil {
+			t.Fatalf("LogTriageAction failed: %v", err)
+		}
+	}
+
+	// Issue 3 has already been decided.
+	logs, _ := db.GetTriageLog(repo.ID, 3)
+	if err := db.UpdateHumanDecision(logs[0].ID, DecisionRejected); err != nil {
+		t.Fatalf("UpdateHumanDecision failed: %v", err)
+	}
+
+	pending, err := db.ListPendingTriage(repo.ID)
+	if err != nil {
+		t.Fatalf("ListPendingTriage failed: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending entries, got %d: %+v", len(pending), pending)
+	}
+	if pending[0].IssueNumber != 1 || pending[0].SuggestedLabels != "bug, ui" {
+		t.Errorf("expected latest suggestion for #1, got %+v", pending[0])
+	}
+	if pending[0].LabelConfidences["bug"] != 0.95 || pending[0].LabelConfidences["ui"] != 0.6 {
+		t.Errorf("unexpected label confidences: %v", pending[0].LabelConfidences)
+	}
+	if pending[1].IssueNumber != 2 || pending[1].DuplicateOf != "#1" {
+		t.Errorf("expected duplicate entry for #2, got %+v", pending[1])
+	}
+}
+
+func TestListTriageLogsSince(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	for _, n := range []int{1, 2, 3} {
+		if err := db.LogTriageAction(&TriageLog{RepoID: repo.ID, IssueNumber: n, Action: "triaged"}); err != nil {
+			t.Fatalf("LogTriageAction failed: %v", err)
+		}
+	}
+	// Backdate issue 1 outside the window.
+	if _, err := db.Conn().Exec(`UPDATE triage_log SET created_at = datetime('now', '-10 days') WHERE issue_number = 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	logs, err := db.ListTriageLogsSince(repo.ID, time.Now().Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("ListTriageLogsSince failed: %v", err)
+	}
+	if len(logs) != 2 || logs[0].IssueNumber != 2 || logs[1].IssueNumber != 3 {
+		t.Fatalf("expected entries for #2 and #3, got %+v", logs)
+	}
+	if time.Since(logs[0].CreatedAt) > time.Minute {
+		t.Errorf("expected CreatedAt to be parsed from the column default, got %v", logs[0].CreatedAt)
+	}
+}
+
+func TestGetDecisionStats(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	for _, l := range []struct {
+		number   int
+		action   string
+		variant  string
+		decision string
+	}{
+		{1, "triaged", "control", DecisionApproved},
+		{2, "triaged", "control", DecisionRejected},
+		{3, "duplicate", "control", ""},
+		{4, "triaged", "short-prompt", DecisionApproved},
+		{5, "abstained", "short-prompt", DecisionApproved},
+		{6, "triaged", "", DecisionRejected},
+		{7, "apply_labels", "control", ""},
+		{8, "triaged", "control", DecisionApproved}, // backdated below
+	} {
+		if err := db.LogTriageAction(&TriageLog{RepoID: repo.ID, IssueNumber: l.number, Action: l.action, Variant: l.variant}); err != nil {
+			t.Fatalf("LogTriageAction failed: %v", err)
+		}
+		if l.decision != "" {
+			if _, err := db.Conn().Exec(`UPDATE triage_log SET human_decision = ? WHERE issue_number = ?`, l.decision, l.number); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := db.Conn().Exec(`UPDATE triage_log SET created_at = datetime('now', '-10 days') WHERE issue_number = 8`); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := db.GetDecisionStats(repo.ID, time.Now().Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("GetDecisionStats failed: %v", err)
+	}
+	want := []DecisionStats{
+		{Variant: "", Suggested: 1, Rejected: 1},
+		{Variant: "control", Suggested: 3, Approved: 1, Rejected: 1},
+		{Variant: "short-prompt", Suggested: 2, Approved: 2},
+	}
+	if fmt.Sprint(stats) != fmt.Sprint(want) {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+
+	logs, _ := db.GetTriageLog(repo.ID, 4)
+	if len(logs) != 1 || logs[0].Variant != "short-prompt" {
+		t.Errorf("expected the variant to be read back, got %+v", logs)
+	}
+}
+
+func TestGetTriageLogByTriageID(t *testing.T) {
+	db := setupTestDB(t)
+
+	repo, _ := db.CreateRepo("octocat", "hello-world")
+	for _, l := range []TriageLog{
+		{RepoID: repo.ID, IssueNumber: 1, Action: "triaged", TriageID: "a1b2c3"},
+		{RepoID: repo.ID, IssueNumber: 2, Action: "duplicate", TriageID: "d4e5f6", Truncated: true},
+		{RepoID: repo.ID, IssueNumber: 3, Action: "apply_labels"},
+	} {
+		if err := db.LogTriageAction(&l); err != nil {
+			t.Fatalf("LogTriageAction failed: %v", err)
+		}
+	}
+
+	got, err := db.GetTriageLogByTriageID("d4e5f6")
+	if err != nil {
+		t.Fatalf("GetTriageLogByTriageID failed: %v", err)
+	}
+	if got.IssueNumber != 2 || got.Action != "duplicate" || got.TriageID != "d4e5f6" || !got.Truncated {
+		t.Errorf("unexpected entry %+v", got)
+	}
+
+	logs, _ := db.GetTriageLog(repo.ID, 3)
+	if len(logs) != 1 || logs[0].TriageID != "" || logs[0].Truncated {
+		t.Errorf("expected no triage ID on entry 3, got %+v", logs)
+	}
+
+	if _, err := db.GetTriageLogByTriageID("ffffff"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
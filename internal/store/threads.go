@@ -0,0 +1,46 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NotifyThread returns the ID of the message that notifier's posts about an
+// issue are threaded under, or "" if there is none yet. repo is owner/repo.
+func (d *DB) NotifyThread(repo string, number int, notifier string) (string, error) {
+	owner, name, _ := strings.Cut(repo, "/")
+	var threadID string
+	err := d.db.QueryRow(`
+		SELECT t.thread_id FROM notify_threads t JOIN repos r ON r.id = t.repo_id
+		WHERE r.owner = ? AND r.repo = ? AND t.issue_number = ? AND t.notifier = ?`,
+		owner, name, number, notifier,
+	).Scan(&threadID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting notify thread for %s#%d: %w", repo, number, err)
+	}
+	return threadID, nil
+}
+
+// SaveNotifyThread records the message that notifier's later posts about an
+// issue should be threaded under. Nothing is saved for an unknown repo.
+func (d *DB) SaveNotifyThread(repo string, number int, notifier, threadID string) error {
+	owner, name, _ := strings.Cut(repo, "/")
+	_, err := d.db.Exec(`
+		INSERT INTO notify_threads (repo_id, issue_number, notifier, thread_id, created_at)
+		SELECT id, ?, ?, ?, ? FROM repos WHERE owner = ? AND repo = ?
+		ON CONFLICT(repo_id, issue_number, notifier) DO UPDATE SET
+			thread_id = excluded.thread_id,
+			created_at = excluded.created_at`,
+		number, notifier, threadID, time.Now().UTC().Format(time.RFC3339), owner, name,
+	)
+	if err != nil {
+		return fmt.Errorf("saving notify thread for %s#%d: %w", repo, number, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package store
+
+import "testing"
+
+func TestNotifyThreads(t *testing.T) {
+	db := setupTestDB(t)
+	if _, err := db.CreateRepo("octocat", "hello-world"); err != nil {
+		t.Fatalf("CreateRepo: %v", err)
+	}
+
+	id, err := db.NotifyThread("octocat/hello-world", 1, "slack")
+	if err != nil || id != "" {
+		t.Fatalf("expected no thread, got %q, %v", id, err)
+	}
+
+	if err := db.SaveNotifyThread("octocat/hello-world", 1, "slack", "1700000000.000100"); err != nil {
+		t.Fatalf("SaveNotifyThread: %v", err)
+	}
+	id, err = db.NotifyThread("octocat/hello-world", 1, "slack")
+	if err != nil || id != "1700000000.000100" {
+		t.Errorf("got %q, %v", id, err)
+	}
+
+	// Threads are per notifier and per issue.
+	if id, _ := db.NotifyThread("octocat/hello-world", 1, "discord"); id != "" {
+		t.Errorf("expected no discord thread, got %q", id)
+	}
+	if id, _ := db.NotifyThread("octocat/hello-world", 2, "slack"); id != "" {
+		t.Errorf("expected no thread for #2, got %q", id)
+	}
+
+	// An unknown repo has no threads, and saving for one is a no-op.
+	if err := db.SaveNotifyThread("other/repo", 1, "slack", "x"); err != nil {
+		t.Fatalf("SaveNotifyThread for unknown repo: %v", err)
+	}
+	if id, _ := db.NotifyThread("other/repo", 1, "slack"); id != "" {
+		t.Errorf("expected no thread for unknown repo, got %q", id)
+	}
+}
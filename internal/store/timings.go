@@ -0,0 +1,123 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Triage stages timed in TriageTiming.
+const (
+	StageDedup    = "dedup"
+	StageClassify = "classify"
+	StageNotify   = "notify"
+	// StageTotal is the whole triage of an issue, from lookup to logging.
+	StageTotal = "total"
+)
+
+// TriageTiming is how long one stage of an issue's triage took.
+type TriageTiming struct {
+	RepoID      int64
+	IssueNumber int
+	TriageID    string
+	Stage       string
+	// Provider names what served the stage, such as "ollama:llama3" for
+	// classification or "slack" for notification. It is empty for
+	// StageTotal.
+	Provider string
+	Duration time.Duration
+}
+
+// LogTriageTimings records the stage timings of a triage.
+func (d *DB) LogTriageTimings(timings []TriageTiming) error {
+	if len(timings) == 0 {
+		return nil
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, t := range timings {
+		_, err := tx.Exec(`
+			INSERT INTO triage_timings (repo_id, issue_number, triage_id, stage, provider, duration_ms, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			t.RepoID, t.IssueNumber, nullStr(t.TriageID), t.Stage, t.Provider, t.Duration.Milliseconds(), now,
+		)
+		if err != nil {
+			return fmt.Errorf("logging %s timing: %w", t.Stage, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LatencyStats summarizes the durations of one stage with one provider.
+type LatencyStats struct {
+	Stage    string
+	Provider string
+	Count    int
+	P50      time.Duration
+	P95      time.Duration
+	Max      time.Duration
+}
+
+// GetLatencyStats returns the latency percentiles of each stage and
+// provider over the timings recorded at or after since, in stage order
+// (dedup, classify, notify, total), then by provider. A zero repoID covers
+// every repo. created_at holds UTC RFC 3339 times, so since is compared as
+// a string, which lets the query use idx_triage_timings_created.
+func (d *DB) GetLatencyStats(repoID int64, since time.Time) ([]LatencyStats, error) {
+	rows, err := d.read.Query(`
+		SELECT stage, provider, duration_ms
+		FROM triage_timings
+		WHERE (? = 0 OR repo_id = ?) AND created_at >= ?
+		ORDER BY CASE stage WHEN ? THEN 0 WHEN ? THEN 1 WHEN ? THEN 2 ELSE 3 END,
+		         stage, provider, duration_ms`,
+		repoID, repoID, since.UTC().Format(time.RFC3339),
+		StageDedup, StageClassify, StageNotify,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying triage timings: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []LatencyStats
+	var durations []time.Duration
+	flush := func() {
+		if len(durations) == 0 {
+			return
+		}
+		s := &stats[len(stats)-1]
+		s.Count = len(durations)
+		s.P50 = percentile(durations, 50)
+		s.P95 = percentile(durations, 95)
+		s.Max = durations[len(durations)-1]
+		durations = durations[:0]
+	}
+	for rows.Next() {
+		var stage, provider string
+		var ms int64
+		if err := rows.Scan(&stage, &provider, &ms); err != nil {
+			return nil, fmt.Errorf("scanning triage timing: %w", err)
+		}
+		if len(stats) == 0 || stats[len(stats)-1].Stage != stage || stats[len(stats)-1].Provider != provider {
+			flush()
+			stats = append(stats, LatencyStats{Stage: stage, Provider: provider})
+		}
+		durations = append(durations, time.Duration(ms)*time.Millisecond)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return stats, nil
+}
+
+// percentile returns the nearest-rank pth percentile of sorted, which must
+// not be empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
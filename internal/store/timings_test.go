@@ -0,0 +1,82 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyStats(t *testing.T) {
+	db := setupTestDB(t)
+
+	a, err := db.CreateRepo("octocat", "a")
+	if err != nil {
+		t.Fatalf("CreateRepo failed: %v", err)
+	}
+	b, err := db.CreateRepo("octocat", "b")
+	if err != nil {
+		t.Fatalf("CreateRepo failed: %v", err)
+	}
+
+	var timings []TriageTiming
+	for i := 1; i <= 20; i++ {
+		timings = append(timings,
+			TriageTiming{RepoID: a.ID, IssueNumber: i, TriageID: "t", Stage: StageClassify, Provider: "ollama:llama3", Duration: time.Duration(i) * time.Second},
+			TriageTiming{RepoID: a.ID, IssueNumber: i, Stage: StageDedup, Provider: "ollama:nomic-embed-text", Duration: 100 * time.Millisecond},
+		)
+	}
+	timings = append(timings,
+		TriageTiming{RepoID: a.ID, IssueNumber: 1, Stage: StageClassify, Provider: "openai:gpt-4o-mini", Duration: 800 * time.Millisecond},
+		TriageTiming{RepoID: b.ID, IssueNumber: 1, Stage: StageClassify, Provider: "ollama:llama3", Duration: time.Minute},
+	)
+	if err := db.LogTriageTimings(timings); err != nil {
+		t.Fatalf("LogTriageTimings failed: %v", err)
+	}
+	if err := db.LogTriageTimings(nil); err != nil {
+		t.Fatalf("LogTriageTimings with no timings failed: %v", err)
+	}
+
+	stats, err := db.GetLatencyStats(a.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetLatencyStats failed: %v", err)
+	}
+	want := []LatencyStats{
+		{Stage: StageDedup, Provider: "ollama:nomic-embed-text", Count: 20, P50: 100 * time.Millisecond, P95: 100 * time.Millisecond, Max: 100 * time.Millisecond},
+		{Stage: StageClassify, Provider: "ollama:llama3", Count: 20, P50: 10 * time.Second, P95: 19 * time.Second, Max: 20 * time.Second},
+		{Stage: StageClassify, Provider: "openai:gpt-4o-mini", Count: 1, P50: 800 * time.Millisecond, P95: 800 * time.Millisecond, Max: 800 * time.Millisecond},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("got %d stats, want %d: %+v", len(stats), len(want), stats)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+
+	// A zero repo ID covers every repo
+	all, err := db.GetLatencyStats(0, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetLatencyStats failed: %v", err)
+	}
+	if len(all) != 3 || all[1].Count != 21 || all[1].Max != time.Minute {
+		t.Errorf("unexpected stats for every repo: %+v", all)
+	}
+
+	// Older timings are left out
+	recent, err := db.GetLatencyStats(0, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetLatencyStats failed: %v", err)
+	}
+	if len(recent) != 0 {
+		t.Errorf("expected no stats in the future, got %+v", recent)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4}
+	for p, want := range map[float64]time.Duration{0: 1, 25: 1, 50: 2, 95: 4, 100: 4} {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIToken represents a provisioned API token. Only the SHA-256 hash of the
+// token is stored; the plaintext is shown once at creation time.
+type APIToken struct {
+	ID         int64
+	Name       string
+	TokenHash  string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// CreateAPIToken inserts a new API token record.
+func (d *DB) CreateAPIToken(name, tokenHash string, scopes []string) (*APIToken, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := d.db.Exec(
+		`INSERT INTO api_tokens (name, token_hash, scopes, created_at) VALUES (?, ?, ?, ?)`,
+		name, tokenHash, strings.Join(scopes, ","), now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating api token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("getting api token id: %w", err)
+	}
+
+	row := d.db.QueryRow(
+		`SELECT id, name, token_hash, scopes, created_at, last_used_at, revoked_at FROM api_tokens WHERE id = ?`,
+		id,
+	)
+	return scanAPIToken(row)
+}
+
+// GetAPITokenByHash retrieves an active (non-revoked) token by its hash.
+func (d *DB) GetAPITokenByHash(tokenHash string) (*APIToken, error) {
+	row := d.db.QueryRow(
+		`SELECT id, name, token_hash, scopes, created_at, last_used_at, revoked_at
+		FROM api_tokens WHERE token_hash = ? AND revoked_at IS NULL`,
+		tokenHash,
+	)
+	return scanAPIToken(row)
+}
+
+// ListAPITokens returns all tokens, including revoked ones.
+func (d *DB) ListAPITokens() ([]APIToken, error) {
+	rows, err := d.db.Query(
+		`SELECT id, name, token_hash, scopes, created_at, last_used_at, revoked_at FROM api_tokens ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeAPIToken marks the named token as revoked. It returns an error
+// wrapping ErrNotFound if no active token with that name exists.
+func (d *DB) RevokeAPIToken(name string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := d.db.Exec(
+		`UPDATE api_tokens SET revoked_at = ? WHERE name = ? AND revoked_at IS NULL`,
+		now, name,
+	)
+	if err != nil {
+		return fmt.Errorf("revoking api token: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("revoking api token: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("revoking api token %q: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// TouchAPIToken records that the token was just used.
+func (d *DB) TouchAPIToken(id int64) error {
+	_, err := d.db.Exec(
+		`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`,
+		time.Now().UTC().Format(time.RFC3339), id,
+	)
+	if err != nil {
+		return fmt.Errorf("touching api token: %w", err)
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIToken(row rowScanner) (*APIToken, error) {
+	var t APIToken
+	var scopes, createdAt string
+	var lastUsed, revoked sql.NullString
+
+	err := row.Scan(&t.ID, &t.Name, &t.TokenHash, &scopes, &createdAt, &lastUsed, &revoked)
+	if err != nil {
+		return nil, fmt.Errorf("scanning api token: %w", notFound(err, ErrNotFound))
+	}
+
+	if scopes != "" {
+		t.Scopes = strings.Split(scopes, ",")
+	}
+	t.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	if lastUsed.Valid {
+		ts, _ := time.Parse(time.RFC3339, lastUsed.String)
+		t.LastUsedAt = &ts
+	}
+	if revoked.Valid {
+		ts, _ := time.Parse(time.RFC3339, revoked.String)
+		t.RevokedAt = &ts
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestAPITokensCRUD(t *testing.T) {
+	db := setupTestDB(t)
+
+	tok, err := db.CreateAPIToken("ci", "hash-1", []string{"read", "triage"})
+	if err != nil {
+		t.Fatalf("CreateAPIToken failed: %v", err)
+	}
+	if tok.Name != "ci" || len(tok.Scopes) != 2 || tok.Scopes[1] != "triage" {
+		t.Errorf("unexpected token: %+v", tok)
+	}
+	if tok.RevokedAt != nil || tok.LastUsedAt != nil {
+		t.Error("new token should be unused and active")
+	}
+
+	got, err := db.GetAPITokenByHash("hash-1")
+	if err != nil {
+		t.Fatalf("GetAPITokenByHash failed: %v", err)
+	}
+	if got.ID != tok.ID {
+		t.Errorf("expected id %d, got %d", tok.ID, got.ID)
+	}
+
+	if err := db.TouchAPIToken(tok.ID); err != nil {
+		t.Fatalf("TouchAPIToken failed: %v", err)
+	}
+	got, _ = db.GetAPITokenByHash("hash-1")
+	if got.LastUsedAt == nil {
+		t.Error("expected LastUsedAt to be set")
+	}
+
+	// Names are unique
+	if _, err := db.CreateAPIToken("ci", "hash-2", []string{"read"}); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+
+	if err := db.RevokeAPIToken("ci"); err != nil {
+		t.Fatalf("RevokeAPIToken failed: %v", err)
+	}
+	if _, err := db.GetAPITokenByHash("hash-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected ErrNoRows for revoked token, got %v", err)
+	}
+	if err := db.RevokeAPIToken("ci"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected ErrNoRows revoking twice, got %v", err)
+	}
+
+	tokens, err := db.ListAPITokens()
+	if err != nil {
+		t.Fatalf("ListAPITokens failed: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].RevokedAt == nil {
+		t.Errorf("expected one revoked token, got %+v", tokens)
+	}
+}
@@ -2,32 +2,72 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
+	"unicode/utf8"
 )
 
+// MaxRawResponseBytes caps the raw LLM response kept with a triage log
+// entry; longer responses are cut short.
+const MaxRawResponseBytes = 16 << 10
+
+// Human decisions recorded against triage log entries.
+const (
+	DecisionApproved = "approved"
+	DecisionRejected = "rejected"
+)
+
+const triageLogColumns = `id, repo_id, issue_number, action, duplicate_of, suggested_labels,
+		       reasoning, notified_via, human_decision, created_at, label_confidences, raw_response,
+		       variant, triage_id, truncated`
+
 // TriageLog represents a triage action log entry.
 type TriageLog struct {
-	ID              int64
-	RepoID          int64
-	IssueNumber     int
-	Action          string
-	DuplicateOf     string
-	SuggestedLabels string
-	Reasoning       string
-	NotifiedVia     string
-	HumanDecision   string
-	CreatedAt       time.Time
+	ID               int64
+	RepoID           int64
+	IssueNumber      int
+	Action           string
+	DuplicateOf      string
+	SuggestedLabels  string
+	Reasoning        string
+	NotifiedVia      string
+	HumanDecision    string
+	CreatedAt        time.Time
+	LabelConfidences map[string]float64 // suggested label name -> confidence
+	// RawResponse is the classifier's raw completion, when kept. It is cut
+	// to MaxRawResponseBytes when logged.
+	RawResponse string
+	// Variant names the experiment variant that classified the issue, or
+	// is empty if no experiment ran.
+	Variant string
+	// TriageID identifies the run that made the entry; the same ID is on
+	// its log lines, notification, and provider requests.
+	TriageID string
+	// Truncated is set when the issue body was shortened to fit the model's
+	// context before it was classified.
+	Truncated bool
 }
 
 // LogTriageAction inserts a new triage log entry.
 func (d *DB) LogTriageAction(log *TriageLog) error {
+	var confidences sql.NullString
+	if len(log.LabelConfidences) > 0 {
+		data, err := json.Marshal(log.LabelConfidences)
+		if err != nil {
+			return fmt.Errorf("encoding label confidences: %w", err)
+		}
+		confidences = nullStr(string(data))
+	}
+
 	_, err := d.db.Exec(`
-		INSERT INTO triage_log (repo_id, issue_number, action, duplicate_of, suggested_labels, reasoning, notified_via)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		INSERT INTO triage_log (repo_id, issue_number, action, duplicate_of, suggested_labels, reasoning, notified_via, label_confidences, raw_response, variant, triage_id, truncated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		log.RepoID, log.IssueNumber, log.Action,
 		nullStr(log.DuplicateOf), nullStr(log.SuggestedLabels),
-		nullStr(log.Reasoning), nullStr(log.NotifiedVia),
+		nullStr(log.Reasoning), nullStr(log.NotifiedVia), confidences,
+		nullStr(capRawResponse(log.RawResponse)), nullStr(log.Variant),
+		nullStr(log.TriageID), log.Truncated,
 	)
 	if err != nil {
 		return fmt.Errorf("logging triage action: %w", err)
@@ -37,9 +77,8 @@ func (d *DB) LogTriageAction(log *TriageLog) error {
 
 // GetTriageLog retrieves triage log entries for a repo and issue.
 func (d *DB) GetTriageLog(repoID int64, issueNumber int) ([]TriageLog, error) {
-	rows, err := d.db.Query(`
-		SELECT id, repo_id, issue_number, action, duplicate_of, suggested_labels,
-		       reasoning, notified_via, human_decision, created_at
+	rows, err := d.read.Query(`
+		SELECT `+triageLogColumns+`
 		FROM triage_log WHERE repo_id = ? AND issue_number = ?
 		ORDER BY created_at DESC`,
 		repoID, issueNumber,
@@ -47,17 +86,105 @@ func (d *DB) GetTriageLog(repoID int64, issueNumber int) ([]TriageLog, error) {
 	if err != nil {
 		return nil, fmt.Errorf("querying triage log: %w", err)
 	}
+	return collectTriageLogs(rows)
+}
+
+// GetTriageLogByTriageID returns the triage log entry made by the run with
+// the given triage ID. It returns an error wrapping ErrNotFound if there is
+// none.
+func (d *DB) GetTriageLogByTriageID(triageID string) (*TriageLog, error) {
+	rows, err := d.db.Query(`
+		SELECT `+triageLogColumns+`
+		FROM triage_log WHERE triage_id = ?
+		ORDER BY id DESC LIMIT 1`,
+		triageID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying triage log: %w", err)
+	}
+	logs, err := collectTriageLogs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("triage log entry %s: %w", triageID, ErrNotFound)
+	}
+	return &logs[0], nil
+}
+
+// ListPendingTriage returns the latest triage suggestion for each issue in a
+// repo that has not yet been approved or rejected, ordered by issue number.
+func (d *DB) ListPendingTriage(repoID int64) ([]TriageLog, error) {
+	rows, err := d.db.Query(`
+		SELECT `+triageLogColumns+`
+		FROM triage_log
+		WHERE id IN (SELECT MAX(id) FROM triage_log WHERE repo_id = ? GROUP BY issue_number)
+		  AND action IN ('triaged', 'duplicate')
+		  AND human_decision IS NULL
+		ORDER BY issue_number`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending triage: %w", err)
+	}
+	return collectTriageLogs(rows)
+}
+
+// ListTriageLogsSince returns the triage log entries for a repo recorded at
+// or after since, oldest first.
+func (d *DB) ListTriageLogsSince(repoID int64, since time.Time) ([]TriageLog, error) {
+	rows, err := d.read.Query(`
+		SELECT `+triageLogColumns+`
+		FROM triage_log
+		WHERE repo_id = ? AND datetime(created_at) >= datetime(?)
+		ORDER BY id`,
+		repoID, since.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying triage log: %w", err)
+	}
+	return collectTriageLogs(rows)
+}
+
+// DecisionStats counts the triage suggestions of one experiment variant and
+// how humans judged them.
+type DecisionStats struct {
+	// Variant is empty for suggestions made while no experiment ran.
+	Variant   string
+	Suggested int
+	Approved  int
+	Rejected  int
+}
+
+// GetDecisionStats returns, per variant, the triage suggestions for a repo
+// recorded at or after since and the human decisions made on them, ordered
+// by variant.
+func (d *DB) GetDecisionStats(repoID int64, since time.Time) ([]DecisionStats, error) {
+	rows, err := d.read.Query(`
+		SELECT COALESCE(variant, ''), COUNT(*),
+		       COUNT(CASE WHEN human_decision = ? THEN 1 END),
+		       COUNT(CASE WHEN human_decision = ? THEN 1 END)
+		FROM triage_log
+		WHERE repo_id = ? AND action IN ('triaged', 'duplicate', 'abstained')
+		  AND datetime(created_at) >= datetime(?)
+		GROUP BY COALESCE(variant, '')
+		ORDER BY 1`,
+		DecisionApproved, DecisionRejected, repoID, since.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying decision stats: %w", err)
+	}
 	defer rows.Close()
 
-	var logs []TriageLog
+	var stats []DecisionStats
 	for rows.Next() {
-		log, err := scanTriageLog(rows)
-		if err != nil {
-			return nil, err
+		var s DecisionStats
+		if err := rows.Scan(&s.Variant, &s.Suggested, &s.Approved, &s.Rejected); err != nil {
+			return nil, fmt.Errorf("scanning decision stats: %w", err)
 		}
-		logs = append(logs, *log)
+		stats = append(stats, s)
 	}
-	return logs, rows.Err()
+	return stats, rows.Err()
 }
 
 // UpdateHumanDecision updates the human_decision field for a triage log entry.
@@ -72,14 +199,29 @@ func (d *DB) UpdateHumanDecision(logID int64, decision string) error {
 	return nil
 }
 
+func collectTriageLogs(rows *sql.Rows) ([]TriageLog, error) {
+	defer rows.Close()
+
+	var logs []TriageLog
+	for rows.Next() {
+		log, err := scanTriageLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *log)
+	}
+	return logs, rows.Err()
+}
+
 func scanTriageLog(rows *sql.Rows) (*TriageLog, error) {
 	var log TriageLog
-	var dupOf, labels, reasoning, notified, decision sql.NullString
+	var dupOf, labels, reasoning, notified, decision, confidences, raw, variant, triageID sql.NullString
 	var createdAt string
 
 	err := rows.Scan(
 		&log.ID, &log.RepoID, &log.IssueNumber, &log.Action,
-		&dupOf, &labels, &reasoning, &notified, &decision, &createdAt,
+		&dupOf, &labels, &reasoning, &notified, &decision, &createdAt, &confidences, &raw,
+		&variant, &triageID, &log.Truncated,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("scanning triage log: %w", err)
@@ -90,11 +232,42 @@ func scanTriageLog(rows *sql.Rows) (*TriageLog, error) {
 	log.Reasoning = reasoning.String
 	log.NotifiedVia = notified.String
 	log.HumanDecision = decision.String
-	log.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	log.RawResponse = raw.String
+	log.Variant = variant.String
+	log.TriageID = triageID.String
+	log.CreatedAt = parseLogTime(createdAt)
+	if confidences.Valid {
+		if err := json.Unmarshal([]byte(confidences.String), &log.LabelConfidences); err != nil {
+			return nil, fmt.Errorf("decoding label confidences: %w", err)
+		}
+	}
 
 	return &log, nil
 }
 
+// capRawResponse cuts s to at most MaxRawResponseBytes without splitting a
+// UTF-8 sequence.
+func capRawResponse(s string) string {
+	if len(s) <= MaxRawResponseBytes {
+		return s
+	}
+	cut := MaxRawResponseBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// parseLogTime parses a triage_log timestamp, which is RFC 3339 or, when set
+// by the column default, SQLite's "YYYY-MM-DD HH:MM:SS" in UTC.
+func parseLogTime(s string) time.Time {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	t, _ := time.Parse(time.DateTime, s)
+	return t
+}
+
 func nullStr(s string) sql.NullString {
 	if s == "" {
 		return sql.NullString{}
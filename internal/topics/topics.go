@@ -0,0 +1,246 @@
+// Package topics groups a repo's issues into recurring problem areas by
+// clustering their embeddings, and names each area with an LLM.
+package topics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/jacklau/triage/internal/provider"
+)
+
+// Issue is an issue to cluster, with its embedding.
+type Issue struct {
+	Number    int
+	Title     string
+	Embedding []float32
+}
+
+// Topic is a cluster of similar issues.
+type Topic struct {
+	// Name describes the problem area, if it was named.
+	Name string
+	// Issues are ordered from the most to the least central.
+	Issues []Issue
+}
+
+// maxIterations bounds the k-means refinement rounds.
+const maxIterations = 50
+
+// DefaultK returns a cluster count suited to n issues: about sqrt(n/2),
+// between 2 and 20.
+func DefaultK(n int) int {
+	k := int(math.Round(math.Sqrt(float64(n) / 2)))
+	return max(2, min(k, 20))
+}
+
+// Cluster groups issues into at most k topics by cosine similarity, using
+// spherical k-means seeded with k-means++ from seed, so the same input gives
+// the same topics. Issues whose embedding dimension differs from the first
+// issue's are left out. Topics are ordered largest first.
+func Cluster(issues []Issue, k int, seed int64) []Topic {
+	var points []Issue
+	var vecs [][]float64
+	for _, is := range issues {
+		if len(is.Embedding) == 0 || (len(vecs) > 0 && len(is.Embedding) != len(vecs[0])) {
+			continue
+		}
+		if v, ok := unit(is.Embedding); ok {
+			points = append(points, is)
+			vecs = append(vecs, v)
+		}
+	}
+	if len(points) == 0 || k <= 0 {
+		return nil
+	}
+	k = min(k, len(points))
+
+	rng := rand.New(rand.NewSource(seed))
+	centroids := seedCentroids(vecs, k, rng)
+	assign := make([]int, len(vecs))
+	for i, v := range vecs {
+		assign[i] = nearest(v, centroids)
+	}
+	for iter := 0; iter < maxIterations; iter++ {
+		centroids = recenter(vecs, assign, centroids)
+		changed := false
+		for i, v := range vecs {
+			if best := nearest(v, centroids); best != assign[i] {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	members := make([][]int, len(centroids))
+	for i, c := range assign {
+		members[c] = append(members[c], i)
+	}
+	var topics []Topic
+	for c, idx := range members {
+		if len(idx) == 0 {
+			continue
+		}
+		sort.SliceStable(idx, func(a, b int) bool {
+			return dot(vecs[idx[a]], centroids[c]) > dot(vecs[idx[b]], centroids[c])
+		})
+		t := Topic{Issues: make([]Issue, len(idx))}
+		for i, p := range idx {
+			t.Issues[i] = points[p]
+		}
+		topics = append(topics, t)
+	}
+	sort.SliceStable(topics, func(i, j int) bool { return len(topics[i].Issues) > len(topics[j].Issues) })
+	return topics
+}
+
+// seedCentroids picks k starting centroids with k-means++: each next one is
+// a point chosen with probability growing with its distance from those
+// already picked.
+func seedCentroids(vecs [][]float64, k int, rng *rand.Rand) [][]float64 {
+	centroids := [][]float64{vecs[rng.Intn(len(vecs))]}
+	dist := make([]float64, len(vecs))
+	for len(centroids) < k {
+		var total float64
+		for i, v := range vecs {
+			dist[i] = 1 - dot(v, centroids[nearest(v, centroids)])
+			if dist[i] < 0 {
+				dist[i] = 0
+			}
+			total += dist[i]
+		}
+		if total == 0 {
+			// Every point sits on a centroid already
+			break
+		}
+		r := rng.Float64() * total
+		pick := len(vecs) - 1
+		for i, d := range dist {
+			if r -= d; r <= 0 {
+				pick = i
+				break
+			}
+		}
+		centroids = append(centroids, vecs[pick])
+	}
+	return centroids
+}
+
+// recenter returns the normalized mean of each cluster's points, keeping
+// the old centroid of an empty cluster.
+func recenter(vecs [][]float64, assign []int, old [][]float64) [][]float64 {
+	sums := make([][]float64, len(old))
+	for c := range sums {
+		sums[c] = make([]float64, len(vecs[0]))
+	}
+	for i, v := range vecs {
+		s := sums[assign[i]]
+		for d, x := range v {
+			s[d] += x
+		}
+	}
+	for c, s := range sums {
+		if u, ok := unit64(s); ok {
+			sums[c] = u
+		} else {
+			sums[c] = old[c]
+		}
+	}
+	return sums
+}
+
+// nearest returns the index of the centroid most similar to v.
+func nearest(v []float64, centroids [][]float64) int {
+	best, bestSim := 0, math.Inf(-1)
+	for c, centroid := range centroids {
+		if sim := dot(v, centroid); sim > bestSim {
+			best, bestSim = c, sim
+		}
+	}
+	return best
+}
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+// unit returns v scaled to length 1, or false for a zero vector.
+func unit(v []float32) ([]float64, bool) {
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = float64(x)
+	}
+	return unit64(out)
+}
+
+func unit64(v []float64) ([]float64, bool) {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return nil, false
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return v, true
+}
+
+// maxNamingTitles caps the issue titles sent to the LLM to name a topic.
+const maxNamingTitles = 10
+
+// Name asks completer to name each topic from its most central issues'
+// titles. Topics that fail to be named are left unnamed; the errors are
+// returned together.
+func Name(ctx context.Context, completer provider.Completer, repo string, topics []Topic) error {
+	var errs []string
+	for i := range topics {
+		name, err := nameTopic(ctx, completer, repo, topics[i])
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("topic %d: %v", i+1, err))
+			continue
+		}
+		topics[i].Name = name
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("naming topics: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func nameTopic(ctx context.Context, completer provider.Completer, repo string, t Topic) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "These issues were filed in the GitHub repository %s and are similar to each other:\n\n", repo)
+	for _, is := range t.Issues[:min(len(t.Issues), maxNamingTitles)] {
+		fmt.Fprintf(&b, "- %s\n", is.Title)
+	}
+	b.WriteString("\nName the problem area they share in at most six words, such as \"Crashes on startup\" or \"Login with SSO\". ")
+	b.WriteString("Reply with the name only.")
+
+	out, err := completer.Complete(ctx, b.String())
+	if err != nil {
+		return "", err
+	}
+	return parseName(out)
+}
+
+// parseName returns the first non-empty line of an LLM reply, without
+// surrounding quotes or markdown emphasis.
+func parseName(out string) (string, error) {
+	for _, line := range strings.Split(out, "\n") {
+		name := strings.Trim(strings.TrimSpace(line), "\"'*`#. ")
+		if name != "" {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("empty reply")
+}
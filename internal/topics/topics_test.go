@@ -0,0 +1,182 @@
+package topics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeCompleter replies with names in turn, failing once they run out.
+type fakeCompleter struct {
+	names   []string
+	prompts []string
+}
+
+func (f *fakeCompleter) Complete(_ context.Context, prompt string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	if len(f.names) == 0 {
+		return "", errors.New("no reply")
+	}
+	name := f.names[0]
+	f.names = f.names[1:]
+	return name, nil
+}
+
+func testIssues() []Issue {
+	return []Issue{
+		{Number: 1, Title: "Crash on start", Embedding: []float32{1, 0.1, 0}},
+		{Number: 2, Title: "Crash on exit", Embedding: []float32{0.9, 0, 0.1}},
+		{Number: 3, Title: "Crash when idle", Embedding: []float32{1, 0, 0}},
+		{Number: 4, Title: "Login fails", Embedding: []float32{0, 1, 0.1}},
+		{Number: 5, Title: "SSO login loops", Embedding: []float32{0.1, 0.9, 0}},
+		{Number: 6, Title: "Docs typo", Embedding: []float32{0, 0, 1}},
+	}
+}
+
+func numbers(t Topic) []int {
+	var ns []int
+	for _, is := range t.Issues {
+		ns = append(ns, is.Number)
+	}
+	return ns
+}
+
+func TestCluster(t *testing.T) {
+	topics := Cluster(testIssues(), 3, 1)
+	if len(topics) != 3 {
+		t.Fatalf("expected 3 topics, got %d", len(topics))
+	}
+	want := [][]int{{1, 2, 3}, {4, 5}, {6}}
+	for i, topic := range topics {
+		got := numbers(topic)
+		if len(got) != len(want[i]) {
+			t.Fatalf("topic %d: expected issues %v, got %v", i, want[i], got)
+		}
+		seen := map[int]bool{}
+		for _, n := range got {
+			seen[n] = true
+		}
+		for _, n := range want[i] {
+			if !seen[n] {
+				t.Errorf("topic %d: expected issues %v, got %v", i, want[i], got)
+			}
+		}
+	}
+	// The issue nearest the middle of the crash topic comes first
+	if topics[0].Issues[0].Number != 3 {
+		t.Errorf("expected #3 to be the most central crash, got %v", numbers(topics[0]))
+	}
+}
+
+func TestClusterDeterministic(t *testing.T) {
+	a := Cluster(testIssues(), 2, 7)
+	b := Cluster(testIssues(), 2, 7)
+	if len(a) != len(b) {
+		t.Fatalf("expected the same topics, got %d and %d", len(a), len(b))
+	}
+	for i := range a {
+		na, nb := numbers(a[i]), numbers(b[i])
+		if len(na) != len(nb) {
+			t.Fatalf("topic %d differs: %v vs %v", i, na, nb)
+		}
+		for j := range na {
+			if na[j] != nb[j] {
+				t.Errorf("topic %d differs: %v vs %v", i, na, nb)
+			}
+		}
+	}
+}
+
+func TestClusterSkipsUnusableEmbeddings(t *testing.T) {
+	issues := []Issue{
+		{Number: 1, Embedding: []float32{1, 0}},
+		{Number: 2, Embedding: nil},
+		{Number: 3, Embedding: []float32{0, 0}},
+		{Number: 4, Embedding: []float32{1, 0, 0}},
+		{Number: 5, Embedding: []float32{0, 1}},
+	}
+	topics := Cluster(issues, 5, 1)
+	var total int
+	for _, topic := range topics {
+		for _, n := range numbers(topic) {
+			if n != 1 && n != 5 {
+				t.Errorf("expected #%d to be left out", n)
+			}
+			total++
+		}
+	}
+	if total != 2 || len(topics) != 2 {
+		t.Errorf("expected #1 and #5 in 2 topics, got %d issues in %d topics", total, len(topics))
+	}
+
+	if Cluster(nil, 3, 1) != nil {
+		t.Error("expected no topics without issues")
+	}
+	if Cluster(issues, 0, 1) != nil {
+		t.Error("expected no topics for k=0")
+	}
+}
+
+func TestClusterIdenticalVectors(t *testing.T) {
+	issues := []Issue{
+		{Number: 1, Embedding: []float32{1, 1}},
+		{Number: 2, Embedding: []float32{2, 2}},
+		{Number: 3, Embedding: []float32{1, 1}},
+	}
+	topics := Cluster(issues, 3, 1)
+	if len(topics) != 1 || len(topics[0].Issues) != 3 {
+		t.Errorf("expected one topic of 3 issues, got %d topics", len(topics))
+	}
+}
+
+func TestDefaultK(t *testing.T) {
+	tests := []struct{ n, want int }{
+		{0, 2},
+		{4, 2},
+		{50, 5},
+		{200, 10},
+		{10000, 20},
+	}
+	for _, tt := range tests {
+		if got := DefaultK(tt.n); got != tt.want {
+			t.Errorf("DefaultK(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	topics := Cluster(testIssues(), 3, 1)
+	completer := &fakeCompleter{names: []string{"\n**\"Crashes\"**\nextra", "Login"}}
+
+	err := Name(context.Background(), completer, "org/repo", topics)
+	if err == nil || !strings.Contains(err.Error(), "topic 3: no reply") {
+		t.Errorf("expected the third topic to fail, got %v", err)
+	}
+	if topics[0].Name != "Crashes" || topics[1].Name != "Login" || topics[2].Name != "" {
+		t.Errorf("unexpected names %q, %q, %q", topics[0].Name, topics[1].Name, topics[2].Name)
+	}
+	if !strings.Contains(completer.prompts[0], "org/repo") || !strings.Contains(completer.prompts[0], "- Crash on start\n") {
+		t.Errorf("expected the repo and titles in the prompt, got %q", completer.prompts[0])
+	}
+}
+
+func TestNameLimitsTitles(t *testing.T) {
+	var topic Topic
+	for i := 0; i < maxNamingTitles+5; i++ {
+		topic.Issues = append(topic.Issues, Issue{Number: i + 1, Title: "issue"})
+	}
+	completer := &fakeCompleter{names: []string{"Issues"}}
+	if err := Name(context.Background(), completer, "org/repo", []Topic{topic}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(completer.prompts[0], "- issue\n"); got != maxNamingTitles {
+		t.Errorf("expected %d titles in the prompt, got %d", maxNamingTitles, got)
+	}
+}
+
+func TestParseNameEmpty(t *testing.T) {
+	if _, err := parseName(" \n\"\"\n"); err == nil {
+		t.Error("expected an error for an empty reply")
+	}
+}